@@ -0,0 +1,187 @@
+// Command auditreplay exports a window of audit events, and replays them in another environment through the
+// service layer, to reproduce a production incident step by step.
+//
+// Usage:
+//
+//	go run ./cmd/auditreplay export -from 2026-10-01T12:00:00Z -to 2026-10-01T13:00:00Z -out events.ndjson
+//	go run ./cmd/auditreplay replay -target-host staging-db [-target-port 5432] [-target-dbname gotemplate] [-json] -in events.ndjson
+//
+// Export reads the database of the configuration and writes one JSON event per line to a new file, without the personal data
+// keys of their metadata. Replay performs the actions again against the target database, in the order read, and
+// reports each event as applied, skipped or failed; it exits with status 1 if any failed. The target connection
+// defaults to the settings of the configuration, with the password taken from AUDIT_REPLAY_DB_PASSWORD when set,
+// and must be a different database than the configured one. Replay into a copy of the data taken before the
+// window, e.g. made with cmd/stagingclone, never into production.
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/logger"
+	"io"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func main() {
+	if len(os.Args) < 2 || (os.Args[1] != "export" && os.Args[1] != "replay") {
+		fmt.Println("Usage: auditreplay export -from <time> -to <time> -out <file> | auditreplay replay -target-host <host> -in <file> [-json]")
+		os.Exit(2)
+	}
+	cmd := flag.NewFlagSet(os.Args[1], flag.ExitOnError)
+	from := cmd.String("from", "", "export events created at or after this RFC 3339 time")
+	to := cmd.String("to", "", "export events created before this RFC 3339 time")
+	out := cmd.String("out", "", "file to export the events to; it must not exist yet")
+	in := cmd.String("in", "", "file of exported events to replay")
+	targetHost := cmd.String("target-host", "", "host of the database to replay into (required for replay)")
+	targetPort := cmd.String("target-port", "", "port of the database to replay into, defaults to the configured one's")
+	targetUser := cmd.String("target-user", "", "user of the database to replay into, defaults to the configured one's")
+	targetDBName := cmd.String("target-dbname", "", "name of the database to replay into, defaults to the configured one's")
+	targetSSLMode := cmd.String("target-sslmode", "", "sslmode of the database to replay into, defaults to the configured one's")
+	asJSON := cmd.Bool("json", false, "print the replay report as JSON")
+	timeout := cmd.Duration("timeout", time.Hour, "abort after this long")
+	_ = cmd.Parse(os.Args[2:])
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(2)
+	}
+	logger.InitLogger(cfg.Server.Debug)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if os.Args[1] == "export" {
+		start, startErr := time.Parse(time.RFC3339, *from)
+		end, endErr := time.Parse(time.RFC3339, *to)
+		if startErr != nil || endErr != nil || !start.Before(end) || *out == "" {
+			fmt.Println("-from and -to must be RFC 3339 times, -from before -to, and -out is required")
+			os.Exit(2)
+		}
+		db := connect(&cfg.Database)
+		defer database.CloseDB(db)
+		if err := export(ctx, db, start, end, *out); err != nil {
+			logger.Fatal("Audit event export failed", zap.Error(err))
+		}
+		return
+	}
+
+	target := cfg.Database
+	target.Host = *targetHost
+	target.Port = orDefault(*targetPort, target.Port)
+	target.User = orDefault(*targetUser, target.User)
+	target.DBName = orDefault(*targetDBName, target.DBName)
+	target.SSLMode = orDefault(*targetSSLMode, target.SSLMode)
+	target.Password = orDefault(os.Getenv("AUDIT_REPLAY_DB_PASSWORD"), target.Password)
+	if target.Host == "" {
+		fmt.Println("-target-host is required")
+		os.Exit(2)
+	}
+	if target.Host == cfg.Database.Host && target.Port == cfg.Database.Port && target.DBName == cfg.Database.DBName {
+		fmt.Println("The target is the configured database; refusing to replay into it")
+		os.Exit(2)
+	}
+
+	f, err := os.Open(*in)
+	if err != nil {
+		fmt.Printf("Error opening events: %v\n", err)
+		os.Exit(2)
+	}
+	defer f.Close()
+	db := connect(&target)
+	defer database.CloseDB(db)
+	report, err := replay(ctx, db, f)
+	if err != nil {
+		logger.Fatal("Audit event replay failed", zap.Error(err))
+	}
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		for _, result := range report.Results {
+			fmt.Printf("%-8s event %d %-24s %s\n", result.Outcome, result.EventID, result.Action, result.Detail)
+		}
+		fmt.Printf("%d applied, %d skipped, %d failed\n", report.Applied, report.Skipped, report.Failed)
+	}
+	if report.Failed > 0 {
+		database.CloseDB(db)
+		os.Exit(1)
+	}
+}
+
+// connect opens the database of cfg
+func connect(cfg *config.DatabaseConfig) *gorm.DB {
+	db, err := database.NewPostgresDB(cfg)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	return db
+}
+
+// export writes the audit events of a window to a new file, one JSON object per line
+func export(ctx context.Context, db *gorm.DB, from, to time.Time, path string) error {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	err = service.ExportAuditEvents(ctx, repository.NewPostgresAuditRepository(db), from, to, func(event *models.AuditEvent) error {
+		return enc.Encode(event)
+	})
+	if err != nil {
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// replay performs the actions of the events read from r again, with services wired as the application wires them
+func replay(ctx context.Context, db *gorm.DB, r io.Reader) (*models.AuditReplayReport, error) {
+	productRepo := repository.NewPostgresProductRepository(db)
+	auditService := service.NewAuditService(repository.NewPostgresAuditRepository(db), service.NewActivityService(repository.NewPostgresActivityRepository(db)))
+	replayer := service.NewAuditReplayer(
+		service.NewProductService(productRepo, repository.NewPostgresProductPermissionRepository(db), repository.NewPostgresUserRepository(db), auditService),
+		service.NewReportService(repository.NewPostgresReportRepository(db), productRepo, repository.NewPostgresCommentRepository(db), auditService),
+	)
+
+	report := &models.AuditReplayReport{}
+	dec := json.NewDecoder(r)
+	for {
+		event := &models.AuditEvent{}
+		if err := dec.Decode(event); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read event %d: %w", len(report.Results)+1, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		report.Add(replayer.Replay(ctx, event))
+	}
+	logger.Info("Audit events replayed", zap.Int("applied", report.Applied), zap.Int("skipped", report.Skipped), zap.Int("failed", report.Failed))
+	return report, nil
+}
+
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
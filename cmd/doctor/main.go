@@ -0,0 +1,73 @@
+// Command doctor scans the database for integrity problems and optionally repairs them.
+//
+// Usage:
+//
+//	go run ./cmd/doctor [-repair] [-json]
+//
+// It exits with status 1 when issues remain after the scan, so it can run as a CI or cron check.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/repository"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/logger"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "fix issues that have a safe automatic repair")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	timeout := flag.Duration("timeout", 10*time.Minute, "abort the scan after this long")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(2)
+	}
+	logger.InitLogger(cfg.Server.Debug)
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to database", zap.Error(err))
+	}
+	defer database.CloseDB(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	integrityService := service.NewIntegrityService(repository.NewPostgresIntegrityRepository(db))
+	report, err := integrityService.Check(ctx, *repair)
+	if err != nil {
+		logger.Fatal("Integrity check failed", zap.Error(err))
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		for _, issue := range report.Issues {
+			status := "FOUND   "
+			if issue.Repaired {
+				status = "REPAIRED"
+			}
+			fmt.Printf("%s %-22s %s %d: %s\n", status, issue.Check, issue.ResourceType, issue.ResourceID, issue.Detail)
+		}
+		fmt.Printf("%d issue(s), %d repaired, %d remaining\n", len(report.Issues), report.Repaired, report.Unrepaired())
+	}
+
+	if report.Unrepaired() > 0 {
+		database.CloseDB(db)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,276 @@
+// Command gen scaffolds code following the repository's layering.
+//
+// Usage:
+//
+//	go run ./cmd/gen resource <name> [field:type ...]
+//
+// For example "go run ./cmd/gen resource gift_card code:string balance:float64" writes the
+// model and DTOs, repository, service, handler and route registration for a user-owned
+// GiftCard, then prints the module entry to add to pkg/app. Supported field types are
+// string, int, int64, uint, float64, bool and time.Time. Run it from the repository root.
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// fieldTypes are the Go types a generated field may have
+var fieldTypes = map[string]bool{
+	"string": true, "int": true, "int64": true, "uint": true, "float64": true, "bool": true, "time.Time": true,
+}
+
+// reservedFields are set by the generated code itself
+var reservedFields = map[string]bool{
+	"ID": true, "UserID": true, "CreatedAt": true, "UpdatedAt": true, "DeletedAt": true, "Model": true,
+}
+
+// initialisms are written in upper case in Go identifiers
+var initialisms = map[string]bool{"id": true, "url": true, "sku": true, "api": true, "http": true, "ip": true, "json": true}
+
+// Field is a user-defined field of the generated resource
+type Field struct {
+	Name     string // Go name, e.g. "UnitPrice"
+	Column   string // SQL column, e.g. "unit_price"
+	JSON     string // JSON key, e.g. "unitPrice"
+	Type     string
+	Required bool
+}
+
+// Resource holds the naming variants used by the templates
+type Resource struct {
+	Name        string // GiftCard
+	Var         string // giftCard
+	Plural      string // GiftCards
+	Table       string // gift_cards
+	Path        string // gift-cards
+	Human       string // gift card
+	HumanPlural string // gift cards
+	Title       string // Gift card
+	Fields      []*Field
+}
+
+// output is one generated file
+type output struct {
+	template string
+	path     string
+	appendTo bool // Append to an existing file instead of creating one
+}
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "print the generated code instead of writing files")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: go run ./cmd/gen [-dry-run] resource <name> [field:type ...]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) < 2 || args[0] != "resource" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if _, err := os.Stat("go.mod"); err != nil && !*dryRun {
+		exitf("run gen from the repository root (go.mod not found)")
+	}
+
+	res, err := newResource(args[1], args[2:])
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	outputs := outputsFor(args[1])
+	tmpl := template.Must(template.ParseFS(templateFS, "templates/*.tmpl"))
+
+	// Render everything before writing anything, so a failure leaves the tree untouched
+	rendered, err := render(tmpl, res, outputs, *dryRun)
+	if err != nil {
+		exitf("%v", err)
+	}
+
+	for i, out := range outputs {
+		if *dryRun {
+			fmt.Printf("// ---- %s ----\n%s\n", out.path, rendered[i])
+			continue
+		}
+		if err := os.WriteFile(out.path, rendered[i], 0o644); err != nil {
+			exitf("failed to write %s: %v", out.path, err)
+		}
+		fmt.Println("wrote", out.path)
+	}
+
+	fmt.Println()
+	if err := tmpl.ExecuteTemplate(os.Stdout, "module.txt.tmpl", res); err != nil {
+		exitf("failed to render module snippet: %v", err)
+	}
+}
+
+// outputsFor lists the files generated for the resource name, relative to the repository root
+func outputsFor(name string) []output {
+	snake := strings.Join(splitWords(name), "_")
+	return []output{
+		{template: "model.go.tmpl", path: filepath.Join("internal", "models", snake+".go")},
+		{template: "repository.go.tmpl", path: filepath.Join("internal", "repository", snake+"_repository.go")},
+		{template: "service.go.tmpl", path: filepath.Join("internal", "service", snake+"_service.go")},
+		{template: "handler.go.tmpl", path: filepath.Join("internal", "handler", snake+"_handler.go")},
+		{template: "routes.go.tmpl", path: filepath.Join("internal", "router", "routes.go"), appendTo: true},
+	}
+}
+
+// render renders and formats every output of res, reading the files appended to from the working directory.
+// With dryRun, files that can't be read are appended to as if empty.
+func render(tmpl *template.Template, res *Resource, outputs []output, dryRun bool) ([][]byte, error) {
+	rendered := make([][]byte, len(outputs))
+	for i, out := range outputs {
+		var buf bytes.Buffer
+		if out.appendTo {
+			existing, err := os.ReadFile(out.path)
+			if err != nil && !dryRun {
+				return nil, fmt.Errorf("failed to read %s: %w", out.path, err)
+			}
+			buf.Write(existing)
+		} else if _, err := os.Stat(out.path); err == nil {
+			return nil, fmt.Errorf("%s already exists", out.path)
+		}
+		if err := tmpl.ExecuteTemplate(&buf, out.template, res); err != nil {
+			return nil, fmt.Errorf("failed to render %s: %w", out.template, err)
+		}
+		src, err := format.Source(buf.Bytes())
+		if err != nil {
+			return nil, fmt.Errorf("generated %s does not parse: %w", out.path, err)
+		}
+		rendered[i] = src
+	}
+	return rendered, nil
+}
+
+// newResource derives the naming variants of a resource and parses its field specs
+func newResource(name string, specs []string) (*Resource, error) {
+	words := splitWords(name)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("invalid resource name %q", name)
+	}
+	plural := append(append([]string{}, words[:len(words)-1]...), pluralize(words[len(words)-1]))
+
+	res := &Resource{
+		Name:        pascal(words),
+		Var:         camel(words),
+		Plural:      pascal(plural),
+		Table:       strings.Join(plural, "_"),
+		Path:        strings.Join(plural, "-"),
+		Human:       strings.Join(words, " "),
+		HumanPlural: strings.Join(plural, " "),
+	}
+	res.Title = strings.ToUpper(res.Human[:1]) + res.Human[1:]
+	if token.IsKeyword(res.Var) {
+		return nil, fmt.Errorf("resource name %q is a Go keyword", name)
+	}
+
+	seen := map[string]bool{}
+	for _, spec := range specs {
+		fieldName, fieldType, ok := strings.Cut(spec, ":")
+		if !ok || !fieldTypes[fieldType] {
+			return nil, fmt.Errorf("invalid field %q, expected name:type with type one of string, int, int64, uint, float64, bool, time.Time", spec)
+		}
+		fw := splitWords(fieldName)
+		if len(fw) == 0 {
+			return nil, fmt.Errorf("invalid field name %q", fieldName)
+		}
+		f := &Field{
+			Name:     pascal(fw),
+			Column:   strings.Join(fw, "_"),
+			JSON:     camel(fw),
+			Type:     fieldType,
+			Required: fieldType == "string", // Zero numbers and false are legitimate values, so only strings are required
+		}
+		if reservedFields[f.Name] || seen[f.Name] {
+			return nil, fmt.Errorf("field %q is reserved or duplicated", fieldName)
+		}
+		seen[f.Name] = true
+		res.Fields = append(res.Fields, f)
+	}
+	if len(res.Fields) == 0 {
+		return nil, fmt.Errorf("at least one field is required, e.g. name:string")
+	}
+	return res, nil
+}
+
+// splitWords splits snake_case, kebab-case, camelCase and PascalCase names into lower-case words
+func splitWords(s string) []string {
+	var words []string
+	var cur []rune
+	flush := func() {
+		if len(cur) > 0 {
+			words = append(words, strings.ToLower(string(cur)))
+			cur = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case r == '_' || r == '-' || r == ' ':
+			flush()
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			return nil
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			cur = append(cur, r)
+		default:
+			cur = append(cur, r)
+		}
+	}
+	flush()
+	if len(words) > 0 && !unicode.IsLetter([]rune(words[0])[0]) {
+		return nil
+	}
+	return words
+}
+
+// pascal joins words into a PascalCase Go identifier
+func pascal(words []string) string {
+	var b strings.Builder
+	for _, w := range words {
+		if initialisms[w] {
+			b.WriteString(strings.ToUpper(w))
+		} else {
+			b.WriteString(strings.ToUpper(w[:1]) + w[1:])
+		}
+	}
+	return b.String()
+}
+
+// camel joins words into a camelCase Go identifier
+func camel(words []string) string {
+	return words[0] + pascal(words[1:])
+}
+
+// pluralize applies the common English plural rules to a lower-case word
+func pluralize(w string) string {
+	switch {
+	case strings.HasSuffix(w, "y") && len(w) > 1 && !strings.ContainsRune("aeiou", rune(w[len(w)-2])):
+		return w[:len(w)-1] + "ies"
+	case strings.HasSuffix(w, "s"), strings.HasSuffix(w, "x"), strings.HasSuffix(w, "z"),
+		strings.HasSuffix(w, "ch"), strings.HasSuffix(w, "sh"):
+		return w + "es"
+	default:
+		return w + "s"
+	}
+}
+
+// exitf prints an error and exits
+func exitf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "gen: "+format+"\n", args...)
+	os.Exit(1)
+}
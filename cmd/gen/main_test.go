@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"text/template"
+)
+
+// TestGeneratedResourceBuilds renders a resource against the current tree and compiles the internal packages
+// with the generated files in place, through a build overlay so the tree itself is left untouched
+func TestGeneratedResourceBuilds(t *testing.T) {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not found")
+	}
+	t.Chdir(filepath.Join("..", ".."))
+
+	res, err := newResource("gift_card", []string{"code:string", "balance:float64", "uses:int", "active:bool", "expires_at:time.Time"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	outputs := outputsFor("gift_card")
+	rendered, err := render(template.Must(template.ParseFS(templateFS, "templates/*.tmpl")), res, outputs, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	overlay := struct{ Replace map[string]string }{Replace: map[string]string{}}
+	for i, out := range outputs {
+		path, err := filepath.Abs(out.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		generated := filepath.Join(dir, strconv.Itoa(i)+".go")
+		if err := os.WriteFile(generated, rendered[i], 0o644); err != nil {
+			t.Fatal(err)
+		}
+		overlay.Replace[path] = generated
+	}
+	overlayPath := filepath.Join(dir, "overlay.json")
+	data, err := json.Marshal(overlay)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(overlayPath, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goBin, "vet", "-overlay", overlayPath, "./internal/...")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated code doesn't build: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,6 @@
+//go:build !go_json
+
+package main
+
+// ginEncoder is the encoder gin was built with
+const ginEncoder = "encoding/json"
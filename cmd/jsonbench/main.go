@@ -0,0 +1,130 @@
+// Command jsonbench measures how fast product listings are written as JSON, with encoding/json and with the
+// encoder gin was built with, so the go_json build tag can be judged on real response shapes.
+//
+// Usage:
+//
+//	go run ./cmd/jsonbench [-products 1000] [-json]
+//	go run -tags=go_json ./cmd/jsonbench [-products 1000] [-json]
+//
+// Without the tag both rows measure encoding/json; with it, the gin row measures github.com/goccy/go-json.
+// Build the server with the same tag (go build -tags=go_json ./cmd) to serve responses with it.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gotemplate/internal/models"
+
+	"github.com/gin-gonic/gin/render"
+)
+
+// result is the measurement of one encoder
+type result struct {
+	Encoder     string  `json:"encoder"`
+	NsPerOp     int64   `json:"nsPerOp"`
+	MBPerSecond float64 `json:"mbPerSecond"`
+	AllocsPerOp int64   `json:"allocsPerOp"`
+	BytesPerOp  int64   `json:"bytesPerOp"`
+}
+
+func main() {
+	products := flag.Int("products", 1000, "products in the listing encoded")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *products <= 0 {
+		fmt.Println("-products must be positive")
+		os.Exit(2)
+	}
+	listing := productListing(*products)
+	size, err := json.Marshal(listing)
+	if err != nil {
+		fmt.Printf("Failed to encode the listing: %v\n", err)
+		os.Exit(2)
+	}
+
+	results := []result{
+		measure("encoding/json", int64(len(size)), func() error {
+			return json.NewEncoder(io.Discard).Encode(listing)
+		}),
+		measure("gin ("+ginEncoder+")", int64(len(size)), func() error {
+			return render.JSON{Data: listing}.Render(discardWriter{})
+		}),
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(results)
+		return
+	}
+	fmt.Printf("%d products, %d bytes\n", *products, len(size))
+	fmt.Printf("%-40s %12s %10s %10s %12s\n", "ENCODER", "NS/OP", "MB/S", "ALLOCS/OP", "BYTES/OP")
+	for _, r := range results {
+		fmt.Printf("%-40s %12d %10.1f %10d %12d\n", r.Encoder, r.NsPerOp, r.MBPerSecond, r.AllocsPerOp, r.BytesPerOp)
+	}
+}
+
+// measure benchmarks encode, which writes a listing of size bytes
+func measure(encoder string, size int64, encode func() error) result {
+	var failed error
+	b := testing.Benchmark(func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(size)
+		for i := 0; i < b.N; i++ {
+			if err := encode(); err != nil {
+				failed = err
+				b.FailNow()
+			}
+		}
+	})
+	if failed != nil {
+		fmt.Printf("Encoding with %s failed: %v\n", encoder, failed)
+		os.Exit(1)
+	}
+	return result{
+		Encoder:     encoder,
+		NsPerOp:     b.NsPerOp(),
+		MBPerSecond: float64(size) * float64(b.N) / b.T.Seconds() / 1e6,
+		AllocsPerOp: b.AllocsPerOp(),
+		BytesPerOp:  b.AllocedBytesPerOp(),
+	}
+}
+
+// discardWriter is a response writer dropping the body, so only encoding is measured
+type discardWriter struct{}
+
+func (discardWriter) Header() http.Header         { return http.Header{} }
+func (discardWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (discardWriter) WriteHeader(int)             {}
+
+// productListing returns n product responses shaped like those of GET /products, owners included
+func productListing(n int) []*models.ProductResponse {
+	now := time.Now()
+	listing := make([]*models.ProductResponse, n)
+	for i := range listing {
+		p := &models.Product{
+			Name:        "Product " + strconv.Itoa(i),
+			Description: strings.Repeat("A fairly ordinary product description. ", 1+i%4),
+			Price:       float64(100+i%5000) / 100,
+			UserID:      uint(1 + i%50),
+		}
+		p.ID = uint(i + 1)
+		p.CreatedAt = now.Add(-time.Duration(i) * time.Minute)
+		p.UpdatedAt = now
+		p.User.ID = p.UserID
+		p.User.Username = "user" + strconv.Itoa(int(p.UserID))
+		p.User.Email = p.User.Username + "@example.com"
+		listing[i] = models.NewProductResponse(p)
+	}
+	return listing
+}
@@ -0,0 +1,326 @@
+// Command loadtest drives a login and product CRUD scenario against a running deployment
+// and reports latency percentiles per operation.
+//
+// Usage:
+//
+//	go run ./cmd/loadtest [-target http://localhost:8080] [-rps 20] [-duration 30s] [-login-ratio 0.1] [-json]
+//
+// Each iteration creates, reads, updates and deletes one product; a share of iterations log in first.
+// Iterations start at a fixed rate whether or not earlier ones finished (open loop), so a slow
+// server shows up as latency rather than as a lower request rate. It creates its own user and
+// exits with status 1 if any request failed.
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operations of the scenario, in report order
+var operations = []string{"login", "create", "get", "update", "delete"}
+
+func main() {
+	target := flag.String("target", "http://localhost:8080", "base URL of the deployment")
+	rps := flag.Float64("rps", 20, "scenario iterations started per second")
+	duration := flag.Duration("duration", 30*time.Second, "how long to start iterations for")
+	loginRatio := flag.Float64("login-ratio", 0.1, "share of iterations that log in first (logins are deliberately slow)")
+	maxInFlight := flag.Int("max-in-flight", 500, "iterations running at once before new ones are dropped")
+	timeout := flag.Duration("timeout", 10*time.Second, "timeout of a single request")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *rps <= 0 || *duration <= 0 {
+		fmt.Println("-rps and -duration must be positive")
+		os.Exit(2)
+	}
+
+	c := &client{
+		baseURL: strings.TrimRight(*target, "/") + "/api/v1",
+		http:    &http.Client{Timeout: *timeout},
+	}
+	email, password, err := c.setup()
+	if err != nil {
+		fmt.Printf("Setup failed: %v\n", err)
+		os.Exit(2)
+	}
+
+	rec := newRecorder()
+	slots := make(chan struct{}, *maxInFlight)
+	var wg sync.WaitGroup
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / *rps))
+	defer ticker.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), *duration)
+	defer cancel()
+
+	started := time.Now()
+	dropped := 0
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+		select {
+		case slots <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+			c.iteration(rec, email, password, rand.Float64() < *loginRatio)
+		}()
+	}
+	wg.Wait()
+
+	report := rec.report(time.Since(started), dropped)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		report.print()
+	}
+
+	if report.Errors > 0 {
+		os.Exit(1)
+	}
+}
+
+// client calls the API of the deployment under test
+type client struct {
+	baseURL string
+	http    *http.Client
+	token   string // Token of the setup login, used by iterations that don't log in themselves
+}
+
+// setup registers a dedicated load test user and logs it in
+func (c *client) setup() (email string, password string, err error) {
+	suffix := fmt.Sprintf("%d", time.Now().UnixNano())
+	email = "loadtest-" + suffix + "@example.com"
+	password = "loadtest-" + suffix
+	status, _, err := c.do(http.MethodPost, "/register", "", map[string]interface{}{
+		"username": "loadtest-" + suffix,
+		"email":    email,
+		"password": password,
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if status != http.StatusCreated {
+		return "", "", fmt.Errorf("registration returned %d", status)
+	}
+	c.token, err = c.login(email, password)
+	return email, password, err
+}
+
+// login returns a token for the user
+func (c *client) login(email, password string) (string, error) {
+	status, body, err := c.do(http.MethodPost, "/login", "", map[string]string{"email": email, "password": password})
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("login returned %d", status)
+	}
+	var res struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(body, &res); err != nil || res.Token == "" {
+		return "", fmt.Errorf("login returned no token")
+	}
+	return res.Token, nil
+}
+
+// iteration runs the scenario once, recording every step. It stops at the first failed step.
+func (c *client) iteration(rec *recorder, email, password string, withLogin bool) {
+	token := c.token
+	if withLogin {
+		start := time.Now()
+		t, err := c.login(email, password)
+		rec.add("login", time.Since(start), err)
+		if err != nil {
+			return
+		}
+		token = t
+	}
+
+	var id uint
+	step := func(op, method, path string, payload interface{}, want int) bool {
+		start := time.Now()
+		status, body, err := c.do(method, path, token, payload)
+		if err == nil && status != want {
+			err = fmt.Errorf("%s %s returned %d", method, path, status)
+		}
+		if err == nil && op == "create" {
+			var res struct {
+				ID uint `json:"id"`
+			}
+			if err = json.Unmarshal(body, &res); err == nil && res.ID == 0 {
+				err = fmt.Errorf("create returned no product ID")
+			}
+			id = res.ID
+		}
+		rec.add(op, time.Since(start), err)
+		return err == nil
+	}
+
+	product := map[string]interface{}{"name": "Load test product", "description": "Created by cmd/loadtest", "price": 9.99}
+	if !step("create", http.MethodPost, "/products", product, http.StatusCreated) {
+		return
+	}
+	path := fmt.Sprintf("/products/%d", id)
+	if !step("get", http.MethodGet, path, nil, http.StatusOK) {
+		return
+	}
+	if !step("update", http.MethodPut, path, map[string]interface{}{"price": 19.99}, http.StatusOK) {
+		return
+	}
+	step("delete", http.MethodDelete, path, nil, http.StatusNoContent)
+}
+
+// do sends a JSON request and returns the status and body of the response
+func (c *client) do(method, path, token string, payload interface{}) (int, []byte, error) {
+	var body io.Reader
+	if payload != nil {
+		b, err := json.Marshal(payload)
+		if err != nil {
+			return 0, nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, body)
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := c.http.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	return res.StatusCode, b, err
+}
+
+// recorder collects latencies and errors per operation
+type recorder struct {
+	mu        sync.Mutex
+	latencies map[string][]time.Duration
+	errors    map[string]int
+	samples   map[string]string // First error message per operation
+}
+
+func newRecorder() *recorder {
+	return &recorder{latencies: map[string][]time.Duration{}, errors: map[string]int{}, samples: map[string]string{}}
+}
+
+func (r *recorder) add(op string, latency time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.latencies[op] = append(r.latencies[op], latency)
+	if err != nil {
+		r.errors[op]++
+		if _, ok := r.samples[op]; !ok {
+			r.samples[op] = err.Error()
+		}
+	}
+}
+
+// OperationStats are the results of one operation
+type OperationStats struct {
+	Operation   string  `json:"operation"`
+	Requests    int     `json:"requests"`
+	Errors      int     `json:"errors"`
+	FirstError  string  `json:"firstError,omitempty"`
+	P50Ms       float64 `json:"p50Ms"`
+	P90Ms       float64 `json:"p90Ms"`
+	P99Ms       float64 `json:"p99Ms"`
+	MaxMs       float64 `json:"maxMs"`
+	RequestsSec float64 `json:"requestsPerSecond"`
+}
+
+// Report is the outcome of a load test run
+type Report struct {
+	Elapsed    string            `json:"elapsed"`
+	Requests   int               `json:"requests"`
+	Errors     int               `json:"errors"`
+	Dropped    int               `json:"dropped"` // Iterations not started because max-in-flight was reached
+	Operations []*OperationStats `json:"operations"`
+}
+
+func (r *recorder) report(elapsed time.Duration, dropped int) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	report := &Report{Elapsed: elapsed.Round(time.Millisecond).String(), Dropped: dropped}
+	for _, op := range operations {
+		latencies := r.latencies[op]
+		if len(latencies) == 0 {
+			continue
+		}
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats := &OperationStats{
+			Operation:   op,
+			Requests:    len(latencies),
+			Errors:      r.errors[op],
+			FirstError:  r.samples[op],
+			P50Ms:       percentile(latencies, 0.50),
+			P90Ms:       percentile(latencies, 0.90),
+			P99Ms:       percentile(latencies, 0.99),
+			MaxMs:       ms(latencies[len(latencies)-1]),
+			RequestsSec: float64(len(latencies)) / elapsed.Seconds(),
+		}
+		report.Requests += stats.Requests
+		report.Errors += stats.Errors
+		report.Operations = append(report.Operations, stats)
+	}
+	return report
+}
+
+func (r *Report) print() {
+	fmt.Printf("%-8s %9s %7s %9s %9s %9s %9s %9s\n", "op", "requests", "errors", "req/s", "p50 ms", "p90 ms", "p99 ms", "max ms")
+	for _, s := range r.Operations {
+		fmt.Printf("%-8s %9d %7d %9.1f %9.1f %9.1f %9.1f %9.1f\n", s.Operation, s.Requests, s.Errors, s.RequestsSec, s.P50Ms, s.P90Ms, s.P99Ms, s.MaxMs)
+	}
+	fmt.Printf("%d request(s) in %s, %d error(s), %d iteration(s) dropped\n", r.Requests, r.Elapsed, r.Errors, r.Dropped)
+	for _, s := range r.Operations {
+		if s.FirstError != "" {
+			fmt.Printf("first %s error: %s\n", s.Operation, s.FirstError)
+		}
+	}
+}
+
+// percentile returns the nearest-rank percentile of sorted latencies in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return ms(sorted[i])
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
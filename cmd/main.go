@@ -4,14 +4,8 @@ import (
 	"context"
 	"fmt"
 	"gotemplate/config"
-	"gotemplate/internal/handler"
-	"gotemplate/internal/repository"
-	"gotemplate/internal/router"
-	"gotemplate/internal/service"
-	"gotemplate/pkg/auth"
-	"gotemplate/pkg/database"
+	"gotemplate/pkg/app"
 	"gotemplate/pkg/logger"
-	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
@@ -46,57 +40,31 @@ func main() {
 	}()
 	logger.Info("Application starting...", zap.Bool("debug_mode", cfg.Server.Debug))
 
-	// Initialize database connection
-	db, err := database.NewPostgresDB(&cfg.Database)
+	// Wire the application (database, repositories, services, handlers, router); see pkg/app
+	application, err := app.New(cfg)
 	if err != nil {
-		logger.Fatal("Failed to connect to database", zap.Error(err))
+		logger.Fatal("Failed to initialize application", zap.Error(err))
 	}
 
-	fmt.Println("Database auto-migration completed successfully!")
-	defer database.CloseDB(db)
-
-	// --- Dependency Injection ---
-	// Instantiate Repositories
-	userRepo := repository.NewPostgresUserRepository(db)
-	productRepo := repository.NewPostgresProductRepository(db)
-
-	// Instantiate JWT Manager
-	jwtManager := auth.NewJWTManager(&cfg.JWT)
-
-	// Instantiate Services with their respective repositories and managers
-	userService := service.NewUserService(userRepo, jwtManager)
-	productService := service.NewProductService(productRepo)
-
-	// Instantiate Handlers with their respective services
-	userHandler := handler.NewUserHandler(userService)
-	productHandler := handler.NewProductHandler(productService)
-
-	// Setup Gin Router with all handlers and middleware
-	r := router.SetupRouter(userHandler, productHandler, jwtManager, cfg.Server.Debug)
-
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:         ":" + cfg.Server.Port,   // Server address (e.g., ":8080")
-		Handler:      r,                       // Gin router as the handler
-		ReadTimeout:  cfg.Server.ReadTimeout,  // Timeout for reading request body
-		WriteTimeout: cfg.Server.WriteTimeout, // Timeout for writing response body
-		IdleTimeout:  time.Minute * 2,         // Timeout for idle connections
+	// Verify config, JWT key, database and schema before binding the port; the checklist goes to stdout
+	if err := application.SelfCheck(context.Background(), os.Stdout); err != nil {
+		logger.Fatal("Startup self-check failed", zap.Error(err))
 	}
 
-	// Start the server in a goroutine so it doesn't block the main thread
-	go func() {
-		logger.Info("Server listening", zap.String("port", cfg.Server.Port))
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Server failed to listen", zap.Error(err))
-		}
-	}()
+	if err := application.Start(); err != nil {
+		logger.Fatal("Server failed to listen", zap.Error(err))
+	}
 
 	// --- Graceful Shutdown ---
 	// Create a channel to listen for OS signals
 	quit := make(chan os.Signal, 1)
 	// Trap OS signals: Interrupt (Ctrl+C) and Terminate
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit // Block until a signal is received
+	select {
+	case <-quit: // Block until a signal is received
+	case err := <-application.Err():
+		logger.Error("Server stopped unexpectedly", zap.Error(err))
+	}
 
 	logger.Info("Shutting down server...")
 
@@ -105,7 +73,7 @@ func main() {
 	defer cancel()
 
 	// Shutdown the HTTP server gracefully
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := application.Shutdown(ctx); err != nil {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
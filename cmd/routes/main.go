@@ -0,0 +1,73 @@
+// Command routes lists every route of the application with its middleware chain and the roles and token
+// scopes it requires, so authorization coverage can be reviewed. GET /api/v1/admin/routes serves the same table.
+//
+// Usage:
+//
+//	go run ./cmd/routes [-json]
+//
+// The application is wired like the server, so it needs the configuration and the database. It exits with
+// status 1 when a route runs a middleware more than once.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/pkg/app"
+	"gotemplate/pkg/logger"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the table as JSON")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(2)
+	}
+	logger.InitLogger(cfg.Server.Debug)
+
+	application, err := app.New(cfg)
+	if err != nil {
+		logger.Fatal("Failed to initialize application", zap.Error(err))
+	}
+	routes := application.Routes()
+	_ = application.Shutdown(context.Background()) // Never started; closes the database
+
+	duplicates := 0
+	for _, route := range routes {
+		if len(route.Duplicates) > 0 {
+			duplicates++
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(routes)
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "METHOD\tPATH\tACCESS\tSCOPES\tMIDDLEWARE\tHANDLER")
+		for _, route := range routes {
+			middleware := strings.Join(route.Middleware, " > ")
+			if len(route.Duplicates) > 0 {
+				middleware += " (duplicate: " + strings.Join(route.Duplicates, ", ") + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", route.Method, route.Path, route.Access, strings.Join(route.Scopes, ","), middleware, route.Handler)
+		}
+		_ = w.Flush()
+		fmt.Printf("%d route(s), %d with duplicate middleware\n", len(routes), duplicates)
+	}
+
+	if duplicates > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/database"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// policies is how each table is copied to staging. Tables holding credentials or data derived from them are
+// skipped; personal data is replaced with fakes; everything else is business data copied as is.
+func policies(f *faker) map[string]database.ClonePolicy {
+	return map[string]database.ClonePolicy{
+		"users": {Rewrite: map[string]database.CloneRewrite{
+			"username": f.username,
+			"email":    f.email,
+			"password": func(string) string { return models.PasswordAnonymized }, // Production passwords never work on staging; register staging accounts to log in
+		}},
+		"addresses": {Rewrite: map[string]database.CloneRewrite{
+			"recipient":   f.name,
+			"line1":       f.street,
+			"line2":       f.line2,
+			"city":        f.city,
+			"postal_code": f.digits,
+			"phone":       f.digits,
+		}},
+		"comments":           {Rewrite: map[string]database.CloneRewrite{"body": f.text}},
+		"reports":            {Rewrite: map[string]database.CloneRewrite{"details": f.text, "resolution_note": f.text}},
+		"audit_events":       {Rewrite: map[string]database.CloneRewrite{"metadata": models.AuditMetadataWithoutPII}},
+		"processing_records": {Rewrite: map[string]database.CloneRewrite{"detail": func(string) string { return "" }}},
+
+		"login_attempts":         {Skip: true}, // Keyed by email
+		"personal_access_tokens": {Skip: true}, // Credentials
+		"operations":             {Skip: true}, // Results may hold exported personal data
+		"audit_forward_cursors":  {Skip: true}, // Staging forwards to its own sinks, if any
+		"activity_entries":       {Skip: true}, // Personal data, deleted with the user by anonymization
+		"saved_searches":         {Skip: true}, // Personal data, deleted with the user by anonymization
+
+		"api_usage":           {},
+		"announcements":       {},
+		"bundles":             {},
+		"bundle_items":        {},
+		"deprecation_usages":  {},
+		"product_changes":     {},
+		"product_permissions": {},
+		"product_views":       {},
+		"products":            {},
+		"report_templates":    {},
+	}
+}
+
+// Words fakes are picked from
+var (
+	fakeFirstNames = []string{"Alex", "Sam", "Jordan", "Taylor", "Morgan", "Casey", "Riley", "Jamie", "Avery", "Quinn", "Robin", "Charlie", "Dana", "Kim", "Lee", "Noa"}
+	fakeLastNames  = []string{"Smith", "Garcia", "Müller", "Kowalski", "Haddad", "Tanaka", "Okafor", "Novak", "Silva", "Jensen", "Rossi", "Nguyen", "Dubois", "Cohen", "Patel", "Ivanova"}
+	fakeStreets    = []string{"Maple", "Oak", "Station", "Harbour", "Mill", "Church", "Park", "River", "Hill", "Market", "Bridge", "Garden"}
+	fakeCities     = []string{"Springfield", "Riverton", "Lakeside", "Fairview", "Greenville", "Oakdale", "Milford", "Ashford"}
+	fakeWords      = strings.Fields("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua")
+)
+
+// faker derives fake values from original ones with a keyed hash: the same value always gets the same fake, and
+// distinct values get distinct fakes wherever that matters, e.g. unique emails
+type faker struct {
+	key []byte
+}
+
+func newFaker(key []byte) *faker {
+	return &faker{key: key}
+}
+
+// sum is the keyed hash of a value, separated by kind so that equal values of different kinds differ
+func (f *faker) sum(kind, value string) []byte {
+	mac := hmac.New(sha256.New, f.key)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// pick chooses one of words by the nth 16-bit group of sum
+func pick(words []string, sum []byte, n int) string {
+	return words[int(binary.BigEndian.Uint16(sum[2*n:]))%len(words)]
+}
+
+func (f *faker) username(value string) string {
+	return "user-" + hex.EncodeToString(f.sum("username", value)[:8])
+}
+
+// email fakes an address on the .invalid domain, which can never receive mail
+func (f *faker) email(value string) string {
+	return "user-" + hex.EncodeToString(f.sum("email", strings.ToLower(value))[:8]) + "@staging.invalid"
+}
+
+func (f *faker) name(value string) string {
+	sum := f.sum("name", value)
+	return pick(fakeFirstNames, sum, 0) + " " + pick(fakeLastNames, sum, 1)
+}
+
+func (f *faker) street(value string) string {
+	sum := f.sum("street", value)
+	return pick(fakeStreets, sum, 0) + " Street " + strconv.Itoa(1+int(sum[2])%200)
+}
+
+// line2 keeps empty second lines empty
+func (f *faker) line2(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "Apt " + strconv.Itoa(1+int(f.sum("line2", value)[0])%100)
+}
+
+func (f *faker) city(value string) string {
+	return pick(fakeCities, f.sum("city", value), 0)
+}
+
+// digits replaces every digit and letter with another one, keeping the format of postal codes and phone numbers
+func (f *faker) digits(value string) string {
+	sum := f.sum("digits", value)
+	var sb strings.Builder
+	i := 0
+	for _, r := range value {
+		b := sum[i%len(sum)]
+		switch {
+		case unicode.IsDigit(r):
+			sb.WriteByte('0' + b%10)
+			i++
+		case unicode.IsLetter(r):
+			sb.WriteByte('A' + b%26)
+			i++
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// text replaces free text with filler of about the same length, keeping empty text empty
+func (f *faker) text(value string) string {
+	length := utf8.RuneCountInString(value)
+	if length == 0 {
+		return ""
+	}
+	sum := f.sum("text", value)
+	var sb strings.Builder
+	for i := 0; sb.Len() < length; i++ {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(fakeWords[int(sum[i%len(sum)]+byte(i/len(sum)))%len(fakeWords)])
+	}
+	return sb.String()[:length]
+}
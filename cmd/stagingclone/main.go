@@ -0,0 +1,116 @@
+// Command stagingclone copies the configured database into a staging database, replacing personal data with
+// deterministic fakes on the way, so staging runs on realistic data without holding anyone's personal data.
+//
+// Usage:
+//
+//	STAGING_CLONE_SECRET=... go run ./cmd/stagingclone -target-host staging-db [-target-port 5432] [-target-dbname gotemplate] [-json]
+//
+// The source is the database of the configuration, as for the application. The target connection defaults to
+// the same settings, with the password taken from STAGING_CLONE_DB_PASSWORD when set; the target must be a
+// different database, whose schema was created by starting the application once against it. Its tables are
+// emptied and refilled in one transaction.
+//
+// Fakes are derived from the original values with STAGING_CLONE_SECRET, so the same email becomes the same fake
+// in every clone made with the same secret, and the fakes can't be reversed without it. Keep the secret out of
+// staging. Every table needs an anonymization policy, see policies: the clone fails on tables without one.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/logger"
+	"os"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// minSecretLength keeps the fakes from being reversed by guessing the secret
+const minSecretLength = 16
+
+func main() {
+	targetHost := flag.String("target-host", "", "host of the staging database (required)")
+	targetPort := flag.String("target-port", "", "port of the staging database, defaults to the source's")
+	targetUser := flag.String("target-user", "", "user of the staging database, defaults to the source's")
+	targetDBName := flag.String("target-dbname", "", "name of the staging database, defaults to the source's")
+	targetSSLMode := flag.String("target-sslmode", "", "sslmode of the staging database, defaults to the source's")
+	asJSON := flag.Bool("json", false, "print the copied tables as JSON")
+	timeout := flag.Duration("timeout", time.Hour, "abort the clone after this long")
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Printf("Error loading configuration: %v\n", err)
+		os.Exit(2)
+	}
+	logger.InitLogger(cfg.Server.Debug)
+
+	secret := os.Getenv("STAGING_CLONE_SECRET")
+	if len(secret) < minSecretLength {
+		fmt.Printf("STAGING_CLONE_SECRET must be set to at least %d characters\n", minSecretLength)
+		os.Exit(2)
+	}
+	target := cfg.Database
+	target.Host = *targetHost
+	target.Port = orDefault(*targetPort, target.Port)
+	target.User = orDefault(*targetUser, target.User)
+	target.DBName = orDefault(*targetDBName, target.DBName)
+	target.SSLMode = orDefault(*targetSSLMode, target.SSLMode)
+	target.Password = orDefault(os.Getenv("STAGING_CLONE_DB_PASSWORD"), target.Password)
+	if target.Host == "" {
+		fmt.Println("-target-host is required")
+		os.Exit(2)
+	}
+	if target.Host == cfg.Database.Host && target.Port == cfg.Database.Port && target.DBName == cfg.Database.DBName {
+		fmt.Println("The target is the source database; refusing to overwrite it")
+		os.Exit(2)
+	}
+
+	source, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		logger.Fatal("Failed to connect to source database", zap.Error(err))
+	}
+	defer database.CloseDB(source)
+	staging, err := database.NewPostgresDB(&target)
+	if err != nil {
+		logger.Fatal("Failed to connect to target database", zap.Error(err))
+	}
+	defer database.CloseDB(staging)
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	tables, err := database.Clone(ctx, source, staging, policies(newFaker([]byte(secret))))
+	if err != nil {
+		logger.Fatal("Staging clone failed", zap.Error(err))
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(tables)
+		return
+	}
+	var rows int64
+	for _, table := range tables {
+		if table.Skipped {
+			fmt.Printf("%-28s skipped\n", table.Name)
+			continue
+		}
+		fmt.Printf("%-28s %d rows\n", table.Name, table.Rows)
+		rows += table.Rows
+	}
+	fmt.Printf("%d table(s), %d rows copied into %s on %s\n", len(tables), rows, target.DBName, target.Host)
+}
+
+// orDefault returns value, or def when value is empty
+func orDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"log"
+	"net"
+	"net/url"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper" // Import viper for configuration management
@@ -14,6 +17,20 @@ type Config struct {
 	Server   ServerConfig
 	Database DatabaseConfig
 	JWT      JWTConfig
+	Auth     AuthConfig
+	Audit    AuditConfig
+	Cascade  CascadeConfig
+	Chaos    ChaosConfig
+	Canary   CanaryConfig
+	Shadow   ShadowConfig
+	Cost     CostLimitConfig
+	Search   SearchConfig
+	Backup   BackupConfig
+	Sync     SyncConfig
+	Stats    StatsConfig
+	Labels   LabelConfig
+	API      APIConfig
+	GeoIP    GeoIPConfig
 }
 
 // ServerConfig holds server-related configurations
@@ -22,6 +39,60 @@ type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	Debug        bool
+	// ReadHeaderTimeout bounds reading the headers of a request, so clients trickling them byte by byte
+	// (slowloris) can't hold connections; ReadTimeout still bounds the whole request
+	ReadHeaderTimeout time.Duration
+	// MaxHeaderBytes caps the request line and headers of a request, in bytes; larger ones get a 431
+	MaxHeaderBytes int
+	// KeepAlives lets clients reuse connections for several requests; IdleTimeout is how long an idle
+	// connection waits for the next one
+	KeepAlives  bool
+	IdleTimeout time.Duration
+	// RequestBudget is the default total time a request may take, shared by all the DB and external
+	// calls it makes; clients may ask for a different budget with X-Request-Timeout up to MaxRequestBudget
+	RequestBudget    time.Duration
+	MaxRequestBudget time.Duration
+	// MaxDecodedBodySize caps a gzip-encoded request body after decompression, in bytes
+	MaxDecodedBodySize int64
+	// MaxJSONBodySize caps the JSON request bodies handlers decode, in bytes, and MaxJSONDepth their nesting
+	MaxJSONBodySize int64
+	MaxJSONDepth    int
+	// StrictJSONRoutes lists the routes, as "METHOD /path" as registered or "*" for all, whose JSON bodies are
+	// refused when they hold fields the payload doesn't have; other routes ignore such fields
+	StrictJSONRoutes []string
+	// ReadOnly starts the instance in read-only mode; admins can switch it at runtime
+	ReadOnly bool
+	// TrustedProxies lists the load balancers and proxies, as IPs or CIDR ranges, whose Forwarded and
+	// X-Forwarded-For headers are believed. Empty trusts none: the client IP is the connection's address.
+	TrustedProxies []string
+	// AllowInsecure lists the settings, by key, whose insecure values the startup self-check accepts outside
+	// debug mode, e.g. "database.sslmode" on a private network; they are still warned about
+	AllowInsecure []string
+}
+
+// Validate checks the connection limits, the JSON body limits, the strict JSON routes and that the trusted proxies
+// are IPs or CIDR ranges
+func (c ServerConfig) Validate() error {
+	if c.ReadHeaderTimeout <= 0 || c.MaxHeaderBytes <= 0 || c.IdleTimeout <= 0 {
+		return fmt.Errorf("server.readHeaderTimeout, server.maxHeaderBytes and server.idleTimeout must be positive")
+	}
+	if c.ReadTimeout > 0 && c.ReadHeaderTimeout > c.ReadTimeout {
+		return fmt.Errorf("server.readHeaderTimeout must not exceed server.readTimeout")
+	}
+	if c.MaxJSONBodySize <= 0 || c.MaxJSONDepth <= 0 {
+		return fmt.Errorf("server.maxJSONBodySize and server.maxJSONDepth must be positive")
+	}
+	for i, route := range c.StrictJSONRoutes {
+		if method, path, ok := strings.Cut(route, " "); route != "*" && (!ok || method == "" || !strings.HasPrefix(path, "/")) {
+			return fmt.Errorf("server.strictJSONRoutes[%d]: %q is not \"*\" or \"METHOD /path\"", i, route)
+		}
+	}
+	for _, p := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(p); err != nil && net.ParseIP(p) == nil {
+			return fmt.Errorf("server.trustedProxies: %q is not an IP address or CIDR range", p)
+		}
+	}
+	return nil
 }
 
 // DatabaseConfig holds database-related configurations
@@ -32,12 +103,521 @@ type DatabaseConfig struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	// HealthCheckInterval is how often reachability is checked for the readiness endpoint
+	HealthCheckInterval time.Duration
+	// ExplainThreshold makes debug mode log the query plan of queries slower than this; zero disables it
+	ExplainThreshold time.Duration
+	// ApplicationName identifies the connections of this service in pg_stat_activity and the server log
+	ApplicationName string
+	// QueryTags is what SQL comments added to the queries of requests name, one of the QueryTags* values
+	QueryTags string
+	// PoolWaitThreshold is the average wait for a pooled connection that is logged as a warning, with the routes
+	// holding the connections; zero disables the warning
+	PoolWaitThreshold time.Duration
+	// UserCacheTTL is how long users looked up by ID, e.g. to authenticate requests, are cached; zero disables the cache
+	UserCacheTTL time.Duration
+}
+
+// What queries are tagged with
+const (
+	QueryTagsOff     = "off"
+	QueryTagsRoute   = "route"   // The route, e.g. "GET /api/v1/products/:id"
+	QueryTagsRequest = "request" // The route and the request ID. Every statement text is then unique, which defeats the driver's prepared statement cache
+)
+
+// maxApplicationNameLength is the longest application_name Postgres keeps (NAMEDATALEN - 1)
+const maxApplicationNameLength = 63
+
+// maxUserCacheTTL bounds how long a user deleted on another instance can still be served from the cache
+const maxUserCacheTTL = time.Minute
+
+// Validate checks the application name, pool wait threshold, user cache TTL and query tags
+func (c DatabaseConfig) Validate() error {
+	if c.ApplicationName == "" || len(c.ApplicationName) > maxApplicationNameLength || strings.ContainsAny(c.ApplicationName, " '\\\t\n") {
+		return fmt.Errorf("database.applicationName must be 1 to %d characters without spaces, quotes or backslashes", maxApplicationNameLength)
+	}
+	if c.PoolWaitThreshold < 0 {
+		return fmt.Errorf("database.poolWaitThreshold must not be negative")
+	}
+	if c.UserCacheTTL < 0 || c.UserCacheTTL > maxUserCacheTTL {
+		return fmt.Errorf("database.userCacheTTL must be between 0 and %s", maxUserCacheTTL)
+	}
+	switch c.QueryTags {
+	case QueryTagsOff, QueryTagsRoute, QueryTagsRequest:
+		return nil
+	default:
+		return fmt.Errorf("unknown database.queryTags %q", c.QueryTags)
+	}
 }
 
 // JWTConfig holds JWT-related configurations
 type JWTConfig struct {
 	SecretKey     string
 	ExpiresInHour time.Duration // Token expiration time in hours
+	// RoleExpiresIn overrides ExpiresInHour for the tokens of a role, e.g. {"admin": "1h"} for shorter-lived admin tokens
+	RoleExpiresIn map[string]time.Duration
+	// Audiences are put in the aud claim of issued tokens. When set, only tokens naming at least one of them are
+	// accepted, so tokens of another deployment sharing the secret are refused.
+	Audiences []string
+	// CustomClaims are added to every issued token, e.g. {"region": "eu"} for downstream services
+	CustomClaims map[string]string
+	// Issuer is put in the iss claim of issued tokens, and required of validated tokens
+	Issuer string
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat, for servers whose clocks drift slightly
+	Leeway time.Duration
+}
+
+// maxJWTLeeway bounds the leeway: more would noticeably extend the life of expired tokens
+const maxJWTLeeway = 5 * time.Minute
+
+// reservedClaims are set by the JWTManager and can't be overridden by custom claims
+var reservedClaims = []string{"iss", "sub", "aud", "exp", "nbf", "iat", "jti", "user_id", "role", "auth_time", "amr"}
+
+// Validate checks the issuer, leeway and per-role lifetimes, and that custom claims don't shadow the claims
+// tokens rely on
+func (c JWTConfig) Validate() error {
+	if c.Issuer == "" || c.Issuer == "*" {
+		return fmt.Errorf("jwt.issuer must name this service")
+	}
+	if c.Leeway < 0 || c.Leeway > maxJWTLeeway {
+		return fmt.Errorf("jwt.leeway must be between 0s and %s", maxJWTLeeway)
+	}
+	for role, ttl := range c.RoleExpiresIn {
+		if ttl <= 0 {
+			return fmt.Errorf("jwt.roleExpiresIn.%s must be positive", role)
+		}
+	}
+	for name := range c.CustomClaims {
+		for _, reserved := range reservedClaims {
+			if strings.EqualFold(name, reserved) {
+				return fmt.Errorf("jwt.customClaims can't set the reserved claim %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+// AuthConfig holds login hardening configurations
+type AuthConfig struct {
+	// LoginMinDuration pads every failed login to at least this long, so "no such user" and
+	// "wrong password" can't be told apart by latency. Zero disables padding.
+	LoginMinDuration time.Duration
+	LoginThrottle    LoginThrottleConfig
+	// StepUpMaxAge is how long after proving their password a user may call sensitive endpoints
+	StepUpMaxAge time.Duration
+	// PersonalTokenMaxLifetime is the longest expiry a user may choose for a personal access token
+	PersonalTokenMaxLifetime time.Duration
+	// Reregistration decides what registering with the email of a deleted account does, one of the Reregister* modes
+	Reregistration string
+	Sessions       SessionConfig
+	PasswordHash   PasswordHashConfig
+}
+
+// What registering with the email of a deleted account does
+const (
+	ReregisterRecreate = "recreate" // Create a fresh account; the deleted one stays deleted
+	ReregisterRestore  = "restore"  // Bring the deleted account back, with its products, if the password is its old one
+)
+
+// Validate checks that the re-registration mode is known and the session settings are usable
+func (c AuthConfig) Validate() error {
+	switch c.Reregistration {
+	case ReregisterRecreate, ReregisterRestore:
+	default:
+		return fmt.Errorf("unknown auth.reregistration mode %q", c.Reregistration)
+	}
+	if err := c.PasswordHash.Validate(); err != nil {
+		return err
+	}
+	return c.Sessions.Validate()
+}
+
+// PasswordHashConfig configures bcrypt password hashing. Every cost step doubles the hashing time; tune it
+// per hardware so one hash takes between MinDuration and MaxDuration (see GET /admin/password-hashing).
+type PasswordHashConfig struct {
+	Cost        int           // bcrypt cost, 4 to 31; applies to new hashes, existing ones keep theirs
+	MinDuration time.Duration // Hashing faster than this is logged at startup: the cost is too low to slow down guessing
+	MaxDuration time.Duration // Hashing slower than this is logged at startup: logins and registrations are too slow
+}
+
+// Validate checks that the cost is one bcrypt accepts and the target range is ordered
+func (c PasswordHashConfig) Validate() error {
+	if c.Cost < 4 || c.Cost > 31 {
+		return fmt.Errorf("auth.passwordHash.cost must be between 4 and 31, got %d", c.Cost)
+	}
+	if c.MinDuration > c.MaxDuration {
+		return fmt.Errorf("auth.passwordHash.minDuration (%s) must not exceed maxDuration (%s)", c.MinDuration, c.MaxDuration)
+	}
+	return nil
+}
+
+// SessionConfig configures cookie sessions, an alternative to bearer tokens for browser apps rendered on the
+// server. Sessions are kept in Redis; they are off unless RedisAddr is set.
+type SessionConfig struct {
+	RedisAddr     string // "host:port" of the Redis server; empty disables sessions
+	RedisPassword string
+	RedisDB       int
+	RedisTimeout  time.Duration // Bounds dialing and each command
+	CookieName    string
+	TTL           time.Duration // Sessions end this long after sign-in
+	SecureCookie  bool          // Only send the cookie over HTTPS; disable for local development over HTTP
+}
+
+// Validate checks the settings of enabled sessions
+func (c SessionConfig) Validate() error {
+	if c.RedisAddr == "" {
+		return nil
+	}
+	if c.CookieName == "" {
+		return fmt.Errorf("auth.sessions.cookieName is empty")
+	}
+	if c.TTL <= 0 {
+		return fmt.Errorf("auth.sessions.ttl must be positive")
+	}
+	if c.RedisTimeout <= 0 {
+		return fmt.Errorf("auth.sessions.redisTimeout must be positive")
+	}
+	return nil
+}
+
+// LoginThrottleConfig configures progressive delays for repeated failed logins on one email
+type LoginThrottleConfig struct {
+	FreeAttempts int           // Failures allowed before delays start; zero disables throttling
+	BaseDelay    time.Duration // Delay after the first failure past the free attempts, doubled on each further failure
+	MaxDelay     time.Duration // Upper bound for the delay
+	Window       time.Duration // Failures older than this are forgotten
+}
+
+// AuditConfig holds audit-log related configurations
+type AuditConfig struct {
+	Forwarder AuditForwarderConfig // Off-box export of audit events (SIEM)
+	// Retention is how long audit events are kept; whole months are dropped once they are older. Zero keeps them
+	// forever. Keep it longer than the forwarder may lag behind, or events are dropped before they are shipped.
+	Retention time.Duration
+}
+
+// Validate checks the retention
+func (c AuditConfig) Validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("audit.retention must not be negative")
+	}
+	return nil
+}
+
+// AuditForwarderConfig configures shipping audit events to an external collector
+type AuditForwarderConfig struct {
+	Sink          string        // "http" or "syslog"; empty disables forwarding
+	URL           string        // Endpoint receiving JSON batches (http sink)
+	AuthHeader    string        // Optional Authorization header value (http sink)
+	SyslogNetwork string        // "udp" or "tcp" (syslog sink)
+	SyslogAddress string        // host:port of the syslog collector (syslog sink)
+	BatchSize     int           // Maximum number of events sent per batch
+	PollInterval  time.Duration // How often to look for new events when caught up
+	MaxBackoff    time.Duration // Upper bound for the retry delay while the sink is failing
+}
+
+// Cascade strategies applied to a deleted user's products
+const (
+	CascadeRestrict   = "restrict"    // Refuse to delete a user who still owns products
+	CascadeSoftDelete = "soft-delete" // Soft-delete the user's products along with the user
+	CascadeReassign   = "reassign"    // Hand the user's products over to ReassignToUserID
+)
+
+// CascadeConfig defines what happens to dependent records when their owner is deleted
+type CascadeConfig struct {
+	UserProducts     string // One of the Cascade* strategies
+	ReassignToUserID uint   // Receiving user for the reassign strategy
+}
+
+// Validate checks that the configured strategies are known and complete
+func (c CascadeConfig) Validate() error {
+	switch c.UserProducts {
+	case CascadeRestrict, CascadeSoftDelete:
+		return nil
+	case CascadeReassign:
+		if c.ReassignToUserID == 0 {
+			return fmt.Errorf("cascade.reassignToUserID is required for the %q strategy", CascadeReassign)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown cascade.userProducts strategy %q", c.UserProducts)
+	}
+}
+
+// ChaosConfig configures fault injection, for exercising client retries and circuit breakers in staging
+type ChaosConfig struct {
+	Enabled bool        // Must be set explicitly; never enable in production
+	Rules   []ChaosRule // Evaluated in order; the first rule matching a request's route decides
+}
+
+// ChaosRule injects faults into a share of the requests to one route
+type ChaosRule struct {
+	Route       string        // "METHOD /path" as registered, e.g. "GET /api/v1/products/:id"; "*" matches every route
+	Percent     float64       // Share of matching requests that get the faults, 0-100
+	Latency     time.Duration // Delay added before the request is handled
+	ErrorStatus int           // If set, respond with this status instead of handling the request
+	Drop        bool          // Close the connection without a response
+}
+
+// Validate checks that every rule is complete and within range
+func (c ChaosConfig) Validate() error {
+	for i, rule := range c.Rules {
+		if rule.Route == "" {
+			return fmt.Errorf("chaos.rules[%d].route is required", i)
+		}
+		if rule.Percent < 0 || rule.Percent > 100 {
+			return fmt.Errorf("chaos.rules[%d].percent must be between 0 and 100", i)
+		}
+		if rule.ErrorStatus != 0 && (rule.ErrorStatus < 400 || rule.ErrorStatus > 599) {
+			return fmt.Errorf("chaos.rules[%d].errorStatus must be a 4xx or 5xx status", i)
+		}
+	}
+	return nil
+}
+
+// CanaryConfig sets how much traffic rewritten endpoints receive during a gradual rollout
+type CanaryConfig struct {
+	Rollouts []CanaryRollout
+}
+
+// CanaryRollout sends a share of the traffic of one rollout to its candidate handler
+type CanaryRollout struct {
+	Name    string  // Rollout name used at route registration, e.g. "products-list-v2"
+	Percent float64 // Share of users routed to the candidate, 0-100
+}
+
+// Validate checks that every rollout is named once and within range
+func (c CanaryConfig) Validate() error {
+	seen := map[string]bool{}
+	for i, rollout := range c.Rollouts {
+		if rollout.Name == "" {
+			return fmt.Errorf("canary.rollouts[%d].name is required", i)
+		}
+		if seen[rollout.Name] {
+			return fmt.Errorf("canary.rollouts[%d].name %q is used twice", i, rollout.Name)
+		}
+		seen[rollout.Name] = true
+		if rollout.Percent < 0 || rollout.Percent > 100 {
+			return fmt.Errorf("canary.rollouts[%d].percent must be between 0 and 100", i)
+		}
+	}
+	return nil
+}
+
+// ShadowConfig sets how much read traffic of shadowed endpoints is mirrored to their candidate implementation
+type ShadowConfig struct {
+	Timeout     time.Duration // Bounds each mirrored request, which runs after the response was sent
+	MaxInFlight int           // Mirrored requests running at once; samples beyond it are skipped
+	Experiments []ShadowExperiment
+}
+
+// ShadowExperiment mirrors a share of the requests of one shadowed route to its candidate handler
+type ShadowExperiment struct {
+	Name    string  // Experiment name used at route registration, e.g. "search-elasticsearch"
+	Percent float64 // Share of read requests mirrored, 0-100
+}
+
+// Validate checks the limits and that every experiment is named once and within range
+func (c ShadowConfig) Validate() error {
+	if c.Timeout <= 0 {
+		return fmt.Errorf("shadow.timeout must be positive")
+	}
+	if c.MaxInFlight <= 0 {
+		return fmt.Errorf("shadow.maxInFlight must be positive")
+	}
+	seen := map[string]bool{}
+	for i, experiment := range c.Experiments {
+		if experiment.Name == "" {
+			return fmt.Errorf("shadow.experiments[%d].name is required", i)
+		}
+		if seen[experiment.Name] {
+			return fmt.Errorf("shadow.experiments[%d].name %q is used twice", i, experiment.Name)
+		}
+		seen[experiment.Name] = true
+		if experiment.Percent < 0 || experiment.Percent > 100 {
+			return fmt.Errorf("shadow.experiments[%d].percent must be between 0 and 100", i)
+		}
+	}
+	return nil
+}
+
+// CostLimitConfig sets the budget of request cost each caller may spend. Routes declare a cost (1 unless
+// declared otherwise), so expensive endpoints exhaust the budget long before cheap ones do.
+type CostLimitConfig struct {
+	Budget int           // Cost a user, or a client IP on public routes, may spend per window; zero disables the limit
+	Window time.Duration // Time over which a spent budget is refilled, continuously
+	DryRun bool          // Log requests over budget instead of rejecting them, to tune costs and budget
+}
+
+// Validate checks the budget and window
+func (c CostLimitConfig) Validate() error {
+	if c.Budget < 0 {
+		return fmt.Errorf("cost.budget must not be negative")
+	}
+	if c.Budget > 0 && c.Window <= 0 {
+		return fmt.Errorf("cost.window must be positive when cost.budget is set")
+	}
+	return nil
+}
+
+// LabelConfig sets what printed product labels link to. Labels are off unless ProductURL is set.
+type LabelConfig struct {
+	// ProductURL is the public page of a product, {id} standing for its ID, e.g. https://shop.example.com/p/{id};
+	// product QR codes link to it
+	ProductURL string
+	CacheSize  int // Rendered QR codes kept in memory
+}
+
+// maxLabelURLLength leaves room for the product ID within the 213 bytes a QR code holds
+const maxLabelURLLength = 190
+
+// Validate checks that the product URL is an absolute HTTP URL with an {id} placeholder
+func (c LabelConfig) Validate() error {
+	if c.ProductURL == "" {
+		return nil
+	}
+	u, err := url.Parse(c.ProductURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("labels.productURL must be an absolute http or https URL")
+	}
+	if !strings.Contains(c.ProductURL, "{id}") {
+		return fmt.Errorf("labels.productURL must contain {id}")
+	}
+	if len(c.ProductURL) > maxLabelURLLength {
+		return fmt.Errorf("labels.productURL must be at most %d characters to fit in a QR code", maxLabelURLLength)
+	}
+	if c.CacheSize <= 0 {
+		return fmt.Errorf("labels.cacheSize must be positive")
+	}
+	return nil
+}
+
+// Search backends
+const (
+	SearchBackendILike = "ilike" // Case-insensitive substring match, finds partial words but can't use an index
+	SearchBackendFTS   = "fts"   // PostgreSQL full-text search on whole words, ranked and served by idx_products_search
+)
+
+// SearchConfig configures the global search endpoint
+type SearchConfig struct {
+	Backend string // One of the SearchBackend* values
+}
+
+// Validate checks that the search backend is known
+func (c SearchConfig) Validate() error {
+	switch c.Backend {
+	case SearchBackendILike, SearchBackendFTS:
+		return nil
+	default:
+		return fmt.Errorf("unknown search.backend %q", c.Backend)
+	}
+}
+
+// BackupConfig configures admin-triggered logical database backups
+type BackupConfig struct {
+	Dir  string // Directory backups are written to; mount object storage here to keep them off the host
+	Keep int    // Number of newest backups kept, older ones are deleted after each successful backup
+}
+
+// Validate checks that backups have somewhere to go and keep at least one
+func (c BackupConfig) Validate() error {
+	if c.Dir == "" {
+		return fmt.Errorf("backup.dir is required")
+	}
+	if c.Keep < 1 {
+		return fmt.Errorf("backup.keep must be at least 1")
+	}
+	return nil
+}
+
+// SyncConfig configures the product change feed used by clients syncing incrementally
+type SyncConfig struct {
+	// ChangeRetention is how long changes are kept; clients holding an older change token must sync from scratch
+	ChangeRetention time.Duration
+}
+
+// Validate checks that changes are kept for some time
+func (c SyncConfig) Validate() error {
+	if c.ChangeRetention <= 0 {
+		return fmt.Errorf("sync.changeRetention must be positive")
+	}
+	return nil
+}
+
+// StatsConfig configures the precomputed aggregates of the admin statistics
+type StatsConfig struct {
+	// RefreshInterval is how often the aggregates are recomputed, i.e. how stale they may get
+	RefreshInterval time.Duration
+	// ViewFlushInterval is how often the product views counted in memory are written to the database.
+	// Views counted since the last flush are lost if the instance crashes.
+	ViewFlushInterval time.Duration
+	// UsageFlushInterval is how often the API requests counted in memory are written to the database, as for views
+	UsageFlushInterval time.Duration
+}
+
+// Validate checks that the aggregates are refreshed and the views and API usage flushed
+func (c StatsConfig) Validate() error {
+	if c.RefreshInterval <= 0 {
+		return fmt.Errorf("stats.refreshInterval must be positive")
+	}
+	if c.ViewFlushInterval <= 0 {
+		return fmt.Errorf("stats.viewFlushInterval must be positive")
+	}
+	if c.UsageFlushInterval <= 0 {
+		return fmt.Errorf("stats.usageFlushInterval must be positive")
+	}
+	return nil
+}
+
+// APIVersionLayout is the format of API versions: the date a breaking response change shipped
+const APIVersionLayout = "2006-01-02"
+
+// APIConfig configures response versioning. Clients pin a version with the Api-Version header; a field
+// deprecated in a version is still sent to clients pinned to an earlier one, next to its replacement.
+type APIConfig struct {
+	// DefaultVersion applies to clients sending no Api-Version. Empty sends them every deprecated field;
+	// raise it at the end of a deprecation window, before the field is deleted.
+	DefaultVersion string
+}
+
+// Validate checks that the default version is a date
+func (c APIConfig) Validate() error {
+	if c.DefaultVersion == "" {
+		return nil
+	}
+	if _, err := time.Parse(APIVersionLayout, c.DefaultVersion); err != nil {
+		return fmt.Errorf("api.defaultVersion %q is not a YYYY-MM-DD date", c.DefaultVersion)
+	}
+	return nil
+}
+
+// GeoIPConfig configures the country lookup of client IPs. It is off unless DatabasePath names a MaxMind
+// Country or City database (.mmdb), e.g. kept current by geoipupdate.
+type GeoIPConfig struct {
+	DatabasePath   string        // Path of the .mmdb file; empty disables the lookup
+	ReloadInterval time.Duration // How often the file is checked for changes
+	LogRequests    bool          // Adds the client country to the request log
+	// Country rules, as ISO 3166-1 alpha-2 codes (e.g. "KP"), for sanctions and compliance. Requests from
+	// blocked countries get 451 Unavailable For Legal Reasons; those from flagged countries are logged.
+	BlockedCountries []string
+	FlaggedCountries []string
+}
+
+// Validate checks that a configured database is polled for changes, and that country rules have a database
+// to apply to and name countries by their two-letter codes
+func (c GeoIPConfig) Validate() error {
+	if c.DatabasePath != "" && c.ReloadInterval <= 0 {
+		return fmt.Errorf("geoip.reloadInterval must be positive")
+	}
+	if c.DatabasePath == "" && len(c.BlockedCountries)+len(c.FlaggedCountries) > 0 {
+		return fmt.Errorf("geoip.blockedCountries and geoip.flaggedCountries require geoip.databasePath")
+	}
+	for _, country := range append(append([]string{}, c.BlockedCountries...), c.FlaggedCountries...) {
+		if len(strings.TrimSpace(country)) != 2 {
+			return fmt.Errorf("geoip country %q is not an ISO 3166-1 alpha-2 code", country)
+		}
+	}
+	return nil
 }
 
 // LoadConfig loads configuration from environment variables or a config file
@@ -65,6 +645,19 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("server.readTimeout", "10s")
 	viper.SetDefault("server.writeTimeout", "10s")
 	viper.SetDefault("server.debug", false)
+	viper.SetDefault("server.readHeaderTimeout", "5s")
+	viper.SetDefault("server.maxHeaderBytes", 64<<10) // Room for JWTs and session cookies, far below net/http's 1 MiB
+	viper.SetDefault("server.keepAlives", true)
+	viper.SetDefault("server.idleTimeout", "2m")
+	viper.SetDefault("server.requestBudget", "8s") // Below writeTimeout, so handlers can still write an error
+	viper.SetDefault("server.maxRequestBudget", "30s")
+	viper.SetDefault("server.maxDecodedBodySize", 256<<20) // Room for the largest product import file
+	viper.SetDefault("server.maxJSONBodySize", 1<<20)
+	viper.SetDefault("server.maxJSONDepth", 32)
+	viper.SetDefault("server.strictJSONRoutes", []string{})
+	viper.SetDefault("server.readOnly", false)
+	viper.SetDefault("server.trustedProxies", []string{}) // Behind a load balancer, list its addresses
+	viper.SetDefault("server.allowInsecure", []string{})
 
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", "5432")
@@ -72,8 +665,72 @@ func LoadConfig() (*Config, error) {
 	viper.SetDefault("database.password", "password")
 	viper.SetDefault("database.dbname", "yourdb")
 	viper.SetDefault("database.sslmode", "disable")
+	viper.SetDefault("database.healthCheckInterval", "5s")
+	viper.SetDefault("database.explainThreshold", "0s") // Only honoured with server.debug
+	viper.SetDefault("database.applicationName", "gotemplate")
+	viper.SetDefault("database.queryTags", QueryTagsRoute)
+	viper.SetDefault("database.poolWaitThreshold", "100ms")
+	viper.SetDefault("database.userCacheTTL", "2s")
 
 	viper.SetDefault("jwt.expiresInHour", "24h") // Default JWT expiration is 24 hours
+	viper.SetDefault("jwt.audiences", []string{})
+	viper.SetDefault("jwt.issuer", "gotemplate")
+	viper.SetDefault("jwt.leeway", "30s")
+
+	viper.SetDefault("auth.loginMinDuration", "0s") // The dummy bcrypt comparison already evens out most of the gap
+	viper.SetDefault("auth.loginThrottle.freeAttempts", 5)
+	viper.SetDefault("auth.loginThrottle.baseDelay", "2s")
+	viper.SetDefault("auth.loginThrottle.maxDelay", "15m")
+	viper.SetDefault("auth.loginThrottle.window", "1h")
+	viper.SetDefault("auth.stepUpMaxAge", "10m")
+	viper.SetDefault("auth.personalTokenMaxLifetime", "8760h") // One year
+	viper.SetDefault("auth.reregistration", ReregisterRecreate)
+	viper.SetDefault("auth.passwordHash.cost", 10) // bcrypt.DefaultCost
+	viper.SetDefault("auth.passwordHash.minDuration", "50ms")
+	viper.SetDefault("auth.passwordHash.maxDuration", "500ms")
+	viper.SetDefault("auth.sessions.redisAddr", "") // Bearer tokens only
+	viper.SetDefault("auth.sessions.redisTimeout", "2s")
+	viper.SetDefault("auth.sessions.cookieName", "session")
+	viper.SetDefault("auth.sessions.ttl", "24h")
+	viper.SetDefault("auth.sessions.secureCookie", true)
+
+	viper.SetDefault("audit.forwarder.sink", "") // Audit forwarding is disabled by default
+	viper.SetDefault("audit.forwarder.syslogNetwork", "udp")
+	viper.SetDefault("audit.forwarder.batchSize", 100)
+	viper.SetDefault("audit.forwarder.pollInterval", "5s")
+	viper.SetDefault("audit.forwarder.maxBackoff", "5m")
+	viper.SetDefault("audit.retention", "0s") // Audit events are compliance records, kept until configured otherwise
+
+	viper.SetDefault("cascade.userProducts", CascadeRestrict) // Deleting a user never silently touches their products
+
+	viper.SetDefault("chaos.enabled", false)
+
+	viper.SetDefault("shadow.timeout", "5s")
+	viper.SetDefault("shadow.maxInFlight", 16)
+	viper.SetDefault("cost.budget", 600) // e.g. 600 product reads, or 12 imports, a minute
+	viper.SetDefault("cost.window", "1m")
+	viper.SetDefault("cost.dryRun", false)
+	viper.SetDefault("labels.productURL", "") // Off by default
+	viper.SetDefault("labels.cacheSize", 1000)
+
+	viper.SetDefault("search.backend", SearchBackendILike)
+
+	viper.SetDefault("backup.dir", "./backups")
+	viper.SetDefault("backup.keep", 7)
+
+	viper.SetDefault("sync.changeRetention", "720h") // 30 days
+
+	viper.SetDefault("stats.refreshInterval", "15m")
+	viper.SetDefault("stats.viewFlushInterval", "30s")
+	viper.SetDefault("stats.usageFlushInterval", "30s")
+
+	viper.SetDefault("api.defaultVersion", "") // Unpinned clients keep receiving deprecated fields
+
+	viper.SetDefault("geoip.databasePath", "") // No country lookup
+	viper.SetDefault("geoip.reloadInterval", "1h")
+	viper.SetDefault("geoip.logRequests", false)
+	viper.SetDefault("geoip.blockedCountries", []string{})
+	viper.SetDefault("geoip.flaggedCountries", []string{})
 
 	// Bind environment variables to config keys (e.g., APP_SERVER_PORT maps to server.port)
 	// This allows overriding config file settings with env vars
@@ -103,6 +760,57 @@ func LoadConfig() (*Config, error) {
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	if err := cfg.Server.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid server configuration: %w", err)
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	if err := cfg.JWT.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid jwt configuration: %w", err)
+	}
+	if err := cfg.Auth.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid auth configuration: %w", err)
+	}
+	if err := cfg.Audit.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid audit configuration: %w", err)
+	}
+	if err := cfg.Cascade.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cascade configuration: %w", err)
+	}
+	if err := cfg.Chaos.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid chaos configuration: %w", err)
+	}
+	if err := cfg.Canary.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid canary configuration: %w", err)
+	}
+	if err := cfg.Shadow.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid shadow configuration: %w", err)
+	}
+	if err := cfg.Cost.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid cost configuration: %w", err)
+	}
+	if err := cfg.Search.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid search configuration: %w", err)
+	}
+	if err := cfg.Backup.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid backup configuration: %w", err)
+	}
+	if err := cfg.Sync.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid sync configuration: %w", err)
+	}
+	if err := cfg.Stats.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid stats configuration: %w", err)
+	}
+	if err := cfg.Labels.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid labels configuration: %w", err)
+	}
+	if err := cfg.API.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid api configuration: %w", err)
+	}
+	if err := cfg.GeoIP.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid geoip configuration: %w", err)
+	}
 
 	return &cfg, nil
 }
@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Where an effective setting came from
+const (
+	SourceDefault = "default" // Built-in default from LoadConfig
+	SourceFile    = "file"    // The config file
+	SourceEnv     = "env"     // An environment variable
+)
+
+// SecretMask replaces the value of secret settings in descriptions
+const SecretMask = "********"
+
+// secretFieldMarkers mark a setting as secret when its lowercased name contains one of them
+var secretFieldMarkers = []string{"password", "secret", "authheader", "apikey", "apitoken", "privatekey"}
+
+// Setting is one effective configuration value and where it came from
+type Setting struct {
+	Key    string      `json:"key"` // viper key, e.g. "server.readtimeout"
+	Value  interface{} `json:"value"`
+	Source string      `json:"source"` // One of the Source* constants
+	Secret bool        `json:"secret,omitempty"`
+}
+
+// Description is the effective configuration of a running instance
+type Description struct {
+	File     string     `json:"file,omitempty"` // Config file that was read, if any
+	Settings []*Setting `json:"settings"`
+}
+
+// Describe lists every setting of cfg with its source. Secrets are masked, so the result is safe to
+// return to administrators or log. Sources are read from viper, so cfg should come from LoadConfig.
+func Describe(cfg *Config) *Description {
+	d := &Description{File: viper.ConfigFileUsed()}
+	describeValue(d, "", reflect.ValueOf(*cfg))
+	return d
+}
+
+// describeValue flattens v into settings keyed like viper: lowercased and dot-separated, slice elements by index
+func describeValue(d *Description, key string, v reflect.Value) {
+	switch {
+	case v.Type() == reflect.TypeOf(time.Duration(0)):
+		d.add(key, v.Interface().(time.Duration).String())
+	case v.Kind() == reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if f := v.Type().Field(i); f.IsExported() {
+				describeValue(d, joinKey(key, strings.ToLower(f.Name)), v.Field(i))
+			}
+		}
+	case v.Kind() == reflect.Slice:
+		if v.Len() == 0 {
+			d.add(key, []interface{}{})
+		}
+		for i := 0; i < v.Len(); i++ {
+			describeValue(d, fmt.Sprintf("%s.%d", key, i), v.Index(i))
+		}
+	default:
+		d.add(key, v.Interface())
+	}
+}
+
+func (d *Description) add(key string, value interface{}) {
+	s := &Setting{Key: key, Value: value, Source: settingSource(key), Secret: isSecretKey(key)}
+	if s.Secret {
+		if isZero(value) {
+			s.Value = ""
+		} else {
+			s.Value = SecretMask
+		}
+	}
+	d.Settings = append(d.Settings, s)
+}
+
+// settingSource tells where viper took key from. Environment variables win over the file, which wins over
+// defaults; slice elements take the source of the slice.
+func settingSource(key string) string {
+	if k := sliceKey(key); k != "" {
+		key = k
+	}
+	// AutomaticEnv looks keys up by their upper-cased name
+	if _, ok := os.LookupEnv(strings.ToUpper(key)); ok {
+		return SourceEnv
+	}
+	if viper.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+func isSecretKey(key string) bool {
+	name := key[strings.LastIndex(key, ".")+1:]
+	for _, marker := range secretFieldMarkers {
+		if strings.Contains(name, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+func isZero(value interface{}) bool {
+	return value == nil || reflect.ValueOf(value).IsZero()
+}
+
+func joinKey(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// sliceKey returns the key of the slice holding key, e.g. "chaos.rules" for "chaos.rules.0.route", or ""
+func sliceKey(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		if _, err := strconv.Atoi(part); err == nil && i > 0 {
+			return strings.Join(parts[:i], ".")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityHandler defines the interface for activity feed HTTP handlers
+type ActivityHandler interface {
+	GetActivity(c *gin.Context)
+}
+
+// activityHandler implements ActivityHandler
+type activityHandler struct {
+	activityService service.ActivityService // Dependency on ActivityService
+}
+
+// NewActivityHandler creates a new ActivityHandler instance
+func NewActivityHandler(activityService service.ActivityService) ActivityHandler {
+	return &activityHandler{
+		activityService: activityService,
+	}
+}
+
+// GetActivity handles retrieving the authenticated user's activity feed, paginated
+func (h *activityHandler) GetActivity(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	entries, total, err := h.activityService.GetActivity(c.Request.Context(), a.EffectiveUserID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve activity"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ActivityListResponse{
+		Items:    models.NewActivityEntryResponses(entries),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
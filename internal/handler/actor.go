@@ -0,0 +1,22 @@
+package handler
+
+import (
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// requireActor returns the authenticated actor set by AuthMiddleware.
+// If it is missing the route was wired without authentication, so a 500 is written and ok is false.
+func requireActor(c *gin.Context) (a actor.Actor, ok bool) {
+	a, ok = actor.FromContext(c.Request.Context())
+	if !ok {
+		logger.Error("Actor not found in request context (AuthMiddleware issue)", zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+		return actor.Actor{}, false
+	}
+	return a, true
+}
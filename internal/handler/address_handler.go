@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/validation"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddressHandler defines the interface for saved address HTTP handlers
+type AddressHandler interface {
+	GetAddresses(c *gin.Context)
+	GetAddress(c *gin.Context)
+	AddAddress(c *gin.Context)
+	UpdateAddress(c *gin.Context)
+	DeleteAddress(c *gin.Context)
+}
+
+// addressHandler implements AddressHandler
+type addressHandler struct {
+	addressService service.AddressService // Dependency on AddressService
+}
+
+// NewAddressHandler creates a new AddressHandler instance
+func NewAddressHandler(addressService service.AddressService) AddressHandler {
+	return &addressHandler{
+		addressService: addressService,
+	}
+}
+
+// GetAddresses handles listing the authenticated user's addresses
+func (h *addressHandler) GetAddresses(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	addresses, err := h.addressService.GetAddresses(c.Request.Context(), a.EffectiveUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve addresses"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAddressResponses(addresses))
+}
+
+// GetAddress handles retrieving one of the authenticated user's addresses
+func (h *addressHandler) GetAddress(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	addressID, ok := parseIDParam(c, "id", "address")
+	if !ok {
+		return
+	}
+
+	address, err := h.addressService.GetAddress(c.Request.Context(), addressID, a.EffectiveUserID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAddressResponse(address))
+}
+
+// AddAddress handles saving a new address
+func (h *addressHandler) AddAddress(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.AddressRequest
+	if !bindRequest(c, &req, "AddAddress") {
+		return
+	}
+
+	address, err := h.addressService.AddAddress(c.Request.Context(), a.EffectiveUserID, &req)
+	if err != nil {
+		h.writeError(c, err, "Failed to add address")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewAddressResponse(address))
+}
+
+// UpdateAddress handles replacing one of the authenticated user's addresses
+func (h *addressHandler) UpdateAddress(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	addressID, ok := parseIDParam(c, "id", "address")
+	if !ok {
+		return
+	}
+
+	var req models.AddressRequest
+	if !bindRequest(c, &req, "UpdateAddress") {
+		return
+	}
+
+	address, err := h.addressService.UpdateAddress(c.Request.Context(), addressID, a.EffectiveUserID, &req)
+	if err != nil {
+		h.writeError(c, err, "Failed to update address")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAddressResponse(address))
+}
+
+// DeleteAddress handles removing one of the authenticated user's addresses
+func (h *addressHandler) DeleteAddress(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	addressID, ok := parseIDParam(c, "id", "address")
+	if !ok {
+		return
+	}
+
+	if err := h.addressService.DeleteAddress(c.Request.Context(), addressID, a.EffectiveUserID); err != nil {
+		h.writeError(c, err, "Failed to delete address")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeError maps address service errors to HTTP responses
+func (h *addressHandler) writeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err.Error() == "address not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "address limit reached":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), validation.ErrorPrefix):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), service.AddressValidationErrorPrefix):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
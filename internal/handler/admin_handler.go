@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"context"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AdminHandler defines the interface for instance introspection HTTP handlers
+type AdminHandler interface {
+	GetConfig(c *gin.Context)
+	GetStatus(c *gin.Context)
+	GetReadOnly(c *gin.Context)
+	SetReadOnly(c *gin.Context)
+	GetRoutes(c *gin.Context)
+	GetDeprecations(c *gin.Context)
+	GetPasswordHashing(c *gin.Context)
+	GetMigrations(c *gin.Context)
+}
+
+// adminHandler implements AdminHandler
+type adminHandler struct {
+	cfg                *config.Config // Configuration the instance was started with
+	statusService      service.StatusService
+	readOnly           *readonly.Mode
+	auditService       service.AuditService       // Runtime setting changes are recorded in the audit log
+	deprecationService service.DeprecationService // Usage of deprecated endpoints and fields
+	routes             func() []*models.RouteInfo // Route table, known once the router is built
+	migrations         func(ctx context.Context) (*database.MigrationReport, error)
+}
+
+// NewAdminHandler creates a new AdminHandler instance
+func NewAdminHandler(cfg *config.Config, statusService service.StatusService, readOnly *readonly.Mode, auditService service.AuditService, deprecationService service.DeprecationService, routes func() []*models.RouteInfo, migrations func(ctx context.Context) (*database.MigrationReport, error)) AdminHandler {
+	return &adminHandler{
+		cfg:                cfg,
+		statusService:      statusService,
+		readOnly:           readOnly,
+		auditService:       auditService,
+		deprecationService: deprecationService,
+		routes:             routes,
+		migrations:         migrations,
+	}
+}
+
+// GetConfig handles listing the effective configuration with secrets masked and the source of every setting
+func (h *adminHandler) GetConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, config.Describe(h.cfg))
+}
+
+// GetRoutes handles listing every route with its middleware chain and required roles and scopes
+func (h *adminHandler) GetRoutes(c *gin.Context) {
+	c.JSON(http.StatusOK, h.routes())
+}
+
+// GetMigrations handles reporting the schema migrations of this instance's release: which are applied, when and
+// with which checksum, which are pending, and which were applied with other statements or by another release
+func (h *adminHandler) GetMigrations(c *gin.Context) {
+	report, err := h.migrations(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to get migration report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve migrations"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetDeprecations handles the sunset report: which clients used deprecated endpoints and fields over the
+// last ?days= days (default 30, at most 365), to tell when removing them is safe
+func (h *adminHandler) GetDeprecations(c *gin.Context) {
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > 365 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+		return
+	}
+
+	since := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, 1-days)
+	report, err := h.deprecationService.GetReport(c.Request.Context(), since)
+	if err != nil {
+		logger.Error("Failed to get deprecation report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve deprecation report"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetPasswordHashing handles timing a password hash at the configured cost on this instance, for tuning the cost
+func (h *adminHandler) GetPasswordHashing(c *gin.Context) {
+	report, err := service.MeasurePasswordHashing(h.cfg.Auth.PasswordHash)
+	if err != nil {
+		logger.Error("Failed to measure password hashing", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to measure password hashing"})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetStatus handles the status snapshot: component health, queue depths and background job runs in one document.
+// It answers 200 even when degraded; dashboards read the status field.
+func (h *adminHandler) GetStatus(c *gin.Context) {
+	c.JSON(http.StatusOK, h.statusService.Status(c.Request.Context()))
+}
+
+// GetReadOnly handles reporting whether the instance is in read-only mode
+func (h *adminHandler) GetReadOnly(c *gin.Context) {
+	c.JSON(http.StatusOK, h.readOnly.State())
+}
+
+// SetReadOnly handles switching read-only mode on or off. It only affects the instance that serves the request.
+func (h *adminHandler) SetReadOnly(c *gin.Context) {
+	var req models.SetReadOnlyRequest
+	if !bindRequest(c, &req, "SetReadOnly") {
+		return
+	}
+
+	ctx := c.Request.Context()
+	was := h.readOnly.Enabled()
+	// Recorded while writes are still possible: before switching on, after switching off
+	if !*req.Enabled {
+		h.readOnly.Set(false, "")
+	}
+	if err := h.auditService.RecordSettingChange(ctx, "server.readOnly", was, *req.Enabled, req.Reason); err != nil {
+		logger.Warn("Read-only switch audit event was not recorded", zap.Error(err))
+	}
+	if *req.Enabled {
+		h.readOnly.Set(true, req.Reason)
+	}
+
+	logger.Warn("Read-only mode switched", zap.Bool("enabled", *req.Enabled), zap.String("reason", req.Reason), actor.Field(ctx))
+	c.JSON(http.StatusOK, h.readOnly.State())
+}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AnnouncementHandler defines the interface for announcement HTTP handlers
+type AnnouncementHandler interface {
+	PublishAnnouncement(c *gin.Context)
+	GetAnnouncements(c *gin.Context)
+}
+
+// announcementHandler implements AnnouncementHandler
+type announcementHandler struct {
+	announcementService service.AnnouncementService // Dependency on AnnouncementService
+}
+
+// NewAnnouncementHandler creates a new AnnouncementHandler instance
+func NewAnnouncementHandler(announcementService service.AnnouncementService) AnnouncementHandler {
+	return &announcementHandler{
+		announcementService: announcementService,
+	}
+}
+
+// PublishAnnouncement handles admins publishing a new announcement
+func (h *announcementHandler) PublishAnnouncement(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateAnnouncementRequest
+	if !bindRequest(c, &req, "PublishAnnouncement") {
+		return
+	}
+
+	announcement, err := h.announcementService.PublishAnnouncement(c.Request.Context(), a.UserID, &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		if err.Error() == "expiry must be in the future" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to publish announcement"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewAnnouncementResponse(announcement))
+}
+
+// GetAnnouncements handles listing the announcements targeted at the authenticated user
+func (h *announcementHandler) GetAnnouncements(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	announcements, err := h.announcementService.GetAnnouncementsForRole(c.Request.Context(), a.Role)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve announcements"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewAnnouncementResponses(announcements))
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AuditHandler defines the interface for audit log HTTP handlers
+type AuditHandler interface {
+	GetAuditLog(c *gin.Context)
+}
+
+// auditHandler implements AuditHandler
+type auditHandler struct {
+	auditService service.AuditService // Dependency on AuditService
+}
+
+// NewAuditHandler creates a new AuditHandler instance
+func NewAuditHandler(auditService service.AuditService) AuditHandler {
+	return &auditHandler{
+		auditService: auditService,
+	}
+}
+
+// GetAuditLog handles auditors querying the audit log, newest first and paginated. ?setting= narrows it to the
+// runtime changes of one setting, e.g. server.readOnly.
+func (h *auditHandler) GetAuditLog(c *gin.Context) {
+	var query models.AuditEventQuery
+	if !bindRequest(c, &query, "GetAuditLog") {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	events, total, err := h.auditService.GetEvents(c.Request.Context(), &query, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve audit log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.AuditLogResponse{
+		Items:    models.NewAuditEventResponses(events),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"context"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/database"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BackupHandler defines the interface for database backup HTTP handlers
+type BackupHandler interface {
+	StartBackup(c *gin.Context)
+	GetBackups(c *gin.Context)
+	VerifyBackup(c *gin.Context)
+}
+
+// backupHandler implements BackupHandler
+type backupHandler struct {
+	backupService    service.BackupService    // Dependency on BackupService
+	operationService service.OperationService // Runs backups in the background
+}
+
+// NewBackupHandler creates a new BackupHandler instance
+func NewBackupHandler(backupService service.BackupService, operationService service.OperationService) BackupHandler {
+	return &backupHandler{
+		backupService:    backupService,
+		operationService: operationService,
+	}
+}
+
+// StartBackup handles triggering a backup. It runs as an operation; poll it for progress and the result.
+func (h *backupHandler) StartBackup(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	op, err := h.operationService.Start(c.Request.Context(), a.UserID, "backup", func(ctx context.Context, report service.ProgressFunc) (interface{}, error) {
+		return h.backupService.RunBackup(ctx, report)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start backup"})
+		return
+	}
+
+	respondAccepted(c, op)
+}
+
+// GetBackups handles listing the complete backups, newest first
+func (h *backupHandler) GetBackups(c *gin.Context) {
+	backups, err := h.backupService.ListBackups(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list backups"})
+		return
+	}
+	if backups == nil {
+		backups = []*database.BackupManifest{}
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+// VerifyBackup handles checking a stored backup against its checksums
+func (h *backupHandler) VerifyBackup(c *gin.Context) {
+	backup, err := h.backupService.VerifyBackup(c.Request.Context(), c.Param("name"))
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, backup)
+	case err.Error() == "backup not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), "backup failed verification"):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify backup"})
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"errors"
+	"gotemplate/pkg/jsonbody"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+)
+
+// bindRequest binds and validates a request DTO. The body is read as JSON, a URL-encoded form or a multipart
+// form according to Content-Type; a body without Content-Type is read as JSON, and GET requests bind the query.
+// JSON bodies are decoded within the limits of the route, see jsonbody.Decode. On failure it writes a 400 (or 413
+// or 415) with a client-readable message, logs it under name and returns false.
+func bindRequest(c *gin.Context, req interface{}, name string, fields ...zap.Field) bool {
+	var b binding.Binding
+	switch c.ContentType() {
+	case "":
+		b = binding.JSON
+		if c.Request.Method == http.MethodGet {
+			b = binding.Query
+		}
+	case binding.MIMEJSON:
+		b = binding.JSON
+	case binding.MIMEPOSTForm:
+		b = binding.Form
+	case binding.MIMEMultipartPOSTForm:
+		b = binding.FormMultipart
+	default:
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/json, application/x-www-form-urlencoded or multipart/form-data"})
+		return false
+	}
+
+	var err error
+	if b == binding.JSON {
+		err = jsonbody.Decode(c.Request.Body, req, jsonbody.LimitsFrom(c.Request.Context()))
+		var bodyErr *jsonbody.Error
+		if errors.As(err, &bodyErr) {
+			logger.Warn("Invalid "+name+" request body", append(fields, zap.Error(err))...)
+			body := gin.H{"error": bodyErr.Message}
+			if bodyErr.Field != "" {
+				body["field"] = bodyErr.Field // Lets clients point at the field without parsing the message
+			}
+			c.JSON(bodyErr.Status, body)
+			return false
+		}
+		err = binding.Validator.ValidateStruct(req)
+	} else {
+		err = c.ShouldBindWith(req, b)
+	}
+	if err != nil {
+		logger.Warn("Invalid "+name+" request payload", append(fields, zap.Error(err))...)
+		c.JSON(http.StatusBadRequest, gin.H{"error": validation.Message(err)})
+		return false
+	}
+	return true
+}
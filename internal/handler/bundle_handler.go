@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BundleHandler defines the interface for product bundle HTTP handlers
+type BundleHandler interface {
+	CreateBundle(c *gin.Context)
+	GetBundle(c *gin.Context)
+	GetBundles(c *gin.Context)
+	DeleteBundle(c *gin.Context)
+}
+
+// bundleHandler implements BundleHandler
+type bundleHandler struct {
+	bundleService service.BundleService // Dependency on BundleService
+}
+
+// NewBundleHandler creates a new BundleHandler instance
+func NewBundleHandler(bundleService service.BundleService) BundleHandler {
+	return &bundleHandler{
+		bundleService: bundleService,
+	}
+}
+
+// CreateBundle handles creating a bundle of the authenticated user's products
+func (h *bundleHandler) CreateBundle(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreateBundleRequest
+	if !bindRequest(c, &req, "CreateBundle") {
+		return
+	}
+
+	bundle, err := h.bundleService.CreateBundle(c.Request.Context(), a.EffectiveUserID, &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "bundle component not found", "bundle components must be distinct products":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "bundle components must belong to the bundle owner":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create bundle"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewBundleResponse(bundle))
+}
+
+// GetBundle handles retrieving a single bundle by ID
+func (h *bundleHandler) GetBundle(c *gin.Context) {
+	bundleID, ok := parseIDParam(c, "id", "bundle")
+	if !ok {
+		return
+	}
+
+	bundle, err := h.bundleService.GetBundle(c.Request.Context(), bundleID)
+	if err != nil {
+		if err.Error() == "bundle not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bundle"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewBundleResponse(bundle))
+}
+
+// GetBundles handles listing the authenticated user's bundles, optionally restricted by time (see parseTimeRange)
+func (h *bundleHandler) GetBundles(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	timeRange, ok := parseTimeRange(c)
+	if !ok {
+		return
+	}
+
+	bundles, err := h.bundleService.GetBundlesByOwner(c.Request.Context(), a.EffectiveUserID, timeRange)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve bundles"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewBundleResponses(bundles))
+}
+
+// DeleteBundle handles deleting one of the authenticated user's bundles
+func (h *bundleHandler) DeleteBundle(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	bundleID, ok := parseIDParam(c, "id", "bundle")
+	if !ok {
+		return
+	}
+
+	if err := h.bundleService.DeleteBundle(c.Request.Context(), bundleID, a.EffectiveUserID); err != nil {
+		switch err.Error() {
+		case "bundle not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "you are not authorized to delete this bundle":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete bundle"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
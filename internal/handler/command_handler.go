@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CommandHandler defines the interface for admin command HTTP handlers
+type CommandHandler interface {
+	GetCommands(c *gin.Context)
+	EnqueueCommand(c *gin.Context)
+}
+
+// commandHandler implements CommandHandler
+type commandHandler struct {
+	commandService service.AdminCommandService // Dependency on AdminCommandService
+}
+
+// NewCommandHandler creates a new CommandHandler instance
+func NewCommandHandler(commandService service.AdminCommandService) CommandHandler {
+	return &commandHandler{
+		commandService: commandService,
+	}
+}
+
+// GetCommands handles listing the commands admins can enqueue, with their parameters
+func (h *commandHandler) GetCommands(c *gin.Context) {
+	c.JSON(http.StatusOK, h.commandService.Commands())
+}
+
+// EnqueueCommand handles running a command with the params of the body. It runs as an operation; poll it for
+// progress and the result.
+func (h *commandHandler) EnqueueCommand(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	var req models.EnqueueCommandRequest
+	if !bindRequest(c, &req, "EnqueueCommand", zap.String("command", c.Param("name"))) {
+		return
+	}
+
+	op, err := h.commandService.Enqueue(c.Request.Context(), a.UserID, c.Param("name"), req.Params)
+	switch {
+	case err == nil:
+		respondAccepted(c, op)
+	case err.Error() == "command not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case respondIfInvalid(c, err):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to enqueue command"})
+	}
+}
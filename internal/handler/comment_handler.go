@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CommentHandler defines the interface for product comment HTTP handlers
+type CommentHandler interface {
+	AddComment(c *gin.Context)
+	GetComments(c *gin.Context)
+	HideComment(c *gin.Context)
+	UnhideComment(c *gin.Context)
+	DeleteComment(c *gin.Context)
+}
+
+// commentHandler implements CommentHandler
+type commentHandler struct {
+	commentService service.CommentService // Dependency on CommentService
+}
+
+// NewCommentHandler creates a new CommentHandler instance
+func NewCommentHandler(commentService service.CommentService) CommentHandler {
+	return &commentHandler{
+		commentService: commentService,
+	}
+}
+
+// AddComment handles commenting on (or replying within) a product's thread
+func (h *commentHandler) AddComment(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if !bindRequest(c, &req, "AddComment") {
+		return
+	}
+
+	comment, err := h.commentService.AddComment(c.Request.Context(), productID, a.EffectiveUserID, a.Role, &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "product not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "parent comment not found":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add comment"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewCommentResponse(comment))
+}
+
+// GetComments handles listing a product's comments, paginated and optionally restricted by time (see parseTimeRange)
+func (h *commentHandler) GetComments(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	timeRange, ok := parseTimeRange(c)
+	if !ok {
+		return
+	}
+
+	comments, total, err := h.commentService.GetComments(c.Request.Context(), productID, a.EffectiveUserID, a.Role, timeRange, page, pageSize)
+	if err != nil {
+		if err.Error() == "product not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve comments"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.CommentListResponse{
+		Items:    models.NewCommentResponses(comments),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// HideComment handles the product owner hiding a comment
+func (h *commentHandler) HideComment(c *gin.Context) {
+	h.setHidden(c, true)
+}
+
+// UnhideComment handles the product owner restoring a hidden comment
+func (h *commentHandler) UnhideComment(c *gin.Context) {
+	h.setHidden(c, false)
+}
+
+// setHidden is shared by HideComment and UnhideComment
+func (h *commentHandler) setHidden(c *gin.Context, hidden bool) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "id", "comment")
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.SetCommentHidden(c.Request.Context(), commentID, a.EffectiveUserID, hidden); err != nil {
+		switch err.Error() {
+		case "comment not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "you are not authorized to moderate this comment":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to moderate comment"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// DeleteComment handles deleting a comment (by its author or the product owner)
+func (h *commentHandler) DeleteComment(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	commentID, ok := parseIDParam(c, "id", "comment")
+	if !ok {
+		return
+	}
+
+	if err := h.commentService.DeleteComment(c.Request.Context(), commentID, a.EffectiveUserID); err != nil {
+		switch err.Error() {
+		case "comment not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "you are not authorized to delete this comment":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete comment"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
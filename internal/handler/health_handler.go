@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ReadinessProbe reports whether a dependency the API needs is usable
+type ReadinessProbe interface {
+	Available() bool
+}
+
+// HealthHandler defines the interface for health check HTTP handlers
+type HealthHandler interface {
+	Live(c *gin.Context)
+	Ready(c *gin.Context)
+}
+
+// healthHandler implements HealthHandler
+type healthHandler struct {
+	database ReadinessProbe
+}
+
+// NewHealthHandler creates a new HealthHandler instance
+func NewHealthHandler(database ReadinessProbe) HealthHandler {
+	return &healthHandler{
+		database: database,
+	}
+}
+
+// Live handles the liveness probe: the process is up and serving
+func (h *healthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready handles the readiness probe: 503 while the database is unreachable, so load balancers stop routing here
+func (h *healthHandler) Ready(c *gin.Context) {
+	if !h.database.Available() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "database": "unreachable"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "ok", "database": "ok"})
+}
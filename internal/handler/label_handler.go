@@ -0,0 +1,156 @@
+package handler
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"gotemplate/config"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/cachecontrol"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/qrcode"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// QR code image formats
+const (
+	qrFormatPNG = "png"
+	qrFormatSVG = "svg"
+)
+
+// Pixels per QR code module of PNGs, set with ?scale=
+const (
+	defaultQRScale = 8
+	maxQRScale     = 32
+)
+
+// qrCacheMaxAge lets clients keep a QR code for a day; it only changes with the product URL configuration
+const qrCacheMaxAge = 24 * time.Hour
+
+// LabelHandler defines the interface for printable product label HTTP handlers
+type LabelHandler interface {
+	GetProductQRCode(c *gin.Context)
+}
+
+// qrImage is a rendered QR code and its entity tag
+type qrImage struct {
+	body []byte
+	etag string
+}
+
+// labelHandler implements LabelHandler
+type labelHandler struct {
+	productService service.ProductService // Checks the caller may read the product
+	cfg            config.LabelConfig
+
+	mu    sync.Mutex
+	cache map[string]*qrImage // Rendered QR codes by product ID, format and scale; emptied when full
+}
+
+// NewLabelHandler creates a new LabelHandler instance
+func NewLabelHandler(productService service.ProductService, cfg config.LabelConfig) LabelHandler {
+	return &labelHandler{
+		productService: productService,
+		cfg:            cfg,
+		cache:          map[string]*qrImage{},
+	}
+}
+
+// GetProductQRCode handles rendering a QR code linking to the public page of a product, for printed labels.
+// ?format=png (default) or svg; ?scale= sets the pixels per module of PNGs. Codes are rendered once and cached.
+func (h *labelHandler) GetProductQRCode(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", qrFormatPNG)
+	if format != qrFormatPNG && format != qrFormatSVG {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be png or svg"})
+		return
+	}
+	scale := defaultQRScale
+	if v := c.Query("scale"); v != "" {
+		s, err := strconv.Atoi(v)
+		if err != nil || s < 1 || s > maxQRScale {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "scale must be between 1 and " + strconv.Itoa(maxQRScale)})
+			return
+		}
+		scale = s
+	}
+	if format == qrFormatSVG {
+		scale = 0 // SVGs scale themselves
+	}
+
+	if _, err := h.productService.GetProduct(c.Request.Context(), productID, a.EffectiveUserID, a.Role); err != nil {
+		logger.Warn("QR code requested for unknown product", zap.Error(err), zap.Uint("productID", productID))
+		if errors.Is(err, service.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product"})
+		}
+		return
+	}
+
+	img, err := h.render(productID, format, scale)
+	if err != nil {
+		logger.Error("Failed to render product QR code", zap.Error(err), zap.Uint("productID", productID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render QR code"})
+		return
+	}
+
+	cachecontrol.Set(c.Writer.Header(), cachecontrol.Fresh(qrCacheMaxAge).ForCaller())
+	c.Header("ETag", img.etag)
+	if c.GetHeader("If-None-Match") == img.etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	contentType := "image/png"
+	if format == qrFormatSVG {
+		contentType = "image/svg+xml"
+	}
+	c.Header("Content-Disposition", `inline; filename="product-`+strconv.FormatUint(uint64(productID), 10)+`.`+format+`"`)
+	c.Data(http.StatusOK, contentType, img.body)
+}
+
+// render returns the QR code of a product from the cache, rendering it on a miss
+func (h *labelHandler) render(productID uint, format string, scale int) (*qrImage, error) {
+	key := strconv.FormatUint(uint64(productID), 10) + "/" + format + "/" + strconv.Itoa(scale)
+	h.mu.Lock()
+	img, ok := h.cache[key]
+	h.mu.Unlock()
+	if ok {
+		return img, nil
+	}
+
+	code, err := qrcode.Encode(strings.ReplaceAll(h.cfg.ProductURL, "{id}", strconv.FormatUint(uint64(productID), 10)))
+	if err != nil {
+		return nil, err
+	}
+	body := code.SVG()
+	if format == qrFormatPNG {
+		if body, err = code.PNG(scale); err != nil {
+			return nil, err
+		}
+	}
+	sum := sha256.Sum256(body)
+	img = &qrImage{body: body, etag: `"` + hex.EncodeToString(sum[:8]) + `"`}
+
+	h.mu.Lock()
+	if len(h.cache) >= h.cfg.CacheSize {
+		h.cache = map[string]*qrImage{}
+	}
+	h.cache[key] = img
+	h.mu.Unlock()
+	return img, nil
+}
@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/logger"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// OperationHandler defines the interface for operation HTTP handlers
+type OperationHandler interface {
+	GetOperation(c *gin.Context)
+}
+
+// operationHandler implements OperationHandler
+type operationHandler struct {
+	operationService service.OperationService // Dependency on OperationService
+}
+
+// NewOperationHandler creates a new OperationHandler instance
+func NewOperationHandler(operationService service.OperationService) OperationHandler {
+	return &operationHandler{
+		operationService: operationService,
+	}
+}
+
+// GetOperation handles polling the status of a long-running operation
+func (h *operationHandler) GetOperation(c *gin.Context) {
+	operationIDStr := c.Param("id")
+	operationID, err := strconv.ParseUint(operationIDStr, 10, 64)
+	if err != nil {
+		logger.Warn("Invalid operation ID format in GetOperation request", zap.Error(err), zap.String("operationIDStr", operationIDStr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid operation ID format"})
+		return
+	}
+
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+	userID := a.EffectiveUserID
+
+	op, err := h.operationService.GetOperation(c.Request.Context(), uint(operationID), userID)
+	if err != nil {
+		logger.Warn("Failed to get operation", zap.Error(err), zap.Uint("operationID", uint(operationID)), zap.Uint("userID", userID))
+		c.JSON(http.StatusNotFound, gin.H{"error": "Operation not found"})
+		return
+	}
+
+	// Hint to pollers how long to wait before asking again
+	if !op.Done() {
+		c.Header("Retry-After", "2")
+	}
+	c.JSON(http.StatusOK, models.NewOperationResponse(op))
+}
+
+// respondAccepted answers a request whose work continues in the background:
+// 202 Accepted with the operation to poll and its URL in the Location header
+func respondAccepted(c *gin.Context, op *models.Operation) {
+	c.Header("Location", "/api/v1/operations/"+strconv.FormatUint(uint64(op.ID), 10))
+	c.JSON(http.StatusAccepted, models.NewOperationResponse(op))
+}
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Pagination defaults for list endpoints
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePagination reads the page and pageSize query parameters.
+// On invalid input a 400 is written and ok is false.
+func parsePagination(c *gin.Context) (page int, pageSize int, ok bool) {
+	page, pageSize = 1, defaultPageSize
+
+	if v := c.Query("page"); v != "" {
+		p, err := strconv.Atoi(v)
+		if err != nil || p < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "page must be a positive integer"})
+			return 0, 0, false
+		}
+		page = p
+	}
+	if v := c.Query("pageSize"); v != "" {
+		ps, err := strconv.Atoi(v)
+		if err != nil || ps < 1 || ps > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "pageSize must be between 1 and " + strconv.Itoa(maxPageSize)})
+			return 0, 0, false
+		}
+		pageSize = ps
+	}
+	return page, pageSize, true
+}
+
+// parseIDParam reads a numeric ID path parameter, resource names it in the error message.
+// On invalid input a 400 is written and ok is false.
+func parseIDParam(c *gin.Context, param string, resource string) (id uint, ok bool) {
+	v, err := strconv.ParseUint(c.Param(param), 10, 64)
+	if err != nil || v == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + resource + " ID format"})
+		return 0, false
+	}
+	return uint(v), true
+}
+
+// parseTimeRange reads the created_after, created_before and updated_after query parameters as RFC3339 timestamps.
+// On invalid input a 400 is written and ok is false.
+func parseTimeRange(c *gin.Context) (r models.TimeRange, ok bool) {
+	params := []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &r.CreatedAfter},
+		{"created_before", &r.CreatedBefore},
+		{"updated_after", &r.UpdatedAfter},
+	}
+	for _, p := range params {
+		v := c.Query(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": p.name + " must be an RFC3339 timestamp, e.g. 2026-01-02T15:04:05Z"})
+			return models.TimeRange{}, false
+		}
+		*p.dst = &t
+	}
+	if r.CreatedAfter != nil && r.CreatedBefore != nil && !r.CreatedAfter.Before(*r.CreatedBefore) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "created_after must be before created_before"})
+		return models.TimeRange{}, false
+	}
+	return r, true
+}
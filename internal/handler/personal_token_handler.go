@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PersonalTokenHandler defines the interface for personal access token HTTP handlers
+type PersonalTokenHandler interface {
+	GetTokens(c *gin.Context)
+	CreateToken(c *gin.Context)
+	RevokeToken(c *gin.Context)
+}
+
+// personalTokenHandler implements PersonalTokenHandler
+type personalTokenHandler struct {
+	tokenService service.PersonalTokenService // Dependency on PersonalTokenService
+}
+
+// NewPersonalTokenHandler creates a new PersonalTokenHandler instance
+func NewPersonalTokenHandler(tokenService service.PersonalTokenService) PersonalTokenHandler {
+	return &personalTokenHandler{
+		tokenService: tokenService,
+	}
+}
+
+// GetTokens handles listing the authenticated user's personal access tokens
+func (h *personalTokenHandler) GetTokens(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokenService.GetTokens(c.Request.Context(), a.EffectiveUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve personal access tokens"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewPersonalAccessTokenResponses(tokens))
+}
+
+// CreateToken handles creating a personal access token. The token is only ever returned in this response.
+func (h *personalTokenHandler) CreateToken(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.CreatePersonalAccessTokenRequest
+	if !bindRequest(c, &req, "CreateToken") {
+		return
+	}
+
+	token, secret, err := h.tokenService.CreateToken(c.Request.Context(), a.EffectiveUserID, a.Role, &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "expiresAt must be in the future and within the maximum token lifetime":
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case "the admin scope requires the admin role":
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case "personal access token limit reached":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create personal access token"})
+		}
+		return
+	}
+
+	res := models.NewPersonalAccessTokenResponse(token)
+	res.Token = secret
+	c.JSON(http.StatusCreated, res)
+}
+
+// RevokeToken handles revoking one of the authenticated user's personal access tokens
+func (h *personalTokenHandler) RevokeToken(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	tokenID, ok := parseIDParam(c, "id", "token")
+	if !ok {
+		return
+	}
+
+	if err := h.tokenService.RevokeToken(c.Request.Context(), tokenID, a.EffectiveUserID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ProcessingPurposeHeader carries the purpose code staff declare when they access or export personal data
+const ProcessingPurposeHeader = "X-Processing-Purpose"
+
+// ProcessingHandler defines the interface for processing log HTTP handlers
+type ProcessingHandler interface {
+	GetProcessingLog(c *gin.Context)
+}
+
+// processingHandler implements ProcessingHandler
+type processingHandler struct {
+	processingLogService service.ProcessingLogService // Dependency on ProcessingLogService
+}
+
+// NewProcessingHandler creates a new ProcessingHandler instance
+func NewProcessingHandler(processingLogService service.ProcessingLogService) ProcessingHandler {
+	return &processingHandler{
+		processingLogService: processingLogService,
+	}
+}
+
+// GetProcessingLog handles compliance officers querying the processing log, newest first and paginated
+func (h *processingHandler) GetProcessingLog(c *gin.Context) {
+	var query models.ProcessingLogQuery
+	if !bindRequest(c, &query, "GetProcessingLog") {
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	records, total, err := h.processingLogService.GetRecords(c.Request.Context(), &query, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve processing log"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ProcessingLogResponse{
+		Items:    models.NewProcessingRecordResponses(records),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// processingPurpose reads the purpose code from ProcessingPurposeHeader.
+// On a missing or unknown code a 400 is written and ok is false.
+func processingPurpose(c *gin.Context) (purpose string, ok bool) {
+	purpose = c.GetHeader(ProcessingPurposeHeader)
+	if !models.IsProcessingPurpose(purpose) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": ProcessingPurposeHeader + " must be one of " + strings.Join(models.ProcessingPurposes, ", ")})
+		return "", false
+	}
+	return purpose, true
+}
+
+// recordProcessing logs access to or export of personal data before it is handed out.
+// If it can't be recorded a 500 is written and ok is false, and the data must not be sent.
+func recordProcessing(c *gin.Context, processingLogService service.ProcessingLogService, activity, purpose, resource string, subjectUserID *uint, detail string) (ok bool) {
+	if err := processingLogService.Record(c.Request.Context(), activity, purpose, resource, subjectUserID, detail); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record data processing"})
+		return false
+	}
+	return true
+}
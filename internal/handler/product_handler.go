@@ -1,11 +1,17 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"gotemplate/internal/models"
 	"gotemplate/internal/service"
+	"gotemplate/pkg/actor"
 	"gotemplate/pkg/logger"
 	"net/http"
 	"strconv" // Import for string to uint conversion
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -16,65 +22,80 @@ type ProductHandler interface {
 	AddProduct(c *gin.Context)
 	GetProduct(c *gin.Context)
 	GetProducts(c *gin.Context)
+	GetProductChanges(c *gin.Context)
+	GetUserProducts(c *gin.Context)
 	UpdateProduct(c *gin.Context)
 	DeleteProduct(c *gin.Context)
+	BatchProducts(c *gin.Context)
+	GrantProductPermission(c *gin.Context)
+	GetProductPermissions(c *gin.Context)
+	RevokeProductPermission(c *gin.Context)
+	GetSharedProducts(c *gin.Context)
+	BulkUpdateProducts(c *gin.Context)
+	ImportProducts(c *gin.Context)
 }
 
 // productHandler implements ProductHandler
 type productHandler struct {
-	productService service.ProductService // Dependency on ProductService
+	productService       service.ProductService       // Dependency on ProductService
+	operationService     service.OperationService     // Runs slow product jobs in the background
+	savedSearchService   service.SavedSearchService   // Runs ?saved=<id> listings
+	processingLogService service.ProcessingLogService // Records admin access to users' products
+	productChangeService service.ProductChangeService // Serves the change feed of sync clients
+	productViewService   service.ProductViewService   // Counts views of single products
 }
 
 // NewProductHandler creates a new ProductHandler instance
-func NewProductHandler(productService service.ProductService) ProductHandler {
+func NewProductHandler(productService service.ProductService, operationService service.OperationService, savedSearchService service.SavedSearchService, processingLogService service.ProcessingLogService, productChangeService service.ProductChangeService, productViewService service.ProductViewService) ProductHandler {
 	return &productHandler{
-		productService: productService,
+		productService:       productService,
+		operationService:     operationService,
+		savedSearchService:   savedSearchService,
+		processingLogService: processingLogService,
+		productChangeService: productChangeService,
+		productViewService:   productViewService,
 	}
 }
 
 // AddProduct handles adding a new product
 func (h *productHandler) AddProduct(c *gin.Context) {
-	// Get userID from context (set by AuthMiddleware)
-	userIDFromContext, exists := c.Get("userID")
-	
-	if !exists {
-		logger.Error("userID not found in context for AddProduct", zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
-		return
-	}
-	idStr, ok := userIDFromContext.(string) // Expecting string from JWT parsing
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
 	if !ok {
-		logger.Error("userID in context is not a string for AddProduct", zap.Any("userID", userIDFromContext), zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
-	}
-	userID, err := strconv.ParseUint(idStr, 10, 64) // Convert to uint
-	if err != nil {
-		logger.Error("Failed to parse userID from context to uint for AddProduct", zap.Error(err), zap.String("userIDStr", idStr))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
 		return
 	}
+	userID := a.EffectiveUserID
 
 	var req models.AddProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Warn("Invalid AddProduct request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindRequest(c, &req, "AddProduct") {
 		return
 	}
 
-	product, err := h.productService.AddProduct(c.Request.Context(), uint(userID), &req) // Pass uint
+	product, created, err := h.productService.AddProduct(c.Request.Context(), userID, &req) // Pass uint
 	if err != nil {
-		logger.Error("Failed to add product", zap.Error(err), zap.Uint("userID", uint(userID))) // Use zap.Uint
+		logger.Error("Failed to add product", zap.Error(err), zap.Uint("userID", userID)) // Use zap.Uint
+		if respondIfInvalid(c, err) {
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add product"})
 		return
 	}
 
-	logger.Info("Product added successfully via API", zap.Uint("productID", product.ID), zap.Uint("userID", uint(userID))) // Use zap.Uint
-	c.JSON(http.StatusCreated, product)                                                                                    // Product will be marshaled correctly with uint ID
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK // A replayed create with a known client ID
+	}
+	logger.Info("Product added successfully via API", zap.Uint("productID", product.ID), zap.Uint("userID", userID)) // Use zap.Uint
+	setProductETag(c, product)
+	writeRedacted(c, status, a, models.NewProductResponse(product))
 }
 
 // GetProduct handles retrieving a single product by ID
 func (h *productHandler) GetProduct(c *gin.Context) {
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+
 	productIDStr := c.Param("id") // Get product ID from URL parameter (string)
 	if productIDStr == "" {
 		logger.Warn("Product ID is missing in GetProduct request")
@@ -89,47 +110,192 @@ func (h *productHandler) GetProduct(c *gin.Context) {
 		return
 	}
 
-	product, err := h.productService.GetProduct(c.Request.Context(), uint(productID)) // Pass uint
+	product, err := h.productService.GetProduct(c.Request.Context(), uint(productID), a.EffectiveUserID, a.Role) // Pass uint
 	if err != nil {
 		logger.Error("Failed to get product", zap.Error(err), zap.Uint("productID", uint(productID))) // Use zap.Uint
-		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		if errors.Is(err, service.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product"})
+		}
 		return
 	}
 
 	logger.Info("Product retrieved successfully via API", zap.Uint("productID", product.ID)) // Use zap.Uint
-	c.JSON(http.StatusOK, product)                                                           // Product will be marshaled correctly with uint ID
+	h.productViewService.RecordView(product, a.EffectiveUserID)
+	setProductETag(c, product)
+	writeRedacted(c, http.StatusOK, a, models.NewProductResponse(product)) // Owner contact details depend on the caller's role
 }
 
-// GetProducts handles retrieving all products for the authenticated user
+// GetProducts handles retrieving all products for the authenticated user.
+// Sync clients pass updated_after (and created_after/created_before) to fetch only what changed, see parseTimeRange.
+// Clients sending Accept: application/x-ndjson get one product per line, streamed as it is read, see streamProducts;
+// saved searches included.
 func (h *productHandler) GetProducts(c *gin.Context) {
-	userIDFromContext, exists := c.Get("userID") // Get userID from context
-	if !exists {
-		logger.Error("userID not found in context for GetProducts", zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+	userID := a.EffectiveUserID
+
+	if c.Query("saved") != "" {
+		h.getSavedSearchProducts(c, a)
 		return
 	}
-	idStr, ok := userIDFromContext.(string)
+	timeRange, ok := parseTimeRange(c)
 	if !ok {
-		logger.Error("userID in context is not a string for GetProducts", zap.Any("userID", userIDFromContext), zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
-	userID, err := strconv.ParseUint(idStr, 10, 64) // Convert to uint
+
+	if wantsNDJSON(c) {
+		h.streamProducts(c, a, userID, timeRange)
+		return
+	}
+
+	products, err := h.productService.GetProductsByOwner(c.Request.Context(), userID, timeRange) // Pass uint
 	if err != nil {
-		logger.Error("Failed to parse userID from context to uint for GetProducts", zap.Error(err), zap.String("userIDStr", idStr))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		logger.Error("Failed to get products for user", zap.Error(err), zap.Uint("userID", userID)) // Use zap.Uint
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve products"})
+		return
+	}
+
+	logger.Info("Products retrieved successfully for user via API", zap.Uint("userID", userID), zap.Int("count", len(products))) // Use zap.Uint
+	writeRedacted(c, http.StatusOK, a, models.NewProductResponses(products))
+}
+
+// streamProducts writes the products of a user as newline-delimited JSON, in ID order, without loading them all
+func (h *productHandler) streamProducts(c *gin.Context, a actor.Actor, ownerID uint, timeRange models.TimeRange) {
+	stream := newNDJSONStream(c, a)
+	err := h.productService.StreamProductsByOwner(c.Request.Context(), ownerID, timeRange, func(p *models.Product) error {
+		return stream.Write(models.NewProductResponse(p))
+	})
+	if err != nil && c.Request.Context().Err() == nil {
+		logger.Error("Failed to stream products of user", zap.Error(err), zap.Uint("ownerID", ownerID), actor.Field(c.Request.Context()))
+	}
+	stream.End(err, "Failed to retrieve products")
+	logger.Info("Products streamed via API", zap.Uint("ownerID", ownerID), zap.Int("count", stream.rows), actor.Field(c.Request.Context()))
+}
+
+// GetProductChanges handles the change feed of the authenticated user's products for clients syncing incrementally.
+// A call without since returns every product and a token; later calls pass the last token as since and get what
+// changed after it. While hasMore is set the client should call again right away.
+func (h *productHandler) GetProductChanges(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	limit := defaultPageSize
+	if v := c.Query("limit"); v != "" {
+		l, err := strconv.Atoi(v)
+		if err != nil || l < 1 || l > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and " + strconv.Itoa(maxPageSize)})
+			return
+		}
+		limit = l
+	}
+
+	res, err := h.productChangeService.GetChanges(c.Request.Context(), a.EffectiveUserID, c.Query("since"), limit)
+	if err != nil {
+		switch err.Error() {
+		case "invalid change token":
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid change token"})
+		case "change token expired":
+			c.JSON(http.StatusGone, gin.H{"error": "Change token expired, sync again without since"})
+		default:
+			logger.Error("Failed to get product changes", zap.Error(err), actor.Field(c.Request.Context()))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product changes"})
+		}
+		return
+	}
+
+	writeRedacted(c, http.StatusOK, a, res)
+}
+
+// getSavedSearchProducts lists the user's products matching the saved search named by the saved query parameter
+func (h *productHandler) getSavedSearchProducts(c *gin.Context, a actor.Actor) {
+	savedSearchID, err := strconv.ParseUint(c.Query("saved"), 10, 64)
+	if err != nil || savedSearchID == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved search ID format"})
+		return
+	}
+
+	if wantsNDJSON(c) {
+		h.streamSavedSearchProducts(c, a, uint(savedSearchID))
+		return
+	}
+
+	products, err := h.savedSearchService.RunSavedSearch(c.Request.Context(), uint(savedSearchID), a.EffectiveUserID)
+	if err != nil {
+		writeSavedSearchError(c, err, "Failed to retrieve products")
+		return
+	}
+
+	logger.Info("Saved search products retrieved via API", zap.Uint("savedSearchID", uint(savedSearchID)), zap.Int("count", len(products)), actor.Field(c.Request.Context()))
+	writeRedacted(c, http.StatusOK, a, models.NewProductResponses(products))
+}
+
+// streamSavedSearchProducts writes the products matching a saved search as newline-delimited JSON, like
+// streamProducts. Errors about the saved search itself come before the first row and get their usual status.
+func (h *productHandler) streamSavedSearchProducts(c *gin.Context, a actor.Actor, savedSearchID uint) {
+	stream := newNDJSONStream(c, a)
+	err := h.savedSearchService.StreamSavedSearch(c.Request.Context(), savedSearchID, a.EffectiveUserID, func(p *models.Product) error {
+		return stream.Write(models.NewProductResponse(p))
+	})
+	if err != nil && !stream.started {
+		if !respondIfBudgetExhausted(c, err) {
+			writeSavedSearchError(c, err, "Failed to retrieve products")
+		}
 		return
 	}
+	if err != nil && c.Request.Context().Err() == nil {
+		logger.Error("Failed to stream saved search products", zap.Error(err), zap.Uint("savedSearchID", savedSearchID), actor.Field(c.Request.Context()))
+	}
+	stream.End(err, "Failed to retrieve products")
+	logger.Info("Saved search products streamed via API", zap.Uint("savedSearchID", savedSearchID), zap.Int("count", stream.rows), actor.Field(c.Request.Context()))
+}
 
-	products, err := h.productService.GetProductsByOwner(c.Request.Context(), uint(userID)) // Pass uint
+// GetUserProducts handles the admin listing of any user's products, for support staff inspecting a customer's data.
+// It is the owner listing with the owner taken from the path, NDJSON included; the route is declared admin-only.
+// The admin must declare a purpose, and the access is written to the processing log.
+func (h *productHandler) GetUserProducts(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	ownerID, ok := parseIDParam(c, "id", "user")
+	if !ok {
+		return
+	}
+	timeRange, ok := parseTimeRange(c)
+	if !ok {
+		return
+	}
+	purpose, ok := processingPurpose(c)
+	if !ok {
+		return
+	}
+
+	if wantsNDJSON(c) {
+		// Rows go out as they are read, so the access is recorded up front
+		if !recordProcessing(c, h.processingLogService, models.ProcessingAccess, purpose, "products", &ownerID, "") {
+			return
+		}
+		h.streamProducts(c, a, ownerID, timeRange)
+		return
+	}
+
+	products, err := h.productService.GetProductsByOwner(c.Request.Context(), ownerID, timeRange)
 	if err != nil {
-		logger.Error("Failed to get products for user", zap.Error(err), zap.Uint("userID", uint(userID))) // Use zap.Uint
+		logger.Error("Failed to get products of user for admin", zap.Error(err), zap.Uint("ownerID", ownerID), actor.Field(c.Request.Context()))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve products"})
 		return
 	}
+	if !recordProcessing(c, h.processingLogService, models.ProcessingAccess, purpose, "products", &ownerID, "") {
+		return
+	}
 
-	logger.Info("Products retrieved successfully for user via API", zap.Uint("userID", uint(userID)), zap.Int("count", len(products))) // Use zap.Uint
-	c.JSON(http.StatusOK, products)
+	logger.Info("Admin retrieved products of user", zap.Uint("ownerID", ownerID), zap.Int("count", len(products)), actor.Field(c.Request.Context()))
+	writeRedacted(c, http.StatusOK, a, models.NewProductResponses(products))
 }
 
 // UpdateProduct handles updating an existing product
@@ -147,38 +313,30 @@ func (h *productHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	userIDFromContext, exists := c.Get("userID") // Get userID from context
-	if !exists {
-		logger.Error("userID not found in context for UpdateProduct", zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
-		return
-	}
-	idStr, ok := userIDFromContext.(string)
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
 	if !ok {
-		logger.Error("userID in context is not a string for UpdateProduct", zap.Any("userID", userIDFromContext), zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
-		return
-	}
-	userID, err := strconv.ParseUint(idStr, 10, 64) // Convert to uint
-	if err != nil {
-		logger.Error("Failed to parse userID from context to uint for UpdateProduct", zap.Error(err), zap.String("userIDStr", idStr))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
 		return
 	}
+	userID := a.EffectiveUserID
 
 	var req models.UpdateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Warn("Invalid UpdateProduct request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindRequest(c, &req, "UpdateProduct") {
+		return
+	}
+	pre, ok := parseProductPrecondition(c)
+	if !ok {
 		return
 	}
 
-	product, err := h.productService.UpdateProduct(c.Request.Context(), uint(productID), uint(userID), &req) // Pass uints
+	product, err := h.productService.UpdateProduct(c.Request.Context(), uint(productID), userID, &req, pre) // Pass uints
 	if err != nil {
-		logger.Error("Failed to update product", zap.Error(err), zap.Uint("productID", uint(productID)), zap.Uint("userID", uint(userID))) // Use zap.Uint
-		if err.Error() == "product not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else if err.Error() == "you are not authorized to update this product" {
+		logger.Error("Failed to update product", zap.Error(err), zap.Uint("productID", uint(productID)), zap.Uint("userID", userID)) // Use zap.Uint
+		if respondIfInvalid(c, err) || respondIfConflict(c, a, err, &req) {
+			return
+		}
+		if errors.Is(err, service.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		} else if errors.Is(err, service.ErrProductForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update product"})
@@ -186,8 +344,9 @@ func (h *productHandler) UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	logger.Info("Product updated successfully via API", zap.Uint("productID", product.ID), zap.Uint("userID", uint(userID))) // Use zap.Uint
-	c.JSON(http.StatusOK, product)
+	logger.Info("Product updated successfully via API", zap.Uint("productID", product.ID), zap.Uint("userID", userID)) // Use zap.Uint
+	setProductETag(c, product)
+	writeRedacted(c, http.StatusOK, a, models.NewProductResponse(product))
 }
 
 // DeleteProduct handles deleting a product
@@ -205,31 +364,26 @@ func (h *productHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	userIDFromContext, exists := c.Get("userID") // Get userID from context
-	if !exists {
-		logger.Error("userID not found in context for DeleteProduct", zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
-		return
-	}
-	idStr, ok := userIDFromContext.(string)
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
 	if !ok {
-		logger.Error("userID in context is not a string for DeleteProduct", zap.Any("userID", userIDFromContext), zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
-	userID, err := strconv.ParseUint(idStr, 10, 64) // Convert to uint
-	if err != nil {
-		logger.Error("Failed to parse userID from context to uint for DeleteProduct", zap.Error(err), zap.String("userIDStr", idStr))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+	userID := a.EffectiveUserID
+
+	pre, ok := parseProductPrecondition(c)
+	if !ok {
 		return
 	}
 
-	err = h.productService.DeleteProduct(c.Request.Context(), uint(productID), uint(userID)) // Pass uints
+	err = h.productService.DeleteProduct(c.Request.Context(), uint(productID), userID, pre) // Pass uints
 	if err != nil {
-		logger.Error("Failed to delete product", zap.Error(err), zap.Uint("productID", uint(productID)), zap.Uint("userID", uint(userID))) // Use zap.Uint
-		if err.Error() == "product not found" {
-			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
-		} else if err.Error() == "you are not authorized to delete this product" {
+		logger.Error("Failed to delete product", zap.Error(err), zap.Uint("productID", uint(productID)), zap.Uint("userID", userID)) // Use zap.Uint
+		if respondIfConflict(c, a, err, nil) {
+			return
+		}
+		if errors.Is(err, service.ErrProductNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+		} else if errors.Is(err, service.ErrProductForbidden) {
 			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
 		} else {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete product"})
@@ -237,6 +391,301 @@ func (h *productHandler) DeleteProduct(c *gin.Context) {
 		return
 	}
 
-	logger.Info("Product deleted successfully via API", zap.Uint("productID", uint(productID)), zap.Uint("userID", uint(userID))) // Use zap.Uint
-	c.JSON(http.StatusNoContent, nil)                                                                                             // 204 No Content for successful deletion
+	logger.Info("Product deleted successfully via API", zap.Uint("productID", uint(productID)), zap.Uint("userID", userID)) // Use zap.Uint
+	c.JSON(http.StatusNoContent, nil)                                                                                       // 204 No Content for successful deletion
+}
+
+// BatchProducts handles a mix of creates, updates and deletes run in one transaction.
+// Responds 200 with each operation's result once committed, or 422 when an atomic batch was rolled back.
+func (h *productHandler) BatchProducts(c *gin.Context) {
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+	userID := a.EffectiveUserID
+
+	var req models.ProductBatchRequest
+	if !bindRequest(c, &req, "BatchProducts") {
+		return
+	}
+
+	result, err := h.productService.BatchProducts(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.Error("Failed to apply product batch", zap.Error(err), zap.Uint("userID", userID))
+		if respondIfInvalid(c, err) {
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply product batch"})
+		return
+	}
+
+	status := http.StatusOK
+	if !result.Committed {
+		status = http.StatusUnprocessableEntity
+	}
+	writeRedacted(c, status, a, result)
+}
+
+// GrantProductPermission handles sharing a product with another user for reading or writing
+func (h *productHandler) GrantProductPermission(c *gin.Context) {
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+
+	var req models.GrantProductPermissionRequest
+	if !bindRequest(c, &req, "GrantProductPermission") {
+		return
+	}
+
+	permission, err := h.productService.GrantProductPermission(c.Request.Context(), productID, a.EffectiveUserID, &req)
+	if err != nil {
+		logger.Error("Failed to grant product permission", zap.Error(err), zap.Uint("productID", productID), zap.Uint("userID", a.EffectiveUserID))
+		if !respondIfInvalid(c, err) && !respondIfPermissionError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant product permission"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, models.NewProductPermissionResponse(permission))
+}
+
+// GetProductPermissions handles listing the users a product is shared with
+func (h *productHandler) GetProductPermissions(c *gin.Context) {
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+
+	permissions, err := h.productService.GetProductPermissions(c.Request.Context(), productID, a.EffectiveUserID)
+	if err != nil {
+		logger.Error("Failed to get product permissions", zap.Error(err), zap.Uint("productID", productID), zap.Uint("userID", a.EffectiveUserID))
+		if !respondIfPermissionError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product permissions"})
+		}
+		return
+	}
+
+	res := make([]*models.ProductPermissionResponse, len(permissions))
+	for i, p := range permissions {
+		res[i] = models.NewProductPermissionResponse(p)
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// RevokeProductPermission handles removing a user's access to a product
+func (h *productHandler) RevokeProductPermission(c *gin.Context) {
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "userId", "user")
+	if !ok {
+		return
+	}
+
+	if err := h.productService.RevokeProductPermission(c.Request.Context(), productID, a.EffectiveUserID, userID); err != nil {
+		logger.Error("Failed to revoke product permission", zap.Error(err), zap.Uint("productID", productID), zap.Uint("userID", a.EffectiveUserID))
+		if !respondIfPermissionError(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke product permission"})
+		}
+		return
+	}
+	c.JSON(http.StatusNoContent, nil)
+}
+
+// GetSharedProducts handles listing the products other users shared with the authenticated user
+func (h *productHandler) GetSharedProducts(c *gin.Context) {
+	a, ok := requireActor(c) // Actor is set by AuthMiddleware
+	if !ok {
+		return
+	}
+
+	shared, err := h.productService.GetSharedProducts(c.Request.Context(), a.EffectiveUserID)
+	if err != nil {
+		logger.Error("Failed to get shared products", zap.Error(err), zap.Uint("userID", a.EffectiveUserID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve shared products"})
+		return
+	}
+
+	res := make([]*models.SharedProductResponse, len(shared))
+	for i, p := range shared {
+		res[i] = &models.SharedProductResponse{ProductResponse: models.NewProductResponse(p.Product), Access: p.Access}
+	}
+	writeRedacted(c, http.StatusOK, a, res)
+}
+
+// respondIfPermissionError writes the status of the errors shared by the product permission endpoints
+// and returns true if err is one of them
+func respondIfPermissionError(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, service.ErrProductNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+	case errors.Is(err, service.ErrProductForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err.Error() == "user not found", err.Error() == "permission not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
+
+// parseProductPrecondition reads the If-Match and If-Unmodified-Since headers of a product write.
+// If-Match lists product versions, as sent in the ETag header and the version field; it returns nil without either header.
+// On an invalid If-Match a 400 is written and ok is false. An invalid If-Unmodified-Since is ignored, as HTTP requires.
+func parseProductPrecondition(c *gin.Context) (pre *models.ProductPrecondition, ok bool) {
+	ifMatch := c.GetHeader("If-Match")
+	ifUnmodifiedSince := c.GetHeader("If-Unmodified-Since")
+	if ifMatch == "" && ifUnmodifiedSince == "" {
+		return nil, true
+	}
+
+	pre = &models.ProductPrecondition{}
+	if ifMatch != "" && strings.TrimSpace(ifMatch) != "*" {
+		for _, tag := range strings.Split(ifMatch, ",") {
+			tag = strings.Trim(strings.TrimPrefix(strings.TrimSpace(tag), "W/"), `"`)
+			v, err := strconv.ParseInt(tag, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "If-Match must list product versions"})
+				return nil, false
+			}
+			pre.Versions = append(pre.Versions, v)
+		}
+	}
+	if t, err := http.ParseTime(ifUnmodifiedSince); err == nil && ifMatch == "" {
+		pre.UnmodifiedSince = &t // If-Match takes precedence when both are sent
+	}
+	return pre, true
+}
+
+// setProductETag sets the ETag header to the product's version, for If-Match on later writes
+func setProductETag(c *gin.Context, product *models.Product) {
+	c.Header("ETag", fmt.Sprintf(`"%d"`, product.Version()))
+}
+
+// respondIfConflict writes a 412 with the stored and the client's copy and returns true if err is a failed
+// write precondition. client is the rejected payload, nil for deletes.
+func respondIfConflict(c *gin.Context, a actor.Actor, err error, client interface{}) bool {
+	var conflict *service.ProductConflictError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	res := &models.ProductConflictResponse{Error: "Product was modified since the version the client has", Client: client}
+	if conflict.Current != nil {
+		res.Server = models.NewProductResponse(conflict.Current)
+		setProductETag(c, conflict.Current)
+	}
+	writeRedacted(c, http.StatusPreconditionFailed, a, res)
+	return true
+}
+
+// BulkUpdateProducts handles the admin bulk data-fix endpoint.
+// Dry runs are answered synchronously; real updates run as an operation and return 202.
+func (h *productHandler) BulkUpdateProducts(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.BulkUpdateProductsRequest
+	if !bindRequest(c, &req, "BulkUpdateProducts") {
+		return
+	}
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.DryRun {
+		result, err := h.productService.BulkUpdateProducts(c.Request.Context(), &req, nil)
+		if err != nil {
+			logger.Error("Bulk update dry run failed", zap.Error(err))
+			if respondIfBudgetExhausted(c, err) {
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to evaluate bulk update"})
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	op, err := h.operationService.Start(c.Request.Context(), a.UserID, "product_bulk_update", func(ctx context.Context, report service.ProgressFunc) (interface{}, error) {
+		return h.productService.BulkUpdateProducts(ctx, &req, report)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start bulk update"})
+		return
+	}
+
+	respondAccepted(c, op)
+}
+
+// maxImportSize caps the size of an uploaded import file after any gzip decoding
+const maxImportSize = 256 << 20 // 256 MiB
+
+// importUploadTimeout is how long the upload of an import file may take, in place of the server read timeout
+const importUploadTimeout = 5 * time.Minute
+
+// importResponseTimeout is how long writing the response may take once the upload is read
+const importResponseTimeout = 10 * time.Second
+
+// ImportProducts handles importing products from an uploaded file in the format named by the format query parameter.
+// The file is the raw request body, optionally gzip-encoded. It is streamed to a temporary file rather than held
+// in memory, checked up front and then imported as an operation that reads it back.
+func (h *productHandler) ImportProducts(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	format := c.Query("format")
+	rc := http.NewResponseController(c.Writer)
+	_ = rc.SetReadDeadline(time.Now().Add(importUploadTimeout))
+	_ = rc.SetWriteDeadline(time.Now().Add(importUploadTimeout + importResponseTimeout))
+	file, size, err := spoolUpload(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize), "product-import-*")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Import file must be at most %d MiB", tooLarge.Limit>>20)})
+			return
+		}
+		logger.Warn("Failed to read product import upload", zap.Error(err), zap.String("format", format))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read import file"})
+		return
+	}
+	_ = rc.SetWriteDeadline(time.Now().Add(importResponseTimeout))
+
+	connector, err := service.NewImportConnector(format, file, size)
+	if err != nil {
+		removeUpload(file)
+		logger.Warn("Rejected product import", zap.Error(err), zap.String("format", format))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID := a.EffectiveUserID
+	op, err := h.operationService.Start(c.Request.Context(), a.UserID, "product_import", func(ctx context.Context, report service.ProgressFunc) (interface{}, error) {
+		defer removeUpload(file)
+		return h.productService.ImportProducts(ctx, userID, format, connector, report)
+	})
+	if err != nil {
+		removeUpload(file)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start import"})
+		return
+	}
+
+	respondAccepted(c, op)
 }
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ReportHandler defines the interface for content report HTTP handlers
+type ReportHandler interface {
+	ReportProduct(c *gin.Context)
+	ReportComment(c *gin.Context)
+	GetModerationQueue(c *gin.Context)
+	ResolveReport(c *gin.Context)
+}
+
+// reportHandler implements ReportHandler
+type reportHandler struct {
+	reportService service.ReportService // Dependency on ReportService
+}
+
+// NewReportHandler creates a new ReportHandler instance
+func NewReportHandler(reportService service.ReportService) ReportHandler {
+	return &reportHandler{
+		reportService: reportService,
+	}
+}
+
+// ReportProduct handles flagging a product
+func (h *reportHandler) ReportProduct(c *gin.Context) {
+	h.report(c, models.ReportTargetProduct)
+}
+
+// ReportComment handles flagging a comment
+func (h *reportHandler) ReportComment(c *gin.Context) {
+	h.report(c, models.ReportTargetComment)
+}
+
+// report is shared by ReportProduct and ReportComment
+func (h *reportHandler) report(c *gin.Context, targetType string) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	targetID, ok := parseIDParam(c, "id", targetType)
+	if !ok {
+		return
+	}
+
+	var req models.CreateReportRequest
+	if !bindRequest(c, &req, "report", zap.String("targetType", targetType)) {
+		return
+	}
+
+	report, err := h.reportService.ReportContent(c.Request.Context(), targetType, targetID, a.EffectiveUserID, &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		switch err.Error() {
+		case targetType + " not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "you have already reported this content":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to report content"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewReportResponse(report))
+}
+
+// GetModerationQueue handles admins listing reports, open ones by default
+func (h *reportHandler) GetModerationQueue(c *gin.Context) {
+	status := c.DefaultQuery("status", models.ReportStatusOpen)
+	if status != models.ReportStatusOpen && status != models.ReportStatusActioned && status != models.ReportStatusDismissed {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be one of open, actioned, dismissed"})
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	reports, total, err := h.reportService.GetModerationQueue(c.Request.Context(), status, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reports"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.ReportListResponse{
+		Items:    models.NewReportResponses(reports),
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	})
+}
+
+// ResolveReport handles admins actioning or dismissing a report
+func (h *reportHandler) ResolveReport(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	reportID, ok := parseIDParam(c, "id", "report")
+	if !ok {
+		return
+	}
+
+	var req models.ResolveReportRequest
+	if !bindRequest(c, &req, "ResolveReport") {
+		return
+	}
+
+	report, err := h.reportService.ResolveReport(c.Request.Context(), reportID, a.UserID, &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		switch err.Error() {
+		case "report not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "report has already been resolved":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve report"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewReportResponse(report))
+}
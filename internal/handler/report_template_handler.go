@@ -0,0 +1,175 @@
+package handler
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// reportStatusTrailer is sent after the rows of a report: "complete", or "failed" when the report stopped
+// midway, since the status of the response went out with the first row
+const reportStatusTrailer = "X-Report-Status"
+
+// ReportTemplateHandler defines the interface for report template HTTP handlers
+type ReportTemplateHandler interface {
+	GetReportTemplates(c *gin.Context)
+	GetReportTemplate(c *gin.Context)
+	CreateReportTemplate(c *gin.Context)
+	DeleteReportTemplate(c *gin.Context)
+	RunReport(c *gin.Context)
+}
+
+// reportTemplateHandler implements ReportTemplateHandler
+type reportTemplateHandler struct {
+	reportTemplateService service.ReportTemplateService // Dependency on ReportTemplateService
+	processingLogService  service.ProcessingLogService  // Reports export personal data, which is logged
+}
+
+// NewReportTemplateHandler creates a new ReportTemplateHandler instance
+func NewReportTemplateHandler(reportTemplateService service.ReportTemplateService, processingLogService service.ProcessingLogService) ReportTemplateHandler {
+	return &reportTemplateHandler{
+		reportTemplateService: reportTemplateService,
+		processingLogService:  processingLogService,
+	}
+}
+
+// GetReportTemplates handles listing report templates
+func (h *reportTemplateHandler) GetReportTemplates(c *gin.Context) {
+	templates, err := h.reportTemplateService.GetReportTemplates(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve report templates"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewReportTemplateResponses(templates))
+}
+
+// GetReportTemplate handles retrieving a report template, with the parameters a run needs
+func (h *reportTemplateHandler) GetReportTemplate(c *gin.Context) {
+	templateID, ok := parseIDParam(c, "id", "report template")
+	if !ok {
+		return
+	}
+
+	template, err := h.reportTemplateService.GetReportTemplate(c.Request.Context(), templateID)
+	if err != nil {
+		writeReportTemplateError(c, err, "Failed to retrieve report template")
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewReportTemplateResponse(template))
+}
+
+// CreateReportTemplate handles defining a report template
+func (h *reportTemplateHandler) CreateReportTemplate(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.ReportTemplateRequest
+	if !bindRequest(c, &req, "CreateReportTemplate") {
+		return
+	}
+
+	template, err := h.reportTemplateService.CreateReportTemplate(c.Request.Context(), a.UserID, &req)
+	if err != nil {
+		writeReportTemplateError(c, err, "Failed to create report template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewReportTemplateResponse(template))
+}
+
+// DeleteReportTemplate handles removing a report template
+func (h *reportTemplateHandler) DeleteReportTemplate(c *gin.Context) {
+	templateID, ok := parseIDParam(c, "id", "report template")
+	if !ok {
+		return
+	}
+
+	if err := h.reportTemplateService.DeleteReportTemplate(c.Request.Context(), templateID); err != nil {
+		writeReportTemplateError(c, err, "Failed to delete report template")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RunReport handles running a report template and downloading it as CSV. The template's parameters are given as
+// query parameters. Rows are written as they are read; see reportStatusTrailer for reports failing midway.
+func (h *reportTemplateHandler) RunReport(c *gin.Context) {
+	templateID, ok := parseIDParam(c, "id", "report template")
+	if !ok {
+		return
+	}
+	purpose, ok := processingPurpose(c)
+	if !ok {
+		return
+	}
+	params := map[string]string{}
+	for name, values := range c.Request.URL.Query() {
+		params[name] = values[0]
+	}
+	if !recordProcessing(c, h.processingLogService, models.ProcessingExport, purpose, "report_templates", nil, strconv.FormatUint(uint64(templateID), 10)) {
+		return
+	}
+
+	w := csv.NewWriter(c.Writer)
+	rows := 0
+	err := h.reportTemplateService.RunReport(c.Request.Context(), templateID, params, func(cells []string) error {
+		if rows == 0 {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			c.Header("Content-Disposition", `attachment; filename="report-`+strconv.FormatUint(uint64(templateID), 10)+`.csv"`)
+			c.Header("Trailer", reportStatusTrailer)
+			c.Status(http.StatusOK)
+			c.Writer.WriteHeaderNow()
+		}
+		if err := w.Write(cells); err != nil {
+			return err
+		}
+		rows++
+		if rows%ndjsonFlushRows == 0 {
+			w.Flush()
+			c.Writer.Flush()
+		}
+		return w.Error()
+	})
+	if rows == 0 {
+		writeReportTemplateError(c, err, "Failed to run report")
+		return
+	}
+
+	w.Flush()
+	status := "complete"
+	if err != nil {
+		status = "failed"
+		if !errors.Is(err, context.Canceled) || c.Request.Context().Err() == nil {
+			logger.Error("Report failed after it started", zap.Error(err), zap.Uint("reportTemplateID", templateID), zap.Int("rows", rows))
+		}
+	}
+	c.Writer.Header().Set(reportStatusTrailer, status)
+}
+
+// writeReportTemplateError maps report template service errors to HTTP responses
+func writeReportTemplateError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err.Error() == "report template not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), validation.ErrorPrefix):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case respondIfBudgetExhausted(c, err):
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"errors"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/apiversion"
+	"gotemplate/pkg/deadline"
+	"gotemplate/pkg/redact"
+	"gotemplate/pkg/validation"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// writeRedacted writes body as JSON after stripping the fields the actor's role may not see, and the
+// deprecated fields removed in the client's API version. Responses still carrying deprecated fields get a
+// Deprecation header, and the fields are reported to the sunset telemetry. body must be a pointer to a DTO or a slice of DTO pointers.
+func writeRedacted(c *gin.Context, status int, a actor.Actor, body interface{}) {
+	redact.ForRole(body, a.Role)
+	if fields := apiversion.ForVersion(body, apiversion.FromContext(c.Request.Context())); len(fields) > 0 {
+		c.Header("Deprecation", "true")
+		for _, field := range fields {
+			apiversion.MarkUsed(c.Request.Context(), "field:"+field)
+		}
+	}
+	c.JSON(status, body)
+}
+
+// respondIfBudgetExhausted writes a 504 and returns true if err means the request ran out of its time budget
+func respondIfBudgetExhausted(c *gin.Context, err error) bool {
+	if !errors.Is(err, deadline.ErrBudgetExhausted) {
+		return false
+	}
+	c.JSON(http.StatusGatewayTimeout, gin.H{"error": "Request time budget exhausted"})
+	return true
+}
+
+// respondIfInvalid writes a 400 and returns true if err is a validation error returned by a service
+func respondIfInvalid(c *gin.Context, err error) bool {
+	if !strings.HasPrefix(err.Error(), validation.ErrorPrefix) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	return true
+}
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/validation"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SavedSearchHandler defines the interface for saved search HTTP handlers
+type SavedSearchHandler interface {
+	GetSavedSearches(c *gin.Context)
+	SaveSearch(c *gin.Context)
+	DeleteSavedSearch(c *gin.Context)
+}
+
+// savedSearchHandler implements SavedSearchHandler
+type savedSearchHandler struct {
+	savedSearchService service.SavedSearchService // Dependency on SavedSearchService
+}
+
+// NewSavedSearchHandler creates a new SavedSearchHandler instance
+func NewSavedSearchHandler(savedSearchService service.SavedSearchService) SavedSearchHandler {
+	return &savedSearchHandler{
+		savedSearchService: savedSearchService,
+	}
+}
+
+// GetSavedSearches handles listing the authenticated user's saved searches
+func (h *savedSearchHandler) GetSavedSearches(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	searches, err := h.savedSearchService.GetSavedSearches(c.Request.Context(), a.EffectiveUserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve saved searches"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.NewSavedSearchResponses(searches))
+}
+
+// SaveSearch handles saving a named filter set, which GET /products?saved=<id> runs
+func (h *savedSearchHandler) SaveSearch(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	var req models.SavedSearchRequest
+	if !bindRequest(c, &req, "SaveSearch") {
+		return
+	}
+
+	search, err := h.savedSearchService.SaveSearch(c.Request.Context(), a.EffectiveUserID, &req)
+	if err != nil {
+		writeSavedSearchError(c, err, "Failed to save search")
+		return
+	}
+
+	c.JSON(http.StatusCreated, models.NewSavedSearchResponse(search))
+}
+
+// DeleteSavedSearch handles removing one of the authenticated user's saved searches
+func (h *savedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	savedSearchID, ok := parseIDParam(c, "id", "saved search")
+	if !ok {
+		return
+	}
+
+	if err := h.savedSearchService.DeleteSavedSearch(c.Request.Context(), savedSearchID, a.EffectiveUserID); err != nil {
+		writeSavedSearchError(c, err, "Failed to delete saved search")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// writeSavedSearchError maps saved search service errors to HTTP responses
+func writeSavedSearchError(c *gin.Context, err error, fallback string) {
+	switch {
+	case err.Error() == "saved search not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err.Error() == "saved search limit reached":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case strings.HasPrefix(err.Error(), validation.ErrorPrefix):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSearchQueryLength bounds the search text, longer input is not a realistic query
+const maxSearchQueryLength = 200
+
+// SearchHandler defines the interface for search HTTP handlers
+type SearchHandler interface {
+	Search(c *gin.Context)
+}
+
+// searchHandler implements SearchHandler
+type searchHandler struct {
+	searchService        service.SearchService        // Dependency on SearchService
+	processingLogService service.ProcessingLogService // Records admins searching users
+}
+
+// NewSearchHandler creates a new SearchHandler instance
+func NewSearchHandler(searchService service.SearchService, processingLogService service.ProcessingLogService) SearchHandler {
+	return &searchHandler{
+		searchService:        searchService,
+		processingLogService: processingLogService,
+	}
+}
+
+// Search handles searching across result types. The types query parameter (comma-separated) narrows the search,
+// which is how a client pages through one type; by default every type the actor may see is searched.
+// Searching users is processing personal data: admins must declare a purpose (X-Processing-Purpose) and the search
+// is written to the processing log. Without a purpose the default search leaves users out.
+func (h *searchHandler) Search(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is required"})
+		return
+	}
+	if utf8.RuneCountInString(q) > maxSearchQueryLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q is too long"})
+		return
+	}
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	isAdmin := a.Role == models.RoleAdmin
+	types := []string{models.SearchTypeProducts}
+	if isAdmin && c.GetHeader(ProcessingPurposeHeader) != "" {
+		types = append(types, models.SearchTypeUsers)
+	}
+	if v := c.Query("types"); v != "" {
+		types = nil
+		for _, t := range strings.Split(v, ",") {
+			switch t = strings.TrimSpace(t); t {
+			case models.SearchTypeProducts:
+			case models.SearchTypeUsers:
+				if !isAdmin {
+					c.JSON(http.StatusForbidden, gin.H{"error": "Only admins can search users"})
+					return
+				}
+			default:
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Unknown search type " + t})
+				return
+			}
+			types = append(types, t)
+		}
+	}
+
+	for _, t := range types {
+		if t != models.SearchTypeUsers {
+			continue
+		}
+		purpose, ok := processingPurpose(c)
+		if !ok {
+			return
+		}
+		if !recordProcessing(c, h.processingLogService, models.ProcessingAccess, purpose, "users", nil, q) {
+			return
+		}
+	}
+
+	response, err := h.searchService.Search(c.Request.Context(), q, types, a.EffectiveUserID, a.Role, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
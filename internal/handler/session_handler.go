@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/logger"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// SessionHandler defines the interface for cookie session HTTP handlers
+type SessionHandler interface {
+	Login(c *gin.Context)
+	Logout(c *gin.Context)
+}
+
+// sessionHandler implements SessionHandler
+type sessionHandler struct {
+	sessionService service.SessionService
+	cfg            config.SessionConfig // Cookie attributes
+}
+
+// NewSessionHandler creates a new SessionHandler instance
+func NewSessionHandler(sessionService service.SessionService, cfg config.SessionConfig) SessionHandler {
+	return &sessionHandler{
+		sessionService: sessionService,
+		cfg:            cfg,
+	}
+}
+
+// Login handles signing in with a session cookie instead of a bearer token
+func (h *sessionHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if !bindRequest(c, &req, "login") {
+		return
+	}
+
+	id, res, err := h.sessionService.Login(c.Request.Context(), &req)
+	if err != nil {
+		if respondIfInvalid(c, err) {
+			return
+		}
+		if respondIfBudgetExhausted(c, err) {
+			return
+		}
+		switch {
+		case err.Error() == "too many login attempts, try again later":
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		case strings.HasPrefix(err.Error(), "failed to create session"):
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session"})
+		default:
+			logger.Warn("Failed to sign in with a session", zap.Error(err), zap.String("email", req.Email))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()}) // Generic "invalid credentials"
+		}
+		return
+	}
+
+	h.setCookie(c, id, res.ExpiresAt)
+	c.JSON(http.StatusOK, res)
+}
+
+// Logout handles ending the current session and clearing its cookie
+func (h *sessionHandler) Logout(c *gin.Context) {
+	if id, err := c.Cookie(h.cfg.CookieName); err == nil && id != "" {
+		if err := h.sessionService.Logout(c.Request.Context(), id); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to end session"})
+			return
+		}
+	}
+
+	h.setCookie(c, "", time.Unix(0, 0))
+	c.Status(http.StatusNoContent)
+}
+
+// setCookie sets the session cookie; a past expiry deletes it. It is HttpOnly so scripts can't read it, and
+// SameSite=Lax so other sites can't send it with their forms (the CSRF token covers the rest).
+func (h *sessionHandler) setCookie(c *gin.Context, id string, expires time.Time) {
+	http.SetCookie(c.Writer, &http.Cookie{
+		Name:     h.cfg.CookieName,
+		Value:    id,
+		Path:     "/",
+		Expires:  expires,
+		Secure:   h.cfg.SecureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
@@ -0,0 +1,244 @@
+package handler
+
+import (
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/cachecontrol"
+	"gotemplate/pkg/logger"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Bounds of the daily statistics period
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 366
+)
+
+// StatsHandler defines the interface for statistics HTTP handlers: the admin aggregates, product views and API usage
+type StatsHandler interface {
+	GetUserProductStats(c *gin.Context)
+	GetDailyProductStats(c *gin.Context)
+	RefreshStats(c *gin.Context)
+	GetProductViews(c *gin.Context)
+	GetAPIUsage(c *gin.Context)
+	GetUserAPIUsage(c *gin.Context)
+	GetAPIUsagePerUser(c *gin.Context)
+}
+
+// statsHandler implements StatsHandler
+type statsHandler struct {
+	statsService         service.StatsService         // Dependency on StatsService
+	productViewService   service.ProductViewService   // Serves product owners their views
+	apiUsageService      service.APIUsageService      // Serves users their API usage
+	processingLogService service.ProcessingLogService // Records admin access to a user's API usage
+	cfg                  config.StatsConfig           // How fresh the statistics are, for caching headers
+}
+
+// NewStatsHandler creates a new StatsHandler instance
+func NewStatsHandler(statsService service.StatsService, productViewService service.ProductViewService, apiUsageService service.APIUsageService, processingLogService service.ProcessingLogService, cfg config.StatsConfig) StatsHandler {
+	return &statsHandler{
+		statsService:         statsService,
+		productViewService:   productViewService,
+		apiUsageService:      apiUsageService,
+		processingLogService: processingLogService,
+		cfg:                  cfg,
+	}
+}
+
+// GetUserProductStats handles listing product counts per user, most products first (paginated).
+// The counts are as of refreshedAt.
+func (h *statsHandler) GetUserProductStats(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.GetUserProductStats(c.Request.Context(), page, pageSize)
+	if err != nil {
+		logger.Error("Failed to get user product stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user product stats"})
+		return
+	}
+	cachecontrol.Set(c.Writer.Header(), h.aggregate(stats.RefreshedAt), cachecontrol.Fresh(0).ForCaller()) // Usernames are read live
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetDailyProductStats handles the daily product summaries between ?from= and ?to=, see parseStatsPeriod.
+// The summaries are as of refreshedAt.
+func (h *statsHandler) GetDailyProductStats(c *gin.Context) {
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.GetDailyProductStats(c.Request.Context(), from, to)
+	if err != nil {
+		logger.Error("Failed to get daily product stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve daily product stats"})
+		return
+	}
+	cachecontrol.Set(c.Writer.Header(), h.aggregate(stats.RefreshedAt))
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetProductViews handles the daily views of one of the authenticated user's products between ?from= and ?to=,
+// see parseStatsPeriod
+func (h *statsHandler) GetProductViews(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	productID, ok := parseIDParam(c, "id", "product")
+	if !ok {
+		return
+	}
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	views, err := h.productViewService.GetProductViews(c.Request.Context(), productID, a.EffectiveUserID, from, to)
+	if err != nil {
+		if err.Error() == "product not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Product not found"})
+			return
+		}
+		logger.Error("Failed to get product views", zap.Error(err), zap.Uint("productID", productID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve product views"})
+		return
+	}
+	cachecontrol.Set(c.Writer.Header(), cachecontrol.Fresh(h.cfg.ViewFlushInterval).ForCaller()) // The counts only move at flushes
+	c.JSON(http.StatusOK, views)
+}
+
+// GetAPIUsage handles the authenticated user's use of the API between ?from= and ?to=, see parseStatsPeriod:
+// requests and errors per day and per credential, and the cost budget they are charged against
+func (h *statsHandler) GetAPIUsage(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.apiUsageService.GetUsage(c.Request.Context(), a.UserID, from, to)
+	if err != nil {
+		logger.Error("Failed to get API usage", zap.Error(err), zap.Uint("userID", a.UserID))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API usage"})
+		return
+	}
+	cachecontrol.Set(c.Writer.Header(), cachecontrol.Fresh(h.cfg.UsageFlushInterval).ForCaller()) // The counts only move at flushes
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetUserAPIUsage handles the admin view of any user's use of the API between ?from= and ?to=, for support staff
+// looking into a customer's errors or throttling. The admin must declare a purpose, and the access is written to
+// the processing log.
+func (h *statsHandler) GetUserAPIUsage(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "user")
+	if !ok {
+		return
+	}
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+	purpose, ok := processingPurpose(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.apiUsageService.GetUsage(c.Request.Context(), userID, from, to)
+	if err != nil {
+		if err.Error() == "user not found" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		logger.Error("Failed to get API usage of user for admin", zap.Error(err), zap.Uint("userID", userID), actor.Field(c.Request.Context()))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API usage"})
+		return
+	}
+	if !recordProcessing(c, h.processingLogService, models.ProcessingAccess, purpose, "api_usage", &userID, "") {
+		return
+	}
+	cachecontrol.Set(c.Writer.Header(), cachecontrol.Fresh(h.cfg.UsageFlushInterval).ForCaller())
+	c.JSON(http.StatusOK, usage)
+}
+
+// GetAPIUsagePerUser handles listing the users of the API between ?from= and ?to=, see parseStatsPeriod, most
+// requests first (paginated)
+func (h *statsHandler) GetAPIUsagePerUser(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c)
+	if !ok {
+		return
+	}
+	from, to, ok := parseStatsPeriod(c)
+	if !ok {
+		return
+	}
+
+	usage, err := h.apiUsageService.GetUserUsage(c.Request.Context(), from, to, page, pageSize)
+	if err != nil {
+		logger.Error("Failed to get API usage per user", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve API usage"})
+		return
+	}
+	cachecontrol.Set(c.Writer.Header(), cachecontrol.Fresh(h.cfg.UsageFlushInterval).ForCaller())
+	c.JSON(http.StatusOK, usage)
+}
+
+// RefreshStats handles recomputing the aggregates now instead of at the next scheduled refresh
+func (h *statsHandler) RefreshStats(c *gin.Context) {
+	result, err := h.statsService.Refresh(c.Request.Context())
+	if err != nil {
+		logger.Error("Failed to refresh stats", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh stats"})
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+// aggregate is the freshness of data read from the aggregates computed at refreshedAt, which stay current until
+// the next scheduled refresh. Without a refresh yet, the data must be revalidated.
+func (h *statsHandler) aggregate(refreshedAt *time.Time) cachecontrol.Part {
+	if refreshedAt == nil {
+		return cachecontrol.Fresh(0).ForCaller()
+	}
+	return cachecontrol.Since(*refreshedAt, h.cfg.RefreshInterval).ForCaller()
+}
+
+// parseStatsPeriod reads the period of daily statistics from ?from= and ?to= (YYYY-MM-DD, UTC, both included).
+// The period defaults to the last 30 days and spans at most 366. On an invalid period a 400 is written and ok is false.
+func parseStatsPeriod(c *gin.Context) (from, to time.Time, ok bool) {
+	to = time.Now().UTC().Truncate(24 * time.Hour)
+	if v := c.Query("to"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date (YYYY-MM-DD)"})
+			return time.Time{}, time.Time{}, false
+		}
+		to = t
+	}
+	from = to.AddDate(0, 0, 1-defaultStatsDays)
+	if v := c.Query("from"); v != "" {
+		t, err := time.Parse(time.DateOnly, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date (YYYY-MM-DD)"})
+			return time.Time{}, time.Time{}, false
+		}
+		from = t
+	}
+	if from.After(to) || to.Sub(from) >= maxStatsDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must be before to, at most 366 days earlier"})
+		return time.Time{}, time.Time{}, false
+	}
+	return from, to, true
+}
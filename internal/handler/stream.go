@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/apiversion"
+	"gotemplate/pkg/redact"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ndjsonContentType is the media type of newline-delimited JSON, one object per line
+const ndjsonContentType = "application/x-ndjson"
+
+// ndjsonFlushRows is the number of rows written between flushes, so a client sees rows arrive without a
+// flush per row
+const ndjsonFlushRows = 100
+
+// wantsNDJSON reports whether the client asked for a list as newline-delimited JSON in its Accept header.
+// JSON stays the default for */* and clients accepting both.
+func wantsNDJSON(c *gin.Context) bool {
+	return c.NegotiateFormat(gin.MIMEJSON, ndjsonContentType) == ndjsonContentType
+}
+
+// ndjsonStream writes a list as newline-delimited JSON row by row, as its rows are read, so memory stays bounded
+// whatever the size of the list. Rows are redacted like writeRedacted does. The status and headers go out with
+// the first row, so an error before it still gets a regular error response; see End.
+type ndjsonStream struct {
+	c       *gin.Context
+	a       actor.Actor
+	enc     *json.Encoder
+	rows    int
+	started bool
+}
+
+// newNDJSONStream creates a stream writing the response of c for the actor
+func newNDJSONStream(c *gin.Context, a actor.Actor) *ndjsonStream {
+	return &ndjsonStream{c: c, a: a, enc: json.NewEncoder(c.Writer)}
+}
+
+// Write writes one row; row must be a pointer to a DTO. An error means the client can't be written to anymore
+// and the listing should stop.
+func (s *ndjsonStream) Write(row interface{}) error {
+	redact.ForRole(row, s.a.Role)
+	fields := apiversion.ForVersion(row, apiversion.FromContext(s.c.Request.Context()))
+	if !s.started {
+		if len(fields) > 0 { // Rows share their type, the first tells for all
+			s.c.Header("Deprecation", "true")
+			for _, field := range fields {
+				apiversion.MarkUsed(s.c.Request.Context(), "field:"+field)
+			}
+		}
+		s.start()
+	}
+
+	if err := s.enc.Encode(row); err != nil {
+		return err
+	}
+	s.rows++
+	if s.rows%ndjsonFlushRows == 0 {
+		s.c.Writer.Flush()
+	}
+	return nil
+}
+
+// End finishes the stream after the listing returned err. Without an error the rest of the rows are flushed.
+// An error before the first row is answered like a buffered listing would be, with message; after it, the status
+// is already sent, so a last line {"error": message} tells the client the list is incomplete. Nothing more is
+// written when the client went away.
+func (s *ndjsonStream) End(err error, message string) {
+	if err == nil {
+		if !s.started {
+			s.start() // An empty list is an empty body
+		}
+		s.c.Writer.Flush()
+		return
+	}
+	if !s.started {
+		if !respondIfBudgetExhausted(s.c, err) {
+			s.c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+		}
+		return
+	}
+	if errors.Is(err, context.Canceled) && s.c.Request.Context().Err() != nil {
+		return // The client disconnected
+	}
+	_ = s.enc.Encode(gin.H{"error": message})
+	s.c.Writer.Flush()
+}
+
+// start sends the status and headers
+func (s *ndjsonStream) start() {
+	s.started = true
+	s.c.Header("Content-Type", ndjsonContentType)
+	s.c.Header("X-Content-Type-Options", "nosniff")
+	s.c.Status(http.StatusOK)
+	s.c.Writer.WriteHeaderNow()
+}
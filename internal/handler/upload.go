@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"fmt"
+	"gotemplate/pkg/logger"
+	"io"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// spoolUpload copies an upload into a temporary file and rewinds it, returning the file and its size.
+// The caller removes the file with removeUpload.
+func spoolUpload(body io.Reader, pattern string) (*os.File, int64, error) {
+	file, err := os.CreateTemp("", pattern)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	size, err := io.Copy(file, body)
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	if err != nil {
+		removeUpload(file)
+		return nil, 0, err
+	}
+	return file, size, nil
+}
+
+// removeUpload closes and deletes a file created by spoolUpload
+func removeUpload(file *os.File) {
+	file.Close()
+	if err := os.Remove(file.Name()); err != nil {
+		logger.Warn("Failed to remove temporary upload file", zap.Error(err), zap.String("file", file.Name()))
+	}
+}
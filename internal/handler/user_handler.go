@@ -1,11 +1,12 @@
 package handler
 
 import (
+	"context"
 	"gotemplate/internal/models"
 	"gotemplate/internal/service"
 	"gotemplate/pkg/logger"
+	"io"
 	"net/http"
-	"strconv" // Import for string to uint conversion
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -15,28 +16,37 @@ import (
 type UserHandler interface {
 	Register(c *gin.Context)
 	Login(c *gin.Context)
+	SetPassword(c *gin.Context)
+	Reauthenticate(c *gin.Context)
 	GetUser(c *gin.Context)
+	DeleteUser(c *gin.Context)
+	AnonymizeUser(c *gin.Context)
+	MergeUser(c *gin.Context)
+	ImportUsers(c *gin.Context)
 }
 
 // userHandler implements UserHandler
 type userHandler struct {
-	userService service.UserService // Dependency on UserService
+	userService          service.UserService          // Dependency on UserService
+	operationService     service.OperationService     // Runs user imports, anonymizations and merges in the background
+	anonymizationService service.AnonymizationService // Scrubs deleted users' personal data
+	accountMergeService  service.AccountMergeService  // Merges duplicate accounts
 }
 
 // NewUserHandler creates a new UserHandler instance
-func NewUserHandler(userService service.UserService) UserHandler {
+func NewUserHandler(userService service.UserService, operationService service.OperationService, anonymizationService service.AnonymizationService, accountMergeService service.AccountMergeService) UserHandler {
 	return &userHandler{
-		userService: userService,
+		userService:          userService,
+		operationService:     operationService,
+		anonymizationService: anonymizationService,
+		accountMergeService:  accountMergeService,
 	}
 }
 
 // Register handles user registration requests
 func (h *userHandler) Register(c *gin.Context) {
 	var req models.RegisterRequest
-	// Bind JSON request body to the struct and validate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Warn("Invalid register request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindRequest(c, &req, "register") {
 		return
 	}
 
@@ -44,10 +54,14 @@ func (h *userHandler) Register(c *gin.Context) {
 	user, err := h.userService.RegisterUser(c.Request.Context(), &req)
 	if err != nil {
 		logger.Error("Failed to register user", zap.Error(err), zap.String("email", req.Email))
+		if respondIfInvalid(c, err) {
+			return
+		}
 		// Handle specific errors for better client feedback
-		if err.Error() == "user with this email already exists" {
+		switch err.Error() {
+		case "user with this email already exists", "user with this username already exists", "user with this email or username already exists":
 			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
-		} else {
+		default:
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register user"})
 		}
 		return
@@ -66,10 +80,7 @@ func (h *userHandler) Register(c *gin.Context) {
 // Login handles user login requests
 func (h *userHandler) Login(c *gin.Context) {
 	var req models.LoginRequest
-	// Bind JSON request body to the struct and validate
-	if err := c.ShouldBindJSON(&req); err != nil {
-		logger.Warn("Invalid login request payload", zap.Error(err))
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindRequest(c, &req, "login") {
 		return
 	}
 
@@ -77,7 +88,17 @@ func (h *userHandler) Login(c *gin.Context) {
 	res, err := h.userService.LoginUser(c.Request.Context(), &req)
 	if err != nil {
 		logger.Error("Failed to login user", zap.Error(err), zap.String("email", req.Email))
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()}) // Return generic "invalid credentials"
+		if respondIfInvalid(c, err) {
+			return
+		}
+		if respondIfBudgetExhausted(c, err) {
+			return
+		}
+		if err.Error() == "too many login attempts, try again later" {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()}) // Return generic "invalid credentials"
+		}
 		return
 	}
 
@@ -86,32 +107,62 @@ func (h *userHandler) Login(c *gin.Context) {
 	c.JSON(http.StatusOK, res)
 }
 
-// GetUser handles retrieving a user's profile
-func (h *userHandler) GetUser(c *gin.Context) {
-	// Retrieve userID from context, set by the AuthMiddleware
-	// Now expecting a string from JWT parsing, convert to uint for service
-	userIDFromContext, exists := c.Get("userID")
-	if !exists {
-		logger.Error("userID not found in context (AuthMiddleware issue)", zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+// SetPassword handles choosing the password of an imported account with its set-password token
+func (h *userHandler) SetPassword(c *gin.Context) {
+	var req models.SetPasswordRequest
+	if !bindRequest(c, &req, "setPassword") {
 		return
 	}
 
-	// AuthMiddleware likely puts string into context, so convert it to uint
-	idStr, ok := userIDFromContext.(string)
+	err := h.userService.SetPassword(c.Request.Context(), &req)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, gin.H{"message": "Password set, you can now log in"})
+	case respondIfInvalid(c, err):
+	case err.Error() == "invalid or used token":
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	default:
+		logger.Error("Failed to set password", zap.Error(err), zap.String("email", req.Email))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to set password"})
+	}
+}
+
+// Reauthenticate handles password confirmation for step-up authentication, returning a fresh token
+func (h *userHandler) Reauthenticate(c *gin.Context) {
+	a, ok := requireActor(c)
 	if !ok {
-		logger.Error("userID in context is not a string, or unexpected type", zap.Any("userID", userIDFromContext), zap.String("path", c.Request.URL.Path))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	idUint, err := strconv.ParseUint(idStr, 10, 64)
+	var req models.ReauthenticateRequest
+	if !bindRequest(c, &req, "reauthenticate") {
+		return
+	}
+
+	res, err := h.userService.Reauthenticate(c.Request.Context(), a.UserID, req.Password)
 	if err != nil {
-		logger.Error("Failed to parse userID from context to uint", zap.Error(err), zap.String("userIDStr", idStr))
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user ID format"})
+		if respondIfBudgetExhausted(c, err) {
+			return
+		}
+		if err.Error() == "too many login attempts, try again later" {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, res)
+}
+
+// GetUser handles retrieving a user's profile
+func (h *userHandler) GetUser(c *gin.Context) {
+	// Retrieve the actor from the request context, set by the AuthMiddleware
+	a, ok := requireActor(c)
+	if !ok {
 		return
 	}
-	userID := uint(idUint) // Convert to uint
+	userID := a.EffectiveUserID
 
 	// Call the service layer to get the user profile
 	user, err := h.userService.GetUserProfile(c.Request.Context(), userID) // Pass uint
@@ -127,7 +178,154 @@ func (h *userHandler) GetUser(c *gin.Context) {
 		"id":        user.ID, // ID is now uint
 		"username":  user.Username,
 		"email":     user.Email,
+		"role":      user.Role,
 		"createdAt": user.CreatedAt,
 		"updatedAt": user.UpdatedAt,
 	})
 }
+
+// DeleteUser handles an admin deleting a user account
+func (h *userHandler) DeleteUser(c *gin.Context) {
+	userID, ok := parseIDParam(c, "id", "user")
+	if !ok {
+		return
+	}
+
+	if err := h.userService.DeleteUser(c.Request.Context(), userID); err != nil {
+		logger.Error("Failed to delete user", zap.Error(err), zap.Uint("userID", userID))
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "user still owns products", "cannot delete the user that receives reassigned products":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
+		}
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AnonymizeUser handles the admin request to anonymize a deleted user (right to be forgotten).
+// The user is checked up front; the scrubbing runs as an operation whose result is the completion report.
+func (h *userHandler) AnonymizeUser(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "id", "user")
+	if !ok {
+		return
+	}
+
+	if err := h.anonymizationService.CheckAnonymizable(c.Request.Context(), userID); err != nil {
+		switch err.Error() {
+		case "user not found":
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case "user must be deleted before being anonymized", "user is already anonymized":
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to anonymize user"})
+		}
+		return
+	}
+
+	op, err := h.operationService.Start(c.Request.Context(), a.UserID, "user_anonymize", func(ctx context.Context, report service.ProgressFunc) (interface{}, error) {
+		return h.anonymizationService.AnonymizeUser(ctx, userID, report)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start user anonymization"})
+		return
+	}
+
+	respondAccepted(c, op)
+}
+
+// MergeUser handles the admin request to merge a user into another, e.g. after duplicate registrations.
+// A dry run answers with the report of what would move; otherwise the merge runs as an operation whose result is
+// the report.
+func (h *userHandler) MergeUser(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+	userID, ok := parseIDParam(c, "id", "user")
+	if !ok {
+		return
+	}
+	var req models.AccountMergeRequest
+	if !bindRequest(c, &req, "MergeUser") {
+		return
+	}
+
+	if err := h.accountMergeService.CheckMergeable(c.Request.Context(), userID, req.IntoUserID); err != nil {
+		writeMergeError(c, err)
+		return
+	}
+	if req.DryRun {
+		result, err := h.accountMergeService.MergeUsers(c.Request.Context(), userID, req.IntoUserID, true, nil)
+		if err != nil {
+			logger.Error("User merge dry run failed", zap.Error(err), zap.Uint("userID", userID))
+			writeMergeError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, result)
+		return
+	}
+
+	op, err := h.operationService.Start(c.Request.Context(), a.UserID, "user_merge", func(ctx context.Context, report service.ProgressFunc) (interface{}, error) {
+		return h.accountMergeService.MergeUsers(ctx, userID, req.IntoUserID, false, report)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start user merge"})
+		return
+	}
+
+	respondAccepted(c, op)
+}
+
+// writeMergeError maps account merge service errors to HTTP responses
+func writeMergeError(c *gin.Context, err error) {
+	switch err.Error() {
+	case "user not found", "target user not found":
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case "cannot merge a user into itself", "cannot merge an admin account":
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		if !respondIfBudgetExhausted(c, err) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to merge users"})
+		}
+	}
+}
+
+// ImportUsers handles the admin bulk user import from a CSV file (email, username and optional role columns).
+// The file structure is checked up front; the accounts are created by an operation with a per-row report.
+func (h *userHandler) ImportUsers(c *gin.Context) {
+	a, ok := requireActor(c)
+	if !ok {
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Import file must be at most 10 MiB"})
+		return
+	}
+	records, err := service.ParseUserImportCSV(data)
+	if err != nil {
+		logger.Warn("Rejected user import", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	op, err := h.operationService.Start(c.Request.Context(), a.UserID, "user_import", func(ctx context.Context, report service.ProgressFunc) (interface{}, error) {
+		return h.userService.ImportUsers(ctx, records, report)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start user import"})
+		return
+	}
+
+	respondAccepted(c, op)
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AccountMergeRequest is the payload for merging a user account into another
+type AccountMergeRequest struct {
+	IntoUserID uint `json:"intoUserId" form:"intoUserId" binding:"required"` // The account that remains
+	DryRun     bool `json:"dryRun" form:"dryRun"`                            // Report what would move without moving it
+}
+
+// AccountMergeReport is the report of merging a user into another. Counts are rows changed per kind; a dry run
+// reports what a merge would change at that moment.
+type AccountMergeReport struct {
+	SourceUserID       uint       `json:"sourceUserId"` // Deleted by the merge
+	TargetUserID       uint       `json:"targetUserId"`
+	DryRun             bool       `json:"dryRun"`
+	Products           int64      `json:"products"`           // Moved, deleted ones included
+	ClientIDsCleared   int64      `json:"clientIdsCleared"`   // Products whose client ID the target already used, see Product.ClientID
+	Bundles            int64      `json:"bundles"`            // Moved
+	Comments           int64      `json:"comments"`           // Moved, authored by the target from now on
+	Reports            int64      `json:"reports"`            // Moved, filed by the target from now on
+	Addresses          int64      `json:"addresses"`          // Moved; they lose their default flag if the target has a default
+	SavedSearches      int64      `json:"savedSearches"`      // Moved
+	PermissionsMoved   int64      `json:"permissionsMoved"`   // Products shared with the source, now shared with the target
+	PermissionsDropped int64      `json:"permissionsDropped"` // Grants made pointless by the merge: to the owner, or duplicates
+	Tokens             int64      `json:"tokens"`             // The source's personal access tokens, revoked rather than moved
+	MergedAt           *time.Time `json:"mergedAt,omitempty"`
+}
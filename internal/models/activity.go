@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// ActivityEntry is a denormalized, user-facing line in a user's activity feed,
+// projected from audit events so the feed can be read without joining the audit log
+type ActivityEntry struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index:idx_activity_user_created"` // User whose feed the entry belongs to
+	Verb         string    `gorm:"not null"`                                 // Machine-readable action, e.g. "product.price_changed"
+	ResourceType string    `gorm:"not null"`
+	ResourceID   uint      `gorm:"not null"`
+	Summary      string    `gorm:"not null"` // Human-readable description
+	CreatedAt    time.Time `gorm:"not null;index:idx_activity_user_created"`
+}
+
+// ActivityEntryResponse is the API representation of an activity feed entry
+type ActivityEntryResponse struct {
+	ID           uint      `json:"id"`
+	Verb         string    `json:"verb"`
+	ResourceType string    `json:"resourceType"`
+	ResourceID   uint      `json:"resourceId"`
+	Summary      string    `json:"summary"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// ActivityListResponse is a page of activity feed entries
+type ActivityListResponse struct {
+	Items    []*ActivityEntryResponse `json:"items"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"pageSize"`
+	Total    int64                    `json:"total"`
+}
+
+// NewActivityEntryResponses converts a list of ActivityEntry models into their API representation
+func NewActivityEntryResponses(entries []*ActivityEntry) []*ActivityEntryResponse {
+	res := make([]*ActivityEntryResponse, 0, len(entries))
+	for _, e := range entries {
+		res = append(res, &ActivityEntryResponse{
+			ID:           e.ID,
+			Verb:         e.Verb,
+			ResourceType: e.ResourceType,
+			ResourceID:   e.ResourceID,
+			Summary:      e.Summary,
+			CreatedAt:    e.CreatedAt,
+		})
+	}
+	return res
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Address is a postal address saved by a user, e.g. for shipping
+type Address struct {
+	gorm.Model        // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	UserID     uint   `gorm:"not null;index"`
+	Label      string // Free-form name, e.g. "Home"
+	Recipient  string `gorm:"not null"`
+	Line1      string `gorm:"not null"`
+	Line2      string
+	City       string `gorm:"not null"`
+	Region     string // State, province or county
+	PostalCode string
+	Country    string `gorm:"not null;size:2"` // ISO 3166-1 alpha-2 code
+	Phone      string
+	IsDefault  bool `gorm:"not null;default:false"` // At most one default address per user
+}
+
+// AddressRequest is the payload for creating or replacing an address
+type AddressRequest struct {
+	Label      string `json:"label" form:"label" binding:"max=64"`
+	Recipient  string `json:"recipient" form:"recipient" binding:"required,max=128"`
+	Line1      string `json:"line1" form:"line1" binding:"required,max=256"`
+	Line2      string `json:"line2" form:"line2" binding:"max=256"`
+	City       string `json:"city" form:"city" binding:"required,max=128"`
+	Region     string `json:"region" form:"region" binding:"max=128"`
+	PostalCode string `json:"postalCode" form:"postalCode" binding:"max=32"`
+	Country    string `json:"country" form:"country" binding:"required,len=2"`
+	Phone      string `json:"phone" form:"phone" binding:"max=32"`
+	IsDefault  bool   `json:"isDefault" form:"isDefault"`
+}
+
+// AddressResponse is the API representation of an address
+type AddressResponse struct {
+	ID         uint      `json:"id"`
+	Label      string    `json:"label,omitempty"`
+	Recipient  string    `json:"recipient"`
+	Line1      string    `json:"line1"`
+	Line2      string    `json:"line2,omitempty"`
+	City       string    `json:"city"`
+	Region     string    `json:"region,omitempty"`
+	PostalCode string    `json:"postalCode,omitempty"`
+	Country    string    `json:"country"`
+	Phone      string    `json:"phone,omitempty"`
+	IsDefault  bool      `json:"isDefault"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// NewAddressResponse converts an Address model into its API representation
+func NewAddressResponse(a *Address) *AddressResponse {
+	return &AddressResponse{
+		ID:         a.ID,
+		Label:      a.Label,
+		Recipient:  a.Recipient,
+		Line1:      a.Line1,
+		Line2:      a.Line2,
+		City:       a.City,
+		Region:     a.Region,
+		PostalCode: a.PostalCode,
+		Country:    a.Country,
+		Phone:      a.Phone,
+		IsDefault:  a.IsDefault,
+		UpdatedAt:  a.UpdatedAt,
+	}
+}
+
+// NewAddressResponses converts a list of Address models into their API representation
+func NewAddressResponses(addresses []*Address) []*AddressResponse {
+	res := make([]*AddressResponse, 0, len(addresses))
+	for _, a := range addresses {
+		res = append(res, NewAddressResponse(a))
+	}
+	return res
+}
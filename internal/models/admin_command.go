@@ -0,0 +1,24 @@
+package models
+
+import "encoding/json"
+
+// AdminCommandParam describes a parameter of an admin command, as read from its params struct
+type AdminCommandParam struct {
+	Name     string `json:"name"`            // JSON field name
+	Type     string `json:"type"`            // JSON type, or "time" for RFC 3339 timestamps
+	Required bool   `json:"required"`        // The command is refused without it
+	Rules    string `json:"rules,omitempty"` // Validation rules, as in binding tags, e.g. "required,max=100"
+}
+
+// AdminCommandInfo is the API representation of an operational command admins can enqueue
+type AdminCommandInfo struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Params      []*AdminCommandParam `json:"params"`
+}
+
+// EnqueueCommandRequest is the body of a request to run an admin command. Params must match the command's
+// parameters exactly: unknown fields are refused.
+type EnqueueCommandRequest struct {
+	Params json.RawMessage `json:"params"`
+}
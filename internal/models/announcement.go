@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Announcement is a message broadcast by admins to all users or a targeted audience
+type Announcement struct {
+	gorm.Model             // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	Title       string     `gorm:"not null"`
+	Body        string     `gorm:"not null"`
+	Audience    string     `gorm:"not null;default:''"` // Target role, empty for everyone
+	PublishedBy uint       `gorm:"not null"`            // Admin who published the announcement
+	ExpiresAt   *time.Time // Announcement is hidden after this time, nil means never
+}
+
+// CreateAnnouncementRequest is the payload for publishing an announcement
+type CreateAnnouncementRequest struct {
+	Title     string     `json:"title" form:"title" binding:"required"`
+	Body      string     `json:"body" form:"body" binding:"required"`
+	Audience  string     `json:"audience" form:"audience" binding:"omitempty,oneof=user admin"` // Empty targets every user
+	ExpiresAt *time.Time `json:"expiresAt" form:"expiresAt"`
+}
+
+// AnnouncementResponse is the API representation of an announcement
+type AnnouncementResponse struct {
+	ID        uint       `json:"id"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	Audience  string     `json:"audience,omitempty"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// NewAnnouncementResponse converts an Announcement model into its API representation
+func NewAnnouncementResponse(a *Announcement) *AnnouncementResponse {
+	return &AnnouncementResponse{
+		ID:        a.ID,
+		Title:     a.Title,
+		Body:      a.Body,
+		Audience:  a.Audience,
+		ExpiresAt: a.ExpiresAt,
+		CreatedAt: a.CreatedAt,
+	}
+}
+
+// NewAnnouncementResponses converts a list of Announcement models into their API representation
+func NewAnnouncementResponses(announcements []*Announcement) []*AnnouncementResponse {
+	res := make([]*AnnouncementResponse, 0, len(announcements))
+	for _, a := range announcements {
+		res = append(res, NewAnnouncementResponse(a))
+	}
+	return res
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AnonymizedUsername is the username a user is left with after anonymization
+func AnonymizedUsername(userID uint) string {
+	return fmt.Sprintf("deleted-user-%d", userID)
+}
+
+// AnonymizedEmail is the email a user is left with after anonymization. The .invalid domain can never receive mail.
+func AnonymizedEmail(userID uint) string {
+	return fmt.Sprintf("deleted-%d@anonymized.invalid", userID)
+}
+
+// AnonymizedText replaces free text written by an anonymized user
+const AnonymizedText = "[removed]"
+
+// AnonymizationReport is the completion report of anonymizing a deleted user. Counts are rows changed per kind.
+// Rows are scrubbed in place rather than deleted wherever other records or aggregates depend on them.
+type AnonymizationReport struct {
+	UserID          uint      `json:"userId"`
+	AuditEvents     int64     `json:"auditEvents"`     // Events whose metadata lost the username or email
+	Comments        int64     `json:"comments"`        // Comments whose body was replaced; threads and counts stay intact
+	Reports         int64     `json:"reports"`         // Reports filed by the user whose details were cleared
+	Addresses       int64     `json:"addresses"`       // Deleted outright
+	SavedSearches   int64     `json:"savedSearches"`   // Deleted outright
+	ActivityEntries int64     `json:"activityEntries"` // The user's own feed, deleted outright
+	Tokens          int64     `json:"tokens"`          // Personal access tokens whose names were cleared
+	LoginAttempts   int64     `json:"loginAttempts"`   // Throttling state keyed by the email, deleted outright
+	AnonymizedAt    time.Time `json:"anonymizedAt"`
+}
+
+// AuditPIIKeys are the audit metadata keys holding personal data, removed when a user is anonymized or when
+// audit events leave production
+var AuditPIIKeys = []string{"username", "email"}
+
+// AuditMetadataWithoutPII removes the AuditPIIKeys from JSON-encoded audit metadata. Metadata that isn't an
+// object is replaced with an empty one, since nothing tells what it holds.
+func AuditMetadataWithoutPII(metadata string) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(metadata), &fields); err != nil || fields == nil {
+		return "{}"
+	}
+	for _, key := range AuditPIIKeys {
+		delete(fields, key)
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return "{}"
+	}
+	return string(encoded)
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AuditSettingChanged is the action of audit events recording a change to a setting at runtime, whatever the
+// setting. Their resource type is "setting" and their metadata holds the setting's configuration key, its
+// previous and new values ("from", "to") and the reason given, so every change can be queried together.
+const AuditSettingChanged = "setting.changed"
+
+// AuditEvent is an append-only record of a security- or compliance-relevant action
+type AuditEvent struct {
+	ID              uint      `gorm:"primaryKey" json:"id"`
+	Action          string    `gorm:"not null;index" json:"action"`                     // Dotted action name, e.g. "report.resolved"
+	ResourceType    string    `gorm:"not null" json:"resourceType"`                     // Kind of resource acted on, e.g. "product"
+	ResourceID      uint      `gorm:"not null" json:"resourceId"`                       // ID of the resource acted on
+	ActingUserID    uint      `json:"actingUserId"`                                     // User who performed the action, 0 for system jobs
+	EffectiveUserID uint      `json:"effectiveUserId"`                                  // User the action was performed as (differs under impersonation)
+	Metadata        string    `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"` // JSON-encoded action details
+	CreatedAt       time.Time `gorm:"not null;index" json:"createdAt"`
+}
+
+// AuditForwardCursor remembers the last audit event delivered to an external sink,
+// so forwarding resumes where it stopped after a restart (at-least-once delivery)
+type AuditForwardCursor struct {
+	Name        string    `gorm:"primaryKey"` // Sink name, one cursor per configured sink
+	LastEventID uint      `gorm:"not null"`
+	UpdatedAt   time.Time `gorm:"not null"`
+}
+
+// AuditEventQuery filters the audit log. Empty fields are ignored.
+type AuditEventQuery struct {
+	Action       string     `form:"action" binding:"max=100"`
+	ResourceType string     `form:"resourceType" binding:"max=100"`
+	ResourceID   *uint      `form:"resourceId"`
+	ActingUserID *uint      `form:"actingUserId"`
+	Setting      string     `form:"setting" binding:"max=100"`                    // Configuration key of setting.changed events
+	From         *time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"` // Inclusive
+	To           *time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`   // Exclusive
+}
+
+// AuditEventResponse is the API representation of an audit event
+type AuditEventResponse struct {
+	ID              uint            `json:"id"`
+	Action          string          `json:"action"`
+	ResourceType    string          `json:"resourceType"`
+	ResourceID      uint            `json:"resourceId"`
+	ActingUserID    uint            `json:"actingUserId"`
+	EffectiveUserID uint            `json:"effectiveUserId"`
+	Metadata        json.RawMessage `json:"metadata"`
+	CreatedAt       time.Time       `json:"createdAt"`
+}
+
+// AuditLogResponse is a page of audit events
+type AuditLogResponse struct {
+	Items    []*AuditEventResponse `json:"items"`
+	Page     int                   `json:"page"`
+	PageSize int                   `json:"pageSize"`
+	Total    int64                 `json:"total"`
+}
+
+// NewAuditEventResponses converts a list of AuditEvent models into their API representation
+func NewAuditEventResponses(events []*AuditEvent) []*AuditEventResponse {
+	res := make([]*AuditEventResponse, 0, len(events))
+	for _, e := range events {
+		res = append(res, &AuditEventResponse{
+			ID:              e.ID,
+			Action:          e.Action,
+			ResourceType:    e.ResourceType,
+			ResourceID:      e.ResourceID,
+			ActingUserID:    e.ActingUserID,
+			EffectiveUserID: e.EffectiveUserID,
+			Metadata:        json.RawMessage(e.Metadata),
+			CreatedAt:       e.CreatedAt,
+		})
+	}
+	return res
+}
+
+// AuditRedeliveryResult reports an audit forwarder cursor moved back, so events are forwarded again
+type AuditRedeliveryResult struct {
+	Sink            string `json:"sink"`
+	PreviousEventID uint   `json:"previousEventId"` // Last event forwarded before the rewind
+	LastEventID     uint   `json:"lastEventId"`     // Forwarding resumes after this event
+	Pending         int64  `json:"pending"`         // Events waiting to be forwarded after the rewind
+}
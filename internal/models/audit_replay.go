@@ -0,0 +1,38 @@
+package models
+
+// Outcomes of replaying an audit event
+const (
+	AuditReplayApplied = "applied" // The action was performed again through the service layer
+	AuditReplaySkipped = "skipped" // The action can't be replayed, e.g. its event doesn't record enough to redo it
+	AuditReplayFailed  = "failed"  // Replaying the action returned an error
+)
+
+// AuditReplayResult is the outcome of replaying one audit event
+type AuditReplayResult struct {
+	EventID    uint   `json:"eventId"`
+	Action     string `json:"action"`
+	Outcome    string `json:"outcome"`              // One of the AuditReplay* outcomes
+	ResourceID uint   `json:"resourceId,omitempty"` // ID of the resource in the replay environment, when applied
+	Detail     string `json:"detail,omitempty"`     // Why the event was skipped or failed
+}
+
+// AuditReplayReport sums up the replay of a window of audit events
+type AuditReplayReport struct {
+	Applied int                  `json:"applied"`
+	Skipped int                  `json:"skipped"`
+	Failed  int                  `json:"failed"`
+	Results []*AuditReplayResult `json:"results"`
+}
+
+// Add counts the result of replaying an event
+func (r *AuditReplayReport) Add(result *AuditReplayResult) {
+	switch result.Outcome {
+	case AuditReplayApplied:
+		r.Applied++
+	case AuditReplaySkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
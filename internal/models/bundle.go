@@ -0,0 +1,109 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Bundle is a set of products sold together at its own price
+type Bundle struct {
+	gorm.Model         // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	UserID      uint   `gorm:"not null;index"` // The seller; every component must belong to them
+	Name        string `gorm:"not null"`
+	Description string
+	Price       float64            `gorm:"not null;check:price > 0"`
+	Items       []*BundleItem      `gorm:"foreignKey:BundleID"`
+	Components  []*BundleComponent `gorm:"-"` // Items joined with their products, filled in on reads
+}
+
+// BundleItem is one component of a bundle
+type BundleItem struct {
+	BundleID  uint `gorm:"primaryKey"`
+	ProductID uint `gorm:"primaryKey;index"`
+	Quantity  int  `gorm:"not null;check:quantity > 0"`
+}
+
+// BundleComponent is a bundle item joined with its product. Product fields are zero when the product no longer exists.
+type BundleComponent struct {
+	ProductID    uint
+	Quantity     int
+	ProductName  string
+	ProductPrice float64
+	Available    bool
+}
+
+// BundleItemRequest is one component in a CreateBundleRequest
+type BundleItemRequest struct {
+	ProductID uint `json:"productId" form:"productId" binding:"required"`
+	Quantity  int  `json:"quantity" form:"quantity" binding:"required,min=1,max=100"`
+}
+
+// CreateBundleRequest is the payload for creating a bundle
+type CreateBundleRequest struct {
+	Name        string               `json:"name" form:"name" binding:"required"`
+	Description string               `json:"description" form:"description"`
+	Price       float64              `json:"price" form:"price" binding:"required,gt=0"`
+	Items       []*BundleItemRequest `json:"items" form:"items" binding:"required,min=2,max=50,dive"`
+}
+
+// BundleComponentResponse is the API representation of a bundle component
+type BundleComponentResponse struct {
+	ProductID uint    `json:"productId"`
+	Name      string  `json:"name,omitempty"`
+	Price     float64 `json:"price,omitempty"`
+	Quantity  int     `json:"quantity"`
+	Available bool    `json:"available"`
+}
+
+// BundleResponse is the API representation of a bundle
+type BundleResponse struct {
+	ID              uint                       `json:"id"`
+	Name            string                     `json:"name"`
+	Description     string                     `json:"description"`
+	Price           float64                    `json:"price"`
+	ComponentsPrice float64                    `json:"componentsPrice"` // What the available components cost when bought separately
+	Available       bool                       `json:"available"`       // A bundle is only available while all of its components are
+	UserID          uint                       `json:"userId"`
+	Components      []*BundleComponentResponse `json:"components"`
+	CreatedAt       time.Time                  `json:"createdAt"`
+}
+
+// NewBundleResponse converts a Bundle, with its components loaded, into the API representation
+func NewBundleResponse(bundle *Bundle) *BundleResponse {
+	components := bundle.Components
+	res := &BundleResponse{
+		ID:          bundle.ID,
+		Name:        bundle.Name,
+		Description: bundle.Description,
+		Price:       bundle.Price,
+		Available:   len(components) > 0,
+		UserID:      bundle.UserID,
+		Components:  make([]*BundleComponentResponse, 0, len(components)),
+		CreatedAt:   bundle.CreatedAt,
+	}
+	for _, c := range components {
+		res.Components = append(res.Components, &BundleComponentResponse{
+			ProductID: c.ProductID,
+			Name:      c.ProductName,
+			Price:     c.ProductPrice,
+			Quantity:  c.Quantity,
+			Available: c.Available,
+		})
+		if c.Available {
+			res.ComponentsPrice += c.ProductPrice * float64(c.Quantity)
+		} else {
+			res.Available = false
+		}
+	}
+	return res
+}
+
+// NewBundleResponses converts a list of Bundle models into their API representation
+func NewBundleResponses(bundles []*Bundle) []*BundleResponse {
+	res := make([]*BundleResponse, 0, len(bundles))
+	for _, b := range bundles {
+		res = append(res, NewBundleResponse(b))
+	}
+	return res
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Comment is a question or remark left on a product. Replies reference their root comment,
+// so threads are at most two levels deep (question and answers).
+type Comment struct {
+	gorm.Model        // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	ProductID  uint   `gorm:"not null;index"` // Product the comment belongs to
+	UserID     uint   `gorm:"not null"`       // Author of the comment
+	ParentID   *uint  `gorm:"index"`          // Root comment this is a reply to, nil for top-level comments
+	Body       string `gorm:"not null"`
+	Hidden     bool   `gorm:"not null;default:false"` // Hidden by the product owner, only visible to the owner and author
+}
+
+// CreateCommentRequest is the payload for commenting on a product
+type CreateCommentRequest struct {
+	Body     string `json:"body" form:"body" binding:"required,max=2000"`
+	ParentID *uint  `json:"parentId" form:"parentId"` // Set to reply to an existing comment
+}
+
+// CommentResponse is the API representation of a comment
+type CommentResponse struct {
+	ID        uint      `json:"id"`
+	ProductID uint      `json:"productId"`
+	UserID    uint      `json:"userId"`
+	ParentID  *uint     `json:"parentId,omitempty"`
+	Body      string    `json:"body"`
+	Hidden    bool      `json:"hidden,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CommentListResponse is a page of comments
+type CommentListResponse struct {
+	Items    []*CommentResponse `json:"items"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"pageSize"`
+	Total    int64              `json:"total"`
+}
+
+// NewCommentResponse converts a Comment model into its API representation
+func NewCommentResponse(comment *Comment) *CommentResponse {
+	return &CommentResponse{
+		ID:        comment.ID,
+		ProductID: comment.ProductID,
+		UserID:    comment.UserID,
+		ParentID:  comment.ParentID,
+		Body:      comment.Body,
+		Hidden:    comment.Hidden,
+		CreatedAt: comment.CreatedAt,
+	}
+}
+
+// NewCommentResponses converts a list of Comment models into their API representation
+func NewCommentResponses(comments []*Comment) []*CommentResponse {
+	res := make([]*CommentResponse, 0, len(comments))
+	for _, comment := range comments {
+		res = append(res, NewCommentResponse(comment))
+	}
+	return res
+}
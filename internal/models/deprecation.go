@@ -0,0 +1,49 @@
+package models
+
+import "time"
+
+// DeprecationUsage counts one client's daily use of a deprecated endpoint or response field
+type DeprecationUsage struct {
+	ID         uint      `gorm:"primaryKey"`
+	Feature    string    `gorm:"not null;uniqueIndex:idx_deprecation_usages_key,priority:1"` // e.g. "field:ProductResponse.price"
+	UserID     uint      `gorm:"not null;uniqueIndex:idx_deprecation_usages_key,priority:2"` // Acting user; 0 if unauthenticated
+	Via        string    `gorm:"not null;uniqueIndex:idx_deprecation_usages_key,priority:3"` // jwt, personal_token, ...
+	UserAgent  string    `gorm:"not null;uniqueIndex:idx_deprecation_usages_key,priority:4"`
+	Day        time.Time `gorm:"type:date;not null;uniqueIndex:idx_deprecation_usages_key,priority:5;index"`
+	Count      int64     `gorm:"not null"`
+	LastSeenAt time.Time `gorm:"not null"`
+}
+
+// DeprecationUsageTotal is the use of a deprecated feature by one kind of client, summed over days
+type DeprecationUsageTotal struct {
+	Feature    string
+	Via        string
+	UserAgent  string
+	Users      int64
+	Count      int64
+	LastSeenAt time.Time
+}
+
+// DeprecationClientUsage is the use of a deprecated feature by one kind of client over a report's period
+type DeprecationClientUsage struct {
+	Via        string    `json:"via"`
+	UserAgent  string    `json:"userAgent"`
+	Users      int64     `json:"users"` // Distinct users, unauthenticated requests counting as one
+	Count      int64     `json:"count"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+}
+
+// DeprecatedFeatureUsage is the use of one deprecated endpoint or field over a report's period
+type DeprecatedFeatureUsage struct {
+	Feature    string                    `json:"feature"`
+	Count      int64                     `json:"count"`
+	LastSeenAt time.Time                 `json:"lastSeenAt"`
+	Clients    []*DeprecationClientUsage `json:"clients"` // Most used first
+}
+
+// DeprecationReport tells whether deprecated behavior is still used, and by whom.
+// A feature absent from the report wasn't used since Since.
+type DeprecationReport struct {
+	Since    time.Time                 `json:"since"`
+	Features []*DeprecatedFeatureUsage `json:"features"` // Most used first
+}
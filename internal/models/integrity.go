@@ -0,0 +1,29 @@
+package models
+
+// IntegrityIssue is a single data integrity problem found by the doctor
+type IntegrityIssue struct {
+	Check        string `json:"check"` // Name of the check that found the issue
+	ResourceType string `json:"resourceType"`
+	ResourceID   uint   `json:"resourceId"`
+	Detail       string `json:"detail"`
+	Repaired     bool   `json:"repaired"`
+}
+
+// IntegrityReport is the outcome of an integrity scan
+type IntegrityReport struct {
+	Issues   []*IntegrityIssue `json:"issues"`
+	Repaired int               `json:"repaired"`
+}
+
+// Add appends an issue to the report
+func (r *IntegrityReport) Add(issue *IntegrityIssue) {
+	r.Issues = append(r.Issues, issue)
+	if issue.Repaired {
+		r.Repaired++
+	}
+}
+
+// Unrepaired counts the issues that are still present after the scan
+func (r *IntegrityReport) Unrepaired() int {
+	return len(r.Issues) - r.Repaired
+}
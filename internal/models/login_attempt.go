@@ -0,0 +1,10 @@
+package models
+
+import "time"
+
+// LoginAttempt tracks recent failed logins for one credential (normalized email)
+type LoginAttempt struct {
+	Key          string    `gorm:"primaryKey"`
+	Failures     int       `gorm:"not null"`
+	LastFailedAt time.Time `gorm:"not null"`
+}
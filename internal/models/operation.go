@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Operation statuses
+const (
+	OperationStatusPending   = "pending"   // Accepted but not started yet
+	OperationStatusRunning   = "running"   // Currently being processed
+	OperationStatusSucceeded = "succeeded" // Finished successfully, Result is populated
+	OperationStatusFailed    = "failed"    // Finished with an error, Error is populated
+)
+
+// Operation represents a long-running asynchronous job that clients poll for completion
+type Operation struct {
+	gorm.Model        // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	Kind       string `gorm:"not null"`                 // Kind of work, e.g. "product_import"
+	Status     string `gorm:"not null;default:pending"` // One of the OperationStatus* constants
+	Progress   int    `gorm:"not null;default:0"`       // Completion percentage (0-100)
+	Result     string // JSON-encoded result payload on success
+	Error      string // Error message on failure
+	UserID     uint   `gorm:"not null"` // User who started the operation
+}
+
+// Done reports whether the operation has reached a terminal status
+func (o *Operation) Done() bool {
+	return o.Status == OperationStatusSucceeded || o.Status == OperationStatusFailed
+}
+
+// OperationResponse is the API representation of an operation
+type OperationResponse struct {
+	ID        uint            `json:"id"`
+	Kind      string          `json:"kind"`
+	Status    string          `json:"status"`
+	Progress  int             `json:"progress"`
+	Result    json.RawMessage `json:"result,omitempty"`
+	Error     string          `json:"error,omitempty"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
+
+// NewOperationResponse converts an Operation model into its API representation
+func NewOperationResponse(op *Operation) *OperationResponse {
+	res := &OperationResponse{
+		ID:        op.ID,
+		Kind:      op.Kind,
+		Status:    op.Status,
+		Progress:  op.Progress,
+		Error:     op.Error,
+		CreatedAt: op.CreatedAt,
+		UpdatedAt: op.UpdatedAt,
+	}
+	if op.Result != "" {
+		res.Result = json.RawMessage(op.Result)
+	}
+	return res
+}
@@ -0,0 +1,19 @@
+package models
+
+// Password hashing timing verdicts
+const (
+	PasswordHashOK      = "ok"
+	PasswordHashTooFast = "too_fast" // The cost is too low to slow down offline guessing
+	PasswordHashTooSlow = "too_slow" // Logins and registrations spend too long hashing
+)
+
+// PasswordHashReport is the measured cost of hashing one password on this instance, for tuning
+// auth.passwordHash.cost to the hardware
+type PasswordHashReport struct {
+	Cost          int     `json:"cost"`
+	DurationMs    float64 `json:"durationMs"`
+	MinDurationMs float64 `json:"minDurationMs"`
+	MaxDurationMs float64 `json:"maxDurationMs"`
+	Verdict       string  `json:"verdict"`       // One of the PasswordHash* verdicts
+	SuggestedCost int     `json:"suggestedCost"` // Cost expected to land within the target range
+}
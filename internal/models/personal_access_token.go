@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PersonalAccessToken is a long-lived, scoped bearer token a user creates for scripts and integrations.
+// Only a hash of the token is stored.
+type PersonalAccessToken struct {
+	gorm.Model            // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	UserID     uint       `gorm:"not null;index"`
+	Name       string     `gorm:"not null"`
+	Prefix     string     `gorm:"not null"`             // First characters of the token, to help users recognize it
+	TokenHash  string     `gorm:"not null;uniqueIndex"` // SHA-256 of the token
+	Scopes     string     `gorm:"not null"`             // Space-separated auth.Scope* values
+	ExpiresAt  time.Time  `gorm:"not null"`
+	LastUsedAt *time.Time // Updated at most once a minute
+	RevokedAt  *time.Time
+	UserRole   string `gorm:"->;-:migration"` // Role of the owning user, only filled when authenticating
+}
+
+// ScopeList returns the token's scopes as a slice
+func (t *PersonalAccessToken) ScopeList() []string {
+	return strings.Fields(t.Scopes)
+}
+
+// CreatePersonalAccessTokenRequest is the payload for creating a personal access token
+type CreatePersonalAccessTokenRequest struct {
+	Name      string    `json:"name" form:"name" binding:"required,max=100"`
+	Scopes    []string  `json:"scopes" form:"scopes" binding:"required,min=1,dive,oneof=read write admin"`
+	ExpiresAt time.Time `json:"expiresAt" form:"expiresAt" binding:"required"`
+}
+
+// PersonalAccessTokenResponse is the API representation of a personal access token
+type PersonalAccessTokenResponse struct {
+	ID         uint       `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	Expired    bool       `json:"expired"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Token      string     `json:"token,omitempty"` // Only returned once, when the token is created
+}
+
+// NewPersonalAccessTokenResponse converts a PersonalAccessToken model into its API representation
+func NewPersonalAccessTokenResponse(t *PersonalAccessToken) *PersonalAccessTokenResponse {
+	return &PersonalAccessTokenResponse{
+		ID:         t.ID,
+		Name:       t.Name,
+		Prefix:     t.Prefix,
+		Scopes:     t.ScopeList(),
+		ExpiresAt:  t.ExpiresAt,
+		Expired:    time.Now().After(t.ExpiresAt),
+		LastUsedAt: t.LastUsedAt,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// NewPersonalAccessTokenResponses converts a list of PersonalAccessToken models into their API representation
+func NewPersonalAccessTokenResponses(tokens []*PersonalAccessToken) []*PersonalAccessTokenResponse {
+	res := make([]*PersonalAccessTokenResponse, 0, len(tokens))
+	for _, t := range tokens {
+		res = append(res, NewPersonalAccessTokenResponse(t))
+	}
+	return res
+}
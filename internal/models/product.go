@@ -1,6 +1,10 @@
 package models
 
 import (
+	"errors"
+	"math"
+	"time"
+
 	"gorm.io/gorm"
 )
 
@@ -8,25 +12,185 @@ import (
 type Product struct {
 	gorm.Model // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
 	// ID        uint is provided by gorm.Model
-	Name        string  `gorm:"not null"` // Name cannot be null
+	Name        string `gorm:"not null"` // Name cannot be null
 	Description string
 	Price       float64 `gorm:"not null;check:price > 0"` // Price cannot be null and must be greater than 0
 	UserID      uint    `gorm:"not null"`                 // Foreign key for User, GORM automatically infers `user_id` column
 	User        User    // Belongs To relationship with User
+	// ClientID is the UUID an offline-capable client gave the product when creating it, unique per owner
+	// (see repository.ProductMigrations), so a replayed create returns the existing product
+	ClientID *string `gorm:"type:uuid"`
 	// CreatedAt time.Time is provided by gorm.Model
 	// UpdatedAt time.Time is provided by gorm.Model
 }
 
+// Version identifies the state of a product for write preconditions: its modification time in microseconds,
+// the precision Postgres stores
+func (p *Product) Version() int64 {
+	return p.UpdatedAt.UnixMicro()
+}
+
 // AddProductRequest is the payload for adding a new product
 type AddProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
+	Name        string  `json:"name" form:"name" binding:"required"`
+	Description string  `json:"description" form:"description"`
+	Price       float64 `json:"price" form:"price" binding:"required,gt=0"`
+	ClientID    string  `json:"clientId" form:"clientId" binding:"omitempty,uuid"` // Set by offline clients, makes the create idempotent
 }
 
 // UpdateProductRequest is the payload for updating an existing product
 type UpdateProductRequest struct {
+	Name        string  `json:"name" form:"name"`
+	Description string  `json:"description" form:"description"`
+	Price       float64 `json:"price,omitempty" form:"price" binding:"omitempty,gt=0"` // omitempty if not provided, gt=0 if provided
+}
+
+// ProductOwner is the view of a product's owner embedded in product responses
+type ProductOwner struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email,omitempty" visibleTo:"admin"` // Only support staff may see owners' contact details
+}
+
+// ProductResponse is the API representation of a product.
+// Role-restricted fields (see the visibleTo tags) are stripped by redact.ForRole before writing,
+// and deprecated ones (see the removedIn tags) by apiversion.ForVersion for clients past their removal.
+type ProductResponse struct {
+	ID             uint          `json:"id"`
+	Name           string        `json:"name"`
+	Description    string        `json:"description"`
+	Price          float64       `json:"price,omitempty" removedIn:"2026-10-16"` // Replaced by UnitPriceCents
+	UnitPriceCents int64         `json:"unitPriceCents"`
+	UserID         uint          `json:"userId"`
+	Owner          *ProductOwner `json:"owner,omitempty"` // Only populated when the owner was loaded with the product
+	ClientID       string        `json:"clientId,omitempty"`
+	Version        int64         `json:"version"` // Sent back in If-Match to make a write conditional
+	CreatedAt      time.Time     `json:"createdAt"`
+	UpdatedAt      time.Time     `json:"updatedAt"`
+}
+
+// NewProductResponse converts a Product model into its API representation
+func NewProductResponse(product *Product) *ProductResponse {
+	res := &ProductResponse{
+		ID:             product.ID,
+		Name:           product.Name,
+		Description:    product.Description,
+		Price:          product.Price,
+		UnitPriceCents: int64(math.Round(product.Price * 100)),
+		UserID:         product.UserID,
+		Version:        product.Version(),
+		CreatedAt:      product.CreatedAt,
+		UpdatedAt:      product.UpdatedAt,
+	}
+	if product.ClientID != nil {
+		res.ClientID = *product.ClientID
+	}
+	if product.User.ID != 0 {
+		res.Owner = &ProductOwner{
+			ID:       product.User.ID,
+			Username: product.User.Username,
+			Email:    product.User.Email,
+		}
+	}
+	return res
+}
+
+// ProductPrecondition is the state a client expects a product to be in before it writes it, from the
+// If-Match and If-Unmodified-Since headers. Nil fields are not checked.
+type ProductPrecondition struct {
+	Versions        []int64 // The product must have one of these versions; empty for If-Match: *
+	UnmodifiedSince *time.Time
+}
+
+// Holds reports whether the product is in the expected state
+func (p *ProductPrecondition) Holds(product *Product) bool {
+	if p.UnmodifiedSince != nil && product.UpdatedAt.Truncate(time.Second).After(*p.UnmodifiedSince) {
+		return false // HTTP dates have second precision
+	}
+	if len(p.Versions) == 0 {
+		return true
+	}
+	for _, v := range p.Versions {
+		if v == product.Version() {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductConflictResponse is returned when a write precondition fails, with both sides for the client to merge.
+// Server is nil if the product was deleted.
+type ProductConflictResponse struct {
+	Error  string           `json:"error"`
+	Server *ProductResponse `json:"server"`
+	Client interface{}      `json:"client"` // The rejected request payload, nil for deletes
+}
+
+// NewProductResponses converts a list of Product models into their API representation
+func NewProductResponses(products []*Product) []*ProductResponse {
+	res := make([]*ProductResponse, 0, len(products))
+	for _, product := range products {
+		res = append(res, NewProductResponse(product))
+	}
+	return res
+}
+
+// ProductFilter selects products for admin bulk operations. Empty fields are ignored.
+type ProductFilter struct {
+	IDs          []uint   `json:"ids" binding:"omitempty,max=10000"`
+	UserID       *uint    `json:"userId"`
+	NameContains string   `json:"nameContains"`
+	MinPrice     *float64 `json:"minPrice" binding:"omitempty,gte=0"`
+	MaxPrice     *float64 `json:"maxPrice" binding:"omitempty,gte=0"`
+}
+
+// IsEmpty reports whether the filter would match every product
+func (f *ProductFilter) IsEmpty() bool {
+	return len(f.IDs) == 0 && f.UserID == nil && f.NameContains == "" && f.MinPrice == nil && f.MaxPrice == nil
+}
+
+// ProductPatch describes the changes applied by a bulk update. Price and PricePercent are mutually exclusive.
+type ProductPatch struct {
+	Price        *float64 `json:"price" binding:"omitempty,gt=0"`
+	PricePercent *float64 `json:"pricePercent" binding:"omitempty,gt=-100"` // e.g. 10 raises prices by 10%, -15 lowers them by 15%
+	Description  *string  `json:"description"`
+}
+
+// BulkUpdateProductsRequest is the payload for the admin bulk product update
+type BulkUpdateProductsRequest struct {
+	Filter    ProductFilter `json:"filter" form:"filter"`
+	Patch     ProductPatch  `json:"patch" form:"patch"`
+	DryRun    bool          `json:"dryRun" form:"dryRun"`                                          // Only report what would change
+	BatchSize int           `json:"batchSize" form:"batchSize" binding:"omitempty,min=1,max=1000"` // Products updated per batch, defaults to 100
+}
+
+// Validate checks the cross-field rules binding tags can't express
+func (r *BulkUpdateProductsRequest) Validate() error {
+	if r.Filter.IsEmpty() {
+		return errors.New("filter must set at least one criterion")
+	}
+	if r.Patch.Price == nil && r.Patch.PricePercent == nil && r.Patch.Description == nil {
+		return errors.New("patch must change at least one field")
+	}
+	if r.Patch.Price != nil && r.Patch.PricePercent != nil {
+		return errors.New("price and pricePercent are mutually exclusive")
+	}
+	return nil
+}
+
+// BulkUpdatePreview shows the effect of a bulk update on one product
+type BulkUpdatePreview struct {
+	ID          uint    `json:"id"`
 	Name        string  `json:"name"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price,omitempty" binding:"omitempty,gt=0"` // omitempty if not provided, gt=0 if provided
-}
\ No newline at end of file
+	PriceBefore float64 `json:"priceBefore"`
+	PriceAfter  float64 `json:"priceAfter"`
+}
+
+// BulkUpdateResult summarizes a bulk product update
+type BulkUpdateResult struct {
+	DryRun  bool                 `json:"dryRun"`
+	Matched int64                `json:"matched"`
+	Updated int                  `json:"updated"`
+	Failed  []uint               `json:"failed,omitempty"` // IDs of products that could not be updated
+	Sample  []*BulkUpdatePreview `json:"sample,omitempty"` // First few changes, for review in dry runs
+}
@@ -0,0 +1,64 @@
+package models
+
+import "errors"
+
+// Batch operations
+const (
+	ProductBatchCreate = "create"
+	ProductBatchUpdate = "update"
+	ProductBatchDelete = "delete"
+)
+
+// Batch modes
+const (
+	ProductBatchAtomic     = "atomic"      // All operations apply, or none does
+	ProductBatchBestEffort = "best-effort" // Failed operations are skipped, the others apply
+)
+
+// ProductBatchOperation is one create, update or delete of a batch.
+// Creates take the fields of AddProductRequest; updates those of UpdateProductRequest.
+type ProductBatchOperation struct {
+	Op          string  `json:"op" binding:"required,oneof=create update delete"`
+	ID          uint    `json:"id"`      // Product to update or delete
+	Version     *int64  `json:"version"` // Optional precondition of updates and deletes, like If-Match
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price" binding:"omitempty,gt=0"`
+	ClientID    string  `json:"clientId" binding:"omitempty,uuid"`
+}
+
+// ProductBatchRequest is the payload of a product batch
+type ProductBatchRequest struct {
+	Mode       string                   `json:"mode" binding:"omitempty,oneof=atomic best-effort"` // Defaults to atomic
+	Operations []*ProductBatchOperation `json:"operations" binding:"required,min=1,max=100,dive"`  // All run in one transaction
+}
+
+// Validate checks that updates and deletes name a product
+func (r *ProductBatchRequest) Validate() error {
+	for _, op := range r.Operations {
+		if op.Op != ProductBatchCreate && op.ID == 0 {
+			return errors.New("operations to update or delete require id")
+		}
+	}
+	return nil
+}
+
+// ProductBatchOperationResult is the outcome of one operation, in request order.
+// Status is the HTTP status the operation would have had as a single request.
+type ProductBatchOperationResult struct {
+	Index   int              `json:"index"`
+	Op      string           `json:"op"`
+	Status  int              `json:"status"`
+	Error   string           `json:"error,omitempty"`
+	Product *ProductResponse `json:"product,omitempty"` // The product after the operation, or the stored copy on a conflict
+}
+
+// ProductBatchResult is the outcome of a product batch.
+// In atomic mode a single failure rolls everything back: Committed is false and the other operations report 424.
+type ProductBatchResult struct {
+	Mode      string                         `json:"mode"`
+	Committed bool                           `json:"committed"`
+	Succeeded int                            `json:"succeeded"`
+	Failed    int                            `json:"failed"`
+	Results   []*ProductBatchOperationResult `json:"results"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ProductChange records that a user's product was created, updated, deleted or moved to another owner.
+// Rows are written by a trigger on products (see repository.ProductMigrations), so no write path can skip them,
+// and pruned after sync.changeRetention. Seq orders the change feed.
+type ProductChange struct {
+	Seq       int64     `gorm:"primaryKey;autoIncrement;index:idx_product_changes_user_seq,priority:2"`
+	UserID    uint      `gorm:"not null;index:idx_product_changes_user_seq,priority:1"` // Owner whose feed the change belongs to
+	ProductID uint      `gorm:"not null"`
+	ChangedAt time.Time `gorm:"not null;index"`
+}
+
+// ProductChangeResponse is an entry of the change feed: the current state of a product, or its deletion
+type ProductChangeResponse struct {
+	ID      uint             `json:"id"`
+	Deleted bool             `json:"deleted"`
+	Product *ProductResponse `json:"product,omitempty"`
+}
+
+// ProductChangesResponse is a page of the change feed.
+// Token is passed as since on the next call; HasMore means more changes are waiting and the client should call again right away.
+type ProductChangesResponse struct {
+	Changes []*ProductChangeResponse `json:"changes"`
+	Token   string                   `json:"token"`
+	HasMore bool                     `json:"hasMore"`
+}
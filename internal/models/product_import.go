@@ -0,0 +1,26 @@
+package models
+
+// ImportRecord is an external product record mapped into the fields a product import understands
+type ImportRecord struct {
+	Ref         string // Where the record came from, e.g. a CSV line or an external ID, for error reports
+	Name        string
+	Description string
+	Price       float64
+}
+
+// ImportError describes a record that could not be imported
+type ImportError struct {
+	Ref   string `json:"ref"`
+	Error string `json:"error"`
+}
+
+// ImportResult summarizes a product import
+type ImportResult struct {
+	Format  string         `json:"format"`
+	Read    int            `json:"read"`
+	Created int            `json:"created"`
+	Updated int            `json:"updated"`
+	Skipped int            `json:"skipped"` // Records identical to the existing product
+	Failed  int            `json:"failed"`
+	Errors  []*ImportError `json:"errors,omitempty"` // First few failures, see Failed for the total
+}
@@ -0,0 +1,60 @@
+package models
+
+import "time"
+
+// Access levels of a product permission
+const (
+	ProductAccessRead  = "read"  // Listed under the grantee's shared products
+	ProductAccessWrite = "write" // Read, and update the product; deleting and sharing stay with the owner
+)
+
+// ProductPermission grants a user other than the owner access to one product
+type ProductPermission struct {
+	ProductID uint   `gorm:"primaryKey"`
+	UserID    uint   `gorm:"primaryKey;index"` // The grantee
+	Access    string `gorm:"not null"`         // ProductAccessRead or ProductAccessWrite
+	GrantedBy uint   `gorm:"not null"`         // Acting user who granted or last changed it
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// GrantProductPermissionRequest is the payload for granting a user access to a product.
+// Granting again changes the access level of the existing permission.
+type GrantProductPermissionRequest struct {
+	UserID uint   `json:"userId" form:"userId" binding:"required"`
+	Access string `json:"access" form:"access" binding:"required,oneof=read write"`
+}
+
+// ProductPermissionResponse is the API representation of a product permission
+type ProductPermissionResponse struct {
+	ProductID uint      `json:"productId"`
+	UserID    uint      `json:"userId"`
+	Access    string    `json:"access"`
+	GrantedBy uint      `json:"grantedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// NewProductPermissionResponse converts a ProductPermission model into its API representation
+func NewProductPermissionResponse(p *ProductPermission) *ProductPermissionResponse {
+	return &ProductPermissionResponse{
+		ProductID: p.ProductID,
+		UserID:    p.UserID,
+		Access:    p.Access,
+		GrantedBy: p.GrantedBy,
+		CreatedAt: p.CreatedAt,
+		UpdatedAt: p.UpdatedAt,
+	}
+}
+
+// SharedProduct is a product another user granted access to, with the access level
+type SharedProduct struct {
+	Product *Product
+	Access  string
+}
+
+// SharedProductResponse is the API representation of a shared product
+type SharedProductResponse struct {
+	*ProductResponse
+	Access string `json:"access"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Report target types
+const (
+	ReportTargetProduct = "product"
+	ReportTargetComment = "comment"
+)
+
+// Report statuses. Reports start open and move to exactly one terminal status.
+const (
+	ReportStatusOpen      = "open"      // Awaiting admin review
+	ReportStatusActioned  = "actioned"  // Admin agreed and acted on the content
+	ReportStatusDismissed = "dismissed" // Admin found no violation
+)
+
+// Report is a user's flag on a product or comment, reviewed by admins in the moderation queue
+type Report struct {
+	gorm.Model            // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	TargetType     string `gorm:"not null;index:idx_reports_target"` // One of the ReportTarget* constants
+	TargetID       uint   `gorm:"not null;index:idx_reports_target"`
+	ReporterID     uint   `gorm:"not null"`
+	Reason         string `gorm:"not null"`
+	Details        string
+	Status         string `gorm:"not null;default:open;index"` // One of the ReportStatus* constants
+	ResolvedBy     *uint  // Admin who resolved the report
+	ResolvedAt     *time.Time
+	ResolutionNote string
+}
+
+// CreateReportRequest is the payload for reporting a product or comment
+type CreateReportRequest struct {
+	Reason  string `json:"reason" form:"reason" binding:"required,oneof=spam offensive prohibited misleading other"`
+	Details string `json:"details" form:"details" binding:"max=2000"`
+}
+
+// ResolveReportRequest is the payload for an admin resolving a report
+type ResolveReportRequest struct {
+	Status string `json:"status" form:"status" binding:"required,oneof=actioned dismissed"`
+	Note   string `json:"note" form:"note" binding:"max=2000"`
+}
+
+// ReportResponse is the API representation of a report
+type ReportResponse struct {
+	ID             uint       `json:"id"`
+	TargetType     string     `json:"targetType"`
+	TargetID       uint       `json:"targetId"`
+	ReporterID     uint       `json:"reporterId"`
+	Reason         string     `json:"reason"`
+	Details        string     `json:"details,omitempty"`
+	Status         string     `json:"status"`
+	ResolvedBy     *uint      `json:"resolvedBy,omitempty"`
+	ResolvedAt     *time.Time `json:"resolvedAt,omitempty"`
+	ResolutionNote string     `json:"resolutionNote,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// ReportListResponse is a page of reports
+type ReportListResponse struct {
+	Items    []*ReportResponse `json:"items"`
+	Page     int               `json:"page"`
+	PageSize int               `json:"pageSize"`
+	Total    int64             `json:"total"`
+}
+
+// NewReportResponse converts a Report model into its API representation
+func NewReportResponse(report *Report) *ReportResponse {
+	return &ReportResponse{
+		ID:             report.ID,
+		TargetType:     report.TargetType,
+		TargetID:       report.TargetID,
+		ReporterID:     report.ReporterID,
+		Reason:         report.Reason,
+		Details:        report.Details,
+		Status:         report.Status,
+		ResolvedBy:     report.ResolvedBy,
+		ResolvedAt:     report.ResolvedAt,
+		ResolutionNote: report.ResolutionNote,
+		CreatedAt:      report.CreatedAt,
+	}
+}
+
+// NewReportResponses converts a list of Report models into their API representation
+func NewReportResponses(reports []*Report) []*ReportResponse {
+	res := make([]*ReportResponse, 0, len(reports))
+	for _, report := range reports {
+		res = append(res, NewReportResponse(report))
+	}
+	return res
+}
@@ -0,0 +1,247 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReportTemplate is an ad-hoc report an admin defined: which rows of a dataset to output, with which columns.
+// It runs on demand, with its parameters given at run time.
+type ReportTemplate struct {
+	gorm.Model        // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	Name       string `gorm:"not null"`
+	Spec       string `gorm:"type:jsonb;not null"` // JSON-encoded ReportSpec
+	CreatedBy  uint   `gorm:"not null"`
+}
+
+// DecodeSpec parses the stored spec. It does not validate it.
+func (t *ReportTemplate) DecodeSpec() (*ReportSpec, error) {
+	spec := &ReportSpec{}
+	if err := json.Unmarshal([]byte(t.Spec), spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// Types of report fields, which decide how filter values are read
+const (
+	ReportFieldText   = "text"
+	ReportFieldInt    = "int"
+	ReportFieldNumber = "number"
+	ReportFieldTime   = "time" // RFC3339 in filters, RFC3339 in output
+)
+
+// ReportField is a field reports may output and filter on
+type ReportField struct {
+	Column string // Column of the dataset's table
+	Type   string // One of the ReportField* types
+}
+
+// ReportDataset is what a report reads: a table and the fields of it reports may use. Only these fields can
+// appear in a report, so a template never names a column by itself, and secrets such as password hashes stay out.
+type ReportDataset struct {
+	Table       string
+	SoftDeleted bool // Rows with deleted_at set are left out
+	Fields      map[string]ReportField
+}
+
+// ReportDatasets lists the datasets of reports by the name specs use
+var ReportDatasets = map[string]ReportDataset{
+	"products": {Table: "products", SoftDeleted: true, Fields: map[string]ReportField{
+		"id":          {Column: "id", Type: ReportFieldInt},
+		"name":        {Column: "name", Type: ReportFieldText},
+		"description": {Column: "description", Type: ReportFieldText},
+		"price":       {Column: "price", Type: ReportFieldNumber},
+		"userId":      {Column: "user_id", Type: ReportFieldInt},
+		"createdAt":   {Column: "created_at", Type: ReportFieldTime},
+		"updatedAt":   {Column: "updated_at", Type: ReportFieldTime},
+	}},
+	"users": {Table: "users", SoftDeleted: true, Fields: map[string]ReportField{
+		"id":        {Column: "id", Type: ReportFieldInt},
+		"username":  {Column: "username", Type: ReportFieldText},
+		"email":     {Column: "email", Type: ReportFieldText},
+		"role":      {Column: "role", Type: ReportFieldText},
+		"createdAt": {Column: "created_at", Type: ReportFieldTime},
+	}},
+	"auditEvents": {Table: "audit_events", Fields: map[string]ReportField{
+		"id":              {Column: "id", Type: ReportFieldInt},
+		"action":          {Column: "action", Type: ReportFieldText},
+		"resourceType":    {Column: "resource_type", Type: ReportFieldText},
+		"resourceId":      {Column: "resource_id", Type: ReportFieldInt},
+		"actingUserId":    {Column: "acting_user_id", Type: ReportFieldInt},
+		"effectiveUserId": {Column: "effective_user_id", Type: ReportFieldInt},
+		"createdAt":       {Column: "created_at", Type: ReportFieldTime},
+	}},
+}
+
+// Report filter operators
+const (
+	ReportOpEq       = "eq"
+	ReportOpNe       = "ne"
+	ReportOpLt       = "lt"
+	ReportOpLte      = "lte"
+	ReportOpGt       = "gt"
+	ReportOpGte      = "gte"
+	ReportOpContains = "contains" // Case-insensitive substring, text fields only
+)
+
+// MaxReportRows caps the rows of a report, and is the limit of specs that don't set one
+const MaxReportRows = 100000
+
+// reportParamName is the form of parameter names, which become query parameters at run time
+var reportParamName = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9_]{0,31}$`)
+
+// ReportSpec defines a report. It is stored as JSON, so fields may be added but never renamed, and stored specs
+// are validated again before they run.
+type ReportSpec struct {
+	Dataset    string         `json:"dataset" binding:"required"`
+	Columns    []ReportColumn `json:"columns" binding:"required,min=1,max=30,dive"`
+	Filters    []ReportFilter `json:"filters,omitempty" binding:"max=20,dive"`
+	OrderBy    string         `json:"orderBy,omitempty"` // A field of the dataset; ID order by default
+	Descending bool           `json:"descending,omitempty"`
+	Limit      int            `json:"limit,omitempty" binding:"omitempty,min=1"` // At most MaxReportRows
+}
+
+// ReportColumn is an output column: a field of the dataset and its CSV header
+type ReportColumn struct {
+	Field string `json:"field" binding:"required"`
+	Label string `json:"label,omitempty" binding:"max=64"` // Defaults to the field name
+}
+
+// ReportFilter keeps the rows whose field compares to a value with op. The value is either fixed in the
+// template or a parameter given when the report runs, e.g. {"field":"createdAt","op":"gte","param":"from"}.
+type ReportFilter struct {
+	Field string  `json:"field" binding:"required"`
+	Op    string  `json:"op" binding:"required,oneof=eq ne lt lte gt gte contains"`
+	Value *string `json:"value,omitempty" binding:"omitempty,max=200"`
+	Param string  `json:"param,omitempty"`
+}
+
+// Validate checks the dataset, fields and filters, which binding tags only check field by field
+func (s *ReportSpec) Validate() error {
+	dataset, ok := ReportDatasets[s.Dataset]
+	if !ok {
+		return fmt.Errorf("unknown dataset %q", s.Dataset)
+	}
+	for _, col := range s.Columns {
+		if _, ok := dataset.Fields[col.Field]; !ok {
+			return fmt.Errorf("dataset %s has no field %q", s.Dataset, col.Field)
+		}
+	}
+	for _, f := range s.Filters {
+		field, ok := dataset.Fields[f.Field]
+		if !ok {
+			return fmt.Errorf("dataset %s has no field %q", s.Dataset, f.Field)
+		}
+		if f.Op == ReportOpContains && field.Type != ReportFieldText {
+			return fmt.Errorf("filter on %s: contains only applies to text fields", f.Field)
+		}
+		switch {
+		case (f.Value == nil) == (f.Param == ""):
+			return fmt.Errorf("filter on %s must set either value or param", f.Field)
+		case f.Param != "" && !reportParamName.MatchString(f.Param):
+			return fmt.Errorf("filter on %s: param must be a letter followed by up to 31 letters, digits or underscores", f.Field)
+		case f.Value != nil:
+			if _, err := ParseReportValue(field.Type, *f.Value); err != nil {
+				return fmt.Errorf("filter on %s: %w", f.Field, err)
+			}
+		}
+	}
+	if s.OrderBy != "" {
+		if _, ok := dataset.Fields[s.OrderBy]; !ok {
+			return fmt.Errorf("dataset %s has no field %q", s.Dataset, s.OrderBy)
+		}
+	}
+	if s.Limit > MaxReportRows {
+		return fmt.Errorf("limit must be at most %d", MaxReportRows)
+	}
+	return nil
+}
+
+// Params returns the names of the parameters the report needs at run time, in filter order
+func (s *ReportSpec) Params() []string {
+	var params []string
+	seen := map[string]bool{}
+	for _, f := range s.Filters {
+		if f.Param != "" && !seen[f.Param] {
+			seen[f.Param] = true
+			params = append(params, f.Param)
+		}
+	}
+	return params
+}
+
+// ParseReportValue reads a filter value of a field type
+func ParseReportValue(fieldType, v string) (interface{}, error) {
+	switch fieldType {
+	case ReportFieldInt:
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, errors.New("value must be an integer")
+		}
+		return n, nil
+	case ReportFieldNumber:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, errors.New("value must be a number")
+		}
+		return n, nil
+	case ReportFieldTime:
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.New("value must be an RFC3339 timestamp")
+		}
+		return t, nil
+	default:
+		return v, nil
+	}
+}
+
+// ReportTemplateRequest is the payload for defining a report template
+type ReportTemplateRequest struct {
+	Name string     `json:"name" form:"name" binding:"required,max=64"`
+	Spec ReportSpec `json:"spec" form:"spec"`
+}
+
+// Validate checks the spec, which binding tags only check field by field
+func (r *ReportTemplateRequest) Validate() error {
+	return r.Spec.Validate()
+}
+
+// ReportTemplateResponse is the API representation of a report template
+type ReportTemplateResponse struct {
+	ID        uint        `json:"id"`
+	Name      string      `json:"name"`
+	Spec      *ReportSpec `json:"spec"`
+	Params    []string    `json:"params"` // Query parameters a run needs
+	CreatedBy uint        `json:"createdBy"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+// NewReportTemplateResponse converts a ReportTemplate model into its API representation.
+// A spec that can't be decoded is left out rather than failing the whole listing.
+func NewReportTemplateResponse(t *ReportTemplate) *ReportTemplateResponse {
+	res := &ReportTemplateResponse{ID: t.ID, Name: t.Name, Params: []string{}, CreatedBy: t.CreatedBy, CreatedAt: t.CreatedAt}
+	if spec, err := t.DecodeSpec(); err == nil {
+		res.Spec = spec
+		if params := spec.Params(); params != nil {
+			res.Params = params
+		}
+	}
+	return res
+}
+
+// NewReportTemplateResponses converts a list of ReportTemplate models into their API representation
+func NewReportTemplateResponses(templates []*ReportTemplate) []*ReportTemplateResponse {
+	res := make([]*ReportTemplateResponse, 0, len(templates))
+	for _, t := range templates {
+		res = append(res, NewReportTemplateResponse(t))
+	}
+	return res
+}
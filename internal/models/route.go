@@ -0,0 +1,14 @@
+package models
+
+// RouteInfo describes a registered route and who may call it, for reviewing authorization coverage
+type RouteInfo struct {
+	Method     string   `json:"method"`
+	Path       string   `json:"path"`
+	Access     string   `json:"access"`               // Who may call it, as declared: public, user, owner or admin
+	Roles      []string `json:"roles,omitempty"`      // Roles let through; empty when any authenticated user is
+	Scopes     []string `json:"scopes,omitempty"`     // Scopes a personal access token needs
+	Cost       int      `json:"cost"`                 // Deducted from the caller's budget per request
+	Middleware []string `json:"middleware"`           // Handler chain before the handler, in order
+	Handler    string   `json:"handler"`              // Function handling the route
+	Duplicates []string `json:"duplicates,omitempty"` // Middleware appearing more than once in the chain
+}
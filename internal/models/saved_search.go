@@ -0,0 +1,90 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SavedSearch is a named set of product filters a user can run again later
+type SavedSearch struct {
+	gorm.Model        // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
+	UserID     uint   `gorm:"not null;index"`
+	Name       string `gorm:"not null"`
+	Spec       string `gorm:"type:jsonb;not null"` // JSON-encoded ProductSearchSpec
+}
+
+// DecodeSpec parses the stored spec. It does not validate it.
+func (s *SavedSearch) DecodeSpec() (*ProductSearchSpec, error) {
+	spec := &ProductSearchSpec{}
+	if err := json.Unmarshal([]byte(s.Spec), spec); err != nil {
+		return nil, err
+	}
+	return spec, nil
+}
+
+// ProductSearchSpec is the filter set of a saved search. It is stored as JSON, so fields may be added but
+// never renamed, and stored specs are validated again before they run.
+type ProductSearchSpec struct {
+	NameContains string   `json:"nameContains,omitempty" binding:"max=200"`
+	MinPrice     *float64 `json:"minPrice,omitempty" binding:"omitempty,gte=0"`
+	MaxPrice     *float64 `json:"maxPrice,omitempty" binding:"omitempty,gte=0"`
+}
+
+// Validate checks the cross-field rules binding tags can't express
+func (s *ProductSearchSpec) Validate() error {
+	if s.NameContains == "" && s.MinPrice == nil && s.MaxPrice == nil {
+		return errors.New("spec must set at least one filter")
+	}
+	if s.MinPrice != nil && s.MaxPrice != nil && *s.MinPrice > *s.MaxPrice {
+		return errors.New("minPrice must not be greater than maxPrice")
+	}
+	return nil
+}
+
+// Filter turns the spec into a product filter over the products of userID
+func (s *ProductSearchSpec) Filter(userID uint) *ProductFilter {
+	return &ProductFilter{UserID: &userID, NameContains: s.NameContains, MinPrice: s.MinPrice, MaxPrice: s.MaxPrice}
+}
+
+// SavedSearchRequest is the payload for saving a search
+type SavedSearchRequest struct {
+	Name string            `json:"name" form:"name" binding:"required,max=64"`
+	Spec ProductSearchSpec `json:"spec" form:"spec"`
+}
+
+// Validate checks the spec, which binding tags only check field by field
+func (r *SavedSearchRequest) Validate() error {
+	return r.Spec.Validate()
+}
+
+// SavedSearchResponse is the API representation of a saved search
+type SavedSearchResponse struct {
+	ID        uint               `json:"id"`
+	Name      string             `json:"name"`
+	Spec      *ProductSearchSpec `json:"spec"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// NewSavedSearchResponse converts a SavedSearch model into its API representation.
+// A spec that can't be decoded is left out rather than failing the whole listing.
+func NewSavedSearchResponse(s *SavedSearch) *SavedSearchResponse {
+	spec, _ := s.DecodeSpec()
+	return &SavedSearchResponse{
+		ID:        s.ID,
+		Name:      s.Name,
+		Spec:      spec,
+		CreatedAt: s.CreatedAt,
+	}
+}
+
+// NewSavedSearchResponses converts a list of SavedSearch models into their API representation
+func NewSavedSearchResponses(searches []*SavedSearch) []*SavedSearchResponse {
+	res := make([]*SavedSearchResponse, 0, len(searches))
+	for _, s := range searches {
+		res = append(res, NewSavedSearchResponse(s))
+	}
+	return res
+}
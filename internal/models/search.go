@@ -0,0 +1,34 @@
+package models
+
+// Result types of the global search
+const (
+	SearchTypeProducts = "products"
+	SearchTypeUsers    = "users" // Admins only
+)
+
+// SearchHit is one search result, reduced to what a result list shows
+type SearchHit struct {
+	ID      uint   `json:"id"`
+	Title   string `json:"title"`
+	Snippet string `json:"snippet,omitempty"`
+}
+
+// SearchPage is a page of results of one type
+type SearchPage struct {
+	Items    []*SearchHit `json:"items"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+	Total    int64        `json:"total"`
+}
+
+// SearchResponse holds the results of a search per result type
+type SearchResponse struct {
+	Query   string                 `json:"query"`
+	Results map[string]*SearchPage `json:"results"`
+}
+
+// SearchReindexResult reports a rebuild of the product search index
+type SearchReindexResult struct {
+	Index      string  `json:"index"`
+	DurationMs float64 `json:"durationMs"`
+}
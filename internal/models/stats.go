@@ -0,0 +1,145 @@
+package models
+
+import "time"
+
+// UserProductStats is the precomputed product count of one user
+type UserProductStats struct {
+	UserID     uint    `json:"userId"`
+	Username   string  `json:"username"`
+	Products   int64   `json:"products"`
+	TotalPrice float64 `json:"totalPrice"` // Sum of the prices of their live products
+}
+
+// UserProductStatsResponse is a page of per-user product counts, most products first
+type UserProductStatsResponse struct {
+	Items       []*UserProductStats `json:"items"`
+	Page        int                 `json:"page"`
+	PageSize    int                 `json:"pageSize"`
+	Total       int64               `json:"total"`
+	RefreshedAt *time.Time          `json:"refreshedAt"` // When the counts were computed; nil before the first product
+}
+
+// DailyProductStats is the precomputed summary of the products created on one day (UTC) and still live
+type DailyProductStats struct {
+	Day        string  `json:"day"` // YYYY-MM-DD
+	Created    int64   `json:"created"`
+	TotalPrice float64 `json:"totalPrice"`
+}
+
+// DailyProductStatsResponse holds the daily summaries of a period, oldest first. Days without products are omitted.
+type DailyProductStatsResponse struct {
+	From        string               `json:"from"`
+	To          string               `json:"to"`
+	Days        []*DailyProductStats `json:"days"`
+	RefreshedAt *time.Time           `json:"refreshedAt"` // When the summaries were computed; nil before the first product
+}
+
+// StatsRefreshResult reports a refresh of the precomputed aggregates
+type StatsRefreshResult struct {
+	RefreshedAt time.Time `json:"refreshedAt"`
+	DurationMs  float64   `json:"durationMs"`
+}
+
+// ProductViewCount is a number of views of a product on one day (UTC)
+type ProductViewCount struct {
+	ProductID uint
+	Day       time.Time // Midnight UTC
+	Views     int64
+}
+
+// DailyProductViews is the number of views of a product on one day (UTC)
+type DailyProductViews struct {
+	Day   string `json:"day"` // YYYY-MM-DD
+	Views int64  `json:"views"`
+}
+
+// ProductViewsResponse holds the daily views of a product over a period, oldest first. Days without views are
+// omitted. Views reach the counts within stats.viewFlushInterval; views by the owner are not counted.
+type ProductViewsResponse struct {
+	ProductID uint                 `json:"productId"`
+	From      string               `json:"from"`
+	To        string               `json:"to"`
+	Days      []*DailyProductViews `json:"days"`
+	Total     int64                `json:"total"`
+}
+
+// APIUsageCount is the use of the API by a user on one day (UTC), with one of their personal access tokens or
+// with their other credentials
+type APIUsageCount struct {
+	UserID       uint
+	TokenID      uint      // 0 for sessions and access tokens
+	Day          time.Time // Midnight UTC
+	Requests     int64
+	ClientErrors int64
+	ServerErrors int64
+	Throttled    int64
+	Cost         int64
+}
+
+// APIUsage sums requests and how they were answered
+type APIUsage struct {
+	Requests     int64   `json:"requests"`
+	ClientErrors int64   `json:"clientErrors"` // 4xx responses, throttled ones included
+	ServerErrors int64   `json:"serverErrors"` // 5xx responses
+	Throttled    int64   `json:"throttled"`    // 429 responses: over the cost budget or another rate limit
+	Cost         int64   `json:"cost"`         // Charged against the cost budget
+	ErrorRate    float64 `json:"errorRate" gorm:"-"`
+}
+
+// SetErrorRate computes ErrorRate, the share of requests answered with a 4xx or 5xx
+func (u *APIUsage) SetErrorRate() {
+	if u.Requests > 0 {
+		u.ErrorRate = float64(u.ClientErrors+u.ServerErrors) / float64(u.Requests)
+	}
+}
+
+// DailyAPIUsage is the use of the API by a user on one day (UTC), all credentials together
+type DailyAPIUsage struct {
+	Day string `json:"day"` // YYYY-MM-DD
+	APIUsage
+}
+
+// CredentialAPIUsage is the use of the API by a user with one personal access token, or with their other
+// credentials when TokenID is 0
+type CredentialAPIUsage struct {
+	TokenID   uint   `json:"tokenId"`
+	TokenName string `json:"tokenName,omitempty"` // Empty when TokenID is 0 or the token was deleted
+	APIUsage
+}
+
+// APIUsageLimit is the cost budget each user's requests are charged against, see config.CostLimitConfig
+type APIUsageLimit struct {
+	Budget int    `json:"budget"`
+	Window string `json:"window"` // Time over which a spent budget refills
+	DryRun bool   `json:"dryRun"` // Requests over budget are logged, not refused
+}
+
+// APIUsageResponse holds the use of the API by a user over a period: per day, oldest first, and per credential,
+// most requests first. Days and credentials without requests are omitted. Requests reach the counts within
+// stats.usageFlushInterval; they are counted for the acting user, the one the cost budget is charged to.
+type APIUsageResponse struct {
+	UserID      uint                  `json:"userId"`
+	From        string                `json:"from"`
+	To          string                `json:"to"`
+	Days        []*DailyAPIUsage      `json:"days"`
+	Credentials []*CredentialAPIUsage `json:"credentials"`
+	Total       APIUsage              `json:"total"`
+	Limit       *APIUsageLimit        `json:"limit"` // Nil when no cost budget is configured
+}
+
+// UserAPIUsage is the use of the API by one user over a period
+type UserAPIUsage struct {
+	UserID   uint   `json:"userId"`
+	Username string `json:"username"`
+	APIUsage
+}
+
+// UserAPIUsageResponse is a page of per-user API usage over a period, most requests first
+type UserAPIUsageResponse struct {
+	From     string          `json:"from"`
+	To       string          `json:"to"`
+	Items    []*UserAPIUsage `json:"items"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"pageSize"`
+	Total    int64           `json:"total"`
+}
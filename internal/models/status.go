@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// Component and instance states reported by the status endpoint
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded" // Some component is down; the instance serves what it can
+	StatusDown     = "down"
+	StatusDisabled = "disabled" // Not configured on this instance
+)
+
+// InstanceStatus is a snapshot of one instance's health and background work, for ops dashboards
+type InstanceStatus struct {
+	Status     string                      `json:"status"` // StatusOK, or StatusDegraded if any component is down
+	CheckedAt  time.Time                   `json:"checkedAt"`
+	StartedAt  time.Time                   `json:"startedAt"`
+	Components map[string]*ComponentStatus `json:"components"`
+	Queues     map[string]*QueueStatus     `json:"queues"`
+	Jobs       map[string]*JobStatus       `json:"jobs"`
+	Pool       *PoolStatus                 `json:"pool"`
+}
+
+// ComponentStatus is the health of a dependency or subsystem
+type ComponentStatus struct {
+	Status string `json:"status"` // One of the Status* constants
+	Error  string `json:"error,omitempty"`
+}
+
+// QueueStatus is the backlog of work waiting to be processed
+type QueueStatus struct {
+	Pending int64  `json:"pending"`
+	Running int64  `json:"running"`
+	Error   string `json:"error,omitempty"` // Set if the depth couldn't be measured
+}
+
+// PoolStatus is the usage of the database connection pool. Waits count since the instance started.
+type PoolStatus struct {
+	MaxOpen        int     `json:"maxOpen"`
+	Open           int     `json:"open"`
+	InUse          int     `json:"inUse"`
+	Idle           int     `json:"idle"`
+	Waiting        int64   `json:"waiting"`        // Statements waiting for a connection right now, estimated
+	WaitCount      int64   `json:"waitCount"`      // Times a statement had to wait for a connection
+	WaitDurationMs float64 `json:"waitDurationMs"` // Total time spent waiting for connections
+}
+
+// JobStatus describes the recent runs of a background job
+type JobStatus struct {
+	LastRun     *time.Time `json:"lastRun,omitempty"`
+	LastSuccess *time.Time `json:"lastSuccess,omitempty"`
+	LastError   string     `json:"lastError,omitempty"` // Error of the last run, if it failed
+}
+
+// SetReadOnlyRequest is the payload for switching read-only mode
+type SetReadOnlyRequest struct {
+	Enabled *bool  `json:"enabled" form:"enabled" binding:"required"`
+	Reason  string `json:"reason" form:"reason" binding:"max=200"` // Shown to clients whose requests are rejected
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// TimeRange restricts a listing by creation and modification time, for clients syncing incremental changes.
+// Bounds are exclusive; nil bounds are open.
+type TimeRange struct {
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+}
+
+// IsEmpty reports whether the range restricts nothing
+func (r *TimeRange) IsEmpty() bool {
+	return r.CreatedAfter == nil && r.CreatedBefore == nil && r.UpdatedAfter == nil
+}
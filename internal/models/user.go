@@ -1,34 +1,88 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
+// User roles
+const (
+	RoleUser  = "user"  // Default role for registered users
+	RoleAdmin = "admin" // Support/operations staff with elevated visibility
+)
+
+// PasswordPending is stored as the password of accounts created without one, e.g. by an admin import.
+// It is not a bcrypt hash, so such accounts cannot log in until a password is set.
+const PasswordPending = "!pending"
+
+// PendingPassword is stored as the password of an account created without one, followed by the hash of the
+// set-password token its owner chooses a password with
+func PendingPassword(tokenHash string) string {
+	return PasswordPending + ":" + tokenHash
+}
+
+// PasswordAnonymized replaces the password hash of anonymized accounts, which can never log in again
+const PasswordAnonymized = "!anonymized"
+
 // User represents a user in the system
 type User struct {
 	gorm.Model // Embed gorm.Model for ID, CreatedAt, UpdatedAt, DeletedAt
 	// ID        uint is provided by gorm.Model
-	Username string `gorm:"unique;not null"` // Add unique and not null constraints
-	Email    string `gorm:"unique;not null"` // Add unique and not null constraints
-	Password string `gorm:"not null"`        // Store hashed password, not null
+	Username string `gorm:"not null"`              // Unique among live users, see repository.UserMigrations
+	Email    string `gorm:"not null"`              // Unique among live users, see repository.UserMigrations
+	Password string `gorm:"not null"`              // Store hashed password, not null
+	Role     string `gorm:"not null;default:user"` // One of the Role* constants
+	// AnonymizedAt is set once the personal data of a deleted user has been scrubbed; such accounts can't be restored
+	AnonymizedAt *time.Time
 	// CreatedAt time.Time is provided by gorm.Model
 	// UpdatedAt time.Time is provided by gorm.Model
 }
 
 // RegisterRequest is the payload for user registration
 type RegisterRequest struct {
-	Username string `json:"username" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6"`
+	Username string `json:"username" form:"username" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required,email" normalize:"email"`
+	Password string `json:"password" form:"password" binding:"required,min=6" normalize:"-"`
 }
 
-// LoginRequest is the payload for user login
+// LoginRequest is the payload for user login, as JSON or as a form
 type LoginRequest struct {
-	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required"`
+	Email    string `json:"email" form:"email" binding:"required_without=Username,omitempty,email" normalize:"email"`
+	Username string `json:"-" form:"username" binding:"omitempty,email" normalize:"email"` // OAuth password-grant clients send the email as username
+	Password string `json:"password" form:"password" binding:"required" normalize:"-"`
+}
+
+// Normalize moves an email sent as username into Email
+func (r *LoginRequest) Normalize() {
+	if r.Email == "" {
+		r.Email = r.Username
+	}
+}
+
+// ReauthenticateRequest is the payload for confirming the password of an already logged-in user
+type ReauthenticateRequest struct {
+	Password string `json:"password" form:"password" binding:"required" normalize:"-"`
+}
+
+// SetPasswordRequest is the payload for choosing the password of an account created without one, with the
+// one-time token issued when it was created
+type SetPasswordRequest struct {
+	Email    string `json:"email" binding:"required,email" normalize:"email"`
+	Token    string `json:"token" binding:"required" normalize:"-"`
+	Password string `json:"password" binding:"required,min=6" normalize:"-"`
 }
 
 // LoginResponse contains the JWT token after successful login
 type LoginResponse struct {
 	Token string `json:"token"`
 }
+
+// SessionResponse describes a session started with cookie authentication. The session ID itself is only
+// sent in the HttpOnly cookie.
+type SessionResponse struct {
+	UserID    uint      `json:"userId"`
+	Role      string    `json:"role"`
+	CSRFToken string    `json:"csrfToken"` // Send in the X-CSRF-Token header of every mutating request
+	ExpiresAt time.Time `json:"expiresAt"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// UserImportRecord is one row of a user import file
+type UserImportRecord struct {
+	Line     int // CSV line, for per-row results
+	Email    string
+	Username string
+	Role     string // Empty means RoleUser
+}
+
+// User import row outcomes
+const (
+	UserImportCreated = "created"
+	UserImportSkipped = "skipped" // An account with the email already exists
+	UserImportFailed  = "failed"
+)
+
+// UserImportRow reports what happened to one row of a user import
+type UserImportRow struct {
+	Line   int    `json:"line"`
+	Email  string `json:"email"`
+	Status string `json:"status"` // One of the UserImport* constants
+	UserID uint   `json:"userId,omitempty"`
+	// SetPasswordToken lets the user of a created account choose its password, once, see POST /set-password.
+	// Hand it to them over a trusted channel; it is only ever shown in this result.
+	SetPasswordToken string `json:"setPasswordToken,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+// UserImportResult summarizes a user import, with the outcome of every row
+type UserImportResult struct {
+	Read    int              `json:"read"`
+	Created int              `json:"created"`
+	Skipped int              `json:"skipped"`
+	Failed  int              `json:"failed"`
+	Rows    []*UserImportRow `json:"rows"`
+}
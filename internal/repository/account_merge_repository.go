@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// errMergeDryRun rolls back the transaction of a dry-run merge once its counts are taken
+var errMergeDryRun = errors.New("dry run")
+
+// AccountMergeRepository defines the cross-table operation used to merge a user account into another
+type AccountMergeRepository interface {
+	MergeUsers(ctx context.Context, sourceID, targetID uint, dryRun bool) (*models.AccountMergeReport, error)
+}
+
+// postgresAccountMergeRepository implements AccountMergeRepository using GORM with raw SQL
+type postgresAccountMergeRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAccountMergeRepository creates a new AccountMergeRepository instance
+func NewPostgresAccountMergeRepository(db *gorm.DB) AccountMergeRepository {
+	return &postgresAccountMergeRepository{db: db}
+}
+
+// MergeUsers moves everything the source user owns or authored to the target user and revokes the source's
+// personal access tokens, in one transaction using raw SQL. The source is left live for the caller to delete
+// through UserRepository.DeleteUser, which keeps the user cache right. A dry run makes the same changes and rolls
+// them back, so its counts are exact. Activity feeds, operations, audit events and processing records stay with
+// the source: they are history.
+func (r *postgresAccountMergeRepository) MergeUsers(ctx context.Context, sourceID, targetID uint, dryRun bool) (*models.AccountMergeReport, error) {
+	now := time.Now()
+	report := &models.AccountMergeReport{SourceUserID: sourceID, TargetUserID: targetID, DryRun: dryRun}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Both accounts stay live until the merge commits
+		var locked []uint
+		if err := tx.Raw(`SELECT id FROM users WHERE id IN (?, ?) AND deleted_at IS NULL FOR UPDATE`, sourceID, targetID).Scan(&locked).Error; err != nil {
+			return err
+		}
+		if len(locked) != 2 {
+			return fmt.Errorf("users %d and %d are not both live", sourceID, targetID)
+		}
+
+		var dropped, droppedDuplicates int64
+		steps := []struct {
+			count *int64
+			query string
+			args  []interface{}
+		}{
+			// Grants to either user on products the target owns once merged are pointless
+			{&dropped, `DELETE FROM product_permissions pp USING products p
+				WHERE pp.product_id = p.id AND p.user_id IN (?, ?) AND pp.user_id IN (?, ?)`,
+				[]interface{}{sourceID, targetID, sourceID, targetID}},
+			// Where both were granted the same product, the target keeps the wider access
+			{new(int64), `UPDATE product_permissions t SET access = s.access, updated_at = ? FROM product_permissions s
+				WHERE s.product_id = t.product_id AND s.user_id = ? AND t.user_id = ? AND s.access = ? AND t.access <> ?`,
+				[]interface{}{now, sourceID, targetID, models.ProductAccessWrite, models.ProductAccessWrite}},
+			{&droppedDuplicates, `DELETE FROM product_permissions s USING product_permissions t
+				WHERE s.product_id = t.product_id AND s.user_id = ? AND t.user_id = ?`,
+				[]interface{}{sourceID, targetID}},
+			{&report.PermissionsMoved, `UPDATE product_permissions SET user_id = ?, updated_at = ? WHERE user_id = ?`,
+				[]interface{}{targetID, now, sourceID}},
+			// Client IDs are unique per owner among live products; the target's products keep theirs
+			{&report.ClientIDsCleared, `UPDATE products SET client_id = NULL, updated_at = ?
+				WHERE user_id = ? AND deleted_at IS NULL AND client_id IN (
+					SELECT client_id FROM products WHERE user_id = ? AND deleted_at IS NULL AND client_id IS NOT NULL)`,
+				[]interface{}{now, sourceID, targetID}},
+			{&report.Products, `UPDATE products SET user_id = ?, updated_at = ? WHERE user_id = ?`,
+				[]interface{}{targetID, now, sourceID}},
+			{&report.Bundles, `UPDATE bundles SET user_id = ?, updated_at = ? WHERE user_id = ?`,
+				[]interface{}{targetID, now, sourceID}},
+			{&report.Comments, `UPDATE comments SET user_id = ?, updated_at = ? WHERE user_id = ?`,
+				[]interface{}{targetID, now, sourceID}},
+			{&report.Reports, `UPDATE reports SET reporter_id = ?, updated_at = ? WHERE reporter_id = ?`,
+				[]interface{}{targetID, now, sourceID}},
+			// At most one default address per user: the target's default wins
+			{&report.Addresses, `UPDATE addresses SET user_id = ?, updated_at = ?, is_default = is_default AND NOT EXISTS (
+					SELECT 1 FROM addresses WHERE user_id = ? AND is_default AND deleted_at IS NULL)
+				WHERE user_id = ?`,
+				[]interface{}{targetID, now, targetID, sourceID}},
+			{&report.SavedSearches, `UPDATE saved_searches SET user_id = ?, updated_at = ? WHERE user_id = ?`,
+				[]interface{}{targetID, now, sourceID}},
+			// Credentials are not handed over: whoever held the source's tokens never had access to the target
+			{&report.Tokens, `UPDATE personal_access_tokens SET revoked_at = ?, updated_at = ? WHERE user_id = ? AND revoked_at IS NULL`,
+				[]interface{}{now, now, sourceID}},
+		}
+		for _, step := range steps {
+			result := tx.Exec(step.query, step.args...)
+			if result.Error != nil {
+				return result.Error
+			}
+			*step.count = result.RowsAffected
+		}
+		report.PermissionsDropped = dropped + droppedDuplicates
+
+		if dryRun {
+			return errMergeDryRun
+		}
+		return nil
+	})
+	if dryRun && errors.Is(err, errMergeDryRun) {
+		return report, nil
+	}
+	if err != nil {
+		logger.Error("Failed to merge users using raw SQL", zap.Error(err), zap.Uint("sourceUserID", sourceID), zap.Uint("targetUserID", targetID))
+		return nil, fmt.Errorf("failed to merge users: %w", err)
+	}
+
+	report.MergedAt = &now
+	logger.Info("Users merged using raw SQL", zap.Uint("sourceUserID", sourceID), zap.Uint("targetUserID", targetID), zap.Int64("products", report.Products), actor.Field(ctx))
+	return report, nil
+}
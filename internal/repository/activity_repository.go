@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ActivityRepository defines the interface for activity feed data operations
+type ActivityRepository interface {
+	CreateActivityEntry(ctx context.Context, entry *models.ActivityEntry) error
+	GetActivityByUserID(ctx context.Context, userID uint, limit, offset int) ([]*models.ActivityEntry, int64, error)
+}
+
+// postgresActivityRepository implements ActivityRepository using GORM with raw SQL
+type postgresActivityRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresActivityRepository creates a new ActivityRepository instance
+func NewPostgresActivityRepository(db *gorm.DB) ActivityRepository {
+	return &postgresActivityRepository{db: db}
+}
+
+// CreateActivityEntry inserts a feed entry using raw SQL
+func (r *postgresActivityRepository) CreateActivityEntry(ctx context.Context, entry *models.ActivityEntry) error {
+	sqlQuery := `INSERT INTO activity_entries (user_id, verb, resource_type, resource_id, summary, created_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING id`
+
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		entry.UserID,
+		entry.Verb,
+		entry.ResourceType,
+		entry.ResourceID,
+		entry.Summary,
+		entry.CreatedAt,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create activity entry in DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", entry.UserID), zap.String("verb", entry.Verb))
+		return fmt.Errorf("failed to create activity entry: %w", result.Error)
+	}
+
+	entry.ID = newID
+	return nil
+}
+
+// GetActivityByUserID retrieves a page of a user's feed, newest first, using raw SQL
+func (r *postgresActivityRepository) GetActivityByUserID(ctx context.Context, userID uint, limit, offset int) ([]*models.ActivityEntry, int64, error) {
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM activity_entries WHERE user_id = ?`
+	if result := r.db.WithContext(ctx).Raw(countQuery, userID).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count activity entries using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, 0, fmt.Errorf("failed to count activity entries: %w", result.Error)
+	}
+
+	var entries []*models.ActivityEntry
+	sqlQuery := `SELECT id, user_id, verb, resource_type, resource_id, summary, created_at FROM activity_entries
+		WHERE user_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ? OFFSET ?`
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID, limit, offset).Scan(&entries)
+	if result.Error != nil {
+		logger.Error("Failed to get activity entries from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, 0, fmt.Errorf("failed to get activity entries: %w", result.Error)
+	}
+	return entries, total, nil
+}
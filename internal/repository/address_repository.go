@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AddressRepository defines the interface for address data operations
+type AddressRepository interface {
+	CreateAddress(ctx context.Context, address *models.Address) error
+	GetAddressByID(ctx context.Context, id uint) (*models.Address, error)
+	GetAddressesByUserID(ctx context.Context, userID uint) ([]*models.Address, error)
+	CountAddressesByUserID(ctx context.Context, userID uint) (int64, error)
+	UpdateAddress(ctx context.Context, address *models.Address) error
+	DeleteAddress(ctx context.Context, id uint) error
+	ClearDefaultAddress(ctx context.Context, userID uint) error
+}
+
+// postgresAddressRepository implements AddressRepository using GORM with raw SQL
+type postgresAddressRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAddressRepository creates a new AddressRepository instance
+func NewPostgresAddressRepository(db *gorm.DB) AddressRepository {
+	return &postgresAddressRepository{db: db}
+}
+
+// addressColumns lists the columns selected for an Address
+const addressColumns = `id, user_id, label, recipient, line1, line2, city, region, postal_code, country, phone, is_default, created_at, updated_at`
+
+// CreateAddress inserts a new address using raw SQL
+func (r *postgresAddressRepository) CreateAddress(ctx context.Context, address *models.Address) error {
+	sqlQuery := `INSERT INTO addresses (user_id, label, recipient, line1, line2, city, region, postal_code, country, phone, is_default, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		address.UserID,
+		address.Label,
+		address.Recipient,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		address.Phone,
+		address.IsDefault,
+		now,
+		now,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create address in DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", address.UserID))
+		return fmt.Errorf("failed to create address: %w", result.Error)
+	}
+
+	address.ID = newID
+	address.CreatedAt = now
+	address.UpdatedAt = now
+	logger.Info("Address created in DB successfully using raw SQL", zap.Uint("addressID", address.ID), zap.Uint("userID", address.UserID))
+	return nil
+}
+
+// GetAddressByID retrieves an address by its ID using raw SQL
+func (r *postgresAddressRepository) GetAddressByID(ctx context.Context, id uint) (*models.Address, error) {
+	address := &models.Address{}
+	sqlQuery := `SELECT ` + addressColumns + ` FROM addresses WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(address)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve address by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("addressID", id))
+		return nil, fmt.Errorf("database error retrieving address by ID: %w", result.Error)
+	}
+	if address.ID == 0 {
+		return nil, fmt.Errorf("address not found with ID %d", id)
+	}
+	return address, nil
+}
+
+// GetAddressesByUserID retrieves a user's addresses, default first, using raw SQL
+func (r *postgresAddressRepository) GetAddressesByUserID(ctx context.Context, userID uint) ([]*models.Address, error) {
+	var addresses []*models.Address
+	sqlQuery := `SELECT ` + addressColumns + ` FROM addresses WHERE user_id = ? AND deleted_at IS NULL ORDER BY is_default DESC, id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&addresses)
+	if result.Error != nil {
+		logger.Error("Failed to get addresses by user ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get addresses: %w", result.Error)
+	}
+	return addresses, nil
+}
+
+// CountAddressesByUserID counts a user's addresses using raw SQL
+func (r *postgresAddressRepository) CountAddressesByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	sqlQuery := `SELECT COUNT(*) FROM addresses WHERE user_id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&count)
+	if result.Error != nil {
+		logger.Error("Failed to count addresses using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return 0, fmt.Errorf("failed to count addresses: %w", result.Error)
+	}
+	return count, nil
+}
+
+// UpdateAddress replaces the fields of an address using raw SQL
+func (r *postgresAddressRepository) UpdateAddress(ctx context.Context, address *models.Address) error {
+	sqlQuery := `UPDATE addresses SET label = ?, recipient = ?, line1 = ?, line2 = ?, city = ?, region = ?, postal_code = ?, country = ?, phone = ?, is_default = ?, updated_at = ?
+		WHERE id = ? AND deleted_at IS NULL`
+
+	address.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Exec(sqlQuery,
+		address.Label,
+		address.Recipient,
+		address.Line1,
+		address.Line2,
+		address.City,
+		address.Region,
+		address.PostalCode,
+		address.Country,
+		address.Phone,
+		address.IsDefault,
+		address.UpdatedAt,
+		address.ID,
+	)
+	if result.Error != nil {
+		logger.Error("Failed to update address in DB using raw SQL", zap.Error(result.Error), zap.Uint("addressID", address.ID))
+		return fmt.Errorf("failed to update address: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("address with ID %d not found for update (raw SQL)", address.ID)
+	}
+	return nil
+}
+
+// DeleteAddress soft-deletes an address using raw SQL
+func (r *postgresAddressRepository) DeleteAddress(ctx context.Context, id uint) error {
+	sqlQuery := `UPDATE addresses SET deleted_at = ?, is_default = false WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), id)
+	if result.Error != nil {
+		logger.Error("Failed to delete address from DB using raw SQL", zap.Error(result.Error), zap.Uint("addressID", id))
+		return fmt.Errorf("failed to delete address: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("address with ID %d not found for deletion (raw SQL)", id)
+	}
+	return nil
+}
+
+// ClearDefaultAddress unsets the default flag on all of a user's addresses using raw SQL
+func (r *postgresAddressRepository) ClearDefaultAddress(ctx context.Context, userID uint) error {
+	sqlQuery := `UPDATE addresses SET is_default = false WHERE user_id = ? AND is_default AND deleted_at IS NULL`
+
+	if result := r.db.WithContext(ctx).Exec(sqlQuery, userID); result.Error != nil {
+		logger.Error("Failed to clear default address using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return fmt.Errorf("failed to clear default address: %w", result.Error)
+	}
+	return nil
+}
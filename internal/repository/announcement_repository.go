@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AnnouncementRepository defines the interface for announcement data operations
+type AnnouncementRepository interface {
+	CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error
+	GetActiveAnnouncements(ctx context.Context, audience string, now time.Time) ([]*models.Announcement, error)
+}
+
+// postgresAnnouncementRepository implements AnnouncementRepository using GORM with raw SQL
+type postgresAnnouncementRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAnnouncementRepository creates a new AnnouncementRepository instance
+func NewPostgresAnnouncementRepository(db *gorm.DB) AnnouncementRepository {
+	return &postgresAnnouncementRepository{db: db}
+}
+
+// CreateAnnouncement inserts a new announcement into the database using raw SQL
+func (r *postgresAnnouncementRepository) CreateAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	sqlQuery := `INSERT INTO announcements (title, body, audience, published_by, expires_at, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		announcement.Title,
+		announcement.Body,
+		announcement.Audience,
+		announcement.PublishedBy,
+		announcement.ExpiresAt,
+		now,
+		now,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create announcement in DB using raw SQL", zap.Error(result.Error), zap.String("title", announcement.Title))
+		return fmt.Errorf("failed to create announcement: %w", result.Error)
+	}
+
+	announcement.ID = newID
+	announcement.CreatedAt = now
+	announcement.UpdatedAt = now
+
+	logger.Info("Announcement created in DB successfully using raw SQL", zap.Uint("announcementID", announcement.ID), actor.Field(ctx))
+	return nil
+}
+
+// GetActiveAnnouncements retrieves unexpired announcements targeted at everyone or at the given audience, newest first
+func (r *postgresAnnouncementRepository) GetActiveAnnouncements(ctx context.Context, audience string, now time.Time) ([]*models.Announcement, error) {
+	var announcements []*models.Announcement
+	sqlQuery := `SELECT id, title, body, audience, published_by, expires_at, created_at, updated_at FROM announcements
+		WHERE deleted_at IS NULL AND (audience = '' OR audience = ?) AND (expires_at IS NULL OR expires_at > ?)
+		ORDER BY created_at DESC`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, audience, now).Scan(&announcements)
+	if result.Error != nil {
+		logger.Error("Failed to get active announcements from DB using raw SQL", zap.Error(result.Error), zap.String("audience", audience))
+		return nil, fmt.Errorf("failed to get announcements: %w", result.Error)
+	}
+	logger.Debug("Active announcements retrieved using raw SQL", zap.String("audience", audience), zap.Int("count", len(announcements)))
+	return announcements, nil
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// auditPIIKeys is models.AuditPIIKeys as an SQL array: the audit metadata keys that hold personal data of the user an event is about.
+// Only events about the user are scrubbed: in events the user performed, these keys name someone else.
+const auditPIIKeys = `ARRAY['username', 'email']`
+
+// AnonymizationRepository defines the cross-table operations used to anonymize a deleted user
+type AnonymizationRepository interface {
+	GetDeletedUserByID(ctx context.Context, id uint) (*models.User, error)
+	AnonymizeUser(ctx context.Context, user *models.User, loginKey string) (*models.AnonymizationReport, error)
+}
+
+// postgresAnonymizationRepository implements AnonymizationRepository using GORM with raw SQL
+type postgresAnonymizationRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAnonymizationRepository creates a new AnonymizationRepository instance
+func NewPostgresAnonymizationRepository(db *gorm.DB) AnonymizationRepository {
+	return &postgresAnonymizationRepository{db: db}
+}
+
+// GetDeletedUserByID retrieves a soft-deleted user by ID using raw SQL, nil if there is no such deleted user
+func (r *postgresAnonymizationRepository) GetDeletedUserByID(ctx context.Context, id uint) (*models.User, error) {
+	user := &models.User{}
+	sqlQuery := `SELECT id, username, email, role, created_at, updated_at, deleted_at, anonymized_at FROM users WHERE id = ? AND deleted_at IS NOT NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(user)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve deleted user by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", id))
+		return nil, fmt.Errorf("database error retrieving deleted user by ID: %w", result.Error)
+	}
+	if user.ID == 0 {
+		return nil, nil
+	}
+	return user, nil
+}
+
+// AnonymizeUser scrubs the personal data of a deleted user from every table in one transaction using raw SQL.
+// IDs are kept, so comments, reports, audit events and products still reference a (now anonymous) user.
+// loginKey is the throttling key derived from the user's email.
+func (r *postgresAnonymizationRepository) AnonymizeUser(ctx context.Context, user *models.User, loginKey string) (*models.AnonymizationReport, error) {
+	now := time.Now()
+	report := &models.AnonymizationReport{UserID: user.ID, AnonymizedAt: now}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`UPDATE users SET username = ?, email = ?, password = ?, anonymized_at = ?, updated_at = ?
+			WHERE id = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL`,
+			models.AnonymizedUsername(user.ID), models.AnonymizedEmail(user.ID), models.PasswordAnonymized, now, now, user.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("user %d is not a deleted, unanonymized user", user.ID)
+		}
+
+		steps := []struct {
+			count *int64
+			query string
+			args  []interface{}
+		}{
+			{&report.AuditEvents, `UPDATE audit_events SET metadata = metadata - ` + auditPIIKeys + `
+				WHERE resource_type = 'user' AND resource_id = ? AND metadata <> metadata - ` + auditPIIKeys,
+				[]interface{}{user.ID}},
+			{&report.Comments, `UPDATE comments SET body = ?, updated_at = ? WHERE user_id = ? AND body <> ?`,
+				[]interface{}{models.AnonymizedText, now, user.ID, models.AnonymizedText}},
+			{&report.Reports, `UPDATE reports SET details = '', updated_at = ? WHERE reporter_id = ? AND details <> ''`,
+				[]interface{}{now, user.ID}},
+			{&report.Addresses, `DELETE FROM addresses WHERE user_id = ?`, []interface{}{user.ID}},
+			{&report.SavedSearches, `DELETE FROM saved_searches WHERE user_id = ?`, []interface{}{user.ID}},
+			{&report.ActivityEntries, `DELETE FROM activity_entries WHERE user_id = ?`, []interface{}{user.ID}},
+			{&report.Tokens, `UPDATE personal_access_tokens SET name = ?, revoked_at = COALESCE(revoked_at, ?), updated_at = ? WHERE user_id = ? AND name <> ?`,
+				[]interface{}{models.AnonymizedText, now, now, user.ID, models.AnonymizedText}},
+			{&report.LoginAttempts, `DELETE FROM login_attempts WHERE key = ?`, []interface{}{loginKey}},
+		}
+		for _, step := range steps {
+			result := tx.Exec(step.query, step.args...)
+			if result.Error != nil {
+				return result.Error
+			}
+			*step.count = result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to anonymize user using raw SQL", zap.Error(err), zap.Uint("userID", user.ID))
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+
+	logger.Info("User anonymized using raw SQL", zap.Uint("userID", user.ID), zap.Int64("auditEvents", report.AuditEvents), zap.Int64("comments", report.Comments), actor.Field(ctx))
+	return report, nil
+}
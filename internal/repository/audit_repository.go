@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// AuditRepository defines the interface for audit event data operations
+type AuditRepository interface {
+	CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error
+	GetAuditEventsAfter(ctx context.Context, afterID uint, createdBefore time.Time, limit int) ([]*models.AuditEvent, error)
+	GetForwardCursor(ctx context.Context, name string) (uint, error)
+	// MoveForwardCursor moves the cursor of a sink from one event ID to another, reporting false if it was no longer
+	// at from, so that concurrent moves can't overwrite each other
+	MoveForwardCursor(ctx context.Context, name string, from, to uint) (bool, error)
+	CountAuditEventsAfter(ctx context.Context, afterID uint) (int64, error)
+	GetLastAuditEventIDBefore(ctx context.Context, before time.Time) (uint, error)
+	GetAuditEventsBetween(ctx context.Context, from, to time.Time, afterID uint, limit int) ([]*models.AuditEvent, error)
+	GetAuditEvents(ctx context.Context, query *models.AuditEventQuery, offset, limit int) ([]*models.AuditEvent, int64, error)
+}
+
+// postgresAuditRepository implements AuditRepository using GORM with raw SQL
+type postgresAuditRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresAuditRepository creates a new AuditRepository instance
+func NewPostgresAuditRepository(db *gorm.DB) AuditRepository {
+	return &postgresAuditRepository{db: db}
+}
+
+// CreateAuditEvent appends an audit event using raw SQL
+func (r *postgresAuditRepository) CreateAuditEvent(ctx context.Context, event *models.AuditEvent) error {
+	sqlQuery := `INSERT INTO audit_events (action, resource_type, resource_id, acting_user_id, effective_user_id, metadata, created_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		event.Action,
+		event.ResourceType,
+		event.ResourceID,
+		event.ActingUserID,
+		event.EffectiveUserID,
+		event.Metadata,
+		event.CreatedAt,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create audit event in DB using raw SQL", zap.Error(result.Error), zap.String("action", event.Action))
+		return fmt.Errorf("failed to create audit event: %w", result.Error)
+	}
+
+	event.ID = newID
+	return nil
+}
+
+// GetAuditEventsAfter retrieves up to limit audit events with an ID greater than afterID, in ID order, using raw SQL.
+// Only events created before createdBefore are returned, so in-flight inserts that were assigned a lower ID
+// get a chance to commit before the caller moves past them.
+func (r *postgresAuditRepository) GetAuditEventsAfter(ctx context.Context, afterID uint, createdBefore time.Time, limit int) ([]*models.AuditEvent, error) {
+	var events []*models.AuditEvent
+	sqlQuery := `SELECT id, action, resource_type, resource_id, acting_user_id, effective_user_id, metadata, created_at FROM audit_events WHERE id > ? AND created_at < ? ORDER BY id LIMIT ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, afterID, createdBefore, limit).Scan(&events)
+	if result.Error != nil {
+		logger.Error("Failed to get audit events from DB using raw SQL", zap.Error(result.Error), zap.Uint("afterID", afterID))
+		return nil, fmt.Errorf("failed to get audit events: %w", result.Error)
+	}
+	return events, nil
+}
+
+// GetForwardCursor retrieves the last forwarded event ID for a sink using raw SQL, 0 if it never forwarded anything
+func (r *postgresAuditRepository) GetForwardCursor(ctx context.Context, name string) (uint, error) {
+	var lastEventID uint
+	sqlQuery := `SELECT last_event_id FROM audit_forward_cursors WHERE name = ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, name).Scan(&lastEventID)
+	if result.Error != nil {
+		logger.Error("Failed to get audit forward cursor using raw SQL", zap.Error(result.Error), zap.String("sink", name))
+		return 0, fmt.Errorf("failed to get audit forward cursor: %w", result.Error)
+	}
+	return lastEventID, nil
+}
+
+// MoveForwardCursor stores the last forwarded event ID for a sink if it still is from, using raw SQL. A sink without
+// a cursor row is at 0, and gets one.
+func (r *postgresAuditRepository) MoveForwardCursor(ctx context.Context, name string, from, to uint) (bool, error) {
+	sqlQuery := `INSERT INTO audit_forward_cursors (name, last_event_id, updated_at) VALUES (?, ?, ?)
+		ON CONFLICT (name) DO UPDATE SET last_event_id = EXCLUDED.last_event_id, updated_at = EXCLUDED.updated_at
+		WHERE audit_forward_cursors.last_event_id = ?`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, name, to, time.Now(), from)
+	if result.Error != nil {
+		logger.Error("Failed to move audit forward cursor using raw SQL", zap.Error(result.Error), zap.String("sink", name), zap.Uint("lastEventID", to))
+		return false, fmt.Errorf("failed to move audit forward cursor: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetAuditEventsBetween retrieves up to limit audit events created from from (inclusive) to to (exclusive) with an
+// ID greater than afterID, in ID order, using raw SQL. Callers page through a window by passing the last ID seen.
+func (r *postgresAuditRepository) GetAuditEventsBetween(ctx context.Context, from, to time.Time, afterID uint, limit int) ([]*models.AuditEvent, error) {
+	var events []*models.AuditEvent
+	sqlQuery := `SELECT id, action, resource_type, resource_id, acting_user_id, effective_user_id, metadata, created_at FROM audit_events
+		WHERE created_at >= ? AND created_at < ? AND id > ? ORDER BY id LIMIT ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, from, to, afterID, limit).Scan(&events)
+	if result.Error != nil {
+		logger.Error("Failed to get audit events between times from DB using raw SQL", zap.Error(result.Error), zap.Time("from", from), zap.Time("to", to))
+		return nil, fmt.Errorf("failed to get audit events: %w", result.Error)
+	}
+	return events, nil
+}
+
+// CountAuditEventsAfter counts the audit events with an ID greater than afterID using raw SQL
+func (r *postgresAuditRepository) CountAuditEventsAfter(ctx context.Context, afterID uint) (int64, error) {
+	var count int64
+	sqlQuery := `SELECT COUNT(*) FROM audit_events WHERE id > ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, afterID).Scan(&count)
+	if result.Error != nil {
+		logger.Error("Failed to count audit events using raw SQL", zap.Error(result.Error), zap.Uint("afterID", afterID))
+		return 0, fmt.Errorf("failed to count audit events: %w", result.Error)
+	}
+	return count, nil
+}
+
+// GetLastAuditEventIDBefore returns the highest ID of the audit events created before before, or 0 if there is
+// none, using raw SQL
+func (r *postgresAuditRepository) GetLastAuditEventIDBefore(ctx context.Context, before time.Time) (uint, error) {
+	var lastID uint
+	sqlQuery := `SELECT COALESCE(MAX(id), 0) FROM audit_events WHERE created_at < ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, before).Scan(&lastID)
+	if result.Error != nil {
+		logger.Error("Failed to get last audit event ID using raw SQL", zap.Error(result.Error), zap.Time("before", before))
+		return 0, fmt.Errorf("failed to get last audit event ID: %w", result.Error)
+	}
+	return lastID, nil
+}
+
+// GetAuditEvents retrieves a page of audit events matching query, newest first, and the total count, using raw SQL
+func (r *postgresAuditRepository) GetAuditEvents(ctx context.Context, query *models.AuditEventQuery, offset, limit int) ([]*models.AuditEvent, int64, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	if query.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, query.Action)
+	}
+	if query.ResourceType != "" {
+		conditions = append(conditions, "resource_type = ?")
+		args = append(args, query.ResourceType)
+	}
+	if query.ResourceID != nil {
+		conditions = append(conditions, "resource_id = ?")
+		args = append(args, *query.ResourceID)
+	}
+	if query.ActingUserID != nil {
+		conditions = append(conditions, "acting_user_id = ?")
+		args = append(args, *query.ActingUserID)
+	}
+	if query.Setting != "" {
+		conditions = append(conditions, "action = ? AND metadata->>'setting' = ?")
+		args = append(args, models.AuditSettingChanged, query.Setting)
+	}
+	if query.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *query.From)
+	}
+	if query.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *query.To)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	if result := r.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM audit_events WHERE `+where, args...).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count audit events using raw SQL", zap.Error(result.Error))
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", result.Error)
+	}
+
+	var events []*models.AuditEvent
+	sqlQuery := `SELECT id, action, resource_type, resource_id, acting_user_id, effective_user_id, metadata, created_at
+		FROM audit_events WHERE ` + where + ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, append(args, limit, offset)...).Scan(&events); result.Error != nil {
+		logger.Error("Failed to get audit events from DB using raw SQL", zap.Error(result.Error))
+		return nil, 0, fmt.Errorf("failed to get audit events: %w", result.Error)
+	}
+	return events, total, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BundleRepository defines the interface for bundle data operations
+type BundleRepository interface {
+	CreateBundle(ctx context.Context, bundle *models.Bundle) error
+	GetBundleByID(ctx context.Context, id uint) (*models.Bundle, error)
+	GetBundlesByUserID(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Bundle, error)
+	GetBundleComponents(ctx context.Context, bundleID uint) ([]*models.BundleComponent, error)
+	DeleteBundle(ctx context.Context, id uint) error
+}
+
+// postgresBundleRepository implements BundleRepository using GORM with raw SQL
+type postgresBundleRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresBundleRepository creates a new BundleRepository instance
+func NewPostgresBundleRepository(db *gorm.DB) BundleRepository {
+	return &postgresBundleRepository{db: db}
+}
+
+// CreateBundle inserts a bundle and its items in one transaction using raw SQL
+func (r *postgresBundleRepository) CreateBundle(ctx context.Context, bundle *models.Bundle) error {
+	now := time.Now()
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var newID uint
+		sqlQuery := `INSERT INTO bundles (user_id, name, description, price, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING id`
+		if err := tx.Raw(sqlQuery, bundle.UserID, bundle.Name, bundle.Description, bundle.Price, now, now).Scan(&newID).Error; err != nil {
+			return err
+		}
+
+		for _, item := range bundle.Items {
+			item.BundleID = newID
+			if err := tx.Exec(`INSERT INTO bundle_items (bundle_id, product_id, quantity) VALUES (?, ?, ?)`, item.BundleID, item.ProductID, item.Quantity).Error; err != nil {
+				return err
+			}
+		}
+		bundle.ID = newID
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to create bundle in DB using raw SQL", zap.Error(err), zap.String("bundleName", bundle.Name))
+		return fmt.Errorf("failed to create bundle: %w", err)
+	}
+
+	bundle.CreatedAt = now
+	bundle.UpdatedAt = now
+	logger.Info("Bundle created in DB successfully using raw SQL", zap.Uint("bundleID", bundle.ID), zap.Int("items", len(bundle.Items)), actor.Field(ctx))
+	return nil
+}
+
+// GetBundleByID retrieves a bundle (without its items) by its ID using raw SQL
+func (r *postgresBundleRepository) GetBundleByID(ctx context.Context, id uint) (*models.Bundle, error) {
+	bundle := &models.Bundle{}
+	sqlQuery := `SELECT id, user_id, name, description, price, created_at, updated_at FROM bundles WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(bundle)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve bundle by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("bundleID", id))
+		return nil, fmt.Errorf("database error retrieving bundle by ID: %w", result.Error)
+	}
+	if bundle.ID == 0 {
+		return nil, fmt.Errorf("bundle not found with ID %d", id)
+	}
+	return bundle, nil
+}
+
+// GetBundlesByUserID retrieves all bundles of a seller within timeRange using raw SQL
+func (r *postgresBundleRepository) GetBundlesByUserID(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Bundle, error) {
+	var bundles []*models.Bundle
+	conditions, args := timeRangeConditions(timeRange)
+	conditions = append([]string{"user_id = ?", "deleted_at IS NULL"}, conditions...)
+	sqlQuery := `SELECT id, user_id, name, description, price, created_at, updated_at FROM bundles WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, append([]interface{}{userID}, args...)...).Scan(&bundles)
+	if result.Error != nil {
+		logger.Error("Failed to get bundles by user ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get bundles: %w", result.Error)
+	}
+	return bundles, nil
+}
+
+// GetBundleComponents retrieves a bundle's items joined with their products using raw SQL.
+// Items whose product has been deleted are returned with Available set to false.
+func (r *postgresBundleRepository) GetBundleComponents(ctx context.Context, bundleID uint) ([]*models.BundleComponent, error) {
+	var components []*models.BundleComponent
+	sqlQuery := `SELECT bi.product_id, bi.quantity,
+		COALESCE(p.name, '') AS product_name, COALESCE(p.price, 0) AS product_price, p.id IS NOT NULL AS available
+		FROM bundle_items bi LEFT JOIN products p ON p.id = bi.product_id AND p.deleted_at IS NULL
+		WHERE bi.bundle_id = ? ORDER BY bi.product_id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, bundleID).Scan(&components)
+	if result.Error != nil {
+		logger.Error("Failed to get bundle components from DB using raw SQL", zap.Error(result.Error), zap.Uint("bundleID", bundleID))
+		return nil, fmt.Errorf("failed to get bundle components: %w", result.Error)
+	}
+	return components, nil
+}
+
+// DeleteBundle soft-deletes a bundle using raw SQL. Its items are kept for the record.
+func (r *postgresBundleRepository) DeleteBundle(ctx context.Context, id uint) error {
+	sqlQuery := `UPDATE bundles SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), id)
+	if result.Error != nil {
+		logger.Error("Failed to delete bundle from DB using raw SQL", zap.Error(result.Error), zap.Uint("bundleID", id))
+		return fmt.Errorf("failed to delete bundle: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("bundle with ID %d not found for deletion (raw SQL)", id)
+	}
+	logger.Info("Bundle deleted from DB successfully using raw SQL", zap.Uint("bundleID", id), actor.Field(ctx))
+	return nil
+}
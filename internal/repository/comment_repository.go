@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// CommentRepository defines the interface for comment data operations
+type CommentRepository interface {
+	CreateComment(ctx context.Context, comment *models.Comment) error
+	GetCommentByID(ctx context.Context, id uint) (*models.Comment, error)
+	GetCommentsByProductID(ctx context.Context, productID uint, viewerID uint, includeHidden bool, timeRange models.TimeRange, limit, offset int) ([]*models.Comment, int64, error)
+	SetCommentHidden(ctx context.Context, id uint, hidden bool) error
+	DeleteComment(ctx context.Context, id uint) error
+}
+
+// postgresCommentRepository implements CommentRepository using GORM with raw SQL
+type postgresCommentRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresCommentRepository creates a new CommentRepository instance
+func NewPostgresCommentRepository(db *gorm.DB) CommentRepository {
+	return &postgresCommentRepository{db: db}
+}
+
+// CreateComment inserts a new comment into the database using raw SQL
+func (r *postgresCommentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	sqlQuery := `INSERT INTO comments (product_id, user_id, parent_id, body, hidden, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		comment.ProductID,
+		comment.UserID,
+		comment.ParentID,
+		comment.Body,
+		comment.Hidden,
+		now,
+		now,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create comment in DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", comment.ProductID))
+		return fmt.Errorf("failed to create comment: %w", result.Error)
+	}
+
+	comment.ID = newID
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	logger.Info("Comment created in DB successfully using raw SQL", zap.Uint("commentID", comment.ID), zap.Uint("productID", comment.ProductID), actor.Field(ctx))
+	return nil
+}
+
+// GetCommentByID retrieves a comment by its ID using raw SQL
+func (r *postgresCommentRepository) GetCommentByID(ctx context.Context, id uint) (*models.Comment, error) {
+	comment := &models.Comment{}
+	sqlQuery := `SELECT id, product_id, user_id, parent_id, body, hidden, created_at, updated_at FROM comments WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(comment)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve comment by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("commentID", id))
+		return nil, fmt.Errorf("database error retrieving comment by ID: %w", result.Error)
+	}
+	if comment.ID == 0 {
+		logger.Warn("Comment not found by ID using raw SQL", zap.Uint("commentID", id))
+		return nil, fmt.Errorf("comment not found with ID %d", id)
+	}
+	return comment, nil
+}
+
+// GetCommentsByProductID retrieves a page of a product's comments in thread order using raw SQL.
+// Hidden comments are only returned when includeHidden is set or when viewerID wrote them.
+func (r *postgresCommentRepository) GetCommentsByProductID(ctx context.Context, productID uint, viewerID uint, includeHidden bool, timeRange models.TimeRange, limit, offset int) ([]*models.Comment, int64, error) {
+	conditions, rangeArgs := timeRangeConditions(timeRange)
+	filter := strings.Join(append([]string{`product_id = ? AND deleted_at IS NULL AND (hidden = false OR ? OR user_id = ?)`}, conditions...), " AND ")
+	args := append([]interface{}{productID, includeHidden, viewerID}, rangeArgs...)
+
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM comments WHERE ` + filter
+	if result := r.db.WithContext(ctx).Raw(countQuery, args...).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count comments by product ID using raw SQL", zap.Error(result.Error), zap.Uint("productID", productID))
+		return nil, 0, fmt.Errorf("failed to count comments: %w", result.Error)
+	}
+
+	// Order replies right after their root comment so each page reads as a thread
+	var comments []*models.Comment
+	sqlQuery := `SELECT id, product_id, user_id, parent_id, body, hidden, created_at, updated_at FROM comments WHERE ` + filter + `
+		ORDER BY COALESCE(parent_id, id), parent_id IS NOT NULL, created_at, id
+		LIMIT ? OFFSET ?`
+	result := r.db.WithContext(ctx).Raw(sqlQuery, append(args, limit, offset)...).Scan(&comments)
+	if result.Error != nil {
+		logger.Error("Failed to get comments by product ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", productID))
+		return nil, 0, fmt.Errorf("failed to get comments: %w", result.Error)
+	}
+	logger.Debug("Comments retrieved by product ID using raw SQL", zap.Uint("productID", productID), zap.Int("count", len(comments)))
+	return comments, total, nil
+}
+
+// SetCommentHidden hides or unhides a comment using raw SQL
+func (r *postgresCommentRepository) SetCommentHidden(ctx context.Context, id uint, hidden bool) error {
+	sqlQuery := `UPDATE comments SET hidden = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, hidden, time.Now(), id)
+	if result.Error != nil {
+		logger.Error("Failed to update comment visibility in DB using raw SQL", zap.Error(result.Error), zap.Uint("commentID", id))
+		return fmt.Errorf("failed to update comment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("comment with ID %d not found for update (raw SQL)", id)
+	}
+	logger.Info("Comment visibility updated in DB using raw SQL", zap.Uint("commentID", id), zap.Bool("hidden", hidden), actor.Field(ctx))
+	return nil
+}
+
+// DeleteComment soft-deletes a comment together with its replies using raw SQL
+func (r *postgresCommentRepository) DeleteComment(ctx context.Context, id uint) error {
+	sqlQuery := `UPDATE comments SET deleted_at = ? WHERE (id = ? OR parent_id = ?) AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), id, id)
+	if result.Error != nil {
+		logger.Error("Failed to delete comment from DB using raw SQL", zap.Error(result.Error), zap.Uint("commentID", id))
+		return fmt.Errorf("failed to delete comment: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("comment with ID %d not found for deletion (raw SQL)", id)
+	}
+	logger.Info("Comment deleted from DB successfully using raw SQL", zap.Uint("commentID", id), zap.Int64("rowsAffected", result.RowsAffected), actor.Field(ctx))
+	return nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// DeprecationRepository defines the interface for deprecation telemetry data operations
+type DeprecationRepository interface {
+	AddDeprecationUsages(ctx context.Context, usages []*models.DeprecationUsage) error
+	GetDeprecationUsageSince(ctx context.Context, since time.Time) ([]*models.DeprecationUsageTotal, error)
+}
+
+// postgresDeprecationRepository implements DeprecationRepository using GORM with raw SQL
+type postgresDeprecationRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresDeprecationRepository creates a new DeprecationRepository instance
+func NewPostgresDeprecationRepository(db *gorm.DB) DeprecationRepository {
+	return &postgresDeprecationRepository{db: db}
+}
+
+// AddDeprecationUsages adds counts to the daily usage rows, creating the missing ones, using raw SQL in one transaction
+func (r *postgresDeprecationRepository) AddDeprecationUsages(ctx context.Context, usages []*models.DeprecationUsage) error {
+	sqlQuery := `INSERT INTO deprecation_usages (feature, user_id, via, user_agent, day, count, last_seen_at) VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (feature, user_id, via, user_agent, day)
+		DO UPDATE SET count = deprecation_usages.count + EXCLUDED.count, last_seen_at = GREATEST(deprecation_usages.last_seen_at, EXCLUDED.last_seen_at)`
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, u := range usages {
+			if err := tx.Exec(sqlQuery, u.Feature, u.UserID, u.Via, u.UserAgent, u.Day, u.Count, u.LastSeenAt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to add deprecation usages using raw SQL", zap.Error(err), zap.Int("rows", len(usages)))
+		return fmt.Errorf("failed to add deprecation usages: %w", err)
+	}
+	return nil
+}
+
+// GetDeprecationUsageSince sums the usage of every deprecated feature per client from since's day on, using raw SQL
+func (r *postgresDeprecationRepository) GetDeprecationUsageSince(ctx context.Context, since time.Time) ([]*models.DeprecationUsageTotal, error) {
+	var rows []*models.DeprecationUsageTotal
+	sqlQuery := `SELECT feature, via, user_agent, COUNT(DISTINCT user_id) AS users, SUM(count) AS count, MAX(last_seen_at) AS last_seen_at
+		FROM deprecation_usages WHERE day >= ?::date
+		GROUP BY feature, via, user_agent ORDER BY feature, count DESC`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, since).Scan(&rows)
+	if result.Error != nil {
+		logger.Error("Failed to get deprecation usage from DB using raw SQL", zap.Error(result.Error), zap.Time("since", since))
+		return nil, fmt.Errorf("failed to get deprecation usage: %w", result.Error)
+	}
+	return rows, nil
+}
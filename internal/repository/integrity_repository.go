@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// IntegrityRepository defines the cross-table queries used by the integrity doctor
+type IntegrityRepository interface {
+	FindOrphanedProducts(ctx context.Context) ([]*models.Product, error)
+	FindOrphanedComments(ctx context.Context) ([]*models.Comment, error)
+	FindDanglingBundleItems(ctx context.Context) ([]*models.BundleItem, error)
+	GetUsersAfter(ctx context.Context, afterID uint, limit int) ([]*models.User, error)
+	SoftDeleteProducts(ctx context.Context, ids []uint) (int64, error)
+	SoftDeleteComments(ctx context.Context, ids []uint) (int64, error)
+}
+
+// postgresIntegrityRepository implements IntegrityRepository using GORM with raw SQL
+type postgresIntegrityRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresIntegrityRepository creates a new IntegrityRepository instance
+func NewPostgresIntegrityRepository(db *gorm.DB) IntegrityRepository {
+	return &postgresIntegrityRepository{db: db}
+}
+
+// FindOrphanedProducts retrieves live products whose owner is missing or deleted using raw SQL
+func (r *postgresIntegrityRepository) FindOrphanedProducts(ctx context.Context) ([]*models.Product, error) {
+	var products []*models.Product
+	sqlQuery := `SELECT p.id, p.name, p.user_id FROM products p
+		LEFT JOIN users u ON u.id = p.user_id AND u.deleted_at IS NULL
+		WHERE p.deleted_at IS NULL AND u.id IS NULL ORDER BY p.id`
+
+	if result := r.db.WithContext(ctx).Raw(sqlQuery).Scan(&products); result.Error != nil {
+		logger.Error("Failed to find orphaned products using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to find orphaned products: %w", result.Error)
+	}
+	return products, nil
+}
+
+// FindOrphanedComments retrieves live comments whose product is missing or deleted using raw SQL
+func (r *postgresIntegrityRepository) FindOrphanedComments(ctx context.Context) ([]*models.Comment, error) {
+	var comments []*models.Comment
+	sqlQuery := `SELECT c.id, c.product_id, c.user_id FROM comments c
+		LEFT JOIN products p ON p.id = c.product_id AND p.deleted_at IS NULL
+		WHERE c.deleted_at IS NULL AND p.id IS NULL ORDER BY c.id`
+
+	if result := r.db.WithContext(ctx).Raw(sqlQuery).Scan(&comments); result.Error != nil {
+		logger.Error("Failed to find orphaned comments using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to find orphaned comments: %w", result.Error)
+	}
+	return comments, nil
+}
+
+// FindDanglingBundleItems retrieves items of live bundles whose product is missing or deleted using raw SQL
+func (r *postgresIntegrityRepository) FindDanglingBundleItems(ctx context.Context) ([]*models.BundleItem, error) {
+	var items []*models.BundleItem
+	sqlQuery := `SELECT bi.bundle_id, bi.product_id, bi.quantity FROM bundle_items bi
+		JOIN bundles b ON b.id = bi.bundle_id AND b.deleted_at IS NULL
+		LEFT JOIN products p ON p.id = bi.product_id AND p.deleted_at IS NULL
+		WHERE p.id IS NULL ORDER BY bi.bundle_id, bi.product_id`
+
+	if result := r.db.WithContext(ctx).Raw(sqlQuery).Scan(&items); result.Error != nil {
+		logger.Error("Failed to find dangling bundle items using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to find dangling bundle items: %w", result.Error)
+	}
+	return items, nil
+}
+
+// GetUsersAfter retrieves up to limit live users with an ID greater than afterID, in ID order, using raw SQL
+func (r *postgresIntegrityRepository) GetUsersAfter(ctx context.Context, afterID uint, limit int) ([]*models.User, error) {
+	var users []*models.User
+	sqlQuery := `SELECT id, username, email FROM users WHERE id > ? AND deleted_at IS NULL ORDER BY id LIMIT ?`
+
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, afterID, limit).Scan(&users); result.Error != nil {
+		logger.Error("Failed to scan users using raw SQL", zap.Error(result.Error), zap.Uint("afterID", afterID))
+		return nil, fmt.Errorf("failed to get users: %w", result.Error)
+	}
+	return users, nil
+}
+
+// SoftDeleteProducts soft-deletes the given products using raw SQL
+func (r *postgresIntegrityRepository) SoftDeleteProducts(ctx context.Context, ids []uint) (int64, error) {
+	return r.softDelete(ctx, "products", ids)
+}
+
+// SoftDeleteComments soft-deletes the given comments using raw SQL
+func (r *postgresIntegrityRepository) SoftDeleteComments(ctx context.Context, ids []uint) (int64, error) {
+	return r.softDelete(ctx, "comments", ids)
+}
+
+// softDelete sets deleted_at on the given rows of a table. table is never user input.
+func (r *postgresIntegrityRepository) softDelete(ctx context.Context, table string, ids []uint) (int64, error) {
+	if len(ids) == 0 {
+		return 0, nil
+	}
+	sqlQuery := `UPDATE ` + table + ` SET deleted_at = ? WHERE id IN ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), ids)
+	if result.Error != nil {
+		logger.Error("Failed to soft-delete rows using raw SQL", zap.Error(result.Error), zap.String("table", table), zap.Int("count", len(ids)))
+		return 0, fmt.Errorf("failed to delete %s: %w", table, result.Error)
+	}
+	return result.RowsAffected, nil
+}
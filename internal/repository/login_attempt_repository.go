@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepository defines the interface for failed login bookkeeping
+type LoginAttemptRepository interface {
+	GetLoginAttempt(ctx context.Context, key string) (*models.LoginAttempt, error)
+	RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	ResetLoginAttempts(ctx context.Context, key string) error
+}
+
+// postgresLoginAttemptRepository implements LoginAttemptRepository using GORM with raw SQL
+type postgresLoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresLoginAttemptRepository creates a new LoginAttemptRepository instance
+func NewPostgresLoginAttemptRepository(db *gorm.DB) LoginAttemptRepository {
+	return &postgresLoginAttemptRepository{db: db}
+}
+
+// GetLoginAttempt retrieves the failure record of a credential using raw SQL, or nil if there is none
+func (r *postgresLoginAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{}
+	sqlQuery := `SELECT key, failures, last_failed_at FROM login_attempts WHERE key = ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, key).Scan(attempt)
+	if result.Error != nil {
+		logger.Error("Failed to get login attempts using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to get login attempts: %w", result.Error)
+	}
+	if attempt.Key == "" {
+		return nil, nil
+	}
+	return attempt, nil
+}
+
+// RecordLoginFailure atomically counts a failed login using raw SQL. Failures older than window
+// no longer count, so the counter restarts at one after a quiet period.
+func (r *postgresLoginAttemptRepository) RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	attempt := &models.LoginAttempt{}
+	sqlQuery := `INSERT INTO login_attempts (key, failures, last_failed_at) VALUES (?, 1, ?)
+		ON CONFLICT (key) DO UPDATE SET
+			failures = CASE WHEN login_attempts.last_failed_at < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failed_at = EXCLUDED.last_failed_at
+		RETURNING key, failures, last_failed_at`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, key, now, now.Add(-window)).Scan(attempt)
+	if result.Error != nil {
+		logger.Error("Failed to record login failure using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to record login failure: %w", result.Error)
+	}
+	return attempt, nil
+}
+
+// ResetLoginAttempts clears the failure record of a credential using raw SQL
+func (r *postgresLoginAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	sqlQuery := `DELETE FROM login_attempts WHERE key = ?`
+
+	if result := r.db.WithContext(ctx).Exec(sqlQuery, key); result.Error != nil {
+		logger.Error("Failed to reset login attempts using raw SQL", zap.Error(result.Error))
+		return fmt.Errorf("failed to reset login attempts: %w", result.Error)
+	}
+	return nil
+}
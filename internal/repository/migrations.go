@@ -0,0 +1,212 @@
+package repository
+
+import "gotemplate/pkg/database"
+
+// Versioned schema changes per module, for the indexes and constraints the queries in this package rely on.
+// AutoMigrate only creates indexes declared in model tags, can't express partial or expression indexes and
+// never replaces a changed one, so they are managed here. Indexes are built CONCURRENTLY to keep tables
+// writable while a deploy migrates. The previous release still serves traffic during a rolling deploy, so
+// Migrate refuses statements that would break it (see database.LintMigrations): drop a column only one
+// release after the code stopped using it.
+
+// UserMigrations are the schema changes of the users module
+var UserMigrations = []database.Migration{
+	{
+		Version: 2026101601,
+		Name:    "users: unique indexes on email and username",
+		Statements: []string{
+			`CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_email ON users (email)`,
+			`CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_username ON users (username)`,
+			// The indexes replace the unique constraints earlier versions declared in the model
+			`ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_email`,
+			`ALTER TABLE users DROP CONSTRAINT IF EXISTS uni_users_username`,
+		},
+		NoTransaction: true,
+	},
+	{
+		Version: 2026101603,
+		Name:    "users: email and username unique among live accounts only",
+		Statements: []string{
+			// A soft-deleted account keeps its row, but its email and username may be registered again
+			`CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_email_live ON users (email) WHERE deleted_at IS NULL`,
+			`CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_users_username_live ON users (username) WHERE deleted_at IS NULL`,
+			`DROP INDEX CONCURRENTLY IF EXISTS idx_users_email`,
+			`DROP INDEX CONCURRENTLY IF EXISTS idx_users_username`,
+		},
+		NoTransaction: true,
+	},
+	{
+		Version: 2026101604,
+		Name:    "users: lower-case emails",
+		Statements: []string{
+			// Emails are lower-cased when requests are bound; existing accounts follow so lookups find them.
+			// An account whose lower-cased email belongs to another live account is left for an administrator to resolve.
+			`UPDATE users u SET email = lower(u.email)
+			WHERE u.email <> lower(u.email)
+			AND (u.deleted_at IS NOT NULL OR NOT EXISTS (
+				SELECT 1 FROM users o WHERE o.id <> u.id AND o.deleted_at IS NULL AND lower(o.email) = lower(u.email)
+			))`,
+		},
+	},
+}
+
+// ProductMigrations are the schema changes of the products module
+var ProductMigrations = []database.Migration{
+	{
+		Version: 2026101602,
+		Name:    "products: owner and full-text search indexes",
+		Statements: []string{
+			// Listings, ownership checks and bulk updates filter live products by owner
+			`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_products_user_id_live ON products (user_id) WHERE deleted_at IS NULL`,
+			// Full-text search over name and description
+			`CREATE INDEX CONCURRENTLY IF NOT EXISTS idx_products_search ON products USING GIN (to_tsvector('simple', name || ' ' || coalesce(description, '')))`,
+		},
+		NoTransaction: true,
+	},
+	{
+		Version: 2026101605,
+		Name:    "products: change feed trigger",
+		Statements: []string{
+			// Every write to products lands in product_changes, whichever code path made it. A product moved to
+			// another owner is also recorded for the previous owner, whose feed reports it as deleted.
+			`CREATE OR REPLACE FUNCTION record_product_change() RETURNS trigger AS $$
+			BEGIN
+				IF TG_OP = 'DELETE' OR (TG_OP = 'UPDATE' AND OLD.user_id <> NEW.user_id) THEN
+					INSERT INTO product_changes (user_id, product_id, changed_at) VALUES (OLD.user_id, OLD.id, clock_timestamp());
+				END IF;
+				IF TG_OP <> 'DELETE' THEN
+					INSERT INTO product_changes (user_id, product_id, changed_at) VALUES (NEW.user_id, NEW.id, clock_timestamp());
+				END IF;
+				RETURN NULL;
+			END
+			$$ LANGUAGE plpgsql`,
+			`DROP TRIGGER IF EXISTS products_record_change ON products`,
+			`CREATE TRIGGER products_record_change AFTER INSERT OR UPDATE OR DELETE ON products
+			FOR EACH ROW EXECUTE FUNCTION record_product_change()`,
+		},
+	},
+	{
+		Version: 2026101606,
+		Name:    "products: client IDs unique per owner",
+		Statements: []string{
+			// A replayed offline create finds the product it made instead of creating a duplicate
+			`CREATE UNIQUE INDEX CONCURRENTLY IF NOT EXISTS idx_products_user_client_id ON products (user_id, client_id) WHERE client_id IS NOT NULL AND deleted_at IS NULL`,
+		},
+		NoTransaction: true,
+	},
+	{
+		Version: 2026101607,
+		Name:    "product_permissions: removed with their product",
+		Statements: []string{
+			// Products are deleted outright, so their grants must go with them
+			`ALTER TABLE product_permissions DROP CONSTRAINT IF EXISTS fk_product_permissions_product`,
+			`ALTER TABLE product_permissions ADD CONSTRAINT fk_product_permissions_product
+			FOREIGN KEY (product_id) REFERENCES products (id) ON DELETE CASCADE`,
+		},
+	},
+}
+
+// StatsMigrations are the schema changes of the stats module. Its aggregates are materialized views, refreshed
+// on schedule by the stats service; requests only read them.
+var StatsMigrations = []database.Migration{
+	{
+		Version: 2026101608,
+		Name:    "stats: product aggregates",
+		Statements: []string{
+			`CREATE MATERIALIZED VIEW IF NOT EXISTS user_product_stats AS
+			SELECT user_id, COUNT(*) AS products, COALESCE(SUM(price), 0) AS total_price, now() AS refreshed_at
+			FROM products WHERE deleted_at IS NULL GROUP BY user_id`,
+			// REFRESH MATERIALIZED VIEW CONCURRENTLY, which doesn't block readers, needs a unique index
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_user_product_stats_user_id ON user_product_stats (user_id)`,
+			`CREATE MATERIALIZED VIEW IF NOT EXISTS daily_product_stats AS
+			SELECT (created_at AT TIME ZONE 'UTC')::date AS day, COUNT(*) AS created, COALESCE(SUM(price), 0) AS total_price, now() AS refreshed_at
+			FROM products WHERE deleted_at IS NULL GROUP BY 1`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_product_stats_day ON daily_product_stats (day)`,
+		},
+	},
+	{
+		Version: 2026101610,
+		Name:    "stats: daily product views",
+		Statements: []string{
+			// Counted in memory and added in batches, see service.ProductViewService. No foreign key: a batch
+			// must not fail because one of its products was purged meanwhile.
+			`CREATE TABLE IF NOT EXISTS product_views (
+				product_id bigint NOT NULL,
+				day date NOT NULL,
+				views bigint NOT NULL,
+				PRIMARY KEY (product_id, day)
+			)`,
+		},
+	},
+	{
+		Version: 2026101611,
+		Name:    "stats: daily API usage",
+		Statements: []string{
+			// Counted in memory and added in batches, see service.APIUsageService. token_id is 0 for requests
+			// authenticated otherwise than with a personal access token; no foreign keys, as for product_views.
+			`CREATE TABLE IF NOT EXISTS api_usage (
+				user_id bigint NOT NULL,
+				token_id bigint NOT NULL,
+				day date NOT NULL,
+				requests bigint NOT NULL,
+				client_errors bigint NOT NULL,
+				server_errors bigint NOT NULL,
+				throttled bigint NOT NULL,
+				cost bigint NOT NULL,
+				PRIMARY KEY (user_id, day, token_id)
+			)`,
+			// The admin ranking reads every user's usage over a period
+			`CREATE INDEX IF NOT EXISTS idx_api_usage_day ON api_usage (day)`,
+		},
+	},
+}
+
+// AuditMigrations are the schema changes of the audit module
+var AuditMigrations = []database.Migration{
+	{
+		Version: 2026101609,
+		Name:    "audit_events: partitioned by month",
+		Statements: []string{
+			// The table is swapped for a partitioned copy in one transaction; audit writes wait for the copy.
+			// Old partitions can then be dropped (audit.retention) instead of deleting rows one by one.
+			`ALTER TABLE audit_events RENAME TO audit_events_unpartitioned`,
+			`ALTER INDEX audit_events_pkey RENAME TO audit_events_unpartitioned_pkey`,
+			`ALTER INDEX IF EXISTS idx_audit_events_action RENAME TO idx_audit_events_unpartitioned_action`,
+			`ALTER INDEX IF EXISTS idx_audit_events_created_at RENAME TO idx_audit_events_unpartitioned_created_at`,
+			// The primary key of a partitioned table must include the partition key; IDs still come from the same sequence
+			`CREATE TABLE audit_events (
+				id bigint NOT NULL DEFAULT nextval('audit_events_id_seq'),
+				action text NOT NULL,
+				resource_type text NOT NULL,
+				resource_id bigint NOT NULL,
+				acting_user_id bigint,
+				effective_user_id bigint,
+				metadata jsonb NOT NULL DEFAULT '{}',
+				created_at timestamptz NOT NULL,
+				PRIMARY KEY (id, created_at)
+			) PARTITION BY RANGE (created_at)`,
+			`CREATE INDEX idx_audit_events_action ON audit_events (action)`,
+			`CREATE INDEX idx_audit_events_created_at ON audit_events (created_at)`,
+			// Partitions from the month of the oldest event to the ones database.PartitionManager creates ahead
+			`DO $$
+			DECLARE
+				month timestamp;
+			BEGIN
+				SELECT date_trunc('month', COALESCE(MIN(created_at), now()) AT TIME ZONE 'UTC') INTO month FROM audit_events_unpartitioned;
+				WHILE month <= date_trunc('month', now() AT TIME ZONE 'UTC') + interval '2 months' LOOP
+					EXECUTE format('CREATE TABLE IF NOT EXISTS %I PARTITION OF audit_events FOR VALUES FROM (%L) TO (%L)',
+						'audit_events_' || to_char(month, 'YYYY_MM'), month AT TIME ZONE 'UTC', (month + interval '1 month') AT TIME ZONE 'UTC');
+					month := month + interval '1 month';
+				END LOOP;
+			END
+			$$`,
+			// Rows of months without a partition, e.g. restored from a backup, land here instead of failing
+			`CREATE TABLE IF NOT EXISTS audit_events_default PARTITION OF audit_events DEFAULT`,
+			`INSERT INTO audit_events (id, action, resource_type, resource_id, acting_user_id, effective_user_id, metadata, created_at)
+			SELECT id, action, resource_type, resource_id, acting_user_id, effective_user_id, metadata, created_at FROM audit_events_unpartitioned`,
+			`ALTER SEQUENCE audit_events_id_seq OWNED BY audit_events.id`,
+			`DROP TABLE audit_events_unpartitioned`,
+		},
+		AllowIncompatible: "audit_events keeps its name and columns; the previous release writes to the partitioned table after the swap",
+	},
+}
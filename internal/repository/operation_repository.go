@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OperationRepository defines the interface for operation data operations
+type OperationRepository interface {
+	CreateOperation(ctx context.Context, op *models.Operation) error
+	GetOperationByID(ctx context.Context, id uint) (*models.Operation, error)
+	UpdateOperation(ctx context.Context, op *models.Operation) error
+	CountUnfinishedOperations(ctx context.Context) (map[string]int64, error)
+	// TouchOperations marks unfinished operations as still executing, see FailStaleOperations
+	TouchOperations(ctx context.Context, ids []uint) error
+	// FailStaleOperations fails the pending and running operations not updated since before, returning how many
+	FailStaleOperations(ctx context.Context, before time.Time, reason string) (int64, error)
+}
+
+// postgresOperationRepository implements OperationRepository using GORM with raw SQL
+type postgresOperationRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresOperationRepository creates a new OperationRepository instance
+func NewPostgresOperationRepository(db *gorm.DB) OperationRepository {
+	return &postgresOperationRepository{db: db}
+}
+
+// CreateOperation inserts a new operation into the database using raw SQL
+func (r *postgresOperationRepository) CreateOperation(ctx context.Context, op *models.Operation) error {
+	sqlQuery := `INSERT INTO operations (kind, status, progress, result, error, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		op.Kind,
+		op.Status,
+		op.Progress,
+		op.Result,
+		op.Error,
+		op.UserID,
+		now,
+		now,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create operation in DB using raw SQL", zap.Error(result.Error), zap.String("kind", op.Kind))
+		return fmt.Errorf("failed to create operation: %w", result.Error)
+	}
+
+	op.ID = newID
+	op.CreatedAt = now
+	op.UpdatedAt = now
+
+	logger.Info("Operation created in DB successfully using raw SQL", zap.Uint("operationID", op.ID), zap.String("kind", op.Kind), actor.Field(ctx))
+	return nil
+}
+
+// GetOperationByID retrieves an operation by its ID using raw SQL
+func (r *postgresOperationRepository) GetOperationByID(ctx context.Context, id uint) (*models.Operation, error) {
+	op := &models.Operation{}
+	sqlQuery := `SELECT id, kind, status, progress, result, error, user_id, created_at, updated_at FROM operations WHERE id = ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(op)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve operation by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("operationID", id))
+		return nil, fmt.Errorf("database error retrieving operation by ID: %w", result.Error)
+	}
+	if op.ID == 0 {
+		logger.Warn("Operation not found by ID using raw SQL", zap.Uint("operationID", id))
+		return nil, fmt.Errorf("operation not found with ID %d", id)
+	}
+	logger.Debug("Operation retrieved by ID using raw SQL", zap.Uint("operationID", op.ID))
+	return op, nil
+}
+
+// UpdateOperation persists the status, progress, result and error of an operation using raw SQL
+func (r *postgresOperationRepository) UpdateOperation(ctx context.Context, op *models.Operation) error {
+	sqlQuery := `UPDATE operations SET status = ?, progress = ?, result = ?, error = ?, updated_at = ? WHERE id = ?`
+
+	op.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Exec(sqlQuery,
+		op.Status,
+		op.Progress,
+		op.Result,
+		op.Error,
+		op.UpdatedAt,
+		op.ID,
+	)
+	if result.Error != nil {
+		logger.Error("Failed to update operation in DB using raw SQL", zap.Error(result.Error), zap.Uint("operationID", op.ID))
+		return fmt.Errorf("failed to update operation: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("operation with ID %d not found for update (raw SQL)", op.ID)
+	}
+	logger.Debug("Operation updated in DB using raw SQL", zap.Uint("operationID", op.ID), zap.String("status", op.Status))
+	return nil
+}
+
+// CountUnfinishedOperations counts pending and running operations by status using raw SQL
+func (r *postgresOperationRepository) CountUnfinishedOperations(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	sqlQuery := `SELECT status, COUNT(*) AS count FROM operations WHERE status IN (?, ?) AND deleted_at IS NULL GROUP BY status`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, models.OperationStatusPending, models.OperationStatusRunning).Scan(&rows)
+	if result.Error != nil {
+		logger.Error("Failed to count unfinished operations using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to count unfinished operations: %w", result.Error)
+	}
+	counts := map[string]int64{models.OperationStatusPending: 0, models.OperationStatusRunning: 0}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// TouchOperations bumps the updated_at of unfinished operations using raw SQL
+func (r *postgresOperationRepository) TouchOperations(ctx context.Context, ids []uint) error {
+	sqlQuery := `UPDATE operations SET updated_at = ? WHERE id IN (?) AND status IN (?, ?)`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), ids, models.OperationStatusPending, models.OperationStatusRunning)
+	if result.Error != nil {
+		logger.Error("Failed to touch operations using raw SQL", zap.Error(result.Error), zap.Int("operations", len(ids)))
+		return fmt.Errorf("failed to touch operations: %w", result.Error)
+	}
+	return nil
+}
+
+// FailStaleOperations marks the unfinished operations last updated before a time as failed using raw SQL
+func (r *postgresOperationRepository) FailStaleOperations(ctx context.Context, before time.Time, reason string) (int64, error) {
+	sqlQuery := `UPDATE operations SET status = ?, error = ?, updated_at = ? WHERE status IN (?, ?) AND updated_at < ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, models.OperationStatusFailed, reason, time.Now(), models.OperationStatusPending, models.OperationStatusRunning, before)
+	if result.Error != nil {
+		logger.Error("Failed to fail stale operations using raw SQL", zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to fail stale operations: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Stale operations failed using raw SQL", zap.Int64("operations", result.RowsAffected))
+	}
+	return result.RowsAffected, nil
+}
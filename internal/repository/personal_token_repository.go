@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// PersonalTokenRepository defines the interface for personal access token data operations
+type PersonalTokenRepository interface {
+	CreateToken(ctx context.Context, token *models.PersonalAccessToken) error
+	GetActiveTokenByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error)
+	GetTokensByUserID(ctx context.Context, userID uint) ([]*models.PersonalAccessToken, error)
+	CountTokensByUserID(ctx context.Context, userID uint) (int64, error)
+	RevokeToken(ctx context.Context, id uint, userID uint) error
+	TouchToken(ctx context.Context, id uint, usedAt time.Time) error
+}
+
+// postgresPersonalTokenRepository implements PersonalTokenRepository using GORM with raw SQL
+type postgresPersonalTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresPersonalTokenRepository creates a new PersonalTokenRepository instance
+func NewPostgresPersonalTokenRepository(db *gorm.DB) PersonalTokenRepository {
+	return &postgresPersonalTokenRepository{db: db}
+}
+
+// personalTokenColumns lists the columns selected for a PersonalAccessToken
+const personalTokenColumns = `t.id, t.user_id, t.name, t.prefix, t.token_hash, t.scopes, t.expires_at, t.last_used_at, t.revoked_at, t.created_at, t.updated_at`
+
+// CreateToken inserts a new personal access token using raw SQL
+func (r *postgresPersonalTokenRepository) CreateToken(ctx context.Context, token *models.PersonalAccessToken) error {
+	sqlQuery := `INSERT INTO personal_access_tokens (user_id, name, prefix, token_hash, scopes, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		token.UserID,
+		token.Name,
+		token.Prefix,
+		token.TokenHash,
+		token.Scopes,
+		token.ExpiresAt,
+		now,
+		now,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create personal access token in DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", token.UserID))
+		return fmt.Errorf("failed to create personal access token: %w", result.Error)
+	}
+
+	token.ID = newID
+	token.CreatedAt = now
+	token.UpdatedAt = now
+	logger.Info("Personal access token created in DB successfully using raw SQL", zap.Uint("tokenID", token.ID), zap.Uint("userID", token.UserID))
+	return nil
+}
+
+// GetActiveTokenByHash retrieves an unrevoked token of an existing user by its hash, along with the user's role, using raw SQL.
+// Expiry is left to the caller. It returns nil, nil if there is no such token.
+func (r *postgresPersonalTokenRepository) GetActiveTokenByHash(ctx context.Context, hash string) (*models.PersonalAccessToken, error) {
+	token := &models.PersonalAccessToken{}
+	sqlQuery := `SELECT ` + personalTokenColumns + `, u.role AS user_role FROM personal_access_tokens t
+		JOIN users u ON u.id = t.user_id AND u.deleted_at IS NULL
+		WHERE t.token_hash = ? AND t.revoked_at IS NULL AND t.deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, hash).Scan(token)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve personal access token from DB using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("database error retrieving personal access token: %w", result.Error)
+	}
+	if token.ID == 0 {
+		return nil, nil
+	}
+	return token, nil
+}
+
+// GetTokensByUserID retrieves a user's unrevoked tokens, newest first, using raw SQL
+func (r *postgresPersonalTokenRepository) GetTokensByUserID(ctx context.Context, userID uint) ([]*models.PersonalAccessToken, error) {
+	var tokens []*models.PersonalAccessToken
+	sqlQuery := `SELECT ` + personalTokenColumns + ` FROM personal_access_tokens t
+		WHERE t.user_id = ? AND t.revoked_at IS NULL AND t.deleted_at IS NULL ORDER BY t.id DESC`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&tokens)
+	if result.Error != nil {
+		logger.Error("Failed to get personal access tokens by user ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get personal access tokens: %w", result.Error)
+	}
+	return tokens, nil
+}
+
+// CountTokensByUserID counts a user's unrevoked tokens using raw SQL
+func (r *postgresPersonalTokenRepository) CountTokensByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	sqlQuery := `SELECT COUNT(*) FROM personal_access_tokens WHERE user_id = ? AND revoked_at IS NULL AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&count)
+	if result.Error != nil {
+		logger.Error("Failed to count personal access tokens using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return 0, fmt.Errorf("failed to count personal access tokens: %w", result.Error)
+	}
+	return count, nil
+}
+
+// RevokeToken revokes one of a user's tokens using raw SQL. The row is kept for the audit trail.
+func (r *postgresPersonalTokenRepository) RevokeToken(ctx context.Context, id uint, userID uint) error {
+	sqlQuery := `UPDATE personal_access_tokens SET revoked_at = ?, updated_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL AND deleted_at IS NULL`
+
+	now := time.Now()
+	result := r.db.WithContext(ctx).Exec(sqlQuery, now, now, id, userID)
+	if result.Error != nil {
+		logger.Error("Failed to revoke personal access token using raw SQL", zap.Error(result.Error), zap.Uint("tokenID", id))
+		return fmt.Errorf("failed to revoke personal access token: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("personal access token with ID %d not found for revocation (raw SQL)", id)
+	}
+	return nil
+}
+
+// TouchToken records that a token was used using raw SQL
+func (r *postgresPersonalTokenRepository) TouchToken(ctx context.Context, id uint, usedAt time.Time) error {
+	sqlQuery := `UPDATE personal_access_tokens SET last_used_at = ? WHERE id = ?`
+
+	if result := r.db.WithContext(ctx).Exec(sqlQuery, usedAt, id); result.Error != nil {
+		logger.Warn("Failed to record personal access token use using raw SQL", zap.Error(result.Error), zap.Uint("tokenID", id))
+		return fmt.Errorf("failed to record personal access token use: %w", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"strings"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProcessingRepository defines the interface for processing log data operations
+type ProcessingRepository interface {
+	CreateProcessingRecord(ctx context.Context, record *models.ProcessingRecord) error
+	GetProcessingRecords(ctx context.Context, query *models.ProcessingLogQuery, offset, limit int) ([]*models.ProcessingRecord, int64, error)
+}
+
+// postgresProcessingRepository implements ProcessingRepository using GORM with raw SQL
+type postgresProcessingRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresProcessingRepository creates a new ProcessingRepository instance
+func NewPostgresProcessingRepository(db *gorm.DB) ProcessingRepository {
+	return &postgresProcessingRepository{db: db}
+}
+
+// CreateProcessingRecord appends a processing log entry using raw SQL
+func (r *postgresProcessingRepository) CreateProcessingRecord(ctx context.Context, record *models.ProcessingRecord) error {
+	sqlQuery := `INSERT INTO processing_records (activity, purpose, resource, subject_user_id, acting_user_id, effective_user_id, detail, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		record.Activity,
+		record.Purpose,
+		record.Resource,
+		record.SubjectUserID,
+		record.ActingUserID,
+		record.EffectiveUserID,
+		record.Detail,
+		record.CreatedAt,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create processing record in DB using raw SQL", zap.Error(result.Error), zap.String("activity", record.Activity), zap.String("resource", record.Resource))
+		return fmt.Errorf("failed to create processing record: %w", result.Error)
+	}
+
+	record.ID = newID
+	return nil
+}
+
+// GetProcessingRecords retrieves a page of processing log entries matching query, newest first, and the total count, using raw SQL
+func (r *postgresProcessingRepository) GetProcessingRecords(ctx context.Context, query *models.ProcessingLogQuery, offset, limit int) ([]*models.ProcessingRecord, int64, error) {
+	conditions := []string{"TRUE"}
+	var args []interface{}
+	if query.SubjectUserID != nil {
+		conditions = append(conditions, "subject_user_id = ?")
+		args = append(args, *query.SubjectUserID)
+	}
+	if query.ActingUserID != nil {
+		conditions = append(conditions, "acting_user_id = ?")
+		args = append(args, *query.ActingUserID)
+	}
+	if query.Activity != "" {
+		conditions = append(conditions, "activity = ?")
+		args = append(args, query.Activity)
+	}
+	if query.Purpose != "" {
+		conditions = append(conditions, "purpose = ?")
+		args = append(args, query.Purpose)
+	}
+	if query.From != nil {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, *query.From)
+	}
+	if query.To != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *query.To)
+	}
+	where := strings.Join(conditions, " AND ")
+
+	var total int64
+	if result := r.db.WithContext(ctx).Raw(`SELECT COUNT(*) FROM processing_records WHERE `+where, args...).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count processing records using raw SQL", zap.Error(result.Error))
+		return nil, 0, fmt.Errorf("failed to count processing records: %w", result.Error)
+	}
+
+	var records []*models.ProcessingRecord
+	sqlQuery := `SELECT id, activity, purpose, resource, subject_user_id, acting_user_id, effective_user_id, detail, created_at
+		FROM processing_records WHERE ` + where + ` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, append(args, limit, offset)...).Scan(&records); result.Error != nil {
+		logger.Error("Failed to get processing records from DB using raw SQL", zap.Error(result.Error))
+		return nil, 0, fmt.Errorf("failed to get processing records: %w", result.Error)
+	}
+	return records, total, nil
+}
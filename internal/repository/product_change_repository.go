@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProductChangeRepository defines the interface for the product change feed.
+// Changes are written by a database trigger, so there is no create method.
+type ProductChangeRepository interface {
+	GetProductChanges(ctx context.Context, userID uint, afterSeq int64, settledBefore time.Time, limit int) ([]*models.ProductChange, error)
+	GetLatestProductChangeSeq(ctx context.Context, settledBefore time.Time) (int64, error)
+	DeleteProductChangesBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// postgresProductChangeRepository implements ProductChangeRepository using GORM with raw SQL
+type postgresProductChangeRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresProductChangeRepository creates a new ProductChangeRepository instance
+func NewPostgresProductChangeRepository(db *gorm.DB) ProductChangeRepository {
+	return &postgresProductChangeRepository{db: db}
+}
+
+// GetProductChanges retrieves up to limit changes of a user's products after afterSeq, in feed order, using raw SQL.
+// Changes at or after settledBefore are left out, see service.ProductChangeService.
+func (r *postgresProductChangeRepository) GetProductChanges(ctx context.Context, userID uint, afterSeq int64, settledBefore time.Time, limit int) ([]*models.ProductChange, error) {
+	var changes []*models.ProductChange
+	sqlQuery := `SELECT seq, user_id, product_id, changed_at FROM product_changes
+		WHERE user_id = ? AND seq > ? AND changed_at < ? ORDER BY seq LIMIT ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID, afterSeq, settledBefore, limit).Scan(&changes)
+	if result.Error != nil {
+		logger.Error("Failed to get product changes from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID), zap.Int64("afterSeq", afterSeq))
+		return nil, fmt.Errorf("failed to get product changes: %w", result.Error)
+	}
+	return changes, nil
+}
+
+// GetLatestProductChangeSeq returns the sequence number of the newest change before settledBefore using raw SQL, 0 if there is none
+func (r *postgresProductChangeRepository) GetLatestProductChangeSeq(ctx context.Context, settledBefore time.Time) (int64, error) {
+	var seq int64
+	sqlQuery := `SELECT seq FROM product_changes WHERE changed_at < ? ORDER BY seq DESC LIMIT 1`
+
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, settledBefore).Scan(&seq); result.Error != nil {
+		logger.Error("Failed to get latest product change from DB using raw SQL", zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to get latest product change: %w", result.Error)
+	}
+	return seq, nil
+}
+
+// DeleteProductChangesBefore prunes changes older than before using raw SQL and returns how many were deleted
+func (r *postgresProductChangeRepository) DeleteProductChangesBefore(ctx context.Context, before time.Time) (int64, error) {
+	sqlQuery := `DELETE FROM product_changes WHERE changed_at < ?`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, before)
+	if result.Error != nil {
+		logger.Error("Failed to prune product changes using raw SQL", zap.Error(result.Error), zap.Time("before", before))
+		return 0, fmt.Errorf("failed to prune product changes: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ProductPermissionRepository defines the interface for product permission data operations
+type ProductPermissionRepository interface {
+	UpsertProductPermission(ctx context.Context, permission *models.ProductPermission) (granted bool, err error)
+	GetProductPermission(ctx context.Context, productID uint, userID uint) (*models.ProductPermission, error)
+	GetProductPermissionsByProductID(ctx context.Context, productID uint) ([]*models.ProductPermission, error)
+	GetProductPermissionsByUserID(ctx context.Context, userID uint) ([]*models.ProductPermission, error)
+	DeleteProductPermission(ctx context.Context, productID uint, userID uint) (bool, error)
+}
+
+// postgresProductPermissionRepository implements ProductPermissionRepository using GORM with raw SQL
+type postgresProductPermissionRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresProductPermissionRepository creates a new ProductPermissionRepository instance
+func NewPostgresProductPermissionRepository(db *gorm.DB) ProductPermissionRepository {
+	return &postgresProductPermissionRepository{db: db}
+}
+
+// productPermissionColumns lists the columns selected for a ProductPermission
+const productPermissionColumns = `pp.product_id, pp.user_id, pp.access, pp.granted_by, pp.created_at, pp.updated_at`
+
+// UpsertProductPermission grants a permission, or changes the access of an existing one, using raw SQL.
+// granted is false, and nothing is written, if the grantee is not a live user.
+func (r *postgresProductPermissionRepository) UpsertProductPermission(ctx context.Context, permission *models.ProductPermission) (bool, error) {
+	sqlQuery := `INSERT INTO product_permissions (product_id, user_id, access, granted_by, created_at, updated_at)
+		SELECT ?, id, ?, ?, ?, ? FROM users WHERE id = ? AND deleted_at IS NULL
+		ON CONFLICT (product_id, user_id) DO UPDATE SET access = EXCLUDED.access, granted_by = EXCLUDED.granted_by, updated_at = EXCLUDED.updated_at
+		RETURNING created_at`
+
+	now := time.Now()
+	var createdAt []time.Time
+	result := r.db.WithContext(ctx).Raw(sqlQuery, permission.ProductID, permission.Access, permission.GrantedBy, now, now, permission.UserID).Scan(&createdAt)
+	if result.Error != nil {
+		logger.Error("Failed to upsert product permission in DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", permission.ProductID), zap.Uint("userID", permission.UserID))
+		return false, fmt.Errorf("failed to grant product permission: %w", result.Error)
+	}
+	if len(createdAt) == 0 {
+		return false, nil
+	}
+
+	permission.CreatedAt = createdAt[0]
+	permission.UpdatedAt = now
+	logger.Info("Product permission upserted in DB successfully using raw SQL", zap.Uint("productID", permission.ProductID), zap.Uint("userID", permission.UserID), zap.String("access", permission.Access))
+	return true, nil
+}
+
+// GetProductPermission retrieves the permission of a live user on a product using raw SQL; nil if there is none
+func (r *postgresProductPermissionRepository) GetProductPermission(ctx context.Context, productID uint, userID uint) (*models.ProductPermission, error) {
+	var permissions []*models.ProductPermission
+	sqlQuery := `SELECT ` + productPermissionColumns + ` FROM product_permissions pp
+		JOIN users u ON u.id = pp.user_id AND u.deleted_at IS NULL
+		WHERE pp.product_id = ? AND pp.user_id = ?`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, productID, userID).Scan(&permissions)
+	if result.Error != nil {
+		logger.Error("Failed to get product permission from DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", productID), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get product permission: %w", result.Error)
+	}
+	if len(permissions) == 0 {
+		return nil, nil
+	}
+	return permissions[0], nil
+}
+
+// GetProductPermissionsByProductID retrieves the permissions granted on a product, to live users, using raw SQL
+func (r *postgresProductPermissionRepository) GetProductPermissionsByProductID(ctx context.Context, productID uint) ([]*models.ProductPermission, error) {
+	var permissions []*models.ProductPermission
+	sqlQuery := `SELECT ` + productPermissionColumns + ` FROM product_permissions pp
+		JOIN users u ON u.id = pp.user_id AND u.deleted_at IS NULL
+		WHERE pp.product_id = ? ORDER BY pp.created_at, pp.user_id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, productID).Scan(&permissions)
+	if result.Error != nil {
+		logger.Error("Failed to get product permissions by product ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", productID))
+		return nil, fmt.Errorf("failed to get product permissions: %w", result.Error)
+	}
+	return permissions, nil
+}
+
+// GetProductPermissionsByUserID retrieves the permissions granted to a user using raw SQL, most recent first
+func (r *postgresProductPermissionRepository) GetProductPermissionsByUserID(ctx context.Context, userID uint) ([]*models.ProductPermission, error) {
+	var permissions []*models.ProductPermission
+	sqlQuery := `SELECT ` + productPermissionColumns + ` FROM product_permissions pp WHERE pp.user_id = ? ORDER BY pp.updated_at DESC, pp.product_id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&permissions)
+	if result.Error != nil {
+		logger.Error("Failed to get product permissions by user ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get product permissions: %w", result.Error)
+	}
+	return permissions, nil
+}
+
+// DeleteProductPermission revokes a user's permission on a product using raw SQL.
+// It reports false if there was none.
+func (r *postgresProductPermissionRepository) DeleteProductPermission(ctx context.Context, productID uint, userID uint) (bool, error) {
+	sqlQuery := `DELETE FROM product_permissions WHERE product_id = ? AND user_id = ?`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, productID, userID)
+	if result.Error != nil {
+		logger.Error("Failed to delete product permission from DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", productID), zap.Uint("userID", userID))
+		return false, fmt.Errorf("failed to revoke product permission: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Product permission deleted from DB successfully using raw SQL", zap.Uint("productID", productID), zap.Uint("userID", userID))
+	}
+	return result.RowsAffected > 0, nil
+}
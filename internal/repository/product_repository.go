@@ -4,7 +4,9 @@ import (
 	"context"
 	"fmt"
 	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
 	"gotemplate/pkg/logger"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -15,9 +17,29 @@ import (
 type ProductRepository interface {
 	AddProduct(ctx context.Context, product *models.Product) error
 	GetProductByID(ctx context.Context, id uint) (*models.Product, error)
-	GetProductsByUserID(ctx context.Context, userID uint) ([]*models.Product, error)
+	GetProductsByUserID(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Product, error)
+	StreamProductsByUserID(ctx context.Context, userID uint, timeRange models.TimeRange, fn func(*models.Product) error) error
 	UpdateProduct(ctx context.Context, product *models.Product) error
 	DeleteProduct(ctx context.Context, id uint) error
+	CountProductsByFilter(ctx context.Context, filter *models.ProductFilter) (int64, error)
+	GetProductsByFilter(ctx context.Context, filter *models.ProductFilter, afterID uint, limit int) ([]*models.Product, error)
+	GetProductByUserAndName(ctx context.Context, userID uint, name string) (*models.Product, error)
+	GetProductByUserAndClientID(ctx context.Context, userID uint, clientID string) (*models.Product, error)
+	UpdateProductIfUnmodified(ctx context.Context, product *models.Product, updatedAt time.Time) (bool, error)
+	DeleteProductIfUnmodified(ctx context.Context, id uint, updatedAt time.Time) (bool, error)
+	// Transaction runs fn with a repository whose methods all run in one transaction, committed if fn returns nil.
+	// Calling Transaction on that repository again opens a savepoint.
+	Transaction(ctx context.Context, fn func(tx ProductRepository) error) error
+	CountProductsByUserID(ctx context.Context, userID uint) (int64, error)
+	SoftDeleteProductsByUserID(ctx context.Context, userID uint) (int64, error)
+	RestoreProductsByUserID(ctx context.Context, userID uint, deletedSince time.Time) (int64, error)
+	ReassignProducts(ctx context.Context, fromUserID, toUserID uint) (int64, error)
+	// LockOwner locks the row of a live user until the transaction ends and reports whether there is one, so the
+	// user can't be deleted or deleted twice while their products are changed. It only makes sense in Transaction.
+	LockOwner(ctx context.Context, userID uint) (bool, error)
+	// DeleteOwner soft-deletes a user together with the cascade of their products, in Transaction. It bypasses
+	// the user cache: callers evict the user with EvictUser once the transaction committed.
+	DeleteOwner(ctx context.Context, userID uint) error
 	// Add other product-related methods
 }
 
@@ -31,18 +53,27 @@ func NewPostgresProductRepository(db *gorm.DB) ProductRepository {
 	return &postgresProductRepository{db: db}
 }
 
+// Transaction runs fn in a database transaction, or in a savepoint if the repository is already in one
+func (r *postgresProductRepository) Transaction(ctx context.Context, fn func(tx ProductRepository) error) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&postgresProductRepository{db: tx})
+	})
+}
+
 // AddProduct inserts a new product into the database using raw SQL
 func (r *postgresProductRepository) AddProduct(ctx context.Context, product *models.Product) error {
-	sqlQuery := `INSERT INTO products (name, description, price, user_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING id`
+	sqlQuery := `INSERT INTO products (name, description, price, user_id, client_id, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?) RETURNING id`
 
+	now := time.Now().Truncate(time.Microsecond) // As stored, so the returned version matches later reads
 	var newID uint
 	result := r.db.WithContext(ctx).Raw(sqlQuery,
 		product.Name,
 		product.Description,
 		product.Price,
 		product.UserID,
-		time.Now(), // Manually set timestamps
-		time.Now(),
+		product.ClientID,
+		now, // Manually set timestamps
+		now,
 	).Scan(&newID)
 
 	if result.Error != nil {
@@ -51,36 +82,73 @@ func (r *postgresProductRepository) AddProduct(ctx context.Context, product *mod
 	}
 
 	product.ID = newID // Set the ID on the product model
+	product.CreatedAt, product.UpdatedAt = now, now
 
-	logger.Info("Product added to DB successfully using raw SQL", zap.Uint("productID", product.ID))
+	logger.Info("Product added to DB successfully using raw SQL", zap.Uint("productID", product.ID), actor.Field(ctx))
 	return nil
 }
 
-// GetProductByID retrieves a product by its ID using raw SQL
+// productWithOwnerRow is the flat result row of a product joined with its owner
+type productWithOwnerRow struct {
+	ID            uint
+	Name          string
+	Description   string
+	Price         float64
+	UserID        uint
+	ClientID      *string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	OwnerUsername string
+	OwnerEmail    string
+}
+
+// GetProductByID retrieves a product by its ID, including its owner, using raw SQL
 func (r *postgresProductRepository) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
-	product := &models.Product{}
-	sqlQuery := `SELECT id, name, description, price, user_id, created_at, updated_at FROM products WHERE id = ?`
+	row := &productWithOwnerRow{}
+	sqlQuery := `SELECT p.id, p.name, p.description, p.price, p.user_id, p.client_id, p.created_at, p.updated_at,
+		u.username AS owner_username, u.email AS owner_email
+		FROM products p LEFT JOIN users u ON u.id = p.user_id
+		WHERE p.id = ? AND p.deleted_at IS NULL`
 
-	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(product)
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(row)
 	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			logger.Warn("Product not found by ID using raw SQL", zap.Uint("productID", id))
-			return nil, fmt.Errorf("product not found with ID %d", id)
-		}
 		logger.Error("Failed to retrieve product by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", id))
 		return nil, fmt.Errorf("database error retrieving product by ID: %w", result.Error)
 	}
+	if row.ID == 0 { // Raw().Scan() doesn't report ErrRecordNotFound
+		logger.Warn("Product not found by ID using raw SQL", zap.Uint("productID", id))
+		return nil, fmt.Errorf("product not found with ID %d", id)
+	}
+
+	product := &models.Product{
+		Name:        row.Name,
+		Description: row.Description,
+		Price:       row.Price,
+		UserID:      row.UserID,
+		ClientID:    row.ClientID,
+	}
+	product.ID = row.ID
+	product.CreatedAt = row.CreatedAt
+	product.UpdatedAt = row.UpdatedAt
+	if row.OwnerUsername != "" { // The owner may be missing if the user row was removed
+		product.User.ID = row.UserID
+		product.User.Username = row.OwnerUsername
+		product.User.Email = row.OwnerEmail
+	}
+
 	logger.Debug("Product retrieved by ID using raw SQL", zap.Uint("productID", product.ID))
 	return product, nil
 }
 
-// GetProductsByUserID retrieves all products for a given user ID using raw SQL
-func (r *postgresProductRepository) GetProductsByUserID(ctx context.Context, userID uint) ([]*models.Product, error) {
+// GetProductsByUserID retrieves all products for a given user ID within timeRange using raw SQL
+func (r *postgresProductRepository) GetProductsByUserID(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Product, error) {
 	var products []*models.Product
-	sqlQuery := `SELECT id, name, description, price, user_id, created_at, updated_at FROM products WHERE user_id = ?`
+	conditions, args := timeRangeConditions(timeRange)
+	conditions = append([]string{"user_id = ?", "deleted_at IS NULL"}, conditions...)
+	sqlQuery := `SELECT id, name, description, price, user_id, client_id, created_at, updated_at FROM products WHERE ` + strings.Join(conditions, " AND ")
 
 	// Use Raw().Scan() to populate a slice of structs
-	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&products)
+	result := r.db.WithContext(ctx).Raw(sqlQuery, append([]interface{}{userID}, args...)...).Scan(&products)
 	if result.Error != nil {
 		logger.Error("Failed to get products by user ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
 		return nil, fmt.Errorf("failed to get products by user ID: %w", result.Error)
@@ -89,16 +157,51 @@ func (r *postgresProductRepository) GetProductsByUserID(ctx context.Context, use
 	return products, nil
 }
 
+// StreamProductsByUserID calls fn with each product of a user within timeRange, in ID order, as rows are read
+// using raw SQL. Only one row is held at a time, whatever the number of products. Iteration stops at the first
+// error of fn, which is returned, or when ctx is cancelled.
+func (r *postgresProductRepository) StreamProductsByUserID(ctx context.Context, userID uint, timeRange models.TimeRange, fn func(*models.Product) error) error {
+	conditions, args := timeRangeConditions(timeRange)
+	conditions = append([]string{"user_id = ?", "deleted_at IS NULL"}, conditions...)
+	sqlQuery := `SELECT id, name, description, price, user_id, client_id, created_at, updated_at FROM products WHERE ` + strings.Join(conditions, " AND ") + ` ORDER BY id`
+
+	db := r.db.WithContext(ctx)
+	rows, err := db.Raw(sqlQuery, append([]interface{}{userID}, args...)...).Rows()
+	if err != nil {
+		logger.Error("Failed to stream products by user ID from DB using raw SQL", zap.Error(err), zap.Uint("userID", userID))
+		return fmt.Errorf("failed to get products by user ID: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		product := &models.Product{}
+		if err := db.ScanRows(rows, product); err != nil {
+			return fmt.Errorf("failed to scan product: %w", err)
+		}
+		if err := fn(product); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to get products by user ID: %w", err)
+	}
+	logger.Debug("Products streamed by user ID using raw SQL", zap.Uint("userID", userID), zap.Int("count", count))
+	return nil
+}
+
 // UpdateProduct updates an existing product in the database using raw SQL
 func (r *postgresProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
-	sqlQuery := `UPDATE products SET name = ?, description = ?, price = ?, updated_at = ? WHERE id = ?`
+	sqlQuery := `UPDATE products SET name = ?, description = ?, price = ?, updated_at = ? WHERE id = ? AND deleted_at IS NULL`
 
 	// Use Exec for UPDATE operations
+	now := time.Now().Truncate(time.Microsecond)
 	result := r.db.WithContext(ctx).Exec(sqlQuery,
 		product.Name,
 		product.Description,
 		product.Price,
-		time.Now(), // Manually update updated_at
+		now, // Manually update updated_at
 		product.ID,
 	)
 	if result.Error != nil {
@@ -108,10 +211,30 @@ func (r *postgresProductRepository) UpdateProduct(ctx context.Context, product *
 	if result.RowsAffected == 0 {
 		return fmt.Errorf("product with ID %d not found for update (raw SQL)", product.ID)
 	}
-	logger.Info("Product updated in DB successfully using raw SQL", zap.Uint("productID", product.ID))
+	product.UpdatedAt = now
+	logger.Info("Product updated in DB successfully using raw SQL", zap.Uint("productID", product.ID), actor.Field(ctx))
 	return nil
 }
 
+// UpdateProductIfUnmodified updates a product like UpdateProduct, but only if it was last modified at updatedAt.
+// It returns false if the product was modified or deleted since.
+func (r *postgresProductRepository) UpdateProductIfUnmodified(ctx context.Context, product *models.Product, updatedAt time.Time) (bool, error) {
+	sqlQuery := `UPDATE products SET name = ?, description = ?, price = ?, updated_at = ? WHERE id = ? AND updated_at = ? AND deleted_at IS NULL`
+
+	now := time.Now().Truncate(time.Microsecond)
+	result := r.db.WithContext(ctx).Exec(sqlQuery, product.Name, product.Description, product.Price, now, product.ID, updatedAt)
+	if result.Error != nil {
+		logger.Error("Failed to conditionally update product in DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", product.ID))
+		return false, fmt.Errorf("failed to update product: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	product.UpdatedAt = now
+	logger.Info("Product updated in DB successfully using raw SQL", zap.Uint("productID", product.ID), actor.Field(ctx))
+	return true, nil
+}
+
 // DeleteProduct deletes a product from the database using raw SQL
 func (r *postgresProductRepository) DeleteProduct(ctx context.Context, id uint) error {
 	// For hard delete:
@@ -130,6 +253,194 @@ func (r *postgresProductRepository) DeleteProduct(ctx context.Context, id uint)
 	if result.RowsAffected == 0 {
 		return fmt.Errorf("product with ID %d not found for deletion (raw SQL)", id)
 	}
-	logger.Info("Product deleted from DB successfully using raw SQL", zap.Uint("productID", id))
+	logger.Info("Product deleted from DB successfully using raw SQL", zap.Uint("productID", id), actor.Field(ctx))
+	return nil
+}
+
+// DeleteProductIfUnmodified deletes a product like DeleteProduct, but only if it was last modified at updatedAt.
+// It returns false if the product was modified or deleted since.
+func (r *postgresProductRepository) DeleteProductIfUnmodified(ctx context.Context, id uint, updatedAt time.Time) (bool, error) {
+	sqlQuery := `DELETE FROM products WHERE id = ? AND updated_at = ?`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, id, updatedAt)
+	if result.Error != nil {
+		logger.Error("Failed to conditionally delete product from DB using raw SQL", zap.Error(result.Error), zap.Uint("productID", id))
+		return false, fmt.Errorf("failed to delete product: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	logger.Info("Product deleted from DB successfully using raw SQL", zap.Uint("productID", id), actor.Field(ctx))
+	return true, nil
+}
+
+// productFilterClause builds the WHERE clause and arguments for a ProductFilter
+func productFilterClause(filter *models.ProductFilter) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if len(filter.IDs) > 0 {
+		conditions = append(conditions, "id IN ?")
+		args = append(args, filter.IDs)
+	}
+	if filter.UserID != nil {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, *filter.UserID)
+	}
+	if filter.NameContains != "" {
+		conditions = append(conditions, "name ILIKE ?")
+		args = append(args, "%"+escapeLike(filter.NameContains)+"%")
+	}
+	if filter.MinPrice != nil {
+		conditions = append(conditions, "price >= ?")
+		args = append(args, *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		conditions = append(conditions, "price <= ?")
+		args = append(args, *filter.MaxPrice)
+	}
+	return strings.Join(conditions, " AND "), args
+}
+
+// escapeLike escapes LIKE wildcards so user input is matched literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// CountProductsByFilter counts the products matching a filter using raw SQL
+func (r *postgresProductRepository) CountProductsByFilter(ctx context.Context, filter *models.ProductFilter) (int64, error) {
+	where, args := productFilterClause(filter)
+	sqlQuery := `SELECT COUNT(*) FROM products WHERE ` + where
+
+	var count int64
+	result := r.db.WithContext(ctx).Raw(sqlQuery, args...).Scan(&count)
+	if result.Error != nil {
+		logger.Error("Failed to count products by filter using raw SQL", zap.Error(result.Error))
+		return 0, fmt.Errorf("failed to count products: %w", result.Error)
+	}
+	return count, nil
+}
+
+// GetProductsByFilter retrieves up to limit matching products with an ID greater than afterID, in ID order, using raw SQL.
+// Paging by ID (keyset) keeps batches stable while earlier batches are being modified.
+func (r *postgresProductRepository) GetProductsByFilter(ctx context.Context, filter *models.ProductFilter, afterID uint, limit int) ([]*models.Product, error) {
+	where, args := productFilterClause(filter)
+	sqlQuery := `SELECT id, name, description, price, user_id, client_id, created_at, updated_at FROM products WHERE ` + where + ` AND id > ? ORDER BY id LIMIT ?`
+	args = append(args, afterID, limit)
+
+	var products []*models.Product
+	result := r.db.WithContext(ctx).Raw(sqlQuery, args...).Scan(&products)
+	if result.Error != nil {
+		logger.Error("Failed to get products by filter from DB using raw SQL", zap.Error(result.Error), zap.Uint("afterID", afterID))
+		return nil, fmt.Errorf("failed to get products: %w", result.Error)
+	}
+	return products, nil
+}
+
+// CountProductsByUserID counts the products a user owns using raw SQL
+func (r *postgresProductRepository) CountProductsByUserID(ctx context.Context, userID uint) (int64, error) {
+	return r.CountProductsByFilter(ctx, &models.ProductFilter{UserID: &userID})
+}
+
+// SoftDeleteProductsByUserID soft-deletes every product a user owns using raw SQL and returns how many were affected
+func (r *postgresProductRepository) SoftDeleteProductsByUserID(ctx context.Context, userID uint) (int64, error) {
+	sqlQuery := `UPDATE products SET deleted_at = ? WHERE user_id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), userID)
+	if result.Error != nil {
+		logger.Error("Failed to soft-delete products by user ID using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return 0, fmt.Errorf("failed to delete products: %w", result.Error)
+	}
+	logger.Info("Products soft-deleted by user ID using raw SQL", zap.Uint("userID", userID), zap.Int64("count", result.RowsAffected), actor.Field(ctx))
+	return result.RowsAffected, nil
+}
+
+// RestoreProductsByUserID undeletes a user's products deleted at or after deletedSince using raw SQL and returns how many were affected
+func (r *postgresProductRepository) RestoreProductsByUserID(ctx context.Context, userID uint, deletedSince time.Time) (int64, error) {
+	sqlQuery := `UPDATE products SET deleted_at = NULL, updated_at = ? WHERE user_id = ? AND deleted_at >= ?`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), userID, deletedSince)
+	if result.Error != nil {
+		logger.Error("Failed to restore products by user ID using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return 0, fmt.Errorf("failed to restore products: %w", result.Error)
+	}
+	logger.Info("Products restored by user ID using raw SQL", zap.Uint("userID", userID), zap.Int64("count", result.RowsAffected), actor.Field(ctx))
+	return result.RowsAffected, nil
+}
+
+// ReassignProducts moves every product from one owner to another using raw SQL and returns how many were affected
+func (r *postgresProductRepository) ReassignProducts(ctx context.Context, fromUserID, toUserID uint) (int64, error) {
+	sqlQuery := `UPDATE products SET user_id = ?, updated_at = ? WHERE user_id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, toUserID, time.Now(), fromUserID)
+	if result.Error != nil {
+		logger.Error("Failed to reassign products using raw SQL", zap.Error(result.Error), zap.Uint("fromUserID", fromUserID), zap.Uint("toUserID", toUserID))
+		return 0, fmt.Errorf("failed to reassign products: %w", result.Error)
+	}
+	logger.Info("Products reassigned using raw SQL", zap.Uint("fromUserID", fromUserID), zap.Uint("toUserID", toUserID), zap.Int64("count", result.RowsAffected), actor.Field(ctx))
+	return result.RowsAffected, nil
+}
+
+// LockOwner locks the row of a live user using raw SQL and reports whether there is one
+func (r *postgresProductRepository) LockOwner(ctx context.Context, userID uint) (bool, error) {
+	sqlQuery := `SELECT id FROM users WHERE id = ? AND deleted_at IS NULL FOR UPDATE`
+
+	var locked []uint
+	if err := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&locked).Error; err != nil {
+		logger.Error("Failed to lock user using raw SQL", zap.Error(err), zap.Uint("userID", userID))
+		return false, fmt.Errorf("failed to lock user: %w", err)
+	}
+	return len(locked) == 1, nil
+}
+
+// DeleteOwner soft-deletes a live user using raw SQL
+func (r *postgresProductRepository) DeleteOwner(ctx context.Context, userID uint) error {
+	sqlQuery := `UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), userID)
+	if result.Error != nil {
+		logger.Error("Failed to delete user from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return fmt.Errorf("failed to delete user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user with ID %d not found for deletion (raw SQL)", userID)
+	}
+	logger.Info("User deleted from DB successfully using raw SQL", zap.Uint("userID", userID), actor.Field(ctx))
 	return nil
 }
+
+// GetProductByUserAndClientID retrieves the product a user's client created with the given client ID using raw SQL.
+// It returns nil without an error when there is none.
+func (r *postgresProductRepository) GetProductByUserAndClientID(ctx context.Context, userID uint, clientID string) (*models.Product, error) {
+	product := &models.Product{}
+	sqlQuery := `SELECT id, name, description, price, user_id, client_id, created_at, updated_at FROM products
+		WHERE user_id = ? AND client_id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID, clientID).Scan(product)
+	if result.Error != nil {
+		logger.Error("Failed to get product by user and client ID using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get product by client ID: %w", result.Error)
+	}
+	if product.ID == 0 {
+		return nil, nil
+	}
+	return product, nil
+}
+
+// GetProductByUserAndName retrieves the oldest of a user's products with exactly the given name using raw SQL.
+// It returns nil without an error when there is none.
+func (r *postgresProductRepository) GetProductByUserAndName(ctx context.Context, userID uint, name string) (*models.Product, error) {
+	product := &models.Product{}
+	sqlQuery := `SELECT id, name, description, price, user_id, client_id, created_at, updated_at FROM products
+		WHERE user_id = ? AND name = ? AND deleted_at IS NULL ORDER BY id LIMIT 1`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID, name).Scan(product)
+	if result.Error != nil {
+		logger.Error("Failed to get product by user and name using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get product by name: %w", result.Error)
+	}
+	if product.ID == 0 {
+		return nil, nil
+	}
+	return product, nil
+}
@@ -0,0 +1,139 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReportRepository defines the interface for content report data operations
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report *models.Report) error
+	GetReportByID(ctx context.Context, id uint) (*models.Report, error)
+	HasOpenReport(ctx context.Context, targetType string, targetID uint, reporterID uint) (bool, error)
+	GetReportsByStatus(ctx context.Context, status string, limit, offset int) ([]*models.Report, int64, error)
+	ResolveReport(ctx context.Context, report *models.Report) error
+}
+
+// postgresReportRepository implements ReportRepository using GORM with raw SQL
+type postgresReportRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresReportRepository creates a new ReportRepository instance
+func NewPostgresReportRepository(db *gorm.DB) ReportRepository {
+	return &postgresReportRepository{db: db}
+}
+
+// CreateReport inserts a new report into the database using raw SQL
+func (r *postgresReportRepository) CreateReport(ctx context.Context, report *models.Report) error {
+	sqlQuery := `INSERT INTO reports (target_type, target_id, reporter_id, reason, details, status, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery,
+		report.TargetType,
+		report.TargetID,
+		report.ReporterID,
+		report.Reason,
+		report.Details,
+		report.Status,
+		now,
+		now,
+	).Scan(&newID)
+
+	if result.Error != nil {
+		logger.Error("Failed to create report in DB using raw SQL", zap.Error(result.Error), zap.String("targetType", report.TargetType), zap.Uint("targetID", report.TargetID))
+		return fmt.Errorf("failed to create report: %w", result.Error)
+	}
+
+	report.ID = newID
+	report.CreatedAt = now
+	report.UpdatedAt = now
+
+	logger.Info("Report created in DB successfully using raw SQL", zap.Uint("reportID", report.ID), actor.Field(ctx))
+	return nil
+}
+
+// GetReportByID retrieves a report by its ID using raw SQL
+func (r *postgresReportRepository) GetReportByID(ctx context.Context, id uint) (*models.Report, error) {
+	report := &models.Report{}
+	sqlQuery := `SELECT id, target_type, target_id, reporter_id, reason, details, status, resolved_by, resolved_at, resolution_note, created_at, updated_at FROM reports WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(report)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve report by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("reportID", id))
+		return nil, fmt.Errorf("database error retrieving report by ID: %w", result.Error)
+	}
+	if report.ID == 0 {
+		logger.Warn("Report not found by ID using raw SQL", zap.Uint("reportID", id))
+		return nil, fmt.Errorf("report not found with ID %d", id)
+	}
+	return report, nil
+}
+
+// HasOpenReport reports whether the reporter already has an open report on the target using raw SQL
+func (r *postgresReportRepository) HasOpenReport(ctx context.Context, targetType string, targetID uint, reporterID uint) (bool, error) {
+	var count int64
+	sqlQuery := `SELECT COUNT(*) FROM reports WHERE target_type = ? AND target_id = ? AND reporter_id = ? AND status = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, targetType, targetID, reporterID, models.ReportStatusOpen).Scan(&count)
+	if result.Error != nil {
+		logger.Error("Failed to check for open reports using raw SQL", zap.Error(result.Error), zap.String("targetType", targetType), zap.Uint("targetID", targetID))
+		return false, fmt.Errorf("failed to check for open reports: %w", result.Error)
+	}
+	return count > 0, nil
+}
+
+// GetReportsByStatus retrieves a page of reports with the given status, oldest first, using raw SQL
+func (r *postgresReportRepository) GetReportsByStatus(ctx context.Context, status string, limit, offset int) ([]*models.Report, int64, error) {
+	var total int64
+	countQuery := `SELECT COUNT(*) FROM reports WHERE status = ? AND deleted_at IS NULL`
+	if result := r.db.WithContext(ctx).Raw(countQuery, status).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count reports by status using raw SQL", zap.Error(result.Error), zap.String("status", status))
+		return nil, 0, fmt.Errorf("failed to count reports: %w", result.Error)
+	}
+
+	var reports []*models.Report
+	sqlQuery := `SELECT id, target_type, target_id, reporter_id, reason, details, status, resolved_by, resolved_at, resolution_note, created_at, updated_at FROM reports
+		WHERE status = ? AND deleted_at IS NULL
+		ORDER BY created_at, id
+		LIMIT ? OFFSET ?`
+	result := r.db.WithContext(ctx).Raw(sqlQuery, status, limit, offset).Scan(&reports)
+	if result.Error != nil {
+		logger.Error("Failed to get reports by status from DB using raw SQL", zap.Error(result.Error), zap.String("status", status))
+		return nil, 0, fmt.Errorf("failed to get reports: %w", result.Error)
+	}
+	return reports, total, nil
+}
+
+// ResolveReport moves an open report to its terminal status using raw SQL.
+// The status guard makes concurrent resolutions of the same report fail instead of overwriting each other.
+func (r *postgresReportRepository) ResolveReport(ctx context.Context, report *models.Report) error {
+	sqlQuery := `UPDATE reports SET status = ?, resolved_by = ?, resolved_at = ?, resolution_note = ?, updated_at = ? WHERE id = ? AND status = ?`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery,
+		report.Status,
+		report.ResolvedBy,
+		report.ResolvedAt,
+		report.ResolutionNote,
+		time.Now(),
+		report.ID,
+		models.ReportStatusOpen,
+	)
+	if result.Error != nil {
+		logger.Error("Failed to resolve report in DB using raw SQL", zap.Error(result.Error), zap.Uint("reportID", report.ID))
+		return fmt.Errorf("failed to resolve report: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("open report with ID %d not found for update (raw SQL)", report.ID)
+	}
+	logger.Info("Report resolved in DB using raw SQL", zap.Uint("reportID", report.ID), zap.String("status", report.Status), actor.Field(ctx))
+	return nil
+}
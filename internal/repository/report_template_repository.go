@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// ReportTemplateRepository defines the interface for report template data operations
+type ReportTemplateRepository interface {
+	CreateReportTemplate(ctx context.Context, template *models.ReportTemplate) error
+	GetReportTemplateByID(ctx context.Context, id uint) (*models.ReportTemplate, error)
+	GetReportTemplates(ctx context.Context) ([]*models.ReportTemplate, error)
+	DeleteReportTemplate(ctx context.Context, id uint) error
+	// RunReport calls fn with the values of the columns of each row of a report, as rows are read. values holds
+	// the value of each filter of spec, in order. spec must be valid (see models.ReportSpec.Validate).
+	RunReport(ctx context.Context, spec *models.ReportSpec, values []interface{}, fn func(row []interface{}) error) error
+}
+
+// postgresReportTemplateRepository implements ReportTemplateRepository using GORM with raw SQL
+type postgresReportTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresReportTemplateRepository creates a new ReportTemplateRepository instance
+func NewPostgresReportTemplateRepository(db *gorm.DB) ReportTemplateRepository {
+	return &postgresReportTemplateRepository{db: db}
+}
+
+// reportTemplateColumns lists the columns selected for a ReportTemplate
+const reportTemplateColumns = `id, name, spec, created_by, created_at, updated_at`
+
+// reportOperators maps report filter operators to SQL
+var reportOperators = map[string]string{
+	models.ReportOpEq:       "=",
+	models.ReportOpNe:       "<>",
+	models.ReportOpLt:       "<",
+	models.ReportOpLte:      "<=",
+	models.ReportOpGt:       ">",
+	models.ReportOpGte:      ">=",
+	models.ReportOpContains: "ILIKE",
+}
+
+// CreateReportTemplate inserts a new report template using raw SQL
+func (r *postgresReportTemplateRepository) CreateReportTemplate(ctx context.Context, template *models.ReportTemplate) error {
+	sqlQuery := `INSERT INTO report_templates (name, spec, created_by, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery, template.Name, template.Spec, template.CreatedBy, now, now).Scan(&newID)
+	if result.Error != nil {
+		logger.Error("Failed to create report template in DB using raw SQL", zap.Error(result.Error), zap.String("name", template.Name))
+		return fmt.Errorf("failed to create report template: %w", result.Error)
+	}
+
+	template.ID = newID
+	template.CreatedAt = now
+	template.UpdatedAt = now
+	logger.Info("Report template created in DB successfully using raw SQL", zap.Uint("reportTemplateID", template.ID))
+	return nil
+}
+
+// GetReportTemplateByID retrieves a report template by its ID using raw SQL
+func (r *postgresReportTemplateRepository) GetReportTemplateByID(ctx context.Context, id uint) (*models.ReportTemplate, error) {
+	template := &models.ReportTemplate{}
+	sqlQuery := `SELECT ` + reportTemplateColumns + ` FROM report_templates WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(template)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve report template by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("reportTemplateID", id))
+		return nil, fmt.Errorf("database error retrieving report template by ID: %w", result.Error)
+	}
+	if template.ID == 0 {
+		return nil, fmt.Errorf("report template not found with ID %d", id)
+	}
+	return template, nil
+}
+
+// GetReportTemplates retrieves all report templates by name using raw SQL
+func (r *postgresReportTemplateRepository) GetReportTemplates(ctx context.Context) ([]*models.ReportTemplate, error) {
+	var templates []*models.ReportTemplate
+	sqlQuery := `SELECT ` + reportTemplateColumns + ` FROM report_templates WHERE deleted_at IS NULL ORDER BY name, id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery).Scan(&templates)
+	if result.Error != nil {
+		logger.Error("Failed to get report templates from DB using raw SQL", zap.Error(result.Error))
+		return nil, fmt.Errorf("failed to get report templates: %w", result.Error)
+	}
+	return templates, nil
+}
+
+// DeleteReportTemplate soft-deletes a report template using raw SQL
+func (r *postgresReportTemplateRepository) DeleteReportTemplate(ctx context.Context, id uint) error {
+	sqlQuery := `UPDATE report_templates SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), id)
+	if result.Error != nil {
+		logger.Error("Failed to delete report template from DB using raw SQL", zap.Error(result.Error), zap.Uint("reportTemplateID", id))
+		return fmt.Errorf("failed to delete report template: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("report template with ID %d not found for deletion (raw SQL)", id)
+	}
+	logger.Info("Report template deleted from DB successfully using raw SQL", zap.Uint("reportTemplateID", id))
+	return nil
+}
+
+// RunReport runs a report using raw SQL. Table and column names come from models.ReportDatasets, never from the
+// spec itself, and every value is a bind parameter, so no template can run SQL of its own.
+func (r *postgresReportTemplateRepository) RunReport(ctx context.Context, spec *models.ReportSpec, values []interface{}, fn func(row []interface{}) error) error {
+	sqlQuery, args := reportQuery(spec, values)
+
+	db := r.db.WithContext(ctx)
+	rows, err := db.Raw(sqlQuery, args...).Rows()
+	if err != nil {
+		logger.Error("Failed to run report using raw SQL", zap.Error(err), zap.String("dataset", spec.Dataset))
+		return fmt.Errorf("failed to run report: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		row := make([]interface{}, len(spec.Columns))
+		dest := make([]interface{}, len(row))
+		for i := range row {
+			dest[i] = &row[i]
+		}
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan report row: %w", err)
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to run report: %w", err)
+	}
+	logger.Debug("Report run using raw SQL", zap.String("dataset", spec.Dataset), zap.Int("count", count))
+	return nil
+}
+
+// reportQuery builds the query of a report and its arguments
+func reportQuery(spec *models.ReportSpec, values []interface{}) (string, []interface{}) {
+	dataset := models.ReportDatasets[spec.Dataset]
+
+	columns := make([]string, 0, len(spec.Columns))
+	for _, col := range spec.Columns {
+		columns = append(columns, dataset.Fields[col.Field].Column)
+	}
+
+	conditions := []string{"TRUE"}
+	if dataset.SoftDeleted {
+		conditions = append(conditions, "deleted_at IS NULL")
+	}
+	args := make([]interface{}, 0, len(spec.Filters)+1)
+	for i, f := range spec.Filters {
+		value := values[i]
+		if f.Op == models.ReportOpContains {
+			value = "%" + escapeLike(fmt.Sprint(value)) + "%"
+		}
+		conditions = append(conditions, dataset.Fields[f.Field].Column+" "+reportOperators[f.Op]+" ?")
+		args = append(args, value)
+	}
+
+	order := "id"
+	if spec.OrderBy != "" {
+		order = dataset.Fields[spec.OrderBy].Column
+	}
+	if spec.Descending {
+		order += " DESC"
+	}
+	if order != "id" && order != "id DESC" {
+		order += ", id" // A stable order when values repeat
+	}
+
+	limit := spec.Limit
+	if limit <= 0 {
+		limit = models.MaxReportRows
+	}
+	args = append(args, limit)
+	return `SELECT ` + strings.Join(columns, ", ") + ` FROM ` + dataset.Table + ` WHERE ` + strings.Join(conditions, " AND ") +
+		` ORDER BY ` + order + ` LIMIT ?`, args
+}
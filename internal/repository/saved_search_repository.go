@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// SavedSearchRepository defines the interface for saved search data operations
+type SavedSearchRepository interface {
+	CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error
+	GetSavedSearchByID(ctx context.Context, id uint) (*models.SavedSearch, error)
+	GetSavedSearchesByUserID(ctx context.Context, userID uint) ([]*models.SavedSearch, error)
+	CountSavedSearchesByUserID(ctx context.Context, userID uint) (int64, error)
+	DeleteSavedSearch(ctx context.Context, id uint) error
+}
+
+// postgresSavedSearchRepository implements SavedSearchRepository using GORM with raw SQL
+type postgresSavedSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSavedSearchRepository creates a new SavedSearchRepository instance
+func NewPostgresSavedSearchRepository(db *gorm.DB) SavedSearchRepository {
+	return &postgresSavedSearchRepository{db: db}
+}
+
+// savedSearchColumns lists the columns selected for a SavedSearch
+const savedSearchColumns = `id, user_id, name, spec, created_at, updated_at`
+
+// CreateSavedSearch inserts a new saved search using raw SQL
+func (r *postgresSavedSearchRepository) CreateSavedSearch(ctx context.Context, search *models.SavedSearch) error {
+	sqlQuery := `INSERT INTO saved_searches (user_id, name, spec, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id`
+
+	now := time.Now()
+	var newID uint
+	result := r.db.WithContext(ctx).Raw(sqlQuery, search.UserID, search.Name, search.Spec, now, now).Scan(&newID)
+	if result.Error != nil {
+		logger.Error("Failed to create saved search in DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", search.UserID))
+		return fmt.Errorf("failed to create saved search: %w", result.Error)
+	}
+
+	search.ID = newID
+	search.CreatedAt = now
+	search.UpdatedAt = now
+	logger.Info("Saved search created in DB successfully using raw SQL", zap.Uint("savedSearchID", search.ID), zap.Uint("userID", search.UserID))
+	return nil
+}
+
+// GetSavedSearchByID retrieves a saved search by its ID using raw SQL
+func (r *postgresSavedSearchRepository) GetSavedSearchByID(ctx context.Context, id uint) (*models.SavedSearch, error) {
+	search := &models.SavedSearch{}
+	sqlQuery := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(search)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve saved search by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("savedSearchID", id))
+		return nil, fmt.Errorf("database error retrieving saved search by ID: %w", result.Error)
+	}
+	if search.ID == 0 {
+		return nil, fmt.Errorf("saved search not found with ID %d", id)
+	}
+	return search, nil
+}
+
+// GetSavedSearchesByUserID retrieves a user's saved searches by name using raw SQL
+func (r *postgresSavedSearchRepository) GetSavedSearchesByUserID(ctx context.Context, userID uint) ([]*models.SavedSearch, error) {
+	var searches []*models.SavedSearch
+	sqlQuery := `SELECT ` + savedSearchColumns + ` FROM saved_searches WHERE user_id = ? AND deleted_at IS NULL ORDER BY name, id`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&searches)
+	if result.Error != nil {
+		logger.Error("Failed to get saved searches by user ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get saved searches: %w", result.Error)
+	}
+	return searches, nil
+}
+
+// CountSavedSearchesByUserID counts a user's saved searches using raw SQL
+func (r *postgresSavedSearchRepository) CountSavedSearchesByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	sqlQuery := `SELECT COUNT(*) FROM saved_searches WHERE user_id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, userID).Scan(&count)
+	if result.Error != nil {
+		logger.Error("Failed to count saved searches using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return 0, fmt.Errorf("failed to count saved searches: %w", result.Error)
+	}
+	return count, nil
+}
+
+// DeleteSavedSearch soft-deletes a saved search using raw SQL
+func (r *postgresSavedSearchRepository) DeleteSavedSearch(ctx context.Context, id uint) error {
+	sqlQuery := `UPDATE saved_searches SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), id)
+	if result.Error != nil {
+		logger.Error("Failed to delete saved search from DB using raw SQL", zap.Error(result.Error), zap.Uint("savedSearchID", id))
+		return fmt.Errorf("failed to delete saved search: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("saved search with ID %d not found for deletion (raw SQL)", id)
+	}
+	logger.Info("Saved search deleted from DB successfully using raw SQL", zap.Uint("savedSearchID", id))
+	return nil
+}
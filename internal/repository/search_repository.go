@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// productSearchDocument is the text full-text search matches products on. It must stay identical to the
+// expression of idx_products_search (see ProductMigrations), or the index is not used.
+const productSearchDocument = `to_tsvector('simple', name || ' ' || coalesce(description, ''))`
+
+// productReadableBy restricts a products query to those the reader given twice, or 0 for all, may read: their own
+// and those shared with them, see ProductService.GetProduct
+const productReadableBy = `(?::bigint = 0 OR user_id = ? OR id IN (
+	SELECT product_id FROM product_permissions WHERE user_id = ?))`
+
+// SearchRepository defines the interface for search queries. Product searches only find the products readerID may
+// read; 0 searches every product, for admins.
+type SearchRepository interface {
+	SearchProductsBySubstring(ctx context.Context, q string, readerID uint, limit, offset int) ([]*models.SearchHit, int64, error)
+	SearchProductsFullText(ctx context.Context, q string, readerID uint, limit, offset int) ([]*models.SearchHit, int64, error)
+	SearchUsersBySubstring(ctx context.Context, q string, limit, offset int) ([]*models.SearchHit, int64, error)
+	ReindexProducts(ctx context.Context) error
+}
+
+// postgresSearchRepository implements SearchRepository using GORM with raw SQL
+type postgresSearchRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresSearchRepository creates a new SearchRepository instance
+func NewPostgresSearchRepository(db *gorm.DB) SearchRepository {
+	return &postgresSearchRepository{db: db}
+}
+
+// SearchProductsBySubstring finds live products whose name or description contains q, case-insensitively, using raw SQL
+func (r *postgresSearchRepository) SearchProductsBySubstring(ctx context.Context, q string, readerID uint, limit, offset int) ([]*models.SearchHit, int64, error) {
+	pattern := "%" + escapeLike(q) + "%"
+	where := `deleted_at IS NULL AND (name ILIKE ? OR description ILIKE ?) AND ` + productReadableBy
+	return r.search(ctx, "products",
+		`SELECT COUNT(*) FROM products WHERE `+where,
+		`SELECT id, name AS title, description AS snippet FROM products WHERE `+where+` ORDER BY name, id LIMIT ? OFFSET ?`,
+		[]interface{}{pattern, pattern, readerID, readerID, readerID}, limit, offset)
+}
+
+// SearchProductsFullText finds live products matching the words of q, best matches first, using raw SQL
+func (r *postgresSearchRepository) SearchProductsFullText(ctx context.Context, q string, readerID uint, limit, offset int) ([]*models.SearchHit, int64, error) {
+	where := `deleted_at IS NULL AND ` + productSearchDocument + ` @@ plainto_tsquery('simple', ?) AND ` + productReadableBy
+	return r.search(ctx, "products",
+		`SELECT COUNT(*) FROM products WHERE `+where,
+		`SELECT id, name AS title, description AS snippet FROM products WHERE `+where+`
+			ORDER BY ts_rank(`+productSearchDocument+`, plainto_tsquery('simple', ?)) DESC, id LIMIT ? OFFSET ?`,
+		[]interface{}{q, readerID, readerID, readerID}, limit, offset, q)
+}
+
+// SearchUsersBySubstring finds live users whose username or email contains q, case-insensitively, using raw SQL
+func (r *postgresSearchRepository) SearchUsersBySubstring(ctx context.Context, q string, limit, offset int) ([]*models.SearchHit, int64, error) {
+	pattern := "%" + escapeLike(q) + "%"
+	where := `deleted_at IS NULL AND (username ILIKE ? OR email ILIKE ?)`
+	return r.search(ctx, "users",
+		`SELECT COUNT(*) FROM users WHERE `+where,
+		`SELECT id, username AS title, email AS snippet FROM users WHERE `+where+` ORDER BY username, id LIMIT ? OFFSET ?`,
+		[]interface{}{pattern, pattern}, limit, offset)
+}
+
+// ReindexProducts rebuilds the full-text index of products, e.g. after bloat from heavy updates, without
+// blocking writes, using raw SQL
+func (r *postgresSearchRepository) ReindexProducts(ctx context.Context) error {
+	// REINDEX CONCURRENTLY can't run inside a transaction; a plain Exec runs on its own
+	if result := r.db.WithContext(ctx).Exec(`REINDEX INDEX CONCURRENTLY idx_products_search`); result.Error != nil {
+		logger.Error("Failed to reindex products using raw SQL", zap.Error(result.Error))
+		return fmt.Errorf("failed to reindex products: %w", result.Error)
+	}
+	return nil
+}
+
+// search runs a count and a page query sharing the where arguments; rankArgs go between them and LIMIT
+func (r *postgresSearchRepository) search(ctx context.Context, resource, countQuery, pageQuery string, args []interface{}, limit, offset int, rankArgs ...interface{}) ([]*models.SearchHit, int64, error) {
+	var total int64
+	if result := r.db.WithContext(ctx).Raw(countQuery, args...).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count search results using raw SQL", zap.Error(result.Error), zap.String("resource", resource))
+		return nil, 0, fmt.Errorf("failed to count %s search results: %w", resource, result.Error)
+	}
+
+	pageArgs := append(append(append([]interface{}{}, args...), rankArgs...), limit, offset)
+	var hits []*models.SearchHit
+	if result := r.db.WithContext(ctx).Raw(pageQuery, pageArgs...).Scan(&hits); result.Error != nil {
+		logger.Error("Failed to search using raw SQL", zap.Error(result.Error), zap.String("resource", resource))
+		return nil, 0, fmt.Errorf("failed to search %s: %w", resource, result.Error)
+	}
+	return hits, total, nil
+}
@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// statsViews are the materialized views of StatsMigrations, in refresh order
+var statsViews = []string{"user_product_stats", "daily_product_stats"}
+
+// StatsRepository defines the interface for reading and refreshing the precomputed aggregates
+type StatsRepository interface {
+	RefreshStats(ctx context.Context) error
+	GetUserProductStats(ctx context.Context, limit, offset int) ([]*models.UserProductStats, int64, *time.Time, error)
+	GetDailyProductStats(ctx context.Context, from, to time.Time) ([]*models.DailyProductStats, *time.Time, error)
+	AddProductViews(ctx context.Context, counts []models.ProductViewCount) error
+	GetDailyProductViews(ctx context.Context, productID uint, from, to time.Time) ([]*models.DailyProductViews, error)
+	AddAPIUsage(ctx context.Context, counts []models.APIUsageCount) error
+	GetDailyAPIUsage(ctx context.Context, userID uint, from, to time.Time) ([]*models.DailyAPIUsage, error)
+	GetCredentialAPIUsage(ctx context.Context, userID uint, from, to time.Time) ([]*models.CredentialAPIUsage, error)
+	GetUserAPIUsage(ctx context.Context, from, to time.Time, limit, offset int) ([]*models.UserAPIUsage, int64, error)
+}
+
+// productViewsBatchSize is the number of counts added per statement
+const productViewsBatchSize = 500
+
+// apiUsageSums are the summed columns of api_usage, as read into models.APIUsage
+const apiUsageSums = `SUM(a.requests) AS requests, SUM(a.client_errors) AS client_errors, SUM(a.server_errors) AS server_errors,
+	SUM(a.throttled) AS throttled, SUM(a.cost) AS cost`
+
+// postgresStatsRepository implements StatsRepository using GORM with raw SQL
+type postgresStatsRepository struct {
+	db *gorm.DB
+}
+
+// NewPostgresStatsRepository creates a new StatsRepository instance
+func NewPostgresStatsRepository(db *gorm.DB) StatsRepository {
+	return &postgresStatsRepository{db: db}
+}
+
+// RefreshStats recomputes every materialized view, concurrently so that readers are never blocked, using raw SQL
+func (r *postgresStatsRepository) RefreshStats(ctx context.Context) error {
+	for _, view := range statsViews {
+		if err := r.db.WithContext(ctx).Exec(`REFRESH MATERIALIZED VIEW CONCURRENTLY ` + view).Error; err != nil {
+			logger.Error("Failed to refresh materialized view using raw SQL", zap.Error(err), zap.String("view", view))
+			return fmt.Errorf("failed to refresh %s: %w", view, err)
+		}
+	}
+	return nil
+}
+
+// GetUserProductStats reads a page of per-user product counts, most products first, with when they were computed,
+// using raw SQL
+func (r *postgresStatsRepository) GetUserProductStats(ctx context.Context, limit, offset int) ([]*models.UserProductStats, int64, *time.Time, error) {
+	var summary struct {
+		Total       int64
+		RefreshedAt *time.Time
+	}
+	countQuery := `SELECT COUNT(*) AS total, MAX(s.refreshed_at) AS refreshed_at
+		FROM user_product_stats s JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL`
+	if result := r.db.WithContext(ctx).Raw(countQuery).Scan(&summary); result.Error != nil {
+		logger.Error("Failed to count user product stats using raw SQL", zap.Error(result.Error))
+		return nil, 0, nil, fmt.Errorf("failed to count user product stats: %w", result.Error)
+	}
+
+	var rows []*models.UserProductStats
+	pageQuery := `SELECT s.user_id, u.username, s.products, s.total_price
+		FROM user_product_stats s JOIN users u ON u.id = s.user_id AND u.deleted_at IS NULL
+		ORDER BY s.products DESC, s.user_id LIMIT ? OFFSET ?`
+	if result := r.db.WithContext(ctx).Raw(pageQuery, limit, offset).Scan(&rows); result.Error != nil {
+		logger.Error("Failed to get user product stats using raw SQL", zap.Error(result.Error))
+		return nil, 0, nil, fmt.Errorf("failed to get user product stats: %w", result.Error)
+	}
+	return rows, summary.Total, summary.RefreshedAt, nil
+}
+
+// GetDailyProductStats reads the daily product summaries from from's day to to's day included, oldest first,
+// with when they were computed, using raw SQL
+func (r *postgresStatsRepository) GetDailyProductStats(ctx context.Context, from, to time.Time) ([]*models.DailyProductStats, *time.Time, error) {
+	var rows []*models.DailyProductStats
+	sqlQuery := `SELECT to_char(day, 'YYYY-MM-DD') AS day, created, total_price
+		FROM daily_product_stats WHERE day BETWEEN ?::date AND ?::date ORDER BY daily_product_stats.day`
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, from, to).Scan(&rows); result.Error != nil {
+		logger.Error("Failed to get daily product stats using raw SQL", zap.Error(result.Error), zap.Time("from", from), zap.Time("to", to))
+		return nil, nil, fmt.Errorf("failed to get daily product stats: %w", result.Error)
+	}
+
+	var refreshedAt *time.Time
+	if result := r.db.WithContext(ctx).Raw(`SELECT MAX(refreshed_at) FROM daily_product_stats`).Scan(&refreshedAt); result.Error != nil {
+		logger.Error("Failed to get daily product stats refresh time using raw SQL", zap.Error(result.Error))
+		return nil, nil, fmt.Errorf("failed to get daily product stats: %w", result.Error)
+	}
+	return rows, refreshedAt, nil
+}
+
+// AddProductViews adds view counts to the daily views of products, in one transaction using raw SQL
+func (r *postgresStatsRepository) AddProductViews(ctx context.Context, counts []models.ProductViewCount) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(counts); start += productViewsBatchSize {
+			batch := counts[start:min(start+productViewsBatchSize, len(counts))]
+			rows := make([]string, len(batch))
+			args := make([]interface{}, 0, 3*len(batch))
+			for i, c := range batch {
+				rows[i] = "(?, ?::date, ?)"
+				args = append(args, c.ProductID, c.Day.Format(time.DateOnly), c.Views)
+			}
+			sqlQuery := `INSERT INTO product_views (product_id, day, views) VALUES ` + strings.Join(rows, ", ") +
+				` ON CONFLICT (product_id, day) DO UPDATE SET views = product_views.views + EXCLUDED.views`
+			if err := tx.Exec(sqlQuery, args...).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to add product views using raw SQL", zap.Error(err), zap.Int("counts", len(counts)))
+		return fmt.Errorf("failed to add product views: %w", err)
+	}
+	return nil
+}
+
+// GetDailyProductViews reads the daily views of a product from from's day to to's day included, oldest first,
+// using raw SQL
+func (r *postgresStatsRepository) GetDailyProductViews(ctx context.Context, productID uint, from, to time.Time) ([]*models.DailyProductViews, error) {
+	var rows []*models.DailyProductViews
+	sqlQuery := `SELECT to_char(day, 'YYYY-MM-DD') AS day, views
+		FROM product_views WHERE product_id = ? AND day BETWEEN ?::date AND ?::date ORDER BY product_views.day`
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, productID, from.Format(time.DateOnly), to.Format(time.DateOnly)).Scan(&rows); result.Error != nil {
+		logger.Error("Failed to get daily product views using raw SQL", zap.Error(result.Error), zap.Uint("productID", productID))
+		return nil, fmt.Errorf("failed to get daily product views: %w", result.Error)
+	}
+	return rows, nil
+}
+
+// AddAPIUsage adds usage counts to the daily API usage of users, in one transaction using raw SQL
+func (r *postgresStatsRepository) AddAPIUsage(ctx context.Context, counts []models.APIUsageCount) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(counts); start += productViewsBatchSize {
+			batch := counts[start:min(start+productViewsBatchSize, len(counts))]
+			rows := make([]string, len(batch))
+			args := make([]interface{}, 0, 8*len(batch))
+			for i, c := range batch {
+				rows[i] = "(?, ?, ?::date, ?, ?, ?, ?, ?)"
+				args = append(args, c.UserID, c.TokenID, c.Day.Format(time.DateOnly), c.Requests, c.ClientErrors, c.ServerErrors, c.Throttled, c.Cost)
+			}
+			sqlQuery := `INSERT INTO api_usage (user_id, token_id, day, requests, client_errors, server_errors, throttled, cost) VALUES ` +
+				strings.Join(rows, ", ") + ` ON CONFLICT (user_id, day, token_id) DO UPDATE SET
+				requests = api_usage.requests + EXCLUDED.requests,
+				client_errors = api_usage.client_errors + EXCLUDED.client_errors,
+				server_errors = api_usage.server_errors + EXCLUDED.server_errors,
+				throttled = api_usage.throttled + EXCLUDED.throttled,
+				cost = api_usage.cost + EXCLUDED.cost`
+			if err := tx.Exec(sqlQuery, args...).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to add API usage using raw SQL", zap.Error(err), zap.Int("counts", len(counts)))
+		return fmt.Errorf("failed to add API usage: %w", err)
+	}
+	return nil
+}
+
+// GetDailyAPIUsage reads the daily API usage of a user from from's day to to's day included, all credentials
+// together, oldest first, using raw SQL
+func (r *postgresStatsRepository) GetDailyAPIUsage(ctx context.Context, userID uint, from, to time.Time) ([]*models.DailyAPIUsage, error) {
+	var rows []*models.DailyAPIUsage
+	sqlQuery := `SELECT to_char(a.day, 'YYYY-MM-DD') AS day, ` + apiUsageSums + `
+		FROM api_usage a WHERE a.user_id = ? AND a.day BETWEEN ?::date AND ?::date GROUP BY a.day ORDER BY a.day`
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, userID, from.Format(time.DateOnly), to.Format(time.DateOnly)).Scan(&rows); result.Error != nil {
+		logger.Error("Failed to get daily API usage using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get daily API usage: %w", result.Error)
+	}
+	return rows, nil
+}
+
+// GetCredentialAPIUsage reads the API usage of a user from from's day to to's day included per personal access
+// token, most requests first, using raw SQL. Token 0 stands for their other credentials.
+func (r *postgresStatsRepository) GetCredentialAPIUsage(ctx context.Context, userID uint, from, to time.Time) ([]*models.CredentialAPIUsage, error) {
+	var rows []*models.CredentialAPIUsage
+	sqlQuery := `SELECT a.token_id, COALESCE(MAX(t.name), '') AS token_name, ` + apiUsageSums + `
+		FROM api_usage a LEFT JOIN personal_access_tokens t ON t.id = a.token_id AND t.deleted_at IS NULL
+		WHERE a.user_id = ? AND a.day BETWEEN ?::date AND ?::date
+		GROUP BY a.token_id ORDER BY requests DESC, a.token_id`
+	if result := r.db.WithContext(ctx).Raw(sqlQuery, userID, from.Format(time.DateOnly), to.Format(time.DateOnly)).Scan(&rows); result.Error != nil {
+		logger.Error("Failed to get API usage per credential using raw SQL", zap.Error(result.Error), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to get API usage per credential: %w", result.Error)
+	}
+	return rows, nil
+}
+
+// GetUserAPIUsage reads a page of per-user API usage from from's day to to's day included, most requests first,
+// using raw SQL
+func (r *postgresStatsRepository) GetUserAPIUsage(ctx context.Context, from, to time.Time, limit, offset int) ([]*models.UserAPIUsage, int64, error) {
+	fromDay, toDay := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	var total int64
+	countQuery := `SELECT COUNT(DISTINCT a.user_id) FROM api_usage a JOIN users u ON u.id = a.user_id AND u.deleted_at IS NULL
+		WHERE a.day BETWEEN ?::date AND ?::date`
+	if result := r.db.WithContext(ctx).Raw(countQuery, fromDay, toDay).Scan(&total); result.Error != nil {
+		logger.Error("Failed to count users of the API using raw SQL", zap.Error(result.Error))
+		return nil, 0, fmt.Errorf("failed to count users of the API: %w", result.Error)
+	}
+
+	var rows []*models.UserAPIUsage
+	pageQuery := `SELECT a.user_id, u.username, ` + apiUsageSums + `
+		FROM api_usage a JOIN users u ON u.id = a.user_id AND u.deleted_at IS NULL
+		WHERE a.day BETWEEN ?::date AND ?::date
+		GROUP BY a.user_id, u.username ORDER BY requests DESC, a.user_id LIMIT ? OFFSET ?`
+	if result := r.db.WithContext(ctx).Raw(pageQuery, fromDay, toDay, limit, offset).Scan(&rows); result.Error != nil {
+		logger.Error("Failed to get API usage per user using raw SQL", zap.Error(result.Error))
+		return nil, 0, fmt.Errorf("failed to get API usage per user: %w", result.Error)
+	}
+	return rows, total, nil
+}
@@ -0,0 +1,23 @@
+package repository
+
+import "gotemplate/internal/models"
+
+// timeRangeConditions returns the SQL conditions and arguments restricting created_at and updated_at
+// of the queried table to r, to be ANDed with the other conditions of a listing
+func timeRangeConditions(r models.TimeRange) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if r.CreatedAfter != nil {
+		conditions = append(conditions, "created_at > ?")
+		args = append(args, *r.CreatedAfter)
+	}
+	if r.CreatedBefore != nil {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, *r.CreatedBefore)
+	}
+	if r.UpdatedAfter != nil {
+		conditions = append(conditions, "updated_at > ?")
+		args = append(args, *r.UpdatedAfter)
+	}
+	return conditions, args
+}
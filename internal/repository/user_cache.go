@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"gotemplate/internal/models"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// maxCachedUsers bounds the user cache; when full, expired entries are swept and, if that isn't enough, it is emptied
+const maxCachedUsers = 10000
+
+// cachedUser is a user looked up by ID and when the lookup stops being served from the cache
+type cachedUser struct {
+	user    models.User
+	expires time.Time
+}
+
+// UserCacheEvicter is implemented by user repositories caching users. Writes to the users table made outside the
+// UserRepository, e.g. within another repository's transaction, must be followed by an eviction, see EvictUser.
+type UserCacheEvicter interface {
+	EvictUser(id uint)
+}
+
+// EvictUser evicts the user with the ID from repo's cache, if repo caches users
+func EvictUser(repo UserRepository, id uint) {
+	if evicter, ok := repo.(UserCacheEvicter); ok {
+		evicter.EvictUser(id)
+	}
+}
+
+// cachedUserRepository decorates a UserRepository, serving GetUserByID from a short-lived cache. Every
+// authenticated request looks its user up (see UserService.CurrentUser); concurrent lookups of the same user
+// missing the cache share one query, so a burst of requests for a user costs a single round trip. Only live users
+// found are cached. Every write to users through this repository evicts the user, and writes elsewhere go through
+// EvictUser. Other instances don't see those evictions, so they may serve a deleted user for up to the TTL.
+type cachedUserRepository struct {
+	UserRepository
+	ttl   time.Duration
+	group singleflight.Group
+
+	mu        sync.Mutex
+	users     map[uint]cachedUser
+	evictions uint64 // Counts evictions, so a lookup that overlapped one doesn't cache what it read before it
+}
+
+// NewCachedUserRepository wraps inner with a GetUserByID cache keeping users for ttl. A zero ttl returns inner.
+func NewCachedUserRepository(inner UserRepository, ttl time.Duration) UserRepository {
+	if ttl <= 0 {
+		return inner
+	}
+	return &cachedUserRepository{UserRepository: inner, ttl: ttl, users: map[uint]cachedUser{}}
+}
+
+// GetUserByID returns the cached user if still fresh, otherwise looks it up, joining a lookup already in flight
+func (r *cachedUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	if user, ok := r.get(id); ok {
+		return user, nil
+	}
+
+	v, err, shared := r.group.Do(strconv.FormatUint(uint64(id), 10), func() (interface{}, error) {
+		evictions := r.evictionCount()
+		user, err := r.UserRepository.GetUserByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		r.put(user, evictions)
+		return *user, nil
+	})
+	if err != nil {
+		// The lookup joined ran with the context of the request that started it; if that request went away, look
+		// the user up again rather than fail a request that is still alive
+		if shared && ctx.Err() == nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)) {
+			return r.UserRepository.GetUserByID(ctx, id)
+		}
+		return nil, err
+	}
+	user := v.(models.User) // Each caller gets its own copy, so none can modify what the others or the cache hold
+	return &user, nil
+}
+
+// RestoreUser restores the user and evicts it, the cache holding what the user was before
+func (r *cachedUserRepository) RestoreUser(ctx context.Context, user *models.User) error {
+	defer r.evict(user.ID)
+	return r.UserRepository.RestoreUser(ctx, user)
+}
+
+// DeleteUser deletes the user and evicts it
+func (r *cachedUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	defer r.evict(id)
+	return r.UserRepository.DeleteUser(ctx, id)
+}
+
+// SetPendingPassword sets the user's password and evicts it
+func (r *cachedUserRepository) SetPendingPassword(ctx context.Context, id uint, pending, hashedPassword string) error {
+	defer r.evict(id)
+	return r.UserRepository.SetPendingPassword(ctx, id, pending, hashedPassword)
+}
+
+// EvictUser evicts the user with the ID, after a write to it made outside this repository
+func (r *cachedUserRepository) EvictUser(id uint) {
+	r.evict(id)
+}
+
+// get returns a copy of the cached user with the ID, if there is one not yet expired
+func (r *cachedUserRepository) get(id uint) (*models.User, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	entry, ok := r.users[id]
+	if !ok || !time.Now().Before(entry.expires) {
+		return nil, false
+	}
+	user := entry.user
+	return &user, true
+}
+
+// evictionCount returns the number of evictions so far
+func (r *cachedUserRepository) evictionCount() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.evictions
+}
+
+// put caches a copy of user for the TTL, unless a user was evicted since evictions was counted
+func (r *cachedUserRepository) put(user *models.User, evictions uint64) {
+	now := time.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.evictions != evictions {
+		return
+	}
+	if len(r.users) >= maxCachedUsers {
+		for id, entry := range r.users {
+			if !now.Before(entry.expires) {
+				delete(r.users, id)
+			}
+		}
+		if len(r.users) >= maxCachedUsers {
+			r.users = map[uint]cachedUser{}
+		}
+	}
+	r.users[user.ID] = cachedUser{user: *user, expires: now.Add(r.ttl)}
+}
+
+// evict removes the user with the ID from the cache. Lookups in flight won't cache what they read, and later
+// lookups don't join them.
+func (r *cachedUserRepository) evict(id uint) {
+	r.group.Forget(strconv.FormatUint(uint64(id), 10))
+	r.mu.Lock()
+	delete(r.users, id)
+	r.evictions++
+	r.mu.Unlock()
+}
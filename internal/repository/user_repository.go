@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gotemplate/internal/models"
 	"gotemplate/pkg/logger"
@@ -15,10 +16,20 @@ import (
 type UserRepository interface {
 	CreateUser(ctx context.Context, user *models.User) error
 	GetUserByEmail(ctx context.Context, email string) (*models.User, error)
+	GetUserByUsername(ctx context.Context, username string) (*models.User, error)
 	GetUserByID(ctx context.Context, id uint) (*models.User, error)
+	GetUsersByIDs(ctx context.Context, ids []uint) ([]*models.User, error)
+	GetDeletedUserByEmail(ctx context.Context, email string) (*models.User, error)
+	RestoreUser(ctx context.Context, user *models.User) error
+	DeleteUser(ctx context.Context, id uint) error
+	// SetPendingPassword replaces the password of a live user, but only while it is still pending
+	SetPendingPassword(ctx context.Context, id uint, pending, hashedPassword string) error
 	// Add other user-related methods as needed
 }
 
+// ErrUserNotFound is wrapped by the errors of lookups finding no live user, as opposed to failing
+var ErrUserNotFound = errors.New("user not found")
+
 // postgresUserRepository implements UserRepository using GORM with raw SQL
 type postgresUserRepository struct {
 	db *gorm.DB
@@ -36,7 +47,7 @@ func (r *postgresUserRepository) CreateUser(ctx context.Context, user *models.Us
 	// GORM's gorm.Model fields (ID, CreatedAt, UpdatedAt) are typically handled by the DB itself
 	// or GORM's hooks. For raw SQL, we'll let the DB assign ID and timestamps.
 	// We might need to fetch them back if the application needs the generated ID immediately.
-	sqlQuery := `INSERT INTO users (username, email, password, created_at, updated_at) VALUES (?, ?, ?, ?, ?) RETURNING id`
+	sqlQuery := `INSERT INTO users (username, email, password, role, created_at, updated_at) VALUES (?, ?, ?, ?, ?, ?) RETURNING id`
 
 	// Use Exec for DML operations (INSERT, UPDATE, DELETE)
 	// For RETURNING ID, we can use Scan, but Exec also returns RowsAffected.
@@ -47,6 +58,7 @@ func (r *postgresUserRepository) CreateUser(ctx context.Context, user *models.Us
 		user.Username,
 		user.Email,
 		user.Password,
+		user.Role,
 		time.Now(), // Manually set timestamps for raw insert
 		time.Now(),
 	).Scan(&newID) // Scan the returned ID into newID
@@ -65,11 +77,10 @@ func (r *postgresUserRepository) CreateUser(ctx context.Context, user *models.Us
 // GetUserByEmail retrieves a user by their email address using raw SQL
 func (r *postgresUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
 	user := &models.User{}
-	sqlQuery := `SELECT id, username, email, password, created_at, updated_at FROM users WHERE email = ?`
+	sqlQuery := `SELECT id, username, email, password, role, created_at, updated_at FROM users WHERE email = ? AND deleted_at IS NULL`
 
 	// Use Raw().Scan() for SELECT queries where you want to scan results into a struct
 	result := r.db.WithContext(ctx).Raw(sqlQuery, email).Scan(user)
-	fmt.Println(user)
 	if result.Error != nil || user.ID == 0 {
 		if result.Error == gorm.ErrRecordNotFound {
 			logger.Warn("User not found by email using raw SQL", zap.String("email", email))
@@ -82,20 +93,127 @@ func (r *postgresUserRepository) GetUserByEmail(ctx context.Context, email strin
 	return user, nil
 }
 
+// GetUserByUsername retrieves a user by their username using raw SQL
+func (r *postgresUserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	user := &models.User{}
+	sqlQuery := `SELECT id, username, email, password, role, created_at, updated_at FROM users WHERE username = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, username).Scan(user)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve user by username from DB using raw SQL", zap.Error(result.Error), zap.String("username", username))
+		return nil, fmt.Errorf("database error retrieving user by username: %w", result.Error)
+	}
+	if user.ID == 0 {
+		logger.Debug("User not found by username using raw SQL", zap.String("username", username))
+		return nil, fmt.Errorf("user not found with username %s", username)
+	}
+	logger.Debug("User retrieved by username using raw SQL", zap.Uint("userID", user.ID))
+	return user, nil
+}
+
 // GetUserByID retrieves a user by their ID using raw SQL
 func (r *postgresUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
 	user := &models.User{}
-	sqlQuery := `SELECT id, username, email, password, created_at, updated_at FROM users WHERE id = ?`
+	sqlQuery := `SELECT id, username, email, password, role, created_at, updated_at FROM users WHERE id = ? AND deleted_at IS NULL`
 
 	result := r.db.WithContext(ctx).Raw(sqlQuery, id).Scan(user)
 	if result.Error != nil {
 		if result.Error == gorm.ErrRecordNotFound {
 			logger.Warn("User not found by ID using raw SQL", zap.Uint("userID", id))
-			return nil, fmt.Errorf("user not found with ID %d", id)
+			return nil, fmt.Errorf("%w with ID %d", ErrUserNotFound, id)
 		}
 		logger.Error("Failed to retrieve user by ID from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", id))
 		return nil, fmt.Errorf("database error retrieving user by ID: %w", result.Error)
 	}
+	if user.ID == 0 { // Raw().Scan() doesn't report ErrRecordNotFound
+		logger.Warn("User not found by ID using raw SQL", zap.Uint("userID", id))
+		return nil, fmt.Errorf("%w with ID %d", ErrUserNotFound, id)
+	}
 	logger.Debug("User retrieved by ID using raw SQL", zap.Uint("userID", user.ID))
 	return user, nil
-}
\ No newline at end of file
+}
+
+// GetUsersByIDs retrieves the live users among ids with one query using raw SQL. IDs of missing or deleted
+// users are left out, so the result may be shorter than ids.
+func (r *postgresUserRepository) GetUsersByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	var users []*models.User
+	if len(ids) == 0 {
+		return users, nil
+	}
+	sqlQuery := `SELECT id, username, email, password, role, created_at, updated_at FROM users WHERE id IN ? AND deleted_at IS NULL`
+
+	if err := r.db.WithContext(ctx).Raw(sqlQuery, ids).Scan(&users).Error; err != nil {
+		logger.Error("Failed to retrieve users by IDs from DB using raw SQL", zap.Error(err), zap.Int("count", len(ids)))
+		return nil, fmt.Errorf("database error retrieving users by IDs: %w", err)
+	}
+	logger.Debug("Users retrieved by IDs using raw SQL", zap.Int("requested", len(ids)), zap.Int("found", len(users)))
+	return users, nil
+}
+
+// GetDeletedUserByEmail retrieves the most recently deleted, not anonymized user with an email address using raw SQL
+func (r *postgresUserRepository) GetDeletedUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	user := &models.User{}
+	sqlQuery := `SELECT id, username, email, password, role, created_at, updated_at, deleted_at FROM users WHERE email = ? AND deleted_at IS NOT NULL AND anonymized_at IS NULL ORDER BY deleted_at DESC LIMIT 1`
+
+	result := r.db.WithContext(ctx).Raw(sqlQuery, email).Scan(user)
+	if result.Error != nil {
+		logger.Error("Failed to retrieve deleted user by email from DB using raw SQL", zap.Error(result.Error), zap.String("email", email))
+		return nil, fmt.Errorf("database error retrieving deleted user by email: %w", result.Error)
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("no deleted user with email %s", email)
+	}
+	logger.Debug("Deleted user retrieved by email using raw SQL", zap.Uint("userID", user.ID))
+	return user, nil
+}
+
+// RestoreUser undeletes a soft-deleted user with a new username, password and role using raw SQL
+func (r *postgresUserRepository) RestoreUser(ctx context.Context, user *models.User) error {
+	sqlQuery := `UPDATE users SET deleted_at = NULL, username = ?, password = ?, role = ?, updated_at = ? WHERE id = ? AND deleted_at IS NOT NULL`
+
+	user.UpdatedAt = time.Now()
+	result := r.db.WithContext(ctx).Exec(sqlQuery, user.Username, user.Password, user.Role, user.UpdatedAt, user.ID)
+	if result.Error != nil {
+		logger.Error("Failed to restore user in DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", user.ID))
+		return fmt.Errorf("failed to restore user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("deleted user with ID %d not found for restore (raw SQL)", user.ID)
+	}
+	user.DeletedAt = gorm.DeletedAt{}
+	logger.Info("User restored in DB successfully using raw SQL", zap.Uint("userID", user.ID))
+	return nil
+}
+
+// DeleteUser soft-deletes a user using raw SQL
+func (r *postgresUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	sqlQuery := `UPDATE users SET deleted_at = ? WHERE id = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, time.Now(), id)
+	if result.Error != nil {
+		logger.Error("Failed to delete user from DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", id))
+		return fmt.Errorf("failed to delete user: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("user with ID %d not found for deletion (raw SQL)", id)
+	}
+	logger.Info("User deleted from DB successfully using raw SQL", zap.Uint("userID", id))
+	return nil
+}
+
+// SetPendingPassword sets the password of a live user whose password is still pending using raw SQL. The pending
+// value is compared in the update, so of concurrent calls only one sets a password.
+func (r *postgresUserRepository) SetPendingPassword(ctx context.Context, id uint, pending, hashedPassword string) error {
+	sqlQuery := `UPDATE users SET password = ?, updated_at = ? WHERE id = ? AND password = ? AND deleted_at IS NULL`
+
+	result := r.db.WithContext(ctx).Exec(sqlQuery, hashedPassword, time.Now(), id, pending)
+	if result.Error != nil {
+		logger.Error("Failed to set pending password in DB using raw SQL", zap.Error(result.Error), zap.Uint("userID", id))
+		return fmt.Errorf("failed to set password: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w with ID %d and a pending password", ErrUserNotFound, id)
+	}
+	logger.Info("Pending password set in DB successfully using raw SQL", zap.Uint("userID", id))
+	return nil
+}
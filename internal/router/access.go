@@ -0,0 +1,201 @@
+package router
+
+import (
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/auth"
+	"gotemplate/pkg/module"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RouteAccess is the authorization matrix: every route, keyed by "METHOD path" relative to /api/v1, with who is
+// expected to call it. Routes declare their access where they are registered; this table is the independent
+// second statement of it, so a route declared .Auth(models.RoleUser) or .Public() where admin or owner access was
+// meant, or not listed here at all, stops the application from starting. Entries of routes that are not
+// registered (e.g. a removed module) are ignored.
+var RouteAccess = map[string]string{
+	// Health
+	"GET /health/live":  module.AccessPublic,
+	"GET /health/ready": module.AccessPublic,
+
+	// Admin
+	"GET /admin/config":           module.AccessAdmin,
+	"GET /admin/status":           module.AccessAdmin,
+	"GET /admin/read-only":        module.AccessAdmin,
+	"PUT /admin/read-only":        module.AccessAdmin,
+	"GET /admin/deprecations":     module.AccessAdmin,
+	"GET /admin/routes":           module.AccessAdmin,
+	"GET /admin/password-hashing": module.AccessAdmin,
+	"GET /admin/migrations":       module.AccessAdmin,
+
+	// Backups
+	"POST /admin/backups":              module.AccessAdmin,
+	"GET /admin/backups":               module.AccessAdmin,
+	"POST /admin/backups/:name/verify": module.AccessAdmin,
+
+	// Users
+	"POST /register":                  module.AccessPublic,
+	"POST /login":                     module.AccessPublic,
+	"POST /set-password":              module.AccessPublic,
+	"GET /user":                       module.AccessUser,
+	"POST /user/reauthenticate":       module.AccessUser,
+	"POST /admin/users/import":        module.AccessAdmin,
+	"DELETE /admin/users/:id":         module.AccessAdmin,
+	"POST /admin/users/:id/anonymize": module.AccessAdmin,
+	"POST /admin/users/:id/merge":     module.AccessAdmin,
+
+	// Sessions
+	"POST /session":   module.AccessPublic,
+	"DELETE /session": module.AccessUser,
+
+	// Personal access tokens
+	"GET /user/tokens":        module.AccessUser,
+	"POST /user/tokens":       module.AccessUser,
+	"DELETE /user/tokens/:id": module.AccessOwner,
+
+	// Products
+	"POST /products":                           module.AccessUser,
+	"POST /products/import":                    module.AccessUser,
+	"GET /products/changes":                    module.AccessUser,
+	"GET /products/:id":                        module.AccessOwner, // Owner, grantees and admins
+	"GET /products":                            module.AccessUser,
+	"PUT /products/:id":                        module.AccessOwner,
+	"DELETE /products/:id":                     module.AccessOwner,
+	"POST /products/batch":                     module.AccessUser,
+	"GET /products/shared":                     module.AccessUser,
+	"POST /products/:id/permissions":           module.AccessOwner,
+	"GET /products/:id/permissions":            module.AccessOwner,
+	"DELETE /products/:id/permissions/:userId": module.AccessOwner,
+	"GET /admin/users/:id/products":            module.AccessAdmin,
+	"POST /admin/products/bulk-update":         module.AccessAdmin,
+
+	// Labels
+	"GET /products/:id/qrcode": module.AccessOwner, // Owner, grantees and admins
+
+	// Bundles
+	"POST /bundles":       module.AccessUser,
+	"GET /bundles/:id":    module.AccessOwner,
+	"GET /bundles":        module.AccessUser,
+	"DELETE /bundles/:id": module.AccessOwner,
+
+	// Comments
+	"POST /products/:id/comments": module.AccessOwner, // Owner, grantees and admins
+	"GET /products/:id/comments":  module.AccessOwner, // Owner, grantees and admins
+	"POST /comments/:id/hide":     module.AccessOwner, // Owner of the commented product
+	"POST /comments/:id/unhide":   module.AccessOwner, // Owner of the commented product
+	"DELETE /comments/:id":        module.AccessOwner, // Comment author or owner of the commented product
+
+	// Reports
+	"POST /products/:id/report": module.AccessUser,
+	"POST /comments/:id/report": module.AccessUser,
+	"GET /admin/reports":        module.AccessAdmin,
+	"PUT /admin/reports/:id":    module.AccessAdmin,
+
+	// Announcements
+	"GET /user/announcements":   module.AccessUser,
+	"POST /admin/announcements": module.AccessAdmin,
+
+	// Activity
+	"GET /user/activity": module.AccessUser,
+
+	// Addresses
+	"GET /user/addresses":        module.AccessUser,
+	"POST /user/addresses":       module.AccessUser,
+	"GET /user/addresses/:id":    module.AccessOwner,
+	"PUT /user/addresses/:id":    module.AccessOwner,
+	"DELETE /user/addresses/:id": module.AccessOwner,
+
+	// Search; users are only searched for admins
+	"GET /search": module.AccessUser,
+
+	// Saved searches
+	"GET /user/saved-searches":        module.AccessUser,
+	"POST /user/saved-searches":       module.AccessUser,
+	"DELETE /user/saved-searches/:id": module.AccessOwner,
+
+	// Admin commands
+	"GET /admin/commands":        module.AccessAdmin,
+	"POST /admin/commands/:name": module.AccessAdmin,
+
+	// Audit log
+	"GET /admin/audit-events": module.AccessAdmin,
+
+	// Processing log
+	"GET /admin/processing-log": module.AccessAdmin,
+
+	// Operations
+	"GET /operations/:id": module.AccessOwner,
+
+	// Stats
+	"GET /admin/stats/users":     module.AccessAdmin,
+	"GET /admin/stats/daily":     module.AccessAdmin,
+	"POST /admin/stats/refresh":  module.AccessAdmin,
+	"GET /products/:id/stats":    module.AccessOwner,
+	"GET /user/usage":            module.AccessUser,
+	"GET /admin/usage":           module.AccessAdmin,
+	"GET /admin/users/:id/usage": module.AccessAdmin,
+
+	// Report templates
+	"GET /admin/report-templates":         module.AccessAdmin,
+	"POST /admin/report-templates":        module.AccessAdmin,
+	"GET /admin/report-templates/:id":     module.AccessAdmin,
+	"DELETE /admin/report-templates/:id":  module.AccessAdmin,
+	"GET /admin/report-templates/:id/run": module.AccessAdmin,
+}
+
+// declaredRoutes collects the route declarations of the modules. Registering routes has no side effects, so
+// it can be done again for checks and introspection.
+func declaredRoutes(modules []module.Module) []*module.Route {
+	routes := &module.Routes{
+		Rollout: func(name string, stable, candidate gin.HandlerFunc) gin.HandlerFunc { return stable },
+	}
+	for _, m := range modules {
+		m.RegisterRoutes(routes)
+	}
+	return routes.Declared()
+}
+
+// CheckRouteAccess verifies that every route declares who may call it, that the declaration matches its
+// RouteAccess entry, and that its requirements fit: roles matching the access, a known token scope, and no
+// scope or step-up on public routes. The application doesn't start otherwise, so adding an endpoint forces an
+// explicit decision about who may use it, stated twice.
+func CheckRouteAccess(modules []module.Module) error {
+	var problems []string
+	for _, rt := range declaredRoutes(modules) {
+		key := rt.Method + " " + rt.Path
+		expected, listed := RouteAccess[key]
+		switch {
+		case rt.Access == "":
+			problems = append(problems, key+" doesn't declare who may call it")
+		case !listed:
+			problems = append(problems, key+" has no RouteAccess entry")
+		case rt.Access != expected:
+			problems = append(problems, fmt.Sprintf("%s is declared %s but RouteAccess expects %s", key, rt.Access, expected))
+		case (rt.Access == module.AccessAdmin) != (len(rt.Roles) == 1 && rt.Roles[0] == models.RoleAdmin):
+			problems = append(problems, fmt.Sprintf("%s is declared %s but lets roles %v through", key, rt.Access, rt.Roles))
+		case rt.Access == module.AccessPublic && (rt.TokenScope != "" || rt.StepUp):
+			problems = append(problems, key+" is public but requires a token scope or recent authentication")
+		case rt.TokenScope != "" && !contains(auth.Scopes, rt.TokenScope):
+			problems = append(problems, fmt.Sprintf("%s requires the unknown token scope %q", key, rt.TokenScope))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("route auth declarations are incomplete: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// contains reports whether values contains v
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
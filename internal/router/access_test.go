@@ -0,0 +1,227 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/handler"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/auth"
+	"gotemplate/pkg/jsonbody"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/module"
+	"gotemplate/pkg/readonly"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.ZapLogger = zap.NewNop()
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// Users of the access tests; product 10 is owned by ownerID and shared with granteeID for reading
+const (
+	ownerID    uint = 1
+	granteeID  uint = 2
+	strangerID uint = 3
+	adminID    uint = 4
+)
+
+// appModules returns the routes of the application's modules as pkg/app wires them, optional ones included.
+// Only the product and admin handlers are backed by services; the others are never called.
+func appModules(productService service.ProductService, productViews service.ProductViewService, readOnly *readonly.Mode) []module.Module {
+	routes := []func(r *module.Routes){
+		HealthRoutes(handler.NewHealthHandler(nil)),
+		AdminRoutes(handler.NewAdminHandler(&config.Config{}, nil, readOnly, nil, nil, nil, nil)),
+		BackupRoutes(handler.NewBackupHandler(nil, nil)),
+		UserRoutes(handler.NewUserHandler(nil, nil, nil, nil)),
+		SessionRoutes(handler.NewSessionHandler(nil, config.SessionConfig{})),
+		OperationRoutes(handler.NewOperationHandler(nil)),
+		CommandRoutes(handler.NewCommandHandler(nil)),
+		AuditRoutes(handler.NewAuditHandler(nil)),
+		ProcessingRoutes(handler.NewProcessingHandler(nil)),
+		PersonalTokenRoutes(handler.NewPersonalTokenHandler(nil)),
+		ActivityRoutes(handler.NewActivityHandler(nil)),
+		ProductRoutes(handler.NewProductHandler(productService, nil, nil, nil, nil, productViews)),
+		LabelRoutes(handler.NewLabelHandler(productService, config.LabelConfig{})),
+		BundleRoutes(handler.NewBundleHandler(nil)),
+		CommentRoutes(handler.NewCommentHandler(nil)),
+		ReportRoutes(handler.NewReportHandler(nil)),
+		AnnouncementRoutes(handler.NewAnnouncementHandler(nil)),
+		AddressRoutes(handler.NewAddressHandler(nil)),
+		SearchRoutes(handler.NewSearchHandler(nil, nil)),
+		SavedSearchRoutes(handler.NewSavedSearchHandler(nil)),
+		StatsRoutes(handler.NewStatsHandler(nil, productViews, nil, nil, config.StatsConfig{})),
+		ReportTemplateRoutes(handler.NewReportTemplateHandler(nil, nil)),
+	}
+	modules := make([]module.Module, len(routes))
+	for i, r := range routes {
+		modules[i] = &module.Definition{ModuleName: fmt.Sprintf("module%d", i), Routes: r}
+	}
+	return modules
+}
+
+// TestAppRoutesDeclareAccess checks every route of the application against RouteAccess
+func TestAppRoutesDeclareAccess(t *testing.T) {
+	if err := CheckRouteAccess(appModules(nil, nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOwnerAndAdminRoutesRefuseOthers sends requests to owner and admin routes as the owner of a product, a user
+// it was shared with for reading, another user and an admin
+func TestOwnerAndAdminRoutesRefuseOthers(t *testing.T) {
+	product := &models.Product{Name: "Lamp", UserID: ownerID}
+	product.ID = 10
+	products := &fakeProductRepository{products: map[uint]*models.Product{product.ID: product}}
+	permissions := &fakePermissionRepository{permissions: []*models.ProductPermission{
+		{ProductID: product.ID, UserID: granteeID, Access: models.ProductAccessRead, GrantedBy: ownerID},
+	}}
+	readOnly := readonly.New(false, "")
+	productService := service.NewProductService(products, permissions, nil, fakeAuditService{})
+	productViews := service.NewProductViewService(nil, products, config.StatsConfig{}, readOnly)
+	modules := appModules(productService, productViews, readOnly)
+
+	cfg := &config.Config{}
+	cfg.Server.MaxJSONBodySize, cfg.Server.MaxJSONDepth = jsonbody.DefaultLimits.MaxSize, jsonbody.DefaultLimits.MaxDepth
+	jwtManager := auth.NewJWTManager(&config.JWTConfig{SecretKey: "test-secret", ExpiresInHour: time.Hour})
+	users := fakeUserLookup{
+		ownerID:    models.RoleUser,
+		granteeID:  models.RoleUser,
+		strangerID: models.RoleUser,
+		adminID:    models.RoleAdmin,
+	}
+	r := SetupRouter(cfg, jwtManager, nil, readOnly, nil, nil, nil, users, nil, modules)
+
+	send := func(method, path, body string, userID uint) int {
+		token, err := jwtManager.GenerateToken(fmt.Sprint(userID), users[userID])
+		if err != nil {
+			t.Fatal(err)
+		}
+		req := httptest.NewRequest(method, "/api/v1"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// The owner goes last, since deleting the product changes the answers to the others
+	callers := []struct {
+		name   string
+		userID uint
+	}{{"stranger", strangerID}, {"grantee", granteeID}, {"admin", adminID}, {"owner", ownerID}}
+	for _, tc := range []struct {
+		method, path, body string
+		want               map[uint]int
+	}{
+		{"GET", "/products/10", "", map[uint]int{strangerID: 404, granteeID: 200, adminID: 200, ownerID: 200}},
+		{"PUT", "/products/10", `{"name":"Desk"}`, map[uint]int{strangerID: 404, granteeID: 403, adminID: 403, ownerID: 200}},
+		{"GET", "/products/10/permissions", "", map[uint]int{strangerID: 404, granteeID: 403, adminID: 403, ownerID: 200}},
+		{"DELETE", "/products/10", "", map[uint]int{strangerID: 404, granteeID: 403, adminID: 403, ownerID: 204}},
+		{"GET", "/admin/read-only", "", map[uint]int{strangerID: 403, granteeID: 403, adminID: 200, ownerID: 403}},
+	} {
+		for _, caller := range callers {
+			if got := send(tc.method, tc.path, tc.body, caller.userID); got != tc.want[caller.userID] {
+				t.Errorf("%s %s as %s: %d, want %d", tc.method, tc.path, caller.name, got, tc.want[caller.userID])
+			}
+		}
+	}
+
+	// Admin routes refuse users before their handlers run
+	for _, rt := range declaredRoutes(modules) {
+		if rt.Access != module.AccessAdmin {
+			continue
+		}
+		path := strings.NewReplacer(":id", "1", ":name", "1").Replace(rt.Path)
+		if got := send(rt.Method, path, "", ownerID); got != http.StatusForbidden {
+			t.Errorf("%s %s as a user: %d, want 403", rt.Method, rt.Path, got)
+		}
+	}
+}
+
+// fakeUserLookup knows the role of each live user
+type fakeUserLookup map[uint]string
+
+func (u fakeUserLookup) CurrentUser(ctx context.Context, userID uint) (*models.User, error) {
+	role, ok := u[userID]
+	if !ok {
+		return nil, nil
+	}
+	user := &models.User{Role: role}
+	user.ID = userID
+	return user, nil
+}
+
+// fakeProductRepository keeps products in memory; other methods aren't implemented
+type fakeProductRepository struct {
+	repository.ProductRepository
+	products map[uint]*models.Product
+}
+
+func (r *fakeProductRepository) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return nil, fmt.Errorf("product with ID %d not found", id)
+	}
+	found := *product
+	return &found, nil
+}
+
+func (r *fakeProductRepository) UpdateProduct(ctx context.Context, product *models.Product) error {
+	stored := *product
+	r.products[product.ID] = &stored
+	return nil
+}
+
+func (r *fakeProductRepository) DeleteProduct(ctx context.Context, id uint) error {
+	delete(r.products, id)
+	return nil
+}
+
+// fakePermissionRepository serves product permissions from memory; other methods aren't implemented
+type fakePermissionRepository struct {
+	repository.ProductPermissionRepository
+	permissions []*models.ProductPermission
+}
+
+func (r *fakePermissionRepository) GetProductPermission(ctx context.Context, productID uint, userID uint) (*models.ProductPermission, error) {
+	for _, p := range r.permissions {
+		if p.ProductID == productID && p.UserID == userID {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakePermissionRepository) GetProductPermissionsByProductID(ctx context.Context, productID uint) ([]*models.ProductPermission, error) {
+	var permissions []*models.ProductPermission
+	for _, p := range r.permissions {
+		if p.ProductID == productID {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions, nil
+}
+
+// fakeAuditService discards audit events; other methods aren't implemented
+type fakeAuditService struct {
+	service.AuditService
+}
+
+func (fakeAuditService) Record(ctx context.Context, action string, resourceType string, resourceID uint, metadata map[string]interface{}) error {
+	return nil
+}
@@ -0,0 +1,117 @@
+package router
+
+import (
+	"context"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/auth"
+	"gotemplate/pkg/middleware"
+	"gotemplate/pkg/module"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// probeKey marks the synthetic requests RouteTable sends through the router
+type probeKey struct{}
+
+// routeProbe is the first global middleware. On a RouteTable request it records the handler chain of the
+// matched route and stops; real requests pass through, as nothing outside this package can set the marker.
+func routeProbe(c *gin.Context) {
+	if chain, ok := c.Request.Context().Value(probeKey{}).(*[]string); ok {
+		*chain = c.HandlerNames()[1:] // Without the probe itself
+		c.Abort()
+		return
+	}
+	c.Next()
+}
+
+// closureSuffix matches the suffixes Go gives closures and method values, e.g. ".func1.2" or "-fm"
+var closureSuffix = regexp.MustCompile(`(\.func\d+)?(\.\d+)*(-fm)?$`)
+
+// RouteTable lists every route of a router built by SetupRouter from modules with its middleware chain and the
+// roles and token scopes it declares. Chains are read by routing one synthetic request per route, so they
+// include the global middleware and the middleware generated from the declaration.
+func RouteTable(engine *gin.Engine, modules []module.Module) []*models.RouteInfo {
+	declared := map[string]*module.Route{}
+	for _, rt := range declaredRoutes(modules) {
+		declared[rt.Method+" /api/v1"+rt.Path] = rt
+	}
+
+	var table []*models.RouteInfo
+	for _, ri := range engine.Routes() {
+		var chain []string
+		req := httptest.NewRequest(ri.Method, probePath(ri.Path), nil)
+		engine.ServeHTTP(httptest.NewRecorder(), req.WithContext(context.WithValue(req.Context(), probeKey{}, &chain)))
+		for i := range chain {
+			chain[i] = shortFuncName(chain[i])
+		}
+
+		info := &models.RouteInfo{Method: ri.Method, Path: ri.Path, Handler: shortFuncName(ri.Handler)}
+		if len(chain) > 0 {
+			info.Middleware = chain[:len(chain)-1]
+		}
+		info.Duplicates = duplicates(info.Middleware)
+		if rt, ok := declared[ri.Method+" "+ri.Path]; ok {
+			info.Access, info.Roles, info.Scopes, info.Cost = rt.Access, rt.Roles, tokenScopes(rt), rt.RequestCost()
+		}
+		table = append(table, info)
+	}
+	sort.Slice(table, func(i, j int) bool {
+		if table[i].Path != table[j].Path {
+			return table[i].Path < table[j].Path
+		}
+		return table[i].Method < table[j].Method
+	})
+	return table
+}
+
+// tokenScopes returns the scopes a personal access token needs for a declared route
+func tokenScopes(rt *module.Route) []string {
+	if rt.Access == module.AccessPublic {
+		return nil
+	}
+	scope := rt.TokenScope
+	if scope == "" {
+		scope = middleware.RequiredScope(rt.Method)
+	}
+	scopes := []string{scope}
+	if contains(rt.Roles, models.RoleAdmin) && scope != auth.ScopeAdmin {
+		scopes = append(scopes, auth.ScopeAdmin) // RequireRole asks admin tokens for the admin scope
+	}
+	return scopes
+}
+
+// probePath fills the parameters of a route path so the router matches it, e.g. /products/:id -> /products/_
+func probePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		if strings.HasPrefix(s, ":") || strings.HasPrefix(s, "*") {
+			segments[i] = "_"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// shortFuncName trims the import path and closure suffixes of a function name,
+// e.g. gotemplate/pkg/middleware.AuthMiddleware.func1 -> middleware.AuthMiddleware
+func shortFuncName(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return closureSuffix.ReplaceAllString(name, "")
+}
+
+// duplicates returns the names appearing more than once in chain, in chain order
+func duplicates(chain []string) []string {
+	seen := map[string]int{}
+	var dups []string
+	for _, name := range chain {
+		if seen[name]++; seen[name] == 2 {
+			dups = append(dups, name)
+		}
+	}
+	return dups
+}
@@ -1,53 +1,146 @@
 package router
 
 import (
-	"gotemplate/internal/handler"
+	"gotemplate/config"
+	"gotemplate/pkg/apiversion"
 	"gotemplate/pkg/auth"
+	"gotemplate/pkg/clientip"
+	"gotemplate/pkg/geoip"
+	"gotemplate/pkg/logger"
 	"gotemplate/pkg/middleware"
+	"gotemplate/pkg/module"
+	"gotemplate/pkg/readonly"
+	"gotemplate/pkg/validation"
 
 	"github.com/gin-gonic/gin" // Import Gin
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
 )
 
-// SetupRouter sets up all application routes and their handlers
-func SetupRouter(
-	userHandler handler.UserHandler,
-	productHandler handler.ProductHandler,
-	jwtManager *auth.JWTManager,
-	debug bool,
-) *gin.Engine {
-	if !debug {
+// readOnlyExempt lists the mutating routes that stay available in read-only mode: signing in and out, and switching it off
+var readOnlyExempt = map[string]bool{
+	"POST /api/v1/login":               true,
+	"POST /api/v1/session":             true,
+	"DELETE /api/v1/session":           true,
+	"POST /api/v1/user/reauthenticate": true,
+	"PUT /api/v1/admin/read-only":      true,
+}
+
+// countryExempt lists the routes that stay available to blocked countries: the health checks of load balancers
+// and probes, which may run from anywhere
+var countryExempt = map[string]bool{
+	"GET /api/v1/health/live":  true,
+	"GET /api/v1/health/ready": true,
+}
+
+// deprecatedRoutes lists the routes kept for older clients, keyed by "METHOD full path". Their responses carry a
+// Deprecation header and their use is reported to the sunset telemetry; remove a route once GET
+// /admin/deprecations shows it unused.
+var deprecatedRoutes = map[string]bool{}
+
+// SetupRouter sets up the global middleware, then serves the routes every module declares
+func SetupRouter(cfg *config.Config, jwtManager *auth.JWTManager, tokens middleware.TokenAuthenticator, readOnly *readonly.Mode, deprecations apiversion.UsageRecorder, geo *geoip.DB, sessions middleware.SessionAuthenticator, users middleware.UserLookup, usage middleware.UsageRecorder, modules []module.Module) *gin.Engine {
+	if !cfg.Server.Debug {
 		gin.SetMode(gin.ReleaseMode) // Set Gin to release mode in production
 	}
 
+	binding.Validator = validation.GinValidator{} // Bind errors name JSON fields, like the services' validation
+
 	router := gin.New() // Create a new Gin router
+	// Only believe forwarding headers from the configured proxies (validated with the config, so none on error)
+	trustedProxies, err := clientip.ParseTrusted(cfg.Server.TrustedProxies)
+	if err != nil {
+		logger.Error("Invalid trusted proxies, trusting none", zap.Error(err))
+	}
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		_ = router.SetTrustedProxies(nil)
+	}
 
 	// Global Middlewares
-	router.Use(middleware.StructuredLogger()) // Custom structured logger middleware
-	router.Use(gin.Recovery())                // Recovers from panics and writes a 500
+	router.Use(routeProbe)                                                                        // Lets RouteTable read each route's handler chain
+	router.Use(middleware.RequestID())                                                            // Identifies the request in responses, logs and query tags
+	router.Use(middleware.RealIP(trustedProxies))                                                 // Resolves the client IP behind the trusted proxies
+	router.Use(middleware.GeoIP(geo))                                                             // Looks up the client country, if a GeoIP database is configured
+	router.Use(middleware.StructuredLogger(cfg.GeoIP.LogRequests))                                // Custom structured logger middleware
+	router.Use(gin.Recovery())                                                                    // Recovers from panics and writes a 500
+	router.Use(middleware.RequestDeadline(cfg.Server.RequestBudget, cfg.Server.MaxRequestBudget)) // Bounds each request's total time
+	router.Use(middleware.DecompressRequest(cfg.Server.MaxDecodedBodySize))                       // Accepts gzip-encoded request bodies
+	router.Use(middleware.JSONLimits(cfg.Server))                                                 // Bounds the JSON bodies handlers decode
+	router.Use(middleware.CountryRestriction(cfg.GeoIP, countryExempt))                           // Blocks or flags configured countries
+	router.Use(middleware.ReadOnly(readOnly, readOnlyExempt))                                     // Rejects mutating requests while read-only mode is on
+	router.Use(middleware.APIVersion(cfg.API.DefaultVersion, deprecatedRoutes, deprecations))     // Picks the response version of deprecated fields, reports their use
+	if cfg.Chaos.Enabled {
+		logger.Warn("Chaos fault injection is enabled", zap.Int("rules", len(cfg.Chaos.Rules)))
+		router.Use(middleware.Chaos(cfg.Chaos.Rules)) // Staging only: injects faults per route
+	}
 	// router.Use(gin.Timeout(time.Second * 10)) // Set a global timeout for requests
 
-	// Public routes (no authentication required)
-	public := router.Group("/api/v1")
-	{
-		public.POST("/register", userHandler.Register) // User registration
-		public.POST("/login", userHandler.Login)       // User login
+	// Every module declares its routes; the middleware chain of each is generated from its auth requirements
+	routes := &module.Routes{
+		// Percentage-based split between old and rewritten handlers
+		Rollout: middleware.Rollout(cfg.Canary.Rollouts),
+		// Mirrors read traffic to a candidate implementation and compares responses in the background
+		Shadow: middleware.Shadow(cfg.Shadow),
+	}
+	for _, m := range modules {
+		m.RegisterRoutes(routes)
+	}
+	chains := &routeChains{
+		authenticate: middleware.AuthMiddleware(jwtManager, tokens, sessions, users),
+		recentAuth:   middleware.RequireRecentAuth(cfg.Auth.StepUpMaxAge),
+		costLimit:    middleware.CostLimit(cfg.Cost),
+		countUsage:   middleware.CountUsage(usage),
+	}
+	api := router.Group("/api/v1")
+	for _, rt := range routes.Declared() {
+		if rt.Access == "" {
+			continue // Refused by CheckRouteAccess; never served without a decision
+		}
+		api.Handle(rt.Method, rt.Path, chains.build(rt)...)
 	}
 
-	// Authenticated routes (require JWT token)
-	authenticated := router.Group("/api/v1")
-	// Apply the authentication middleware to this group
-	authenticated.Use(middleware.AuthMiddleware(jwtManager))
-	{
-		// User routes
-		authenticated.GET("/user", userHandler.GetUser) // Get authenticated user's profile
+	return router
+}
+
+// routeChains builds the middleware chains of declared routes
+type routeChains struct {
+	authenticate gin.HandlerFunc
+	recentAuth   gin.HandlerFunc
+	costLimit    func(cost int) gin.HandlerFunc // Nil when no cost budget is configured
+	countUsage   gin.HandlerFunc                // Nil when API usage isn't counted
+}
 
-		// Product routes
-		authenticated.POST("/products", productHandler.AddProduct)          // Add a new product
-		authenticated.GET("/products/:id", productHandler.GetProduct)       // Get a single product by ID
-		authenticated.GET("/products", productHandler.GetProducts)          // Get all products for the authenticated user
-		authenticated.PUT("/products/:id", productHandler.UpdateProduct)    // Update an existing product
-		authenticated.DELETE("/products/:id", productHandler.DeleteProduct) // Delete a product
+// build returns the handler chain of a route: authentication, usage counting, role and token scope checks, the
+// cost limit and the step-up check as declared, then the route's own handlers. Public routes only get the cost limit.
+func (rc *routeChains) build(rt *module.Route) []gin.HandlerFunc {
+	handlers := rt.Handlers
+	if rt.Strict {
+		handlers = append([]gin.HandlerFunc{middleware.StrictJSON}, handlers...)
+	}
+	if rt.Access == module.AccessPublic {
+		if rc.costLimit == nil {
+			return handlers
+		}
+		return append([]gin.HandlerFunc{rc.costLimit(rt.RequestCost())}, handlers...)
 	}
 
-	return router
+	chain := []gin.HandlerFunc{rc.authenticate}
+	if rc.countUsage != nil {
+		chain = append(chain, rc.countUsage)
+	}
+	if len(rt.Roles) > 0 {
+		chain = append(chain, middleware.RequireRole(rt.Roles...))
+	}
+	scope := rt.TokenScope
+	if scope == "" {
+		scope = middleware.RequiredScope(rt.Method)
+	}
+	chain = append(chain, middleware.RequireScope(scope))
+	if rc.costLimit != nil { // Charged once the caller is known to be allowed, so refused requests cost nothing
+		chain = append(chain, rc.costLimit(rt.RequestCost()))
+	}
+	if rt.StepUp {
+		chain = append(chain, rc.recentAuth)
+	}
+	return append(chain, handlers...)
 }
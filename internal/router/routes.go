@@ -0,0 +1,231 @@
+package router
+
+import (
+	"gotemplate/internal/handler"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/module"
+)
+
+// Route registration per feature. Each function is used as the Routes of that feature's module.
+
+// HealthRoutes registers liveness and readiness probes
+func HealthRoutes(h handler.HealthHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/health/live", h.Live).Public()   // The process is up
+		r.GET("/health/ready", h.Ready).Public() // The process can serve requests (database reachable)
+	}
+}
+
+// AdminRoutes registers instance introspection routes
+func AdminRoutes(h handler.AdminHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/admin/config", h.GetConfig).Auth(models.RoleAdmin)                    // Effective configuration, secrets masked, with the source of each setting
+		r.GET("/admin/status", h.GetStatus).Auth(models.RoleAdmin)                    // Component health, queue depths, background job runs and connection pool usage
+		r.GET("/admin/read-only", h.GetReadOnly).Auth(models.RoleAdmin)               // Whether the instance is in read-only mode
+		r.PUT("/admin/read-only", h.SetReadOnly).Auth(models.RoleAdmin)               // Switch read-only mode on or off (this instance only)
+		r.GET("/admin/deprecations", h.GetDeprecations).Auth(models.RoleAdmin)        // Clients still using deprecated endpoints and fields (?days=)
+		r.GET("/admin/routes", h.GetRoutes).Auth(models.RoleAdmin)                    // Every route with its middleware chain and required roles/scopes
+		r.GET("/admin/password-hashing", h.GetPasswordHashing).Auth(models.RoleAdmin) // Time one password hash at the configured cost, with a suggested cost
+		r.GET("/admin/migrations", h.GetMigrations).Auth(models.RoleAdmin)            // Applied and pending schema migrations with checksums, as this instance's release sees them
+	}
+}
+
+// BackupRoutes registers database backup routes
+func BackupRoutes(h handler.BackupHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/admin/backups", h.StartBackup).Auth(models.RoleAdmin).RecentAuth().Cost(50) // Back up the database (async), then verify it and apply retention
+		r.GET("/admin/backups", h.GetBackups).Auth(models.RoleAdmin)                         // Complete backups, newest first
+		r.POST("/admin/backups/:name/verify", h.VerifyBackup).Auth(models.RoleAdmin)         // Check a backup against its checksums
+	}
+}
+
+// UserRoutes registers authentication and profile routes
+func UserRoutes(h handler.UserHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/register", h.Register).Public().Cost(5)        // User registration
+		r.POST("/login", h.Login).Public().Cost(5)              // User login
+		r.POST("/set-password", h.SetPassword).Public().Cost(5) // Choose the password of an imported account, with its one-time token
+
+		r.GET("/user", h.GetUser).Auth(models.RoleUser)                                // Get authenticated user's profile
+		r.POST("/user/reauthenticate", h.Reauthenticate).Auth(models.RoleUser).Cost(5) // Confirm the password to get a token fresh enough for sensitive endpoints
+
+		r.POST("/admin/users/import", h.ImportUsers).Auth(models.RoleAdmin).RecentAuth().Cost(50)  // Import users from a CSV file (async)
+		r.DELETE("/admin/users/:id", h.DeleteUser).Auth(models.RoleAdmin).RecentAuth()             // Delete a user; owned products follow the cascade config
+		r.POST("/admin/users/:id/anonymize", h.AnonymizeUser).Auth(models.RoleAdmin).RecentAuth()  // Scrub a deleted user's personal data (async)
+		r.POST("/admin/users/:id/merge", h.MergeUser).Auth(models.RoleAdmin).RecentAuth().Cost(50) // Move a user's data into another account and delete it (dry-run or async)
+	}
+}
+
+// SessionRoutes registers cookie session routes, for browser apps that don't handle bearer tokens
+func SessionRoutes(h handler.SessionHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/session", h.Login).Public().Cost(5)         // Sign in; sets the session cookie and returns its CSRF token
+		r.DELETE("/session", h.Logout).Auth(models.RoleUser) // Sign out; ends the session and clears the cookie
+	}
+}
+
+// PersonalTokenRoutes registers personal access token routes
+func PersonalTokenRoutes(h handler.PersonalTokenHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/user/tokens", h.GetTokens).Auth(models.RoleUser)                 // List the user's personal access tokens
+		r.POST("/user/tokens", h.CreateToken).Auth(models.RoleUser).RecentAuth() // Create a personal access token
+		r.DELETE("/user/tokens/:id", h.RevokeToken).Owner()                      // Revoke a personal access token
+	}
+}
+
+// ProductRoutes registers product routes
+func ProductRoutes(h handler.ProductHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/products", h.AddProduct).Auth(models.RoleUser)                                // Add a new product (a clientId UUID makes it idempotent)
+		r.POST("/products/import", h.ImportProducts).Auth(models.RoleUser).Cost(50)            // Import products from a file (async, ?format=shopify-csv)
+		r.GET("/products/changes", h.GetProductChanges).Auth(models.RoleUser).Cost(5)          // Products changed since a token, for incremental sync (?since=<token>)
+		r.GET("/products/:id", h.GetProduct).Owner()                                           // Get a single product by ID, as its owner, a grantee or an admin
+		r.GET("/products", h.GetProducts).Auth(models.RoleUser).Cost(10)                       // Get all products for the authenticated user (?saved=<id> runs a saved search; Accept: application/x-ndjson streams them)
+		r.PUT("/products/:id", h.UpdateProduct).Owner()                                        // Update a product, as its owner or a write grantee (If-Match/If-Unmodified-Since make it conditional)
+		r.DELETE("/products/:id", h.DeleteProduct).Owner()                                     // Delete a product (conditional like updates)
+		r.POST("/products/batch", h.BatchProducts).Auth(models.RoleUser).Cost(10).StrictJSON() // Creates, updates and deletes in one transaction (atomic or best-effort)
+		r.GET("/products/shared", h.GetSharedProducts).Auth(models.RoleUser)                   // Products other users shared with the caller, with their owners and the access granted
+		r.POST("/products/:id/permissions", h.GrantProductPermission).Owner()                  // Share a product for reading or writing (owner only)
+		r.GET("/products/:id/permissions", h.GetProductPermissions).Owner()                    // Users a product is shared with (owner only)
+		r.DELETE("/products/:id/permissions/:userId", h.RevokeProductPermission).Owner()       // Stop sharing a product with a user (owner only)
+
+		r.GET("/admin/users/:id/products", h.GetUserProducts).Auth(models.RoleAdmin).Cost(10)                    // Any user's products, for support
+		r.POST("/admin/products/bulk-update", h.BulkUpdateProducts).Auth(models.RoleAdmin).Cost(50).StrictJSON() // Filtered bulk data fix (dry-run or async); a mistyped filter must not match too much
+	}
+}
+
+// LabelRoutes registers printable product label routes
+func LabelRoutes(h handler.LabelHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/products/:id/qrcode", h.GetProductQRCode).Owner() // QR code linking to the product's public page (?format=png|svg&scale=)
+	}
+}
+
+// BundleRoutes registers product bundle routes
+func BundleRoutes(h handler.BundleHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/bundles", h.CreateBundle).Auth(models.RoleUser) // Bundle several of the user's products
+		r.GET("/bundles/:id", h.GetBundle).Owner()               // Get a bundle with its components
+		r.GET("/bundles", h.GetBundles).Auth(models.RoleUser)    // Get all bundles of the authenticated user
+		r.DELETE("/bundles/:id", h.DeleteBundle).Owner()         // Delete a bundle
+	}
+}
+
+// CommentRoutes registers product comment routes
+func CommentRoutes(h handler.CommentHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/products/:id/comments", h.AddComment).Owner()  // Comment on a product the caller can read, or reply to a comment
+		r.GET("/products/:id/comments", h.GetComments).Owner()  // List the comments of a product the caller can read (paginated)
+		r.POST("/comments/:id/hide", h.HideComment).Owner()     // Product owner hides a comment
+		r.POST("/comments/:id/unhide", h.UnhideComment).Owner() // Product owner restores a hidden comment
+		r.DELETE("/comments/:id", h.DeleteComment).Owner()      // Delete a comment and its replies
+	}
+}
+
+// ReportRoutes registers content reporting and moderation routes
+func ReportRoutes(h handler.ReportHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.POST("/products/:id/report", h.ReportProduct).Auth(models.RoleUser) // Flag a product for moderation
+		r.POST("/comments/:id/report", h.ReportComment).Auth(models.RoleUser) // Flag a comment for moderation
+
+		r.GET("/admin/reports", h.GetModerationQueue).Auth(models.RoleAdmin) // Moderation queue (open reports by default)
+		r.PUT("/admin/reports/:id", h.ResolveReport).Auth(models.RoleAdmin)  // Action or dismiss a report
+	}
+}
+
+// AnnouncementRoutes registers announcement routes
+func AnnouncementRoutes(h handler.AnnouncementHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/user/announcements", h.GetAnnouncements).Auth(models.RoleUser) // Announcements targeted at the user
+
+		r.POST("/admin/announcements", h.PublishAnnouncement).Auth(models.RoleAdmin) // Publish an announcement
+	}
+}
+
+// ActivityRoutes registers activity feed routes
+func ActivityRoutes(h handler.ActivityHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/user/activity", h.GetActivity).Auth(models.RoleUser) // The user's own activity feed (paginated)
+	}
+}
+
+// AddressRoutes registers saved address routes
+func AddressRoutes(h handler.AddressHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/user/addresses", h.GetAddresses).Auth(models.RoleUser) // List saved addresses, default first
+		r.POST("/user/addresses", h.AddAddress).Auth(models.RoleUser)  // Save a new address
+		r.GET("/user/addresses/:id", h.GetAddress).Owner()             // Get a saved address
+		r.PUT("/user/addresses/:id", h.UpdateAddress).Owner()          // Replace a saved address
+		r.DELETE("/user/addresses/:id", h.DeleteAddress).Owner()       // Remove a saved address
+	}
+}
+
+// SearchRoutes registers global search routes
+func SearchRoutes(h handler.SearchHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/search", h.Search).Auth(models.RoleUser).Cost(5) // Search products, and users for admins (paginated per type)
+	}
+}
+
+// SavedSearchRoutes registers saved search routes; GET /products?saved=<id> runs one
+func SavedSearchRoutes(h handler.SavedSearchHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/user/saved-searches", h.GetSavedSearches).Auth(models.RoleUser) // List saved searches
+		r.POST("/user/saved-searches", h.SaveSearch).Auth(models.RoleUser)      // Save a named product filter set
+		r.DELETE("/user/saved-searches/:id", h.DeleteSavedSearch).Owner()       // Remove a saved search
+	}
+}
+
+// CommandRoutes registers admin command routes
+func CommandRoutes(h handler.CommandHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/admin/commands", h.GetCommands).Auth(models.RoleAdmin)                                 // Operational commands admins can run, with their parameters
+		r.POST("/admin/commands/:name", h.EnqueueCommand).Auth(models.RoleAdmin).RecentAuth().Cost(50) // Validate params and run a command (async), audited
+	}
+}
+
+// AuditRoutes registers audit log routes
+func AuditRoutes(h handler.AuditHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/admin/audit-events", h.GetAuditLog).Auth(models.RoleAdmin) // Who did what, including runtime setting changes (?action=&setting=&from=&to=, paginated)
+	}
+}
+
+// ProcessingRoutes registers personal data processing log routes
+func ProcessingRoutes(h handler.ProcessingHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/admin/processing-log", h.GetProcessingLog).Auth(models.RoleAdmin) // Who accessed or exported whose data and why, for compliance officers
+	}
+}
+
+// OperationRoutes registers long-running operation routes
+func OperationRoutes(h handler.OperationHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/operations/:id", h.GetOperation).Owner() // Poll a long-running operation
+	}
+}
+
+// StatsRoutes registers statistics routes: admin aggregates refreshed on schedule, product views for their owners,
+// and API usage for users and admins
+func StatsRoutes(h handler.StatsHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/admin/stats/users", h.GetUserProductStats).Auth(models.RoleAdmin)      // Product counts per user, most products first (paginated)
+		r.GET("/admin/stats/daily", h.GetDailyProductStats).Auth(models.RoleAdmin)     // Products created per day and their total price (?from=&to=)
+		r.POST("/admin/stats/refresh", h.RefreshStats).Auth(models.RoleAdmin).Cost(50) // Recompute the aggregates now
+		r.GET("/products/:id/stats", h.GetProductViews).Owner()                        // Daily views of one of the caller's products (?from=&to=)
+		r.GET("/user/usage", h.GetAPIUsage).Auth(models.RoleUser)                      // The caller's API requests, errors and cost per day and credential (?from=&to=)
+		r.GET("/admin/usage", h.GetAPIUsagePerUser).Auth(models.RoleAdmin)             // API usage per user, most requests first (?from=&to=, paginated)
+		r.GET("/admin/users/:id/usage", h.GetUserAPIUsage).Auth(models.RoleAdmin)      // Any user's API usage, for support (?from=&to=)
+	}
+}
+
+// ReportTemplateRoutes registers admin report template routes
+func ReportTemplateRoutes(h handler.ReportTemplateHandler) func(r *module.Routes) {
+	return func(r *module.Routes) {
+		r.GET("/admin/report-templates", h.GetReportTemplates).Auth(models.RoleAdmin)                 // Report templates by name
+		r.POST("/admin/report-templates", h.CreateReportTemplate).Auth(models.RoleAdmin).RecentAuth() // Define a report: dataset, columns, filters and parameters
+		r.GET("/admin/report-templates/:id", h.GetReportTemplate).Auth(models.RoleAdmin)              // A report template and the parameters a run needs
+		r.DELETE("/admin/report-templates/:id", h.DeleteReportTemplate).Auth(models.RoleAdmin)        // Remove a report template
+		r.GET("/admin/report-templates/:id/run", h.RunReport).Auth(models.RoleAdmin).Cost(50)         // Download a report as CSV; parameters as query parameters
+	}
+}
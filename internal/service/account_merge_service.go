@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/session"
+
+	"go.uber.org/zap"
+)
+
+// AccountMergeService defines the interface for merging duplicate user accounts
+type AccountMergeService interface {
+	CheckMergeable(ctx context.Context, sourceID, targetID uint) error
+	MergeUsers(ctx context.Context, sourceID, targetID uint, dryRun bool, report ProgressFunc) (*models.AccountMergeReport, error)
+}
+
+// accountMergeService implements AccountMergeService
+type accountMergeService struct {
+	mergeRepo    repository.AccountMergeRepository // Dependency on AccountMergeRepository
+	userRepo     repository.UserRepository         // Checks both accounts are live
+	auditService AuditService                      // Merges are recorded in the audit log
+	sessions     session.Store                     // The source's sessions are ended; nil unless cookie sessions are enabled
+}
+
+// NewAccountMergeService creates a new AccountMergeService instance
+func NewAccountMergeService(mergeRepo repository.AccountMergeRepository, userRepo repository.UserRepository, auditService AuditService, sessions session.Store) AccountMergeService {
+	return &accountMergeService{
+		mergeRepo:    mergeRepo,
+		userRepo:     userRepo,
+		auditService: auditService,
+		sessions:     sessions,
+	}
+}
+
+// CheckMergeable verifies that two distinct live users exist and that the source can be merged away.
+// Admin accounts can't be: their role would silently be lost, or granted to whoever held the source.
+func (s *accountMergeService) CheckMergeable(ctx context.Context, sourceID, targetID uint) error {
+	if sourceID == targetID {
+		return errors.New("cannot merge a user into itself")
+	}
+	source, err := s.userRepo.GetUserByID(ctx, sourceID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if _, err := s.userRepo.GetUserByID(ctx, targetID); err != nil {
+		return errors.New("target user not found")
+	}
+	if source.Role == models.RoleAdmin {
+		return errors.New("cannot merge an admin account")
+	}
+	return nil
+}
+
+// MergeUsers moves the source user's products, bundles, comments, reports, addresses, saved searches and shares
+// to the target and revokes the source's personal access tokens, all or nothing. Then it deletes the source and
+// ends its sessions, in the same operation. A dry run reports the same counts without changing anything.
+func (s *accountMergeService) MergeUsers(ctx context.Context, sourceID, targetID uint, dryRun bool, report ProgressFunc) (*models.AccountMergeReport, error) {
+	if err := s.CheckMergeable(ctx, sourceID, targetID); err != nil {
+		return nil, err
+	}
+
+	result, err := s.mergeRepo.MergeUsers(ctx, sourceID, targetID, dryRun)
+	if err != nil {
+		logger.Error("Failed to merge users in repository", zap.Error(err), zap.Uint("sourceUserID", sourceID), zap.Uint("targetUserID", targetID))
+		return nil, fmt.Errorf("failed to merge users: %w", err)
+	}
+	if report != nil {
+		report(100)
+	}
+	if dryRun {
+		return result, nil
+	}
+	// Deleting through the user repository evicts the source from the user cache, so its credentials are refused
+	// at once. Should it fail, the source is left live and empty: merging again moves anything added since and
+	// deletes it.
+	if err := s.userRepo.DeleteUser(ctx, sourceID); err != nil {
+		logger.Error("Failed to delete the merged user in repository", zap.Error(err), zap.Uint("sourceUserID", sourceID))
+		return nil, fmt.Errorf("failed to delete the merged user, merge again to finish: %w", err)
+	}
+	revokeSessions(ctx, s.sessions, sourceID)
+
+	if err := s.auditService.Record(ctx, "user.merged", "user", sourceID, map[string]interface{}{
+		"intoUserId":         targetID,
+		"products":           result.Products,
+		"clientIdsCleared":   result.ClientIDsCleared,
+		"bundles":            result.Bundles,
+		"comments":           result.Comments,
+		"reports":            result.Reports,
+		"addresses":          result.Addresses,
+		"savedSearches":      result.SavedSearches,
+		"permissionsMoved":   result.PermissionsMoved,
+		"permissionsDropped": result.PermissionsDropped,
+		"tokens":             result.Tokens,
+	}); err != nil {
+		logger.Warn("User merge audit event was not recorded", zap.Error(err), zap.Uint("userID", sourceID))
+	}
+	logger.Info("Users merged successfully", zap.Uint("sourceUserID", sourceID), zap.Uint("targetUserID", targetID), actor.Field(ctx))
+	return result, nil
+}
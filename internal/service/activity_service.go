@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ActivityService defines the interface for the per-user activity feed.
+// It builds the feed by subscribing to audit events.
+type ActivityService interface {
+	AuditSubscriber
+	GetActivity(ctx context.Context, userID uint, page, pageSize int) ([]*models.ActivityEntry, int64, error)
+}
+
+// activityService implements ActivityService
+type activityService struct {
+	activityRepo repository.ActivityRepository // Dependency on ActivityRepository
+}
+
+// NewActivityService creates a new ActivityService instance
+func NewActivityService(activityRepo repository.ActivityRepository) ActivityService {
+	return &activityService{
+		activityRepo: activityRepo,
+	}
+}
+
+// OnAuditEvent projects user-facing audit events into the effective user's feed.
+// Events that don't belong in a feed (system jobs, admin-only actions) are ignored.
+func (s *activityService) OnAuditEvent(ctx context.Context, event *models.AuditEvent, metadata map[string]interface{}) {
+	if event.EffectiveUserID == 0 {
+		return
+	}
+
+	verb, summary := event.Action, ""
+	name, _ := metadata["name"].(string)
+	switch event.Action {
+	case "product.created":
+		summary = fmt.Sprintf("Created product %q", name)
+	case "product.updated":
+		if price, ok := metadata["price"].(map[string]interface{}); ok {
+			verb = "product.price_changed"
+			summary = fmt.Sprintf("Changed the price of %q from %.2f to %.2f", name, price["from"], price["to"])
+		} else {
+			summary = fmt.Sprintf("Updated product %q", name)
+		}
+	case "product.deleted":
+		summary = fmt.Sprintf("Deleted product %q", name)
+	case "bundle.created":
+		summary = fmt.Sprintf("Created bundle %q", name)
+	case "bundle.deleted":
+		summary = fmt.Sprintf("Deleted bundle %q", name)
+	case "report.opened":
+		summary = fmt.Sprintf("Reported a %v", metadata["targetType"])
+	default:
+		return
+	}
+
+	entry := &models.ActivityEntry{
+		UserID:       event.EffectiveUserID,
+		Verb:         verb,
+		ResourceType: event.ResourceType,
+		ResourceID:   event.ResourceID,
+		Summary:      summary,
+		CreatedAt:    event.CreatedAt,
+	}
+	if err := s.activityRepo.CreateActivityEntry(ctx, entry); err != nil {
+		// The feed is best-effort; the audit event itself has already been stored
+		logger.Warn("Failed to project audit event into activity feed", zap.Error(err), zap.Uint("auditEventID", event.ID))
+	}
+}
+
+// GetActivity retrieves a page of the user's own activity, newest first
+func (s *activityService) GetActivity(ctx context.Context, userID uint, page, pageSize int) ([]*models.ActivityEntry, int64, error) {
+	entries, total, err := s.activityRepo.GetActivityByUserID(ctx, userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		logger.Error("Failed to get activity in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, 0, fmt.Errorf("failed to retrieve activity: %w", err)
+	}
+	return entries, total, nil
+}
@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// AddressValidationErrorPrefix starts the message of every error caused by the validator rejecting an address
+const AddressValidationErrorPrefix = "invalid address: "
+
+// maxAddressesPerUser caps how many addresses a single user can save
+const maxAddressesPerUser = 20
+
+// AddressService defines the interface for saved address business logic
+type AddressService interface {
+	GetAddresses(ctx context.Context, userID uint) ([]*models.Address, error)
+	GetAddress(ctx context.Context, addressID uint, userID uint) (*models.Address, error)
+	AddAddress(ctx context.Context, userID uint, req *models.AddressRequest) (*models.Address, error)
+	UpdateAddress(ctx context.Context, addressID uint, userID uint, req *models.AddressRequest) (*models.Address, error)
+	DeleteAddress(ctx context.Context, addressID uint, userID uint) error
+}
+
+// addressService implements AddressService
+type addressService struct {
+	addressRepo repository.AddressRepository // Dependency on AddressRepository
+	validator   AddressValidator             // Optional, nil skips validation and normalization
+}
+
+// NewAddressService creates a new AddressService instance. validator may be nil.
+func NewAddressService(addressRepo repository.AddressRepository, validator AddressValidator) AddressService {
+	return &addressService{
+		addressRepo: addressRepo,
+		validator:   validator,
+	}
+}
+
+// GetAddresses retrieves all of a user's saved addresses, default first
+func (s *addressService) GetAddresses(ctx context.Context, userID uint) ([]*models.Address, error) {
+	addresses, err := s.addressRepo.GetAddressesByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get addresses in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to retrieve addresses: %w", err)
+	}
+	return addresses, nil
+}
+
+// GetAddress retrieves one of the user's addresses. Other users' addresses are reported as not found.
+func (s *addressService) GetAddress(ctx context.Context, addressID uint, userID uint) (*models.Address, error) {
+	address, err := s.addressRepo.GetAddressByID(ctx, addressID)
+	if err != nil || address.UserID != userID {
+		logger.Debug("Address not found for user", zap.Uint("addressID", addressID), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("address not found")
+	}
+	return address, nil
+}
+
+// AddAddress saves a new address. The user's first address always becomes the default.
+func (s *addressService) AddAddress(ctx context.Context, userID uint, req *models.AddressRequest) (*models.Address, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	count, err := s.addressRepo.CountAddressesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add address: %w", err)
+	}
+	if count >= maxAddressesPerUser {
+		return nil, fmt.Errorf("address limit reached")
+	}
+
+	address := &models.Address{UserID: userID}
+	applyAddressRequest(address, req)
+	if count == 0 {
+		address.IsDefault = true
+	}
+	if err := s.normalize(ctx, address); err != nil {
+		return nil, err
+	}
+
+	if address.IsDefault {
+		if err := s.addressRepo.ClearDefaultAddress(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to add address: %w", err)
+		}
+	}
+	if err := s.addressRepo.CreateAddress(ctx, address); err != nil {
+		logger.Error("Failed to create address in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to add address: %w", err)
+	}
+
+	logger.Info("Address added successfully", zap.Uint("addressID", address.ID), actor.Field(ctx))
+	return address, nil
+}
+
+// UpdateAddress replaces one of the user's addresses
+func (s *addressService) UpdateAddress(ctx context.Context, addressID uint, userID uint, req *models.AddressRequest) (*models.Address, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	address, err := s.GetAddress(ctx, addressID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasDefault := address.IsDefault
+	applyAddressRequest(address, req)
+	if wasDefault {
+		address.IsDefault = true // The default can only move by marking another address as default
+	}
+	if err := s.normalize(ctx, address); err != nil {
+		return nil, err
+	}
+
+	if address.IsDefault && !wasDefault {
+		if err := s.addressRepo.ClearDefaultAddress(ctx, userID); err != nil {
+			return nil, fmt.Errorf("failed to update address: %w", err)
+		}
+	}
+	if err := s.addressRepo.UpdateAddress(ctx, address); err != nil {
+		logger.Error("Failed to update address in repository", zap.Error(err), zap.Uint("addressID", addressID))
+		return nil, fmt.Errorf("failed to update address: %w", err)
+	}
+
+	logger.Info("Address updated successfully", zap.Uint("addressID", address.ID), actor.Field(ctx))
+	return address, nil
+}
+
+// DeleteAddress removes one of the user's addresses. If it was the default, the oldest remaining address takes over.
+func (s *addressService) DeleteAddress(ctx context.Context, addressID uint, userID uint) error {
+	address, err := s.GetAddress(ctx, addressID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.addressRepo.DeleteAddress(ctx, addressID); err != nil {
+		logger.Error("Failed to delete address in repository", zap.Error(err), zap.Uint("addressID", addressID))
+		return fmt.Errorf("failed to delete address: %w", err)
+	}
+
+	if address.IsDefault {
+		remaining, err := s.addressRepo.GetAddressesByUserID(ctx, userID)
+		if err == nil && len(remaining) > 0 {
+			remaining[0].IsDefault = true
+			err = s.addressRepo.UpdateAddress(ctx, remaining[0])
+		}
+		if err != nil {
+			logger.Warn("Failed to promote a new default address", zap.Error(err), zap.Uint("userID", userID))
+		}
+	}
+
+	logger.Info("Address deleted successfully", zap.Uint("addressID", addressID), actor.Field(ctx))
+	return nil
+}
+
+// normalize runs the configured validator, if any. Validation failures are prefixed so handlers can map them to 422.
+func (s *addressService) normalize(ctx context.Context, address *models.Address) error {
+	if s.validator == nil {
+		return nil
+	}
+	if err := s.validator.Normalize(ctx, address); err != nil {
+		return fmt.Errorf("%s%s", AddressValidationErrorPrefix, err.Error())
+	}
+	return nil
+}
+
+// applyAddressRequest copies the request fields onto an address
+func applyAddressRequest(address *models.Address, req *models.AddressRequest) {
+	address.Label = req.Label
+	address.Recipient = req.Recipient
+	address.Line1 = req.Line1
+	address.Line2 = req.Line2
+	address.City = req.City
+	address.Region = req.Region
+	address.PostalCode = req.PostalCode
+	address.Country = req.Country
+	address.Phone = req.Phone
+	address.IsDefault = req.IsDefault
+}
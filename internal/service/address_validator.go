@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"regexp"
+	"strings"
+)
+
+// AddressValidator validates and normalizes an address before it is saved.
+// Implementations backed by an external verification provider can plug in here;
+// a returned error is shown to the user, so it should say what is wrong.
+type AddressValidator interface {
+	Normalize(ctx context.Context, address *models.Address) error
+}
+
+// countryCodePattern matches an ISO 3166-1 alpha-2 code after upper-casing
+var countryCodePattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// basicAddressValidator performs local checks only, without calling out to a provider
+type basicAddressValidator struct{}
+
+// NewBasicAddressValidator creates an AddressValidator that trims whitespace and checks the country code
+func NewBasicAddressValidator() AddressValidator {
+	return basicAddressValidator{}
+}
+
+// Normalize trims every field, collapses inner whitespace and upper-cases the country and postal code
+func (basicAddressValidator) Normalize(ctx context.Context, address *models.Address) error {
+	for _, field := range []*string{
+		&address.Label, &address.Recipient, &address.Line1, &address.Line2,
+		&address.City, &address.Region, &address.PostalCode, &address.Phone,
+	} {
+		*field = strings.Join(strings.Fields(*field), " ")
+	}
+	address.Country = strings.ToUpper(strings.TrimSpace(address.Country))
+	address.PostalCode = strings.ToUpper(address.PostalCode)
+
+	if !countryCodePattern.MatchString(address.Country) {
+		return fmt.Errorf("country must be an ISO 3166-1 alpha-2 code")
+	}
+	if address.Recipient == "" || address.Line1 == "" || address.City == "" {
+		return fmt.Errorf("recipient, line1 and city must not be blank")
+	}
+	return nil
+}
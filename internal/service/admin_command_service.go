@@ -0,0 +1,180 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/jsonbody"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"reflect"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AdminCommand is a named operational task admins can enqueue through the API, e.g. rebuilding an index, instead of
+// running one-off SQL against production. Commands are declared next to the service they drive and registered
+// with NewAdminCommandService.
+type AdminCommand struct {
+	Name        string // Unique dotted name, e.g. "stats.refresh"
+	Description string
+	// Params returns a pointer to a new params struct, which requests are decoded into and validated with its binding
+	// tags before the command is queued. Nil for commands without parameters.
+	Params func() interface{}
+	// Run performs the command in the background, with the value returned by Params, decoded and validated
+	Run func(ctx context.Context, params interface{}, report ProgressFunc) (interface{}, error)
+}
+
+// AdminCommandService defines the interface for the admin command bus
+type AdminCommandService interface {
+	Commands() []*models.AdminCommandInfo
+	// Enqueue validates the params of a command and runs it as an operation, recording who started it and with what
+	Enqueue(ctx context.Context, userID uint, name string, params json.RawMessage) (*models.Operation, error)
+}
+
+// adminCommandService implements AdminCommandService
+type adminCommandService struct {
+	operationService OperationService // Commands run as operations
+	auditService     AuditService     // Every command enqueued is audited
+	commands         []*AdminCommand  // In registration order
+}
+
+// NewAdminCommandService creates a new AdminCommandService instance offering commands
+func NewAdminCommandService(operationService OperationService, auditService AuditService, commands ...*AdminCommand) AdminCommandService {
+	return &adminCommandService{
+		operationService: operationService,
+		auditService:     auditService,
+		commands:         commands,
+	}
+}
+
+// Commands describes the registered commands and their parameters
+func (s *adminCommandService) Commands() []*models.AdminCommandInfo {
+	infos := make([]*models.AdminCommandInfo, 0, len(s.commands))
+	for _, cmd := range s.commands {
+		infos = append(infos, &models.AdminCommandInfo{
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Params:      describeParams(cmd.newParams()),
+		})
+	}
+	return infos
+}
+
+// Enqueue starts the command name with params. Params that don't decode into the command's params struct or
+// break its rules are refused with a validation error, before anything is queued.
+func (s *adminCommandService) Enqueue(ctx context.Context, userID uint, name string, params json.RawMessage) (*models.Operation, error) {
+	cmd := s.command(name)
+	if cmd == nil {
+		return nil, errors.New("command not found")
+	}
+	decoded := cmd.newParams()
+	if len(bytes.TrimSpace(params)) == 0 {
+		params = json.RawMessage(`{}`)
+	}
+	limits := jsonbody.DefaultLimits
+	limits.DisallowUnknownFields = true
+	if err := jsonbody.Decode(bytes.NewReader(params), decoded, limits); err != nil {
+		var bodyErr *jsonbody.Error
+		if !errors.As(err, &bodyErr) {
+			return nil, err
+		}
+		message := "params: " + bodyErr.Message // Messages are written about the request body
+		if strings.Contains(bodyErr.Message, "request body") {
+			message = strings.Replace(bodyErr.Message, "request body", "params", 1)
+		}
+		return nil, errors.New(validation.ErrorPrefix + message)
+	}
+	if err := validation.Struct(decoded); err != nil {
+		return nil, err
+	}
+
+	op, err := s.operationService.Start(ctx, userID, "command:"+cmd.Name, func(ctx context.Context, report ProgressFunc) (interface{}, error) {
+		return cmd.Run(ctx, decoded, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := s.auditService.Record(ctx, "command.enqueued", "operation", op.ID, map[string]interface{}{
+		"command": cmd.Name,
+		"params":  decoded,
+	}); err != nil {
+		logger.Warn("Admin command audit event was not recorded", zap.Error(err), zap.String("command", cmd.Name), zap.Uint("operationID", op.ID))
+	}
+	logger.Info("Admin command enqueued", zap.String("command", cmd.Name), zap.Uint("operationID", op.ID), zap.Uint("userID", userID))
+	return op, nil
+}
+
+// command returns the registered command named name, or nil
+func (s *adminCommandService) command(name string) *AdminCommand {
+	for _, cmd := range s.commands {
+		if cmd.Name == name {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// newParams returns a new params struct for the command; commands without parameters accept an empty object
+func (c *AdminCommand) newParams() interface{} {
+	if c.Params == nil {
+		return &struct{}{}
+	}
+	return c.Params()
+}
+
+// describeParams lists the JSON fields of a params struct with their types and binding rules
+func describeParams(params interface{}) []*models.AdminCommandParam {
+	described := []*models.AdminCommandParam{}
+	t := reflect.TypeOf(params)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		rules := field.Tag.Get("binding")
+		described = append(described, &models.AdminCommandParam{
+			Name:     name,
+			Type:     paramType(field.Type),
+			Required: strings.Contains(","+rules+",", ",required,"),
+			Rules:    rules,
+		})
+	}
+	return described
+}
+
+// paramType names the JSON type a parameter is decoded from
+func paramType(t reflect.Type) string {
+	if t.Kind() == reflect.Pointer {
+		return paramType(t.Elem())
+	}
+	if t == reflect.TypeOf(time.Time{}) {
+		return "time"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AnnouncementService defines the interface for announcement business logic
+type AnnouncementService interface {
+	PublishAnnouncement(ctx context.Context, adminID uint, req *models.CreateAnnouncementRequest) (*models.Announcement, error)
+	GetAnnouncementsForRole(ctx context.Context, role string) ([]*models.Announcement, error)
+}
+
+// announcementService implements AnnouncementService
+type announcementService struct {
+	announcementRepo repository.AnnouncementRepository // Dependency on AnnouncementRepository
+}
+
+// NewAnnouncementService creates a new AnnouncementService instance
+func NewAnnouncementService(announcementRepo repository.AnnouncementRepository) AnnouncementService {
+	return &announcementService{
+		announcementRepo: announcementRepo,
+	}
+}
+
+// PublishAnnouncement stores a new announcement published by an admin
+func (s *announcementService) PublishAnnouncement(ctx context.Context, adminID uint, req *models.CreateAnnouncementRequest) (*models.Announcement, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expiry must be in the future")
+	}
+
+	announcement := &models.Announcement{
+		Title:       req.Title,
+		Body:        req.Body,
+		Audience:    req.Audience,
+		PublishedBy: adminID,
+		ExpiresAt:   req.ExpiresAt,
+	}
+	if err := s.announcementRepo.CreateAnnouncement(ctx, announcement); err != nil {
+		logger.Error("Failed to create announcement in repository", zap.Error(err), zap.Uint("adminID", adminID))
+		return nil, fmt.Errorf("failed to publish announcement: %w", err)
+	}
+
+	logger.Info("Announcement published", zap.Uint("announcementID", announcement.ID), zap.String("audience", announcement.Audience), actor.Field(ctx))
+	return announcement, nil
+}
+
+// GetAnnouncementsForRole retrieves the active announcements visible to users with the given role
+func (s *announcementService) GetAnnouncementsForRole(ctx context.Context, role string) ([]*models.Announcement, error) {
+	announcements, err := s.announcementRepo.GetActiveAnnouncements(ctx, role, time.Now())
+	if err != nil {
+		logger.Error("Failed to get announcements in repository", zap.Error(err), zap.String("role", role))
+		return nil, fmt.Errorf("failed to retrieve announcements: %w", err)
+	}
+	return announcements, nil
+}
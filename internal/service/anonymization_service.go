@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// AnonymizationService defines the interface for the right-to-be-forgotten anonymization of deleted users
+type AnonymizationService interface {
+	CheckAnonymizable(ctx context.Context, userID uint) error
+	AnonymizeUser(ctx context.Context, userID uint, report ProgressFunc) (*models.AnonymizationReport, error)
+}
+
+// anonymizationService implements AnonymizationService
+type anonymizationService struct {
+	anonymizationRepo repository.AnonymizationRepository // Dependency on AnonymizationRepository
+	userRepo          repository.UserRepository          // Tells live users apart from missing ones
+	auditService      AuditService                       // Anonymizations are recorded in the audit log
+}
+
+// NewAnonymizationService creates a new AnonymizationService instance
+func NewAnonymizationService(anonymizationRepo repository.AnonymizationRepository, userRepo repository.UserRepository, auditService AuditService) AnonymizationService {
+	return &anonymizationService{
+		anonymizationRepo: anonymizationRepo,
+		userRepo:          userRepo,
+		auditService:      auditService,
+	}
+}
+
+// CheckAnonymizable verifies that a user exists, is deleted and has not been anonymized yet
+func (s *anonymizationService) CheckAnonymizable(ctx context.Context, userID uint) error {
+	_, err := s.getAnonymizable(ctx, userID)
+	return err
+}
+
+// getAnonymizable retrieves a deleted user that has not been anonymized yet
+func (s *anonymizationService) getAnonymizable(ctx context.Context, userID uint) (*models.User, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err == nil {
+		return nil, errors.New("user must be deleted before being anonymized")
+	}
+	user, err := s.anonymizationRepo.GetDeletedUserByID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.AnonymizedAt != nil {
+		return nil, errors.New("user is already anonymized")
+	}
+	return user, nil
+}
+
+// AnonymizeUser scrubs a deleted user's personal data everywhere it is stored, keeping rows other records or
+// aggregates depend on. It is all or nothing, and the account can no longer be restored by re-registration.
+// Audit events already shipped to an external collector are out of reach and must be handled there.
+func (s *anonymizationService) AnonymizeUser(ctx context.Context, userID uint, report ProgressFunc) (*models.AnonymizationReport, error) {
+	user, err := s.getAnonymizable(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.anonymizationRepo.AnonymizeUser(ctx, user, throttleKey(user.Email))
+	if err != nil {
+		logger.Error("Failed to anonymize user in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to anonymize user: %w", err)
+	}
+	repository.EvictUser(s.userRepo, userID) // The users row was rewritten in the anonymization's transaction
+	if report != nil {
+		report(100)
+	}
+
+	// The event carries counts only, it must not bring back what was just removed
+	if err := s.auditService.Record(ctx, "user.anonymized", "user", userID, map[string]interface{}{
+		"auditEvents":     result.AuditEvents,
+		"comments":        result.Comments,
+		"reports":         result.Reports,
+		"addresses":       result.Addresses,
+		"savedSearches":   result.SavedSearches,
+		"activityEntries": result.ActivityEntries,
+		"tokens":          result.Tokens,
+		"loginAttempts":   result.LoginAttempts,
+	}); err != nil {
+		logger.Warn("User anonymization audit event was not recorded", zap.Error(err), zap.Uint("userID", userID))
+	}
+	logger.Info("User anonymized successfully", zap.Uint("userID", userID), actor.Field(ctx))
+	return result, nil
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxPendingAPIUsage caps the users, tokens and days counted in memory between flushes, as maxPendingProductViews
+const maxPendingAPIUsage = 100000
+
+// apiUsageKey identifies the counters of a user's credential on a day
+type apiUsageKey struct {
+	userID  uint
+	tokenID uint
+	day     time.Time
+}
+
+// APIUsageService defines the interface for per-user API usage. Requests are counted in memory and written in
+// batches, as product views, so counting a request doesn't cost a write.
+type APIUsageService interface {
+	// RecordRequest counts a request of an authenticated caller, for the acting user and their personal access token
+	RecordRequest(a actor.Actor, status, cost int)
+	GetUsage(ctx context.Context, userID uint, from, to time.Time) (*models.APIUsageResponse, error)
+	GetUserUsage(ctx context.Context, from, to time.Time, page, pageSize int) (*models.UserAPIUsageResponse, error)
+	Run(ctx context.Context) // Writes the counted requests every stats.usageFlushInterval, and once more on shutdown
+}
+
+// apiUsageService implements APIUsageService
+type apiUsageService struct {
+	statsRepo repository.StatsRepository
+	userRepo  repository.UserRepository
+	cfg       config.StatsConfig
+	cost      config.CostLimitConfig // The budget usage is reported against
+	readOnly  *readonly.Mode
+
+	mu      sync.Mutex
+	pending map[apiUsageKey]*models.APIUsageCount // Requests counted since the last flush
+	dropped int64                                 // Requests dropped since the last warning, see maxPendingAPIUsage
+}
+
+// NewAPIUsageService creates a new APIUsageService instance
+func NewAPIUsageService(statsRepo repository.StatsRepository, userRepo repository.UserRepository, cfg config.StatsConfig, cost config.CostLimitConfig, readOnly *readonly.Mode) APIUsageService {
+	return &apiUsageService{
+		statsRepo: statsRepo,
+		userRepo:  userRepo,
+		cfg:       cfg,
+		cost:      cost,
+		readOnly:  readOnly,
+		pending:   map[apiUsageKey]*models.APIUsageCount{},
+	}
+}
+
+// RecordRequest counts a request on the current day (UTC)
+func (s *apiUsageService) RecordRequest(a actor.Actor, status, cost int) {
+	key := apiUsageKey{userID: a.UserID, tokenID: a.TokenID, day: time.Now().UTC().Truncate(24 * time.Hour)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count, ok := s.pending[key]
+	if !ok {
+		if len(s.pending) >= maxPendingAPIUsage {
+			s.dropped++
+			return
+		}
+		count = &models.APIUsageCount{UserID: key.userID, TokenID: key.tokenID, Day: key.day}
+		s.pending[key] = count
+	}
+	count.Requests++
+	count.Cost += int64(cost)
+	switch {
+	case status >= http.StatusInternalServerError:
+		count.ServerErrors++
+	case status >= http.StatusBadRequest:
+		count.ClientErrors++
+		if status == http.StatusTooManyRequests {
+			count.Throttled++
+		}
+	}
+}
+
+// GetUsage returns the API usage of a user from from's day to to's day included, with the cost budget it is
+// charged against
+func (s *apiUsageService) GetUsage(ctx context.Context, userID uint, from, to time.Time) (*models.APIUsageResponse, error) {
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
+		logger.Debug("User not found for API usage", zap.Error(err), zap.Uint("userID", userID))
+		return nil, errors.New("user not found")
+	}
+
+	days, err := s.statsRepo.GetDailyAPIUsage(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := s.statsRepo.GetCredentialAPIUsage(ctx, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if days == nil {
+		days = []*models.DailyAPIUsage{}
+	}
+	if credentials == nil {
+		credentials = []*models.CredentialAPIUsage{}
+	}
+
+	resp := &models.APIUsageResponse{
+		UserID:      userID,
+		From:        from.Format(time.DateOnly),
+		To:          to.Format(time.DateOnly),
+		Days:        days,
+		Credentials: credentials,
+	}
+	for _, d := range days {
+		d.SetErrorRate()
+		resp.Total.Requests += d.Requests
+		resp.Total.ClientErrors += d.ClientErrors
+		resp.Total.ServerErrors += d.ServerErrors
+		resp.Total.Throttled += d.Throttled
+		resp.Total.Cost += d.Cost
+	}
+	resp.Total.SetErrorRate()
+	for _, c := range credentials {
+		c.SetErrorRate()
+	}
+	if s.cost.Budget > 0 {
+		resp.Limit = &models.APIUsageLimit{Budget: s.cost.Budget, Window: s.cost.Window.String(), DryRun: s.cost.DryRun}
+	}
+	return resp, nil
+}
+
+// GetUserUsage returns a page of per-user API usage from from's day to to's day included, most requests first
+func (s *apiUsageService) GetUserUsage(ctx context.Context, from, to time.Time, page, pageSize int) (*models.UserAPIUsageResponse, error) {
+	items, total, err := s.statsRepo.GetUserAPIUsage(ctx, from, to, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []*models.UserAPIUsage{}
+	}
+	for _, item := range items {
+		item.SetErrorRate()
+	}
+	return &models.UserAPIUsageResponse{
+		From:     from.Format(time.DateOnly),
+		To:       to.Format(time.DateOnly),
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// Run writes the counted requests every stats.usageFlushInterval until ctx is cancelled. Requests are kept in
+// memory while the instance is read-only or a write fails, and retried at the next flush.
+func (s *apiUsageService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if s.readOnly.Enabled() {
+				return
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), productViewFlushTimeout)
+			if err := s.flush(flushCtx); err != nil {
+				logger.Warn("API usage counted since the last flush was lost on shutdown", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-time.After(s.cfg.UsageFlushInterval):
+		}
+
+		if s.readOnly.Enabled() {
+			continue
+		}
+		if err := s.flush(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to write API usage, retrying at the next flush", zap.Error(err))
+		}
+	}
+}
+
+// flush writes the requests counted since the last flush. On failure they are counted again.
+func (s *apiUsageService) flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending, dropped := s.pending, s.dropped
+	s.pending, s.dropped = map[apiUsageKey]*models.APIUsageCount{}, 0
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Warn("API requests were not counted, too many callers between flushes", zap.Int64("dropped", dropped), zap.Int("limit", maxPendingAPIUsage))
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	counts := make([]models.APIUsageCount, 0, len(pending))
+	for _, count := range pending {
+		counts = append(counts, *count)
+	}
+	if err := s.statsRepo.AddAPIUsage(ctx, counts); err != nil {
+		s.requeue(pending)
+		return fmt.Errorf("failed to write API usage: %w", err)
+	}
+	logger.Debug("API usage written", zap.Int("counts", len(counts)))
+	return nil
+}
+
+// requeue counts requests that could not be written again, within maxPendingAPIUsage
+func (s *apiUsageService) requeue(pending map[apiUsageKey]*models.APIUsageCount) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, count := range pending {
+		current, ok := s.pending[key]
+		if !ok {
+			if len(s.pending) >= maxPendingAPIUsage {
+				s.dropped += count.Requests
+				continue
+			}
+			s.pending[key] = count
+			continue
+		}
+		current.Requests += count.Requests
+		current.ClientErrors += count.ClientErrors
+		current.ServerErrors += count.ServerErrors
+		current.Throttled += count.Throttled
+		current.Cost += count.Cost
+	}
+}
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/auditsink"
+	"gotemplate/pkg/logger"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// auditSettleDelay holds back very recent events so concurrent inserts can't commit behind the cursor
+const auditSettleDelay = 2 * time.Second
+
+// AuditForwarder ships audit events to an external sink with at-least-once delivery.
+// The audit table itself is the buffer: events are read in ID order from a persisted cursor,
+// and the cursor only advances after the sink accepted the batch. A slow or failing sink
+// therefore just delays forwarding (with exponential backoff) instead of growing memory.
+type AuditForwarder struct {
+	auditRepo repository.AuditRepository
+	sink      auditsink.Sink
+	name      string
+	cfg       config.AuditForwarderConfig
+
+	mu          sync.Mutex // Guards the run bookkeeping below, read by Status
+	lastRun     time.Time
+	lastSuccess time.Time
+	lastErr     error
+}
+
+// NewAuditForwarder creates a forwarder for the configured sink
+func NewAuditForwarder(auditRepo repository.AuditRepository, sink auditsink.Sink, cfg config.AuditForwarderConfig) *AuditForwarder {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.MaxBackoff < cfg.PollInterval {
+		cfg.MaxBackoff = cfg.PollInterval
+	}
+	return &AuditForwarder{
+		auditRepo: auditRepo,
+		sink:      sink,
+		name:      cfg.Sink,
+		cfg:       cfg,
+	}
+}
+
+// Run forwards events until ctx is cancelled
+func (f *AuditForwarder) Run(ctx context.Context) {
+	logger.Info("Audit forwarder started", zap.String("sink", f.name), zap.Int("batchSize", f.cfg.BatchSize))
+	defer func() {
+		if err := f.sink.Close(); err != nil {
+			logger.Warn("Failed to close audit sink", zap.Error(err), zap.String("sink", f.name))
+		}
+		logger.Info("Audit forwarder stopped", zap.String("sink", f.name))
+	}()
+
+	backoff := f.cfg.PollInterval
+	for {
+		sent, err := f.forwardBatch(ctx)
+		f.recordRun(err)
+
+		var wait time.Duration
+		switch {
+		case err != nil:
+			logger.Warn("Audit forwarding failed, will retry", zap.Error(err), zap.String("sink", f.name), zap.Duration("retryIn", backoff))
+			wait = backoff
+			backoff *= 2
+			if backoff > f.cfg.MaxBackoff {
+				backoff = f.cfg.MaxBackoff
+			}
+		case sent == f.cfg.BatchSize:
+			backoff = f.cfg.PollInterval
+			wait = 0 // More events are likely waiting, keep draining
+		default:
+			backoff = f.cfg.PollInterval
+			wait = f.cfg.PollInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// recordRun notes the outcome of a forwarding attempt for Status
+func (f *AuditForwarder) recordRun(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lastRun = time.Now()
+	f.lastErr = err
+	if err == nil {
+		f.lastSuccess = f.lastRun
+	}
+}
+
+// Status reports the forwarder's recent runs and how many events are waiting to be forwarded
+func (f *AuditForwarder) Status(ctx context.Context) (*models.JobStatus, *models.QueueStatus) {
+	f.mu.Lock()
+	job := &models.JobStatus{LastRun: timeOrNil(f.lastRun), LastSuccess: timeOrNil(f.lastSuccess)}
+	if f.lastErr != nil {
+		job.LastError = f.lastErr.Error()
+	}
+	f.mu.Unlock()
+
+	queue := &models.QueueStatus{}
+	lastID, err := f.auditRepo.GetForwardCursor(ctx, f.name)
+	if err == nil {
+		queue.Pending, err = f.auditRepo.CountAuditEventsAfter(ctx, lastID)
+	}
+	if err != nil {
+		queue.Error = err.Error()
+	}
+	return job, queue
+}
+
+// auditRewindAttempts bounds how often Redeliver tries again when batches keep moving the cursor under it
+const auditRewindAttempts = 5
+
+// Redeliver moves the cursor back to the last event created before from, so the events since are forwarded again,
+// e.g. after the sink lost them. The cursor never moves forward. The rewind is a compare-and-set: a batch being
+// forwarded meanwhile can't save its position over it, and a batch that advanced the cursor first is rewound too.
+func (f *AuditForwarder) Redeliver(ctx context.Context, from time.Time) (*models.AuditRedeliveryResult, error) {
+	lastID, err := f.auditRepo.GetLastAuditEventIDBefore(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	var result *models.AuditRedeliveryResult
+	for attempt := 1; ; attempt++ {
+		current, err := f.auditRepo.GetForwardCursor(ctx, f.name)
+		if err != nil {
+			return nil, err
+		}
+		result = &models.AuditRedeliveryResult{Sink: f.name, PreviousEventID: current, LastEventID: current}
+		if lastID >= current {
+			break
+		}
+		moved, err := f.auditRepo.MoveForwardCursor(ctx, f.name, current, lastID)
+		if err != nil {
+			return nil, err
+		}
+		if moved {
+			result.LastEventID = lastID
+			logger.Info("Audit forward cursor rewound", zap.String("sink", f.name), zap.Uint("from", current), zap.Uint("to", lastID))
+			break
+		}
+		if attempt == auditRewindAttempts {
+			return nil, errors.New("audit forward cursor kept moving, try again")
+		}
+	}
+	if result.Pending, err = f.auditRepo.CountAuditEventsAfter(ctx, result.LastEventID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// auditRedeliverParams are the parameters of the audit.redeliver command
+type auditRedeliverParams struct {
+	From time.Time `json:"from" binding:"required"` // Events created from this time on are forwarded again
+}
+
+// AuditRedeliverCommand is the admin command forwarding the audit events since a time to the sink again
+func AuditRedeliverCommand(forwarder *AuditForwarder) *AdminCommand {
+	return &AdminCommand{
+		Name:        "audit.redeliver",
+		Description: "Forward the audit events created since from to the " + forwarder.name + " sink again",
+		Params:      func() interface{} { return &auditRedeliverParams{} },
+		Run: func(ctx context.Context, params interface{}, _ ProgressFunc) (interface{}, error) {
+			return forwarder.Redeliver(ctx, params.(*auditRedeliverParams).From)
+		},
+	}
+}
+
+// forwardBatch sends the next batch after the cursor and advances the cursor on success
+func (f *AuditForwarder) forwardBatch(ctx context.Context) (int, error) {
+	lastID, err := f.auditRepo.GetForwardCursor(ctx, f.name)
+	if err != nil {
+		return 0, err
+	}
+	events, err := f.auditRepo.GetAuditEventsAfter(ctx, lastID, time.Now().Add(-auditSettleDelay), f.cfg.BatchSize)
+	if err != nil || len(events) == 0 {
+		return 0, err
+	}
+
+	if err := f.sink.Send(ctx, events); err != nil {
+		return 0, err
+	}
+
+	// If saving the cursor fails the batch is sent again later, which is what at-least-once allows. If it was
+	// rewound by Redeliver meanwhile, the rewind wins and the batch is sent again too.
+	newLastID := events[len(events)-1].ID
+	moved, err := f.auditRepo.MoveForwardCursor(ctx, f.name, lastID, newLastID)
+	if err != nil {
+		return 0, err
+	}
+	if !moved {
+		logger.Info("Audit forward cursor was moved while forwarding, keeping it", zap.String("sink", f.name), zap.Uint("lastEventID", newLastID))
+		return len(events), nil
+	}
+	logger.Debug("Audit batch forwarded", zap.String("sink", f.name), zap.Int("count", len(events)), zap.Uint("lastEventID", newLastID))
+	return len(events), nil
+}
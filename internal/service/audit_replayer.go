@@ -0,0 +1,166 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// auditExportBatchSize is how many audit events an export reads per query
+const auditExportBatchSize = 500
+
+// ExportAuditEvents passes the audit events created from from (inclusive) to to (exclusive) to fn in ID order,
+// without the personal data keys of their metadata, so they can leave production, e.g. to be replayed
+func ExportAuditEvents(ctx context.Context, auditRepo repository.AuditRepository, from, to time.Time, fn func(*models.AuditEvent) error) error {
+	var afterID uint
+	for {
+		events, err := auditRepo.GetAuditEventsBetween(ctx, from, to, afterID, auditExportBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, event := range events {
+			event.Metadata = models.AuditMetadataWithoutPII(event.Metadata)
+			if err := fn(event); err != nil {
+				return err
+			}
+			afterID = event.ID
+		}
+		if len(events) < auditExportBatchSize {
+			return nil
+		}
+	}
+}
+
+// errNotReplayable is returned for actions whose events don't record enough to perform them again
+var errNotReplayable = errors.New("not replayable")
+
+// replayKey identifies a resource of the recorded environment
+type replayKey struct {
+	resourceType string
+	id           uint
+}
+
+// AuditReplayer performs recorded actions again through the service layer, one event at a time in the order
+// given, so a production incident can be reproduced in a test environment. Resources created during the replay
+// get new IDs; later events about them are mapped to those. Resources that existed before the window keep their
+// IDs, so the environment should start from a copy of the data taken before it, e.g. a staging clone.
+//
+// Audit events only record what auditors need, so replays are approximate: created products have no
+// description, and descriptions can't be cleared. Actions that can't be redone at all are skipped.
+type AuditReplayer struct {
+	productService ProductService
+	reportService  ReportService
+	ids            map[replayKey]uint // Recorded IDs of resources created during the replay, to their new IDs
+}
+
+// NewAuditReplayer creates a replayer for one window of events
+func NewAuditReplayer(productService ProductService, reportService ReportService) *AuditReplayer {
+	return &AuditReplayer{
+		productService: productService,
+		reportService:  reportService,
+		ids:            map[replayKey]uint{},
+	}
+}
+
+// Replay performs the action of an event again, as its acting and effective users
+func (r *AuditReplayer) Replay(ctx context.Context, event *models.AuditEvent) *models.AuditReplayResult {
+	result := &models.AuditReplayResult{EventID: event.ID, Action: event.Action}
+	var metadata map[string]interface{}
+	if err := json.Unmarshal([]byte(event.Metadata), &metadata); err != nil {
+		result.Outcome, result.Detail = models.AuditReplayFailed, "invalid metadata: "+err.Error()
+		return result
+	}
+	if event.EffectiveUserID == 0 {
+		result.Outcome, result.Detail = models.AuditReplaySkipped, "performed by the system"
+		return result
+	}
+	ctx = actor.WithActor(ctx, actor.Actor{UserID: event.ActingUserID, EffectiveUserID: event.EffectiveUserID, Via: actor.ViaReplay})
+
+	id, err := r.replay(ctx, event, metadata)
+	switch {
+	case errors.Is(err, errNotReplayable):
+		result.Outcome, result.Detail = models.AuditReplaySkipped, "action is not replayable"
+	case err != nil:
+		result.Outcome, result.Detail = models.AuditReplayFailed, err.Error()
+		logger.Debug("Audit event replay failed", zap.Error(err), zap.Uint("eventID", event.ID), zap.String("action", event.Action))
+	default:
+		result.Outcome, result.ResourceID = models.AuditReplayApplied, id
+	}
+	return result
+}
+
+// replay dispatches an event to the service performing its action, and returns the ID of the resource acted on
+func (r *AuditReplayer) replay(ctx context.Context, event *models.AuditEvent, metadata map[string]interface{}) (uint, error) {
+	userID := event.EffectiveUserID
+	switch event.Action {
+	case "product.created":
+		name, _ := metadata["name"].(string)
+		price, _ := metadata["price"].(float64)
+		product, _, err := r.productService.AddProduct(ctx, userID, &models.AddProductRequest{Name: name, Price: price})
+		if err != nil {
+			return 0, err
+		}
+		r.ids[replayKey{"product", event.ResourceID}] = product.ID
+		return product.ID, nil
+
+	case "product.updated":
+		req := &models.UpdateProductRequest{}
+		if _, renamed := metadata["previousName"]; renamed {
+			req.Name, _ = metadata["name"].(string)
+		}
+		req.Description, _ = changedTo(metadata, "description").(string)
+		req.Price, _ = changedTo(metadata, "price").(float64)
+		productID := r.id("product", event.ResourceID)
+		if _, err := r.productService.UpdateProduct(ctx, productID, userID, req, nil); err != nil {
+			return 0, err
+		}
+		return productID, nil
+
+	case "product.deleted":
+		productID := r.id("product", event.ResourceID)
+		return productID, r.productService.DeleteProduct(ctx, productID, userID, nil)
+
+	case "report.opened":
+		targetType, _ := metadata["targetType"].(string)
+		targetID, _ := metadata["targetId"].(float64)
+		reason, _ := metadata["reason"].(string)
+		report, err := r.reportService.ReportContent(ctx, targetType, r.id(targetType, uint(targetID)), userID, &models.CreateReportRequest{Reason: reason})
+		if err != nil {
+			return 0, err
+		}
+		r.ids[replayKey{"report", event.ResourceID}] = report.ID
+		return report.ID, nil
+
+	case "report." + models.ReportStatusActioned, "report." + models.ReportStatusDismissed:
+		note, _ := metadata["note"].(string)
+		reportID := r.id("report", event.ResourceID)
+		req := &models.ResolveReportRequest{Status: strings.TrimPrefix(event.Action, "report."), Note: note}
+		if _, err := r.reportService.ResolveReport(ctx, reportID, event.ActingUserID, req); err != nil {
+			return 0, err
+		}
+		return reportID, nil
+	}
+	return 0, errNotReplayable
+}
+
+// id maps the recorded ID of a resource to its ID in the replay environment
+func (r *AuditReplayer) id(resourceType string, recorded uint) uint {
+	if id, ok := r.ids[replayKey{resourceType, recorded}]; ok {
+		return id
+	}
+	return recorded
+}
+
+// changedTo returns the new value of a field recorded as {"from": ..., "to": ...}, or nil if it didn't change
+func changedTo(metadata map[string]interface{}, field string) interface{} {
+	change, _ := metadata[field].(map[string]interface{})
+	return change["to"]
+}
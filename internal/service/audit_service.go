@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/geoip"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// AuditService defines the interface for recording and querying audit events
+type AuditService interface {
+	Record(ctx context.Context, action string, resourceType string, resourceID uint, metadata map[string]interface{}) error
+	// RecordSettingChange records a change to a setting at runtime, see models.AuditSettingChanged. Every setting
+	// that can be changed without a restart must go through it, with its configuration key.
+	RecordSettingChange(ctx context.Context, setting string, from, to interface{}, reason string) error
+	GetEvents(ctx context.Context, query *models.AuditEventQuery, page, pageSize int) ([]*models.AuditEvent, int64, error)
+}
+
+// AuditSubscriber is notified synchronously after every audit event is stored,
+// so read models (e.g. activity feeds) can be derived from the audit stream
+type AuditSubscriber interface {
+	OnAuditEvent(ctx context.Context, event *models.AuditEvent, metadata map[string]interface{})
+}
+
+// auditService implements AuditService
+type auditService struct {
+	auditRepo   repository.AuditRepository // Dependency on AuditRepository
+	subscribers []AuditSubscriber          // Projections fed from the audit stream
+}
+
+// NewAuditService creates a new AuditService instance
+func NewAuditService(auditRepo repository.AuditRepository, subscribers ...AuditSubscriber) AuditService {
+	return &auditService{
+		auditRepo:   auditRepo,
+		subscribers: subscribers,
+	}
+}
+
+// Record stores an audit event attributed to the actor in ctx (or to the system when there is none)
+func (s *auditService) Record(ctx context.Context, action string, resourceType string, resourceID uint, metadata map[string]interface{}) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	if country := geoip.FromContext(ctx); country != "" {
+		if _, ok := metadata["country"]; !ok {
+			metadata["country"] = country // Client country of the request, when a GeoIP database is configured
+		}
+	}
+	encoded, err := json.Marshal(metadata)
+	if err != nil {
+		logger.Error("Failed to encode audit metadata", zap.Error(err), zap.String("action", action))
+		return fmt.Errorf("failed to encode audit metadata: %w", err)
+	}
+
+	event := &models.AuditEvent{
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Metadata:     string(encoded),
+		CreatedAt:    time.Now(),
+	}
+	if a, ok := actor.FromContext(ctx); ok {
+		event.ActingUserID = a.UserID
+		event.EffectiveUserID = a.EffectiveUserID
+	}
+
+	if err := s.auditRepo.CreateAuditEvent(ctx, event); err != nil {
+		logger.Error("Failed to record audit event", zap.Error(err), zap.String("action", action), zap.String("resourceType", resourceType), zap.Uint("resourceID", resourceID), actor.Field(ctx))
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+
+	logger.Debug("Audit event recorded", zap.String("action", action), zap.String("resourceType", resourceType), zap.Uint("resourceID", resourceID), actor.Field(ctx))
+
+	for _, sub := range s.subscribers {
+		sub.OnAuditEvent(ctx, event, metadata)
+	}
+	return nil
+}
+
+// RecordSettingChange stores a setting.changed event attributed to the actor in ctx
+func (s *auditService) RecordSettingChange(ctx context.Context, setting string, from, to interface{}, reason string) error {
+	return s.Record(ctx, models.AuditSettingChanged, "setting", 0, map[string]interface{}{
+		"setting": setting,
+		"from":    from,
+		"to":      to,
+		"reason":  reason,
+	})
+}
+
+// GetEvents retrieves a page of the audit log, newest first
+func (s *auditService) GetEvents(ctx context.Context, query *models.AuditEventQuery, page, pageSize int) ([]*models.AuditEvent, int64, error) {
+	events, total, err := s.auditRepo.GetAuditEvents(ctx, query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		logger.Error("Failed to get audit events in repository", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to retrieve audit log: %w", err)
+	}
+	return events, total, nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/logger"
+	"os"
+	"sync"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// BackupResult is the outcome of a backup run, the result of its operation
+type BackupResult struct {
+	Backup   *database.BackupManifest `json:"backup"`
+	Verified bool                     `json:"verified"`
+	Pruned   []string                 `json:"pruned"` // Older backups deleted by retention
+}
+
+// BackupService defines the interface for admin-triggered database backups
+type BackupService interface {
+	RunBackup(ctx context.Context, report ProgressFunc) (*BackupResult, error)
+	ListBackups(ctx context.Context) ([]*database.BackupManifest, error)
+	VerifyBackup(ctx context.Context, name string) (*database.BackupManifest, error)
+}
+
+// backupService implements BackupService
+type backupService struct {
+	db           *gorm.DB
+	cfg          config.BackupConfig
+	auditService AuditService // Backups are recorded in the audit log, they hold every user's data
+	running      sync.Mutex   // One backup at a time per instance
+}
+
+// NewBackupService creates a new BackupService instance
+func NewBackupService(db *gorm.DB, cfg config.BackupConfig, auditService AuditService) BackupService {
+	return &backupService{
+		db:           db,
+		cfg:          cfg,
+		auditService: auditService,
+	}
+}
+
+// RunBackup writes a backup, verifies it against its checksums and applies retention.
+// Retention only runs after a verified backup, so a failing backup never costs an older good one.
+func (s *backupService) RunBackup(ctx context.Context, report ProgressFunc) (*BackupResult, error) {
+	if !s.running.TryLock() {
+		return nil, errors.New("a backup is already running")
+	}
+	defer s.running.Unlock()
+
+	manifest, err := database.Backup(ctx, s.db, s.cfg.Dir)
+	if err != nil {
+		logger.Error("Database backup failed", zap.Error(err))
+		return nil, fmt.Errorf("backup failed: %w", err)
+	}
+	if report != nil {
+		report(80)
+	}
+	if _, err := database.VerifyBackup(s.cfg.Dir, manifest.Name); err != nil {
+		logger.Error("Database backup failed verification", zap.Error(err), zap.String("backup", manifest.Name))
+		return nil, fmt.Errorf("backup %s failed verification: %w", manifest.Name, err)
+	}
+	result := &BackupResult{Backup: manifest, Verified: true}
+	if report != nil {
+		report(90)
+	}
+
+	if result.Pruned, err = database.PruneBackups(s.cfg.Dir, s.cfg.Keep); err != nil {
+		// The new backup is fine; retention is retried after the next one
+		logger.Warn("Backup retention failed", zap.Error(err))
+	}
+
+	if err := s.auditService.Record(ctx, "backup.created", "backup", 0, map[string]interface{}{
+		"name":   manifest.Name,
+		"tables": len(manifest.Tables),
+		"bytes":  manifest.Bytes,
+		"pruned": result.Pruned,
+	}); err != nil {
+		logger.Warn("Backup audit event was not recorded", zap.Error(err))
+	}
+	logger.Info("Database backup finished", zap.String("backup", manifest.Name), zap.Int("pruned", len(result.Pruned)), actor.Field(ctx))
+	return result, nil
+}
+
+// ListBackups returns the complete backups, newest first
+func (s *backupService) ListBackups(ctx context.Context) ([]*database.BackupManifest, error) {
+	manifests, err := database.ListBackups(s.cfg.Dir)
+	if err != nil {
+		logger.Error("Failed to list backups", zap.Error(err))
+		return nil, fmt.Errorf("failed to list backups: %w", err)
+	}
+	return manifests, nil
+}
+
+// VerifyBackup checks a stored backup against its checksums, e.g. before relying on it for a restore
+func (s *backupService) VerifyBackup(ctx context.Context, name string) (*database.BackupManifest, error) {
+	manifest, err := database.VerifyBackup(s.cfg.Dir, name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.New("backup not found")
+	}
+	if err != nil {
+		logger.Warn("Backup failed verification", zap.Error(err), zap.String("backup", name))
+		return nil, fmt.Errorf("backup failed verification: %w", err)
+	}
+	return manifest, nil
+}
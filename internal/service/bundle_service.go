@@ -0,0 +1,146 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// BundleService defines the interface for product bundle business logic
+type BundleService interface {
+	CreateBundle(ctx context.Context, userID uint, req *models.CreateBundleRequest) (*models.Bundle, error)
+	GetBundle(ctx context.Context, bundleID uint) (*models.Bundle, error)
+	GetBundlesByOwner(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Bundle, error)
+	DeleteBundle(ctx context.Context, bundleID uint, userID uint) error
+}
+
+// bundleService implements BundleService
+type bundleService struct {
+	bundleRepo   repository.BundleRepository  // Dependency on BundleRepository
+	productRepo  repository.ProductRepository // Dependency on ProductRepository, to validate components
+	auditService AuditService                 // Bundle mutations are recorded in the audit log
+}
+
+// NewBundleService creates a new BundleService instance
+func NewBundleService(bundleRepo repository.BundleRepository, productRepo repository.ProductRepository, auditService AuditService) BundleService {
+	return &bundleService{
+		bundleRepo:   bundleRepo,
+		productRepo:  productRepo,
+		auditService: auditService,
+	}
+}
+
+// CreateBundle creates a bundle of the seller's own products
+func (s *bundleService) CreateBundle(ctx context.Context, userID uint, req *models.CreateBundleRequest) (*models.Bundle, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	bundle := &models.Bundle{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+	}
+
+	seen := make(map[uint]bool, len(req.Items))
+	for _, item := range req.Items {
+		if seen[item.ProductID] {
+			return nil, fmt.Errorf("bundle components must be distinct products")
+		}
+		seen[item.ProductID] = true
+
+		product, err := s.productRepo.GetProductByID(ctx, item.ProductID)
+		if err != nil {
+			logger.Warn("Bundle component not found", zap.Error(err), zap.Uint("productID", item.ProductID))
+			return nil, fmt.Errorf("bundle component not found")
+		}
+		if product.UserID != userID {
+			logger.Warn("Bundle component owned by another seller", zap.Uint("productID", item.ProductID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+			return nil, fmt.Errorf("bundle components must belong to the bundle owner")
+		}
+		bundle.Items = append(bundle.Items, &models.BundleItem{ProductID: item.ProductID, Quantity: item.Quantity})
+	}
+
+	if err := s.bundleRepo.CreateBundle(ctx, bundle); err != nil {
+		logger.Error("Failed to create bundle in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to create bundle: %w", err)
+	}
+	if err := s.loadComponents(ctx, bundle); err != nil {
+		return nil, err
+	}
+
+	if err := s.auditService.Record(ctx, "bundle.created", "bundle", bundle.ID, map[string]interface{}{"name": bundle.Name, "price": bundle.Price}); err != nil {
+		logger.Warn("Bundle audit event was not recorded", zap.Error(err), zap.Uint("bundleID", bundle.ID))
+	}
+	logger.Info("Bundle created successfully", zap.Uint("bundleID", bundle.ID), actor.Field(ctx))
+	return bundle, nil
+}
+
+// GetBundle retrieves a bundle with its components
+func (s *bundleService) GetBundle(ctx context.Context, bundleID uint) (*models.Bundle, error) {
+	bundle, err := s.bundleRepo.GetBundleByID(ctx, bundleID)
+	if err != nil {
+		logger.Debug("Bundle not found", zap.Error(err), zap.Uint("bundleID", bundleID))
+		return nil, fmt.Errorf("bundle not found")
+	}
+	if err := s.loadComponents(ctx, bundle); err != nil {
+		return nil, err
+	}
+	return bundle, nil
+}
+
+// GetBundlesByOwner retrieves all bundles of a seller created or updated within timeRange, with their components
+func (s *bundleService) GetBundlesByOwner(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Bundle, error) {
+	bundles, err := s.bundleRepo.GetBundlesByUserID(ctx, userID, timeRange)
+	if err != nil {
+		logger.Error("Failed to get bundles in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to retrieve bundles: %w", err)
+	}
+	for _, bundle := range bundles {
+		if err := s.loadComponents(ctx, bundle); err != nil {
+			return nil, err
+		}
+	}
+	return bundles, nil
+}
+
+// DeleteBundle deletes a bundle. Ensures the bundle belongs to the user.
+func (s *bundleService) DeleteBundle(ctx context.Context, bundleID uint, userID uint) error {
+	bundle, err := s.bundleRepo.GetBundleByID(ctx, bundleID)
+	if err != nil {
+		return fmt.Errorf("bundle not found")
+	}
+	if bundle.UserID != userID {
+		logger.Warn("Unauthorized attempt to delete bundle", zap.Uint("bundleID", bundleID), zap.Uint("attemptingUserID", userID), actor.Field(ctx))
+		return fmt.Errorf("you are not authorized to delete this bundle")
+	}
+
+	if err := s.bundleRepo.DeleteBundle(ctx, bundleID); err != nil {
+		logger.Error("Failed to delete bundle in repository", zap.Error(err), zap.Uint("bundleID", bundleID))
+		return fmt.Errorf("failed to delete bundle: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, "bundle.deleted", "bundle", bundle.ID, map[string]interface{}{"name": bundle.Name}); err != nil {
+		logger.Warn("Bundle audit event was not recorded", zap.Error(err), zap.Uint("bundleID", bundle.ID))
+	}
+	logger.Info("Bundle deleted successfully", zap.Uint("bundleID", bundleID), actor.Field(ctx))
+	return nil
+}
+
+// loadComponents fills in a bundle's components. Availability is derived from them: a bundle is
+// only available while every component product still exists.
+func (s *bundleService) loadComponents(ctx context.Context, bundle *models.Bundle) error {
+	components, err := s.bundleRepo.GetBundleComponents(ctx, bundle.ID)
+	if err != nil {
+		logger.Error("Failed to get bundle components in repository", zap.Error(err), zap.Uint("bundleID", bundle.ID))
+		return fmt.Errorf("failed to retrieve bundle: %w", err)
+	}
+	bundle.Components = components
+	return nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// CommentService defines the interface for product comment business logic
+type CommentService interface {
+	// AddComment and GetComments act on products the user with the role can read, see ProductService.GetProduct
+	AddComment(ctx context.Context, productID uint, userID uint, role string, req *models.CreateCommentRequest) (*models.Comment, error)
+	GetComments(ctx context.Context, productID uint, viewerID uint, role string, timeRange models.TimeRange, page, pageSize int) ([]*models.Comment, int64, error)
+	SetCommentHidden(ctx context.Context, commentID uint, userID uint, hidden bool) error
+	DeleteComment(ctx context.Context, commentID uint, userID uint) error
+}
+
+// commentService implements CommentService
+type commentService struct {
+	commentRepo    repository.CommentRepository // Dependency on CommentRepository
+	productRepo    repository.ProductRepository // Dependency on ProductRepository, for ownership checks
+	productService ProductService               // Threads are only read and written by users who can read the product
+}
+
+// NewCommentService creates a new CommentService instance
+func NewCommentService(commentRepo repository.CommentRepository, productRepo repository.ProductRepository, productService ProductService) CommentService {
+	return &commentService{
+		commentRepo:    commentRepo,
+		productRepo:    productRepo,
+		productService: productService,
+	}
+}
+
+// AddComment adds a comment, or a reply to an existing comment, on a product
+func (s *commentService) AddComment(ctx context.Context, productID uint, userID uint, role string, req *models.CreateCommentRequest) (*models.Comment, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	product, err := s.productService.GetProduct(ctx, productID, userID, role)
+	if err != nil {
+		logger.Warn("Comment on unknown product", zap.Error(err), zap.Uint("productID", productID))
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		ProductID: productID,
+		UserID:    userID,
+		Body:      req.Body,
+	}
+
+	if req.ParentID != nil {
+		parent, err := s.commentRepo.GetCommentByID(ctx, *req.ParentID)
+		if err != nil || parent.ProductID != productID {
+			logger.Warn("Reply to unknown comment", zap.Uint("parentID", *req.ParentID), zap.Uint("productID", productID))
+			return nil, fmt.Errorf("parent comment not found")
+		}
+		// Replies always hang off the root comment to keep threads two levels deep
+		rootID := parent.ID
+		if parent.ParentID != nil {
+			rootID = *parent.ParentID
+		}
+		comment.ParentID = &rootID
+	}
+
+	if err := s.commentRepo.CreateComment(ctx, comment); err != nil {
+		logger.Error("Failed to create comment in repository", zap.Error(err), zap.Uint("productID", productID))
+		return nil, fmt.Errorf("failed to add comment: %w", err)
+	}
+
+	// There is no notification subsystem yet; the owner-facing event is recorded in the logs
+	logger.Info("New comment on product", zap.Uint("commentID", comment.ID), zap.Uint("productID", productID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+	return comment, nil
+}
+
+// GetComments retrieves a page of a product's comments. The product owner also sees hidden comments.
+func (s *commentService) GetComments(ctx context.Context, productID uint, viewerID uint, role string, timeRange models.TimeRange, page, pageSize int) ([]*models.Comment, int64, error) {
+	product, err := s.productService.GetProduct(ctx, productID, viewerID, role)
+	if err != nil {
+		logger.Warn("Comments requested for unknown product", zap.Error(err), zap.Uint("productID", productID))
+		return nil, 0, err
+	}
+
+	includeHidden := product.UserID == viewerID
+	comments, total, err := s.commentRepo.GetCommentsByProductID(ctx, productID, viewerID, includeHidden, timeRange, pageSize, (page-1)*pageSize)
+	if err != nil {
+		logger.Error("Failed to get comments in repository", zap.Error(err), zap.Uint("productID", productID))
+		return nil, 0, fmt.Errorf("failed to retrieve comments: %w", err)
+	}
+	return comments, total, nil
+}
+
+// SetCommentHidden hides or unhides a comment. Only the owner of the commented product may moderate.
+func (s *commentService) SetCommentHidden(ctx context.Context, commentID uint, userID uint, hidden bool) error {
+	comment, product, err := s.loadCommentWithProduct(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	if product.UserID != userID {
+		logger.Warn("Unauthorized attempt to moderate comment", zap.Uint("commentID", commentID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+		return fmt.Errorf("you are not authorized to moderate this comment")
+	}
+
+	if err := s.commentRepo.SetCommentHidden(ctx, comment.ID, hidden); err != nil {
+		logger.Error("Failed to update comment visibility in repository", zap.Error(err), zap.Uint("commentID", commentID))
+		return fmt.Errorf("failed to moderate comment: %w", err)
+	}
+	return nil
+}
+
+// DeleteComment deletes a comment and its replies. Allowed for the author and the product owner.
+func (s *commentService) DeleteComment(ctx context.Context, commentID uint, userID uint) error {
+	comment, product, err := s.loadCommentWithProduct(ctx, commentID)
+	if err != nil {
+		return err
+	}
+
+	if comment.UserID != userID && product.UserID != userID {
+		logger.Warn("Unauthorized attempt to delete comment", zap.Uint("commentID", commentID), zap.Uint("attemptingUserID", userID), zap.Uint("commentAuthorID", comment.UserID), actor.Field(ctx))
+		return fmt.Errorf("you are not authorized to delete this comment")
+	}
+
+	if err := s.commentRepo.DeleteComment(ctx, comment.ID); err != nil {
+		logger.Error("Failed to delete comment in repository", zap.Error(err), zap.Uint("commentID", commentID))
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
+
+// loadCommentWithProduct fetches a comment along with the product it belongs to
+func (s *commentService) loadCommentWithProduct(ctx context.Context, commentID uint) (*models.Comment, *models.Product, error) {
+	comment, err := s.commentRepo.GetCommentByID(ctx, commentID)
+	if err != nil {
+		logger.Warn("Comment not found", zap.Error(err), zap.Uint("commentID", commentID))
+		return nil, nil, fmt.Errorf("comment not found")
+	}
+	product, err := s.productRepo.GetProductByID(ctx, comment.ProductID)
+	if err != nil {
+		logger.Warn("Product of comment not found", zap.Error(err), zap.Uint("commentID", commentID), zap.Uint("productID", comment.ProductID))
+		return nil, nil, fmt.Errorf("comment not found")
+	}
+	return comment, product, nil
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/apiversion"
+	"gotemplate/pkg/logger"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// deprecationFlushInterval is how often buffered deprecation usage is written to the database
+const deprecationFlushInterval = time.Minute
+
+// maxUserAgentLength bounds the user agents stored, so a client can't grow the table with huge headers
+const maxUserAgentLength = 256
+
+// DeprecationService defines the interface of the sunset telemetry: which clients still use deprecated
+// endpoints and response fields, so maintainers know when removing them is safe
+type DeprecationService interface {
+	apiversion.UsageRecorder
+	GetReport(ctx context.Context, since time.Time) (*models.DeprecationReport, error)
+	Run(ctx context.Context) // Writes the buffered usage every deprecationFlushInterval, and once more when ctx is cancelled
+}
+
+// deprecationUsageKey identifies a usage counter
+type deprecationUsageKey struct {
+	feature   string
+	userID    uint
+	via       string
+	userAgent string
+	day       time.Time
+}
+
+// deprecationService implements DeprecationService
+type deprecationService struct {
+	deprecationRepo repository.DeprecationRepository
+	mu              sync.Mutex
+	pending         map[deprecationUsageKey]*models.DeprecationUsage // Counted in memory, requests don't wait on the database
+}
+
+// NewDeprecationService creates a new DeprecationService instance
+func NewDeprecationService(deprecationRepo repository.DeprecationRepository) DeprecationService {
+	return &deprecationService{
+		deprecationRepo: deprecationRepo,
+		pending:         map[deprecationUsageKey]*models.DeprecationUsage{},
+	}
+}
+
+// RecordDeprecatedUsage counts a request that used deprecated features. It is called by the API version middleware.
+func (s *deprecationService) RecordDeprecatedUsage(u apiversion.Usage) {
+	now := time.Now().UTC()
+	day := now.Truncate(24 * time.Hour)
+	userAgent := u.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, feature := range u.Features {
+		key := deprecationUsageKey{feature: feature, userID: u.UserID, via: u.Via, userAgent: userAgent, day: day}
+		usage, ok := s.pending[key]
+		if !ok {
+			usage = &models.DeprecationUsage{Feature: feature, UserID: u.UserID, Via: u.Via, UserAgent: userAgent, Day: day}
+			s.pending[key] = usage
+		}
+		usage.Count++
+		usage.LastSeenAt = now
+	}
+}
+
+// GetReport sums the usage of every deprecated feature since the given day, per client
+func (s *deprecationService) GetReport(ctx context.Context, since time.Time) (*models.DeprecationReport, error) {
+	totals, err := s.deprecationRepo.GetDeprecationUsageSince(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.DeprecationReport{Since: since, Features: []*models.DeprecatedFeatureUsage{}}
+	byFeature := map[string]*models.DeprecatedFeatureUsage{}
+	for _, t := range totals {
+		f, ok := byFeature[t.Feature]
+		if !ok {
+			f = &models.DeprecatedFeatureUsage{Feature: t.Feature}
+			byFeature[t.Feature] = f
+			report.Features = append(report.Features, f)
+		}
+		f.Count += t.Count
+		if t.LastSeenAt.After(f.LastSeenAt) {
+			f.LastSeenAt = t.LastSeenAt
+		}
+		f.Clients = append(f.Clients, &models.DeprecationClientUsage{Via: t.Via, UserAgent: t.UserAgent, Users: t.Users, Count: t.Count, LastSeenAt: t.LastSeenAt})
+	}
+	sort.SliceStable(report.Features, func(i, j int) bool { return report.Features[i].Count > report.Features[j].Count })
+	return report, nil
+}
+
+// Run writes the buffered usage every deprecationFlushInterval until ctx is cancelled, then a last time
+func (s *deprecationService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			s.flush(flushCtx)
+			cancel()
+			return
+		case <-time.After(deprecationFlushInterval):
+			s.flush(ctx)
+		}
+	}
+}
+
+// flush writes the buffered usage. Counts that fail to be written are put back for the next flush.
+func (s *deprecationService) flush(ctx context.Context) {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = map[deprecationUsageKey]*models.DeprecationUsage{}
+	s.mu.Unlock()
+	if len(pending) == 0 {
+		return
+	}
+
+	usages := make([]*models.DeprecationUsage, 0, len(pending))
+	for _, u := range pending {
+		usages = append(usages, u)
+	}
+	if err := s.deprecationRepo.AddDeprecationUsages(ctx, usages); err != nil {
+		logger.Warn("Failed to write deprecation usage, keeping it for the next flush", zap.Error(err), zap.Int("rows", len(usages)))
+		s.mu.Lock()
+		for key, u := range pending {
+			if current, ok := s.pending[key]; ok {
+				current.Count += u.Count
+				if u.LastSeenAt.After(current.LastSeenAt) {
+					current.LastSeenAt = u.LastSeenAt
+				}
+			} else {
+				s.pending[key] = u
+			}
+		}
+		s.mu.Unlock()
+		return
+	}
+	logger.Debug("Deprecation usage written", zap.Int("rows", len(usages)))
+}
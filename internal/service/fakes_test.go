@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+func TestMain(m *testing.M) {
+	logger.ZapLogger = zap.NewNop()
+	os.Exit(m.Run())
+}
+
+// fakeUserRepository is an in-memory repository.UserRepository
+type fakeUserRepository struct {
+	users  map[uint]*models.User
+	nextID uint
+}
+
+func newFakeUserRepository(users ...*models.User) *fakeUserRepository {
+	r := &fakeUserRepository{users: map[uint]*models.User{}}
+	for _, user := range users {
+		if err := r.CreateUser(context.Background(), user); err != nil {
+			panic(err)
+		}
+	}
+	return r
+}
+
+func (r *fakeUserRepository) CreateUser(ctx context.Context, user *models.User) error {
+	if live, _ := r.GetUserByEmail(ctx, user.Email); live != nil {
+		return fmt.Errorf("email %s is taken", user.Email)
+	}
+	r.nextID++
+	user.ID, user.CreatedAt, user.UpdatedAt = r.nextID, time.Now(), time.Now()
+	stored := *user
+	r.users[user.ID] = &stored
+	return nil
+}
+
+func (r *fakeUserRepository) find(match func(*models.User) bool) (*models.User, error) {
+	for _, user := range r.users {
+		if match(user) {
+			found := *user
+			return &found, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUserRepository) GetUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Email == email && !u.DeletedAt.Valid })
+}
+
+func (r *fakeUserRepository) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Username == username && !u.DeletedAt.Valid })
+}
+
+func (r *fakeUserRepository) GetUserByID(ctx context.Context, id uint) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.ID == id && !u.DeletedAt.Valid })
+}
+
+func (r *fakeUserRepository) GetUsersByIDs(ctx context.Context, ids []uint) ([]*models.User, error) {
+	var users []*models.User
+	for _, id := range ids {
+		if user, err := r.GetUserByID(ctx, id); err == nil {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (r *fakeUserRepository) GetDeletedUserByEmail(ctx context.Context, email string) (*models.User, error) {
+	return r.find(func(u *models.User) bool { return u.Email == email && u.DeletedAt.Valid })
+}
+
+func (r *fakeUserRepository) RestoreUser(ctx context.Context, user *models.User) error {
+	stored, ok := r.users[user.ID]
+	if !ok || !stored.DeletedAt.Valid {
+		return repository.ErrUserNotFound
+	}
+	stored.DeletedAt = gorm.DeletedAt{}
+	stored.Username, stored.Password, stored.Role = user.Username, user.Password, user.Role
+	return nil
+}
+
+func (r *fakeUserRepository) DeleteUser(ctx context.Context, id uint) error {
+	stored, ok := r.users[id]
+	if !ok || stored.DeletedAt.Valid {
+		return repository.ErrUserNotFound
+	}
+	stored.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+	return nil
+}
+
+func (r *fakeUserRepository) SetPendingPassword(ctx context.Context, id uint, pending, hashedPassword string) error {
+	stored, ok := r.users[id]
+	if !ok || stored.DeletedAt.Valid || stored.Password != pending {
+		return repository.ErrUserNotFound
+	}
+	stored.Password = hashedPassword
+	return nil
+}
+
+// fakeAuditService records nothing
+type fakeAuditService struct{}
+
+func (fakeAuditService) Record(ctx context.Context, action string, resourceType string, resourceID uint, metadata map[string]interface{}) error {
+	return nil
+}
+
+func (fakeAuditService) RecordSettingChange(ctx context.Context, setting string, from, to interface{}, reason string) error {
+	return nil
+}
+
+func (fakeAuditService) GetEvents(ctx context.Context, query *models.AuditEventQuery, page, pageSize int) ([]*models.AuditEvent, int64, error) {
+	return nil, 0, nil
+}
+
+// openThrottle never blocks a login
+type openThrottle struct{}
+
+func (openThrottle) RetryAfter(ctx context.Context, email string) time.Duration { return 0 }
+func (openThrottle) RecordFailure(ctx context.Context, email string)            {}
+func (openThrottle) Reset(ctx context.Context, email string)                    {}
+
+// fakeProductRepository serves products from memory; other methods aren't implemented
+type fakeProductRepository struct {
+	repository.ProductRepository
+	products map[uint]*models.Product
+}
+
+func (r *fakeProductRepository) GetProductByID(ctx context.Context, id uint) (*models.Product, error) {
+	product, ok := r.products[id]
+	if !ok {
+		return nil, fmt.Errorf("product with ID %d not found", id)
+	}
+	found := *product
+	return &found, nil
+}
+
+// fakePermissionRepository serves product permissions from memory; other methods aren't implemented
+type fakePermissionRepository struct {
+	repository.ProductPermissionRepository
+	permissions []*models.ProductPermission
+}
+
+func (r *fakePermissionRepository) GetProductPermission(ctx context.Context, productID uint, userID uint) (*models.ProductPermission, error) {
+	for _, p := range r.permissions {
+		if p.ProductID == productID && p.UserID == userID {
+			return p, nil
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ImportConnector reads product records from an external source.
+// Next returns io.EOF once the source is exhausted; any other error aborts the import.
+// Per-record problems should be reported through ImportRecordError so the import can continue.
+type ImportConnector interface {
+	Next(ctx context.Context) (*models.ImportRecord, error)
+	Progress() int // Estimated share of the source consumed so far, 0-100
+}
+
+// ImportRecordError is returned by a connector for a record that can't be mapped; the import skips it
+type ImportRecordError struct {
+	Ref string
+	Err error
+}
+
+func (e *ImportRecordError) Error() string {
+	return e.Ref + ": " + e.Err.Error()
+}
+
+// ImportConnectorFactory creates a connector reading an uploaded payload of size bytes as a stream.
+// It should check the start of the payload (e.g. a header) so bad uploads are rejected before the import starts.
+type ImportConnectorFactory func(src io.Reader, size int64) (ImportConnector, error)
+
+// importConnectors holds the registered connectors by format name
+var importConnectors = map[string]ImportConnectorFactory{
+	"shopify-csv": NewShopifyCSVConnector,
+	"ndjson":      NewNDJSONConnector,
+}
+
+// RegisterImportConnector makes a connector available under a format name. It is meant to be called at startup.
+func RegisterImportConnector(format string, factory ImportConnectorFactory) {
+	importConnectors[format] = factory
+}
+
+// NewImportConnector creates the connector registered for format, reading from src
+func NewImportConnector(format string, src io.Reader, size int64) (ImportConnector, error) {
+	factory, ok := importConnectors[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported import format %q, supported: %s", format, strings.Join(ImportFormats(), ", "))
+	}
+	return factory(src, size)
+}
+
+// ImportFormats lists the registered connector format names
+func ImportFormats() []string {
+	formats := make([]string, 0, len(importConnectors))
+	for f := range importConnectors {
+		formats = append(formats, f)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+// shopifyCSVConnector reads a Shopify product export. Shopify writes one row per variant;
+// only the first row of each handle carries the title, so later variant rows are skipped.
+type shopifyCSVConnector struct {
+	reader  *csv.Reader
+	size    int64
+	columns map[string]int
+	seen    map[string]bool
+}
+
+// NewShopifyCSVConnector creates a connector for a Shopify products CSV export
+func NewShopifyCSVConnector(src io.Reader, size int64) (ImportConnector, error) {
+	reader := csv.NewReader(src)
+	reader.FieldsPerRecord = -1 // Tolerate ragged rows, missing cells read as empty
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Exports re-saved by spreadsheet tools often start with a byte order mark
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+	for _, required := range []string{"Handle", "Title", "Variant Price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+
+	return &shopifyCSVConnector{
+		reader:  reader,
+		size:    size,
+		columns: columns,
+		seen:    map[string]bool{},
+	}, nil
+}
+
+// Next returns the next product row
+func (c *shopifyCSVConnector) Next(ctx context.Context) (*models.ImportRecord, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		row, err := c.reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, &ImportRecordError{Ref: fmt.Sprintf("line %d", parseErr.Line), Err: parseErr.Err}
+			}
+			return nil, err // io.EOF ends the import
+		}
+
+		line, _ := c.reader.FieldPos(0)
+		ref := fmt.Sprintf("line %d", line)
+		handle := c.cell(row, "Handle")
+		if handle == "" || c.seen[handle] || c.cell(row, "Title") == "" {
+			continue // Variant or image rows of a product already read
+		}
+		c.seen[handle] = true
+
+		price, err := strconv.ParseFloat(c.cell(row, "Variant Price"), 64)
+		if err != nil || price <= 0 {
+			return nil, &ImportRecordError{Ref: ref, Err: fmt.Errorf("invalid price %q", c.cell(row, "Variant Price"))}
+		}
+		return &models.ImportRecord{
+			Ref:         ref + " (" + handle + ")",
+			Name:        c.cell(row, "Title"),
+			Description: c.cell(row, "Body (HTML)"),
+			Price:       price,
+		}, nil
+	}
+}
+
+// Progress estimates progress from the input offset
+func (c *shopifyCSVConnector) Progress() int {
+	if c.size == 0 {
+		return 100
+	}
+	return int(c.reader.InputOffset() * 100 / c.size)
+}
+
+// cell returns a trimmed cell by column name, or "" if the column or cell is missing
+func (c *shopifyCSVConnector) cell(row []string, column string) string {
+	i, ok := c.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// maxNDJSONLineSize caps one NDJSON record; a longer line aborts the import
+const maxNDJSONLineSize = 1 << 20 // 1 MiB
+
+// ndjsonConnector reads newline-delimited JSON, one product object per line:
+// {"name": "...", "description": "...", "price": 9.99}. An optional "ref" names the record in error reports.
+// Blank lines are skipped and unknown fields are ignored.
+type ndjsonConnector struct {
+	scanner *bufio.Scanner
+	size    int64
+	read    int64 // Bytes consumed so far
+	line    int
+}
+
+// ndjsonRecord is the shape of one NDJSON line
+type ndjsonRecord struct {
+	Ref         string  `json:"ref"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+}
+
+// NewNDJSONConnector creates a connector for newline-delimited JSON product records
+func NewNDJSONConnector(src io.Reader, size int64) (ImportConnector, error) {
+	scanner := bufio.NewScanner(src)
+	scanner.Buffer(make([]byte, 0, 64<<10), maxNDJSONLineSize)
+	return &ndjsonConnector{scanner: scanner, size: size}, nil
+}
+
+// Next returns the record on the next non-blank line
+func (c *ndjsonConnector) Next(ctx context.Context) (*models.ImportRecord, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if !c.scanner.Scan() {
+			if err := c.scanner.Err(); err != nil {
+				if errors.Is(err, bufio.ErrTooLong) {
+					return nil, fmt.Errorf("line %d is longer than %d bytes", c.line+1, maxNDJSONLineSize)
+				}
+				return nil, err
+			}
+			return nil, io.EOF
+		}
+		c.line++
+		line := c.scanner.Bytes()
+		c.read += int64(len(line)) + 1
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		ref := fmt.Sprintf("line %d", c.line)
+		var rec ndjsonRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, &ImportRecordError{Ref: ref, Err: fmt.Errorf("invalid JSON: %w", err)}
+		}
+		if rec.Ref != "" {
+			ref += " (" + rec.Ref + ")"
+		}
+		return &models.ImportRecord{
+			Ref:         ref,
+			Name:        strings.TrimSpace(rec.Name),
+			Description: strings.TrimSpace(rec.Description),
+			Price:       rec.Price,
+		}, nil
+	}
+}
+
+// Progress estimates progress from the bytes consumed
+func (c *ndjsonConnector) Progress() int {
+	if c.size <= 0 || c.read >= c.size {
+		return 100
+	}
+	return int(c.read * 100 / c.size)
+}
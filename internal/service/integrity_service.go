@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"net/mail"
+
+	"go.uber.org/zap"
+)
+
+// integrityUserBatchSize is the number of users loaded per batch when checking emails
+const integrityUserBatchSize = 500
+
+// IntegrityService defines the interface for scanning (and optionally repairing) data integrity problems
+type IntegrityService interface {
+	Check(ctx context.Context, repair bool) (*models.IntegrityReport, error)
+}
+
+// integrityService implements IntegrityService
+type integrityService struct {
+	integrityRepo repository.IntegrityRepository // Dependency on IntegrityRepository
+}
+
+// NewIntegrityService creates a new IntegrityService instance
+func NewIntegrityService(integrityRepo repository.IntegrityRepository) IntegrityService {
+	return &integrityService{
+		integrityRepo: integrityRepo,
+	}
+}
+
+// Check runs every integrity check. With repair set, issues that have a safe fix are fixed:
+// orphaned products and comments are soft-deleted. Invalid emails and dangling bundle items
+// are only reported, since fixing them needs a human decision.
+func (s *integrityService) Check(ctx context.Context, repair bool) (*models.IntegrityReport, error) {
+	report := &models.IntegrityReport{Issues: []*models.IntegrityIssue{}}
+
+	if err := s.checkOrphanedProducts(ctx, report, repair); err != nil {
+		return nil, err
+	}
+	if err := s.checkOrphanedComments(ctx, report, repair); err != nil {
+		return nil, err
+	}
+	if err := s.checkDanglingBundleItems(ctx, report); err != nil {
+		return nil, err
+	}
+	if err := s.checkUserEmails(ctx, report); err != nil {
+		return nil, err
+	}
+
+	logger.Info("Integrity check finished", zap.Int("issues", len(report.Issues)), zap.Int("repaired", report.Repaired), zap.Bool("repair", repair))
+	return report, nil
+}
+
+// checkOrphanedProducts finds products whose owner no longer exists
+func (s *integrityService) checkOrphanedProducts(ctx context.Context, report *models.IntegrityReport, repair bool) error {
+	products, err := s.integrityRepo.FindOrphanedProducts(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uint, 0, len(products))
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	repaired := repair && len(ids) > 0
+	if repaired {
+		if _, err := s.integrityRepo.SoftDeleteProducts(ctx, ids); err != nil {
+			return err
+		}
+	}
+
+	for _, p := range products {
+		report.Add(&models.IntegrityIssue{
+			Check:        "orphaned_product",
+			ResourceType: "product",
+			ResourceID:   p.ID,
+			Detail:       fmt.Sprintf("owner user %d does not exist", p.UserID),
+			Repaired:     repaired,
+		})
+	}
+	return nil
+}
+
+// checkOrphanedComments finds comments whose product no longer exists
+func (s *integrityService) checkOrphanedComments(ctx context.Context, report *models.IntegrityReport, repair bool) error {
+	comments, err := s.integrityRepo.FindOrphanedComments(ctx)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uint, 0, len(comments))
+	for _, c := range comments {
+		ids = append(ids, c.ID)
+	}
+	repaired := repair && len(ids) > 0
+	if repaired {
+		if _, err := s.integrityRepo.SoftDeleteComments(ctx, ids); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range comments {
+		report.Add(&models.IntegrityIssue{
+			Check:        "orphaned_comment",
+			ResourceType: "comment",
+			ResourceID:   c.ID,
+			Detail:       fmt.Sprintf("product %d does not exist", c.ProductID),
+			Repaired:     repaired,
+		})
+	}
+	return nil
+}
+
+// checkDanglingBundleItems finds bundle components whose product no longer exists
+func (s *integrityService) checkDanglingBundleItems(ctx context.Context, report *models.IntegrityReport) error {
+	items, err := s.integrityRepo.FindDanglingBundleItems(ctx)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		report.Add(&models.IntegrityIssue{
+			Check:        "dangling_bundle_item",
+			ResourceType: "bundle",
+			ResourceID:   item.BundleID,
+			Detail:       fmt.Sprintf("component product %d does not exist", item.ProductID),
+		})
+	}
+	return nil
+}
+
+// checkUserEmails finds users whose stored email is not a valid address
+func (s *integrityService) checkUserEmails(ctx context.Context, report *models.IntegrityReport) error {
+	var afterID uint
+	for {
+		users, err := s.integrityRepo.GetUsersAfter(ctx, afterID, integrityUserBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(users) == 0 {
+			return nil
+		}
+
+		for _, u := range users {
+			if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email {
+				report.Add(&models.IntegrityIssue{
+					Check:        "invalid_email",
+					ResourceType: "user",
+					ResourceID:   u.ID,
+					Detail:       fmt.Sprintf("email %q is not a valid address", u.Email),
+				})
+			}
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"gotemplate/config"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// LoginThrottle slows down repeated failed logins against the same credential, independent of client IP
+type LoginThrottle interface {
+	// RetryAfter reports how long the credential is still blocked, zero if a login may be attempted now
+	RetryAfter(ctx context.Context, email string) time.Duration
+	RecordFailure(ctx context.Context, email string)
+	Reset(ctx context.Context, email string)
+}
+
+// loginThrottle implements LoginThrottle on top of LoginAttemptRepository, so limits hold across instances.
+// Logins stay available in read-only mode, when nothing can be written: failures are then counted in memory,
+// per instance, and checked along with the stored ones.
+type loginThrottle struct {
+	attemptRepo repository.LoginAttemptRepository // Dependency on LoginAttemptRepository
+	cfg         config.LoginThrottleConfig
+	readOnly    *readonly.Mode
+
+	mu       sync.Mutex
+	inMemory map[string]*memoryAttempt // Failures counted while read-only, by throttle key
+}
+
+// memoryAttempt counts the consecutive failed logins of a credential while read-only
+type memoryAttempt struct {
+	failures     int
+	lastFailedAt time.Time
+}
+
+// maxMemoryAttempts bounds the credentials counted in memory; past it, the least recently failed one is forgotten
+const maxMemoryAttempts = 10000
+
+// NewLoginThrottle creates a LoginThrottle. With FreeAttempts set to zero, throttling is disabled.
+func NewLoginThrottle(attemptRepo repository.LoginAttemptRepository, cfg config.LoginThrottleConfig, readOnly *readonly.Mode) LoginThrottle {
+	return &loginThrottle{
+		attemptRepo: attemptRepo,
+		cfg:         cfg,
+		readOnly:    readOnly,
+		inMemory:    map[string]*memoryAttempt{},
+	}
+}
+
+// throttleKey normalizes an email so case and whitespace variants share one counter
+func throttleKey(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// delay returns the block following a given number of consecutive failures:
+// nothing for the free attempts, then BaseDelay doubling with each failure up to MaxDelay
+func (t *loginThrottle) delay(failures int) time.Duration {
+	over := failures - t.cfg.FreeAttempts
+	if over <= 0 {
+		return 0
+	}
+	d := t.cfg.BaseDelay
+	for i := 1; i < over && d < t.cfg.MaxDelay; i++ {
+		d *= 2
+	}
+	if d > t.cfg.MaxDelay {
+		d = t.cfg.MaxDelay
+	}
+	return d
+}
+
+// RetryAfter reports the remaining block for a credential, the longer of the stored and the in-memory one.
+// Store errors fail open so logins keep working.
+func (t *loginThrottle) RetryAfter(ctx context.Context, email string) time.Duration {
+	if t.cfg.FreeAttempts == 0 {
+		return 0
+	}
+	failures, wait := t.memoryRetryAfter(throttleKey(email))
+	attempt, err := t.attemptRepo.GetLoginAttempt(ctx, throttleKey(email))
+	if err == nil && attempt != nil && time.Since(attempt.LastFailedAt) <= t.cfg.Window {
+		if stored := time.Until(attempt.LastFailedAt.Add(t.delay(attempt.Failures))); stored > wait {
+			failures, wait = attempt.Failures, stored
+		}
+	}
+	if wait > 0 {
+		logger.Warn("Login throttled", zap.String("email", email), zap.Int("failures", failures), zap.Duration("retryAfter", wait))
+		return wait
+	}
+	return 0
+}
+
+// memoryRetryAfter returns the failures counted in memory for a credential and the block they impose
+func (t *loginThrottle) memoryRetryAfter(key string) (int, time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	attempt, ok := t.inMemory[key]
+	if !ok || time.Since(attempt.lastFailedAt) > t.cfg.Window {
+		return 0, 0
+	}
+	return attempt.failures, time.Until(attempt.lastFailedAt.Add(t.delay(attempt.failures)))
+}
+
+// RecordFailure counts a failed login for the credential, in memory while read-only
+func (t *loginThrottle) RecordFailure(ctx context.Context, email string) {
+	if t.cfg.FreeAttempts == 0 {
+		return
+	}
+	if t.readOnly.Enabled() {
+		var stored int // The in-memory count continues from the stored one, which can't grow now
+		if attempt, err := t.attemptRepo.GetLoginAttempt(ctx, throttleKey(email)); err == nil && attempt != nil && time.Since(attempt.LastFailedAt) <= t.cfg.Window {
+			stored = attempt.Failures
+		}
+		t.recordMemoryFailure(throttleKey(email), stored, time.Now())
+		return
+	}
+	if _, err := t.attemptRepo.RecordLoginFailure(ctx, throttleKey(email), time.Now(), t.cfg.Window); err != nil {
+		logger.Warn("Failed login was not counted", zap.Error(err))
+	}
+}
+
+// recordMemoryFailure counts a failed login in memory, like RecordLoginFailure does in the store: failures older
+// than the window start the count over, from the stored failures
+func (t *loginThrottle) recordMemoryFailure(key string, stored int, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	attempt, ok := t.inMemory[key]
+	if !ok || at.Sub(attempt.lastFailedAt) > t.cfg.Window {
+		if !ok && len(t.inMemory) >= maxMemoryAttempts {
+			t.pruneMemoryAttempts(at)
+		}
+		attempt = &memoryAttempt{failures: stored}
+		t.inMemory[key] = attempt
+	}
+	attempt.failures++
+	attempt.lastFailedAt = at
+}
+
+// pruneMemoryAttempts forgets the in-memory failures older than the window, or the least recent one if none are
+func (t *loginThrottle) pruneMemoryAttempts(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	for key, attempt := range t.inMemory {
+		if now.Sub(attempt.lastFailedAt) > t.cfg.Window {
+			delete(t.inMemory, key)
+		} else if oldestKey == "" || attempt.lastFailedAt.Before(oldest) {
+			oldestKey, oldest = key, attempt.lastFailedAt
+		}
+	}
+	if len(t.inMemory) >= maxMemoryAttempts {
+		delete(t.inMemory, oldestKey)
+	}
+}
+
+// Reset clears the credential's failures after a successful login. Stored failures can't be cleared while
+// read-only; they expire with the window instead.
+func (t *loginThrottle) Reset(ctx context.Context, email string) {
+	if t.cfg.FreeAttempts == 0 {
+		return
+	}
+	t.mu.Lock()
+	delete(t.inMemory, throttleKey(email))
+	t.mu.Unlock()
+	if t.readOnly.Enabled() {
+		return
+	}
+	if err := t.attemptRepo.ResetLoginAttempts(ctx, throttleKey(email)); err != nil {
+		logger.Warn("Failed to reset login attempts", zap.Error(err))
+	}
+}
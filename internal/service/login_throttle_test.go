@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/readonly"
+	"testing"
+	"time"
+)
+
+// readOnlyAttemptRepository serves a stored attempt and refuses writes, like the store in read-only mode
+type readOnlyAttemptRepository struct {
+	stored *models.LoginAttempt
+}
+
+func (r *readOnlyAttemptRepository) GetLoginAttempt(ctx context.Context, key string) (*models.LoginAttempt, error) {
+	return r.stored, nil
+}
+
+func (r *readOnlyAttemptRepository) RecordLoginFailure(ctx context.Context, key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	panic("login failure written while read-only")
+}
+
+func (r *readOnlyAttemptRepository) ResetLoginAttempts(ctx context.Context, key string) error {
+	panic("login attempts reset while read-only")
+}
+
+// TestLoginThrottleCountsFailuresWhileReadOnly checks that failures keep blocking a credential while they can't be
+// stored, continuing from the stored count
+func TestLoginThrottleCountsFailuresWhileReadOnly(t *testing.T) {
+	ctx := context.Background()
+	cfg := config.LoginThrottleConfig{FreeAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+	repo := &readOnlyAttemptRepository{stored: &models.LoginAttempt{Failures: 2, LastFailedAt: time.Now().Add(-10 * time.Minute)}}
+	throttle := NewLoginThrottle(repo, cfg, readonly.New(true, "test"))
+
+	if wait := throttle.RetryAfter(ctx, "ada@example.com"); wait != 0 {
+		t.Fatalf("blocked for %v within the free attempts", wait)
+	}
+	throttle.RecordFailure(ctx, "ada@example.com") // The third failure, still free
+	if wait := throttle.RetryAfter(ctx, "Ada@Example.com"); wait != 0 {
+		t.Fatalf("blocked for %v within the free attempts", wait)
+	}
+	throttle.RecordFailure(ctx, "ada@example.com")
+	if wait := throttle.RetryAfter(ctx, "ada@example.com"); wait <= 0 || wait > cfg.BaseDelay {
+		t.Fatalf("blocked for %v past the free attempts, want up to %v", wait, cfg.BaseDelay)
+	}
+	if wait := throttle.RetryAfter(ctx, "bob@example.com"); wait != 0 {
+		t.Fatalf("another credential is blocked for %v", wait)
+	}
+
+	throttle.Reset(ctx, "ada@example.com")
+	if wait := throttle.RetryAfter(ctx, "ada@example.com"); wait != 0 {
+		t.Fatalf("blocked for %v after a successful login", wait)
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ProgressFunc reports the completion percentage (0-100) of a running operation
+type ProgressFunc func(progress int)
+
+// OperationFunc is the unit of work executed in the background for an operation.
+// The returned value is JSON-encoded into the operation's result.
+type OperationFunc func(ctx context.Context, report ProgressFunc) (interface{}, error)
+
+// OperationService defines the interface for long-running operation management
+type OperationService interface {
+	Start(ctx context.Context, userID uint, kind string, run OperationFunc) (*models.Operation, error)
+	GetOperation(ctx context.Context, operationID uint, userID uint) (*models.Operation, error)
+	// Run keeps the operations executing on this instance marked alive and fails those left behind by instances
+	// that stopped, until ctx is cancelled
+	Run(ctx context.Context)
+	// Wait waits until the operations executing on this instance finish, or ctx is done
+	Wait(ctx context.Context) error
+}
+
+// An instance marks the operations it executes every operationHeartbeatInterval. Unfinished operations not marked
+// for operationStaleAfter were left behind by an instance that stopped or crashed, and are failed by the others,
+// or by the next one to start. Status counts would otherwise show them pending or running forever.
+const (
+	operationHeartbeatInterval = 30 * time.Second
+	operationStaleAfter        = 3 * operationHeartbeatInterval
+)
+
+// operationInterrupted is the error of operations whose instance stopped before they finished
+const operationInterrupted = "interrupted: the server running it stopped"
+
+// operationService implements OperationService
+type operationService struct {
+	operationRepo repository.OperationRepository // Dependency on OperationRepository
+	readOnly      *readonly.Mode                 // No new background work is started in read-only mode
+
+	wg      sync.WaitGroup // Operations executing on this instance, waited for at shutdown
+	mu      sync.Mutex
+	running map[uint]bool // IDs of the operations executing on this instance
+}
+
+// NewOperationService creates a new OperationService instance
+func NewOperationService(operationRepo repository.OperationRepository, readOnly *readonly.Mode) OperationService {
+	return &operationService{
+		operationRepo: operationRepo,
+		readOnly:      readOnly,
+		running:       map[uint]bool{},
+	}
+}
+
+// Start records a pending operation and executes run in the background.
+// The returned operation can be handed to the client immediately (202 Accepted).
+func (s *operationService) Start(ctx context.Context, userID uint, kind string, run OperationFunc) (*models.Operation, error) {
+	if err := s.readOnly.Check(); err != nil {
+		return nil, err
+	}
+	op := &models.Operation{
+		Kind:   kind,
+		Status: models.OperationStatusPending,
+		UserID: userID,
+	}
+	if err := s.operationRepo.CreateOperation(ctx, op); err != nil {
+		logger.Error("Failed to create operation in repository", zap.Error(err), zap.String("kind", kind), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to start operation: %w", err)
+	}
+
+	// The background work must outlive the HTTP request that started it,
+	// but keeps the actor so audit events and logs stay attributed
+	jobCtx := context.Background()
+	if a, ok := actor.FromContext(ctx); ok {
+		jobCtx = actor.WithActor(jobCtx, a)
+	}
+	snapshot := *op
+	s.mu.Lock()
+	s.running[op.ID] = true
+	s.mu.Unlock()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.mu.Lock()
+			delete(s.running, snapshot.ID)
+			s.mu.Unlock()
+		}()
+		s.execute(jobCtx, &snapshot, run)
+	}()
+
+	logger.Info("Operation started", zap.Uint("operationID", op.ID), zap.String("kind", kind), zap.Uint("userID", userID), actor.Field(ctx))
+	return op, nil
+}
+
+// execute runs the operation and persists every status transition
+func (s *operationService) execute(ctx context.Context, op *models.Operation, run OperationFunc) {
+	defer func() {
+		// A panicking job must not take the whole server down with it
+		if r := recover(); r != nil {
+			logger.Error("Operation panicked", zap.Uint("operationID", op.ID), zap.Any("panic", r))
+			s.finish(ctx, op, nil, fmt.Errorf("internal error"))
+		}
+	}()
+
+	op.Status = models.OperationStatusRunning
+	if err := s.operationRepo.UpdateOperation(ctx, op); err != nil {
+		logger.Error("Failed to mark operation as running", zap.Error(err), zap.Uint("operationID", op.ID))
+	}
+
+	report := func(progress int) {
+		if progress < 0 {
+			progress = 0
+		} else if progress > 100 {
+			progress = 100
+		}
+		if progress == op.Progress {
+			return // Avoid a DB round trip when nothing changed
+		}
+		op.Progress = progress
+		if err := s.operationRepo.UpdateOperation(ctx, op); err != nil {
+			logger.Warn("Failed to record operation progress", zap.Error(err), zap.Uint("operationID", op.ID))
+		}
+	}
+
+	result, err := run(ctx, report)
+	s.finish(ctx, op, result, err)
+}
+
+// finish stores the terminal status of an operation along with its result or error
+func (s *operationService) finish(ctx context.Context, op *models.Operation, result interface{}, runErr error) {
+	if runErr != nil {
+		op.Status = models.OperationStatusFailed
+		op.Error = runErr.Error()
+	} else {
+		op.Status = models.OperationStatusSucceeded
+		op.Progress = 100
+		if result != nil {
+			encoded, err := json.Marshal(result)
+			if err != nil {
+				op.Status = models.OperationStatusFailed
+				op.Error = "failed to encode operation result"
+				logger.Error("Failed to encode operation result", zap.Error(err), zap.Uint("operationID", op.ID))
+			} else {
+				op.Result = string(encoded)
+			}
+		}
+	}
+
+	if err := s.operationRepo.UpdateOperation(ctx, op); err != nil {
+		logger.Error("Failed to record operation completion", zap.Error(err), zap.Uint("operationID", op.ID))
+		return
+	}
+	logger.Info("Operation finished", zap.Uint("operationID", op.ID), zap.String("kind", op.Kind), zap.String("status", op.Status))
+}
+
+// Run marks the operations executing on this instance every operationHeartbeatInterval and fails the stale ones.
+// The first sweep runs at startup, failing what a previous run of the server left behind long enough ago; rows it
+// left more recently fail on a later sweep, as they can't be told apart from operations of other instances.
+func (s *operationService) Run(ctx context.Context) {
+	for {
+		s.heartbeat(ctx)
+		if _, err := s.operationRepo.FailStaleOperations(ctx, time.Now().Add(-operationStaleAfter), operationInterrupted); err != nil {
+			logger.Warn("Failed to fail stale operations", zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(operationHeartbeatInterval):
+		}
+	}
+}
+
+// heartbeat marks the operations executing on this instance as alive
+func (s *operationService) heartbeat(ctx context.Context) {
+	s.mu.Lock()
+	ids := make([]uint, 0, len(s.running))
+	for id := range s.running {
+		ids = append(ids, id)
+	}
+	s.mu.Unlock()
+	if len(ids) == 0 {
+		return
+	}
+	if err := s.operationRepo.TouchOperations(ctx, ids); err != nil {
+		logger.Warn("Failed to mark running operations alive", zap.Error(err), zap.Int("operations", len(ids)))
+	}
+}
+
+// Wait blocks until every operation started on this instance has finished, or ctx is done. Operations still
+// running then are left to be failed as stale.
+func (s *operationService) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		unfinished := len(s.running)
+		s.mu.Unlock()
+		return fmt.Errorf("%d operations still running: %w", unfinished, ctx.Err())
+	}
+}
+
+// GetOperation retrieves an operation. Ensures the operation belongs to the user.
+func (s *operationService) GetOperation(ctx context.Context, operationID uint, userID uint) (*models.Operation, error) {
+	op, err := s.operationRepo.GetOperationByID(ctx, operationID)
+	if err != nil {
+		logger.Error("Failed to get operation by ID in repository", zap.Error(err), zap.Uint("operationID", operationID))
+		return nil, fmt.Errorf("operation not found")
+	}
+
+	// Authorization check: only the user who started the operation may poll it
+	if op.UserID != userID {
+		logger.Warn("Unauthorized attempt to view operation", zap.Uint("operationID", operationID), zap.Uint("attemptingUserID", userID), zap.Uint("operationOwnerID", op.UserID), actor.Field(ctx))
+		return nil, fmt.Errorf("operation not found") // Don't reveal existence to other users
+	}
+
+	logger.Debug("Operation retrieved", zap.Uint("operationID", operationID), zap.String("status", op.Status))
+	return op, nil
+}
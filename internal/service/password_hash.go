@@ -0,0 +1,38 @@
+package service
+
+import (
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/auth"
+	"time"
+)
+
+// MeasurePasswordHashing times one password hash at the configured cost and compares it to the target range
+func MeasurePasswordHashing(cfg config.PasswordHashConfig) (*models.PasswordHashReport, error) {
+	took, err := auth.BenchmarkPasswordHash(cfg.Cost)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure password hashing: %w", err)
+	}
+
+	report := &models.PasswordHashReport{
+		Cost:          cfg.Cost,
+		DurationMs:    milliseconds(took),
+		MinDurationMs: milliseconds(cfg.MinDuration),
+		MaxDurationMs: milliseconds(cfg.MaxDuration),
+		Verdict:       models.PasswordHashOK,
+		SuggestedCost: auth.SuggestPasswordHashCost(cfg.Cost, took, cfg.MinDuration, cfg.MaxDuration),
+	}
+	switch {
+	case took < cfg.MinDuration:
+		report.Verdict = models.PasswordHashTooFast
+	case took > cfg.MaxDuration:
+		report.Verdict = models.PasswordHashTooSlow
+	}
+	return report, nil
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/auth"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"gotemplate/pkg/validation"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxPersonalTokensPerUser caps how many unrevoked personal access tokens a single user can have
+const maxPersonalTokensPerUser = 50
+
+// personalTokenTouchInterval limits how often a token's last use is written back
+const personalTokenTouchInterval = time.Minute
+
+// PersonalTokenService defines the interface for personal access token business logic
+type PersonalTokenService interface {
+	CreateToken(ctx context.Context, userID uint, role string, req *models.CreatePersonalAccessTokenRequest) (*models.PersonalAccessToken, string, error)
+	GetTokens(ctx context.Context, userID uint) ([]*models.PersonalAccessToken, error)
+	RevokeToken(ctx context.Context, tokenID uint, userID uint) error
+	Authenticate(ctx context.Context, token string) (actor.Actor, error) // Used by the auth middleware
+}
+
+// personalTokenService implements PersonalTokenService
+type personalTokenService struct {
+	tokenRepo    repository.PersonalTokenRepository // Dependency on PersonalTokenRepository
+	auditService AuditService                       // Token creation and revocation are recorded in the audit log
+	maxLifetime  time.Duration                      // Latest allowed expiry, counted from creation
+	readOnly     *readonly.Mode                     // Token use isn't recorded in read-only mode
+}
+
+// NewPersonalTokenService creates a new PersonalTokenService instance
+func NewPersonalTokenService(tokenRepo repository.PersonalTokenRepository, auditService AuditService, maxLifetime time.Duration, readOnly *readonly.Mode) PersonalTokenService {
+	return &personalTokenService{
+		tokenRepo:    tokenRepo,
+		auditService: auditService,
+		maxLifetime:  maxLifetime,
+		readOnly:     readOnly,
+	}
+}
+
+// CreateToken creates a personal access token for a user. The token is returned once, alongside its model.
+func (s *personalTokenService) CreateToken(ctx context.Context, userID uint, role string, req *models.CreatePersonalAccessTokenRequest) (*models.PersonalAccessToken, string, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, "", err
+	}
+	now := time.Now()
+	if !req.ExpiresAt.After(now) || req.ExpiresAt.After(now.Add(s.maxLifetime)) {
+		return nil, "", errors.New("expiresAt must be in the future and within the maximum token lifetime")
+	}
+	scopes := map[string]bool{}
+	for _, scope := range req.Scopes {
+		scopes[scope] = true
+	}
+	if scopes[auth.ScopeAdmin] && role != models.RoleAdmin {
+		return nil, "", errors.New("the admin scope requires the admin role")
+	}
+
+	count, err := s.tokenRepo.CountTokensByUserID(ctx, userID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create personal access token: %w", err)
+	}
+	if count >= maxPersonalTokensPerUser {
+		return nil, "", errors.New("personal access token limit reached")
+	}
+
+	secret, hash, err := auth.GeneratePersonalToken()
+	if err != nil {
+		return nil, "", err
+	}
+	scopeList := make([]string, 0, len(scopes))
+	for scope := range scopes {
+		scopeList = append(scopeList, scope)
+	}
+	sort.Strings(scopeList)
+
+	token := &models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    secret[:len(auth.PersonalTokenPrefix)+4],
+		TokenHash: hash,
+		Scopes:    strings.Join(scopeList, " "),
+		ExpiresAt: req.ExpiresAt,
+	}
+	if err := s.tokenRepo.CreateToken(ctx, token); err != nil {
+		logger.Error("Failed to create personal access token in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, "", fmt.Errorf("failed to create personal access token: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, "token.created", "personal_access_token", token.ID, map[string]interface{}{
+		"name":      token.Name,
+		"scopes":    scopeList,
+		"expiresAt": token.ExpiresAt,
+	}); err != nil {
+		logger.Warn("Personal access token audit event was not recorded", zap.Error(err), zap.Uint("tokenID", token.ID))
+	}
+	logger.Info("Personal access token created", zap.Uint("tokenID", token.ID), zap.Uint("userID", userID), actor.Field(ctx))
+	return token, secret, nil
+}
+
+// GetTokens lists a user's unrevoked personal access tokens, including expired ones
+func (s *personalTokenService) GetTokens(ctx context.Context, userID uint) ([]*models.PersonalAccessToken, error) {
+	tokens, err := s.tokenRepo.GetTokensByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get personal access tokens in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to retrieve personal access tokens: %w", err)
+	}
+	return tokens, nil
+}
+
+// RevokeToken revokes one of the user's tokens. Other users' tokens are reported as not found.
+func (s *personalTokenService) RevokeToken(ctx context.Context, tokenID uint, userID uint) error {
+	if err := s.tokenRepo.RevokeToken(ctx, tokenID, userID); err != nil {
+		logger.Debug("Personal access token not found for revocation", zap.Error(err), zap.Uint("tokenID", tokenID), zap.Uint("userID", userID))
+		return errors.New("personal access token not found")
+	}
+
+	if err := s.auditService.Record(ctx, "token.revoked", "personal_access_token", tokenID, nil); err != nil {
+		logger.Warn("Personal access token audit event was not recorded", zap.Error(err), zap.Uint("tokenID", tokenID))
+	}
+	logger.Info("Personal access token revoked", zap.Uint("tokenID", tokenID), zap.Uint("userID", userID), actor.Field(ctx))
+	return nil
+}
+
+// Authenticate resolves a personal access token to the actor it acts as.
+// The actor carries the token's scopes and the owner's current role.
+func (s *personalTokenService) Authenticate(ctx context.Context, secret string) (actor.Actor, error) {
+	token, err := s.tokenRepo.GetActiveTokenByHash(ctx, auth.HashPersonalToken(secret))
+	if err != nil {
+		return actor.Actor{}, err
+	}
+	now := time.Now()
+	if token == nil || now.After(token.ExpiresAt) {
+		return actor.Actor{}, errors.New("invalid or expired personal access token")
+	}
+
+	if (token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > personalTokenTouchInterval) && !s.readOnly.Enabled() {
+		_ = s.tokenRepo.TouchToken(ctx, token.ID, now) // Best effort, the repository logs failures
+	}
+
+	a := actor.NewUser(token.UserID, token.UserRole, actor.ViaPersonalToken)
+	a.Scopes = token.ScopeList()
+	a.TokenID = token.ID
+	return a, nil
+}
@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ProcessingLogService defines the interface for the personal data processing log
+type ProcessingLogService interface {
+	Record(ctx context.Context, activity, purpose, resource string, subjectUserID *uint, detail string) error
+	GetRecords(ctx context.Context, query *models.ProcessingLogQuery, page, pageSize int) ([]*models.ProcessingRecord, int64, error)
+}
+
+// processingLogService implements ProcessingLogService
+type processingLogService struct {
+	processingRepo repository.ProcessingRepository // Dependency on ProcessingRepository
+}
+
+// NewProcessingLogService creates a new ProcessingLogService instance
+func NewProcessingLogService(processingRepo repository.ProcessingRepository) ProcessingLogService {
+	return &processingLogService{
+		processingRepo: processingRepo,
+	}
+}
+
+// Record appends an entry attributed to the actor in ctx. Unlike audit events, callers must not hand out
+// the data when this fails: processing that can't be accounted for must not happen.
+func (s *processingLogService) Record(ctx context.Context, activity, purpose, resource string, subjectUserID *uint, detail string) error {
+	if !models.IsProcessingPurpose(purpose) {
+		return fmt.Errorf("%sunknown processing purpose %q", validation.ErrorPrefix, purpose)
+	}
+	record := &models.ProcessingRecord{
+		Activity:      activity,
+		Purpose:       purpose,
+		Resource:      resource,
+		SubjectUserID: subjectUserID,
+		Detail:        detail,
+		CreatedAt:     time.Now(),
+	}
+	if a, ok := actor.FromContext(ctx); ok {
+		record.ActingUserID = a.UserID
+		record.EffectiveUserID = a.EffectiveUserID
+	}
+
+	if err := s.processingRepo.CreateProcessingRecord(ctx, record); err != nil {
+		logger.Error("Failed to record processing", zap.Error(err), zap.String("activity", activity), zap.String("resource", resource), actor.Field(ctx))
+		return fmt.Errorf("failed to record processing: %w", err)
+	}
+	logger.Debug("Processing recorded", zap.String("activity", activity), zap.String("purpose", purpose), zap.String("resource", resource), actor.Field(ctx))
+	return nil
+}
+
+// GetRecords retrieves a page of the processing log, newest first
+func (s *processingLogService) GetRecords(ctx context.Context, query *models.ProcessingLogQuery, page, pageSize int) ([]*models.ProcessingRecord, int64, error) {
+	records, total, err := s.processingRepo.GetProcessingRecords(ctx, query, (page-1)*pageSize, pageSize)
+	if err != nil {
+		logger.Error("Failed to get processing records in repository", zap.Error(err))
+		return nil, 0, fmt.Errorf("failed to retrieve processing log: %w", err)
+	}
+	return records, total, nil
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// errBatchOperationFailed rolls back the savepoint of a failed batch operation
+var errBatchOperationFailed = errors.New("batch operation failed")
+
+// BatchProducts runs a user's creates, updates and deletes in one transaction, each operation in its own savepoint.
+// In atomic mode the first failure rolls back the whole batch; in best-effort mode only the failed operation is
+// rolled back and the others commit. Audit events are recorded once the transaction committed.
+func (s *productService) BatchProducts(ctx context.Context, userID uint, req *models.ProductBatchRequest) (*models.ProductBatchResult, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	mode := req.Mode
+	if mode == "" {
+		mode = models.ProductBatchAtomic
+	}
+
+	result := &models.ProductBatchResult{Mode: mode, Results: make([]*models.ProductBatchOperationResult, len(req.Operations))}
+	var pendingAudits []func()
+	err := s.productRepo.Transaction(ctx, func(tx repository.ProductRepository) error {
+		for i, op := range req.Operations {
+			audited := len(pendingAudits)
+			var res *models.ProductBatchOperationResult
+			err := tx.Transaction(ctx, func(savepoint repository.ProductRepository) error {
+				txService := &productService{productRepo: savepoint, permissionRepo: s.permissionRepo, userRepo: s.userRepo, auditService: s.auditService, pendingAudits: &pendingAudits}
+				res = txService.applyBatchOperation(ctx, userID, i, op)
+				if res.Error != "" {
+					return errBatchOperationFailed
+				}
+				return nil
+			})
+			if err != nil && !errors.Is(err, errBatchOperationFailed) {
+				return err
+			}
+			result.Results[i] = res
+
+			if res.Error == "" {
+				result.Succeeded++
+				continue
+			}
+			result.Failed++
+			pendingAudits = pendingAudits[:audited] // The operation was rolled back
+			if mode == models.ProductBatchAtomic {
+				return errBatchOperationFailed
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errBatchOperationFailed) {
+		logger.Error("Product batch failed", zap.Error(err), zap.Uint("userID", userID), actor.Field(ctx))
+		return nil, fmt.Errorf("failed to apply product batch: %w", err)
+	}
+
+	if err != nil {
+		// Atomic batch rolled back: nothing applied, including the operations that had succeeded
+		for i, res := range result.Results {
+			if res == nil || res.Error == "" {
+				result.Results[i] = &models.ProductBatchOperationResult{Index: i, Op: req.Operations[i].Op, Status: http.StatusFailedDependency, Error: "not applied, another operation of the atomic batch failed"}
+			}
+		}
+		result.Succeeded = 0
+		logger.Info("Atomic product batch rolled back", zap.Uint("userID", userID), zap.Int("operations", len(req.Operations)), actor.Field(ctx))
+		return result, nil
+	}
+
+	result.Committed = true
+	for _, record := range pendingAudits {
+		record()
+	}
+	logger.Info("Product batch committed", zap.Uint("userID", userID), zap.String("mode", mode), zap.Int("succeeded", result.Succeeded), zap.Int("failed", result.Failed), actor.Field(ctx))
+	return result, nil
+}
+
+// applyBatchOperation runs one batch operation through the single-product methods, so it gets the same
+// validation, ownership checks and preconditions as the REST endpoints
+func (s *productService) applyBatchOperation(ctx context.Context, userID uint, index int, op *models.ProductBatchOperation) *models.ProductBatchOperationResult {
+	res := &models.ProductBatchOperationResult{Index: index, Op: op.Op}
+	var pre *models.ProductPrecondition
+	if op.Version != nil {
+		pre = &models.ProductPrecondition{Versions: []int64{*op.Version}}
+	}
+
+	var product *models.Product
+	var err error
+	switch op.Op {
+	case models.ProductBatchCreate:
+		var created bool
+		product, created, err = s.AddProduct(ctx, userID, &models.AddProductRequest{Name: op.Name, Description: op.Description, Price: op.Price, ClientID: op.ClientID})
+		res.Status = http.StatusCreated
+		if !created {
+			res.Status = http.StatusOK
+		}
+	case models.ProductBatchUpdate:
+		product, err = s.UpdateProduct(ctx, op.ID, userID, &models.UpdateProductRequest{Name: op.Name, Description: op.Description, Price: op.Price}, pre)
+		res.Status = http.StatusOK
+	case models.ProductBatchDelete:
+		err = s.DeleteProduct(ctx, op.ID, userID, pre)
+		res.Status = http.StatusNoContent
+	}
+
+	var conflict *ProductConflictError
+	switch {
+	case err == nil:
+		if product != nil {
+			res.Product = models.NewProductResponse(product)
+		}
+	case strings.HasPrefix(err.Error(), validation.ErrorPrefix):
+		res.Status, res.Error = http.StatusBadRequest, err.Error()
+	case errors.Is(err, ErrProductNotFound):
+		res.Status, res.Error = http.StatusNotFound, err.Error()
+	case errors.Is(err, ErrProductForbidden):
+		res.Status, res.Error = http.StatusForbidden, err.Error()
+	case errors.As(err, &conflict):
+		res.Status, res.Error = http.StatusPreconditionFailed, err.Error()
+		if conflict.Current != nil {
+			res.Product = models.NewProductResponse(conflict.Current)
+		}
+	default:
+		res.Status, res.Error = http.StatusInternalServerError, "failed to "+op.Op+" product"
+	}
+	return res
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// changeSettleTime is how old a change must be before the feed returns it. Sequence numbers are taken when a
+// change is written, not when it commits, so a transaction committing late could otherwise land behind a token
+// a client already holds. Transactions writing products must commit within this time.
+const changeSettleTime = 5 * time.Second
+
+// changePruneInterval is how often changes older than the retention are deleted
+const changePruneInterval = time.Hour
+
+// ProductChangeService defines the interface of the product change feed, for clients syncing incrementally
+type ProductChangeService interface {
+	GetChanges(ctx context.Context, userID uint, token string, limit int) (*models.ProductChangesResponse, error)
+	Run(ctx context.Context) // Prunes changes older than the retention, until ctx is cancelled
+}
+
+// productChangeService implements ProductChangeService
+type productChangeService struct {
+	changeRepo  repository.ProductChangeRepository
+	productRepo repository.ProductRepository // Changes are returned with the product's current state
+	cfg         config.SyncConfig
+}
+
+// NewProductChangeService creates a new ProductChangeService instance
+func NewProductChangeService(changeRepo repository.ProductChangeRepository, productRepo repository.ProductRepository, cfg config.SyncConfig) ProductChangeService {
+	return &productChangeService{
+		changeRepo:  changeRepo,
+		productRepo: productRepo,
+		cfg:         cfg,
+	}
+}
+
+// GetChanges returns the user's products changed since token, oldest change first, up to limit changes.
+// Without a token it returns all of the user's products, the starting point of a sync.
+// A product changed several times is returned once, in its current state, or as deleted if it is gone.
+func (s *productChangeService) GetChanges(ctx context.Context, userID uint, token string, limit int) (*models.ProductChangesResponse, error) {
+	now := time.Now()
+	settledBefore := now.Add(-changeSettleTime)
+
+	if token == "" {
+		// Read the position before the products, so changes made in between are returned again rather than missed
+		seq, err := s.changeRepo.GetLatestProductChangeSeq(ctx, settledBefore)
+		if err != nil {
+			return nil, err
+		}
+		products, err := s.productRepo.GetProductsByUserID(ctx, userID, models.TimeRange{})
+		if err != nil {
+			return nil, err
+		}
+		res := &models.ProductChangesResponse{Changes: make([]*models.ProductChangeResponse, 0, len(products)), Token: encodeChangeToken(seq, now)}
+		for _, p := range products {
+			res.Changes = append(res.Changes, &models.ProductChangeResponse{ID: p.ID, Product: models.NewProductResponse(p)})
+		}
+		return res, nil
+	}
+
+	afterSeq, issuedAt, err := decodeChangeToken(token)
+	if err != nil {
+		return nil, err
+	}
+	// Changes after the token may have been pruned once the token is older than the retention
+	if issuedAt.Before(now.Add(-s.cfg.ChangeRetention + changeSettleTime)) {
+		return nil, errors.New("change token expired")
+	}
+
+	changes, err := s.changeRepo.GetProductChanges(ctx, userID, afterSeq, settledBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keep the last change of each product, in feed order
+	last := make(map[uint]int, len(changes))
+	for i, change := range changes {
+		last[change.ProductID] = i
+	}
+	var ids []uint
+	for i, change := range changes {
+		if last[change.ProductID] == i {
+			ids = append(ids, change.ProductID)
+		}
+	}
+
+	current := make(map[uint]*models.Product, len(ids))
+	if len(ids) > 0 {
+		products, err := s.productRepo.GetProductsByFilter(ctx, &models.ProductFilter{IDs: ids, UserID: &userID}, 0, len(ids))
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range products {
+			current[p.ID] = p
+		}
+	}
+
+	res := &models.ProductChangesResponse{Changes: make([]*models.ProductChangeResponse, 0, len(ids)), HasMore: len(changes) == limit}
+	for _, id := range ids {
+		if p, ok := current[id]; ok {
+			res.Changes = append(res.Changes, &models.ProductChangeResponse{ID: id, Product: models.NewProductResponse(p)})
+		} else {
+			res.Changes = append(res.Changes, &models.ProductChangeResponse{ID: id, Deleted: true}) // Deleted, or moved to another owner
+		}
+	}
+	if len(changes) > 0 {
+		afterSeq = changes[len(changes)-1].Seq
+	}
+	res.Token = encodeChangeToken(afterSeq, now)
+	return res, nil
+}
+
+// Run deletes changes older than the retention every changePruneInterval until ctx is cancelled
+func (s *productChangeService) Run(ctx context.Context) {
+	for {
+		pruned, err := s.changeRepo.DeleteProductChangesBefore(ctx, time.Now().Add(-s.cfg.ChangeRetention))
+		if err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to prune product changes", zap.Error(err))
+		} else if pruned > 0 {
+			logger.Info("Product changes pruned", zap.Int64("count", pruned))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(changePruneInterval):
+		}
+	}
+}
+
+// encodeChangeToken makes the opaque token of a feed position, stamped with when it was issued
+func encodeChangeToken(seq int64, issuedAt time.Time) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d.%d", seq, issuedAt.Unix())))
+}
+
+// decodeChangeToken reads a token made by encodeChangeToken
+func decodeChangeToken(token string) (seq int64, issuedAt time.Time, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	var issued int64
+	if err == nil {
+		_, err = fmt.Sscanf(string(raw), "%d.%d", &seq, &issued)
+	}
+	if err != nil || seq < 0 {
+		return 0, time.Time{}, errors.New("invalid change token")
+	}
+	return seq, time.Unix(issued, 0), nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// GrantProductPermission gives a user read or write access to one of the owner's products.
+// Granting a user who already has access changes their access level.
+func (s *productService) GrantProductPermission(ctx context.Context, productID uint, ownerID uint, req *models.GrantProductPermissionRequest) (*models.ProductPermission, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	product, err := s.ownedProduct(ctx, productID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.UserID == product.UserID {
+		return nil, fmt.Errorf("%sthe owner already has full access", validation.ErrorPrefix)
+	}
+
+	permission := &models.ProductPermission{ProductID: productID, UserID: req.UserID, Access: req.Access, GrantedBy: ownerID}
+	if a, ok := actor.FromContext(ctx); ok {
+		permission.GrantedBy = a.UserID // The admin, when impersonating
+	}
+	granted, err := s.permissionRepo.UpsertProductPermission(ctx, permission)
+	if err != nil {
+		return nil, fmt.Errorf("failed to grant product permission: %w", err)
+	}
+	if !granted {
+		return nil, errors.New("user not found")
+	}
+
+	s.audit(ctx, "product.permission_granted", product, map[string]interface{}{"userId": req.UserID, "access": req.Access})
+	logger.Info("Product permission granted", zap.Uint("productID", productID), zap.Uint("granteeID", req.UserID), zap.String("access", req.Access), actor.Field(ctx))
+	return permission, nil
+}
+
+// GetProductPermissions lists the users one of the owner's products is shared with
+func (s *productService) GetProductPermissions(ctx context.Context, productID uint, ownerID uint) ([]*models.ProductPermission, error) {
+	if _, err := s.ownedProduct(ctx, productID, ownerID); err != nil {
+		return nil, err
+	}
+	permissions, err := s.permissionRepo.GetProductPermissionsByProductID(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product permissions: %w", err)
+	}
+	return permissions, nil
+}
+
+// RevokeProductPermission removes a user's access to one of the owner's products
+func (s *productService) RevokeProductPermission(ctx context.Context, productID uint, ownerID uint, userID uint) error {
+	product, err := s.ownedProduct(ctx, productID, ownerID)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.permissionRepo.DeleteProductPermission(ctx, productID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke product permission: %w", err)
+	}
+	if !revoked {
+		return errors.New("permission not found")
+	}
+
+	s.audit(ctx, "product.permission_revoked", product, map[string]interface{}{"userId": userID})
+	logger.Info("Product permission revoked", zap.Uint("productID", productID), zap.Uint("granteeID", userID), actor.Field(ctx))
+	return nil
+}
+
+// GetSharedProducts lists the products other users gave the user access to, most recently shared first
+func (s *productService) GetSharedProducts(ctx context.Context, userID uint) ([]*models.SharedProduct, error) {
+	permissions, err := s.permissionRepo.GetProductPermissionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve shared products: %w", err)
+	}
+	if len(permissions) == 0 {
+		return []*models.SharedProduct{}, nil
+	}
+
+	ids := make([]uint, len(permissions))
+	for i, p := range permissions {
+		ids[i] = p.ProductID
+	}
+	products, err := s.productRepo.GetProductsByFilter(ctx, &models.ProductFilter{IDs: ids}, 0, len(ids))
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve shared products: %w", err)
+	}
+	byID := make(map[uint]*models.Product, len(products))
+	for _, p := range products {
+		byID[p.ID] = p
+	}
+
+	shared := make([]*models.SharedProduct, 0, len(permissions))
+	for _, p := range permissions {
+		if product, ok := byID[p.ProductID]; ok && product.UserID != userID { // Skip products the user has since become the owner of
+			shared = append(shared, &models.SharedProduct{Product: product, Access: p.Access})
+		}
+	}
+
+	// The products come from many owners, whom the listing shows
+	sharedProducts := make([]*models.Product, len(shared))
+	for i, p := range shared {
+		sharedProducts[i] = p.Product
+	}
+	if err := loadOwners(ctx, newUserLoader(s.userRepo), sharedProducts); err != nil {
+		return nil, fmt.Errorf("failed to retrieve shared products: %w", err)
+	}
+	return shared, nil
+}
+
+// ownedProduct loads a product its owner is managing the permissions of. It is not found for users who can't read it.
+func (s *productService) ownedProduct(ctx context.Context, productID uint, ownerID uint) (*models.Product, error) {
+	product, err := s.readableProduct(ctx, productID, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if product.UserID != ownerID {
+		logger.Warn("Unauthorized attempt to manage product permissions", zap.Uint("productID", productID), zap.Uint("attemptingUserID", ownerID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+		return nil, ErrProductForbidden
+	}
+	return product, nil
+}
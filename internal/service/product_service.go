@@ -2,10 +2,18 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"gotemplate/internal/models"
 	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/deadline"
 	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"io"
+	"math"
+	"time"
 
 	// Added for string to uint conversion
 	// "github.com/google/uuid" // No longer needed for UUID generation
@@ -15,27 +23,92 @@ import (
 // ProductService defines the interface for product-related business logic
 type ProductService interface {
 	// Changed userID and productID to uint
-	AddProduct(ctx context.Context, userID uint, req *models.AddProductRequest) (*models.Product, error)
-	GetProduct(ctx context.Context, productID uint) (*models.Product, error)
-	GetProductsByOwner(ctx context.Context, userID uint) ([]*models.Product, error)
-	UpdateProduct(ctx context.Context, productID uint, userID uint, req *models.UpdateProductRequest) (*models.Product, error)
-	DeleteProduct(ctx context.Context, productID uint, userID uint) error
+	AddProduct(ctx context.Context, userID uint, req *models.AddProductRequest) (product *models.Product, created bool, err error)
+	// GetProduct retrieves a product for a user with a role. Only its owner, users it was shared with and admins
+	// can read a product; it is not found for anyone else.
+	GetProduct(ctx context.Context, productID uint, userID uint, role string) (*models.Product, error)
+	GetProductsByOwner(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Product, error)
+	StreamProductsByOwner(ctx context.Context, userID uint, timeRange models.TimeRange, fn func(*models.Product) error) error
+	UpdateProduct(ctx context.Context, productID uint, userID uint, req *models.UpdateProductRequest, pre *models.ProductPrecondition) (*models.Product, error)
+	DeleteProduct(ctx context.Context, productID uint, userID uint, pre *models.ProductPrecondition) error
+	BatchProducts(ctx context.Context, userID uint, req *models.ProductBatchRequest) (*models.ProductBatchResult, error)
+	GrantProductPermission(ctx context.Context, productID uint, ownerID uint, req *models.GrantProductPermissionRequest) (*models.ProductPermission, error)
+	GetProductPermissions(ctx context.Context, productID uint, ownerID uint) ([]*models.ProductPermission, error)
+	RevokeProductPermission(ctx context.Context, productID uint, ownerID uint, userID uint) error
+	GetSharedProducts(ctx context.Context, userID uint) ([]*models.SharedProduct, error)
+	BulkUpdateProducts(ctx context.Context, req *models.BulkUpdateProductsRequest, report ProgressFunc) (*models.BulkUpdateResult, error)
+	ImportProducts(ctx context.Context, userID uint, format string, connector ImportConnector, report ProgressFunc) (*models.ImportResult, error)
 }
 
+// importErrorSampleSize is the number of per-record errors included in an import result
+const importErrorSampleSize = 100
+
+// bulkUpdateSampleSize is the number of per-product previews included in a bulk update result
+const bulkUpdateSampleSize = 20
+
+// bulkUpdateBatchBudget is the request budget that must be left to start another bulk update batch
+const bulkUpdateBatchBudget = 500 * time.Millisecond
+
+// ProductConflictError is returned by writes whose precondition failed.
+// Current is the product as stored, nil if it was deleted meanwhile.
+type ProductConflictError struct {
+	Current *models.Product
+}
+
+func (e *ProductConflictError) Error() string {
+	return "product was modified"
+}
+
+// ErrProductNotFound is returned for products that don't exist and for products the user can't read, so their
+// existence isn't revealed
+var ErrProductNotFound = errors.New("product not found")
+
+// ErrProductForbidden is returned to users who can read a product but not make the change they asked for
+var ErrProductForbidden = errors.New("you are not authorized to change this product")
+
 // productService implements ProductService
 type productService struct {
-	productRepo repository.ProductRepository // Dependency on ProductRepository
+	productRepo    repository.ProductRepository           // Dependency on ProductRepository
+	permissionRepo repository.ProductPermissionRepository // Access other users were granted on products
+	auditService   AuditService                           // Product mutations are recorded in the audit log
+	userRepo       repository.UserRepository              // Owners embedded in listings of other users' products
+	// pendingAudits, when set, collects audit events instead of recording them, until the transaction
+	// the mutations run in has committed; see BatchProducts
+	pendingAudits *[]func()
 }
 
 // NewProductService creates a new ProductService instance
-func NewProductService(productRepo repository.ProductRepository) ProductService {
+func NewProductService(productRepo repository.ProductRepository, permissionRepo repository.ProductPermissionRepository, userRepo repository.UserRepository, auditService AuditService) ProductService {
 	return &productService{
-		productRepo: productRepo,
+		productRepo:    productRepo,
+		permissionRepo: permissionRepo,
+		userRepo:       userRepo,
+		auditService:   auditService,
+	}
+}
+
+// audit records a product audit event. Failures are logged, not returned, since the mutation already happened.
+func (s *productService) audit(ctx context.Context, action string, product *models.Product, metadata map[string]interface{}) {
+	if s.pendingAudits != nil {
+		snapshot, direct := *product, &productService{auditService: s.auditService}
+		*s.pendingAudits = append(*s.pendingAudits, func() { direct.audit(ctx, action, &snapshot, metadata) })
+		return
+	}
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["name"] = product.Name
+	if err := s.auditService.Record(ctx, action, "product", product.ID, metadata); err != nil {
+		logger.Warn("Product audit event was not recorded", zap.Error(err), zap.String("action", action), zap.Uint("productID", product.ID))
 	}
 }
 
-// AddProduct adds a new product for a user
-func (s *productService) AddProduct(ctx context.Context, userID uint, req *models.AddProductRequest) (*models.Product, error) {
+// AddProduct adds a new product for a user.
+// A request with a client ID the user already created a product with returns that product, with created false.
+func (s *productService) AddProduct(ctx context.Context, userID uint, req *models.AddProductRequest) (*models.Product, bool, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, false, err
+	}
 	product := &models.Product{
 		// ID, CreatedAt, UpdatedAt are handled by gorm.Model and the repository's raw SQL returning clause
 		Name:        req.Name,
@@ -43,30 +116,58 @@ func (s *productService) AddProduct(ctx context.Context, userID uint, req *model
 		Price:       req.Price,
 		UserID:      userID, // UserID is now uint
 	}
+	if req.ClientID != "" {
+		if existing, err := s.productRepo.GetProductByUserAndClientID(ctx, userID, req.ClientID); err != nil {
+			return nil, false, fmt.Errorf("failed to add product: %w", err)
+		} else if existing != nil {
+			logger.Info("Replayed product create returned the existing product", zap.Uint("productID", existing.ID), actor.Field(ctx))
+			return existing, false, nil
+		}
+		clientID := req.ClientID
+		product.ClientID = &clientID
+	}
 
 	if err := s.productRepo.AddProduct(ctx, product); err != nil {
+		// A concurrent replay of the same create won the race
+		if product.ClientID != nil && database.IsUniqueViolation(err) {
+			if existing, getErr := s.productRepo.GetProductByUserAndClientID(ctx, userID, req.ClientID); getErr == nil && existing != nil {
+				return existing, false, nil
+			}
+		}
 		logger.Error("Failed to add product in repository", zap.Error(err), zap.Uint("userID", userID)) // Changed userID to uint
-		return nil, fmt.Errorf("failed to add product: %w", err)
+		return nil, false, fmt.Errorf("failed to add product: %w", err)
 	}
 
-	logger.Info("Product added successfully", zap.Uint("productID", product.ID), zap.Uint("userID", userID)) // Changed userID and productID to uint
-	return product, nil
+	s.audit(ctx, "product.created", product, map[string]interface{}{"price": product.Price})
+	logger.Info("Product added successfully", zap.Uint("productID", product.ID), zap.Uint("userID", userID), actor.Field(ctx)) // Changed userID and productID to uint
+	return product, true, nil
 }
 
-// GetProduct retrieves a product by its ID
-func (s *productService) GetProduct(ctx context.Context, productID uint) (*models.Product, error) { // Changed productID to uint
+// GetProduct retrieves a product by its ID, if the user may read it. Products the user can't read are reported
+// as not found, so their existence isn't revealed.
+func (s *productService) GetProduct(ctx context.Context, productID uint, userID uint, role string) (*models.Product, error) { // Changed productID to uint
 	product, err := s.productRepo.GetProductByID(ctx, productID)
 	if err != nil {
 		logger.Error("Failed to get product by ID in repository", zap.Error(err), zap.Uint("productID", productID)) // Changed productID to uint
-		return nil, fmt.Errorf("product not found: %w", err)
+		return nil, ErrProductNotFound
+	}
+
+	// Authorization check: the owner, a user the product was shared with, or an admin
+	allowed, err := s.canRead(ctx, product, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product: %w", err)
+	}
+	if !allowed {
+		logger.Warn("Unauthorized attempt to read product", zap.Uint("productID", productID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+		return nil, ErrProductNotFound
 	}
 	logger.Debug("Product retrieved", zap.Uint("productID", productID)) // Changed productID to uint
 	return product, nil
 }
 
-// GetProductsByOwner retrieves all products owned by a specific user
-func (s *productService) GetProductsByOwner(ctx context.Context, userID uint) ([]*models.Product, error) { // Changed userID to uint
-	products, err := s.productRepo.GetProductsByUserID(ctx, userID)
+// GetProductsByOwner retrieves all products owned by a specific user, created or updated within timeRange
+func (s *productService) GetProductsByOwner(ctx context.Context, userID uint, timeRange models.TimeRange) ([]*models.Product, error) { // Changed userID to uint
+	products, err := s.productRepo.GetProductsByUserID(ctx, userID, timeRange)
 	if err != nil {
 		logger.Error("Failed to get products by user ID in repository", zap.Error(err), zap.Uint("userID", userID)) // Changed userID to uint
 		return nil, fmt.Errorf("failed to retrieve products: %w", err)
@@ -75,21 +176,43 @@ func (s *productService) GetProductsByOwner(ctx context.Context, userID uint) ([
 	return products, nil
 }
 
-// UpdateProduct updates an existing product. Ensures the product belongs to the user.
-func (s *productService) UpdateProduct(ctx context.Context, productID uint, userID uint, req *models.UpdateProductRequest) (*models.Product, error) { // Changed IDs to uint
-	product, err := s.productRepo.GetProductByID(ctx, productID)
+// StreamProductsByOwner calls fn with each product owned by a user, created or updated within timeRange, as it is
+// read from the database, so listings of any size can be written without holding them in memory
+func (s *productService) StreamProductsByOwner(ctx context.Context, userID uint, timeRange models.TimeRange, fn func(*models.Product) error) error {
+	if err := s.productRepo.StreamProductsByUserID(ctx, userID, timeRange, fn); err != nil {
+		return fmt.Errorf("failed to retrieve products: %w", err)
+	}
+	return nil
+}
+
+// UpdateProduct updates an existing product. Ensures the product belongs to the user, or was shared with them for writing;
+// it is not found for users who can't read it. With a precondition the update only happens if the product is still in the expected state, otherwise a
+// *ProductConflictError carries the stored product.
+func (s *productService) UpdateProduct(ctx context.Context, productID uint, userID uint, req *models.UpdateProductRequest, pre *models.ProductPrecondition) (*models.Product, error) { // Changed IDs to uint
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	product, err := s.readableProduct(ctx, productID, userID)
 	if err != nil {
-		logger.Error("Product not found for update", zap.Error(err), zap.Uint("productID", productID)) // Changed productID to uint
-		return nil, fmt.Errorf("product not found: %w", err)
+		return nil, err
 	}
 
-	// Authorization check: ensure the current user owns the product
-	// Both product.UserID and userID are now uint
-	if product.UserID != userID {
-		logger.Warn("Unauthorized attempt to update product", zap.Uint("productID", productID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID))
-		return nil, fmt.Errorf("you are not authorized to update this product")
+	// Authorization check: the owner, or a user granted write access
+	allowed, err := s.canWrite(ctx, product, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update product: %w", err)
+	}
+	if !allowed {
+		logger.Warn("Unauthorized attempt to update product", zap.Uint("productID", productID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+		return nil, ErrProductForbidden
+	}
+	if pre != nil && !pre.Holds(product) {
+		return nil, &ProductConflictError{Current: product}
 	}
 
+	// Keep the previous values so the audit trail can record what changed
+	before := *product
+
 	// Update fields if provided
 	if req.Name != "" {
 		product.Name = req.Name
@@ -105,34 +228,303 @@ func (s *productService) UpdateProduct(ctx context.Context, productID uint, user
 	// but the DB update relies on the repository.
 	// product.UpdatedAt = time.Now() // No longer strictly necessary here, but doesn't hurt
 
-	if err := s.productRepo.UpdateProduct(ctx, product); err != nil {
+	if pre != nil {
+		// The precondition held when read; the update checks the version again so a concurrent write isn't overwritten
+		updated, err := s.productRepo.UpdateProductIfUnmodified(ctx, product, before.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to update product in repository", zap.Error(err), zap.Uint("productID", productID))
+			return nil, fmt.Errorf("failed to update product: %w", err)
+		}
+		if !updated {
+			return nil, s.conflict(ctx, productID)
+		}
+	} else if err := s.productRepo.UpdateProduct(ctx, product); err != nil {
 		logger.Error("Failed to update product in repository", zap.Error(err), zap.Uint("productID", productID)) // Changed productID to uint
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
 
-	logger.Info("Product updated successfully", zap.Uint("productID", product.ID)) // Changed productID to uint
+	changes := map[string]interface{}{}
+	if before.Name != product.Name {
+		changes["previousName"] = before.Name
+	}
+	if before.Description != product.Description {
+		changes["description"] = map[string]interface{}{"from": before.Description, "to": product.Description}
+	}
+	if before.Price != product.Price {
+		changes["price"] = map[string]interface{}{"from": before.Price, "to": product.Price}
+	}
+	s.audit(ctx, "product.updated", product, changes)
+
+	logger.Info("Product updated successfully", zap.Uint("productID", product.ID), actor.Field(ctx)) // Changed productID to uint
 	return product, nil
 }
 
-// DeleteProduct deletes a product. Ensures the product belongs to the user.
-func (s *productService) DeleteProduct(ctx context.Context, productID uint, userID uint) error { // Changed IDs to uint
-	product, err := s.productRepo.GetProductByID(ctx, productID)
+// DeleteProduct deletes a product. Ensures the product belongs to the user; write access doesn't allow deleting.
+// Preconditions and products the user can't read work as in UpdateProduct.
+func (s *productService) DeleteProduct(ctx context.Context, productID uint, userID uint, pre *models.ProductPrecondition) error { // Changed IDs to uint
+	product, err := s.readableProduct(ctx, productID, userID)
 	if err != nil {
-		logger.Error("Product not found for deletion", zap.Error(err), zap.Uint("productID", productID)) // Changed productID to uint
-		return fmt.Errorf("product not found: %w", err)
+		return err
 	}
 
 	// Authorization check: ensure the current user owns the product
 	if product.UserID != userID {
-		logger.Warn("Unauthorized attempt to delete product", zap.Uint("productID", productID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID))
-		return fmt.Errorf("you are not authorized to delete this product")
+		logger.Warn("Unauthorized attempt to delete product", zap.Uint("productID", productID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+		return ErrProductForbidden
 	}
 
-	if err := s.productRepo.DeleteProduct(ctx, productID); err != nil {
+	if pre != nil {
+		if !pre.Holds(product) {
+			return &ProductConflictError{Current: product}
+		}
+		deleted, err := s.productRepo.DeleteProductIfUnmodified(ctx, productID, product.UpdatedAt)
+		if err != nil {
+			logger.Error("Failed to delete product in repository", zap.Error(err), zap.Uint("productID", productID))
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+		if !deleted {
+			return s.conflict(ctx, productID)
+		}
+	} else if err := s.productRepo.DeleteProduct(ctx, productID); err != nil {
 		logger.Error("Failed to delete product in repository", zap.Error(err), zap.Uint("productID", productID)) // Changed productID to uint
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
-	logger.Info("Product deleted successfully", zap.Uint("productID", productID)) // Changed productID to uint
+	s.audit(ctx, "product.deleted", product, nil)
+	logger.Info("Product deleted successfully", zap.Uint("productID", productID), actor.Field(ctx)) // Changed productID to uint
 	return nil
 }
+
+// readableProduct loads a product a user is about to change. Products the user can't read are not found, as in
+// GetProduct; the caller checks the access the change needs. The role is the acting user's, if any.
+func (s *productService) readableProduct(ctx context.Context, productID uint, userID uint) (*models.Product, error) {
+	product, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		logger.Error("Failed to get product by ID in repository", zap.Error(err), zap.Uint("productID", productID))
+		return nil, ErrProductNotFound
+	}
+	var role string
+	if a, ok := actor.FromContext(ctx); ok {
+		role = a.Role
+	}
+	allowed, err := s.canRead(ctx, product, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve product: %w", err)
+	}
+	if !allowed {
+		logger.Warn("Attempt to change a product the user can't read", zap.Uint("productID", productID), zap.Uint("attemptingUserID", userID), zap.Uint("productOwnerID", product.UserID), actor.Field(ctx))
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// canRead reports whether a user may read a product: its owner, a user granted any access, or an admin
+func (s *productService) canRead(ctx context.Context, product *models.Product, userID uint, role string) (bool, error) {
+	if product.UserID == userID || role == models.RoleAdmin {
+		return true, nil
+	}
+	permission, err := s.permissionRepo.GetProductPermission(ctx, product.ID, userID)
+	if err != nil {
+		return false, err
+	}
+	return permission != nil, nil
+}
+
+// canWrite reports whether a user may update a product: its owner, or a user granted write access
+func (s *productService) canWrite(ctx context.Context, product *models.Product, userID uint) (bool, error) {
+	if product.UserID == userID {
+		return true, nil
+	}
+	permission, err := s.permissionRepo.GetProductPermission(ctx, product.ID, userID)
+	if err != nil {
+		return false, err
+	}
+	return permission != nil && permission.Access == models.ProductAccessWrite, nil
+}
+
+// conflict builds the error of a conditional write that lost a race, with the product as now stored
+func (s *productService) conflict(ctx context.Context, productID uint) error {
+	logger.Info("Conditional product write lost to a concurrent change", zap.Uint("productID", productID), actor.Field(ctx))
+	current, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil {
+		return &ProductConflictError{} // Deleted meanwhile
+	}
+	return &ProductConflictError{Current: current}
+}
+
+// BulkUpdateProducts applies an admin patch to every product matching the filter, in ID-ordered batches.
+// In dry-run mode nothing is written and the result only describes the would-be changes.
+// Every changed product gets its own audit event so the data fix is fully traceable.
+func (s *productService) BulkUpdateProducts(ctx context.Context, req *models.BulkUpdateProductsRequest, report ProgressFunc) (*models.BulkUpdateResult, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	batchSize := req.BatchSize
+	if batchSize == 0 {
+		batchSize = 100
+	}
+
+	matched, err := s.productRepo.CountProductsByFilter(ctx, &req.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count matching products: %w", err)
+	}
+	result := &models.BulkUpdateResult{DryRun: req.DryRun, Matched: matched}
+
+	var afterID uint
+	processed := 0
+	for {
+		// Dry runs are answered within the request, so stop before a batch that cannot finish in time
+		if err := deadline.Require(ctx, bulkUpdateBatchBudget); err != nil {
+			return nil, err
+		}
+		products, err := s.productRepo.GetProductsByFilter(ctx, &req.Filter, afterID, batchSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load products batch: %w", err)
+		}
+		if len(products) == 0 {
+			break
+		}
+
+		for _, product := range products {
+			before := *product
+			applyProductPatch(product, &req.Patch)
+
+			if len(result.Sample) < bulkUpdateSampleSize {
+				result.Sample = append(result.Sample, &models.BulkUpdatePreview{
+					ID:          product.ID,
+					Name:        product.Name,
+					PriceBefore: before.Price,
+					PriceAfter:  product.Price,
+				})
+			}
+			if req.DryRun || (before.Price == product.Price && before.Description == product.Description) {
+				continue
+			}
+
+			if err := s.productRepo.UpdateProduct(ctx, product); err != nil {
+				logger.Warn("Bulk update failed for product", zap.Error(err), zap.Uint("productID", product.ID))
+				result.Failed = append(result.Failed, product.ID)
+				continue
+			}
+			result.Updated++
+			s.audit(ctx, "product.bulk_updated", product, map[string]interface{}{
+				"price":       map[string]interface{}{"from": before.Price, "to": product.Price},
+				"description": map[string]interface{}{"from": before.Description, "to": product.Description},
+			})
+		}
+
+		afterID = products[len(products)-1].ID
+		processed += len(products)
+		if report != nil && matched > 0 {
+			report(int(int64(processed) * 100 / matched))
+		}
+	}
+
+	if !req.DryRun {
+		if err := s.auditService.Record(ctx, "product.bulk_update", "product", 0, map[string]interface{}{
+			"filter":  req.Filter,
+			"patch":   req.Patch,
+			"matched": result.Matched,
+			"updated": result.Updated,
+			"failed":  len(result.Failed),
+		}); err != nil {
+			logger.Warn("Bulk update audit summary was not recorded", zap.Error(err))
+		}
+	}
+
+	logger.Info("Bulk product update finished", zap.Bool("dryRun", req.DryRun), zap.Int64("matched", matched), zap.Int("updated", result.Updated), zap.Int("failed", len(result.Failed)), actor.Field(ctx))
+	return result, nil
+}
+
+// ImportProducts upserts every record from an import connector into the user's products.
+// Records are matched to existing products by exact name; a match is updated, anything else is created.
+// Bad records are counted and reported without stopping the import.
+func (s *productService) ImportProducts(ctx context.Context, userID uint, format string, connector ImportConnector, report ProgressFunc) (*models.ImportResult, error) {
+	result := &models.ImportResult{Format: format}
+	fail := func(ref string, err error) {
+		result.Failed++
+		if len(result.Errors) < importErrorSampleSize {
+			result.Errors = append(result.Errors, &models.ImportError{Ref: ref, Error: err.Error()})
+		}
+	}
+
+	lastProgress := -1
+	for {
+		record, err := connector.Next(ctx)
+		if err == io.EOF {
+			break
+		}
+		var recordErr *ImportRecordError
+		if errors.As(err, &recordErr) {
+			result.Read++
+			fail(recordErr.Ref, recordErr.Err)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read import source: %w", err)
+		}
+		result.Read++
+		// Imported records must pass the same rules as products added through the API
+		if err := validation.Struct(&models.AddProductRequest{Name: record.Name, Description: record.Description, Price: record.Price}); err != nil {
+			fail(record.Ref, err)
+			continue
+		}
+
+		existing, err := s.productRepo.GetProductByUserAndName(ctx, userID, record.Name)
+		if err != nil {
+			fail(record.Ref, err)
+			continue
+		}
+
+		if existing == nil {
+			product := &models.Product{Name: record.Name, Description: record.Description, Price: record.Price, UserID: userID}
+			if err := s.productRepo.AddProduct(ctx, product); err != nil {
+				fail(record.Ref, err)
+				continue
+			}
+			result.Created++
+			s.audit(ctx, "product.created", product, map[string]interface{}{"price": product.Price, "source": format})
+		} else if existing.Description != record.Description || existing.Price != record.Price {
+			before := *existing
+			existing.Description = record.Description
+			existing.Price = record.Price
+			if err := s.productRepo.UpdateProduct(ctx, existing); err != nil {
+				fail(record.Ref, err)
+				continue
+			}
+			result.Updated++
+			changes := map[string]interface{}{"source": format}
+			if before.Price != existing.Price {
+				changes["price"] = map[string]interface{}{"from": before.Price, "to": existing.Price}
+			}
+			if before.Description != existing.Description {
+				changes["description"] = map[string]interface{}{"from": before.Description, "to": existing.Description}
+			}
+			s.audit(ctx, "product.updated", existing, changes)
+		} else {
+			result.Skipped++
+		}
+
+		if p := connector.Progress(); report != nil && p != lastProgress {
+			report(p)
+			lastProgress = p
+		}
+	}
+
+	logger.Info("Product import finished", zap.String("format", format), zap.Int("read", result.Read), zap.Int("created", result.Created), zap.Int("updated", result.Updated), zap.Int("failed", result.Failed), actor.Field(ctx))
+	return result, nil
+}
+
+// applyProductPatch applies a bulk patch to a product in memory
+func applyProductPatch(product *models.Product, patch *models.ProductPatch) {
+	if patch.Price != nil {
+		product.Price = *patch.Price
+	}
+	if patch.PricePercent != nil {
+		// Round to cents and never go below one cent, products must keep a positive price
+		product.Price = math.Max(0.01, math.Round(product.Price*(100+*patch.PricePercent))/100)
+	}
+	if patch.Description != nil {
+		product.Description = *patch.Description
+	}
+}
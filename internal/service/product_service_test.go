@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"testing"
+)
+
+// Users of the product read access tests
+const (
+	ownerID    uint = 1
+	granteeID  uint = 2
+	strangerID uint = 3
+	adminID    uint = 4
+)
+
+// newSharedProductService serves product 10, owned by ownerID and shared with granteeID for reading
+func newSharedProductService() ProductService {
+	product := &models.Product{Name: "Lamp", UserID: ownerID}
+	product.ID = 10
+	products := &fakeProductRepository{products: map[uint]*models.Product{product.ID: product}}
+	permissions := &fakePermissionRepository{permissions: []*models.ProductPermission{
+		{ProductID: product.ID, UserID: granteeID, Access: models.ProductAccessRead, GrantedBy: ownerID},
+	}}
+	return NewProductService(products, permissions, newFakeUserRepository(), fakeAuditService{})
+}
+
+// TestGetProductRefusesNonGrantees checks that a product is only found for its owner, grantees and admins
+func TestGetProductRefusesNonGrantees(t *testing.T) {
+	ctx := context.Background()
+	products := newSharedProductService()
+
+	for _, reader := range []struct {
+		name   string
+		userID uint
+		role   string
+	}{
+		{"owner", ownerID, models.RoleUser},
+		{"grantee", granteeID, models.RoleUser},
+		{"admin", adminID, models.RoleAdmin},
+	} {
+		if _, err := products.GetProduct(ctx, 10, reader.userID, reader.role); err != nil {
+			t.Errorf("%s can't read the product: %v", reader.name, err)
+		}
+	}
+
+	_, err := products.GetProduct(ctx, 10, strangerID, models.RoleUser)
+	if !errors.Is(err, ErrProductNotFound) {
+		t.Fatalf("non-grantee reading the product: %v, want product not found", err)
+	}
+}
+
+// TestProductWritesHideProductsFromNonReaders checks that changes to a product the user can't read fail as not
+// found, and as forbidden only for users who can read it
+func TestProductWritesHideProductsFromNonReaders(t *testing.T) {
+	products := newSharedProductService()
+	update := &models.UpdateProductRequest{Name: "Desk"}
+
+	for _, user := range []struct {
+		name   string
+		userID uint
+		role   string
+		want   error
+	}{
+		{"stranger", strangerID, models.RoleUser, ErrProductNotFound},
+		{"read grantee", granteeID, models.RoleUser, ErrProductForbidden},
+		{"admin", adminID, models.RoleAdmin, ErrProductForbidden},
+	} {
+		ctx := actor.WithActor(context.Background(), actor.NewUser(user.userID, user.role, actor.ViaSession))
+		if _, err := products.UpdateProduct(ctx, 10, user.userID, update, nil); !errors.Is(err, user.want) {
+			t.Errorf("%s updating the product: %v, want %v", user.name, err, user.want)
+		}
+		if err := products.DeleteProduct(ctx, 10, user.userID, nil); !errors.Is(err, user.want) {
+			t.Errorf("%s deleting the product: %v, want %v", user.name, err, user.want)
+		}
+		if _, err := products.GetProductPermissions(ctx, 10, user.userID); !errors.Is(err, user.want) {
+			t.Errorf("%s listing the product's permissions: %v, want %v", user.name, err, user.want)
+		}
+	}
+
+	if err := products.DeleteProduct(context.Background(), 11, ownerID, nil); !errors.Is(err, ErrProductNotFound) {
+		t.Errorf("deleting a missing product: %v, want product not found", err)
+	}
+}
+
+// fakeCommentRepository stores comments in memory; other methods aren't implemented
+type fakeCommentRepository struct {
+	repository.CommentRepository
+	comments []*models.Comment
+}
+
+func (r *fakeCommentRepository) CreateComment(ctx context.Context, comment *models.Comment) error {
+	comment.ID = uint(len(r.comments) + 1)
+	r.comments = append(r.comments, comment)
+	return nil
+}
+
+func (r *fakeCommentRepository) GetCommentsByProductID(ctx context.Context, productID uint, viewerID uint, includeHidden bool, timeRange models.TimeRange, limit, offset int) ([]*models.Comment, int64, error) {
+	var comments []*models.Comment
+	for _, c := range r.comments {
+		if c.ProductID == productID {
+			comments = append(comments, c)
+		}
+	}
+	return comments, int64(len(comments)), nil
+}
+
+// TestCommentsRefuseNonGrantees checks that only users who can read a product read and write its comments
+func TestCommentsRefuseNonGrantees(t *testing.T) {
+	ctx := context.Background()
+	comments := NewCommentService(&fakeCommentRepository{}, nil, newSharedProductService())
+
+	if _, err := comments.AddComment(ctx, 10, granteeID, models.RoleUser, &models.CreateCommentRequest{Body: "Still available?"}); err != nil {
+		t.Fatalf("grantee can't comment: %v", err)
+	}
+	if _, err := comments.AddComment(ctx, 10, strangerID, models.RoleUser, &models.CreateCommentRequest{Body: "Hello"}); err == nil || err.Error() != "product not found" {
+		t.Fatalf("non-grantee commenting: %v, want product not found", err)
+	}
+
+	if _, total, err := comments.GetComments(ctx, 10, granteeID, models.RoleUser, models.TimeRange{}, 1, 20); err != nil || total != 1 {
+		t.Fatalf("grantee reading comments: %d comments, %v; want 1", total, err)
+	}
+	if _, _, err := comments.GetComments(ctx, 10, strangerID, models.RoleUser, models.TimeRange{}, 1, 20); err == nil || err.Error() != "product not found" {
+		t.Fatalf("non-grantee reading comments: %v, want product not found", err)
+	}
+}
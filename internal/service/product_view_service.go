@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// maxPendingProductViews caps the products and days counted in memory between flushes. Past it, views are
+// dropped rather than memory growing while the database is unreachable or the instance read-only.
+const maxPendingProductViews = 100000
+
+// productViewFlushTimeout bounds the last flush when the instance shuts down
+const productViewFlushTimeout = 5 * time.Second
+
+// productViewKey identifies the counter of a product on a day
+type productViewKey struct {
+	productID uint
+	day       time.Time
+}
+
+// ProductViewService defines the interface for product view analytics. Views are counted in memory and
+// written in batches, so viewing a product doesn't cost a write.
+type ProductViewService interface {
+	// RecordView counts a view of a product by a user. Views by the owner are not counted.
+	RecordView(product *models.Product, viewerID uint)
+	GetProductViews(ctx context.Context, productID uint, ownerID uint, from, to time.Time) (*models.ProductViewsResponse, error)
+	Run(ctx context.Context) // Writes the counted views every stats.viewFlushInterval, and once more on shutdown
+}
+
+// productViewService implements ProductViewService
+type productViewService struct {
+	statsRepo   repository.StatsRepository
+	productRepo repository.ProductRepository // Only owners see the views of a product
+	cfg         config.StatsConfig
+	readOnly    *readonly.Mode
+
+	mu      sync.Mutex
+	pending map[productViewKey]int64 // Views counted since the last flush
+	dropped int64                    // Views dropped since the last warning, see maxPendingProductViews
+}
+
+// NewProductViewService creates a new ProductViewService instance
+func NewProductViewService(statsRepo repository.StatsRepository, productRepo repository.ProductRepository, cfg config.StatsConfig, readOnly *readonly.Mode) ProductViewService {
+	return &productViewService{
+		statsRepo:   statsRepo,
+		productRepo: productRepo,
+		cfg:         cfg,
+		readOnly:    readOnly,
+		pending:     map[productViewKey]int64{},
+	}
+}
+
+// RecordView counts a view of a product on the current day (UTC)
+func (s *productViewService) RecordView(product *models.Product, viewerID uint) {
+	if viewerID == product.UserID {
+		return
+	}
+	key := productViewKey{productID: product.ID, day: time.Now().UTC().Truncate(24 * time.Hour)}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.pending[key]; !ok && len(s.pending) >= maxPendingProductViews {
+		s.dropped++
+		return
+	}
+	s.pending[key]++
+}
+
+// GetProductViews returns the daily views of one of the owner's products from from's day to to's day included.
+// Other users' products are reported as not found.
+func (s *productViewService) GetProductViews(ctx context.Context, productID uint, ownerID uint, from, to time.Time) (*models.ProductViewsResponse, error) {
+	product, err := s.productRepo.GetProductByID(ctx, productID)
+	if err != nil || product.UserID != ownerID {
+		logger.Debug("Product not found for views", zap.Uint("productID", productID), zap.Uint("userID", ownerID))
+		return nil, errors.New("product not found")
+	}
+
+	days, err := s.statsRepo.GetDailyProductViews(ctx, productID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if days == nil {
+		days = []*models.DailyProductViews{}
+	}
+	var total int64
+	for _, d := range days {
+		total += d.Views
+	}
+	return &models.ProductViewsResponse{
+		ProductID: productID,
+		From:      from.Format(time.DateOnly),
+		To:        to.Format(time.DateOnly),
+		Days:      days,
+		Total:     total,
+	}, nil
+}
+
+// Run writes the counted views every stats.viewFlushInterval until ctx is cancelled. Views are kept in memory
+// while the instance is read-only or a write fails, and retried at the next flush.
+func (s *productViewService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if s.readOnly.Enabled() {
+				return
+			}
+			flushCtx, cancel := context.WithTimeout(context.Background(), productViewFlushTimeout)
+			if err := s.flush(flushCtx); err != nil {
+				logger.Warn("Product views counted since the last flush were lost on shutdown", zap.Error(err))
+			}
+			cancel()
+			return
+		case <-time.After(s.cfg.ViewFlushInterval):
+		}
+
+		if s.readOnly.Enabled() {
+			continue
+		}
+		if err := s.flush(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to write product views, retrying at the next flush", zap.Error(err))
+		}
+	}
+}
+
+// flush writes the views counted since the last flush. On failure they are counted again.
+func (s *productViewService) flush(ctx context.Context) error {
+	s.mu.Lock()
+	pending, dropped := s.pending, s.dropped
+	s.pending, s.dropped = map[productViewKey]int64{}, 0
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		logger.Warn("Product views were dropped, too many products viewed between flushes", zap.Int64("dropped", dropped), zap.Int("limit", maxPendingProductViews))
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	counts := make([]models.ProductViewCount, 0, len(pending))
+	for key, views := range pending {
+		counts = append(counts, models.ProductViewCount{ProductID: key.productID, Day: key.day, Views: views})
+	}
+	if err := s.statsRepo.AddProductViews(ctx, counts); err != nil {
+		s.requeue(pending)
+		return fmt.Errorf("failed to write product views: %w", err)
+	}
+	logger.Debug("Product views written", zap.Int("counts", len(counts)))
+	return nil
+}
+
+// requeue counts views that could not be written again, within maxPendingProductViews
+func (s *productViewService) requeue(pending map[productViewKey]int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key, views := range pending {
+		if _, ok := s.pending[key]; !ok && len(s.pending) >= maxPendingProductViews {
+			s.dropped += views
+			continue
+		}
+		s.pending[key] += views
+	}
+}
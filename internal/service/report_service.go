@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReportService defines the interface for the content report and moderation workflow
+type ReportService interface {
+	ReportContent(ctx context.Context, targetType string, targetID uint, reporterID uint, req *models.CreateReportRequest) (*models.Report, error)
+	GetModerationQueue(ctx context.Context, status string, page, pageSize int) ([]*models.Report, int64, error)
+	ResolveReport(ctx context.Context, reportID uint, adminID uint, req *models.ResolveReportRequest) (*models.Report, error)
+}
+
+// reportService implements ReportService
+type reportService struct {
+	reportRepo   repository.ReportRepository  // Dependency on ReportRepository
+	productRepo  repository.ProductRepository // Used to check reported products exist
+	commentRepo  repository.CommentRepository // Used to check reported comments exist
+	auditService AuditService                 // Status transitions are recorded in the audit log
+}
+
+// NewReportService creates a new ReportService instance
+func NewReportService(reportRepo repository.ReportRepository, productRepo repository.ProductRepository, commentRepo repository.CommentRepository, auditService AuditService) ReportService {
+	return &reportService{
+		reportRepo:   reportRepo,
+		productRepo:  productRepo,
+		commentRepo:  commentRepo,
+		auditService: auditService,
+	}
+}
+
+// ReportContent files a report against a product or comment
+func (s *reportService) ReportContent(ctx context.Context, targetType string, targetID uint, reporterID uint, req *models.CreateReportRequest) (*models.Report, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	var err error
+	switch targetType {
+	case models.ReportTargetProduct:
+		_, err = s.productRepo.GetProductByID(ctx, targetID)
+	case models.ReportTargetComment:
+		_, err = s.commentRepo.GetCommentByID(ctx, targetID)
+	default:
+		return nil, fmt.Errorf("unsupported report target %q", targetType)
+	}
+	if err != nil {
+		logger.Warn("Report filed against unknown content", zap.Error(err), zap.String("targetType", targetType), zap.Uint("targetID", targetID))
+		return nil, fmt.Errorf("%s not found", targetType)
+	}
+
+	exists, err := s.reportRepo.HasOpenReport(ctx, targetType, targetID, reporterID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to report content: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("you have already reported this content")
+	}
+
+	report := &models.Report{
+		TargetType: targetType,
+		TargetID:   targetID,
+		ReporterID: reporterID,
+		Reason:     req.Reason,
+		Details:    req.Details,
+		Status:     models.ReportStatusOpen,
+	}
+	if err := s.reportRepo.CreateReport(ctx, report); err != nil {
+		logger.Error("Failed to create report in repository", zap.Error(err), zap.String("targetType", targetType), zap.Uint("targetID", targetID))
+		return nil, fmt.Errorf("failed to report content: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, "report.opened", "report", report.ID, map[string]interface{}{
+		"targetType": targetType,
+		"targetId":   targetID,
+		"reason":     req.Reason,
+	}); err != nil {
+		logger.Warn("Report created but audit event was not recorded", zap.Error(err), zap.Uint("reportID", report.ID))
+	}
+
+	logger.Info("Content reported", zap.Uint("reportID", report.ID), zap.String("targetType", targetType), zap.Uint("targetID", targetID), actor.Field(ctx))
+	return report, nil
+}
+
+// GetModerationQueue retrieves a page of reports with the given status, oldest first
+func (s *reportService) GetModerationQueue(ctx context.Context, status string, page, pageSize int) ([]*models.Report, int64, error) {
+	reports, total, err := s.reportRepo.GetReportsByStatus(ctx, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		logger.Error("Failed to get reports in repository", zap.Error(err), zap.String("status", status))
+		return nil, 0, fmt.Errorf("failed to retrieve reports: %w", err)
+	}
+	return reports, total, nil
+}
+
+// ResolveReport moves an open report to actioned or dismissed
+func (s *reportService) ResolveReport(ctx context.Context, reportID uint, adminID uint, req *models.ResolveReportRequest) (*models.Report, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	report, err := s.reportRepo.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, fmt.Errorf("report not found")
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, fmt.Errorf("report has already been resolved")
+	}
+
+	now := time.Now()
+	report.Status = req.Status
+	report.ResolvedBy = &adminID
+	report.ResolvedAt = &now
+	report.ResolutionNote = req.Note
+
+	if err := s.reportRepo.ResolveReport(ctx, report); err != nil {
+		logger.Warn("Failed to resolve report in repository", zap.Error(err), zap.Uint("reportID", reportID))
+		return nil, fmt.Errorf("report has already been resolved") // Lost a race with another admin
+	}
+
+	if err := s.auditService.Record(ctx, "report."+req.Status, "report", report.ID, map[string]interface{}{
+		"targetType": report.TargetType,
+		"targetId":   report.TargetID,
+		"from":       models.ReportStatusOpen,
+		"to":         req.Status,
+		"note":       req.Note,
+	}); err != nil {
+		logger.Warn("Report resolved but audit event was not recorded", zap.Error(err), zap.Uint("reportID", report.ID))
+	}
+
+	logger.Info("Report resolved", zap.Uint("reportID", report.ID), zap.String("status", report.Status), actor.Field(ctx))
+	return report, nil
+}
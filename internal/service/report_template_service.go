@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReportTemplateService defines the interface for report template business logic
+type ReportTemplateService interface {
+	GetReportTemplates(ctx context.Context) ([]*models.ReportTemplate, error)
+	GetReportTemplate(ctx context.Context, templateID uint) (*models.ReportTemplate, error)
+	CreateReportTemplate(ctx context.Context, createdBy uint, req *models.ReportTemplateRequest) (*models.ReportTemplate, error)
+	DeleteReportTemplate(ctx context.Context, templateID uint) error
+	// RunReport runs a template with the values of its parameters. fn is called with the column labels, then with
+	// the cells of each row; nothing is passed to fn before the report has started returning rows, so an error
+	// returned before the first call leaves the output untouched.
+	RunReport(ctx context.Context, templateID uint, params map[string]string, fn func(cells []string) error) error
+}
+
+// reportTemplateService implements ReportTemplateService
+type reportTemplateService struct {
+	reportTemplateRepo repository.ReportTemplateRepository // Dependency on ReportTemplateRepository
+	auditService       AuditService                        // Templates decide what data leaves the system, so changes are audited
+}
+
+// NewReportTemplateService creates a new ReportTemplateService instance
+func NewReportTemplateService(reportTemplateRepo repository.ReportTemplateRepository, auditService AuditService) ReportTemplateService {
+	return &reportTemplateService{
+		reportTemplateRepo: reportTemplateRepo,
+		auditService:       auditService,
+	}
+}
+
+// GetReportTemplates retrieves all report templates by name
+func (s *reportTemplateService) GetReportTemplates(ctx context.Context) ([]*models.ReportTemplate, error) {
+	templates, err := s.reportTemplateRepo.GetReportTemplates(ctx)
+	if err != nil {
+		logger.Error("Failed to get report templates in repository", zap.Error(err))
+		return nil, fmt.Errorf("failed to retrieve report templates: %w", err)
+	}
+	return templates, nil
+}
+
+// GetReportTemplate retrieves a report template
+func (s *reportTemplateService) GetReportTemplate(ctx context.Context, templateID uint) (*models.ReportTemplate, error) {
+	template, err := s.reportTemplateRepo.GetReportTemplateByID(ctx, templateID)
+	if err != nil {
+		logger.Debug("Report template not found", zap.Error(err), zap.Uint("reportTemplateID", templateID))
+		return nil, fmt.Errorf("report template not found")
+	}
+	return template, nil
+}
+
+// CreateReportTemplate validates a report spec and stores it under a name
+func (s *reportTemplateService) CreateReportTemplate(ctx context.Context, createdBy uint, req *models.ReportTemplateRequest) (*models.ReportTemplate, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	spec, err := json.Marshal(&req.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report template: %w", err)
+	}
+	template := &models.ReportTemplate{Name: req.Name, Spec: string(spec), CreatedBy: createdBy}
+	if err := s.reportTemplateRepo.CreateReportTemplate(ctx, template); err != nil {
+		logger.Error("Failed to create report template in repository", zap.Error(err), zap.String("name", req.Name))
+		return nil, fmt.Errorf("failed to create report template: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, "report_template.created", "report_template", template.ID, map[string]interface{}{
+		"name":    template.Name,
+		"dataset": req.Spec.Dataset,
+	}); err != nil {
+		logger.Warn("Report template audit event was not recorded", zap.Error(err), zap.Uint("reportTemplateID", template.ID))
+	}
+	logger.Info("Report template created successfully", zap.Uint("reportTemplateID", template.ID), actor.Field(ctx))
+	return template, nil
+}
+
+// DeleteReportTemplate removes a report template
+func (s *reportTemplateService) DeleteReportTemplate(ctx context.Context, templateID uint) error {
+	template, err := s.GetReportTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	if err := s.reportTemplateRepo.DeleteReportTemplate(ctx, templateID); err != nil {
+		logger.Error("Failed to delete report template in repository", zap.Error(err), zap.Uint("reportTemplateID", templateID))
+		return fmt.Errorf("failed to delete report template: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, "report_template.deleted", "report_template", templateID, map[string]interface{}{"name": template.Name}); err != nil {
+		logger.Warn("Report template audit event was not recorded", zap.Error(err), zap.Uint("reportTemplateID", templateID))
+	}
+	logger.Info("Report template deleted successfully", zap.Uint("reportTemplateID", templateID), actor.Field(ctx))
+	return nil
+}
+
+// RunReport runs a report template. The stored spec is validated again, since the datasets may have lost
+// fields after it was saved. Every parameter of the template must be given; others are refused, as likely typos.
+func (s *reportTemplateService) RunReport(ctx context.Context, templateID uint, params map[string]string, fn func(cells []string) error) error {
+	template, err := s.GetReportTemplate(ctx, templateID)
+	if err != nil {
+		return err
+	}
+	spec, err := template.DecodeSpec()
+	if err != nil {
+		logger.Error("Stored report template spec is not valid JSON", zap.Error(err), zap.Uint("reportTemplateID", templateID))
+		return fmt.Errorf("%sreport template spec can't be read", validation.ErrorPrefix)
+	}
+	if err := validation.Struct(spec); err != nil {
+		return err
+	}
+	values, err := reportFilterValues(spec, params)
+	if err != nil {
+		return err
+	}
+
+	dataset := models.ReportDatasets[spec.Dataset]
+	header := make([]string, 0, len(spec.Columns))
+	for _, col := range spec.Columns {
+		label := col.Label
+		if label == "" {
+			label = col.Field
+		}
+		header = append(header, label)
+	}
+	started := false
+	err = s.reportTemplateRepo.RunReport(ctx, spec, values, func(row []interface{}) error {
+		if !started {
+			started = true
+			if err := fn(header); err != nil {
+				return err
+			}
+		}
+		cells := make([]string, len(row))
+		for i, v := range row {
+			cells[i] = reportCell(dataset.Fields[spec.Columns[i].Field].Type, v)
+		}
+		return fn(cells)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run report: %w", err)
+	}
+	if !started {
+		if err := fn(header); err != nil { // A report without rows is its header
+			return err
+		}
+	}
+	logger.Info("Report run", zap.Uint("reportTemplateID", templateID), actor.Field(ctx))
+	return nil
+}
+
+// reportFilterValues returns the value of each filter of a spec, reading parameters from params
+func reportFilterValues(spec *models.ReportSpec, params map[string]string) ([]interface{}, error) {
+	expected := map[string]bool{}
+	for _, name := range spec.Params() {
+		expected[name] = true
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("%sparameter %s is required", validation.ErrorPrefix, name)
+		}
+	}
+	for name := range params {
+		if !expected[name] {
+			return nil, fmt.Errorf("%sreport has no parameter %s", validation.ErrorPrefix, name)
+		}
+	}
+
+	dataset := models.ReportDatasets[spec.Dataset]
+	values := make([]interface{}, 0, len(spec.Filters))
+	for _, f := range spec.Filters {
+		raw, name := "", f.Param
+		if f.Value != nil {
+			raw = *f.Value
+		} else {
+			raw = params[name]
+		}
+		v, err := models.ParseReportValue(dataset.Fields[f.Field].Type, raw)
+		if err != nil {
+			if name == "" {
+				return nil, fmt.Errorf("%sfilter on %s: %v", validation.ErrorPrefix, f.Field, err) // Validated, but kept safe
+			}
+			return nil, fmt.Errorf("%sparameter %s: %v", validation.ErrorPrefix, name, err)
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
+
+// reportCell formats a value read for a field of a report
+func reportCell(fieldType string, v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []byte:
+		return reportText(fieldType, string(v))
+	case string:
+		return reportText(fieldType, v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// reportText keeps user-entered text from being read as a formula when a report is opened in a spreadsheet,
+// by starting it with a quote when it starts with a character spreadsheets treat as one
+func reportText(fieldType, s string) string {
+	if fieldType == models.ReportFieldText && s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
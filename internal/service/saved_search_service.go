@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// maxSavedSearchesPerUser caps how many searches a single user can save
+const maxSavedSearchesPerUser = 50
+
+// savedSearchBatchSize is the number of products loaded per query when running a saved search
+const savedSearchBatchSize = 500
+
+// SavedSearchService defines the interface for saved search business logic
+type SavedSearchService interface {
+	GetSavedSearches(ctx context.Context, userID uint) ([]*models.SavedSearch, error)
+	SaveSearch(ctx context.Context, userID uint, req *models.SavedSearchRequest) (*models.SavedSearch, error)
+	DeleteSavedSearch(ctx context.Context, savedSearchID uint, userID uint) error
+	RunSavedSearch(ctx context.Context, savedSearchID uint, userID uint) ([]*models.Product, error)
+	StreamSavedSearch(ctx context.Context, savedSearchID uint, userID uint, fn func(*models.Product) error) error
+}
+
+// savedSearchService implements SavedSearchService
+type savedSearchService struct {
+	savedSearchRepo repository.SavedSearchRepository // Dependency on SavedSearchRepository
+	productRepo     repository.ProductRepository     // Saved searches run against the user's products
+}
+
+// NewSavedSearchService creates a new SavedSearchService instance
+func NewSavedSearchService(savedSearchRepo repository.SavedSearchRepository, productRepo repository.ProductRepository) SavedSearchService {
+	return &savedSearchService{
+		savedSearchRepo: savedSearchRepo,
+		productRepo:     productRepo,
+	}
+}
+
+// GetSavedSearches retrieves all of a user's saved searches by name
+func (s *savedSearchService) GetSavedSearches(ctx context.Context, userID uint) ([]*models.SavedSearch, error) {
+	searches, err := s.savedSearchRepo.GetSavedSearchesByUserID(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to get saved searches in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to retrieve saved searches: %w", err)
+	}
+	return searches, nil
+}
+
+// SaveSearch validates a filter set and stores it under a name
+func (s *savedSearchService) SaveSearch(ctx context.Context, userID uint, req *models.SavedSearchRequest) (*models.SavedSearch, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	count, err := s.savedSearchRepo.CountSavedSearchesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save search: %w", err)
+	}
+	if count >= maxSavedSearchesPerUser {
+		return nil, fmt.Errorf("saved search limit reached")
+	}
+
+	spec, err := json.Marshal(&req.Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to save search: %w", err)
+	}
+	search := &models.SavedSearch{UserID: userID, Name: req.Name, Spec: string(spec)}
+	if err := s.savedSearchRepo.CreateSavedSearch(ctx, search); err != nil {
+		logger.Error("Failed to create saved search in repository", zap.Error(err), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("failed to save search: %w", err)
+	}
+
+	logger.Info("Search saved successfully", zap.Uint("savedSearchID", search.ID), actor.Field(ctx))
+	return search, nil
+}
+
+// DeleteSavedSearch removes one of the user's saved searches
+func (s *savedSearchService) DeleteSavedSearch(ctx context.Context, savedSearchID uint, userID uint) error {
+	if _, err := s.getSavedSearch(ctx, savedSearchID, userID); err != nil {
+		return err
+	}
+	if err := s.savedSearchRepo.DeleteSavedSearch(ctx, savedSearchID); err != nil {
+		logger.Error("Failed to delete saved search in repository", zap.Error(err), zap.Uint("savedSearchID", savedSearchID))
+		return fmt.Errorf("failed to delete saved search: %w", err)
+	}
+
+	logger.Info("Saved search deleted successfully", zap.Uint("savedSearchID", savedSearchID), actor.Field(ctx))
+	return nil
+}
+
+// RunSavedSearch returns the user's products matching one of their saved searches, in ID order.
+// The stored spec is validated again, since the rules may have tightened after it was saved.
+func (s *savedSearchService) RunSavedSearch(ctx context.Context, savedSearchID uint, userID uint) ([]*models.Product, error) {
+	products := []*models.Product{}
+	err := s.StreamSavedSearch(ctx, savedSearchID, userID, func(p *models.Product) error {
+		products = append(products, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// StreamSavedSearch calls fn with each of the user's products matching one of their saved searches, in ID order,
+// a batch at a time, so results of any size can be written without holding them in memory. Errors about the saved
+// search itself are returned before fn is first called.
+func (s *savedSearchService) StreamSavedSearch(ctx context.Context, savedSearchID uint, userID uint, fn func(*models.Product) error) error {
+	search, err := s.getSavedSearch(ctx, savedSearchID, userID)
+	if err != nil {
+		return err
+	}
+	spec, err := search.DecodeSpec()
+	if err != nil {
+		logger.Error("Stored saved search spec is not valid JSON", zap.Error(err), zap.Uint("savedSearchID", savedSearchID))
+		return fmt.Errorf("%ssaved search spec can't be read", validation.ErrorPrefix)
+	}
+	if err := validation.Struct(spec); err != nil {
+		return err
+	}
+
+	filter := spec.Filter(userID)
+	count := 0
+	var afterID uint
+	for {
+		batch, err := s.productRepo.GetProductsByFilter(ctx, filter, afterID, savedSearchBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to run saved search: %w", err)
+		}
+		for _, p := range batch {
+			if err := fn(p); err != nil {
+				return err
+			}
+		}
+		count += len(batch)
+		if len(batch) < savedSearchBatchSize {
+			break
+		}
+		afterID = batch[len(batch)-1].ID
+	}
+
+	logger.Debug("Saved search run", zap.Uint("savedSearchID", savedSearchID), zap.Int("count", count))
+	return nil
+}
+
+// getSavedSearch retrieves one of the user's saved searches. Other users' searches are reported as not found.
+func (s *savedSearchService) getSavedSearch(ctx context.Context, savedSearchID uint, userID uint) (*models.SavedSearch, error) {
+	search, err := s.savedSearchRepo.GetSavedSearchByID(ctx, savedSearchID)
+	if err != nil || search.UserID != userID {
+		logger.Debug("Saved search not found for user", zap.Uint("savedSearchID", savedSearchID), zap.Uint("userID", userID))
+		return nil, fmt.Errorf("saved search not found")
+	}
+	return search, nil
+}
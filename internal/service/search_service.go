@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"time"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+// searchSnippetLength is the number of characters of a description shown with a search hit
+const searchSnippetLength = 160
+
+// SearchService defines the interface for the global search
+type SearchService interface {
+	// Search finds the results of the types for a user with a role. Products are only found by users who may read
+	// them, see ProductService.GetProduct.
+	Search(ctx context.Context, q string, types []string, userID uint, role string, page, pageSize int) (*models.SearchResponse, error)
+	Reindex(ctx context.Context) (*models.SearchReindexResult, error)
+}
+
+// searchService implements SearchService
+type searchService struct {
+	searchRepo repository.SearchRepository // Dependency on SearchRepository
+	backend    string                      // One of the config.SearchBackend* values, used for products
+}
+
+// NewSearchService creates a new SearchService instance
+func NewSearchService(searchRepo repository.SearchRepository, cfg config.SearchConfig) SearchService {
+	return &searchService{
+		searchRepo: searchRepo,
+		backend:    cfg.Backend,
+	}
+}
+
+// Search runs q against every requested result type. Each type is paged on its own, with the same page and size.
+// Callers decide which types the actor may see; users are always matched by substring since there is no index to use.
+func (s *searchService) Search(ctx context.Context, q string, types []string, userID uint, role string, page, pageSize int) (*models.SearchResponse, error) {
+	offset := (page - 1) * pageSize
+	readerID := userID
+	if role == models.RoleAdmin {
+		readerID = 0 // Admins read every product
+	}
+	response := &models.SearchResponse{Query: q, Results: map[string]*models.SearchPage{}}
+	for _, t := range types {
+		var hits []*models.SearchHit
+		var total int64
+		var err error
+		switch {
+		case t == models.SearchTypeProducts && s.backend == config.SearchBackendFTS:
+			hits, total, err = s.searchRepo.SearchProductsFullText(ctx, q, readerID, pageSize, offset)
+		case t == models.SearchTypeProducts:
+			hits, total, err = s.searchRepo.SearchProductsBySubstring(ctx, q, readerID, pageSize, offset)
+		case t == models.SearchTypeUsers:
+			hits, total, err = s.searchRepo.SearchUsersBySubstring(ctx, q, pageSize, offset)
+		default:
+			return nil, fmt.Errorf("unknown search type %q", t)
+		}
+		if err != nil {
+			logger.Error("Failed to search in repository", zap.Error(err), zap.String("type", t))
+			return nil, fmt.Errorf("failed to search %s: %w", t, err)
+		}
+
+		for _, hit := range hits {
+			hit.Snippet = truncateSnippet(hit.Snippet)
+		}
+		if hits == nil {
+			hits = []*models.SearchHit{}
+		}
+		response.Results[t] = &models.SearchPage{Items: hits, Page: page, PageSize: pageSize, Total: total}
+	}
+	return response, nil
+}
+
+// truncateSnippet shortens text to searchSnippetLength characters without splitting a character
+func truncateSnippet(text string) string {
+	if utf8.RuneCountInString(text) <= searchSnippetLength {
+		return text
+	}
+	return string([]rune(text)[:searchSnippetLength]) + "…"
+}
+
+// Reindex rebuilds the full-text index of products. Searches and writes go on while it runs.
+func (s *searchService) Reindex(ctx context.Context) (*models.SearchReindexResult, error) {
+	start := time.Now()
+	if err := s.searchRepo.ReindexProducts(ctx); err != nil {
+		return nil, err
+	}
+	result := &models.SearchReindexResult{Index: "idx_products_search", DurationMs: milliseconds(time.Since(start))}
+	logger.Info("Product search index rebuilt", zap.Float64("durationMs", result.DurationMs))
+	return result, nil
+}
+
+// SearchReindexCommand is the admin command rebuilding the product search index, e.g. after bloat from heavy updates
+func SearchReindexCommand(searchService SearchService) *AdminCommand {
+	return &AdminCommand{
+		Name:        "search.reindex",
+		Description: "Rebuild the full-text index of products without blocking writes",
+		Run: func(ctx context.Context, _ interface{}, _ ProgressFunc) (interface{}, error) {
+			return searchService.Reindex(ctx)
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"testing"
+)
+
+// readerRecordingSearchRepository records the reader product searches are restricted to
+type readerRecordingSearchRepository struct {
+	repository.SearchRepository
+	readerID uint
+}
+
+func (r *readerRecordingSearchRepository) SearchProductsBySubstring(ctx context.Context, q string, readerID uint, limit, offset int) ([]*models.SearchHit, int64, error) {
+	r.readerID = readerID
+	return nil, 0, nil
+}
+
+// TestSearchRestrictsProductsToReadable checks that product searches of users are restricted to the products they
+// may read, while admins search every product
+func TestSearchRestrictsProductsToReadable(t *testing.T) {
+	ctx := context.Background()
+	repo := &readerRecordingSearchRepository{}
+	search := NewSearchService(repo, config.SearchConfig{Backend: config.SearchBackendILike})
+
+	if _, err := search.Search(ctx, "lamp", []string{models.SearchTypeProducts}, strangerID, models.RoleUser, 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if repo.readerID != strangerID {
+		t.Fatalf("user search restricted to the products of reader %d, want %d", repo.readerID, strangerID)
+	}
+
+	if _, err := search.Search(ctx, "lamp", []string{models.SearchTypeProducts}, adminID, models.RoleAdmin, 1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if repo.readerID != 0 {
+		t.Fatalf("admin search restricted to the products of reader %d, want every product", repo.readerID)
+	}
+}
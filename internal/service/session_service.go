@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/session"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// SessionService defines the interface for cookie sessions, which let browser apps sign in without
+// handling bearer tokens. They coexist with JWT authentication.
+type SessionService interface {
+	Login(ctx context.Context, req *models.LoginRequest) (id string, res *models.SessionResponse, err error)
+	Logout(ctx context.Context, id string) error
+	CookieName() string
+	AuthenticateSession(ctx context.Context, id string) (*session.Session, error)
+}
+
+// sessionService implements SessionService
+type sessionService struct {
+	userService UserService
+	store       session.Store
+	cfg         config.SessionConfig
+}
+
+// NewSessionService creates a new SessionService instance
+func NewSessionService(userService UserService, store session.Store, cfg config.SessionConfig) SessionService {
+	return &sessionService{userService: userService, store: store, cfg: cfg}
+}
+
+// Login checks the credentials like a token login, then starts a session. It returns the session ID, for the
+// cookie, and the CSRF token the client must echo on mutating requests.
+func (s *sessionService) Login(ctx context.Context, req *models.LoginRequest) (string, *models.SessionResponse, error) {
+	user, err := s.userService.Authenticate(ctx, req)
+	if err != nil {
+		return "", nil, err
+	}
+
+	id, err := session.NewToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	csrfToken, err := session.NewToken()
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+	now := time.Now()
+	sess := &session.Session{UserID: user.ID, Role: user.Role, AuthTime: now, CSRFToken: csrfToken, ExpiresAt: now.Add(s.cfg.TTL)}
+	if err := s.store.Save(ctx, id, sess); err != nil {
+		logger.Error("Failed to save session", zap.Error(err), zap.Uint("userID", user.ID))
+		return "", nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	logger.Info("User signed in with a session", zap.Uint("userID", user.ID))
+	return id, &models.SessionResponse{UserID: user.ID, Role: user.Role, CSRFToken: csrfToken, ExpiresAt: sess.ExpiresAt}, nil
+}
+
+// Logout ends a session immediately, on every instance
+func (s *sessionService) Logout(ctx context.Context, id string) error {
+	if err := s.store.Delete(ctx, id); err != nil {
+		logger.Error("Failed to delete session", zap.Error(err))
+		return fmt.Errorf("failed to end session: %w", err)
+	}
+	logger.Debug("Session ended")
+	return nil
+}
+
+// CookieName returns the name of the session cookie
+func (s *sessionService) CookieName() string {
+	return s.cfg.CookieName
+}
+
+// AuthenticateSession returns the session of a cookie, with the user's current role. It is called by the auth
+// middleware. Sessions of users deleted or merged away since they signed in are ended.
+func (s *sessionService) AuthenticateSession(ctx context.Context, id string) (*session.Session, error) {
+	sess, err := s.store.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if time.Now().After(sess.ExpiresAt) {
+		return nil, session.ErrNotFound // The store expires sessions too; don't rely on its clock alone
+	}
+
+	user, err := s.userService.CurrentUser(ctx, sess.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		if err := s.store.Delete(ctx, id); err != nil {
+			logger.Warn("Failed to end the session of a deleted user", zap.Error(err), zap.Uint("userID", sess.UserID))
+		}
+		return nil, session.ErrNotFound
+	}
+	sess.Role = user.Role // Set at sign-in; a demotion applies to live sessions at once
+	return sess, nil
+}
+
+// revokeSessions ends every session of a user whose account is gone, if cookie sessions are enabled. Failures are
+// logged only: the sessions are refused anyway, as AuthenticateSession checks the user still exists.
+func revokeSessions(ctx context.Context, sessions session.Store, userID uint) {
+	if sessions == nil {
+		return
+	}
+	if err := sessions.DeleteUser(ctx, userID); err != nil {
+		logger.Warn("Failed to end the sessions of a removed user", zap.Error(err), zap.Uint("userID", userID))
+		return
+	}
+	logger.Info("Sessions of a removed user ended", zap.Uint("userID", userID))
+}
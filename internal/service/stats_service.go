@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StatsService defines the interface for the admin statistics. Expensive aggregates are precomputed and
+// refreshed on schedule, so reading them costs the same whatever the size of the tables.
+type StatsService interface {
+	GetUserProductStats(ctx context.Context, page, pageSize int) (*models.UserProductStatsResponse, error)
+	GetDailyProductStats(ctx context.Context, from, to time.Time) (*models.DailyProductStatsResponse, error)
+	Refresh(ctx context.Context) (*models.StatsRefreshResult, error)
+	Run(ctx context.Context) // Refreshes the aggregates every stats.refreshInterval, except in read-only mode
+}
+
+// statsService implements StatsService
+type statsService struct {
+	statsRepo repository.StatsRepository
+	cfg       config.StatsConfig
+	readOnly  *readonly.Mode
+	refreshMu sync.Mutex // Scheduled and manual refreshes take turns
+}
+
+// NewStatsService creates a new StatsService instance
+func NewStatsService(statsRepo repository.StatsRepository, cfg config.StatsConfig, readOnly *readonly.Mode) StatsService {
+	return &statsService{
+		statsRepo: statsRepo,
+		cfg:       cfg,
+		readOnly:  readOnly,
+	}
+}
+
+// GetUserProductStats returns a page of per-user product counts, most products first
+func (s *statsService) GetUserProductStats(ctx context.Context, page, pageSize int) (*models.UserProductStatsResponse, error) {
+	rows, total, refreshedAt, err := s.statsRepo.GetUserProductStats(ctx, pageSize, (page-1)*pageSize)
+	if err != nil {
+		return nil, err
+	}
+	if rows == nil {
+		rows = []*models.UserProductStats{}
+	}
+	return &models.UserProductStatsResponse{Items: rows, Page: page, PageSize: pageSize, Total: total, RefreshedAt: refreshedAt}, nil
+}
+
+// GetDailyProductStats returns the daily product summaries from from's day to to's day included
+func (s *statsService) GetDailyProductStats(ctx context.Context, from, to time.Time) (*models.DailyProductStatsResponse, error) {
+	days, refreshedAt, err := s.statsRepo.GetDailyProductStats(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	if days == nil {
+		days = []*models.DailyProductStats{}
+	}
+	return &models.DailyProductStatsResponse{
+		From:        from.Format(time.DateOnly),
+		To:          to.Format(time.DateOnly),
+		Days:        days,
+		RefreshedAt: refreshedAt,
+	}, nil
+}
+
+// Refresh recomputes the aggregates now, e.g. after a bulk import
+func (s *statsService) Refresh(ctx context.Context) (*models.StatsRefreshResult, error) {
+	if err := s.readOnly.Check(); err != nil {
+		return nil, err
+	}
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	start := time.Now()
+	if err := s.statsRepo.RefreshStats(ctx); err != nil {
+		return nil, fmt.Errorf("failed to refresh stats: %w", err)
+	}
+	result := &models.StatsRefreshResult{RefreshedAt: time.Now(), DurationMs: milliseconds(time.Since(start))}
+	logger.Info("Stats refreshed", zap.Float64("durationMs", result.DurationMs))
+	return result, nil
+}
+
+// StatsRefreshCommand is the admin command recomputing the aggregates now, in the background
+func StatsRefreshCommand(statsService StatsService) *AdminCommand {
+	return &AdminCommand{
+		Name:        "stats.refresh",
+		Description: "Recompute the statistics aggregates now instead of at the next scheduled refresh",
+		Run: func(ctx context.Context, _ interface{}, _ ProgressFunc) (interface{}, error) {
+			return statsService.Refresh(ctx)
+		},
+	}
+}
+
+// Run refreshes the aggregates every stats.refreshInterval until ctx is cancelled
+func (s *statsService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(s.cfg.RefreshInterval):
+		}
+
+		if s.readOnly.Enabled() {
+			continue
+		}
+		if _, err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("Failed to refresh stats", zap.Error(err))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/database"
+	"time"
+)
+
+// DatabaseProbe reports the database reachability tracked in the background, and the connection pool usage
+type DatabaseProbe interface {
+	Available() bool
+	LastCheck() time.Time
+	PoolStats() database.PoolStats
+}
+
+// StatusService defines the interface for the instance status snapshot
+type StatusService interface {
+	Status(ctx context.Context) *models.InstanceStatus
+}
+
+// statusService implements StatusService
+type statusService struct {
+	database       DatabaseProbe
+	operationRepo  repository.OperationRepository
+	auditForwarder *AuditForwarder // Nil when audit forwarding is disabled
+	startedAt      time.Time
+}
+
+// NewStatusService creates a new StatusService instance. auditForwarder may be nil.
+func NewStatusService(database DatabaseProbe, operationRepo repository.OperationRepository, auditForwarder *AuditForwarder) StatusService {
+	return &statusService{
+		database:       database,
+		operationRepo:  operationRepo,
+		auditForwarder: auditForwarder,
+		startedAt:      time.Now(),
+	}
+}
+
+// Status aggregates component health, queue depths, background job runs and connection pool usage.
+// Parts that can't be measured carry an error instead of failing the whole snapshot.
+func (s *statusService) Status(ctx context.Context) *models.InstanceStatus {
+	status := &models.InstanceStatus{
+		Status:     models.StatusOK,
+		CheckedAt:  time.Now(),
+		StartedAt:  s.startedAt,
+		Components: map[string]*models.ComponentStatus{},
+		Queues:     map[string]*models.QueueStatus{},
+		Jobs:       map[string]*models.JobStatus{},
+	}
+
+	if s.database.Available() {
+		status.Components["database"] = &models.ComponentStatus{Status: models.StatusOK}
+	} else {
+		status.Components["database"] = &models.ComponentStatus{Status: models.StatusDown, Error: "unreachable"}
+	}
+	status.Jobs["database_monitor"] = &models.JobStatus{LastRun: timeOrNil(s.database.LastCheck())}
+	pool := s.database.PoolStats()
+	status.Pool = &models.PoolStatus{
+		MaxOpen:        pool.MaxOpenConnections,
+		Open:           pool.OpenConnections,
+		InUse:          pool.InUse,
+		Idle:           pool.Idle,
+		Waiting:        pool.Waiting,
+		WaitCount:      pool.WaitCount,
+		WaitDurationMs: milliseconds(pool.WaitDuration),
+	}
+
+	operations := &models.QueueStatus{}
+	if counts, err := s.operationRepo.CountUnfinishedOperations(ctx); err != nil {
+		operations.Error = err.Error()
+	} else {
+		operations.Pending = counts[models.OperationStatusPending]
+		operations.Running = counts[models.OperationStatusRunning]
+	}
+	status.Queues["operations"] = operations
+
+	if s.auditForwarder == nil {
+		status.Components["audit_forwarder"] = &models.ComponentStatus{Status: models.StatusDisabled}
+	} else {
+		job, backlog := s.auditForwarder.Status(ctx)
+		component := &models.ComponentStatus{Status: models.StatusOK}
+		if job.LastError != "" {
+			component.Status = models.StatusDown
+			component.Error = job.LastError
+		}
+		status.Components["audit_forwarder"] = component
+		status.Jobs["audit_forwarder"] = job
+		status.Queues["audit_outbox"] = backlog
+	}
+
+	for _, c := range status.Components {
+		if c.Status == models.StatusDown {
+			status.Status = models.StatusDegraded
+		}
+	}
+	return status
+}
+
+// timeOrNil returns nil for the zero time, so JSON omits times that never happened
+func timeOrNil(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
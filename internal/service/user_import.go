@@ -0,0 +1,69 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"strings"
+)
+
+// maxUserImportRows caps the number of rows in one user import file
+const maxUserImportRows = 10000
+
+// ParseUserImportCSV reads a user import file with the columns email, username and optionally role.
+// Column names are case-insensitive and may appear in any order. Only the file structure is checked here;
+// the rows themselves are validated by ImportUsers so that every bad row is reported, not just the first.
+func ParseUserImportCSV(data []byte) ([]*models.UserImportRecord, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.FieldsPerRecord = -1 // Tolerate ragged rows, missing cells read as empty
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		// Exports re-saved by spreadsheet tools often start with a byte order mark
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, required := range []string{"email", "username"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("CSV header is missing the %q column", required)
+		}
+	}
+	cell := func(row []string, column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(row) {
+			return ""
+		}
+		return strings.TrimSpace(row[i])
+	}
+
+	var records []*models.UserImportRecord
+	for {
+		row, err := reader.Read()
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				return nil, fmt.Errorf("invalid CSV at line %d: %w", parseErr.Line, parseErr.Err)
+			}
+			break // io.EOF
+		}
+		if len(records) == maxUserImportRows {
+			return nil, fmt.Errorf("import file has more than %d rows", maxUserImportRows)
+		}
+		line, _ := reader.FieldPos(0)
+		records = append(records, &models.UserImportRecord{
+			Line:     line,
+			Email:    cell(row, "email"),
+			Username: cell(row, "username"),
+			Role:     strings.ToLower(cell(row, "role")),
+		})
+	}
+	if len(records) == 0 {
+		return nil, errors.New("import file has no rows")
+	}
+	return records, nil
+}
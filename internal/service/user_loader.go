@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/dataloader"
+)
+
+// userLoaderWait is how long a user lookup waits for others to be fetched with; short, since the lookups of a
+// request or resolution are made together
+const userLoaderWait = 2 * time.Millisecond
+
+// userLoaderMaxBatch bounds the IDs fetched with one query
+const userLoaderMaxBatch = 500
+
+// userLoader batches the user lookups by ID of a request into WHERE id IN (...) queries
+type userLoader = dataloader.Loader[uint, *models.User]
+
+// newUserLoader creates a userLoader reading users from userRepo. It remembers the users it loaded, so it must not
+// outlive the request.
+func newUserLoader(userRepo repository.UserRepository) *userLoader {
+	return dataloader.New(func(ctx context.Context, ids []uint) (map[uint]*models.User, error) {
+		users, err := userRepo.GetUsersByIDs(ctx, ids)
+		if err != nil {
+			return nil, err
+		}
+		byID := make(map[uint]*models.User, len(users))
+		for _, u := range users {
+			byID[u.ID] = u
+		}
+		return byID, nil
+	}, userLoaderWait, userLoaderMaxBatch)
+}
+
+// loadOwners sets the owner of each product with one lookup for all of them. Products whose owner is gone keep
+// an empty owner, which responses leave out.
+func loadOwners(ctx context.Context, loader *userLoader, products []*models.Product) error {
+	ids := make([]uint, 0, len(products))
+	for _, p := range products {
+		if p.User.ID == 0 {
+			ids = append(ids, p.UserID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+	owners, err := loader.LoadMany(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, p := range products {
+		if owner, ok := owners[p.UserID]; ok && p.User.ID == 0 {
+			p.User = *owner
+		}
+	}
+	return nil
+}
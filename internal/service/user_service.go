@@ -2,12 +2,23 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
+	"gotemplate/config"
 	"gotemplate/internal/models"
 	"gotemplate/internal/repository"
+	"gotemplate/pkg/actor"
 	"gotemplate/pkg/auth"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/deadline"
 	"gotemplate/pkg/logger"
+	"gotemplate/pkg/session"
+	"gotemplate/pkg/validation"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
 
 	// "github.com/google/uuid" // No longer needed for UUID generation if ID is uint
 	"go.uber.org/zap"            // Import zap for structured logging
@@ -18,85 +29,276 @@ import (
 type UserService interface {
 	RegisterUser(ctx context.Context, req *models.RegisterRequest) (*models.User, error)
 	LoginUser(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error)
+	Authenticate(ctx context.Context, req *models.LoginRequest) (*models.User, error)
+	Reauthenticate(ctx context.Context, userID uint, password string) (*models.LoginResponse, error)
 	GetUserProfile(ctx context.Context, userID uint) (*models.User, error) // Changed userID to uint
+	// CurrentUser returns the live user with the ID, or nil if it was deleted or merged away. Credentials are checked
+	// against it on every request, so it is served from the user cache.
+	CurrentUser(ctx context.Context, userID uint) (*models.User, error)
+	DeleteUser(ctx context.Context, userID uint) error
+	// SetPassword sets the password of an account created without one, with the token issued when it was created
+	SetPassword(ctx context.Context, req *models.SetPasswordRequest) error
+	ImportUsers(ctx context.Context, records []*models.UserImportRecord, report ProgressFunc) (*models.UserImportResult, error)
 }
 
 // userService implements UserService
 type userService struct {
-	userRepo   repository.UserRepository // Dependency on UserRepository
-	jwtManager *auth.JWTManager          // Dependency on JWTManager
+	userRepo     repository.UserRepository    // Dependency on UserRepository
+	productRepo  repository.ProductRepository // Dependency on ProductRepository, for cascading deletes
+	jwtManager   *auth.JWTManager             // Dependency on JWTManager
+	auditService AuditService                 // User deletions are recorded in the audit log
+	cascade      config.CascadeConfig         // What happens to a deleted user's products
+	authCfg      config.AuthConfig            // Login hardening settings
+	throttle     LoginThrottle                // Per-credential brute-force protection
+	sessions     session.Store                // Sessions of deleted users are ended; nil unless cookie sessions are enabled
+}
+
+// loginMinBudget is the request budget a login needs to be worth attempting
+const loginMinBudget = 250 * time.Millisecond
+
+// dummyPasswordHash is compared against when the login email is unknown, so that
+// the request costs as much as one with a wrong password
+var (
+	dummyPasswordHash     []byte
+	dummyPasswordHashOnce sync.Once
+)
+
+// compareDummyPassword performs a bcrypt comparison whose result is always discarded. The dummy hash uses the
+// configured cost, like the hashes of real accounts.
+func compareDummyPassword(password string, cost int) {
+	dummyPasswordHashOnce.Do(func() {
+		dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not-a-real-password"), cost)
+	})
+	_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
 }
 
 // NewUserService creates a new UserService instance
-func NewUserService(userRepo repository.UserRepository, jwtManager *auth.JWTManager) UserService {
+func NewUserService(userRepo repository.UserRepository, productRepo repository.ProductRepository, jwtManager *auth.JWTManager, auditService AuditService, cascade config.CascadeConfig, authCfg config.AuthConfig, throttle LoginThrottle, sessions session.Store) UserService {
 	return &userService{
-		userRepo:   userRepo,
-		jwtManager: jwtManager,
+		userRepo:     userRepo,
+		productRepo:  productRepo,
+		jwtManager:   jwtManager,
+		auditService: auditService,
+		cascade:      cascade,
+		authCfg:      authCfg,
+		throttle:     throttle,
+		sessions:     sessions,
 	}
 }
 
 // RegisterUser handles user registration
 func (s *userService) RegisterUser(ctx context.Context, req *models.RegisterRequest) (*models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
 	// Check if a user with the given email already exists
 	existingUser, err := s.userRepo.GetUserByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
 		logger.Warn("Attempted registration with existing email", zap.String("email", req.Email))
 		return nil, errors.New("user with this email already exists")
 	}
+	// Emails and usernames of deleted accounts are free again; only live accounts conflict
+	if existingUser, err := s.userRepo.GetUserByUsername(ctx, req.Username); err == nil && existingUser != nil {
+		logger.Warn("Attempted registration with existing username", zap.String("username", req.Username))
+		return nil, errors.New("user with this username already exists")
+	}
 
 	// Hash the password
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.authCfg.PasswordHash.Cost)
 	if err != nil {
 		logger.Error("Failed to hash password during registration", zap.Error(err))
 		return nil, fmt.Errorf("failed to hash password: %w", err)
 	}
 
+	// A deleted account with this email is either brought back or linked from the new one in the audit log. Owning
+	// the email isn't proven at registration, so only someone knowing the account's password may bring it back;
+	// anyone else gets a fresh account.
+	previous, err := s.userRepo.GetDeletedUserByEmail(ctx, req.Email)
+	if err != nil {
+		previous = nil // Never registered before, or the lookup failed; both register a fresh account
+	}
+	if previous != nil && s.authCfg.Reregistration == config.ReregisterRestore &&
+		bcrypt.CompareHashAndPassword([]byte(previous.Password), []byte(req.Password)) == nil {
+		return s.restoreUser(ctx, previous, req.Username, string(hashedPassword))
+	}
+
 	// Create a new user model
 	// ID, CreatedAt, UpdatedAt are handled by gorm.Model and the repository's raw SQL returning clause
 	user := &models.User{
 		Username: req.Username,
 		Email:    req.Email,
 		Password: string(hashedPassword),
+		Role:     models.RoleUser, // Elevated roles are granted out-of-band, never at registration
 	}
 
 	// Save the user to the database
 	// The repository method is responsible for setting the user.ID after successful creation
 	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if database.IsUniqueViolation(err) {
+			// A concurrent registration took the email or username after the checks above
+			return nil, errors.New("user with this email or username already exists")
+		}
 		logger.Error("Failed to create user in repository", zap.Error(err), zap.String("email", req.Email))
 		return nil, fmt.Errorf("failed to register user: %w", err)
 	}
 
+	if previous != nil {
+		if err := s.auditService.Record(ctx, "user.reregistered", "user", user.ID, map[string]interface{}{
+			"username":       user.Username,
+			"previousUserID": previous.ID,
+		}); err != nil {
+			logger.Warn("User re-registration audit event was not recorded", zap.Error(err), zap.Uint("userID", user.ID))
+		}
+	}
+
 	logger.Info("User registered successfully", zap.Uint("userID", user.ID), zap.String("email", user.Email))
 	return user, nil
 }
 
+// cascadeRestoreWindow is how long before a user's deletion their products count as deleted along with them
+const cascadeRestoreWindow = time.Minute
+
+// restoreUser brings a deleted account back for a re-registration with its password, keeping its ID so its audit
+// trail continues.
+// Products soft-deleted by the deletion cascade come back too; products reassigned to another user stay there.
+// The account returns with the user role, like any registration.
+func (s *userService) restoreUser(ctx context.Context, user *models.User, username, hashedPassword string) (*models.User, error) {
+	deletedAt := user.DeletedAt.Time
+	user.Username = username
+	user.Password = hashedPassword
+	user.Role = models.RoleUser
+	if err := s.userRepo.RestoreUser(ctx, user); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, errors.New("user with this email or username already exists")
+		}
+		logger.Error("Failed to restore user in repository", zap.Error(err), zap.Uint("userID", user.ID))
+		return nil, fmt.Errorf("failed to register user: %w", err)
+	}
+
+	restored, err := s.productRepo.RestoreProductsByUserID(ctx, user.ID, deletedAt.Add(-cascadeRestoreWindow))
+	if err != nil {
+		// The account is back either way; the products can still be restored by support
+		logger.Error("Failed to restore products of re-registered user", zap.Error(err), zap.Uint("userID", user.ID))
+	}
+
+	if err := s.auditService.Record(ctx, "user.restored", "user", user.ID, map[string]interface{}{
+		"username":         user.Username,
+		"deletedAt":        deletedAt,
+		"productsRestored": restored,
+	}); err != nil {
+		logger.Warn("User restore audit event was not recorded", zap.Error(err), zap.Uint("userID", user.ID))
+	}
+	logger.Info("Deleted user restored by re-registration", zap.Uint("userID", user.ID), zap.Int64("productsRestored", restored))
+	return user, nil
+}
+
 // LoginUser handles user login and token generation
 func (s *userService) LoginUser(ctx context.Context, req *models.LoginRequest) (*models.LoginResponse, error) {
+	user, err := s.Authenticate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Generate a JWT token
+	// JWTManager typically expects string IDs, so convert uint to string here
+	token, err := s.jwtManager.GenerateToken(fmt.Sprintf("%d", user.ID), user.Role)
+	if err != nil {
+		logger.Error("Failed to generate JWT token during login", zap.Error(err), zap.Uint("userID", user.ID))
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	logger.Info("User logged in successfully", zap.Uint("userID", user.ID), zap.String("email", user.Email))
+	return &models.LoginResponse{Token: token}, nil
+}
+
+// Authenticate checks a user's email and password, for every way of signing in.
+// Unknown emails and wrong passwords fail identically, in error and in timing.
+func (s *userService) Authenticate(ctx context.Context, req *models.LoginRequest) (*models.User, error) {
+	if err := validation.Struct(req); err != nil {
+		return nil, err
+	}
+	req.Normalize()
+	if s.throttle.RetryAfter(ctx, req.Email) > 0 {
+		return nil, errors.New("too many login attempts, try again later")
+	}
+	// A bcrypt comparison plus the lookup cannot finish in less; give up before spending the CPU
+	if err := deadline.Require(ctx, loginMinBudget); err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	fail := func() (*models.User, error) {
+		s.throttle.RecordFailure(ctx, req.Email)
+		if wait := s.authCfg.LoginMinDuration - time.Since(start); wait > 0 {
+			select {
+			case <-time.After(wait):
+			case <-ctx.Done():
+			}
+		}
+		return nil, errors.New("invalid credentials") // Generic error for security
+	}
+
 	// Retrieve the user by email
 	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
 	if err != nil {
 		logger.Warn("Login attempt with non-existent email", zap.String("email", req.Email), zap.Error(err))
-		return nil, errors.New("invalid credentials") // Generic error for security
+		compareDummyPassword(req.Password, s.authCfg.PasswordHash.Cost) // Spend the same bcrypt time as a real comparison
+		return fail()
 	}
 
 	// Compare the provided password with the hashed password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
 		logger.Warn("Login attempt with incorrect password", zap.String("email", req.Email))
-		return nil, errors.New("invalid credentials") // Generic error for security
+		return fail()
 	}
+	s.throttle.Reset(ctx, req.Email)
+	return user, nil
+}
 
-	// Generate a JWT token
-	// JWTManager typically expects string IDs, so convert uint to string here
-	token, err := s.jwtManager.GenerateToken(fmt.Sprintf("%d", user.ID))
+// Reauthenticate checks the password of a logged-in user and issues a fresh token, whose auth_time
+// satisfies the step-up check of sensitive endpoints. Failures count towards the login throttle.
+func (s *userService) Reauthenticate(ctx context.Context, userID uint, password string) (*models.LoginResponse, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
-		logger.Error("Failed to generate JWT token during login", zap.Error(err), zap.Uint("userID", user.ID))
+		logger.Warn("Re-authentication for unknown user", zap.Error(err), zap.Uint("userID", userID))
+		return nil, errors.New("invalid credentials")
+	}
+	if s.throttle.RetryAfter(ctx, user.Email) > 0 {
+		return nil, errors.New("too many login attempts, try again later")
+	}
+	if err := deadline.Require(ctx, loginMinBudget); err != nil {
+		return nil, err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		logger.Warn("Re-authentication with incorrect password", zap.Uint("userID", userID), actor.Field(ctx))
+		s.throttle.RecordFailure(ctx, user.Email)
+		return nil, errors.New("invalid credentials")
+	}
+
+	token, err := s.jwtManager.GenerateToken(fmt.Sprintf("%d", user.ID), user.Role)
+	if err != nil {
+		logger.Error("Failed to generate JWT token during re-authentication", zap.Error(err), zap.Uint("userID", user.ID))
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
+	s.throttle.Reset(ctx, user.Email)
 
-	logger.Info("User logged in successfully", zap.Uint("userID", user.ID), zap.String("email", user.Email))
+	logger.Info("User re-authenticated", zap.Uint("userID", user.ID), actor.Field(ctx))
 	return &models.LoginResponse{Token: token}, nil
 }
 
+// CurrentUser returns the live user with the ID, nil if there is none, and an error only if the lookup failed
+func (s *userService) CurrentUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up user: %w", err)
+	}
+	return user, nil
+}
+
 // GetUserProfile retrieves a user's profile by their ID
 func (s *userService) GetUserProfile(ctx context.Context, userID uint) (*models.User, error) { // Changed userID to uint
 	user, err := s.userRepo.GetUserByID(ctx, userID)
@@ -107,3 +309,209 @@ func (s *userService) GetUserProfile(ctx context.Context, userID uint) (*models.
 	logger.Debug("User profile retrieved", zap.Uint("userID", userID))
 	return user, nil
 }
+
+// DeleteUser deletes a user, applying the configured cascade strategy to the products they own. The user's row is
+// locked and the cascade and the deletion run in one transaction, so products created meanwhile can't escape the
+// cascade and a failure leaves everything as it was.
+func (s *userService) DeleteUser(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return errors.New("user not found")
+	}
+	if s.cascade.UserProducts == config.CascadeReassign && userID == s.cascade.ReassignToUserID {
+		return errors.New("cannot delete the user that receives reassigned products")
+	}
+
+	var affected int64
+	err = s.productRepo.Transaction(ctx, func(tx repository.ProductRepository) error {
+		live, err := tx.LockOwner(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		if !live {
+			return errors.New("user not found") // Deleted meanwhile
+		}
+		owned, err := tx.CountProductsByUserID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+
+		switch s.cascade.UserProducts {
+		case config.CascadeRestrict:
+			if owned > 0 {
+				logger.Warn("User deletion restricted by owned products", zap.Uint("userID", userID), zap.Int64("products", owned), actor.Field(ctx))
+				return errors.New("user still owns products")
+			}
+		case config.CascadeSoftDelete:
+			if affected, err = tx.SoftDeleteProductsByUserID(ctx, userID); err != nil {
+				return fmt.Errorf("failed to delete user: %w", err)
+			}
+		case config.CascadeReassign:
+			// The target stays live until the products it receives are committed
+			if live, err := tx.LockOwner(ctx, s.cascade.ReassignToUserID); err != nil || !live {
+				logger.Error("Product reassignment target does not exist", zap.Error(err), zap.Uint("reassignToUserID", s.cascade.ReassignToUserID))
+				return fmt.Errorf("failed to delete user: reassignment target not found")
+			}
+			if affected, err = tx.ReassignProducts(ctx, userID, s.cascade.ReassignToUserID); err != nil {
+				return fmt.Errorf("failed to delete user: %w", err)
+			}
+		default:
+			return fmt.Errorf("failed to delete user: unknown cascade strategy %q", s.cascade.UserProducts)
+		}
+
+		if err := tx.DeleteOwner(ctx, userID); err != nil {
+			logger.Error("Failed to delete user in repository", zap.Error(err), zap.Uint("userID", userID))
+			return fmt.Errorf("failed to delete user: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	repository.EvictUser(s.userRepo, userID)
+
+	revokeSessions(ctx, s.sessions, userID)
+
+	if err := s.auditService.Record(ctx, "user.deleted", "user", userID, map[string]interface{}{
+		"username":         user.Username,
+		"cascade":          s.cascade.UserProducts,
+		"productsAffected": affected,
+	}); err != nil {
+		logger.Warn("User deletion audit event was not recorded", zap.Error(err), zap.Uint("userID", userID))
+	}
+	logger.Info("User deleted successfully", zap.Uint("userID", userID), zap.String("cascade", s.cascade.UserProducts), zap.Int64("productsAffected", affected), actor.Field(ctx))
+	return nil
+}
+
+// SetPassword lets the owner of an account created without a password choose one, proving it with the one-time
+// token issued for the account. Unknown emails, wrong tokens and used tokens fail identically.
+func (s *userService) SetPassword(ctx context.Context, req *models.SetPasswordRequest) error {
+	if err := validation.Struct(req); err != nil {
+		return err
+	}
+	invalid := errors.New("invalid or used token")
+
+	user, err := s.userRepo.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		logger.Warn("Password set for non-existent email", zap.String("email", req.Email), zap.Error(err))
+		return invalid
+	}
+	tokenHash, pending := strings.CutPrefix(user.Password, models.PasswordPending+":")
+	if !pending || subtle.ConstantTimeCompare([]byte(tokenHash), []byte(auth.HashPersonalToken(req.Token))) != 1 {
+		logger.Warn("Password set with an invalid token", zap.Uint("userID", user.ID))
+		return invalid
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), s.authCfg.PasswordHash.Cost)
+	if err != nil {
+		logger.Error("Failed to hash password while setting it", zap.Error(err))
+		return fmt.Errorf("failed to hash password: %w", err)
+	}
+	if err := s.userRepo.SetPendingPassword(ctx, user.ID, user.Password, string(hashedPassword)); err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return invalid // The token was used concurrently, or the account deleted since
+		}
+		return fmt.Errorf("failed to set password: %w", err)
+	}
+
+	if err := s.auditService.Record(ctx, "user.password_set", "user", user.ID, nil); err != nil {
+		logger.Warn("Password set audit event was not recorded", zap.Error(err), zap.Uint("userID", user.ID))
+	}
+	logger.Info("Pending password set", zap.Uint("userID", user.ID))
+	return nil
+}
+
+// ImportUsers creates an account for every import record whose email is not registered yet.
+// Imported accounts get a pending password and cannot log in until their user sets one with the set-password
+// token of their row; there is no mailer to send invitations yet, so admins hand the tokens out. Bad rows are
+// reported per row without stopping the import.
+func (s *userService) ImportUsers(ctx context.Context, records []*models.UserImportRecord, report ProgressFunc) (*models.UserImportResult, error) {
+	result := &models.UserImportResult{Rows: make([]*models.UserImportRow, 0, len(records))}
+	seen := make(map[string]bool, len(records))
+
+	lastProgress := -1
+	for i, record := range records {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		result.Read++
+		row := &models.UserImportRow{Line: record.Line, Email: record.Email}
+		result.Rows = append(result.Rows, row)
+
+		user, token, err := s.importUser(ctx, record, seen)
+		switch {
+		case err != nil:
+			row.Status, row.Error = models.UserImportFailed, err.Error()
+			result.Failed++
+		case user == nil:
+			row.Status = models.UserImportSkipped
+			result.Skipped++
+		default:
+			row.Status, row.UserID, row.SetPasswordToken = models.UserImportCreated, user.ID, token
+			result.Created++
+		}
+
+		if p := (i + 1) * 100 / len(records); report != nil && p != lastProgress {
+			report(p)
+			lastProgress = p
+		}
+	}
+
+	logger.Info("User import finished", zap.Int("read", result.Read), zap.Int("created", result.Created), zap.Int("skipped", result.Skipped), zap.Int("failed", result.Failed), actor.Field(ctx))
+	return result, nil
+}
+
+// importUser validates and creates one imported user, returning its set-password token. It returns a nil user if
+// the email is already registered.
+func (s *userService) importUser(ctx context.Context, record *models.UserImportRecord, seen map[string]bool) (*models.User, string, error) {
+	// Imported rows don't pass through request binding, so they are normalized like registrations here
+	record.Email = validation.NormalizeEmail(record.Email)
+	record.Username = validation.NormalizeString(record.Username)
+	if _, err := mail.ParseAddress(record.Email); err != nil || record.Email == "" {
+		return nil, "", errors.New("invalid email")
+	}
+	if record.Username == "" {
+		return nil, "", errors.New("username is required")
+	}
+	role := record.Role
+	if role == "" {
+		role = models.RoleUser
+	}
+	if role != models.RoleUser && role != models.RoleAdmin {
+		return nil, "", fmt.Errorf("unknown role %q", record.Role)
+	}
+	if seen[record.Email] {
+		return nil, "", errors.New("duplicate email in import file")
+	}
+	seen[record.Email] = true
+
+	if existing, err := s.userRepo.GetUserByEmail(ctx, record.Email); err == nil && existing != nil {
+		return nil, "", nil
+	}
+
+	token, tokenHash, err := auth.GenerateSetPasswordToken()
+	if err != nil {
+		return nil, "", errors.New("failed to create user")
+	}
+
+	user := &models.User{
+		Username: record.Username,
+		Email:    record.Email,
+		Password: models.PendingPassword(tokenHash),
+		Role:     role,
+	}
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
+		if database.IsUniqueViolation(err) {
+			return nil, "", errors.New("username is already taken")
+		}
+		return nil, "", errors.New("failed to create user")
+	}
+
+	if err := s.auditService.Record(ctx, "user.imported", "user", user.ID, map[string]interface{}{
+		"username": user.Username,
+		"role":     user.Role,
+	}); err != nil {
+		logger.Warn("User import audit event was not recorded", zap.Error(err), zap.Uint("userID", user.ID))
+	}
+	return user, token, nil
+}
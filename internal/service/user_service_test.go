@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"gotemplate/config"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/pkg/auth"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+func newTestUserService(userRepo *fakeUserRepository) UserService {
+	jwtManager := auth.NewJWTManager(&config.JWTConfig{SecretKey: "test-secret", ExpiresInHour: time.Hour})
+	authCfg := config.AuthConfig{PasswordHash: config.PasswordHashConfig{Cost: bcrypt.MinCost}}
+	return NewUserService(userRepo, nil, jwtManager, fakeAuditService{}, config.CascadeConfig{}, authCfg, openThrottle{}, nil)
+}
+
+// TestImportedUserSetsPasswordAndLogsIn follows an imported account from the import through setting its password
+// with the token of its row to logging in
+func TestImportedUserSetsPasswordAndLogsIn(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(newFakeUserRepository())
+
+	result, err := users.ImportUsers(ctx, []*models.UserImportRecord{{Line: 2, Email: "Ada@Example.com", Username: "ada"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Created != 1 || result.Rows[0].SetPasswordToken == "" {
+		t.Fatalf("import created %d users, row %+v; want 1 with a set-password token", result.Created, result.Rows[0])
+	}
+	token := result.Rows[0].SetPasswordToken
+
+	login := func() error {
+		_, err := users.LoginUser(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "correct horse"})
+		return err
+	}
+	if err := login(); err == nil || err.Error() != "invalid credentials" {
+		t.Fatalf("login before setting a password: %v, want invalid credentials", err)
+	}
+
+	setPassword := func(token string) error {
+		return users.SetPassword(ctx, &models.SetPasswordRequest{Email: "ada@example.com", Token: token, Password: "correct horse"})
+	}
+	if err := setPassword(auth.SetPasswordTokenPrefix + "not-the-token"); err == nil || err.Error() != "invalid or used token" {
+		t.Fatalf("set password with a wrong token: %v, want invalid or used token", err)
+	}
+	if err := setPassword(token); err != nil {
+		t.Fatalf("set password: %v", err)
+	}
+	if err := setPassword(token); err == nil || err.Error() != "invalid or used token" {
+		t.Fatalf("set password with a used token: %v, want invalid or used token", err)
+	}
+
+	res, err := users.LoginUser(ctx, &models.LoginRequest{Email: "ada@example.com", Password: "correct horse"})
+	if err != nil {
+		t.Fatalf("login after setting the password: %v", err)
+	}
+	if res.Token == "" {
+		t.Fatal("login after setting the password returned no token")
+	}
+}
+
+// TestSetPasswordRefusesAccountsWithAPassword checks that a token can't replace the password of a registered account
+func TestSetPasswordRefusesAccountsWithAPassword(t *testing.T) {
+	ctx := context.Background()
+	users := newTestUserService(newFakeUserRepository())
+	if _, err := users.RegisterUser(ctx, &models.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "hunter22"}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := users.SetPassword(ctx, &models.SetPasswordRequest{Email: "bob@example.com", Token: auth.SetPasswordTokenPrefix + "guess", Password: "taken over"})
+	if err == nil || err.Error() != "invalid or used token" {
+		t.Fatalf("set password of a registered account: %v, want invalid or used token", err)
+	}
+}
+
+// fakeCascadeRepository keeps the number of products of each owner, deleting owners from users; a failed
+// Transaction rolls both back. Other methods aren't implemented.
+type fakeCascadeRepository struct {
+	repository.ProductRepository
+	users *fakeUserRepository
+	owned map[uint]int64
+}
+
+func (r *fakeCascadeRepository) Transaction(ctx context.Context, fn func(tx repository.ProductRepository) error) error {
+	owned := map[uint]int64{}
+	for id, n := range r.owned {
+		owned[id] = n
+	}
+	users := map[uint]models.User{}
+	for id, user := range r.users.users {
+		users[id] = *user
+	}
+	if err := fn(r); err != nil {
+		r.owned = owned
+		for id, user := range users {
+			*r.users.users[id] = user
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *fakeCascadeRepository) LockOwner(ctx context.Context, userID uint) (bool, error) {
+	_, err := r.users.GetUserByID(ctx, userID)
+	return err == nil, nil
+}
+
+func (r *fakeCascadeRepository) CountProductsByUserID(ctx context.Context, userID uint) (int64, error) {
+	return r.owned[userID], nil
+}
+
+func (r *fakeCascadeRepository) SoftDeleteProductsByUserID(ctx context.Context, userID uint) (int64, error) {
+	n := r.owned[userID]
+	delete(r.owned, userID)
+	return n, nil
+}
+
+func (r *fakeCascadeRepository) ReassignProducts(ctx context.Context, fromUserID, toUserID uint) (int64, error) {
+	n := r.owned[fromUserID]
+	r.owned[toUserID] += n
+	delete(r.owned, fromUserID)
+	return n, nil
+}
+
+func (r *fakeCascadeRepository) DeleteOwner(ctx context.Context, userID uint) error {
+	return r.users.DeleteUser(ctx, userID)
+}
+
+// TestDeleteUserFailsAtomically checks that a deletion failing in its cascade leaves the user and their products
+// as they were, and that a successful one deletes both
+func TestDeleteUserFailsAtomically(t *testing.T) {
+	ctx := context.Background()
+	userRepo := newFakeUserRepository(&models.User{Email: "ada@example.com", Username: "ada"}, &models.User{Email: "bob@example.com", Username: "bob"})
+	products := &fakeCascadeRepository{users: userRepo, owned: map[uint]int64{1: 3}}
+	jwtManager := auth.NewJWTManager(&config.JWTConfig{SecretKey: "test-secret", ExpiresInHour: time.Hour})
+	newService := func(cascade config.CascadeConfig) UserService {
+		return NewUserService(userRepo, products, jwtManager, fakeAuditService{}, cascade, config.AuthConfig{}, openThrottle{}, nil)
+	}
+
+	// The reassignment target is deleted before the products could move to it
+	if err := userRepo.DeleteUser(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	err := newService(config.CascadeConfig{UserProducts: config.CascadeReassign, ReassignToUserID: 2}).DeleteUser(ctx, 1)
+	if err == nil {
+		t.Fatal("deleting a user whose products can't be reassigned succeeded")
+	}
+	if _, err := userRepo.GetUserByID(ctx, 1); err != nil || products.owned[1] != 3 {
+		t.Fatalf("after a failed deletion the user was %v with %d products, want live with 3", err, products.owned[1])
+	}
+
+	if err := newService(config.CascadeConfig{UserProducts: config.CascadeSoftDelete}).DeleteUser(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := userRepo.GetUserByID(ctx, 1); err == nil || products.owned[1] != 0 {
+		t.Fatalf("after the deletion the user was found: %t, with %d products, want deleted with none", err == nil, products.owned[1])
+	}
+}
@@ -0,0 +1,135 @@
+package apiversion
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Header is the request header a client pins its API version with, e.g. "Api-Version: 2026-10-16".
+// Versions are dates, so they order as strings.
+const Header = "Api-Version"
+
+// Tag marks a response field deprecated as of a version, e.g. `removedIn:"2026-10-16"`. Clients pinned to
+// an earlier version keep receiving it next to its replacement; later ones don't. Combine it with
+// `json:",omitempty"` so the field disappears instead of being sent as a zero value, and delete the field
+// once the default version has moved past it.
+const Tag = "removedIn"
+
+// contextKey is unexported to prevent collisions with context keys from other packages
+type contextKey struct{}
+
+// WithVersion returns a copy of ctx carrying the client's API version
+func WithVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, contextKey{}, version)
+}
+
+// FromContext returns the API version stored in ctx; "" predates every deprecation
+func FromContext(ctx context.Context) string {
+	version, _ := ctx.Value(contextKey{}).(string)
+	return version
+}
+
+// ForVersion zeroes, in place, every field in v removed in or before version, and returns the deprecated fields
+// v still carries, as "Type.jsonName". v must be a pointer (or a slice of pointers), like for redact.ForRole.
+func ForVersion(v interface{}, version string) (deprecated []string) {
+	seen := map[string]bool{}
+	apply(reflect.ValueOf(v), version, seen)
+	for field := range seen {
+		deprecated = append(deprecated, field)
+	}
+	return deprecated
+}
+
+// apply walks a value, clears the fields removed for version and collects the deprecated fields kept
+func apply(v reflect.Value, version string, seen map[string]bool) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			apply(v.Elem(), version, seen)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			apply(v.Index(i), version, seen)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue // Unexported fields are never serialized, and values passed by copy can't be modified
+			}
+			if removedIn, ok := t.Field(i).Tag.Lookup(Tag); ok {
+				if version >= removedIn {
+					field.Set(reflect.Zero(field.Type()))
+					continue
+				}
+				if !field.IsZero() {
+					seen[t.Name()+"."+jsonName(t.Field(i))] = true
+				}
+			}
+			apply(field, version, seen)
+		}
+	}
+}
+
+// jsonName returns the name a field is serialized under
+func jsonName(f reflect.StructField) string {
+	if name, _, _ := strings.Cut(f.Tag.Get("json"), ","); name != "" {
+		return name
+	}
+	return f.Name
+}
+
+// Usage describes a request that used deprecated endpoints or fields, for sunset telemetry
+type Usage struct {
+	Features  []string // e.g. "field:ProductResponse.price" or "route:GET /api/v1/..."
+	UserID    uint     // Acting user; zero for unauthenticated requests
+	Via       string   // How the user authenticated, see actor.Via*
+	UserAgent string
+}
+
+// UsageRecorder receives the Usage of every request that used a deprecated feature
+type UsageRecorder interface {
+	RecordDeprecatedUsage(u Usage)
+}
+
+// usageKey is unexported to prevent collisions with context keys from other packages
+type usageKey struct{}
+
+// usage collects the deprecated features a request used
+type usage struct {
+	mu       sync.Mutex
+	features []string
+}
+
+// WithUsage returns a copy of ctx collecting the deprecated features the request uses, see MarkUsed,
+// and a function returning them once the request is done
+func WithUsage(ctx context.Context) (context.Context, func() []string) {
+	u := &usage{}
+	return context.WithValue(ctx, usageKey{}, u), func() []string {
+		u.mu.Lock()
+		defer u.mu.Unlock()
+		return u.features
+	}
+}
+
+// MarkUsed records that the request of ctx used deprecated features. It is a no-op without WithUsage.
+func MarkUsed(ctx context.Context, features ...string) {
+	u, ok := ctx.Value(usageKey{}).(*usage)
+	if !ok {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for _, f := range features {
+		known := false
+		for _, existing := range u.features {
+			known = known || existing == f
+		}
+		if !known {
+			u.features = append(u.features, f)
+		}
+	}
+}
@@ -0,0 +1,455 @@
+// Package app wires the whole server together so it can be run from cmd/main.go,
+// embedded in another Go program, or started in-process by end-to-end tests.
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/internal/handler"
+	"gotemplate/internal/models"
+	"gotemplate/internal/repository"
+	"gotemplate/internal/router"
+	"gotemplate/internal/service"
+	"gotemplate/pkg/auditsink"
+	"gotemplate/pkg/auth"
+	"gotemplate/pkg/database"
+	"gotemplate/pkg/geoip"
+	"gotemplate/pkg/leader"
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/module"
+	"gotemplate/pkg/readonly"
+	"gotemplate/pkg/session"
+	"net"
+	"net/http"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// App is a fully wired server: database, services, background workers and HTTP server
+type App struct {
+	cfg         *config.Config
+	db          *gorm.DB
+	server      *http.Server
+	listener    net.Listener
+	modules     []module.Module // Features, in dependency order
+	routes      []*models.RouteInfo
+	sessions    *session.RedisStore      // Nil unless cookie sessions are enabled
+	operations  service.OperationService // Waited for at shutdown
+	stopWorkers context.CancelFunc
+	serveErr    chan error
+}
+
+// New connects to the database and wires every repository, service and handler.
+// Nothing is started until Start is called. The logger is initialized from cfg if the host hasn't done so.
+func New(cfg *config.Config) (*App, error) {
+	if logger.ZapLogger == nil {
+		logger.InitLogger(cfg.Server.Debug)
+	}
+
+	db, err := database.NewPostgresDB(&cfg.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+
+	a := &App{cfg: cfg, db: db, serveErr: make(chan error, 1)}
+	if err := a.wire(); err != nil {
+		database.CloseDB(db)
+		return nil, err
+	}
+
+	var models []interface{}
+	for _, m := range a.modules {
+		models = append(models, m.Migrations()...)
+	}
+	if err := database.Migrate(context.Background(), db, models, a.schemaMigrations()); err != nil {
+		database.CloseDB(db)
+		return nil, err
+	}
+	return a, nil
+}
+
+// schemaMigrations returns the versioned schema changes of every module
+func (a *App) schemaMigrations() []database.Migration {
+	var schemaMigrations []database.Migration
+	for _, m := range a.modules {
+		schemaMigrations = append(schemaMigrations, m.SchemaMigrations()...)
+	}
+	return schemaMigrations
+}
+
+// MigrationReport compares the schema migrations recorded in the database with those of this release
+func (a *App) MigrationReport(ctx context.Context) (*database.MigrationReport, error) {
+	return database.GetMigrationReport(ctx, a.db, a.schemaMigrations())
+}
+
+// Modules returns the application's feature modules
+func (a *App) Modules() []module.Module {
+	return a.modules
+}
+
+// wire instantiates the dependency graph, assembles the feature modules and builds the HTTP server
+func (a *App) wire() error {
+	cfg, db := a.cfg, a.db
+
+	// Reachability of the database, for the readiness probe and connection pool resets
+	dbMonitor, err := database.NewMonitor(db, cfg.Database.HealthCheckInterval, cfg.Database.PoolWaitThreshold)
+	if err != nil {
+		return fmt.Errorf("failed to set up database monitor: %w", err)
+	}
+	if cfg.Server.Debug && cfg.Database.ExplainThreshold > 0 {
+		if err := database.EnableExplain(db, cfg.Database.ExplainThreshold); err != nil {
+			return fmt.Errorf("failed to set up slow query plans: %w", err)
+		}
+	}
+
+	if cfg.Database.QueryTags != config.QueryTagsOff {
+		if err := database.EnableQueryTags(db, cfg.Database.QueryTags == config.QueryTagsRequest); err != nil {
+			return fmt.Errorf("failed to set up query tags: %w", err)
+		}
+	}
+
+	// Instantiate Repositories
+	userRepo := repository.NewCachedUserRepository(repository.NewPostgresUserRepository(db), cfg.Database.UserCacheTTL)
+	productRepo := repository.NewPostgresProductRepository(db)
+	operationRepo := repository.NewPostgresOperationRepository(db)
+	announcementRepo := repository.NewPostgresAnnouncementRepository(db)
+	commentRepo := repository.NewPostgresCommentRepository(db)
+	reportRepo := repository.NewPostgresReportRepository(db)
+	auditRepo := repository.NewPostgresAuditRepository(db)
+	activityRepo := repository.NewPostgresActivityRepository(db)
+	addressRepo := repository.NewPostgresAddressRepository(db)
+	bundleRepo := repository.NewPostgresBundleRepository(db)
+	loginAttemptRepo := repository.NewPostgresLoginAttemptRepository(db)
+	personalTokenRepo := repository.NewPostgresPersonalTokenRepository(db)
+	searchRepo := repository.NewPostgresSearchRepository(db)
+	savedSearchRepo := repository.NewPostgresSavedSearchRepository(db)
+	processingRepo := repository.NewPostgresProcessingRepository(db)
+	anonymizationRepo := repository.NewPostgresAnonymizationRepository(db)
+	accountMergeRepo := repository.NewPostgresAccountMergeRepository(db)
+	productChangeRepo := repository.NewPostgresProductChangeRepository(db)
+	productPermissionRepo := repository.NewPostgresProductPermissionRepository(db)
+	deprecationRepo := repository.NewPostgresDeprecationRepository(db)
+	statsRepo := repository.NewPostgresStatsRepository(db)
+	reportTemplateRepo := repository.NewPostgresReportTemplateRepository(db)
+
+	// Instantiate JWT Manager
+	jwtManager := auth.NewJWTManager(&cfg.JWT)
+
+	// Read-only switch, shared by the middleware, services and the admin toggle
+	readOnly := readonly.New(cfg.Server.ReadOnly, "server.readOnly is set")
+
+	// Cookie session store, when enabled; services end the sessions of users they delete
+	var sessionStore session.Store
+	if sessCfg := cfg.Auth.Sessions; sessCfg.RedisAddr != "" {
+		a.sessions = session.NewRedisStore(sessCfg.RedisAddr, sessCfg.RedisPassword, sessCfg.RedisDB, sessCfg.RedisTimeout)
+		sessionStore = a.sessions
+	}
+
+	// Instantiate Services with their respective repositories and managers
+	activityService := service.NewActivityService(activityRepo)
+	processingLogService := service.NewProcessingLogService(processingRepo)
+	auditService := service.NewAuditService(auditRepo, activityService) // Activity feeds are projected from audit events
+	userService := service.NewUserService(userRepo, productRepo, jwtManager, auditService, cfg.Cascade, cfg.Auth, service.NewLoginThrottle(loginAttemptRepo, cfg.Auth.LoginThrottle, readOnly), sessionStore)
+	backupService := service.NewBackupService(db, cfg.Backup, auditService)
+	anonymizationService := service.NewAnonymizationService(anonymizationRepo, userRepo, auditService)
+	accountMergeService := service.NewAccountMergeService(accountMergeRepo, userRepo, auditService, sessionStore)
+	personalTokenService := service.NewPersonalTokenService(personalTokenRepo, auditService, cfg.Auth.PersonalTokenMaxLifetime, readOnly)
+	productService := service.NewProductService(productRepo, productPermissionRepo, userRepo, auditService)
+	productChangeService := service.NewProductChangeService(productChangeRepo, productRepo, cfg.Sync)
+	operationService := service.NewOperationService(operationRepo, readOnly)
+	a.operations = operationService
+	announcementService := service.NewAnnouncementService(announcementRepo)
+	commentService := service.NewCommentService(commentRepo, productRepo, productService)
+	reportService := service.NewReportService(reportRepo, productRepo, commentRepo, auditService)
+	bundleService := service.NewBundleService(bundleRepo, productRepo, auditService)
+	addressService := service.NewAddressService(addressRepo, service.NewBasicAddressValidator()) // Swap in a provider-backed AddressValidator here
+	searchService := service.NewSearchService(searchRepo, cfg.Search)
+	savedSearchService := service.NewSavedSearchService(savedSearchRepo, productRepo)
+	deprecationService := service.NewDeprecationService(deprecationRepo)
+	statsService := service.NewStatsService(statsRepo, cfg.Stats, readOnly)
+	productViewService := service.NewProductViewService(statsRepo, productRepo, cfg.Stats, readOnly)
+	apiUsageService := service.NewAPIUsageService(statsRepo, userRepo, cfg.Stats, cfg.Cost, readOnly)
+	reportTemplateService := service.NewReportTemplateService(reportTemplateRepo, auditService)
+
+	// Background workers. Those maintaining shared data run on one instance at a time; those flushing what an
+	// instance counted in memory run on every instance.
+	auditPartitions := database.NewPartitionManager(db, database.MonthlyPartitions{Table: "audit_events", Retention: cfg.Audit.Retention})
+	auditWorkers := []module.Worker{leader.Singleton(db, "audit partitions", auditPartitions.Run)}
+	var auditForwarder *service.AuditForwarder
+	if fwdCfg := cfg.Audit.Forwarder; fwdCfg.Sink != "" {
+		sink, err := auditsink.New(fwdCfg.Sink, fwdCfg.URL, fwdCfg.AuthHeader, fwdCfg.SyslogNetwork, fwdCfg.SyslogAddress)
+		if err != nil {
+			return fmt.Errorf("invalid audit forwarder configuration: %w", err)
+		}
+		auditForwarder = service.NewAuditForwarder(auditRepo, sink, fwdCfg)
+		auditWorkers = append(auditWorkers, leader.Singleton(db, "audit forwarder "+fwdCfg.Sink, auditForwarder.Run)) // One cursor per sink
+	}
+	statusService := service.NewStatusService(dbMonitor, operationRepo, auditForwarder)
+	commands := []*service.AdminCommand{service.StatsRefreshCommand(statsService), service.SearchReindexCommand(searchService)}
+	if auditForwarder != nil {
+		commands = append(commands, service.AuditRedeliverCommand(auditForwarder))
+	}
+	commandService := service.NewAdminCommandService(operationService, auditService, commands...)
+	var sessionService service.SessionService
+	var sessionRoutes func(r *module.Routes)
+	if sessCfg := cfg.Auth.Sessions; sessCfg.RedisAddr != "" {
+		sessionService = service.NewSessionService(userService, sessionStore, sessCfg)
+		sessionRoutes = router.SessionRoutes(handler.NewSessionHandler(sessionService, sessCfg))
+		logger.Info("Session cookie authentication enabled", zap.String("redis", sessCfg.RedisAddr))
+	}
+	var labelRoutes func(r *module.Routes)
+	if labelCfg := cfg.Labels; labelCfg.ProductURL != "" {
+		labelRoutes = router.LabelRoutes(handler.NewLabelHandler(productService, labelCfg))
+	}
+	var geoDB *geoip.DB
+	var geoWorkers []module.Worker
+	if geoCfg := cfg.GeoIP; geoCfg.DatabasePath != "" {
+		geoDB, err = geoip.Open(geoCfg.DatabasePath, geoCfg.ReloadInterval)
+		if err != nil {
+			return err
+		}
+		geoWorkers = append(geoWorkers, geoDB.Run)
+		logger.Info("GeoIP country lookup enabled", zap.String("path", geoCfg.DatabasePath))
+	}
+
+	// Feature modules, in dependency order: a module's models may only reference models of earlier modules
+	a.modules = []module.Module{
+		&module.Definition{
+			ModuleName: "health",
+			Routes:     router.HealthRoutes(handler.NewHealthHandler(dbMonitor)),
+			Jobs:       []module.Worker{dbMonitor.Run},
+		},
+		&module.Definition{
+			ModuleName: "geoip",
+			Jobs:       geoWorkers,
+		},
+		&module.Definition{
+			ModuleName: "admin",
+			Routes:     router.AdminRoutes(handler.NewAdminHandler(cfg, statusService, readOnly, auditService, deprecationService, a.Routes, a.MigrationReport)),
+			Models:     []interface{}{&models.DeprecationUsage{}},
+			Jobs:       []module.Worker{deprecationService.Run},
+		},
+		&module.Definition{
+			ModuleName: "backups",
+			Routes:     router.BackupRoutes(handler.NewBackupHandler(backupService, operationService)),
+		},
+		&module.Definition{
+			ModuleName: "users",
+			Routes:     router.UserRoutes(handler.NewUserHandler(userService, operationService, anonymizationService, accountMergeService)),
+			Models:     []interface{}{&models.User{}, &models.LoginAttempt{}},
+			Schema:     repository.UserMigrations,
+		},
+		&module.Definition{
+			ModuleName: "sessions",
+			Routes:     sessionRoutes,
+		},
+		&module.Definition{
+			ModuleName: "operations",
+			Routes:     router.OperationRoutes(handler.NewOperationHandler(operationService)),
+			Models:     []interface{}{&models.Operation{}},
+			Jobs:       []module.Worker{operationService.Run},
+		},
+		&module.Definition{
+			ModuleName: "commands",
+			Routes:     router.CommandRoutes(handler.NewCommandHandler(commandService)),
+		},
+		&module.Definition{
+			ModuleName: "audit",
+			Routes:     router.AuditRoutes(handler.NewAuditHandler(auditService)),
+			Models:     []interface{}{&models.AuditEvent{}, &models.AuditForwardCursor{}},
+			Schema:     repository.AuditMigrations,
+			Jobs:       auditWorkers,
+		},
+		&module.Definition{
+			ModuleName: "processing",
+			Routes:     router.ProcessingRoutes(handler.NewProcessingHandler(processingLogService)),
+			Models:     []interface{}{&models.ProcessingRecord{}},
+		},
+		&module.Definition{
+			ModuleName: "tokens",
+			Routes:     router.PersonalTokenRoutes(handler.NewPersonalTokenHandler(personalTokenService)),
+			Models:     []interface{}{&models.PersonalAccessToken{}},
+		},
+		&module.Definition{
+			ModuleName: "activity",
+			Routes:     router.ActivityRoutes(handler.NewActivityHandler(activityService)),
+			Models:     []interface{}{&models.ActivityEntry{}},
+		},
+		&module.Definition{
+			ModuleName: "products",
+			Routes:     router.ProductRoutes(handler.NewProductHandler(productService, operationService, savedSearchService, processingLogService, productChangeService, productViewService)),
+			Models:     []interface{}{&models.Product{}, &models.ProductChange{}, &models.ProductPermission{}},
+			Schema:     repository.ProductMigrations,
+			Jobs:       []module.Worker{leader.Singleton(db, "product change pruning", productChangeService.Run)},
+		},
+		&module.Definition{
+			ModuleName: "labels",
+			Routes:     labelRoutes,
+		},
+		&module.Definition{
+			ModuleName: "bundles",
+			Routes:     router.BundleRoutes(handler.NewBundleHandler(bundleService)),
+			Models:     []interface{}{&models.Bundle{}, &models.BundleItem{}},
+		},
+		&module.Definition{
+			ModuleName: "comments",
+			Routes:     router.CommentRoutes(handler.NewCommentHandler(commentService)),
+			Models:     []interface{}{&models.Comment{}},
+		},
+		&module.Definition{
+			ModuleName: "reports",
+			Routes:     router.ReportRoutes(handler.NewReportHandler(reportService)),
+			Models:     []interface{}{&models.Report{}},
+		},
+		&module.Definition{
+			ModuleName: "announcements",
+			Routes:     router.AnnouncementRoutes(handler.NewAnnouncementHandler(announcementService)),
+			Models:     []interface{}{&models.Announcement{}},
+		},
+		&module.Definition{
+			ModuleName: "addresses",
+			Routes:     router.AddressRoutes(handler.NewAddressHandler(addressService)),
+			Models:     []interface{}{&models.Address{}},
+		},
+		&module.Definition{
+			ModuleName: "search",
+			Routes:     router.SearchRoutes(handler.NewSearchHandler(searchService, processingLogService)),
+		},
+		&module.Definition{
+			ModuleName: "savedsearches",
+			Routes:     router.SavedSearchRoutes(handler.NewSavedSearchHandler(savedSearchService)),
+			Models:     []interface{}{&models.SavedSearch{}},
+		},
+		&module.Definition{
+			ModuleName: "stats",
+			Routes:     router.StatsRoutes(handler.NewStatsHandler(statsService, productViewService, apiUsageService, processingLogService, cfg.Stats)),
+			Schema:     repository.StatsMigrations,
+			Jobs:       []module.Worker{leader.Singleton(db, "stats refresh", statsService.Run), productViewService.Run, apiUsageService.Run},
+		},
+		&module.Definition{
+			ModuleName: "reporting",
+			Routes:     router.ReportTemplateRoutes(handler.NewReportTemplateHandler(reportTemplateService, processingLogService)),
+			Models:     []interface{}{&models.ReportTemplate{}},
+		},
+	}
+
+	// Setup Gin Router; every module registers its own routes
+	r := router.SetupRouter(cfg, jwtManager, personalTokenService, readOnly, deprecationService, geoDB, sessionService, userService, apiUsageService, a.modules)
+	// Every route must declare who may call it
+	if err := router.CheckRouteAccess(a.modules); err != nil {
+		return err
+	}
+	a.routes = router.RouteTable(r, a.modules)
+	for _, route := range a.routes {
+		if len(route.Duplicates) > 0 {
+			logger.Warn("Route runs middleware more than once", zap.String("method", route.Method), zap.String("path", route.Path), zap.Strings("middleware", route.Duplicates))
+		}
+	}
+
+	a.server = &http.Server{
+		Addr:              ":" + cfg.Server.Port,        // Server address (e.g., ":8080"); port "0" picks a free port
+		Handler:           r,                            // Gin router as the handler
+		ReadTimeout:       cfg.Server.ReadTimeout,       // Timeout for reading request body
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout, // Timeout for reading request headers
+		WriteTimeout:      cfg.Server.WriteTimeout,      // Timeout for writing response body
+		IdleTimeout:       cfg.Server.IdleTimeout,       // Timeout for idle connections
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,    // Cap on request headers
+	}
+	a.server.SetKeepAlivesEnabled(cfg.Server.KeepAlives)
+	return nil
+}
+
+// Routes returns every registered route with its middleware chain and required roles and scopes
+func (a *App) Routes() []*models.RouteInfo {
+	return a.routes
+}
+
+// Handler returns the HTTP handler, e.g. for use with httptest without opening a port
+func (a *App) Handler() http.Handler {
+	return a.server.Handler
+}
+
+// DB returns the database connection, e.g. for seeding data in tests
+func (a *App) DB() *gorm.DB {
+	return a.db
+}
+
+// Start starts the background workers and begins serving HTTP. It returns once the port is bound;
+// errors from the server after that are delivered on Err.
+func (a *App) Start() error {
+	a.benchmarkPasswordHashing()
+
+	ln, err := net.Listen("tcp", a.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", a.server.Addr, err)
+	}
+	a.listener = ln
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	a.stopWorkers = stopWorkers
+	for _, m := range a.modules {
+		for _, run := range m.Workers() {
+			go run(workerCtx)
+		}
+	}
+
+	go func() {
+		logger.Info("Server listening", zap.String("addr", ln.Addr().String()), zap.String("jsonEncoder", jsonEncoder))
+		if err := a.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.serveErr <- err
+		}
+	}()
+	return nil
+}
+
+// benchmarkPasswordHashing warns when hashing a password at the configured cost takes longer or shorter than
+// intended on this hardware
+func (a *App) benchmarkPasswordHashing() {
+	report, err := service.MeasurePasswordHashing(a.cfg.Auth.PasswordHash)
+	if err != nil {
+		logger.Warn("Password hashing benchmark failed", zap.Error(err))
+		return
+	}
+	if report.Verdict != models.PasswordHashOK {
+		logger.Warn("Password hashing time is outside its target range, adjust auth.passwordHash.cost",
+			zap.Int("cost", report.Cost), zap.Float64("durationMs", report.DurationMs), zap.String("verdict", report.Verdict), zap.Int("suggestedCost", report.SuggestedCost))
+		return
+	}
+	logger.Info("Password hashing benchmark", zap.Int("cost", report.Cost), zap.Float64("durationMs", report.DurationMs))
+}
+
+// Addr returns the address the server is listening on, or "" before Start
+func (a *App) Addr() string {
+	if a.listener == nil {
+		return ""
+	}
+	return a.listener.Addr().String()
+}
+
+// Err delivers an error if the HTTP server stops unexpectedly
+func (a *App) Err() <-chan error {
+	return a.serveErr
+}
+
+// Shutdown stops the background workers, drains in-flight requests and then running operations until ctx expires,
+// and closes the database
+func (a *App) Shutdown(ctx context.Context) error {
+	if a.stopWorkers != nil {
+		a.stopWorkers()
+	}
+	defer database.CloseDB(a.db)
+
+	if a.listener == nil {
+		return nil // Never started
+	}
+	if err := a.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("server forced to shutdown: %w", err)
+	}
+	if err := a.operations.Wait(ctx); err != nil {
+		return fmt.Errorf("server stopped before its operations finished: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/pkg/logger"
+	"net"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// jwtSecretPlaceholders are found in secrets copied from examples and templates instead of generated
+var jwtSecretPlaceholders = []string{"changeme", "change-me", "change_me", "your-secret", "your_secret", "secret-key", "secret_key", "example", "placeholder"}
+
+// minJWTSecretDistinctBytes is the fewest distinct bytes a generated secret of minJWTSecretSize bytes has in
+// practice; fewer means a typed or repeated pattern
+const minJWTSecretDistinctBytes = 10
+
+// defaultDatabasePassword is the database.password default of config.LoadConfig
+const defaultDatabasePassword = "password"
+
+// insecureSetting is a setting with an insecure value
+type insecureSetting struct {
+	key    string // Configuration key, as listed in server.allowInsecure
+	reason string
+}
+
+// insecureSettings returns the settings of cfg that are unsafe outside local development
+func insecureSettings(cfg *config.Config) []insecureSetting {
+	var found []insecureSetting
+	secret := strings.ToLower(cfg.JWT.SecretKey)
+	for _, placeholder := range jwtSecretPlaceholders {
+		if strings.Contains(secret, placeholder) {
+			found = append(found, insecureSetting{"jwt.secretKey", fmt.Sprintf("looks like a placeholder (contains %q), generate a random one", placeholder)})
+			break
+		}
+	}
+	if len(found) == 0 && distinctBytes(cfg.JWT.SecretKey) < minJWTSecretDistinctBytes {
+		found = append(found, insecureSetting{"jwt.secretKey", "is a repeated pattern, not a random secret"})
+	}
+
+	if cfg.Database.Password == defaultDatabasePassword {
+		found = append(found, insecureSetting{"database.password", "is the default password"})
+	}
+	switch cfg.Database.SSLMode {
+	case "disable", "allow", "prefer":
+		if !isLocalHost(cfg.Database.Host) {
+			found = append(found, insecureSetting{"database.sslmode", fmt.Sprintf("%q may send credentials and data to %s unencrypted, use verify-full", cfg.Database.SSLMode, cfg.Database.Host)})
+		}
+	}
+
+	if cfg.Auth.Sessions.RedisAddr != "" && !cfg.Auth.Sessions.SecureCookie {
+		found = append(found, insecureSetting{"auth.sessions.secureCookie", "is off, so session cookies are sent over plain HTTP"})
+	}
+	for _, proxy := range cfg.Server.TrustedProxies {
+		if _, ipNet, err := net.ParseCIDR(proxy); err == nil {
+			if ones, _ := ipNet.Mask.Size(); ones == 0 {
+				found = append(found, insecureSetting{"server.trustedProxies", fmt.Sprintf("%s trusts every client's forwarding headers, so anyone can pick their client IP", proxy)})
+			}
+		}
+	}
+	return found
+}
+
+// checkInsecureSettings warns about insecure settings in debug mode. In release mode it fails on them, unless
+// they are listed in server.allowInsecure, so a production instance doesn't start with a development setup.
+func (a *App) checkInsecureSettings(ctx context.Context) error {
+	allowed := map[string]bool{}
+	for _, key := range a.cfg.Server.AllowInsecure {
+		allowed[key] = true
+	}
+	var warnings Warnings
+	var refused []string
+	for _, s := range insecureSettings(a.cfg) {
+		finding := s.key + " " + s.reason
+		logger.Warn("Insecure setting", zap.String("setting", s.key), zap.String("reason", s.reason), zap.Bool("allowed", allowed[s.key]))
+		if a.cfg.Server.Debug || allowed[s.key] {
+			warnings = append(warnings, finding)
+			continue
+		}
+		refused = append(refused, finding)
+	}
+	if len(refused) > 0 {
+		return fmt.Errorf("refusing to start outside debug mode: %s (list accepted risks in server.allowInsecure)", strings.Join(refused, "; "))
+	}
+	if len(warnings) > 0 {
+		return warnings
+	}
+	return nil
+}
+
+// isLocalHost reports whether a database host is reached without crossing the network: a loopback address or
+// a Unix socket directory
+func isLocalHost(host string) bool {
+	if host == "localhost" || strings.HasPrefix(host, "/") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// distinctBytes counts the different bytes of s
+func distinctBytes(s string) int {
+	seen := map[byte]bool{}
+	for i := 0; i < len(s); i++ {
+		seen[s[i]] = true
+	}
+	return len(seen)
+}
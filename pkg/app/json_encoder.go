@@ -0,0 +1,7 @@
+//go:build !go_json
+
+package app
+
+// jsonEncoder is the encoder gin writes JSON responses with. Building with -tags=go_json switches to
+// github.com/goccy/go-json, which may encode large lists faster; measure with cmd/jsonbench before switching.
+const jsonEncoder = "encoding/json"
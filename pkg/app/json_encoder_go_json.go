@@ -0,0 +1,6 @@
+//go:build go_json
+
+package app
+
+// jsonEncoder is the encoder gin writes JSON responses with, chosen by the go_json build tag
+const jsonEncoder = "github.com/goccy/go-json"
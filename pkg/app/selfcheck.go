@@ -0,0 +1,213 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gotemplate/pkg/database"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// minJWTSecretSize is the shortest accepted HMAC secret: HS256 keys must be at least 256 bits (RFC 7518)
+const minJWTSecretSize = 32
+
+// selfCheckTimeout bounds each startup self-check
+const selfCheckTimeout = 10 * time.Second
+
+// Check is one startup self-check
+type Check struct {
+	Name string
+	Run  func(ctx context.Context) error // Fails with an error; passes with reservations by returning Warnings
+}
+
+// Warnings are the reservations of a check that passed
+type Warnings []string
+
+func (w Warnings) Error() string {
+	return strings.Join(w, "; ")
+}
+
+// Checks returns the startup self-checks, in the order SelfCheck runs them
+func (a *App) Checks() []Check {
+	return []Check{
+		{Name: "configuration", Run: a.checkConfig},
+		{Name: "JWT key material", Run: a.checkJWTKey},
+		{Name: "insecure settings", Run: a.checkInsecureSettings},
+		{Name: "database connection", Run: a.checkDatabase},
+		{Name: "database schema", Run: a.checkSchema},
+		{Name: "session store", Run: a.checkSessionStore},
+	}
+}
+
+// SelfCheck runs the checks in order before the port is bound, writing a checklist to w, warnings included.
+// It stops at the first failure and returns it, so the instance never starts half-working.
+func (a *App) SelfCheck(ctx context.Context, w io.Writer) error {
+	checks := a.Checks()
+	fmt.Fprintln(w, "Startup self-check:")
+	for i, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, selfCheckTimeout)
+		err := check.Run(checkCtx)
+		cancel()
+		var warnings Warnings
+		if errors.As(err, &warnings) {
+			fmt.Fprintf(w, "  [WARN] %s:\n", check.Name)
+			for _, warning := range warnings {
+				fmt.Fprintf(w, "         - %s\n", warning)
+			}
+			continue
+		}
+		if err != nil {
+			fmt.Fprintf(w, "  [FAIL] %s: %v\n", check.Name, err)
+			for _, skipped := range checks[i+1:] {
+				fmt.Fprintf(w, "  [SKIP] %s\n", skipped.Name)
+			}
+			return fmt.Errorf("self-check %q failed: %w", check.Name, err)
+		}
+		fmt.Fprintf(w, "  [ OK ] %s\n", check.Name)
+	}
+	return nil
+}
+
+// checkConfig catches settings that LoadConfig accepts but that would break the server at runtime
+func (a *App) checkConfig(ctx context.Context) error {
+	cfg := a.cfg
+	if port, err := strconv.Atoi(cfg.Server.Port); err != nil || port < 0 || port > 65535 {
+		return fmt.Errorf("server.port %q is not a port number", cfg.Server.Port)
+	}
+	if cfg.Server.WriteTimeout > 0 && cfg.Server.RequestBudget >= cfg.Server.WriteTimeout {
+		return fmt.Errorf("server.requestBudget (%s) must be shorter than server.writeTimeout (%s), or handlers can't write their timeout errors",
+			cfg.Server.RequestBudget, cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.MaxDecodedBodySize <= 0 {
+		return fmt.Errorf("server.maxDecodedBodySize must be positive")
+	}
+	if cfg.Database.HealthCheckInterval <= 0 {
+		return fmt.Errorf("database.healthCheckInterval must be positive")
+	}
+	if cfg.JWT.ExpiresInHour <= 0 {
+		return fmt.Errorf("jwt.expiresInHour must be positive")
+	}
+	if err := cfg.Server.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Database.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.JWT.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Auth.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Audit.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Cascade.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Chaos.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Canary.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Shadow.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Cost.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Search.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Backup.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Sync.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Stats.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.Labels.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.API.Validate(); err != nil {
+		return err
+	}
+	return cfg.GeoIP.Validate()
+}
+
+// checkJWTKey verifies there is a signing secret long enough for HS256
+func (a *App) checkJWTKey(ctx context.Context) error {
+	switch n := len(a.cfg.JWT.SecretKey); {
+	case n == 0:
+		return fmt.Errorf("jwt.secretKey is empty")
+	case n < minJWTSecretSize:
+		return fmt.Errorf("jwt.secretKey is %d bytes, at least %d are required", n, minJWTSecretSize)
+	}
+	return nil
+}
+
+// checkDatabase verifies the database answers
+func (a *App) checkDatabase(ctx context.Context) error {
+	sqlDB, err := a.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// checkSessionStore verifies that Redis answers, if cookie sessions are enabled
+func (a *App) checkSessionStore(ctx context.Context) error {
+	if a.sessions == nil {
+		return nil
+	}
+	return a.sessions.Ping(ctx)
+}
+
+// checkSchema verifies that every versioned migration was applied and every module's tables, columns and
+// indexes exist, e.g. after a failed or partial migration
+func (a *App) checkSchema(ctx context.Context) error {
+	pending, err := database.PendingMigrations(ctx, a.db, a.schemaMigrations())
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("schema is behind: migration %d (%s) is not applied", pending[0].Version, pending[0].Name)
+	}
+
+	db := a.db.WithContext(ctx)
+	migrator := db.Migrator()
+	for _, m := range a.modules {
+		for _, model := range m.Migrations() {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return fmt.Errorf("module %s: failed to parse model %T: %w", m.Name(), model, err)
+			}
+			table := stmt.Schema.Table
+			if !migrator.HasTable(model) {
+				return fmt.Errorf("module %s: table %s is missing", m.Name(), table)
+			}
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName == "" || field.IgnoreMigration {
+					continue
+				}
+				if !migrator.HasColumn(model, field.DBName) {
+					return fmt.Errorf("module %s: column %s.%s is missing", m.Name(), table, field.DBName)
+				}
+			}
+			for _, index := range stmt.Schema.ParseIndexes() {
+				if !migrator.HasIndex(model, index.Name) {
+					return fmt.Errorf("module %s: index %s on %s is missing", m.Name(), index.Name, table)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package auditsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotemplate/internal/models"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Sink delivers batches of audit events to an external system (SIEM, log collector).
+// Send must either deliver the whole batch or return an error; the caller retries failed batches,
+// so sinks must tolerate receiving the same events more than once.
+type Sink interface {
+	Send(ctx context.Context, events []*models.AuditEvent) error
+	Close() error
+}
+
+// New creates the sink selected by name ("http" or "syslog")
+func New(name string, url string, authHeader string, syslogNetwork string, syslogAddress string) (Sink, error) {
+	switch name {
+	case "http":
+		if url == "" {
+			return nil, fmt.Errorf("audit http sink requires a URL")
+		}
+		return NewHTTPSink(url, authHeader), nil
+	case "syslog":
+		if syslogAddress == "" {
+			return nil, fmt.Errorf("audit syslog sink requires an address")
+		}
+		return NewSyslogSink(syslogNetwork, syslogAddress), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", name)
+	}
+}
+
+// httpSink POSTs each batch as a JSON array
+type httpSink struct {
+	url        string
+	authHeader string
+	client     *http.Client
+}
+
+// NewHTTPSink creates a sink POSTing JSON batches to url
+func NewHTTPSink(url string, authHeader string) Sink {
+	return &httpSink{
+		url:        url,
+		authHeader: authHeader,
+		client:     &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Send POSTs the batch and treats any non-2xx response as a failure
+func (s *httpSink) Send(ctx context.Context, events []*models.AuditEvent) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit batch: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authHeader != "" {
+		req.Header.Set("Authorization", s.authHeader)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send audit batch: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body) // Drain so the connection can be reused
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("audit collector responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close releases idle connections
+func (s *httpSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// syslogSink writes one RFC 5424 message per event
+type syslogSink struct {
+	network  string
+	address  string
+	hostname string
+	conn     net.Conn
+}
+
+// NewSyslogSink creates a sink writing RFC 5424 messages to a syslog collector over udp or tcp
+func NewSyslogSink(network string, address string) Sink {
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	return &syslogSink{network: network, address: address, hostname: hostname}
+}
+
+// Send writes every event of the batch, reconnecting on the next call after a write error
+func (s *syslogSink) Send(ctx context.Context, events []*models.AuditEvent) error {
+	if s.conn == nil {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return fmt.Errorf("failed to connect to syslog collector: %w", err)
+		}
+		s.conn = conn
+	}
+
+	for _, event := range events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return fmt.Errorf("failed to encode audit event %d: %w", event.ID, err)
+		}
+		// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID SD MSG; facility 13 (log audit), severity 6 (info)
+		msg := fmt.Sprintf("<110>1 %s %s gotemplate - %s - %s",
+			event.CreatedAt.UTC().Format(time.RFC3339Nano), s.hostname, strings.ReplaceAll(event.Action, " ", "_"), payload)
+		if s.network == "tcp" {
+			msg = fmt.Sprintf("%d %s", len(msg), msg) // Octet-counting framing (RFC 6587)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok {
+			_ = s.conn.SetWriteDeadline(deadline)
+		}
+		if _, err := s.conn.Write([]byte(msg)); err != nil {
+			s.conn.Close()
+			s.conn = nil
+			return fmt.Errorf("failed to write to syslog collector: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to the collector
+func (s *syslogSink) Close() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"encoding/json"
 	"fmt"
 	"gotemplate/config"
 	"gotemplate/pkg/logger"
@@ -10,9 +11,17 @@ import (
 	"go.uber.org/zap"
 )
 
+// Authentication methods carried in the amr claim (RFC 8176)
+const (
+	AMRPassword = "pwd"
+)
+
 // Claims defines the JWT custom claims
 type Claims struct {
-	UserID string `json:"user_id"`
+	UserID   string           `json:"user_id"`
+	Role     string           `json:"role,omitempty"`      // Role of the user at issuance time
+	AuthTime *jwt.NumericDate `json:"auth_time,omitempty"` // When the user last proved their credentials
+	AMR      []string         `json:"amr,omitempty"`       // How they proved them, AMR* constants
 	jwt.RegisteredClaims
 }
 
@@ -20,6 +29,11 @@ type Claims struct {
 type JWTManager struct {
 	secretKey     string
 	expiresInHour time.Duration
+	roleExpiresIn map[string]time.Duration // Lifetimes overriding expiresInHour per role
+	audiences     []string                 // Issued in aud, and required of validated tokens when set
+	customClaims  map[string]string        // Added to every issued token
+	issuer        string                   // Issued in iss, and required of validated tokens
+	leeway        time.Duration            // Clock skew tolerated on exp, nbf and iat
 }
 
 // NewJWTManager creates a new JWTManager instance
@@ -27,28 +41,47 @@ func NewJWTManager(cfg *config.JWTConfig) *JWTManager {
 	return &JWTManager{
 		secretKey:     cfg.SecretKey,
 		expiresInHour: cfg.ExpiresInHour,
+		roleExpiresIn: cfg.RoleExpiresIn,
+		audiences:     cfg.Audiences,
+		customClaims:  cfg.CustomClaims,
+		issuer:        cfg.Issuer,
+		leeway:        cfg.Leeway,
 	}
 }
 
-// GenerateToken generates a new JWT token for a given user ID
-func (jm *JWTManager) GenerateToken(userID string) (string, error) {
+// GenerateToken generates a new JWT token for a given user ID and role.
+// Tokens are only issued right after a password check, so auth_time is the issuance time.
+func (jm *JWTManager) GenerateToken(userID string, role string) (string, error) {
 	// Define the expiration time for the token
-	expirationTime := time.Now().Add(jm.expiresInHour) // Use configured expiration
+	lifetime := jm.expiresInHour // Use configured expiration
+	if ttl, ok := jm.roleExpiresIn[role]; ok {
+		lifetime = ttl
+	}
+	expirationTime := time.Now().Add(lifetime)
 
 	// Create the JWT claims, including the user ID and standard claims
 	claims := &Claims{
-		UserID: userID,
+		UserID:   userID,
+		Role:     role,
+		AuthTime: jwt.NewNumericDate(time.Now()),
+		AMR:      []string{AMRPassword},
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime), // Token expiration time
 			IssuedAt:  jwt.NewNumericDate(time.Now()),     // Token issuance time
 			NotBefore: jwt.NewNumericDate(time.Now()),     // Token not valid before this time
-			Issuer:    "*",                                // Token issuer
+			Issuer:    jm.issuer,                          // Token issuer
 			Subject:   userID,                             // Token subject (typically the user ID)
+			Audience:  jm.audiences,                       // Deployments the token is meant for
 		},
 	}
 
 	// Create the token with the specified signing method and claims
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := jm.withCustomClaims(claims)
+	if err != nil {
+		logger.Error("Failed to add custom claims to JWT token", zap.Error(err), zap.String("userID", userID))
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, signed)
 
 	// Sign the token with the secret key
 	tokenString, err := token.SignedString([]byte(jm.secretKey))
@@ -61,7 +94,30 @@ func (jm *JWTManager) GenerateToken(userID string) (string, error) {
 	return tokenString, nil
 }
 
-// ValidateToken validates a JWT token and returns the claims if valid
+// withCustomClaims returns the claims to sign: claims itself, or a map adding the configured custom claims.
+// Custom claims never replace the ones set here (the config rejects reserved names).
+func (jm *JWTManager) withCustomClaims(claims *Claims) (jwt.Claims, error) {
+	if len(jm.customClaims) == 0 {
+		return claims, nil
+	}
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	merged := jwt.MapClaims{}
+	if err := json.Unmarshal(encoded, &merged); err != nil {
+		return nil, err
+	}
+	for name, value := range jm.customClaims {
+		if _, ok := merged[name]; !ok {
+			merged[name] = value
+		}
+	}
+	return merged, nil
+}
+
+// ValidateToken validates a JWT token and returns the claims if valid.
+// exp is required and, like nbf and iat, checked with the configured leeway; iss must be the configured issuer.
 func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 	// Parse the token with the custom claims type and a key function
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
@@ -71,7 +127,12 @@ func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		}
 		// Return the secret key for validation
 		return []byte(jm.secretKey), nil
-	})
+	},
+		jwt.WithLeeway(jm.leeway),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithIssuer(jm.issuer),
+	)
 
 	if err != nil {
 		logger.Error("Failed to parse JWT token", zap.Error(err))
@@ -91,6 +152,24 @@ func (jm *JWTManager) ValidateToken(tokenString string) (*Claims, error) {
 		return nil, fmt.Errorf("invalid token claims")
 	}
 
+	// Tokens must be meant for this deployment, when it names itself
+	if len(jm.audiences) > 0 && !intersects(claims.Audience, jm.audiences) {
+		logger.Warn("JWT token issued for another audience", zap.Strings("audience", claims.Audience))
+		return nil, fmt.Errorf("token is not meant for this audience")
+	}
+
 	logger.Debug("JWT token validated successfully", zap.String("userID", claims.UserID))
 	return claims, nil
 }
+
+// intersects reports whether a and b share a value
+func intersects(a, b []string) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// benchmarkPassword is hashed to measure the cost of password hashing; bcrypt's time doesn't depend on it
+const benchmarkPassword = "correct horse battery staple"
+
+// BenchmarkPasswordHash hashes a sample password at cost and returns how long it took
+func BenchmarkPasswordHash(cost int) (time.Duration, error) {
+	start := time.Now()
+	if _, err := bcrypt.GenerateFromPassword([]byte(benchmarkPassword), cost); err != nil {
+		return 0, err
+	}
+	return time.Since(start), nil
+}
+
+// SuggestPasswordHashCost returns the cost nearest to cost whose hashing time, extrapolated from took at cost
+// (each step doubles it), falls between min and max. A cost already in range is returned as is.
+func SuggestPasswordHashCost(cost int, took, min, max time.Duration) int {
+	for cost > bcrypt.MinCost && took > max {
+		cost, took = cost-1, took/2
+	}
+	for cost < bcrypt.MaxCost && took < min && took*2 <= max {
+		cost, took = cost+1, took*2
+	}
+	return cost
+}
@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// PersonalTokenPrefix starts every personal access token, so they can be told apart from JWTs
+// and recognized by secret scanners
+const PersonalTokenPrefix = "gtp_"
+
+// Scopes a personal access token can be granted
+const (
+	ScopeRead  = "read"  // Safe (GET/HEAD/OPTIONS) requests
+	ScopeWrite = "write" // All other requests
+	ScopeAdmin = "admin" // Admin endpoints, for tokens of admin users only
+)
+
+// Scopes lists every personal access token scope
+var Scopes = []string{ScopeRead, ScopeWrite, ScopeAdmin}
+
+// GeneratePersonalToken returns a new random personal access token and the hash to store for it.
+// The token itself is shown to the user once and never stored.
+func GeneratePersonalToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = PersonalTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashPersonalToken(token), nil
+}
+
+// HashPersonalToken returns the stored form of a personal access token.
+// Tokens carry 256 random bits, so a fast unsalted hash is enough.
+func HashPersonalToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsPersonalToken reports whether a bearer token is a personal access token rather than a JWT
+func IsPersonalToken(token string) bool {
+	return strings.HasPrefix(token, PersonalTokenPrefix)
+}
@@ -0,0 +1,22 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+)
+
+// SetPasswordTokenPrefix starts every set-password token, so they can be told apart from other secrets
+// and recognized by secret scanners
+const SetPasswordTokenPrefix = "gtsp_"
+
+// GenerateSetPasswordToken returns a new random one-time token letting the holder choose the password of an
+// account created without one, and the hash to store for it. Like personal access tokens, it is never stored.
+func GenerateSetPasswordToken() (token string, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token = SetPasswordTokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashPersonalToken(token), nil
+}
@@ -0,0 +1,81 @@
+// Package cachecontrol computes the caching headers of responses composed of data of mixed freshness.
+// Each component of a response declares how long it may be reused and how old it already is; the response is
+// only cacheable as long as its most restrictive component.
+package cachecontrol
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Part is the freshness of one component of a response
+type Part struct {
+	MaxAge  time.Duration // How long the component may be reused once read; 0 means it must be revalidated each time
+	Age     time.Duration // How old the component already is, e.g. the time since an aggregate was computed
+	Private bool          // Specific to the caller, so shared caches such as CDNs must not store it
+	NoStore bool          // Must not be stored at all
+}
+
+// Fresh is a component that may be reused for maxAge, read just now
+func Fresh(maxAge time.Duration) Part {
+	return Part{MaxAge: maxAge}
+}
+
+// Since is a component computed at a time, which may be reused until maxAge after it
+func Since(computedAt time.Time, maxAge time.Duration) Part {
+	return Part{MaxAge: maxAge, Age: max(0, time.Since(computedAt))}
+}
+
+// ForCaller marks a component as specific to the caller, e.g. read with the caller's permissions
+func (p Part) ForCaller() Part {
+	p.Private = true
+	return p
+}
+
+// Combine returns the freshness of a response made of parts: private or not stored if any part is, as old as
+// its oldest part, and fresh only as long as every part still is. No parts makes a response that must be
+// revalidated each time.
+func Combine(parts ...Part) Part {
+	if len(parts) == 0 {
+		return Part{}
+	}
+	var res Part
+	remaining := time.Duration(-1)
+	for _, p := range parts {
+		res.Private = res.Private || p.Private
+		res.NoStore = res.NoStore || p.NoStore
+		res.Age = max(res.Age, p.Age)
+		if left := max(0, p.MaxAge-p.Age); remaining < 0 || left < remaining {
+			remaining = left
+		}
+	}
+	res.MaxAge = res.Age + remaining // Caches count max-age from the Age sent
+	return res
+}
+
+// Set writes the Cache-Control and Age headers of a response made of parts, see Combine
+func Set(h http.Header, parts ...Part) {
+	p := Combine(parts...)
+	age, remaining := int64(p.Age/time.Second), int64((p.MaxAge-p.Age)/time.Second) // Rounded down, never longer
+	switch {
+	case p.NoStore:
+		h.Set("Cache-Control", "no-store")
+		return
+	case remaining <= 0:
+		h.Set("Cache-Control", scope(p)+", no-cache")
+	default:
+		h.Set("Cache-Control", scope(p)+", max-age="+strconv.FormatInt(age+remaining, 10))
+	}
+	if age > 0 {
+		h.Set("Age", strconv.FormatInt(age, 10))
+	}
+}
+
+// scope is the directive telling which caches may store a response
+func scope(p Part) string {
+	if p.Private {
+		return "private"
+	}
+	return "public"
+}
@@ -0,0 +1,124 @@
+package clientip
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// ParseTrusted parses the addresses and CIDR ranges of trusted proxies, e.g. "10.0.0.0/8" or "192.0.2.1"
+func ParseTrusted(proxies []string) ([]*net.IPNet, error) {
+	nets := make([]*net.IPNet, 0, len(proxies))
+	for _, p := range proxies {
+		p = strings.TrimSpace(p)
+		if !strings.Contains(p, "/") {
+			ip := net.ParseIP(p)
+			if ip == nil {
+				return nil, fmt.Errorf("%q is not an IP address or CIDR range", p)
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip, bits = ip4, 8*net.IPv4len
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, n, err := net.ParseCIDR(p)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an IP address or CIDR range", p)
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// Resolve returns the IP of the client that made a request. Forwarding headers are only believed when the
+// connection comes from a trusted proxy; the chain is then walked back, from the nearest hop, until an address
+// that isn't a trusted proxy. The RFC 7239 Forwarded header is used if present, X-Forwarded-For otherwise.
+func Resolve(r *http.Request, trusted []*net.IPNet) string {
+	ip := parseNode(r.RemoteAddr)
+	if ip == nil {
+		return r.RemoteAddr
+	}
+	if !isTrusted(ip, trusted) {
+		return ip.String()
+	}
+
+	hops := forwardedFor(r.Header.Values("Forwarded"))
+	if len(hops) == 0 {
+		hops = forwardedList(r.Header.Values("X-Forwarded-For"))
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := parseNode(hops[i])
+		if hop == nil {
+			break // "unknown" or an obfuscated node: nothing reliable beyond the last trusted proxy
+		}
+		ip = hop
+		if !isTrusted(ip, trusted) {
+			break
+		}
+	}
+	return ip.String()
+}
+
+// isTrusted reports whether ip belongs to a trusted proxy
+func isTrusted(ip net.IP, trusted []*net.IPNet) bool {
+	for _, n := range trusted {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardedFor returns the for= nodes of RFC 7239 Forwarded headers, nearest to the client first
+func forwardedFor(headers []string) []string {
+	var nodes []string
+	for _, element := range forwardedList(headers) {
+		for _, pair := range strings.Split(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				nodes = append(nodes, strings.Trim(value, `"`))
+			}
+		}
+	}
+	return nodes
+}
+
+// forwardedList splits comma-separated header values, which may be spread over several header lines
+func forwardedList(headers []string) []string {
+	var items []string
+	for _, h := range headers {
+		for _, item := range strings.Split(h, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+// parseNode parses an address with an optional port: "192.0.2.1", "192.0.2.1:4711", "[2001:db8::1]:4711"
+// or "2001:db8::1". It returns nil for anything else, such as "unknown" or "_hidden".
+func parseNode(node string) net.IP {
+	if host, _, err := net.SplitHostPort(node); err == nil {
+		node = host
+	}
+	return net.ParseIP(strings.Trim(node, "[]"))
+}
+
+// contextKey is unexported to prevent collisions with context keys from other packages
+type contextKey struct{}
+
+// WithIP returns a copy of ctx carrying the client IP of the request
+func WithIP(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, contextKey{}, ip)
+}
+
+// FromContext returns the client IP stored in ctx, or "" outside of a request
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(contextKey{}).(string)
+	return ip
+}
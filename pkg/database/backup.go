@@ -0,0 +1,260 @@
+package database
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"gotemplate/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// backupManifestFile is the file in a backup directory describing its contents
+const backupManifestFile = "manifest.json"
+
+// backupPartialSuffix marks a backup directory that is still being written
+const backupPartialSuffix = ".partial"
+
+// staleBackupAge is how old a partial backup must be before pruning treats it as abandoned rather than in progress
+const staleBackupAge = 24 * time.Hour
+
+// backupNameFormat names backups by their UTC start time, so names sort chronologically
+const backupNameFormat = "20060102T150405Z"
+
+// BackupManifest describes a logical backup: one gzip-compressed COPY text file per table of the current
+// schema, all taken from the same snapshot. A table is restored with COPY <table> FROM STDIN on the
+// decompressed file, after the schema was created by starting the application once. Partitioned tables are
+// dumped as a whole; restoring them routes each row to its partition.
+type BackupManifest struct {
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Duration  string         `json:"duration"`
+	Tables    []*BackupTable `json:"tables"`
+	Bytes     int64          `json:"bytes"` // Compressed size of all table files
+}
+
+// BackupTable is the dump of one table
+type BackupTable struct {
+	Name   string `json:"name"`
+	File   string `json:"file"`
+	Rows   int64  `json:"rows"`
+	Bytes  int64  `json:"bytes"`
+	SHA256 string `json:"sha256"` // Checksum of the compressed file
+}
+
+// Backup dumps every table of the current schema into a new directory under root and returns its manifest.
+// All tables are read in one REPEATABLE READ transaction, so the backup is consistent without blocking writers.
+// The directory only gets its final name once complete; an interrupted backup leaves a .partial directory behind
+// for PruneBackups to clean up.
+func Backup(ctx context.Context, db *gorm.DB, root string) (*BackupManifest, error) {
+	started := time.Now().UTC()
+	manifest := &BackupManifest{Name: started.Format(backupNameFormat), CreatedAt: started}
+	final := filepath.Join(root, manifest.Name)
+	partial := final + backupPartialSuffix
+	if err := os.MkdirAll(partial, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a database connection: %w", err)
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn any) error {
+		pg := driverConn.(*stdlib.Conn).Conn().PgConn()
+		if err := pg.Exec(ctx, `BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY`).Close(); err != nil {
+			return fmt.Errorf("failed to start backup transaction: %w", err)
+		}
+		defer pg.Exec(context.Background(), `ROLLBACK`).Close()
+
+		// Partitioned tables are dumped as a whole through their parent, not partition by partition
+		results, err := pg.Exec(ctx, `SELECT c.relname, c.relkind FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND NOT c.relispartition ORDER BY c.relname`).ReadAll()
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		for _, row := range results[0].Rows {
+			table := &BackupTable{Name: string(row[0]), File: string(row[0]) + ".copy.gz"}
+			source := pgx.Identifier{table.Name}.Sanitize()
+			if string(row[1]) == "p" {
+				source = `(SELECT * FROM ` + source + `)` // COPY can't read a partitioned table directly
+			}
+			f, err := os.OpenFile(filepath.Join(partial, table.File), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+			if err != nil {
+				return err
+			}
+			sum := sha256.New()
+			counter := &countingWriter{w: io.MultiWriter(f, sum)}
+			zw := gzip.NewWriter(counter)
+			tag, copyErr := pg.CopyTo(ctx, zw, `COPY `+source+` TO STDOUT`)
+			if err := firstError(copyErr, zw.Close(), f.Sync(), f.Close()); err != nil {
+				return fmt.Errorf("failed to dump table %s: %w", table.Name, err)
+			}
+			table.Rows, table.Bytes, table.SHA256 = tag.RowsAffected(), counter.n, hex.EncodeToString(sum.Sum(nil))
+			manifest.Tables = append(manifest.Tables, table)
+			manifest.Bytes += table.Bytes
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	manifest.Duration = time.Since(started).Round(time.Millisecond).String()
+	encoded, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(partial, backupManifestFile), encoded, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to write backup manifest: %w", err)
+	}
+	if err := os.Rename(partial, final); err != nil {
+		return nil, fmt.Errorf("failed to finish backup: %w", err)
+	}
+
+	logger.Info("Database backup written", zap.String("backup", manifest.Name), zap.Int("tables", len(manifest.Tables)), zap.Int64("bytes", manifest.Bytes))
+	return manifest, nil
+}
+
+// VerifyBackup checks every table file of a backup against the size and checksum in its manifest
+func VerifyBackup(root, name string) (*BackupManifest, error) {
+	manifest, err := readBackupManifest(root, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range manifest.Tables {
+		f, err := os.Open(filepath.Join(root, name, table.File))
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+		sum := sha256.New()
+		n, err := io.Copy(sum, f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("table %s: %w", table.Name, err)
+		}
+		if n != table.Bytes || hex.EncodeToString(sum.Sum(nil)) != table.SHA256 {
+			return nil, fmt.Errorf("table %s: file does not match its checksum", table.Name)
+		}
+	}
+	return manifest, nil
+}
+
+// ListBackups returns the manifests of the complete backups under root, newest first
+func ListBackups(root string) ([]*BackupManifest, error) {
+	entries, err := os.ReadDir(root)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifests []*BackupManifest
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasSuffix(entry.Name(), backupPartialSuffix) {
+			continue
+		}
+		manifest, err := readBackupManifest(root, entry.Name())
+		if err != nil {
+			logger.Warn("Skipping unreadable backup", zap.String("backup", entry.Name()), zap.Error(err))
+			continue
+		}
+		manifests = append(manifests, manifest)
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Name > manifests[j].Name })
+	return manifests, nil
+}
+
+// PruneBackups deletes all but the keep newest complete backups, and partial backups abandoned for longer than
+// staleBackupAge, and returns the names of the deleted backups
+func PruneBackups(root string, keep int) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	var complete, pruned []string
+	for _, entry := range entries {
+		switch {
+		case !entry.IsDir():
+		case strings.HasSuffix(entry.Name(), backupPartialSuffix):
+			if info, err := entry.Info(); err == nil && time.Since(info.ModTime()) > staleBackupAge {
+				pruned = append(pruned, entry.Name())
+			}
+		default:
+			if _, err := readBackupManifest(root, entry.Name()); err == nil {
+				complete = append(complete, entry.Name())
+			}
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(complete)))
+	if len(complete) > keep {
+		pruned = append(pruned, complete[keep:]...)
+	}
+
+	for i, name := range pruned {
+		if err := os.RemoveAll(filepath.Join(root, name)); err != nil {
+			return pruned[:i], fmt.Errorf("failed to delete backup %s: %w", name, err)
+		}
+	}
+	if len(pruned) > 0 {
+		logger.Info("Old backups deleted", zap.Strings("backups", pruned))
+	}
+	return pruned, nil
+}
+
+// readBackupManifest reads the manifest of a complete backup
+func readBackupManifest(root, name string) (*BackupManifest, error) {
+	if name == "" || name != filepath.Base(name) {
+		return nil, fmt.Errorf("invalid backup name %q: %w", name, os.ErrNotExist)
+	}
+	data, err := os.ReadFile(filepath.Join(root, name, backupManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	manifest := &BackupManifest{}
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("invalid backup manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// firstError returns the first non-nil error
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,355 @@
+package database
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"gotemplate/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"gorm.io/gorm"
+)
+
+// CloneRewrite replaces a column value while it is copied. NULLs are copied as is.
+type CloneRewrite func(value string) string
+
+// ClonePolicy is how Clone copies one table
+type ClonePolicy struct {
+	Skip    bool                    // Not copied: the target table is left empty
+	Rewrite map[string]CloneRewrite // Rewritten columns by name; other columns are copied as is
+}
+
+// CloneTable is the outcome of copying one table
+type CloneTable struct {
+	Name    string `json:"name"`
+	Rows    int64  `json:"rows"`
+	Skipped bool   `json:"skipped,omitempty"`
+}
+
+// Clone replaces the contents of every table of target with the rows of source, streaming each table with COPY
+// and rewriting columns on the way, so rewritten values never reach the target. Every table of the target must
+// have a policy, so a table added later is not copied until someone decided how. The target schema must exist,
+// e.g. by starting the application once against it, and schema_migrations is left alone.
+//
+// Source tables are read in one REPEATABLE READ transaction, and the target is written in one transaction:
+// either the whole clone is visible in the target, or nothing changed. Parents are copied before the tables
+// referencing them, and sequences continue after the copied IDs.
+func Clone(ctx context.Context, source, target *gorm.DB, policies map[string]ClonePolicy) ([]*CloneTable, error) {
+	started := time.Now()
+	sourceConn, err := rawConn(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	defer sourceConn.Close()
+	targetConn, err := rawConn(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	defer targetConn.Close()
+
+	var tables []*CloneTable
+	err = sourceConn.Raw(func(sourceDriverConn any) error {
+		return targetConn.Raw(func(targetDriverConn any) error {
+			src := sourceDriverConn.(*stdlib.Conn).Conn().PgConn()
+			dst := targetDriverConn.(*stdlib.Conn).Conn().PgConn()
+			if err := src.Exec(ctx, `BEGIN ISOLATION LEVEL REPEATABLE READ READ ONLY`).Close(); err != nil {
+				return fmt.Errorf("failed to start source transaction: %w", err)
+			}
+			defer src.Exec(context.Background(), `ROLLBACK`).Close()
+			if err := dst.Exec(ctx, `BEGIN`).Close(); err != nil {
+				return fmt.Errorf("failed to start target transaction: %w", err)
+			}
+			defer dst.Exec(context.Background(), `ROLLBACK`).Close() // No-op once committed
+
+			tables, err = cloneTables(ctx, src, dst, policies)
+			if err != nil {
+				return err
+			}
+			if err := dst.Exec(ctx, `COMMIT`).Close(); err != nil {
+				return fmt.Errorf("failed to commit target transaction: %w", err)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Database cloned", zap.Int("tables", len(tables)), zap.Duration("duration", time.Since(started)))
+	return tables, nil
+}
+
+// cloneTables empties the target tables and copies every table not skipped, within the open transactions
+func cloneTables(ctx context.Context, src, dst *pgconn.PgConn, policies map[string]ClonePolicy) ([]*CloneTable, error) {
+	names, err := cloneOrder(ctx, dst)
+	if err != nil {
+		return nil, err
+	}
+	var missing []string
+	for _, name := range names {
+		if _, ok := policies[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("no clone policy for tables %s", strings.Join(missing, ", "))
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = pgx.Identifier{name}.Sanitize()
+	}
+	if err := dst.Exec(ctx, `TRUNCATE `+strings.Join(quoted, ", ")).Close(); err != nil {
+		return nil, fmt.Errorf("failed to empty target tables: %w", err)
+	}
+
+	tables := make([]*CloneTable, 0, len(names))
+	for _, name := range names {
+		table := &CloneTable{Name: name, Skipped: policies[name].Skip}
+		if !table.Skipped {
+			if table.Rows, err = cloneTable(ctx, src, dst, name, policies[name]); err != nil {
+				return nil, fmt.Errorf("failed to clone table %s: %w", name, err)
+			}
+			logger.Info("Table cloned", zap.String("table", name), zap.Int64("rows", table.Rows))
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// cloneOrder lists the tables of the target schema except schema_migrations, parents before the tables
+// referencing them. Partitions are left out: rows are routed to them through their parent.
+func cloneOrder(ctx context.Context, dst *pgconn.PgConn) ([]string, error) {
+	results, err := dst.Exec(ctx, `SELECT c.relname FROM pg_class c JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relkind IN ('r', 'p') AND NOT c.relispartition AND c.relname <> 'schema_migrations'
+		ORDER BY c.relname;
+		SELECT DISTINCT c.relname, p.relname FROM pg_constraint f
+		JOIN pg_class c ON c.oid = f.conrelid JOIN pg_class p ON p.oid = f.confrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE f.contype = 'f' AND n.nspname = current_schema() AND f.conrelid <> f.confrelid`).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	parents := map[string][]string{}
+	for _, row := range results[1].Rows {
+		parents[string(row[0])] = append(parents[string(row[0])], string(row[1]))
+	}
+	var order []string
+	state := map[string]int{} // 1 while visiting, 2 once ordered
+	var visit func(name string) error
+	visit = func(name string) error {
+		switch state[name] {
+		case 1:
+			return fmt.Errorf("foreign keys of table %s form a cycle", name)
+		case 2:
+			return nil
+		}
+		state[name] = 1
+		sort.Strings(parents[name])
+		for _, parent := range parents[name] {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[name] = 2
+		order = append(order, name)
+		return nil
+	}
+	for _, row := range results[0].Rows {
+		if err := visit(string(row[0])); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+// cloneTable streams one table from source to target, rewriting columns on the way, and moves the target's
+// sequences past the copied values. Columns are those of the target, except generated ones.
+func cloneTable(ctx context.Context, src, dst *pgconn.PgConn, name string, policy ClonePolicy) (int64, error) {
+	results, err := dst.Exec(ctx, `SELECT a.attname, pg_get_serial_sequence(quote_ident(c.relname), a.attname)
+		FROM pg_attribute a JOIN pg_class c ON c.oid = a.attrelid JOIN pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = current_schema() AND c.relname = '`+strings.ReplaceAll(name, "'", "''")+`'
+		AND a.attnum > 0 AND NOT a.attisdropped AND a.attgenerated = '' ORDER BY a.attnum`).ReadAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list columns: %w", err)
+	}
+	var columns, sequences []string
+	rewrites := map[int]CloneRewrite{}
+	for i, row := range results[0].Rows {
+		column := string(row[0])
+		columns = append(columns, pgx.Identifier{column}.Sanitize())
+		if row[1] != nil {
+			sequences = append(sequences, `SELECT setval('`+strings.ReplaceAll(string(row[1]), "'", "''")+`', (SELECT COALESCE(MAX(`+
+				pgx.Identifier{column}.Sanitize()+`), 0) + 1 FROM `+pgx.Identifier{name}.Sanitize()+`), false)`)
+		}
+		if rewrite, ok := policy.Rewrite[column]; ok {
+			rewrites[i] = rewrite
+		}
+	}
+	for column := range policy.Rewrite {
+		if !containsColumn(results[0].Rows, column) {
+			return 0, fmt.Errorf("rewritten column %s does not exist", column)
+		}
+	}
+	list := strings.Join(columns, ", ")
+
+	// The source is written into the pipe while the target reads from it; whichever fails first stops the other
+	pr, pw := io.Pipe()
+	var rows int64
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error {
+		w := &copyRewriter{w: pw, rewrites: rewrites}
+		_, err := src.CopyTo(gctx, w, `COPY (SELECT `+list+` FROM `+pgx.Identifier{name}.Sanitize()+`) TO STDOUT`)
+		if err == nil {
+			err = w.Close()
+		}
+		pw.CloseWithError(err)
+		return err
+	})
+	g.Go(func() error {
+		tag, err := dst.CopyFrom(gctx, pr, `COPY `+pgx.Identifier{name}.Sanitize()+` (`+list+`) FROM STDIN`)
+		pr.CloseWithError(err)
+		rows = tag.RowsAffected()
+		return err
+	})
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+
+	if len(sequences) > 0 {
+		if err := dst.Exec(ctx, strings.Join(sequences, "; ")).Close(); err != nil {
+			return 0, fmt.Errorf("failed to reset sequences: %w", err)
+		}
+	}
+	return rows, nil
+}
+
+// containsColumn reports whether a column is among the listed ones
+func containsColumn(rows [][][]byte, column string) bool {
+	for _, row := range rows {
+		if string(row[0]) == column {
+			return true
+		}
+	}
+	return false
+}
+
+// copyRewriter rewrites columns of COPY text format rows written through it. Rows end with a newline and
+// columns are separated by tabs; both are escaped inside values, so neither splits a value.
+type copyRewriter struct {
+	w        io.Writer
+	rewrites map[int]CloneRewrite // By column index
+	buf      []byte               // Incomplete row carried over to the next write
+}
+
+func (r *copyRewriter) Write(p []byte) (int, error) {
+	r.buf = append(r.buf, p...)
+	start := 0
+	for {
+		end := bytes.IndexByte(r.buf[start:], '\n')
+		if end < 0 {
+			break
+		}
+		if err := r.writeRow(r.buf[start : start+end+1]); err != nil {
+			return 0, err
+		}
+		start += end + 1
+	}
+	r.buf = r.buf[:copy(r.buf, r.buf[start:])]
+	return len(p), nil
+}
+
+// Close fails if the stream ended midway through a row
+func (r *copyRewriter) Close() error {
+	if len(r.buf) > 0 {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// writeRow writes one row, newline included, rewriting its columns
+func (r *copyRewriter) writeRow(row []byte) error {
+	if len(r.rewrites) == 0 {
+		_, err := r.w.Write(row)
+		return err
+	}
+	fields := bytes.Split(row[:len(row)-1], []byte{'\t'})
+	for i, rewrite := range r.rewrites {
+		if i >= len(fields) {
+			return fmt.Errorf("row has %d columns, expected more than %d", len(fields), i)
+		}
+		if string(fields[i]) != `\N` {
+			fields[i] = encodeCopyText(rewrite(decodeCopyText(fields[i])))
+		}
+	}
+	out := append(bytes.Join(fields, []byte{'\t'}), '\n')
+	_, err := r.w.Write(out)
+	return err
+}
+
+// decodeCopyText unescapes a COPY text format value
+func decodeCopyText(field []byte) string {
+	if bytes.IndexByte(field, '\\') < 0 {
+		return string(field)
+	}
+	var sb strings.Builder
+	for i := 0; i < len(field); i++ {
+		c := field[i]
+		if c != '\\' || i+1 == len(field) {
+			sb.WriteByte(c)
+			continue
+		}
+		i++
+		switch field[i] {
+		case 'b':
+			sb.WriteByte('\b')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case 'v':
+			sb.WriteByte('\v')
+		default:
+			sb.WriteByte(field[i]) // Backslash itself; COPY TO writes no other escapes
+		}
+	}
+	return sb.String()
+}
+
+// copyTextEscaper escapes the characters that would end a COPY text format value early
+var copyTextEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// encodeCopyText escapes a value for the COPY text format
+func encodeCopyText(value string) []byte {
+	return []byte(copyTextEscaper.Replace(value))
+}
+
+// rawConn reserves a connection of db, for driver-level operations such as COPY
+func rawConn(ctx context.Context, db *gorm.DB) (*sql.Conn, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get a database connection: %w", err)
+	}
+	return conn, nil
+}
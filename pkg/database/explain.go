@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// explainStartKey is the statement instance key holding a query's start time
+const explainStartKey = "database:explain:start"
+
+// explainTimeout bounds the EXPLAIN of one slow query
+const explainTimeout = 2 * time.Second
+
+// EnableExplain logs the plan of every query slower than threshold, to spot missing indexes during development.
+// The plan comes from EXPLAIN without ANALYZE, so the query is not run again. Meant for debug mode only:
+// every slow query costs an extra round trip.
+func EnableExplain(db *gorm.DB, threshold time.Duration) error {
+	cb := db.Callback()
+	start := func(tx *gorm.DB) { tx.InstanceSet(explainStartKey, time.Now()) }
+	explain := func(tx *gorm.DB) { explainSlowQuery(tx, threshold) }
+	hooks := []error{
+		cb.Query().Before("gorm:query").Register("database:explain_start", start),
+		cb.Query().After("gorm:query").Register("database:explain", explain),
+		cb.Row().Before("gorm:row").Register("database:explain_start", start),
+		cb.Row().After("gorm:row").Register("database:explain", explain),
+		cb.Raw().Before("gorm:raw").Register("database:explain_start", start),
+		cb.Raw().After("gorm:raw").Register("database:explain", explain),
+	}
+	if err := errors.Join(hooks...); err != nil {
+		return err
+	}
+	logger.Info("Slow query plans will be logged", zap.Duration("threshold", threshold))
+	return nil
+}
+
+// explainSlowQuery logs the plan of the statement if it took longer than threshold
+func explainSlowQuery(tx *gorm.DB, threshold time.Duration) {
+	v, ok := tx.InstanceGet(explainStartKey)
+	if !ok || tx.Error != nil {
+		return
+	}
+	elapsed := time.Since(v.(time.Time))
+	query := strings.TrimSpace(tx.Statement.SQL.String())
+	if elapsed < threshold || query == "" || strings.HasPrefix(strings.ToUpper(query), "EXPLAIN") {
+		return
+	}
+
+	sqlDB, err := tx.DB()
+	if err != nil {
+		return
+	}
+	// Use the pool directly: the EXPLAIN must not pass through these callbacks again
+	ctx, cancel := context.WithTimeout(context.Background(), explainTimeout)
+	defer cancel()
+	rows, err := sqlDB.QueryContext(ctx, "EXPLAIN (ANALYZE false) "+query, tx.Statement.Vars...)
+	if err != nil {
+		logger.Debug("Failed to explain slow query", zap.Error(err), zap.String("sql", query))
+		return
+	}
+	defer rows.Close()
+
+	var plan []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return
+		}
+		plan = append(plan, line)
+	}
+	logger.Warn("Slow query",
+		zap.Duration("duration", elapsed),
+		zap.Duration("threshold", threshold),
+		zap.String("sql", query),
+		zap.String("plan", strings.Join(plan, "\n")))
+}
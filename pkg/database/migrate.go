@@ -0,0 +1,253 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gotemplate/pkg/leader"
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrationLockID is the Postgres advisory lock held while migrations run, so instances starting
+// together during a rolling deploy don't apply the same migration twice. It predates leader.Key and is kept so
+// instances of older releases still exclude each other.
+const migrationLockID = 0x676f74656d706c // "gotempl"
+
+// Migration is a versioned schema change applied once, after AutoMigrate created the tables.
+// It covers what AutoMigrate can't express or doesn't reliably maintain: partial and expression
+// indexes, replacing constraints, data fixes.
+type Migration struct {
+	Version    int64    // Unique across all modules; migrations run in ascending order. Use the date: 2026101601
+	Name       string   // Short description, recorded in schema_migrations
+	Statements []string // Run one by one
+	// NoTransaction runs the statements outside a transaction, which CREATE INDEX CONCURRENTLY requires.
+	// Such statements must be idempotent (IF NOT EXISTS), since a failure midway is retried from the start.
+	NoTransaction bool
+	// AllowIncompatible states why statements LintMigrations flags are safe in this migration, e.g. that the
+	// dropped column has been unused since the previous release. Without it such a migration is refused.
+	AllowIncompatible string
+}
+
+// Checksum identifies the statements of a migration, to tell whether an applied migration was edited since
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(strings.Join(m.Statements, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
+
+// schemaMigrationsTable records the applied migrations
+const schemaMigrationsTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version BIGINT PRIMARY KEY,
+	name TEXT NOT NULL,
+	applied_at TIMESTAMPTZ NOT NULL
+)`
+
+// schemaMigrationsChecksum adds the checksums of applied migrations to tables created before they were
+// recorded; migrations applied before have none
+const schemaMigrationsChecksum = `ALTER TABLE schema_migrations ADD COLUMN IF NOT EXISTS checksum TEXT`
+
+// States of a migration in a MigrationReport
+const (
+	MigrationApplied = "applied"
+	MigrationPending = "pending"
+	MigrationChanged = "changed" // Applied, but its statements have changed since
+	MigrationUnknown = "unknown" // Recorded as applied but not known to this release, e.g. applied by a newer one
+)
+
+// MigrationStatus is the state of one migration in the database
+type MigrationStatus struct {
+	Version         int64      `json:"version"`
+	Name            string     `json:"name"`
+	State           string     `json:"state"`                     // One of the Migration* states
+	Checksum        string     `json:"checksum,omitempty"`        // Of the statements of this release
+	AppliedChecksum string     `json:"appliedChecksum,omitempty"` // Of the statements applied; empty if applied before checksums were recorded
+	AppliedAt       *time.Time `json:"appliedAt,omitempty"`
+}
+
+// MigrationReport is the state of the schema against the migrations of a release
+type MigrationReport struct {
+	Applied    int                `json:"applied"`
+	Pending    int                `json:"pending"`
+	Changed    int                `json:"changed"`
+	Unknown    int                `json:"unknown"`
+	Migrations []*MigrationStatus `json:"migrations"` // In version order
+}
+
+// Migrate auto-migrates models, then applies the migrations that haven't been applied yet, in version order.
+// Both run under the migration lock. Nothing is applied if any migration has backwards-incompatible statements;
+// see LintMigrations.
+func Migrate(ctx context.Context, db *gorm.DB, models []interface{}, migrations []Migration) error {
+	pending, err := sortMigrations(migrations)
+	if err != nil {
+		return err
+	}
+	if issues := LintMigrations(pending); len(issues) > 0 {
+		for _, issue := range issues {
+			logger.Error("Backwards-incompatible schema migration", zap.Int64("version", issue.Version), zap.String("name", issue.Name),
+				zap.String("problem", issue.Problem), zap.String("statement", issue.Statement))
+		}
+		return fmt.Errorf("%d backwards-incompatible statement(s) in schema migrations, first: %s", len(issues), issues[0])
+	}
+
+	return leader.WithLock(ctx, db, migrationLockID, func(conn *gorm.DB) error {
+		if err := AutoMigrate(conn, models...); err != nil {
+			return err
+		}
+		if err := conn.Exec(schemaMigrationsTable).Error; err != nil {
+			return fmt.Errorf("failed to create schema_migrations table: %w", err)
+		}
+		if err := conn.Exec(schemaMigrationsChecksum).Error; err != nil {
+			return fmt.Errorf("failed to add checksums to schema_migrations table: %w", err)
+		}
+		applied, err := appliedVersions(conn)
+		if err != nil {
+			return err
+		}
+
+		count := 0
+		for _, m := range pending {
+			if applied[m.Version] {
+				continue
+			}
+			if err := applyMigration(conn, m); err != nil {
+				logger.Error("Schema migration failed", zap.Error(err), zap.Int64("version", m.Version), zap.String("name", m.Name))
+				return fmt.Errorf("migration %d (%s) failed: %w", m.Version, m.Name, err)
+			}
+			logger.Info("Schema migration applied", zap.Int64("version", m.Version), zap.String("name", m.Name))
+			count++
+		}
+		logger.Info("Schema migrations completed successfully", zap.Int("applied", count), zap.Int("known", len(pending)))
+		return nil
+	})
+}
+
+// PendingMigrations returns the migrations not recorded as applied, e.g. for a startup check
+func PendingMigrations(ctx context.Context, db *gorm.DB, migrations []Migration) ([]Migration, error) {
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	if !db.WithContext(ctx).Migrator().HasTable("schema_migrations") {
+		return sorted, nil
+	}
+	applied, err := appliedVersions(db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, m := range sorted {
+		if !applied[m.Version] {
+			pending = append(pending, m)
+		}
+	}
+	return pending, nil
+}
+
+// GetMigrationReport compares the migrations recorded in the database with migrations, the migrations of this
+// release
+func GetMigrationReport(ctx context.Context, db *gorm.DB, migrations []Migration) (*MigrationReport, error) {
+	sorted, err := sortMigrations(migrations)
+	if err != nil {
+		return nil, err
+	}
+	var recorded []struct {
+		Version   int64
+		Name      string
+		Checksum  *string
+		AppliedAt time.Time
+	}
+	if db.WithContext(ctx).Migrator().HasTable("schema_migrations") {
+		if err := db.WithContext(ctx).Raw(`SELECT version, name, checksum, applied_at FROM schema_migrations`).Scan(&recorded).Error; err != nil {
+			return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+		}
+	}
+
+	statuses := map[int64]*MigrationStatus{}
+	for _, m := range sorted {
+		statuses[m.Version] = &MigrationStatus{Version: m.Version, Name: m.Name, State: MigrationPending, Checksum: m.Checksum()}
+	}
+	for _, r := range recorded {
+		status, known := statuses[r.Version]
+		if !known {
+			status = &MigrationStatus{Version: r.Version, Name: r.Name, State: MigrationUnknown}
+			statuses[r.Version] = status
+		}
+		appliedAt := r.AppliedAt
+		status.AppliedAt = &appliedAt
+		if r.Checksum != nil {
+			status.AppliedChecksum = *r.Checksum
+		}
+		switch {
+		case !known:
+		case status.AppliedChecksum != "" && status.AppliedChecksum != status.Checksum:
+			status.State = MigrationChanged
+		default:
+			status.State = MigrationApplied
+		}
+	}
+
+	report := &MigrationReport{Migrations: make([]*MigrationStatus, 0, len(statuses))}
+	for _, status := range statuses {
+		report.Migrations = append(report.Migrations, status)
+		switch status.State {
+		case MigrationApplied:
+			report.Applied++
+		case MigrationPending:
+			report.Pending++
+		case MigrationChanged:
+			report.Changed++
+		default:
+			report.Unknown++
+		}
+	}
+	sort.Slice(report.Migrations, func(i, j int) bool { return report.Migrations[i].Version < report.Migrations[j].Version })
+	return report, nil
+}
+
+// sortMigrations returns the migrations in version order, rejecting duplicate versions
+func sortMigrations(migrations []Migration) ([]Migration, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i := 1; i < len(sorted); i++ {
+		if sorted[i].Version == sorted[i-1].Version {
+			return nil, fmt.Errorf("migrations %q and %q share version %d", sorted[i-1].Name, sorted[i].Name, sorted[i].Version)
+		}
+	}
+	return sorted, nil
+}
+
+// appliedVersions reads the versions recorded in schema_migrations
+func appliedVersions(db *gorm.DB) (map[int64]bool, error) {
+	var versions []int64
+	if err := db.Raw(`SELECT version FROM schema_migrations`).Scan(&versions).Error; err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	applied := make(map[int64]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// applyMigration runs the statements of a migration and records it
+func applyMigration(conn *gorm.DB, m Migration) error {
+	run := func(tx *gorm.DB) error {
+		for _, stmt := range m.Statements {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`, m.Version, m.Name, m.Checksum(), time.Now()).Error
+	}
+	if m.NoTransaction {
+		return run(conn)
+	}
+	return conn.Transaction(run)
+}
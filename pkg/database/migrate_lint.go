@@ -0,0 +1,112 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// During a rolling deploy the previous release keeps serving traffic on the migrated schema until the
+// last old instance is gone, so a migration must not break it: a column is dropped or renamed one release
+// after the code stopped using it, and indexes on existing tables are built CONCURRENTLY so writes aren't
+// blocked while they build. LintMigrations flags statements that break these rules.
+
+// MigrationIssue is a backwards-incompatible statement found by LintMigrations
+type MigrationIssue struct {
+	Version   int64
+	Name      string
+	Statement string
+	Problem   string
+}
+
+func (i MigrationIssue) String() string {
+	return fmt.Sprintf("migration %d (%s): %s: %s", i.Version, i.Name, i.Problem, i.Statement)
+}
+
+var (
+	sqlComment       = regexp.MustCompile(`--[^\n]*`)
+	sqlSpace         = regexp.MustCompile(`\s+`)
+	createTable      = regexp.MustCompile(`^CREATE (?:UNLOGGED )?(?:TABLE|MATERIALIZED VIEW) (?:IF NOT EXISTS )?("?[\w.]+"?)`)
+	createIndex      = regexp.MustCompile(`^CREATE (?:UNIQUE )?INDEX `)
+	indexTable       = regexp.MustCompile(` ON (?:ONLY )?("?[\w.]+"?)`)
+	dropIndex        = regexp.MustCompile(`^DROP INDEX `)
+	dropTable        = regexp.MustCompile(`^DROP TABLE `)
+	dropColumn       = regexp.MustCompile(`\bDROP (?:COLUMN )?(?:IF EXISTS )?"?(\w+)`)
+	renameColumn     = regexp.MustCompile(`^ALTER TABLE .*\bRENAME\b`)
+	changeColumnType = regexp.MustCompile(`^ALTER TABLE .*\bALTER (?:COLUMN )?"?\w+"? (?:SET DATA )?TYPE\b`)
+	setNotNull       = regexp.MustCompile(`^ALTER TABLE .*\bSET NOT NULL\b`)
+	addNotNull       = regexp.MustCompile(`^ALTER TABLE .*\bADD (?:COLUMN )?(?:IF NOT EXISTS )?"?\w+"? [^,]*\bNOT NULL\b`)
+	hasDefault       = regexp.MustCompile(`\bDEFAULT\b`)
+)
+
+// LintMigrations returns the statements of the migrations that would break the release still serving
+// traffic during a rolling deploy. Migrations with AllowIncompatible set are skipped.
+func LintMigrations(migrations []Migration) []MigrationIssue {
+	var issues []MigrationIssue
+	for _, m := range migrations {
+		if m.AllowIncompatible != "" {
+			continue
+		}
+		// Tables and materialized views created by the migration itself aren't used by the previous release yet
+		created := map[string]bool{}
+		for _, stmt := range m.Statements {
+			if match := createTable.FindStringSubmatch(normalizeSQL(stmt)); match != nil {
+				created[strings.Trim(match[1], `"`)] = true
+			}
+		}
+		for _, stmt := range m.Statements {
+			if problem := lintStatement(normalizeSQL(stmt), created, m.NoTransaction); problem != "" {
+				issues = append(issues, MigrationIssue{Version: m.Version, Name: m.Name, Statement: strings.TrimSpace(stmt), Problem: problem})
+			}
+		}
+	}
+	return issues
+}
+
+// lintStatement returns why a normalized statement is backwards-incompatible, or "" if it isn't
+func lintStatement(stmt string, created map[string]bool, noTransaction bool) string {
+	concurrently := strings.Contains(stmt, " CONCURRENTLY ")
+	switch {
+	case concurrently && !noTransaction:
+		return "CONCURRENTLY can't run inside a transaction; set NoTransaction"
+	case createIndex.MatchString(stmt) && !concurrently:
+		if match := indexTable.FindStringSubmatch(stmt); match != nil && created[strings.Trim(match[1], `"`)] {
+			return ""
+		}
+		return "index built without CONCURRENTLY blocks writes to the table while it builds"
+	case dropIndex.MatchString(stmt) && !concurrently:
+		return "index dropped without CONCURRENTLY blocks queries on the table"
+	case dropTable.MatchString(stmt):
+		return "table dropped while the previous release may still use it"
+	case strings.HasPrefix(stmt, "ALTER TABLE ") && dropsColumn(stmt):
+		return "column dropped while the previous release may still use it; drop it one release after the code stops using it"
+	case renameColumn.MatchString(stmt):
+		return "rename breaks the previous release; add the new column or table, copy and drop the old one in a later release"
+	case changeColumnType.MatchString(stmt):
+		return "column type change rewrites the table and may break the previous release"
+	case setNotNull.MatchString(stmt):
+		return "NOT NULL constraint breaks inserts of the previous release that leave the column empty"
+	case addNotNull.MatchString(stmt) && !hasDefault.MatchString(stmt):
+		return "NOT NULL column without DEFAULT breaks inserts of the previous release"
+	}
+	return ""
+}
+
+// dropsColumn reports whether an ALTER TABLE statement drops a column, as opposed to a constraint,
+// a default or NOT NULL
+func dropsColumn(stmt string) bool {
+	for _, match := range dropColumn.FindAllStringSubmatch(stmt, -1) {
+		switch match[1] {
+		case "CONSTRAINT", "DEFAULT", "NOT", "EXPRESSION", "IDENTITY":
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSQL strips comments, collapses whitespace and upper-cases a statement for matching
+func normalizeSQL(stmt string) string {
+	stmt = sqlComment.ReplaceAllString(stmt, "")
+	return strings.ToUpper(strings.TrimSpace(sqlSpace.ReplaceAllString(stmt, " ")))
+}
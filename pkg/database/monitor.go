@@ -0,0 +1,165 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"gotemplate/pkg/logger"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// maxIdleConns is the idle pool size set by NewPostgresDB, restored after a pool reset
+const maxIdleConns = 10
+
+// minCheckInterval rate-limits the checks triggered by failing queries
+const minCheckInterval = time.Second
+
+// Monitor tracks whether the database is reachable. Queries failing with a connection error trigger an
+// immediate check; while the database is down the pool's idle connections are dropped, so that requests
+// after the database comes back (e.g. after a failover) use fresh connections instead of dead ones.
+// It also watches the connection pool for saturation, see checkPool.
+type Monitor struct {
+	db        *gorm.DB
+	interval  time.Duration
+	available atomic.Bool
+	lastCheck atomic.Int64 // Unix nanoseconds of the last completed check, 0 before the first
+	wake      chan struct{}
+
+	poolWaitThreshold time.Duration // Average connection wait that is warned about; zero disables the warning
+	running           atomic.Int64  // Statements running or waiting for a connection
+	routeMu           sync.Mutex
+	routeTime         map[string]time.Duration // Time spent in statements per route since the last pool check
+	lastPool          sql.DBStats              // Pool statistics at the last pool check
+}
+
+// NewMonitor creates a Monitor for db and hooks it into every query. Run must be started for checks to happen.
+func NewMonitor(db *gorm.DB, interval, poolWaitThreshold time.Duration) (*Monitor, error) {
+	m := &Monitor{db: db, interval: interval, wake: make(chan struct{}, 1), poolWaitThreshold: poolWaitThreshold, routeTime: map[string]time.Duration{}}
+	m.available.Store(true) // NewPostgresDB only returns a pinged connection
+
+	cb := db.Callback()
+	hooks := []error{
+		cb.Create().After("gorm:create").Register("database:monitor", m.afterQuery),
+		cb.Query().After("gorm:query").Register("database:monitor", m.afterQuery),
+		cb.Update().After("gorm:update").Register("database:monitor", m.afterQuery),
+		cb.Delete().After("gorm:delete").Register("database:monitor", m.afterQuery),
+		cb.Row().After("gorm:row").Register("database:monitor", m.afterQuery),
+		cb.Raw().After("gorm:raw").Register("database:monitor", m.afterQuery),
+		cb.Create().Before("gorm:create").Register("database:monitor_start", m.beforeStatement),
+		cb.Query().Before("gorm:query").Register("database:monitor_start", m.beforeStatement),
+		cb.Update().Before("gorm:update").Register("database:monitor_start", m.beforeStatement),
+		cb.Delete().Before("gorm:delete").Register("database:monitor_start", m.beforeStatement),
+		cb.Row().Before("gorm:row").Register("database:monitor_start", m.beforeStatement),
+		cb.Raw().Before("gorm:raw").Register("database:monitor_start", m.beforeStatement),
+	}
+	if err := errors.Join(hooks...); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Available reports whether the last check reached the database
+func (m *Monitor) Available() bool {
+	return m.available.Load()
+}
+
+// LastCheck returns when the database was last checked, or the zero time if it wasn't yet
+func (m *Monitor) LastCheck() time.Time {
+	if ns := m.lastCheck.Load(); ns != 0 {
+		return time.Unix(0, ns)
+	}
+	return time.Time{}
+}
+
+// afterQuery is a GORM callback that schedules a check when a query failed with a connection error
+func (m *Monitor) afterQuery(tx *gorm.DB) {
+	m.afterStatement(tx)
+	if tx.Error != nil && IsConnectionError(tx.Error) {
+		select {
+		case m.wake <- struct{}{}:
+		default: // A check is already pending
+		}
+	}
+}
+
+// Run checks the database and its pool every interval, and the database right away when a query hit a connection
+// error, until ctx is cancelled
+func (m *Monitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.checkPool()
+		case <-m.wake:
+			if time.Since(m.LastCheck()) < minCheckInterval {
+				continue
+			}
+		}
+		m.lastCheck.Store(time.Now().UnixNano())
+		m.check(ctx)
+	}
+}
+
+// check pings the database and flips availability, resetting the pool when the database goes away
+func (m *Monitor) check(ctx context.Context) {
+	sqlDB, err := m.db.DB()
+	if err != nil {
+		logger.Error("Failed to get underlying sql.DB for health check", zap.Error(err))
+		return
+	}
+
+	pingCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	err = sqlDB.PingContext(pingCtx)
+	if ctx.Err() != nil {
+		return // Shutting down
+	}
+
+	switch {
+	case err != nil && m.available.Swap(false):
+		logger.Error("Database became unreachable, resetting connection pool", zap.Error(err))
+		sqlDB.SetMaxIdleConns(0) // Closes the idle connections, which are likely dead
+		sqlDB.SetMaxIdleConns(maxIdleConns)
+	case err == nil && !m.available.Swap(true):
+		logger.Info("Database is reachable again")
+	}
+}
+
+// IsConnectionError reports whether err means the database connection failed, as opposed to a query error
+func IsConnectionError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return false // The request ran out of time; that says nothing about the connection
+	}
+	var netErr net.Error
+	return errors.Is(err, driver.ErrBadConn) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.As(err, &netErr)
+}
+
+// uniqueViolation is the Postgres SQLSTATE of a unique constraint or index violation
+const uniqueViolation = "23505"
+
+// IsUniqueViolation reports whether err is a write rejected by a unique constraint or index
+func IsUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
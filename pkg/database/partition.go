@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gotemplate/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// partitionCheckInterval is how often the partition manager creates and drops partitions
+const partitionCheckInterval = time.Hour
+
+// partitionsAhead is the number of months after the current one whose partitions are created in advance, so
+// inserts still find their partition if the manager stops running for a while
+const partitionsAhead = 2
+
+// partitionMonthFormat is the suffix of monthly partition names
+const partitionMonthFormat = "2006_01"
+
+// MonthlyPartitions is a table range-partitioned by month on a timestamp column, with one partition per
+// UTC month named <table>_YYYY_MM. Other partitions, e.g. a DEFAULT one, are left alone.
+type MonthlyPartitions struct {
+	Table     string
+	Retention time.Duration // Partitions whose month ended longer ago are dropped; zero keeps them all
+}
+
+// PartitionManager keeps monthly partitioned tables ready: it creates the partitions of the coming months
+// and drops those past retention, replacing row-by-row deletes of old data with a cheap DROP TABLE
+type PartitionManager struct {
+	db     *gorm.DB
+	tables []MonthlyPartitions
+}
+
+// NewPartitionManager creates a PartitionManager for tables. Run must be started for partitions to be managed.
+func NewPartitionManager(db *gorm.DB, tables ...MonthlyPartitions) *PartitionManager {
+	return &PartitionManager{db: db, tables: tables}
+}
+
+// Run maintains the partitions at start and then every partitionCheckInterval, until ctx is cancelled
+func (m *PartitionManager) Run(ctx context.Context) {
+	for {
+		for _, table := range m.tables {
+			if err := m.Maintain(ctx, table, time.Now()); err != nil && ctx.Err() == nil {
+				logger.Warn("Failed to maintain partitions", zap.Error(err), zap.String("table", table.Table))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(partitionCheckInterval):
+		}
+	}
+}
+
+// Maintain creates the partitions of table for now's month and the partitionsAhead next ones, then drops the
+// partitions whose month ended more than the retention before now
+func (m *PartitionManager) Maintain(ctx context.Context, table MonthlyPartitions, now time.Time) error {
+	db := m.db.WithContext(ctx)
+	current := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= partitionsAhead; i++ {
+		month := current.AddDate(0, i, 0)
+		name := partitionName(table.Table, month)
+		sqlQuery := fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')`,
+			pgx.Identifier{name}.Sanitize(), pgx.Identifier{table.Table}.Sanitize(),
+			month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339))
+		if err := db.Exec(sqlQuery).Error; err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", name, err)
+		}
+	}
+
+	if table.Retention <= 0 {
+		return nil
+	}
+	var partitions []string
+	sqlQuery := `SELECT c.relname FROM pg_inherits i JOIN pg_class c ON c.oid = i.inhrelid WHERE i.inhparent = ?::regclass ORDER BY c.relname`
+	if err := db.Raw(sqlQuery, table.Table).Scan(&partitions).Error; err != nil {
+		return fmt.Errorf("failed to list partitions of %s: %w", table.Table, err)
+	}
+	cutoff := now.Add(-table.Retention)
+	for _, name := range partitions {
+		month, err := time.Parse(partitionMonthFormat, strings.TrimPrefix(name, table.Table+"_"))
+		if err != nil || !month.AddDate(0, 1, 0).Before(cutoff) {
+			continue // Not a monthly partition, or still holds rows within retention
+		}
+		if err := db.Exec(`DROP TABLE ` + pgx.Identifier{name}.Sanitize()).Error; err != nil {
+			return fmt.Errorf("failed to drop partition %s: %w", name, err)
+		}
+		logger.Info("Partition dropped past retention", zap.String("table", table.Table), zap.String("partition", name))
+	}
+	return nil
+}
+
+// partitionName returns the name of the partition of table holding month
+func partitionName(table string, month time.Time) string {
+	return table + "_" + month.UTC().Format(partitionMonthFormat)
+}
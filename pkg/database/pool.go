@@ -0,0 +1,110 @@
+package database
+
+import (
+	"database/sql"
+	"sort"
+	"time"
+
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/requestid"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// poolStartKey is the statement instance key holding when a statement started and for which route
+const poolStartKey = "database:pool:start"
+
+// poolWarnRoutes is the number of routes named when warning about connection waits
+const poolWarnRoutes = 5
+
+// backgroundRoute labels the statements of background jobs, which run outside of any request
+const backgroundRoute = "background"
+
+// PoolStats is a snapshot of the connection pool
+type PoolStats struct {
+	sql.DBStats
+	Waiting int64 // Statements waiting for a connection, estimated as those running beyond the connections in use
+}
+
+// statementStart is what the start of a statement records for its end
+type statementStart struct {
+	at    time.Time
+	route string
+}
+
+// PoolStats returns the connection pool statistics
+func (m *Monitor) PoolStats() PoolStats {
+	stats := PoolStats{}
+	if sqlDB, err := m.db.DB(); err == nil {
+		stats.DBStats = sqlDB.Stats()
+	}
+	if waiting := m.running.Load() - int64(stats.InUse); waiting > 0 {
+		stats.Waiting = waiting
+	}
+	return stats
+}
+
+// beforeStatement is a GORM callback counting a statement as running and noting its route
+func (m *Monitor) beforeStatement(tx *gorm.DB) {
+	route := backgroundRoute
+	if tx.Statement.Context != nil {
+		if r := requestid.RouteFromContext(tx.Statement.Context); r != "" {
+			route = r
+		}
+	}
+	tx.InstanceSet(poolStartKey, statementStart{at: time.Now(), route: route})
+	m.running.Add(1)
+}
+
+// afterStatement is a GORM callback ending what beforeStatement started, adding the statement's time to its route
+func (m *Monitor) afterStatement(tx *gorm.DB) {
+	v, ok := tx.InstanceGet(poolStartKey)
+	if !ok {
+		return
+	}
+	start := v.(statementStart)
+	m.running.Add(-1)
+
+	m.routeMu.Lock()
+	m.routeTime[start.route] += time.Since(start.at)
+	m.routeMu.Unlock()
+}
+
+// checkPool warns when statements waited longer than the threshold on average for a connection since the last
+// check, naming the routes that spent the most time in the database meanwhile: they hold the connections.
+// It is only called from Run.
+func (m *Monitor) checkPool() {
+	m.routeMu.Lock()
+	routeTime := m.routeTime
+	m.routeTime = map[string]time.Duration{}
+	m.routeMu.Unlock()
+
+	stats := m.PoolStats()
+	waits := stats.WaitCount - m.lastPool.WaitCount
+	waited := stats.WaitDuration - m.lastPool.WaitDuration
+	m.lastPool = stats.DBStats
+	if m.poolWaitThreshold <= 0 || waits <= 0 || waited/time.Duration(waits) < m.poolWaitThreshold {
+		return
+	}
+
+	routes := make([]string, 0, len(routeTime))
+	for route := range routeTime {
+		routes = append(routes, route)
+	}
+	sort.Slice(routes, func(i, j int) bool { return routeTime[routes[i]] > routeTime[routes[j]] })
+	if len(routes) > poolWarnRoutes {
+		routes = routes[:poolWarnRoutes]
+	}
+	busiest := make([]string, len(routes))
+	for i, route := range routes {
+		busiest[i] = route + " (" + routeTime[route].Round(time.Millisecond).String() + ")"
+	}
+	logger.Warn("Statements are waiting for database connections",
+		zap.Int64("waits", waits),
+		zap.Duration("averageWait", waited/time.Duration(waits)),
+		zap.Int("inUse", stats.InUse),
+		zap.Int("maxOpen", stats.MaxOpenConnections),
+		zap.Int64("waiting", stats.Waiting),
+		zap.Strings("busiestRoutes", busiest))
+}
@@ -6,7 +6,6 @@ import (
 	"time"
 
 	"gotemplate/config"
-	"gotemplate/internal/models" // Import your models package here!
 	"gotemplate/pkg/logger"
 
 	"go.uber.org/zap"
@@ -14,13 +13,13 @@ import (
 	"gorm.io/gorm"            // GORM main package
 )
 
-// NewPostgresDB establishes a new PostgreSQL database connection using GORM
-// and performs auto-migration.
+// NewPostgresDB establishes a new PostgreSQL database connection using GORM.
+// Schema migration is separate, see AutoMigrate.
 // It now returns *gorm.DB directly.
 func NewPostgresDB(cfg *config.DatabaseConfig) (*gorm.DB, error) { // Changed return type
 	// Construct the DSN (Data Source Name) for GORM
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Shanghai",
-		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode)
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Shanghai application_name=%s",
+		cfg.Host, cfg.User, cfg.Password, cfg.DBName, cfg.Port, cfg.SSLMode, cfg.ApplicationName)
 
 	// Open connection with GORM
 	gormDB, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
@@ -43,7 +42,7 @@ func NewPostgresDB(cfg *config.DatabaseConfig) (*gorm.DB, error) { // Changed re
 	}
 
 	// Set connection pool settings (optional, but recommended)
-	sqlDB.SetMaxIdleConns(10)                 // Maximum number of idle connections in the pool
+	sqlDB.SetMaxIdleConns(maxIdleConns)       // Maximum number of idle connections in the pool
 	sqlDB.SetMaxOpenConns(100)                // Maximum number of open connections to the database
 	sqlDB.SetConnMaxLifetime(5 * time.Minute) // Maximum amount of time a connection may be reused
 
@@ -62,21 +61,19 @@ func NewPostgresDB(cfg *config.DatabaseConfig) (*gorm.DB, error) { // Changed re
 		zap.String("host", cfg.Host),
 		zap.String("db_name", cfg.DBName))
 
-	// Perform auto-migration
-	// Pass all your model structs here
-	err = gormDB.AutoMigrate(
-		&models.User{},
-		&models.Product{}, // Make sure to uncomment or add all your GORM models here!
-	)
-	if err != nil {
+	// Return *gorm.DB directly
+	return gormDB, nil
+}
+
+// AutoMigrate creates or updates the tables of the given models, in order
+func AutoMigrate(db *gorm.DB, models ...interface{}) error {
+	if err := db.AutoMigrate(models...); err != nil {
 		logger.Error("Failed to perform GORM auto-migration", zap.Error(err))
-		return nil, fmt.Errorf("failed to perform GORM auto-migration: %w", err)
+		return fmt.Errorf("failed to perform GORM auto-migration: %w", err)
 	}
 
-	logger.Info("GORM auto-migration completed successfully")
-
-	// Return *gorm.DB directly
-	return gormDB, nil
+	logger.Info("GORM auto-migration completed successfully", zap.Int("models", len(models)))
+	return nil
 }
 
 // Close function is now a standalone helper or could be a method on a struct
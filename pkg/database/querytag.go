@@ -0,0 +1,47 @@
+package database
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	"gotemplate/pkg/requestid"
+
+	"gorm.io/gorm"
+)
+
+// EnableQueryTags appends a sqlcommenter-style comment naming the route, and the request ID if withRequestID, to
+// every raw SQL statement run for a request, e.g. /*request_id='4bf9',route='GET%20%2Fapi%2Fv1%2Fproducts'*/.
+// Slow queries seen in pg_stat_activity or the server log can then be traced back to where they came from;
+// pg_stat_statements ignores comments, so its statistics aren't split. Statements GORM builds itself are not
+// tagged, the repositories write theirs as raw SQL.
+func EnableQueryTags(db *gorm.DB, withRequestID bool) error {
+	cb := db.Callback()
+	tag := func(tx *gorm.DB) { tagQuery(tx, withRequestID) }
+	hooks := []error{
+		cb.Query().Before("gorm:query").Register("database:tag", tag),
+		cb.Row().Before("gorm:row").Register("database:tag", tag),
+		cb.Raw().Before("gorm:raw").Register("database:tag", tag),
+	}
+	return errors.Join(hooks...)
+}
+
+// tagQuery appends the comment to the statement's SQL, if it was already written and runs for a request
+func tagQuery(tx *gorm.DB, withRequestID bool) {
+	stmt := tx.Statement
+	if stmt.SQL.Len() == 0 || stmt.Context == nil {
+		return
+	}
+	route := requestid.RouteFromContext(stmt.Context)
+	if route == "" {
+		return // Background jobs and migrations
+	}
+
+	// Keys are sorted and values URL-encoded as sqlcommenter specifies; encoding also keeps "*/" out of the comment
+	var tags []string
+	if id := requestid.FromContext(stmt.Context); withRequestID && id != "" {
+		tags = append(tags, "request_id='"+url.PathEscape(id)+"'")
+	}
+	tags = append(tags, "route='"+url.PathEscape(route)+"'")
+	stmt.SQL.WriteString(" /*" + strings.Join(tags, ",") + "*/")
+}
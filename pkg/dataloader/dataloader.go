@@ -0,0 +1,152 @@
+// Package dataloader batches lookups by key: the loads made while a batch is open are fetched together with one
+// call, e.g. one WHERE id IN (...) query instead of one query per row. A Loader also remembers what it fetched,
+// so it is meant to live for one request or one resolution, not to be shared as a cache.
+package dataloader
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Load for keys the fetch didn't return
+var ErrNotFound = errors.New("not found")
+
+// FetchFunc looks up keys at once. Keys missing from the map are not found.
+type FetchFunc[K comparable, V any] func(ctx context.Context, keys []K) (map[K]V, error)
+
+// Loader collects the keys loaded within Wait of the first one, or until MaxBatch are waiting, and fetches them
+// together. The batch is fetched with the context of the load that opened it.
+type Loader[K comparable, V any] struct {
+	fetch    FetchFunc[K, V]
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	batch   *batch[K, V]
+	results map[K]*result[V]
+}
+
+// batch is the keys waiting to be fetched together
+type batch[K comparable, V any] struct {
+	ctx  context.Context
+	keys []K
+	done chan struct{} // Closed once the batch has been fetched
+}
+
+// result is the outcome of loading one key, valid once its batch is done
+type result[V any] struct {
+	batchDone chan struct{}
+	value     V
+	err       error
+}
+
+// New creates a Loader fetching with fetch. A batch is fetched wait after its first key was loaded, or as soon as
+// maxBatch keys are waiting if maxBatch is positive.
+func New[K comparable, V any](fetch FetchFunc[K, V], wait time.Duration, maxBatch int) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, wait: wait, maxBatch: maxBatch, results: map[K]*result[V]{}}
+}
+
+// Load returns the value of key, fetching it with the other keys loaded meanwhile. Keys already loaded are not
+// fetched again.
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	res := l.enqueue(ctx, []K{key}, false)[0]
+	select {
+	case <-res.batchDone:
+		return res.value, res.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// LoadMany returns the values of keys found, fetching the missing ones right away in the open batch rather than
+// waiting for more. The error is the first fetch error; keys not found are left out of the map.
+func (l *Loader[K, V]) LoadMany(ctx context.Context, keys []K) (map[K]V, error) {
+	results := l.enqueue(ctx, keys, true)
+	values := make(map[K]V, len(keys))
+	for i, res := range results {
+		select {
+		case <-res.batchDone:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if errors.Is(res.err, ErrNotFound) {
+			continue
+		} else if res.err != nil {
+			return nil, res.err
+		}
+		values[keys[i]] = res.value
+	}
+	return values, nil
+}
+
+// enqueue returns the results of keys, adding those not loaded yet to the open batch, and fetches the batch now
+// if dispatch is set or it is full
+func (l *Loader[K, V]) enqueue(ctx context.Context, keys []K, dispatch bool) []*result[V] {
+	l.mu.Lock()
+	results := make([]*result[V], len(keys))
+	for i, key := range keys {
+		if res, ok := l.results[key]; ok {
+			results[i] = res
+			continue
+		}
+		if l.batch == nil {
+			l.batch = &batch[K, V]{ctx: ctx, done: make(chan struct{})}
+			if !dispatch {
+				b := l.batch
+				time.AfterFunc(l.wait, func() { l.dispatch(b) })
+			}
+		}
+		res := &result[V]{batchDone: l.batch.done}
+		l.results[key] = res
+		l.batch.keys = append(l.batch.keys, key)
+		results[i] = res
+	}
+	b := l.batch
+	full := b != nil && l.maxBatch > 0 && len(b.keys) >= l.maxBatch
+	l.mu.Unlock()
+
+	if b != nil && (dispatch || full) {
+		l.dispatch(b)
+	}
+	return results
+}
+
+// dispatch fetches b, unless it was already fetched, and fills in the results of its keys
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch != b {
+		l.mu.Unlock()
+		return // Fetched by whoever filled it or loaded many
+	}
+	l.batch = nil
+	results := make([]*result[V], len(b.keys))
+	for i, key := range b.keys {
+		results[i] = l.results[key]
+	}
+	l.mu.Unlock()
+
+	values, err := l.fetch(b.ctx, b.keys)
+	for i, key := range b.keys {
+		if err != nil {
+			results[i].err = err
+		} else if v, ok := values[key]; ok {
+			results[i].value = v
+		} else {
+			results[i].err = ErrNotFound
+		}
+	}
+	if err != nil {
+		// Failures are not remembered: a later load tries again
+		l.mu.Lock()
+		for i, key := range b.keys {
+			if l.results[key] == results[i] {
+				delete(l.results, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+	close(b.done)
+}
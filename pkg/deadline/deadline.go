@@ -0,0 +1,44 @@
+// Package deadline helps code respect the per-request time budget set by middleware.RequestDeadline.
+// The budget travels as the context deadline, so database calls made with WithContext are bounded
+// automatically; Require lets code skip expensive work that can no longer finish in time.
+package deadline
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrBudgetExhausted is returned by Require when too little of the request budget is left
+var ErrBudgetExhausted = errors.New("request time budget exhausted")
+
+// Remaining returns the time left until the context deadline. ok is false if the context has no deadline.
+func Remaining(ctx context.Context) (remaining time.Duration, ok bool) {
+	d, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+	return time.Until(d), true
+}
+
+// Require returns ErrBudgetExhausted unless at least need is left before the context deadline.
+// Contexts without a deadline always pass.
+func Require(ctx context.Context, need time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return ErrBudgetExhausted
+	}
+	if remaining, ok := Remaining(ctx); ok && remaining < need {
+		return ErrBudgetExhausted
+	}
+	return nil
+}
+
+// Slice derives a context for one step (e.g. an external call) that gets at most share of the remaining budget,
+// leaving the rest for the work that follows
+func Slice(ctx context.Context, share float64) (context.Context, context.CancelFunc) {
+	remaining, ok := Remaining(ctx)
+	if !ok || share <= 0 || share >= 1 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Duration(float64(remaining)*share))
+}
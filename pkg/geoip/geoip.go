@@ -0,0 +1,127 @@
+// Package geoip resolves client IPs to countries from a MaxMind database (GeoLite2 or GeoIP2 Country/City).
+// The file is reloaded when it changes on disk, e.g. after geoipupdate, without restarting the server.
+package geoip
+
+import (
+	"context"
+	"fmt"
+	"gotemplate/pkg/logger"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DB is a country lookup over a MaxMind database file
+type DB struct {
+	path     string
+	interval time.Duration
+
+	mu      sync.RWMutex
+	db      *mmdb
+	modTime time.Time
+}
+
+// Open loads the database at path. Run reloads it every reloadInterval if the file changed.
+func Open(path string, reloadInterval time.Duration) (*DB, error) {
+	g := &DB{path: path, interval: reloadInterval}
+	if err := g.load(); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+// load reads and parses the file, replacing the database in use only if it is valid
+func (g *DB) load() error {
+	info, err := os.Stat(g.path)
+	if err != nil {
+		return fmt.Errorf("failed to stat GeoIP database: %w", err)
+	}
+	buf, err := os.ReadFile(g.path)
+	if err != nil {
+		return fmt.Errorf("failed to read GeoIP database: %w", err)
+	}
+	db, err := parseMMDB(buf)
+	if err != nil {
+		return fmt.Errorf("failed to parse GeoIP database %s: %w", g.path, err)
+	}
+
+	g.mu.Lock()
+	g.db, g.modTime = db, info.ModTime()
+	g.mu.Unlock()
+	return nil
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of ip, e.g. "DE", or "" if it is unknown
+func (g *DB) Country(ip string) string {
+	parsed := net.ParseIP(ip)
+	if g == nil || parsed == nil {
+		return ""
+	}
+	g.mu.RLock()
+	db := g.db
+	g.mu.RUnlock()
+
+	record, err := db.lookup(parsed)
+	if err != nil {
+		logger.Warn("GeoIP lookup failed", zap.String("ip", ip), zap.Error(err))
+		return ""
+	}
+	m, _ := record.(map[string]interface{})
+	if code := isoCode(m["country"]); code != "" {
+		return code
+	}
+	return isoCode(m["registered_country"]) // E.g. anycast and satellite networks have no located country
+}
+
+// isoCode returns the iso_code of a country record
+func isoCode(v interface{}) string {
+	m, _ := v.(map[string]interface{})
+	code, _ := m["iso_code"].(string)
+	return strings.ToUpper(code)
+}
+
+// Run reloads the database whenever the file's modification time changes, until ctx is done.
+// A file that fails to load is logged and the previous database stays in use.
+func (g *DB) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(g.interval):
+		}
+
+		info, err := os.Stat(g.path)
+		if err != nil {
+			logger.Warn("GeoIP database is unavailable, keeping the loaded copy", zap.String("path", g.path), zap.Error(err))
+			continue
+		}
+		g.mu.RLock()
+		changed := !info.ModTime().Equal(g.modTime)
+		g.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := g.load(); err != nil {
+			logger.Error("Failed to reload GeoIP database, keeping the loaded copy", zap.Error(err))
+			continue
+		}
+		logger.Info("GeoIP database reloaded", zap.String("path", g.path))
+	}
+}
+
+type contextKey struct{}
+
+// WithCountry returns a copy of ctx carrying the country of the request's client
+func WithCountry(ctx context.Context, country string) context.Context {
+	return context.WithValue(ctx, contextKey{}, country)
+}
+
+// FromContext returns the client country stored in ctx, or "" if it is unknown or outside of a request
+func FromContext(ctx context.Context) string {
+	country, _ := ctx.Value(contextKey{}).(string)
+	return country
+}
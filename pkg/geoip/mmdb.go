@@ -0,0 +1,272 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"net"
+)
+
+// This file reads the MaxMind DB format (https://maxmind.github.io/MaxMind-DB/): a binary search tree over the
+// bits of an address whose leaves point into a data section of typed, self-describing values. Only what
+// lookups need is implemented; the file is read into memory.
+
+// metadataMarker precedes the metadata map at the end of the file
+var metadataMarker = []byte("\xAB\xCD\xEFMaxMind.com")
+
+// maxDecodeDepth bounds nested maps, arrays and pointers, so a corrupt file can't recurse forever
+const maxDecodeDepth = 32
+
+// mmdb is a parsed MaxMind database
+type mmdb struct {
+	tree       []byte
+	data       decoder
+	nodeCount  uint
+	recordSize uint
+	ipVersion  uint
+	ipv4Start  uint // Node of ::/96 in an IPv6 tree, where IPv4 addresses are looked up
+}
+
+// parseMMDB parses a MaxMind database file
+func parseMMDB(buf []byte) (*mmdb, error) {
+	i := bytes.LastIndex(buf, metadataMarker)
+	if i < 0 {
+		return nil, errors.New("not a MaxMind database: metadata marker missing")
+	}
+	meta, _, err := decoder{buf: buf[i+len(metadataMarker):]}.decode(0, 0)
+	if err != nil {
+		return nil, fmt.Errorf("invalid metadata: %w", err)
+	}
+	m, ok := meta.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("invalid metadata: not a map")
+	}
+
+	db := &mmdb{nodeCount: toUint(m["node_count"]), recordSize: toUint(m["record_size"]), ipVersion: toUint(m["ip_version"])}
+	switch db.recordSize {
+	case 24, 28, 32:
+	default:
+		return nil, fmt.Errorf("unsupported record size %d", db.recordSize)
+	}
+	if db.ipVersion != 4 && db.ipVersion != 6 {
+		return nil, fmt.Errorf("unsupported IP version %d", db.ipVersion)
+	}
+	treeSize := db.nodeCount * db.recordSize / 4
+	if treeSize+16 > uint(i) {
+		return nil, errors.New("search tree is larger than the file")
+	}
+	db.tree = buf[:treeSize]
+	db.data = decoder{buf: buf[treeSize+16 : i]} // The tree is followed by 16 zero bytes
+
+	if db.ipVersion == 6 {
+		for bit := 0; bit < 96 && db.ipv4Start < db.nodeCount; bit++ {
+			db.ipv4Start = db.readNode(db.ipv4Start, 0)
+		}
+	}
+	return db, nil
+}
+
+// readNode returns the left (bit 0) or right (bit 1) record of a tree node
+func (db *mmdb) readNode(node uint, bit uint) uint {
+	b := db.tree
+	switch db.recordSize {
+	case 24:
+		off := node*6 + bit*3
+		return uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+	case 28:
+		off := node * 7
+		if bit == 0 {
+			return uint(b[off+3]&0xF0)<<20 | uint(b[off])<<16 | uint(b[off+1])<<8 | uint(b[off+2])
+		}
+		return uint(b[off+3]&0x0F)<<24 | uint(b[off+4])<<16 | uint(b[off+5])<<8 | uint(b[off+6])
+	default:
+		off := node*8 + bit*4
+		return uint(binary.BigEndian.Uint32(b[off:]))
+	}
+}
+
+// lookup returns the record of the network containing ip, or nil if the database has none
+func (db *mmdb) lookup(ip net.IP) (interface{}, error) {
+	node := uint(0)
+	bits := ip.To4()
+	if bits != nil {
+		node = db.ipv4Start
+	} else if db.ipVersion == 4 {
+		return nil, nil // IPv6 address in an IPv4-only database
+	} else {
+		bits = ip.To16()
+	}
+	if bits == nil {
+		return nil, errors.New("invalid IP address")
+	}
+
+	for i := 0; i < len(bits)*8 && node < db.nodeCount; i++ {
+		node = db.readNode(node, uint(bits[i/8]>>(7-i%8))&1)
+	}
+	switch {
+	case node == db.nodeCount:
+		return nil, nil
+	case node < db.nodeCount:
+		return nil, errors.New("search tree is deeper than the address")
+	}
+	record, _, err := db.data.decode(node-db.nodeCount-16, 0)
+	return record, err
+}
+
+// decoder decodes the values of a data section; pointers are offsets from its start
+type decoder struct {
+	buf []byte
+}
+
+// decode decodes the value at offset and returns it with the offset following it
+func (d decoder) decode(offset uint, depth int) (interface{}, uint, error) {
+	if depth > maxDecodeDepth {
+		return nil, 0, errors.New("data nested too deeply")
+	}
+	b, offset, err := d.read(offset, 1)
+	if err != nil {
+		return nil, 0, err
+	}
+	ctrl := b[0]
+	typ := uint(ctrl >> 5)
+
+	if typ == 1 { // Pointer
+		size := uint(ctrl>>3) & 0x3
+		p, next, err := d.read(offset, size+1)
+		if err != nil {
+			return nil, 0, err
+		}
+		v := uint(ctrl & 0x7)
+		var target uint
+		switch size {
+		case 0:
+			target = v<<8 | uint(p[0])
+		case 1:
+			target = (v<<16 | uint(p[0])<<8 | uint(p[1])) + 2048
+		case 2:
+			target = (v<<24 | uint(p[0])<<16 | uint(p[1])<<8 | uint(p[2])) + 526336
+		default:
+			target = uint(binary.BigEndian.Uint32(p))
+		}
+		value, _, err := d.decode(target, depth+1)
+		return value, next, err
+	}
+
+	if typ == 0 { // Extended type
+		ext, next, err := d.read(offset, 1)
+		if err != nil {
+			return nil, 0, err
+		}
+		typ, offset = 7+uint(ext[0]), next
+	}
+
+	size := uint(ctrl & 0x1f)
+	if size >= 29 {
+		n := size - 28
+		s, next, err := d.read(offset, n)
+		if err != nil {
+			return nil, 0, err
+		}
+		offset = next
+		switch n {
+		case 1:
+			size = 29 + uint(s[0])
+		case 2:
+			size = 285 + (uint(s[0])<<8 | uint(s[1]))
+		default:
+			size = 65821 + (uint(s[0])<<16 | uint(s[1])<<8 | uint(s[2]))
+		}
+	}
+
+	switch typ {
+	case 7: // Map
+		m := make(map[string]interface{}, size)
+		for i := uint(0); i < size; i++ {
+			key, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, 0, errors.New("map key is not a string")
+			}
+			value, next, err := d.decode(next, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			m[k], offset = value, next
+		}
+		return m, offset, nil
+	case 11: // Array
+		a := make([]interface{}, 0, size)
+		for i := uint(0); i < size; i++ {
+			value, next, err := d.decode(offset, depth+1)
+			if err != nil {
+				return nil, 0, err
+			}
+			a, offset = append(a, value), next
+		}
+		return a, offset, nil
+	case 14: // Boolean, held in the size
+		return size != 0, offset, nil
+	}
+
+	payload, next, err := d.read(offset, size)
+	if err != nil {
+		return nil, 0, err
+	}
+	switch typ {
+	case 2: // UTF-8 string
+		return string(payload), next, nil
+	case 3: // Double
+		if size != 8 {
+			return nil, 0, errors.New("invalid double size")
+		}
+		return math.Float64frombits(binary.BigEndian.Uint64(payload)), next, nil
+	case 4: // Bytes
+		return append([]byte(nil), payload...), next, nil
+	case 5, 6, 9: // uint16, uint32, uint64
+		if size > 8 {
+			return nil, 0, errors.New("invalid unsigned integer size")
+		}
+		var v uint64
+		for _, c := range payload {
+			v = v<<8 | uint64(c)
+		}
+		return v, next, nil
+	case 8: // int32
+		if size > 4 {
+			return nil, 0, errors.New("invalid int32 size")
+		}
+		var v uint32
+		for _, c := range payload {
+			v = v<<8 | uint32(c)
+		}
+		return int32(v), next, nil
+	case 10: // uint128
+		return new(big.Int).SetBytes(payload), next, nil
+	case 15: // Float
+		if size != 4 {
+			return nil, 0, errors.New("invalid float size")
+		}
+		return math.Float32frombits(binary.BigEndian.Uint32(payload)), next, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported data type %d", typ)
+}
+
+// read returns n bytes at offset and the offset following them
+func (d decoder) read(offset uint, n uint) ([]byte, uint, error) {
+	if offset+n > uint(len(d.buf)) || offset+n < offset {
+		return nil, 0, errors.New("unexpected end of data")
+	}
+	return d.buf[offset : offset+n], offset + n, nil
+}
+
+// toUint converts a decoded unsigned integer of the metadata
+func toUint(v interface{}) uint {
+	n, _ := v.(uint64)
+	return uint(n)
+}
@@ -0,0 +1,167 @@
+// Package jsonbody decodes JSON request bodies within size and nesting limits, and explains to API clients
+// why a body was refused. The limits of a request are set by middleware.JSONLimits and travel in its context.
+package jsonbody
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Limits bound the JSON body of a request
+type Limits struct {
+	MaxSize               int64 // Largest body accepted, in bytes
+	MaxDepth              int   // Deepest nesting of objects and arrays accepted
+	DisallowUnknownFields bool  // Refuse fields the target type doesn't have, rather than ignoring them
+}
+
+// DefaultLimits apply to requests whose context carries no limits
+var DefaultLimits = Limits{MaxSize: 1 << 20, MaxDepth: 32}
+
+// contextKey is unexported to prevent collisions with context keys from other packages
+type contextKey struct{}
+
+// WithLimits returns a copy of ctx carrying the limits of its request's body
+func WithLimits(ctx context.Context, limits Limits) context.Context {
+	return context.WithValue(ctx, contextKey{}, limits)
+}
+
+// LimitsFrom returns the limits stored in ctx, or DefaultLimits
+func LimitsFrom(ctx context.Context) Limits {
+	if limits, ok := ctx.Value(contextKey{}).(Limits); ok {
+		return limits
+	}
+	return DefaultLimits
+}
+
+// Error is a body that was refused, with the status and message to answer with
+type Error struct {
+	Status  int    // 413 for bodies over the size limit, 400 otherwise
+	Message string // Meant for API clients
+	Field   string // Field at fault, when the error is about one
+	Err     error  // Underlying cause, for logs
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Decode reads one JSON value from body into v within limits. Any failure is an *Error.
+func Decode(body io.Reader, v interface{}, limits Limits) error {
+	data, err := io.ReadAll(io.LimitReader(body, limits.MaxSize+1))
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge): // Set by middleware.DecompressRequest on gzip-encoded bodies
+		return &Error{Status: http.StatusRequestEntityTooLarge, Message: fmt.Sprintf("request body is larger than %d bytes once decompressed", tooLarge.Limit), Err: err}
+	case err != nil:
+		return &Error{Status: http.StatusBadRequest, Message: "request body could not be read", Err: err}
+	case int64(len(data)) > limits.MaxSize:
+		return &Error{Status: http.StatusRequestEntityTooLarge, Message: fmt.Sprintf("request body is larger than %d bytes", limits.MaxSize)}
+	case len(bytes.TrimSpace(data)) == 0:
+		return &Error{Status: http.StatusBadRequest, Message: "request body is empty"}
+	case !withinDepth(data, limits.MaxDepth):
+		return &Error{Status: http.StatusBadRequest, Message: fmt.Sprintf("request body is nested deeper than %d levels", limits.MaxDepth)}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if limits.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		return &Error{Status: http.StatusBadRequest, Message: message(err), Field: field(err), Err: err}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return &Error{Status: http.StatusBadRequest, Message: "request body must hold a single JSON value"}
+	}
+	return nil
+}
+
+// message describes a decoding error for API clients
+func message(err error) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is truncated, the JSON ends early"
+	case errors.As(err, &syntaxErr):
+		return fmt.Sprintf("request body is not valid JSON: %s at byte %d", strings.TrimPrefix(syntaxErr.Error(), "json: "), syntaxErr.Offset)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("%s must be a JSON %s, not a JSON %s", typeErr.Field, jsonKind(typeErr.Type), typeErr.Value)
+	case errors.As(err, &typeErr):
+		return fmt.Sprintf("request body must be a JSON %s, not a JSON %s", jsonKind(typeErr.Type), typeErr.Value)
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return "unknown field " + strings.TrimPrefix(err.Error(), "json: unknown field ")
+	default: // A type's own UnmarshalJSON refused the value, e.g. a malformed time
+		return "request body holds an invalid value: " + strings.TrimPrefix(err.Error(), "json: ")
+	}
+}
+
+// field returns the field a decoding error is about, or "" if it isn't about one
+func field(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return typeErr.Field
+	}
+	if name, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			return unquoted
+		}
+	}
+	return ""
+}
+
+// jsonKind names the JSON value a Go type is decoded from
+func jsonKind(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map:
+		return "object"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Pointer:
+		return jsonKind(t.Elem())
+	default:
+		return "number"
+	}
+}
+
+// withinDepth reports whether the objects and arrays of a JSON text nest at most max levels deep
+func withinDepth(data []byte, max int) bool {
+	level, inString, escaped := 0, false, false
+	for _, b := range data {
+		switch {
+		case escaped:
+			escaped = false
+		case inString && b == '\\':
+			escaped = true
+		case inString:
+			inString = b != '"'
+		case b == '"':
+			inString = true
+		case b == '{' || b == '[':
+			if level++; level > max {
+				return false
+			}
+		case b == '}' || b == ']':
+			level--
+		}
+	}
+	return true
+}
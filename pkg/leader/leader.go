@@ -0,0 +1,132 @@
+// Package leader coordinates the instances of a deployment through Postgres advisory locks, so that work
+// meant for the whole deployment, like scheduled jobs and migrations, runs on one instance at a time.
+//
+// Advisory locks belong to a database session: the lock is held on one pinned connection for as long as the
+// work runs, and Postgres releases it when that session ends, so an instance that crashes or loses its
+// connection can't keep others waiting.
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"gotemplate/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// RetryInterval is how often an instance that isn't leading tries to take over
+const RetryInterval = 15 * time.Second
+
+// checkInterval is how often a leader checks that the session holding its lock is still alive
+const checkInterval = 10 * time.Second
+
+// Key derives the advisory lock key of a name. Names must be unique across the application.
+func Key(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("gotemplate/leader:" + name))
+	return int64(h.Sum64())
+}
+
+// WithLock runs fn on a connection holding the advisory lock key, waiting until other instances release it.
+// It is meant for work every instance must see done before going on, like migrations at startup.
+func WithLock(ctx context.Context, db *gorm.DB, key int64, fn func(conn *gorm.DB) error) error {
+	// The advisory lock belongs to a session, so everything runs on one pinned connection
+	return db.WithContext(ctx).Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec(`SELECT pg_advisory_lock(?)`, key).Error; err != nil {
+			return fmt.Errorf("failed to acquire advisory lock: %w", err)
+		}
+		defer func() {
+			// With ctx cancelled, e.g. on a startup timeout, unlock on a fresh context; were the session to
+			// survive, the lock would be held until the connection is closed
+			unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := conn.WithContext(unlockCtx).Exec(`SELECT pg_advisory_unlock(?)`, key).Error; err != nil {
+				logger.Warn("Failed to release advisory lock", zap.Error(err), zap.Int64("key", key))
+			}
+		}()
+		return fn(conn)
+	})
+}
+
+// Singleton returns a worker running run on one instance of the deployment at a time: the instance holding the
+// lock of name. The others try to take over every RetryInterval. If the lock's session is lost, run's context
+// is cancelled and leadership is contested again. Workers of in-memory state, e.g. counters flushed to the
+// database, must run on every instance and not be wrapped.
+func Singleton(db *gorm.DB, name string, run func(ctx context.Context)) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		for {
+			led, err := lead(ctx, db, name, run)
+			switch {
+			case err != nil && ctx.Err() == nil:
+				logger.Warn("Failed to lead job, retrying", zap.Error(err), zap.String("job", name))
+			case led && ctx.Err() == nil:
+				logger.Info("Job stopped, leadership released", zap.String("job", name))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(RetryInterval):
+			}
+		}
+	}
+}
+
+// lead runs run while holding the lock of name, if it is free, and reports whether it was
+func lead(ctx context.Context, db *gorm.DB, name string, run func(ctx context.Context)) (led bool, err error) {
+	key := Key(name)
+	sqlDB, err := db.DB()
+	if err != nil {
+		return false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get a connection for the lock: %w", err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&acquired); err != nil {
+		return false, fmt.Errorf("failed to try the advisory lock: %w", err)
+	}
+	if !acquired {
+		return false, nil
+	}
+	defer func() {
+		// With ctx cancelled on shutdown, unlock on a fresh context; a dead session has released the lock already
+		unlockCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_, _ = conn.ExecContext(unlockCtx, `SELECT pg_advisory_unlock($1)`, key)
+	}()
+	logger.Info("Leadership acquired", zap.String("job", name))
+
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		run(runCtx)
+	}()
+	return true, watch(runCtx, conn, done, cancel)
+}
+
+// watch waits for run to finish, cancelling it if the session holding the lock dies, since another instance
+// may have taken over
+func watch(ctx context.Context, conn *sql.Conn, done <-chan struct{}, cancel context.CancelFunc) error {
+	for {
+		select {
+		case <-done:
+			return nil
+		case <-time.After(checkInterval):
+		}
+		if err := conn.PingContext(ctx); err != nil && ctx.Err() == nil {
+			cancel()
+			<-done
+			return fmt.Errorf("lost the session holding the lock: %w", err)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package logger
 import (
 	"log"
 
-	"go.uber.org/zap"        // Import zap for structured logging
+	"go.uber.org/zap"         // Import zap for structured logging
 	"go.uber.org/zap/zapcore" // Import zapcore for logger configuration
 )
 
@@ -30,7 +30,10 @@ func InitLogger(debug bool) {
 	config.EncoderConfig.TimeKey = "timestamp"                   // Field name for timestamp
 	config.EncoderConfig.StacktraceKey = "stacktrace"            // Field name for stacktrace
 
-	ZapLogger, err = config.Build(zap.AddCallerSkip(1)) // Build the logger, skip 1 caller for correct file/line number
+	ZapLogger, err = config.Build(
+		zap.AddCallerSkip(1),           // Skip 1 caller for correct file/line number
+		zap.WrapCore(newRedactingCore), // Mask tokens and keys before anything is written
+	)
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err) // Use standard log if Zap fails
 	}
@@ -56,4 +59,4 @@ func Error(msg string, fields ...zap.Field) {
 
 func Fatal(msg string, fields ...zap.Field) {
 	ZapLogger.Fatal(msg, fields...)
-}
\ No newline at end of file
+}
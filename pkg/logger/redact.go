@@ -0,0 +1,109 @@
+package logger
+
+import (
+	"regexp"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedValue replaces a secret in log output
+const redactedValue = "[REDACTED]"
+
+// secretPatterns match credentials that may end up inside log messages or string fields
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`),                                         // Authorization header values
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),                        // JWTs (base64url JSON header starts with eyJ)
+	regexp.MustCompile(`\b(?:sk|pk|rk)_(?:live|test)_[A-Za-z0-9]{10,}`),                              // Stripe-style keys
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{20,}`),                                               // GitHub tokens
+	regexp.MustCompile(`\bgtp_[A-Za-z0-9_-]{20,}`),                                                   // Personal access tokens issued by this service
+	regexp.MustCompile(`\bgtsp_[A-Za-z0-9_-]{20,}`),                                                  // Set-password tokens issued by this service
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),                                                       // AWS access key IDs
+	regexp.MustCompile(`(?i)\b(api[_-]?key|secret|token|password)(["']?\s*[:=]\s*["']?)[^\s"'&,;]+`), // key=value pairs
+}
+
+// secretFieldKeys are field names whose whole value is always masked
+var secretFieldKeys = map[string]bool{
+	"password": true, "token": true, "authorization": true, "apikey": true, "api_key": true, "secret": true,
+}
+
+// RedactSecrets masks credentials found in s
+func RedactSecrets(s string) string {
+	for i, p := range secretPatterns {
+		if i == len(secretPatterns)-1 {
+			s = p.ReplaceAllString(s, "${1}${2}"+redactedValue) // Keep the key name, mask only the value
+		} else {
+			s = p.ReplaceAllString(s, redactedValue)
+		}
+	}
+	return s
+}
+
+// redactingCore wraps a zapcore.Core and masks secrets in messages and fields before they are written,
+// so no individual log call has to remember to sanitize request data
+type redactingCore struct {
+	zapcore.Core
+}
+
+// newRedactingCore wraps core with secret masking
+func newRedactingCore(core zapcore.Core) zapcore.Core {
+	return &redactingCore{Core: core}
+}
+
+// With redacts the fields added to a child logger
+func (c *redactingCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactingCore{Core: c.Core.With(redactFields(fields))}
+}
+
+// Check registers this core, rather than the wrapped one, so Write below is used
+func (c *redactingCore) Check(entry zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return ce.AddCore(entry, c)
+	}
+	return ce
+}
+
+// Write redacts the message and fields, then writes them with the wrapped core
+func (c *redactingCore) Write(entry zapcore.Entry, fields []zapcore.Field) error {
+	entry.Message = RedactSecrets(entry.Message)
+	return c.Core.Write(entry, redactFields(fields))
+}
+
+// redactFields returns fields with secrets masked. Only string-like values are inspected;
+// structured values (objects, arrays) are expected to expose no secrets in their marshalers.
+func redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		switch {
+		case secretFieldKeys[strings.ToLower(f.Key)] && f.Type != zapcore.SkipType:
+			out[i] = zap.String(f.Key, redactedValue)
+		case f.Type == zapcore.StringType:
+			f.String = RedactSecrets(f.String)
+			out[i] = f
+		case f.Type == zapcore.ByteStringType:
+			out[i] = zap.String(f.Key, RedactSecrets(string(f.Interface.([]byte))))
+		case f.Type == zapcore.ErrorType:
+			if err, ok := f.Interface.(error); ok && err != nil {
+				out[i] = zap.String(f.Key, RedactSecrets(err.Error()))
+			} else {
+				out[i] = f
+			}
+		case f.Type == zapcore.StringerType:
+			out[i] = zap.String(f.Key, RedactSecrets(stringerValue(f)))
+		default:
+			out[i] = f
+		}
+	}
+	return out
+}
+
+// stringerValue renders a Stringer field, tolerating nil values the way zap does
+func stringerValue(f zapcore.Field) (s string) {
+	defer func() {
+		if recover() != nil {
+			s = "<nil>"
+		}
+	}()
+	return f.Interface.(interface{ String() string }).String()
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"gotemplate/config"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/apiversion"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIVersion creates a middleware that stores the client's API version, from the Api-Version header or
+// defaultVersion, in the request context, where handlers shape responses with it. It echoes the version
+// applied, and rejects versions that aren't YYYY-MM-DD dates.
+// Requests to the deprecated routes, keyed by "METHOD full path", get a Deprecation header. Every request
+// using a deprecated route or field is reported to usage, with the client that made it.
+func APIVersion(defaultVersion string, deprecatedRoutes map[string]bool, usage apiversion.UsageRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		version := c.GetHeader(apiversion.Header)
+		if version == "" {
+			version = defaultVersion
+		} else if _, err := time.Parse(config.APIVersionLayout, version); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": apiversion.Header + " must be a YYYY-MM-DD date"})
+			return
+		}
+
+		if version != "" {
+			c.Header(apiversion.Header, version)
+		}
+		ctx, used := apiversion.WithUsage(apiversion.WithVersion(c.Request.Context(), version))
+		if route := c.Request.Method + " " + c.FullPath(); deprecatedRoutes[route] {
+			c.Header("Deprecation", "true")
+			apiversion.MarkUsed(ctx, "route:"+route)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		features := used()
+		if len(features) == 0 || usage == nil {
+			return
+		}
+		u := apiversion.Usage{Features: features, UserAgent: c.Request.UserAgent()}
+		if a, ok := actor.FromContext(c.Request.Context()); ok { // Set by AuthMiddleware further down the chain
+			u.UserID, u.Via = a.UserID, a.Via
+		}
+		usage.RecordDeprecatedUsage(u)
+	}
+}
@@ -1,20 +1,57 @@
 package middleware
 
 import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"gotemplate/internal/models"
+	"gotemplate/pkg/actor"
 	"gotemplate/pkg/auth"
 	"gotemplate/pkg/logger"
+	"gotemplate/pkg/session"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin" // Import Gin
 	"go.uber.org/zap"          // Import zap for structured logging
 )
 
-// AuthMiddleware creates a middleware that authenticates requests using JWT
-func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
+// TokenAuthenticator resolves personal access tokens to the actor they act as
+type TokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (actor.Actor, error)
+}
+
+// SessionAuthenticator resolves session cookies to the signed-in user
+type SessionAuthenticator interface {
+	CookieName() string
+	AuthenticateSession(ctx context.Context, id string) (*session.Session, error)
+}
+
+// UserLookup returns the current state of the user a credential was issued to
+type UserLookup interface {
+	CurrentUser(ctx context.Context, userID uint) (*models.User, error) // Nil if the user was deleted or merged away
+}
+
+// csrfHeader carries the CSRF token of a session on mutating requests
+const csrfHeader = "X-CSRF-Token"
+
+// AuthMiddleware creates a middleware that authenticates requests using a JWT or a personal access token.
+// When sessions is set, requests without an Authorization header may use a session cookie instead. A JWT only
+// proves who the user was when it was issued: the user is looked up in users on every request, so tokens of deleted
+// users are refused and a role change applies at once rather than when the token expires.
+func AuthMiddleware(jwtManager *auth.JWTManager, tokens TokenAuthenticator, sessions SessionAuthenticator, users UserLookup) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get the Authorization header from the request
 		authHeader := c.GetHeader("Authorization")
+		if authHeader == "" && sessions != nil {
+			if id, err := c.Cookie(sessions.CookieName()); err == nil && id != "" {
+				authenticateSession(c, sessions, id)
+				return
+			}
+		}
 		if authHeader == "" {
 			logger.Warn("Authorization header missing", zap.String("path", c.Request.URL.Path))
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
@@ -34,6 +71,11 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 		// Extract the token string
 		tokenString := parts[1]
 
+		if auth.IsPersonalToken(tokenString) {
+			authenticatePersonalToken(c, tokens, tokenString)
+			return
+		}
+
 		// Validate the token using the JWTManager
 		claims, err := jwtManager.ValidateToken(tokenString)
 		if err != nil {
@@ -43,11 +85,174 @@ func AuthMiddleware(jwtManager *auth.JWTManager) gin.HandlerFunc {
 			return
 		}
 
-		// If the token is valid, set the UserID in the Gin context for later use
-		c.Set("userID", claims.UserID)
-		logger.Debug("User authenticated", zap.String("userID", claims.UserID))
+		// Tokens carry the user ID as a string, services work with uint IDs
+		userID, err := strconv.ParseUint(claims.UserID, 10, 64)
+		if err != nil {
+			logger.Warn("JWT token carries a malformed user ID", zap.Error(err), zap.String("userID", claims.UserID))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		user, err := users.CurrentUser(c.Request.Context(), uint(userID))
+		if err != nil {
+			logger.Error("Failed to look up the user of a JWT", zap.Error(err), zap.Uint64("userID", userID))
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Authentication is temporarily unavailable"})
+			c.Abort()
+			return
+		}
+		if user == nil {
+			logger.Warn("JWT of a deleted user refused", zap.Uint64("userID", userID))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+
+		// If the token is valid, attach the actor to the request context for handlers, services and repositories,
+		// with the user's current role rather than the one in the token
+		a := actor.NewUser(user.ID, user.Role, actor.ViaJWT)
+		if claims.AuthTime != nil {
+			a.AuthTime = claims.AuthTime.Time
+		}
+		c.Request = c.Request.WithContext(actor.WithActor(c.Request.Context(), a))
+		logger.Debug("User authenticated", zap.Object("actor", a))
 
 		// Continue to the next handler in the chain
 		c.Next()
 	}
 }
+
+// authenticatePersonalToken authenticates a request made with a personal access token.
+// Its scopes are checked by RequireScope.
+func authenticatePersonalToken(c *gin.Context, tokens TokenAuthenticator, tokenString string) {
+	a, err := tokens.Authenticate(c.Request.Context(), tokenString)
+	if err != nil {
+		logger.Warn("Personal access token authentication failed", zap.Error(err))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	c.Request = c.Request.WithContext(actor.WithActor(c.Request.Context(), a))
+	logger.Debug("User authenticated", zap.Object("actor", a))
+	c.Next()
+}
+
+// authenticateSession authenticates a request made with a session cookie. Browsers attach cookies to
+// cross-site requests too, so mutating requests must also carry the session's CSRF token.
+func authenticateSession(c *gin.Context, sessions SessionAuthenticator, id string) {
+	sess, err := sessions.AuthenticateSession(c.Request.Context(), id)
+	if err != nil && !errors.Is(err, session.ErrNotFound) {
+		logger.Error("Session authentication unavailable", zap.Error(err))
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Authentication is temporarily unavailable"})
+		c.Abort()
+		return
+	}
+	if err != nil {
+		logger.Warn("Session authentication failed", zap.Error(err))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session"})
+		c.Abort()
+		return
+	}
+
+	if RequiredScope(c.Request.Method) == auth.ScopeWrite &&
+		subtle.ConstantTimeCompare([]byte(c.GetHeader(csrfHeader)), []byte(sess.CSRFToken)) != 1 {
+		logger.Warn("Forbidden: missing or invalid CSRF token", zap.Uint("userID", sess.UserID), zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusForbidden, gin.H{"error": "Missing or invalid " + csrfHeader + " header"})
+		c.Abort()
+		return
+	}
+
+	a := actor.NewUser(sess.UserID, sess.Role, actor.ViaSession)
+	a.AuthTime = sess.AuthTime
+	c.Request = c.Request.WithContext(actor.WithActor(c.Request.Context(), a))
+	logger.Debug("User authenticated", zap.Object("actor", a))
+	c.Next()
+}
+
+// RequireScope creates a middleware that only lets through actors whose credentials allow scope. Only personal
+// access tokens are scoped; other credentials allow everything. It must run after AuthMiddleware.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a, ok := actor.FromContext(c.Request.Context())
+		if !ok {
+			logger.Error("RequireScope used without AuthMiddleware", zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+			c.Abort()
+			return
+		}
+
+		if !a.HasScope(scope) {
+			logger.Warn("Forbidden: personal access token lacks scope", zap.Object("actor", a), zap.String("scope", scope), zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the " + scope + " scope"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequiredScope returns the scope a personal access token needs for a request method, unless the route sets one
+func RequiredScope(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return auth.ScopeRead
+	}
+	return auth.ScopeWrite
+}
+
+// RequireRole creates a middleware that only lets through actors with one of the given roles.
+// It must run after AuthMiddleware.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a, ok := actor.FromContext(c.Request.Context())
+		if !ok {
+			logger.Error("RequireRole used without AuthMiddleware", zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+			c.Abort()
+			return
+		}
+
+		for _, role := range roles {
+			if a.Role == role {
+				if role == models.RoleAdmin && !a.HasScope(auth.ScopeAdmin) {
+					logger.Warn("Forbidden: personal access token lacks admin scope", zap.Object("actor", a), zap.String("path", c.Request.URL.Path))
+					c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing the admin scope"})
+					c.Abort()
+					return
+				}
+				c.Next()
+				return
+			}
+		}
+
+		logger.Warn("Forbidden: insufficient role", zap.Object("actor", a), zap.Strings("requiredRoles", roles), zap.String("path", c.Request.URL.Path))
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions"})
+		c.Abort()
+	}
+}
+
+// RequireRecentAuth creates a middleware for sensitive endpoints that only lets through actors who proved
+// their credentials within maxAge. Others get a 401 telling the client to re-authenticate (RFC 9470).
+// It must run after AuthMiddleware.
+func RequireRecentAuth(maxAge time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		a, ok := actor.FromContext(c.Request.Context())
+		if !ok {
+			logger.Error("RequireRecentAuth used without AuthMiddleware", zap.String("path", c.Request.URL.Path))
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Authentication error"})
+			c.Abort()
+			return
+		}
+
+		if a.AuthTime.IsZero() || time.Since(a.AuthTime) > maxAge {
+			logger.Warn("Step-up authentication required", zap.Object("actor", a), zap.Time("authTime", a.AuthTime), zap.String("path", c.Request.URL.Path))
+			c.Header("WWW-Authenticate", fmt.Sprintf(`Bearer error="insufficient_user_authentication", max_age=%d`, int(maxAge.Seconds())))
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Recent authentication required, please re-authenticate"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
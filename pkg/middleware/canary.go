@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"gotemplate/config"
+	"gotemplate/pkg/actor"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CanaryHeader lets a client force a variant ("candidate" or "stable") of a rollout, e.g. for testing the
+// candidate before any users are routed to it. Responses carry the variant that served them in the same header.
+const CanaryHeader = "X-Canary"
+
+// Rollout variants
+const (
+	CanaryStable    = "stable"
+	CanaryCandidate = "candidate"
+)
+
+// Rollout returns a function that splits a route between two handler implementations. The configured
+// percentage of users of each rollout gets the candidate; the split hashes the effective user ID, so a user
+// keeps seeing the same variant while the percentage doesn't shrink. Unauthenticated requests get the stable
+// handler. Rollouts missing from the config send nobody to the candidate.
+func Rollout(rollouts []config.CanaryRollout) func(name string, stable, candidate gin.HandlerFunc) gin.HandlerFunc {
+	percents := make(map[string]float64, len(rollouts))
+	for _, r := range rollouts {
+		percents[r.Name] = r.Percent
+	}
+
+	return func(name string, stable, candidate gin.HandlerFunc) gin.HandlerFunc {
+		percent := percents[name]
+		return func(c *gin.Context) {
+			variant := canaryVariant(c, name, percent)
+			c.Header(CanaryHeader, variant)
+			if variant == CanaryCandidate {
+				candidate(c)
+				return
+			}
+			stable(c)
+		}
+	}
+}
+
+// canaryVariant picks the variant of a rollout serving the request
+func canaryVariant(c *gin.Context, name string, percent float64) string {
+	switch strings.ToLower(c.GetHeader(CanaryHeader)) {
+	case CanaryCandidate:
+		return CanaryCandidate
+	case CanaryStable:
+		return CanaryStable
+	}
+
+	a, ok := actor.FromContext(c.Request.Context())
+	if !ok || percent <= 0 {
+		return CanaryStable
+	}
+	// Hash the rollout name too, so each rollout picks a different set of users
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(name + ":" + strconv.FormatUint(uint64(a.EffectiveUserID), 10)))
+	if float64(h.Sum32()%10000) < percent*100 {
+		return CanaryCandidate
+	}
+	return CanaryStable
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"gotemplate/config"
+	"gotemplate/pkg/logger"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ChaosHeader names the faults injected into a response, so clients and logs can tell them from real failures
+const ChaosHeader = "X-Chaos-Injected"
+
+// Chaos creates a middleware that injects latency, errors or dropped connections according to the rules.
+// It is meant for staging only and must run after the routes are resolved, i.e. as router middleware.
+func Chaos(rules []config.ChaosRule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Request.Method + " " + c.FullPath()
+		for _, rule := range rules {
+			if rule.Route != "*" && rule.Route != key {
+				continue
+			}
+			if rand.Float64()*100 >= rule.Percent {
+				break
+			}
+			injectFaults(c, rule, key)
+			return
+		}
+		c.Next()
+	}
+}
+
+// injectFaults applies the faults of a rule to the request
+func injectFaults(c *gin.Context, rule config.ChaosRule, route string) {
+	if rule.Latency > 0 {
+		c.Writer.Header().Add(ChaosHeader, "latency")
+		select {
+		case <-time.After(rule.Latency):
+		case <-c.Request.Context().Done():
+		}
+	}
+
+	switch {
+	case rule.Drop:
+		logger.Debug("Chaos: dropping connection", zap.String("route", route))
+		conn, _, err := c.Writer.Hijack()
+		if err != nil {
+			// Connections that can't be hijacked (e.g. HTTP/2) get an error instead
+			c.Writer.Header().Add(ChaosHeader, "error")
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Injected fault"})
+			return
+		}
+		_ = conn.Close()
+		c.Abort()
+	case rule.ErrorStatus != 0:
+		logger.Debug("Chaos: injecting error", zap.String("route", route), zap.Int("status", rule.ErrorStatus))
+		c.Writer.Header().Add(ChaosHeader, "error")
+		c.AbortWithStatusJSON(rule.ErrorStatus, gin.H{"error": "Injected fault"})
+	default:
+		c.Next()
+	}
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gotemplate/config"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/clientip"
+	"gotemplate/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// costBucket is the budget left to one caller, as of updated
+type costBucket struct {
+	left    float64
+	updated time.Time
+}
+
+// costChargedKey holds, in the gin context, the cost charged to the request, for CountUsage
+const costChargedKey = "middleware.costCharged"
+
+// costLimiter holds the budgets of the callers of this instance
+type costLimiter struct {
+	budget    float64
+	perSecond float64 // Budget refilled per second
+	dryRun    bool
+
+	mu        sync.Mutex
+	buckets   map[string]*costBucket
+	lastSweep time.Time
+}
+
+// CostLimit returns the middleware of a route costing cost, deducted from a budget per user, or per client IP
+// on public routes, which refills continuously over the configured window. Requests over budget get a 429 with
+// Retry-After, or are only logged in dry-run mode. A cost above the whole budget needs the budget full.
+// Budgets are per instance. It returns nil when no budget is configured. The middleware must run after
+// authentication on authenticated routes.
+func CostLimit(cfg config.CostLimitConfig) func(cost int) gin.HandlerFunc {
+	if cfg.Budget <= 0 {
+		return nil
+	}
+	l := &costLimiter{
+		budget:    float64(cfg.Budget),
+		perSecond: float64(cfg.Budget) / cfg.Window.Seconds(),
+		dryRun:    cfg.DryRun,
+		buckets:   map[string]*costBucket{},
+		lastSweep: time.Now(),
+	}
+	return func(cost int) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			key := "ip:" + clientip.FromContext(c.Request.Context())
+			if a, ok := actor.FromContext(c.Request.Context()); ok {
+				key = "user:" + strconv.FormatUint(uint64(a.UserID), 10) // The acting user, who sends the requests
+			}
+
+			wait, ok := l.spend(key, float64(cost), time.Now())
+			if ok {
+				c.Set(costChargedKey, cost)
+				c.Next()
+				return
+			}
+			logger.Warn("Request over its cost budget", zap.String("caller", key), zap.String("route", c.Request.Method+" "+c.FullPath()),
+				zap.Int("cost", cost), zap.Bool("dryRun", l.dryRun))
+			if l.dryRun {
+				c.Next()
+				return
+			}
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many expensive requests, try again later"})
+		}
+	}
+}
+
+// spend deducts cost from the budget of key if enough is left, and otherwise returns how long until it is
+func (l *costLimiter) spend(key string, cost float64, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &costBucket{left: l.budget, updated: now}
+		l.buckets[key] = b
+	}
+	b.left = math.Min(l.budget, b.left+now.Sub(b.updated).Seconds()*l.perSecond)
+	b.updated = now
+
+	need := math.Min(cost, l.budget)
+	if b.left < need {
+		return time.Duration((need - b.left) / l.perSecond * float64(time.Second)), false
+	}
+	b.left -= need
+	return 0, true
+}
+
+// sweep drops, once per the time a budget takes to refill, the buckets refilled since, which a new bucket
+// replaces; callers gone don't accumulate
+func (l *costLimiter) sweep(now time.Time) {
+	refill := time.Duration(l.budget / l.perSecond * float64(time.Second))
+	if now.Sub(l.lastSweep) < refill {
+		return
+	}
+	l.lastSweep = now
+	for key, b := range l.buckets {
+		if b.left+now.Sub(b.updated).Seconds()*l.perSecond >= l.budget {
+			delete(l.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestTimeoutHeader lets a client ask for a shorter (or, up to max, longer) request budget.
+// The value is a Go duration ("1500ms", "2s") or a plain number of milliseconds.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// RequestDeadline bounds every request by a total time budget carried as the request context deadline.
+// The budget defaults to defaultBudget and can be changed per request with X-Request-Timeout, capped at max.
+func RequestDeadline(defaultBudget, max time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		budget := defaultBudget
+		if v := c.GetHeader(RequestTimeoutHeader); v != "" {
+			requested, err := parseRequestTimeout(v)
+			if err != nil || requested <= 0 {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid " + RequestTimeoutHeader + " header"})
+				return
+			}
+			budget = requested
+		}
+		if max > 0 && budget > max {
+			budget = max
+		}
+		if budget <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), budget)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// parseRequestTimeout accepts a Go duration or a number of milliseconds
+func parseRequestTimeout(v string) (time.Duration, error) {
+	if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	return time.ParseDuration(v)
+}
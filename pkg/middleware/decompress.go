@@ -0,0 +1,40 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DecompressRequest decodes request bodies sent with Content-Encoding: gzip, so handlers read them as
+// plain streams. The decoded body is capped at maxSize bytes to defuse compression bombs; reading past
+// it fails with *http.MaxBytesError. Other encodings are rejected with 415.
+func DecompressRequest(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		switch encoding {
+		case "", "identity":
+			c.Next()
+			return
+		case "gzip", "x-gzip":
+		default:
+			c.AbortWithStatusJSON(http.StatusUnsupportedMediaType, gin.H{"error": "Unsupported Content-Encoding " + encoding + ", only gzip is accepted"})
+			return
+		}
+
+		gz, err := gzip.NewReader(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Request body is not valid gzip"})
+			return
+		}
+		defer gz.Close()
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, gz, maxSize)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1 // Decoded length is unknown until the body is read
+		c.Next()
+	}
+}
@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"gotemplate/config"
+	"gotemplate/pkg/clientip"
+	"gotemplate/pkg/geoip"
+	"gotemplate/pkg/logger"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// CountryRestriction creates a middleware that applies the deployment's country rules (see config.GeoIPConfig)
+// to the client country found by GeoIP: requests from blocked countries are rejected with 451 Unavailable For
+// Legal Reasons, requests from flagged countries are served but logged for review. Requests of unknown origin
+// pass. exempt lists routes that stay available, keyed by "METHOD full path".
+// It must run after the routes are resolved, i.e. as router middleware.
+func CountryRestriction(rules config.GeoIPConfig, exempt map[string]bool) gin.HandlerFunc {
+	blockedSet, flaggedSet := countrySet(rules.BlockedCountries), countrySet(rules.FlaggedCountries)
+	return func(c *gin.Context) {
+		country := geoip.FromContext(c.Request.Context())
+		if country == "" || exempt[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		if blockedSet[country] {
+			logger.Info("Rejected request from a blocked country", zap.String("country", country), zap.String("ip", clientip.FromContext(c.Request.Context())),
+				zap.String("method", c.Request.Method), zap.String("route", RouteLabel(c)))
+			c.AbortWithStatusJSON(http.StatusUnavailableForLegalReasons, gin.H{
+				"error":   "This service is not available in your country",
+				"country": country,
+			})
+			return
+		}
+		if flaggedSet[country] {
+			logger.Warn("Request from a flagged country", zap.String("country", country), zap.String("ip", clientip.FromContext(c.Request.Context())),
+				zap.String("method", c.Request.Method), zap.String("route", RouteLabel(c)))
+		}
+		c.Next()
+	}
+}
+
+// countrySet indexes country codes, upper-cased like the codes GeoIP returns
+func countrySet(countries []string) map[string]bool {
+	set := make(map[string]bool, len(countries))
+	for _, country := range countries {
+		set[strings.ToUpper(strings.TrimSpace(country))] = true
+	}
+	return set
+}
@@ -0,0 +1,19 @@
+package middleware
+
+import (
+	"gotemplate/pkg/clientip"
+	"gotemplate/pkg/geoip"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GeoIP creates a middleware that looks up the country of the client IP resolved by RealIP and stores it in
+// the request context, for logs and auditing. A nil database stores nothing.
+func GeoIP(db *geoip.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if country := db.Country(clientip.FromContext(c.Request.Context())); country != "" {
+			c.Request = c.Request.WithContext(geoip.WithCountry(c.Request.Context(), country))
+		}
+		c.Next()
+	}
+}
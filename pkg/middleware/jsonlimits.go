@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"gotemplate/config"
+	"gotemplate/pkg/jsonbody"
+
+	"github.com/gin-gonic/gin"
+)
+
+// JSONLimits creates a middleware setting the limits JSON request bodies are decoded with, see jsonbody.Decode.
+// Routes are matched like chaos rules, so it must run as router middleware.
+func JSONLimits(cfg config.ServerConfig) gin.HandlerFunc {
+	strict, all := map[string]bool{}, false
+	for _, route := range cfg.StrictJSONRoutes {
+		all = all || route == "*"
+		strict[route] = true
+	}
+	return func(c *gin.Context) {
+		limits := jsonbody.Limits{
+			MaxSize:               cfg.MaxJSONBodySize,
+			MaxDepth:              cfg.MaxJSONDepth,
+			DisallowUnknownFields: all || strict[c.Request.Method+" "+c.FullPath()],
+		}
+		c.Request = c.Request.WithContext(jsonbody.WithLimits(c.Request.Context(), limits))
+		c.Next()
+	}
+}
+
+// StrictJSON refuses JSON bodies holding fields the payload doesn't have, for routes declared with
+// module.Route.StrictJSON. It must run after JSONLimits.
+func StrictJSON(c *gin.Context) {
+	limits := jsonbody.LimitsFrom(c.Request.Context())
+	limits.DisallowUnknownFields = true
+	c.Request = c.Request.WithContext(jsonbody.WithLimits(c.Request.Context(), limits))
+	c.Next()
+}
@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"bytes"
+	"gotemplate/pkg/clientip"
+	"gotemplate/pkg/geoip"
 	"gotemplate/pkg/logger"
+	"gotemplate/pkg/requestid"
 	"io/ioutil"
 	"time"
 
@@ -10,14 +13,30 @@ import (
 	"go.uber.org/zap"
 )
 
+// maxLoggedBodySize is the largest request body the logger buffers and logs
+const maxLoggedBodySize = 64 << 10 // 64 KiB
+
+// UnmatchedRoute labels requests that matched no route, so scans of random paths collapse into one label
+const UnmatchedRoute = "unmatched"
+
+// RouteLabel returns the route template of the request ("/api/v1/products/:id") rather than its concrete path,
+// keeping the set of labels bounded for logs and metrics
+func RouteLabel(c *gin.Context) string {
+	if route := c.FullPath(); route != "" {
+		return route
+	}
+	return UnmatchedRoute
+}
+
 // structuredLogger logs HTTP requests with Zap
-func StructuredLogger() gin.HandlerFunc {
+func StructuredLogger(logCountry bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		start := time.Now() // Start time of the request
 
-		// Read the request body to log it, then put it back for the next handlers
+		// Read the request body to log it, then put it back for the next handlers.
+		// Streamed, compressed and large bodies are left alone so uploads aren't buffered in memory.
 		var bodyBytes []byte
-		if c.Request.Body != nil {
+		if c.Request.Body != nil && c.Request.ContentLength > 0 && c.Request.ContentLength <= maxLoggedBodySize && c.GetHeader("Content-Encoding") == "" {
 			bodyBytes, _ = ioutil.ReadAll(c.Request.Body)
 			// Restore the io.ReadCloser to its original state
 			c.Request.Body = ioutil.NopCloser(bytes.NewBuffer(bodyBytes))
@@ -30,19 +49,28 @@ func StructuredLogger() gin.HandlerFunc {
 		duration := time.Since(start)      // Duration of the request
 		status := c.Writer.Status()        // HTTP status code of the response
 		path := c.Request.URL.Path         // Request URL path
+		route := RouteLabel(c)             // Route template, for aggregating per endpoint
 		method := c.Request.Method         // HTTP method
 		clientIP := c.ClientIP()           // Client IP address
 		userAgent := c.Request.UserAgent() // User-Agent header
 		responseSize := c.Writer.Size()    // Response body size
+		if ip := clientip.FromContext(c.Request.Context()); ip != "" {
+			clientIP = ip // Resolved by RealIP, which also reads the Forwarded header
+		}
 
 		fields := []zap.Field{
 			zap.String("method", method),
 			zap.String("path", path),
+			zap.String("route", route),
 			zap.Int("status", status),
 			zap.Duration("duration", duration),
 			zap.String("ip", clientIP),
 			zap.String("user_agent", userAgent),
 			zap.Int("response_size", responseSize),
+			zap.String("request_id", requestid.FromContext(c.Request.Context())),
+		}
+		if country := geoip.FromContext(c.Request.Context()); logCountry && country != "" {
+			fields = append(fields, zap.String("country", country)) // Resolved by GeoIP
 		}
 
 		// Log request body if present
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"gotemplate/pkg/logger"
+	"gotemplate/pkg/readonly"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// readOnlyRetryAfter is the Retry-After hint, in seconds, sent with requests rejected in read-only mode
+const readOnlyRetryAfter = "60"
+
+// ReadOnly creates a middleware that rejects mutating requests with 503 while read-only mode is on.
+// exempt lists routes that stay available, keyed by "METHOD full path" (e.g. "POST /api/v1/login").
+// It must run after the routes are resolved, i.e. as router middleware.
+func ReadOnly(mode *readonly.Mode, exempt map[string]bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if !mode.Enabled() || exempt[c.Request.Method+" "+c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		logger.Debug("Rejected mutating request in read-only mode", zap.String("method", c.Request.Method), zap.String("path", c.Request.URL.Path))
+		c.Header("Retry-After", readOnlyRetryAfter)
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+			"error":  "The service is in read-only mode",
+			"reason": mode.State().Reason,
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"gotemplate/pkg/clientip"
+	"net"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RealIP creates a middleware that resolves the client IP behind the trusted proxies (see clientip.Resolve)
+// and stores it in the request context, for logs, auditing and rate limiting
+func RealIP(trusted []*net.IPNet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ip := clientip.Resolve(c.Request, trusted)
+		c.Request = c.Request.WithContext(clientip.WithIP(c.Request.Context(), ip))
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"gotemplate/pkg/requestid"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestID gives every request an ID, reusing a valid X-Request-ID from the client or proxy, and echoes it in the
+// response. The ID and the route are put in the request context for logs and query tags.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(requestid.Header)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		c.Header(requestid.Header, id)
+
+		ctx := requestid.WithID(c.Request.Context(), id)
+		ctx = requestid.WithRoute(ctx, c.Request.Method+" "+RouteLabel(c))
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
@@ -0,0 +1,234 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"gotemplate/config"
+	"gotemplate/pkg/actor"
+	"gotemplate/pkg/logger"
+	"math/rand"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// maxShadowBody bounds the bytes of each response kept for comparison
+const maxShadowBody = 1 << 20
+
+// shadowResponse is what the comparison of a mirrored request looks at
+type shadowResponse struct {
+	status    int
+	body      []byte
+	truncated bool // The body exceeded maxShadowBody, only its beginning was kept
+	elapsed   time.Duration
+}
+
+// Shadow returns a function that mirrors the read traffic of a route to a candidate implementation, to de-risk
+// swapping a backend (e.g. search moving to Elasticsearch). The primary handler serves every request; for the
+// configured percentage of GET requests of each experiment, the candidate then runs in the background on a copy
+// of the request, and its response is compared with the primary's. Differences are logged, clients never see the
+// candidate's response, so candidates must not have side effects. Experiments missing from the config mirror nothing.
+func Shadow(cfg config.ShadowConfig) func(name string, primary, candidate gin.HandlerFunc) gin.HandlerFunc {
+	percents := make(map[string]float64, len(cfg.Experiments))
+	for _, e := range cfg.Experiments {
+		percents[e.Name] = e.Percent
+	}
+	engine := gin.New() // Owns the contexts candidates run in, apart from the serving router
+	slots := make(chan struct{}, cfg.MaxInFlight)
+
+	return func(name string, primary, candidate gin.HandlerFunc) gin.HandlerFunc {
+		percent := percents[name]
+		return func(c *gin.Context) {
+			if percent <= 0 || c.Request.Method != http.MethodGet || rand.Float64()*100 >= percent {
+				primary(c)
+				return
+			}
+
+			// The copy outlives the request: it keeps its values (e.g. the actor) but not its cancellation
+			req := c.Request.Clone(context.WithoutCancel(c.Request.Context()))
+			params := append(gin.Params(nil), c.Params...)
+			capture := &shadowCapture{ResponseWriter: c.Writer}
+			c.Writer = capture
+			start := time.Now()
+			primary(c)
+			c.Writer = capture.ResponseWriter
+			served := &shadowResponse{status: capture.Status(), body: capture.body.Bytes(), truncated: capture.truncated, elapsed: time.Since(start)}
+
+			select {
+			case slots <- struct{}{}:
+			default:
+				logger.Debug("Shadow request skipped, too many in flight", zap.String("experiment", name))
+				return
+			}
+			route := c.FullPath()
+			go func() {
+				defer func() { <-slots }()
+				runShadow(engine, cfg.Timeout, name, route, req, params, candidate, served)
+			}()
+		}
+	}
+}
+
+// runShadow runs the candidate of an experiment on a mirrored request and logs how its response compares
+func runShadow(engine *gin.Engine, timeout time.Duration, name, route string, req *http.Request, params gin.Params, candidate gin.HandlerFunc, served *shadowResponse) {
+	fields := []zap.Field{zap.String("experiment", name), zap.String("route", route), actor.Field(req.Context())}
+	defer func() {
+		if r := recover(); r != nil {
+			logger.Error("Shadow candidate panicked", append(fields, zap.Any("panic", r))...)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(req.Context(), timeout)
+	defer cancel()
+	recorder := &shadowRecorder{header: http.Header{}}
+	c := gin.CreateTestContextOnly(recorder, engine)
+	c.Request = req.WithContext(ctx)
+	c.Params = params
+
+	start := time.Now()
+	candidate(c)
+	mirrored := &shadowResponse{status: c.Writer.Status(), body: recorder.body.Bytes(), truncated: recorder.truncated, elapsed: time.Since(start)}
+
+	fields = append(fields,
+		zap.Int("primaryStatus", served.status), zap.Int("candidateStatus", mirrored.status),
+		zap.Duration("primaryLatency", served.elapsed), zap.Duration("candidateLatency", mirrored.elapsed))
+	if ctx.Err() != nil {
+		logger.Warn("Shadow candidate timed out", fields...)
+		return
+	}
+	if diff := shadowDiff(served, mirrored); diff != "" {
+		logger.Warn("Shadow response differs", append(fields, zap.String("diff", diff))...)
+		return
+	}
+	logger.Debug("Shadow response matches", fields...)
+}
+
+// shadowDiff describes the first difference between the primary's and the candidate's response, or returns "".
+// JSON bodies are compared by value, so key order and formatting don't count; only paths are reported, never
+// values, which may be personal data.
+func shadowDiff(primary, candidate *shadowResponse) string {
+	if primary.status != candidate.status {
+		return fmt.Sprintf("status %d != %d", primary.status, candidate.status)
+	}
+	if bytes.Equal(primary.body, candidate.body) {
+		return ""
+	}
+	if primary.truncated || candidate.truncated {
+		return "body differs (compared the first bytes only)"
+	}
+	var a, b interface{}
+	if json.Unmarshal(primary.body, &a) != nil || json.Unmarshal(candidate.body, &b) != nil {
+		return "body differs"
+	}
+	return jsonDiff(a, b, "$")
+}
+
+// jsonDiff returns the path of the first difference between two decoded JSON values, or ""
+func jsonDiff(a, b interface{}, path string) string {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok {
+			return path + ": type differs"
+		}
+		keys := make([]string, 0, len(a)+len(b))
+		for k := range a {
+			keys = append(keys, k)
+		}
+		for k := range b {
+			if _, ok := a[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			av, inA := a[k]
+			bv, inB := b[k]
+			switch {
+			case !inA:
+				return path + "." + k + ": only in candidate"
+			case !inB:
+				return path + "." + k + ": only in primary"
+			}
+			if diff := jsonDiff(av, bv, path+"."+k); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok {
+			return path + ": type differs"
+		}
+		if len(a) != len(b) {
+			return fmt.Sprintf("%s: %d items != %d", path, len(a), len(b))
+		}
+		for i := range a {
+			if diff := jsonDiff(a[i], b[i], fmt.Sprintf("%s[%d]", path, i)); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	default:
+		if a != b {
+			return path + ": value differs"
+		}
+		return ""
+	}
+}
+
+// shadowCapture keeps a copy of the primary's response body while it is written to the client
+type shadowCapture struct {
+	gin.ResponseWriter
+	body      bytes.Buffer
+	truncated bool
+}
+
+// Write writes to the client and keeps a copy
+func (w *shadowCapture) Write(data []byte) (int, error) {
+	w.truncated = keepShadowBody(&w.body, data) || w.truncated
+	return w.ResponseWriter.Write(data)
+}
+
+// WriteString writes to the client and keeps a copy
+func (w *shadowCapture) WriteString(s string) (int, error) {
+	w.truncated = keepShadowBody(&w.body, []byte(s)) || w.truncated
+	return w.ResponseWriter.WriteString(s)
+}
+
+// shadowRecorder is the response writer of a candidate, nothing reaches the client
+type shadowRecorder struct {
+	header    http.Header
+	body      bytes.Buffer
+	truncated bool
+}
+
+// Header returns the candidate's response headers
+func (w *shadowRecorder) Header() http.Header {
+	return w.header
+}
+
+// Write keeps the candidate's response body
+func (w *shadowRecorder) Write(data []byte) (int, error) {
+	w.truncated = keepShadowBody(&w.body, data) || w.truncated
+	return len(data), nil
+}
+
+// WriteHeader ignores the status, which the gin writer wrapping the recorder keeps
+func (w *shadowRecorder) WriteHeader(int) {}
+
+// keepShadowBody appends data to body up to maxShadowBody and reports whether some was dropped
+func keepShadowBody(body *bytes.Buffer, data []byte) bool {
+	room := maxShadowBody - body.Len()
+	if len(data) <= room {
+		body.Write(data)
+		return false
+	}
+	body.Write(data[:room])
+	return true
+}
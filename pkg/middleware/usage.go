@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"gotemplate/pkg/actor"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageRecorder counts the requests of authenticated callers, see service.APIUsageService
+type UsageRecorder interface {
+	// RecordRequest counts a request of a, answered with status, and the cost charged to its budget
+	RecordRequest(a actor.Actor, status, cost int)
+}
+
+// CountUsage returns middleware counting each request once it is answered, with the cost the cost limit
+// charged to it. It must run after authentication and before the checks that may refuse the request, so refused
+// requests are counted too. It returns nil when recorder is nil.
+func CountUsage(recorder UsageRecorder) gin.HandlerFunc {
+	if recorder == nil {
+		return nil
+	}
+	return func(c *gin.Context) {
+		c.Next()
+		if a, ok := actor.FromContext(c.Request.Context()); ok {
+			recorder.RecordRequest(a, c.Writer.Status(), c.GetInt(costChargedKey))
+		}
+	}
+}
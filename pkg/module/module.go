@@ -0,0 +1,114 @@
+// Package module defines how a feature plugs into the application: its routes,
+// the models it migrates and the background workers it runs. Adding a feature means
+// adding one Module to the list in pkg/app; removing it means deleting that entry.
+package module
+
+import (
+	"context"
+	"net/http"
+
+	"gotemplate/pkg/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Routes collects the routes a module declares, all under /api/v1, e.g.
+// r.GET("/products/:id", h.GetProduct).Auth(models.RoleUser). See Route for declaring who may call them.
+type Routes struct {
+	// Rollout splits a route between a stable and a candidate handler for a gradual rollout of a rewrite:
+	// r.GET("/products", r.Rollout("products-list-v2", h.GetProducts, h.GetProductsV2)).Auth(models.RoleUser).
+	// The share of users getting the candidate is set per rollout name in the canary config.
+	Rollout func(name string, stable, candidate gin.HandlerFunc) gin.HandlerFunc
+	// Shadow mirrors read traffic of a route to a candidate implementation and logs where its responses differ,
+	// before any user is routed to it: r.GET("/search", r.Shadow("search-elasticsearch", h.Search, es.Search)).
+	// The share of requests mirrored is set per experiment name in the shadow config.
+	Shadow func(name string, primary, candidate gin.HandlerFunc) gin.HandlerFunc
+
+	routes []*Route
+}
+
+// Handle declares a route; handlers are route-specific middleware, if any, then the handler
+func (r *Routes) Handle(method, path string, handlers ...gin.HandlerFunc) *Route {
+	rt := &Route{Method: method, Path: path, Handlers: handlers}
+	r.routes = append(r.routes, rt)
+	return rt
+}
+
+// GET declares a GET route
+func (r *Routes) GET(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.Handle(http.MethodGet, path, handlers...)
+}
+
+// POST declares a POST route
+func (r *Routes) POST(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.Handle(http.MethodPost, path, handlers...)
+}
+
+// PUT declares a PUT route
+func (r *Routes) PUT(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.Handle(http.MethodPut, path, handlers...)
+}
+
+// PATCH declares a PATCH route
+func (r *Routes) PATCH(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.Handle(http.MethodPatch, path, handlers...)
+}
+
+// DELETE declares a DELETE route
+func (r *Routes) DELETE(path string, handlers ...gin.HandlerFunc) *Route {
+	return r.Handle(http.MethodDelete, path, handlers...)
+}
+
+// Declared returns the routes declared so far, in declaration order
+func (r *Routes) Declared() []*Route {
+	return r.routes
+}
+
+// Worker is a background job. It must return once ctx is cancelled.
+type Worker func(ctx context.Context)
+
+// Module is a self-contained feature
+type Module interface {
+	Name() string
+	RegisterRoutes(r *Routes)
+	Migrations() []interface{}              // Models to auto-migrate, in dependency order
+	SchemaMigrations() []database.Migration // Versioned changes applied after auto-migration, e.g. indexes
+	Workers() []Worker
+}
+
+// Definition is a Module assembled from plain values, for features that need no type of their own.
+// Nil fields mean the module has nothing of that kind.
+type Definition struct {
+	ModuleName string
+	Routes     func(r *Routes)
+	Models     []interface{}
+	Schema     []database.Migration
+	Jobs       []Worker
+}
+
+// Name returns the module name
+func (d *Definition) Name() string {
+	return d.ModuleName
+}
+
+// RegisterRoutes registers the module's routes, if any
+func (d *Definition) RegisterRoutes(r *Routes) {
+	if d.Routes != nil {
+		d.Routes(r)
+	}
+}
+
+// Migrations returns the module's models
+func (d *Definition) Migrations() []interface{} {
+	return d.Models
+}
+
+// SchemaMigrations returns the module's versioned schema changes
+func (d *Definition) SchemaMigrations() []database.Migration {
+	return d.Schema
+}
+
+// Workers returns the module's background workers
+func (d *Definition) Workers() []Worker {
+	return d.Jobs
+}
@@ -0,0 +1,169 @@
+// Package qrcode encodes short texts, typically URLs, as QR codes and renders them as PNG or SVG.
+// It covers what labels need and no more: byte mode, error correction level M (15% of the symbol may be damaged)
+// and versions 1 to 10, i.e. up to 213 bytes.
+package qrcode
+
+import (
+	"fmt"
+)
+
+// MaxLength is the longest text that can be encoded
+const MaxLength = 213
+
+// ErrTooLong is returned for texts longer than MaxLength
+var ErrTooLong = fmt.Errorf("text is longer than %d bytes", MaxLength)
+
+// maxVersion is the largest symbol supported, 57x57 modules
+const maxVersion = 10
+
+// eccPerBlock and eccBlocks are the error correction codewords per block and the number of blocks of each
+// version at level M, indexed by version
+var (
+	eccPerBlock = [maxVersion + 1]int{0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26}
+	eccBlocks   = [maxVersion + 1]int{0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5}
+)
+
+// eccLevelBits are the format bits of error correction level M
+const eccLevelBits = 0
+
+// Code is an encoded QR code: a square of modules, true for dark ones, without the quiet zone
+type Code struct {
+	Size    int
+	modules [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (c *Code) Dark(x, y int) bool {
+	return c.modules[y][x]
+}
+
+// Encode encodes text in the smallest symbol that holds it
+func Encode(text string) (*Code, error) {
+	data := []byte(text)
+	if len(data) > MaxLength {
+		return nil, ErrTooLong
+	}
+	version := 1
+	for ; version <= maxVersion; version++ {
+		if headerBits(version)+len(data)*8 <= dataCodewords(version)*8 {
+			break
+		}
+	}
+	if version > maxVersion {
+		return nil, ErrTooLong
+	}
+
+	s := newSymbol(version)
+	s.drawCodewords(addECC(version, dataBits(version, data)))
+
+	// Keep the mask that leaves the fewest patterns confusing readers
+	best, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		s.applyMask(mask)
+		s.drawFormatBits(mask)
+		if p := s.penalty(); bestPenalty < 0 || p < bestPenalty {
+			best, bestPenalty = mask, p
+		}
+		s.applyMask(mask) // Masking twice undoes it
+	}
+	s.applyMask(best)
+	s.drawFormatBits(best)
+	return &Code{Size: s.size, modules: s.modules}, nil
+}
+
+// headerBits is the length of the mode indicator and character count of byte mode in a version
+func headerBits(version int) int {
+	if version < 10 {
+		return 4 + 8
+	}
+	return 4 + 16
+}
+
+// rawCodewords is the number of codewords, data and error correction, a version holds
+func rawCodewords(version int) int {
+	modules := (16*version+128)*version + 64
+	if version >= 2 {
+		align := version/7 + 2
+		modules -= (25*align-10)*align - 55
+		if version >= 7 {
+			modules -= 36
+		}
+	}
+	return modules / 8
+}
+
+// dataCodewords is the number of data codewords a version holds at level M
+func dataCodewords(version int) int {
+	return rawCodewords(version) - eccPerBlock[version]*eccBlocks[version]
+}
+
+// dataBits returns the data codewords of a version: the byte mode segment, terminator and padding
+func dataBits(version int, data []byte) []byte {
+	var bb bitBuffer
+	bb.append(0x4, 4) // Byte mode
+	bb.append(len(data), headerBits(version)-4)
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	capacity := dataCodewords(version) * 8
+	bb.append(0, min(4, capacity-len(bb)))
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	codewords := make([]byte, len(bb)/8)
+	for i, bit := range bb {
+		if bit {
+			codewords[i>>3] |= 1 << (7 - (i & 7))
+		}
+	}
+	return codewords
+}
+
+// addECC splits data into the blocks of a version, appends the error correction codewords of each and
+// interleaves the blocks
+func addECC(version int, data []byte) []byte {
+	numBlocks, blockECC := eccBlocks[version], eccPerBlock[version]
+	raw := rawCodewords(version)
+	numShort := numBlocks - raw%numBlocks
+	shortLen := raw / numBlocks
+
+	divisor := rsDivisor(blockECC)
+	blocks := make([][]byte, numBlocks)
+	k := 0
+	for i := range blocks {
+		n := shortLen - blockECC
+		if i >= numShort {
+			n++
+		}
+		block := append([]byte{}, data[k:k+n]...)
+		k += n
+		ecc := rsRemainder(block, divisor)
+		if i < numShort {
+			block = append(block, 0) // Placeholder keeping blocks aligned, skipped when interleaving
+		}
+		blocks[i] = append(block, ecc...)
+	}
+
+	result := make([]byte, 0, raw)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortLen-blockECC || j >= numShort {
+				result = append(result, block[i])
+			}
+		}
+	}
+	return result
+}
+
+// bitBuffer is a sequence of bits, most significant first
+type bitBuffer []bool
+
+// append appends the n low bits of v
+func (bb *bitBuffer) append(v, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, (v>>i)&1 != 0)
+	}
+}
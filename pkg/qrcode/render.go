@@ -0,0 +1,69 @@
+package qrcode
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strings"
+)
+
+// QuietZone is the light margin around the symbol, in modules, that readers need to find it
+const QuietZone = 4
+
+// PNG renders the code as a black and white PNG with scale pixels per module, quiet zone included
+func (c *Code) PNG(scale int) ([]byte, error) {
+	if scale < 1 {
+		return nil, fmt.Errorf("scale must be at least 1")
+	}
+	side := (c.Size + 2*QuietZone) * scale
+	img := image.NewPaletted(image.Rect(0, 0, side, side), color.Palette{color.White, color.Black})
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			for py := 0; py < scale; py++ {
+				row := (y+QuietZone)*scale + py
+				start := img.PixOffset((x+QuietZone)*scale, row)
+				for px := 0; px < scale; px++ {
+					img.Pix[start+px] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := enc.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode PNG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// SVG renders the code as an SVG whose units are modules, quiet zone included; it scales to any size, e.g. with
+// width and height set where it is placed
+func (c *Code) SVG() []byte {
+	side := c.Size + 2*QuietZone
+	var path strings.Builder
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if !c.Dark(x, y) {
+				continue
+			}
+			run := 1 // Dark modules in a row make one rectangle
+			for x+run < c.Size && c.Dark(x+run, y) {
+				run++
+			}
+			fmt.Fprintf(&path, "M%d,%dh%dv1h-%dz", x+QuietZone, y+QuietZone, run, run)
+			x += run - 1
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, side, side)
+	fmt.Fprintf(&buf, `<rect width="100%%" height="100%%" fill="#fff"/><path fill="#000" d="%s"/></svg>`, path.String())
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
@@ -0,0 +1,265 @@
+package qrcode
+
+// symbol is a QR code being drawn
+type symbol struct {
+	version  int
+	size     int
+	modules  [][]bool // Dark modules, by row then column
+	function [][]bool // Modules of function patterns, which data and masks leave alone
+}
+
+// newSymbol returns a symbol of version with its function patterns drawn and the format area reserved
+func newSymbol(version int) *symbol {
+	size := version*4 + 17
+	s := &symbol{version: version, size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+	for y := range s.modules {
+		s.modules[y] = make([]bool, size)
+		s.function[y] = make([]bool, size)
+	}
+
+	for i := 0; i < size; i++ { // Timing patterns
+		s.set(6, i, i%2 == 0)
+		s.set(i, 6, i%2 == 0)
+	}
+	s.drawFinder(3, 3)
+	s.drawFinder(size-4, 3)
+	s.drawFinder(3, size-4)
+
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue // Overlaps a finder pattern
+			}
+			s.drawAlignment(x, y)
+		}
+	}
+
+	s.drawFormatBits(0) // Reserves the area; redrawn once the mask is chosen
+	s.drawVersion()
+	return s
+}
+
+// set sets the function module at column x, row y
+func (s *symbol) set(x, y int, dark bool) {
+	s.modules[y][x] = dark
+	s.function[y][x] = true
+}
+
+// drawFinder draws the finder pattern centered on x, y with its separator
+func (s *symbol) drawFinder(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= s.size || yy < 0 || yy >= s.size {
+				continue
+			}
+			dist := max(abs(dx), abs(dy))
+			s.set(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+// drawAlignment draws the alignment pattern centered on x, y
+func (s *symbol) drawAlignment(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			s.set(x+dx, y+dy, max(abs(dx), abs(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPositions returns the row and column coordinates of the alignment pattern centers of a version
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	count := version/7 + 2
+	step := (version*8 + count*3 + 5) / (count*4 - 4) * 2
+	positions := make([]int, count)
+	positions[0] = 6
+	for i, pos := count-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// drawFormatBits draws both copies of the format information of mask, and the dark module
+func (s *symbol) drawFormatBits(mask int) {
+	data := eccLevelBits<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+	bits := (data<<10 | rem) ^ 0x5412
+	bit := func(i int) bool { return (bits>>i)&1 != 0 }
+
+	for i := 0; i <= 5; i++ {
+		s.set(8, i, bit(i))
+	}
+	s.set(8, 7, bit(6))
+	s.set(8, 8, bit(7))
+	s.set(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		s.set(14-i, 8, bit(i))
+	}
+
+	for i := 0; i < 8; i++ {
+		s.set(s.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		s.set(8, s.size-15+i, bit(i))
+	}
+	s.set(8, s.size-8, true)
+}
+
+// drawVersion draws both copies of the version information, which versions 7 and up carry
+func (s *symbol) drawVersion() {
+	if s.version < 7 {
+		return
+	}
+	rem := s.version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	bits := s.version<<12 | rem
+	for i := 0; i < 18; i++ {
+		dark := (bits>>i)&1 != 0
+		a, b := s.size-11+i%3, i/3
+		s.set(a, b, dark)
+		s.set(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the zigzag order, two columns at a time from the bottom right
+func (s *symbol) drawCodewords(codewords []byte) {
+	i := 0
+	for right := s.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5 // Skips the vertical timing pattern
+		}
+		for vert := 0; vert < s.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = s.size - 1 - vert // Upward
+				}
+				if !s.function[y][x] && i < len(codewords)*8 {
+					s.modules[y][x] = (codewords[i>>3]>>(7-(i&7)))&1 != 0
+					i++
+				}
+			}
+		}
+	}
+}
+
+// applyMask inverts the data modules selected by mask
+func (s *symbol) applyMask(mask int) {
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			var invert bool
+			switch mask {
+			case 0:
+				invert = (x+y)%2 == 0
+			case 1:
+				invert = y%2 == 0
+			case 2:
+				invert = x%3 == 0
+			case 3:
+				invert = (x+y)%3 == 0
+			case 4:
+				invert = (x/3+y/2)%2 == 0
+			case 5:
+				invert = x*y%2+x*y%3 == 0
+			case 6:
+				invert = (x*y%2+x*y%3)%2 == 0
+			case 7:
+				invert = ((x+y)%2+x*y%3)%2 == 0
+			}
+			if invert && !s.function[y][x] {
+				s.modules[y][x] = !s.modules[y][x]
+			}
+		}
+	}
+}
+
+// finderLike is the 1:1:3:1:1 dark/light ratio of finder patterns, followed by 4 light modules
+var finderLike = []bool{true, false, true, true, true, false, true, false, false, false, false}
+
+// penalty scores the patterns of the symbol that hinder reading, per the four rules of the specification:
+// long runs of one color, 2x2 blocks, finder-like patterns and an unbalanced share of dark modules
+func (s *symbol) penalty() int {
+	total, dark := 0, 0
+	line := make([]bool, s.size)
+	for _, horizontal := range []bool{true, false} {
+		for i := 0; i < s.size; i++ {
+			for j := 0; j < s.size; j++ {
+				if horizontal {
+					line[j] = s.modules[i][j]
+				} else {
+					line[j] = s.modules[j][i]
+				}
+			}
+			total += linePenalty(line)
+		}
+	}
+
+	for y := 0; y < s.size; y++ {
+		for x := 0; x < s.size; x++ {
+			if s.modules[y][x] {
+				dark++
+			}
+			if x < s.size-1 && y < s.size-1 {
+				c := s.modules[y][x]
+				if c == s.modules[y][x+1] && c == s.modules[y+1][x] && c == s.modules[y+1][x+1] {
+					total += 3
+				}
+			}
+		}
+	}
+
+	all := s.size * s.size
+	k := (abs(dark*20-all*10)+all-1)/all - 1
+	return total + k*10
+}
+
+// linePenalty scores the runs and finder-like patterns of one row or column
+func linePenalty(line []bool) int {
+	total := 0
+	run := 1
+	for i := 1; i <= len(line); i++ {
+		if i < len(line) && line[i] == line[i-1] {
+			run++
+			continue
+		}
+		if run >= 5 {
+			total += 3 + run - 5
+		}
+		run = 1
+	}
+
+	for i := 0; i+len(finderLike) <= len(line); i++ {
+		forward, backward := true, true
+		for j, want := range finderLike {
+			forward = forward && line[i+j] == want
+			backward = backward && line[i+len(finderLike)-1-j] == want
+		}
+		if forward {
+			total += 40
+		}
+		if backward {
+			total += 40
+		}
+	}
+	return total
+}
+
+// abs returns the absolute value of v
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,75 @@
+// Package readonly holds the switch that puts an instance into read-only mode, e.g. during a database
+// failover or restore. The HTTP middleware rejects mutating requests while it is on; services and background
+// jobs check it before writes that don't come from such a request.
+package readonly
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrReadOnly is returned by services asked to write while read-only mode is on
+var ErrReadOnly = errors.New("the service is in read-only mode")
+
+// State is a snapshot of the switch
+type State struct {
+	Enabled bool       `json:"enabled"`
+	Reason  string     `json:"reason,omitempty"`
+	Since   *time.Time `json:"since,omitempty"` // When read-only mode was last switched on
+}
+
+// Mode is the read-only switch of one instance. It is not shared between instances, so a fleet is switched
+// by calling every instance (or by restarting it with server.readOnly set).
+type Mode struct {
+	mu    sync.RWMutex
+	state State
+}
+
+// New creates a switch, on if enabled
+func New(enabled bool, reason string) *Mode {
+	m := &Mode{}
+	m.Set(enabled, reason)
+	return m
+}
+
+// Enabled reports whether read-only mode is on. A nil Mode is never enabled.
+func (m *Mode) Enabled() bool {
+	if m == nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state.Enabled
+}
+
+// Check returns ErrReadOnly while read-only mode is on
+func (m *Mode) Check() error {
+	if m.Enabled() {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// State returns a snapshot of the switch
+func (m *Mode) State() State {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.state
+}
+
+// Set switches read-only mode on or off. The reason is shown to rejected clients.
+func (m *Mode) Set(enabled bool, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !enabled {
+		m.state = State{}
+		return
+	}
+	if !m.state.Enabled {
+		now := time.Now()
+		m.state.Since = &now
+	}
+	m.state.Enabled = true
+	m.state.Reason = reason
+}
@@ -0,0 +1,54 @@
+package redact
+
+import (
+	"reflect"
+	"strings"
+)
+
+// Tag is the struct tag listing the roles allowed to see a field, e.g. `visibleTo:"admin"`.
+// Fields without the tag are visible to every role. Combine it with `json:",omitempty"`
+// so redacted fields disappear from responses instead of being sent as zero values.
+const Tag = "visibleTo"
+
+// ForRole zeroes, in place, every field in v that the given role is not allowed to see.
+// v must be a pointer (or a slice of pointers); nested structs, pointers and slices are walked recursively.
+func ForRole(v interface{}, role string) {
+	apply(reflect.ValueOf(v), role)
+}
+
+// apply walks a value and clears role-restricted struct fields
+func apply(v reflect.Value, role string) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			apply(v.Elem(), role)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			apply(v.Index(i), role)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := v.Field(i)
+			if !field.CanSet() {
+				continue // Unexported fields are never serialized, and values passed by copy can't be modified
+			}
+			if roles, ok := t.Field(i).Tag.Lookup(Tag); ok && !allows(roles, role) {
+				field.Set(reflect.Zero(field.Type()))
+				continue
+			}
+			apply(field, role)
+		}
+	}
+}
+
+// allows reports whether role appears in a comma-separated role list
+func allows(roles string, role string) bool {
+	for _, r := range strings.Split(roles, ",") {
+		if strings.TrimSpace(r) == role {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Package requestid identifies the request work is done for: its ID, echoed to the client and written to logs,
+// and the route serving it. Both are carried in the request context down to the database, which tags its
+// queries with them.
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// Header carries the request ID. A client (or proxy) may set it to correlate its own logs; responses echo it.
+const Header = "X-Request-ID"
+
+// maxLength bounds the IDs accepted from clients
+const maxLength = 64
+
+// New returns a random request ID
+func New() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Valid reports whether an ID received from a client can be used: short, and only letters, digits, '-', '_' and '.',
+// so it is safe in headers, logs and SQL comments
+func Valid(id string) bool {
+	if id == "" || len(id) > maxLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// contextKey is unexported to prevent collisions with context keys from other packages
+type contextKey int
+
+const (
+	idKey contextKey = iota
+	routeKey
+)
+
+// WithID returns a copy of ctx carrying the request ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, idKey, id)
+}
+
+// FromContext returns the request ID stored in ctx, or "" outside of a request
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(idKey).(string)
+	return id
+}
+
+// WithRoute returns a copy of ctx carrying the route serving the request, e.g. "GET /api/v1/products/:id"
+func WithRoute(ctx context.Context, route string) context.Context {
+	return context.WithValue(ctx, routeKey, route)
+}
+
+// RouteFromContext returns the route stored in ctx, or "" outside of a request
+func RouteFromContext(ctx context.Context) string {
+	route, _ := ctx.Value(routeKey).(string)
+	return route
+}
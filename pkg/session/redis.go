@@ -0,0 +1,241 @@
+package session
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+)
+
+// redisPoolSize is the number of idle connections kept open to Redis
+const redisPoolSize = 8
+
+// RedisStore keeps sessions in Redis, each under its own key expiring with the session, and indexes them per user
+// in a set. It speaks the handful of RESP commands it needs (AUTH, SELECT, SET, GET, DEL, SADD, SMEMBERS, PEXPIRE)
+// over pooled connections.
+type RedisStore struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *redisConn
+}
+
+// NewRedisStore creates a store on the Redis server at addr ("host:port"). Connections are opened on first use.
+func NewRedisStore(addr, password string, db int, timeout time.Duration) *RedisStore {
+	return &RedisStore{addr: addr, password: password, db: db, timeout: timeout, idle: make(chan *redisConn, redisPoolSize)}
+}
+
+// Ping checks that the server answers and accepts the credentials
+func (s *RedisStore) Ping(ctx context.Context) error {
+	if _, err := s.do(ctx, "PING"); err != nil {
+		return fmt.Errorf("failed to connect to Redis at %s: %w", s.addr, err)
+	}
+	return nil
+}
+
+// Save stores a session until it expires
+func (s *RedisStore) Save(ctx context.Context, id string, sess *Session) error {
+	ttl := time.Until(sess.ExpiresAt).Milliseconds()
+	if ttl <= 0 {
+		return errors.New("session already expired")
+	}
+	encoded, err := json.Marshal(sess)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+	if _, err := s.do(ctx, "SET", storageKey(id), string(encoded), "PX", strconv.FormatInt(ttl, 10)); err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	// Sessions share the configured TTL, so the user's set lives as long as their newest session. Keys of sessions
+	// that ended before are left in it and deleted again, harmlessly, by DeleteUser.
+	if _, err := s.do(ctx, "SADD", userKey(sess.UserID), storageKey(id)); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	if _, err := s.do(ctx, "PEXPIRE", userKey(sess.UserID), strconv.FormatInt(ttl, 10)); err != nil {
+		return fmt.Errorf("failed to index session: %w", err)
+	}
+	return nil
+}
+
+// Get returns a session, or ErrNotFound
+func (s *RedisStore) Get(ctx context.Context, id string) (*Session, error) {
+	reply, err := s.do(ctx, "GET", storageKey(id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+	encoded, ok := reply.(string)
+	if !ok {
+		return nil, ErrNotFound // Nil reply
+	}
+	var sess Session
+	if err := json.Unmarshal([]byte(encoded), &sess); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	return &sess, nil
+}
+
+// Delete removes a session
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	if _, err := s.do(ctx, "DEL", storageKey(id)); err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	return nil
+}
+
+// DeleteUser removes every session of a user
+func (s *RedisStore) DeleteUser(ctx context.Context, userID uint) error {
+	reply, err := s.do(ctx, "SMEMBERS", userKey(userID))
+	if err != nil {
+		return fmt.Errorf("failed to list the user's sessions: %w", err)
+	}
+	members, _ := reply.([]interface{})
+	keys := []string{"DEL", userKey(userID)}
+	for _, member := range members {
+		if key, ok := member.(string); ok {
+			keys = append(keys, key)
+		}
+	}
+	if _, err := s.do(ctx, keys...); err != nil {
+		return fmt.Errorf("failed to delete the user's sessions: %w", err)
+	}
+	return nil
+}
+
+// do runs a command on a pooled connection. Connections that fail are closed rather than returned to the pool.
+func (s *RedisStore) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(s.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	conn.SetDeadline(deadline)
+
+	reply, err := conn.do(args...)
+	var redisErr redisError
+	if err != nil && !errors.As(err, &redisErr) {
+		conn.Close()
+		return nil, err
+	}
+	select {
+	case s.idle <- conn:
+	default:
+		conn.Close()
+	}
+	return reply, err
+}
+
+// conn returns an idle connection, or dials, authenticates and selects the database on a new one
+func (s *RedisStore) conn(ctx context.Context) (*redisConn, error) {
+	select {
+	case c := <-s.idle:
+		return c, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	nc, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, err
+	}
+	c := &redisConn{Conn: nc, r: bufio.NewReader(nc)}
+	c.SetDeadline(time.Now().Add(s.timeout))
+	if s.password != "" {
+		if _, err := c.do("AUTH", s.password); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	if s.db != 0 {
+		if _, err := c.do("SELECT", strconv.Itoa(s.db)); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// redisError is an error reply of the server; the connection stays usable
+type redisError string
+
+func (e redisError) Error() string {
+	return "redis: " + string(e)
+}
+
+// redisConn is a connection speaking RESP
+type redisConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+// do sends a command and reads its reply: a string for simple and bulk strings, int64 for integers, a slice of
+// replies for arrays, nil for nil replies
+func (c *redisConn) do(args ...string) (interface{}, error) {
+	buf := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		buf = append(buf, "$"+strconv.Itoa(len(arg))+"\r\n"...)
+		buf = append(buf, arg...)
+		buf = append(buf, "\r\n"...)
+	}
+	if _, err := c.Write(buf); err != nil {
+		return nil, err
+	}
+	return c.readReply()
+}
+
+// readReply reads one reply, and the elements of an array reply
+func (c *redisConn) readReply() (interface{}, error) {
+	line, err := c.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 {
+		return nil, errors.New("malformed Redis reply")
+	}
+	body := line[1 : len(line)-2]
+	switch line[0] {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, redisError(body)
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, errors.New("malformed Redis reply")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		data := make([]byte, n+2)
+		if _, err := io.ReadFull(c.r, data); err != nil {
+			return nil, err
+		}
+		return string(data[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, errors.New("malformed Redis reply")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		elems := make([]interface{}, n)
+		for i := range elems {
+			if elems[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return elems, nil
+	}
+	return nil, fmt.Errorf("unexpected Redis reply type %q", line[0])
+}
@@ -0,0 +1,56 @@
+// Package session keeps server-side sessions for cookie authentication, for browser apps (HTMX, server-side
+// rendering) that shouldn't hold tokens in JavaScript. Sessions live in a shared store, so every instance
+// recognises them and logging out takes effect immediately.
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ErrNotFound is returned for unknown, expired and deleted sessions
+var ErrNotFound = errors.New("session not found")
+
+// Session is a signed-in browser
+type Session struct {
+	UserID    uint      `json:"userId"`
+	Role      string    `json:"role"`
+	AuthTime  time.Time `json:"authTime"`  // When the password was checked, for step-up authentication
+	CSRFToken string    `json:"csrfToken"` // Must accompany mutating requests, in the X-CSRF-Token header
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// Store keeps sessions by ID until they expire
+type Store interface {
+	Save(ctx context.Context, id string, s *Session) error // Stores s until s.ExpiresAt
+	Get(ctx context.Context, id string) (*Session, error)  // ErrNotFound if it doesn't exist or expired
+	Delete(ctx context.Context, id string) error           // Deleting an unknown session is not an error
+	DeleteUser(ctx context.Context, userID uint) error     // Ends every session of the user, e.g. once deleted
+}
+
+// NewToken returns a random URL-safe token with 256 bits of entropy, for session IDs and CSRF tokens
+func NewToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// storageKey is the key a session is stored under: a hash of its ID, so the store's contents (dumps, MONITOR,
+// slow logs) don't reveal session cookies
+func storageKey(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return "session:" + hex.EncodeToString(sum[:])
+}
+
+// userKey is the key of the set of storage keys of a user's sessions, so they can all be ended at once
+func userKey(userID uint) string {
+	return "user-sessions:" + strconv.FormatUint(uint64(userID), 10)
+}
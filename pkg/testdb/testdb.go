@@ -0,0 +1,195 @@
+// Package testdb keeps integration tests fast by resetting a migrated and seeded database between tests
+// instead of migrating a fresh one each time. Two approaches are supported:
+//
+//   - Snapshot copies every table's rows into a side schema once; Restore truncates the tables and copies
+//     the rows back. It works within one database and suits tests that run one after another.
+//   - CloneDatabase creates a database from a migrated template with CREATE DATABASE ... TEMPLATE, which
+//     gives parallel tests a database each.
+//
+// Both are meant for disposable test databases only.
+package testdb
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// snapshotSchema holds the copies of the rows taken by TakeSnapshot
+const snapshotSchema = "testdb_snapshot"
+
+// keptTables are not snapshotted or truncated
+var keptTables = map[string]bool{"schema_migrations": true}
+
+// Snapshot is a saved copy of the rows of every table in the current schema
+type Snapshot struct {
+	db     *gorm.DB
+	tables []string // Parents before children, so restoring never violates a foreign key
+}
+
+// TakeSnapshot copies the rows of every table in the current schema, typically right after migrating and seeding
+func TakeSnapshot(ctx context.Context, db *gorm.DB) (*Snapshot, error) {
+	db = db.WithContext(ctx)
+	tables, err := tablesInDependencyOrder(db)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		stmts := []string{
+			`DROP SCHEMA IF EXISTS ` + snapshotSchema + ` CASCADE`,
+			`CREATE SCHEMA ` + snapshotSchema,
+		}
+		for _, t := range tables {
+			stmts = append(stmts, fmt.Sprintf(`CREATE TABLE %s.%s AS TABLE %s`, snapshotSchema, quoteIdent(t), quoteIdent(t)))
+		}
+		for _, stmt := range stmts {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to take snapshot: %w", err)
+	}
+	return &Snapshot{db: db, tables: tables}, nil
+}
+
+// Restore resets every snapshotted table to its rows at snapshot time, including the ID sequences
+func (s *Snapshot) Restore(ctx context.Context) error {
+	db := s.db.WithContext(ctx)
+	quoted := make([]string, len(s.tables))
+	for i, t := range s.tables {
+		quoted[i] = quoteIdent(t)
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if len(quoted) > 0 {
+			if err := tx.Exec(`TRUNCATE ` + strings.Join(quoted, ", ") + ` RESTART IDENTITY CASCADE`).Error; err != nil {
+				return err
+			}
+		}
+		for _, t := range quoted {
+			if err := tx.Exec(fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s.%s`, t, snapshotSchema, t)).Error; err != nil {
+				return err
+			}
+		}
+		return resetSequences(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	return nil
+}
+
+// Drop deletes the snapshot's copies
+func (s *Snapshot) Drop(ctx context.Context) error {
+	return s.db.WithContext(ctx).Exec(`DROP SCHEMA IF EXISTS ` + snapshotSchema + ` CASCADE`).Error
+}
+
+// CloneDatabase creates database name as a copy of template. Nothing may be connected to template meanwhile,
+// so migrate and seed it through a connection that is closed before cloning.
+func CloneDatabase(ctx context.Context, db *gorm.DB, template, name string) error {
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf(`CREATE DATABASE %s TEMPLATE %s`, quoteIdent(name), quoteIdent(template))).Error; err != nil {
+		return fmt.Errorf("failed to clone database %s from %s: %w", name, template, err)
+	}
+	return nil
+}
+
+// DropDatabase deletes database name, closing connections other tests may have left open
+func DropDatabase(ctx context.Context, db *gorm.DB, name string) error {
+	if err := db.WithContext(ctx).Exec(fmt.Sprintf(`DROP DATABASE IF EXISTS %s WITH (FORCE)`, quoteIdent(name))).Error; err != nil {
+		return fmt.Errorf("failed to drop database %s: %w", name, err)
+	}
+	return nil
+}
+
+// tablesInDependencyOrder lists the tables of the current schema with referenced tables before the tables referencing them
+func tablesInDependencyOrder(db *gorm.DB) ([]string, error) {
+	var tables []string
+	if err := db.Raw(`SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'`).Scan(&tables).Error; err != nil {
+		return nil, fmt.Errorf("failed to list tables: %w", err)
+	}
+	var refs []struct {
+		Child  string
+		Parent string
+	}
+	err := db.Raw(`SELECT c.relname AS child, p.relname AS parent FROM pg_constraint fk
+		JOIN pg_class c ON c.oid = fk.conrelid
+		JOIN pg_class p ON p.oid = fk.confrelid
+		WHERE fk.contype = 'f' AND fk.connamespace = current_schema()::regnamespace`).Scan(&refs).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list foreign keys: %w", err)
+	}
+
+	parents := map[string][]string{}
+	for _, r := range refs {
+		if r.Child != r.Parent { // Self-references are checked at the end of the statement
+			parents[r.Child] = append(parents[r.Child], r.Parent)
+		}
+	}
+	sort.Strings(tables)
+
+	var ordered []string
+	state := map[string]int{} // 1 while visiting, 2 when placed
+	var visit func(t string) error
+	visit = func(t string) error {
+		switch state[t] {
+		case 1:
+			return fmt.Errorf("foreign keys form a cycle through table %s", t)
+		case 2:
+			return nil
+		}
+		state[t] = 1
+		for _, p := range parents[t] {
+			if err := visit(p); err != nil {
+				return err
+			}
+		}
+		state[t] = 2
+		if !keptTables[t] {
+			ordered = append(ordered, t)
+		}
+		return nil
+	}
+	for _, t := range tables {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// resetSequences moves every serial sequence of the current schema past the highest restored value
+func resetSequences(tx *gorm.DB) error {
+	var columns []struct {
+		TableName  string
+		ColumnName string
+	}
+	err := tx.Raw(`SELECT table_name, column_name FROM information_schema.columns
+		WHERE table_schema = current_schema() AND column_default LIKE 'nextval(%'`).Scan(&columns).Error
+	if err != nil {
+		return err
+	}
+	for _, c := range columns {
+		if keptTables[c.TableName] {
+			continue
+		}
+		t, col := quoteIdent(c.TableName), quoteIdent(c.ColumnName)
+		stmt := fmt.Sprintf(`SELECT setval(pg_get_serial_sequence('%s', '%s'), COALESCE(MAX(%s), 0) + 1, false) FROM %s`,
+			strings.ReplaceAll(t, "'", "''"), strings.ReplaceAll(c.ColumnName, "'", "''"), col, t)
+		if err := tx.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// quoteIdent quotes a Postgres identifier
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,66 @@
+package validation
+
+import (
+	"reflect"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Normalize cleans the string fields of a DTO (a pointer to a struct, or to a slice of them) before it is
+// validated, so visually identical input is stored and looked up the same way. Every string is converted
+// to Unicode NFC and trimmed; nested structs, pointers and slices are walked. The "normalize" tag adjusts
+// a field: normalize:"email" also lower-cases it, normalize:"-" leaves it untouched (passwords, secrets).
+func Normalize(v interface{}) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return // Not addressable, nothing to update in place
+	}
+	normalizeValue(rv.Elem(), "")
+}
+
+// NormalizeString converts s to Unicode NFC and trims surrounding whitespace
+func NormalizeString(s string) string {
+	return strings.TrimSpace(norm.NFC.String(s))
+}
+
+// NormalizeEmail normalizes s like NormalizeString and lower-cases it, so addresses differing only
+// in case map to one account
+func NormalizeEmail(s string) string {
+	return strings.ToLower(NormalizeString(s))
+}
+
+func normalizeValue(v reflect.Value, tag string) {
+	if tag == "-" {
+		return
+	}
+	switch v.Kind() {
+	case reflect.String:
+		if !v.CanSet() {
+			return
+		}
+		if tag == "email" {
+			v.SetString(NormalizeEmail(v.String()))
+		} else {
+			v.SetString(NormalizeString(v.String()))
+		}
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			normalizeValue(v.Elem(), tag)
+		}
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return // Raw bytes, e.g. json.RawMessage
+		}
+		for i := 0; i < v.Len(); i++ {
+			normalizeValue(v.Index(i), tag)
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if f := t.Field(i); f.IsExported() {
+				normalizeValue(v.Field(i), f.Tag.Get("normalize"))
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Package validation applies the rules declared in request DTO binding tags outside of HTTP binding,
+// so services give jobs, CLIs and other non-HTTP callers the same validation as REST requests.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrorPrefix starts the message of every error returned by Struct
+const ErrorPrefix = "validation failed: "
+
+// StructValidator is implemented by DTOs with cross-field rules that tags can't express.
+// Struct calls Validate after the tag rules passed.
+type StructValidator interface {
+	Validate() error
+}
+
+// validate reads the same "binding" tags Gin validates, so the rules are declared once
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	// Report fields by their JSON names, as API clients know them
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name := strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+		if name == "" || name == "-" {
+			return f.Name
+		}
+		return name
+	})
+	return v
+}
+
+// Struct validates a DTO (a struct or pointer to one) against its binding tags and its Validate method.
+// A pointer is normalized first, see Normalize. Errors start with ErrorPrefix and name the first failing field.
+func Struct(v interface{}) error {
+	Normalize(v)
+	if err := validate.Struct(v); err != nil {
+		var fieldErrs validator.ValidationErrors
+		if errors.As(err, &fieldErrs) && len(fieldErrs) > 0 {
+			return errors.New(ErrorPrefix + describe(fieldErrs[0]))
+		}
+		return fmt.Errorf("%s%w", ErrorPrefix, err)
+	}
+	if sv, ok := v.(StructValidator); ok {
+		if err := sv.Validate(); err != nil {
+			return errors.New(ErrorPrefix + err.Error())
+		}
+	}
+	return nil
+}
+
+// Message turns an error from binding or validating a request into a message for API clients
+func Message(err error) string {
+	var fieldErrs validator.ValidationErrors
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &fieldErrs) && len(fieldErrs) > 0:
+		return describe(fieldErrs[0])
+	case errors.Is(err, io.EOF):
+		return "request body is empty"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "request body is not valid JSON"
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type)
+	default:
+		return strings.TrimPrefix(err.Error(), ErrorPrefix)
+	}
+}
+
+// describe turns a failed rule into a message for API clients
+func describe(fe validator.FieldError) string {
+	field := strings.SplitN(fe.Namespace(), ".", 2)
+	name := fe.Field()
+	if len(field) == 2 {
+		name = field[1] // Drop the struct name, keep the path of nested fields
+	}
+	switch fe.Tag() {
+	case "required", "required_without":
+		return name + " is required"
+	case "email":
+		return name + " must be a valid email address"
+	case "min", "gte":
+		return fmt.Sprintf("%s must be at least %s", name, fe.Param())
+	case "max", "lte":
+		return fmt.Sprintf("%s must be at most %s", name, fe.Param())
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", name, fe.Param())
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", name, fe.Param())
+	case "len":
+		return fmt.Sprintf("%s must have length %s", name, fe.Param())
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", name, fe.Param())
+	default:
+		return fmt.Sprintf("%s failed the %s rule", name, fe.Tag())
+	}
+}
+
+// GinValidator makes Gin's binding use the same engine as Struct, so field errors name JSON fields too.
+// Install it with binding.Validator = validation.GinValidator{}.
+type GinValidator struct{}
+
+var _ binding.StructValidator = GinValidator{}
+
+// ValidateStruct normalizes and validates structs, pointers to structs and slices of them; other values are
+// accepted as is. Gin passes the pointer it decoded the request into, so handlers see normalized DTOs.
+func (GinValidator) ValidateStruct(obj interface{}) error {
+	Normalize(obj)
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return validate.Struct(v.Interface())
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := (GinValidator{}).ValidateStruct(v.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Engine returns the underlying validator
+func (GinValidator) Engine() interface{} {
+	return validate
+}